@@ -22,10 +22,11 @@ func main() {
 	front := flag.String("f", "", "Path to front image")
 	back := flag.String("b", "", "Path to back image")
 	doAlign := flag.Bool("align", false, "Run full alignment pipeline")
+	saveTransform := flag.String("save-transform", "", "Write the final transform to this .align file")
 	flag.Parse()
 
 	if *front == "" || *back == "" {
-		fmt.Println("Usage: aligntest -f <front> -b <back> -align [-p <profile>]")
+		fmt.Println("Usage: aligntest -f <front> -b <back> -align [-p <profile>] [-save-transform <file.align>]")
 		os.Exit(1)
 	}
 
@@ -100,7 +101,7 @@ func main() {
 	// Step 3: Fine alignment via vias
 	fmt.Printf("\n=== Fine alignment (vias) ===\n")
 	viaResult, viaErr := alignment.FineAlignViaTranslation(frontImg, coarseBack, dpi,
-		frontContactResult, backContactResult, coarseTransform)
+		frontContactResult, backContactResult, coarseTransform, nil)
 	if viaErr != nil {
 		fmt.Fprintf(os.Stderr, "Via alignment failed: %v\n", viaErr)
 		if viaResult == nil {
@@ -117,6 +118,7 @@ func main() {
 		fmt.Printf("Matched vias: %d\n", viaResult.MatchedVias)
 		fmt.Printf("Avg error: %.2f px\n", viaResult.AvgError)
 		fmt.Printf("Max error: %.2f px\n", viaResult.MaxError)
+		fmt.Printf("RANSAC: %d via inliers, %d outliers rejected\n", len(viaResult.Inliers), len(viaResult.Outliers))
 		fmt.Printf("Rotation: %.4f°\n", angle)
 		fmt.Printf("Scale: %.6f\n", scale)
 		fmt.Printf("Translation: (%.1f, %.1f)\n", finalTransform.TX, finalTransform.TY)
@@ -124,6 +126,26 @@ func main() {
 		// Use the fine transform (not composed) since UsedBackPts are in
 		// coarse-warped coordinates, not original image coordinates.
 		printResiduals(viaResult, viaResult.Transform)
+
+		if *saveTransform != "" {
+			avg, maxErr, rms := alignment.ResidualStats(viaResult.UsedFrontPts, viaResult.UsedBackPts, viaResult.Transform)
+			meta := alignment.AlignMeta{
+				DPI:         dpi,
+				Profile:     state.BoardSpec.Name(),
+				MatchedVias: viaResult.MatchedVias,
+				AvgError:    avg,
+				MaxError:    maxErr,
+				RMSError:    rms,
+			}
+			if err := alignment.SaveTransform(*saveTransform, finalTransform, meta); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to save transform: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\nSaved transform to %s\n", *saveTransform)
+		}
+	} else if *saveTransform != "" {
+		fmt.Fprintf(os.Stderr, "No via alignment; transform not saved\n")
+		os.Exit(1)
 	}
 }
 
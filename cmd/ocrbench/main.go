@@ -0,0 +1,233 @@
+// Command ocrbench measures OCR accuracy on a directory of labeled component
+// crops, so changes to the preprocessing/recognition pipeline can be checked
+// for regressions.
+//
+// The directory must contain a labels.json listing each crop:
+//
+//	[{"image": "u7.png", "text": "74LS00", "orientation": "N"}, ...]
+//
+// Orientation is the N/S/E/W direction selected in the components panel; the
+// crop is rotated the same way before recognition. Accuracy is the mean
+// TextSimilarity against the expected text, overall and per orientation.
+//
+// Usage: ocrbench [options] <fixture-dir>
+//
+// With -baseline, exits non-zero if any accuracy falls below the baseline by
+// more than -tolerance. With -write-baseline, stores the current accuracies.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"pcb-tracer/internal/component"
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/internal/ocr"
+
+	"gocv.io/x/gocv"
+)
+
+// Label describes one fixture crop and its ground truth.
+type Label struct {
+	Image       string `json:"image"`
+	Text        string `json:"text"`
+	Orientation string `json:"orientation"`
+}
+
+// Baseline holds the accuracies a run must not fall below.
+type Baseline struct {
+	Overall      float64            `json:"overall"`
+	Orientations map[string]float64 `json:"orientations"`
+}
+
+var (
+	flagVerbose       = flag.Bool("v", false, "Print every reading")
+	flagBaseline      = flag.String("baseline", "", "Fail if accuracy drops below this baseline JSON")
+	flagWriteBaseline = flag.String("write-baseline", "", "Write current accuracies to this baseline JSON")
+	flagTolerance     = flag.Float64("tolerance", 0.01, "Allowed accuracy drop below baseline")
+)
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <fixture-dir>\n", os.Args[0])
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	dir := flag.Arg(0)
+
+	labels, err := loadLabels(filepath.Join(dir, "labels.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading labels: %v\n", err)
+		os.Exit(1)
+	}
+
+	engine, err := ocr.NewEngine()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating OCR engine: %v\n", err)
+		os.Exit(1)
+	}
+	defer engine.Close()
+
+	params := ocr.DefaultOCRParams()
+
+	var total float64
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, l := range labels {
+		layer, err := pcbimage.Load(filepath.Join(dir, l.Image))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", l.Image, err)
+			os.Exit(1)
+		}
+		orient := l.Orientation
+		if orient == "" {
+			orient = "N"
+		}
+
+		rotated := component.RotateToOrientation(toRGBA(layer.Image), orient)
+		mat, err := imageToMat(rotated)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", l.Image, err)
+			os.Exit(1)
+		}
+		text, err := engine.RecognizeWithParams(mat, params)
+		mat.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "OCR error on %s: %v\n", l.Image, err)
+		}
+
+		score := ocr.TextSimilarity(text, l.Text)
+		total += score
+		sums[orient] += score
+		counts[orient]++
+
+		if *flagVerbose {
+			fmt.Printf("  %-20s %s  %.3f  %q (want %q)\n", l.Image, orient, score, text, l.Text)
+		}
+	}
+
+	current := Baseline{
+		Overall:      total / float64(len(labels)),
+		Orientations: make(map[string]float64),
+	}
+	orients := make([]string, 0, len(counts))
+	for o := range counts {
+		orients = append(orients, o)
+		current.Orientations[o] = sums[o] / float64(counts[o])
+	}
+	sort.Strings(orients)
+
+	fmt.Printf("OCR accuracy: %.1f%% over %d crops\n", current.Overall*100, len(labels))
+	for _, o := range orients {
+		fmt.Printf("  %s: %.1f%% (%d crops)\n", o, current.Orientations[o]*100, counts[o])
+	}
+
+	if *flagWriteBaseline != "" {
+		data, _ := json.MarshalIndent(current, "", "  ")
+		if err := os.WriteFile(*flagWriteBaseline, append(data, '\n'), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing baseline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Baseline written to %s\n", *flagWriteBaseline)
+	}
+
+	if *flagBaseline != "" {
+		base, err := loadBaseline(*flagBaseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading baseline: %v\n", err)
+			os.Exit(1)
+		}
+		if failures := compareBaseline(current, base, *flagTolerance); len(failures) > 0 {
+			fmt.Println("REGRESSION:")
+			for _, f := range failures {
+				fmt.Printf("  %s\n", f)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("Baseline OK")
+	}
+}
+
+func loadLabels(path string) ([]Label, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var labels []Label
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels in %s", path)
+	}
+	return labels, nil
+}
+
+func loadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var base Baseline
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, err
+	}
+	return &base, nil
+}
+
+// compareBaseline lists every accuracy that fell below its baseline by more
+// than tolerance. Orientations absent from the current run are ignored.
+func compareBaseline(current Baseline, base *Baseline, tolerance float64) []string {
+	var failures []string
+	if current.Overall < base.Overall-tolerance {
+		failures = append(failures, fmt.Sprintf("overall %.1f%% < baseline %.1f%%",
+			current.Overall*100, base.Overall*100))
+	}
+	orients := make([]string, 0, len(base.Orientations))
+	for o := range base.Orientations {
+		orients = append(orients, o)
+	}
+	sort.Strings(orients)
+	for _, o := range orients {
+		acc, ok := current.Orientations[o]
+		if ok && acc < base.Orientations[o]-tolerance {
+			failures = append(failures, fmt.Sprintf("%s %.1f%% < baseline %.1f%%",
+				o, acc*100, base.Orientations[o]*100))
+		}
+	}
+	return failures
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			rgba.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return rgba
+}
+
+func imageToMat(img *image.RGBA) (gocv.Mat, error) {
+	bounds := img.Bounds()
+	mat, err := gocv.NewMatFromBytes(bounds.Dy(), bounds.Dx(), gocv.MatTypeCV8UC4, img.Pix)
+	if err != nil {
+		return gocv.NewMat(), err
+	}
+	defer mat.Close()
+
+	bgr := gocv.NewMat()
+	gocv.CvtColor(mat, &bgr, gocv.ColorRGBAToBGR)
+	return bgr, nil
+}
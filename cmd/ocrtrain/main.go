@@ -2,14 +2,23 @@
 // It explores different preprocessing strategies to find optimal OCR parameters.
 //
 // Usage: ocrtrain <project.pcbtrace> [options]
+//
+//	ocrtrain -report
+//	ocrtrain -prune [-prune-max N] [-prune-min S]
+//
+// With -report it prints the most frequent character errors recorded in the
+// global training database instead of training. With -prune it removes
+// duplicate, low-score and surplus samples from that database.
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"image"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -21,6 +30,7 @@ import (
 	"pcb-tracer/internal/logo"
 	"pcb-tracer/internal/ocr"
 	"pcb-tracer/pkg/geometry"
+	"pcb-tracer/ui/prefs"
 
 	"gocv.io/x/gocv"
 )
@@ -52,12 +62,7 @@ type ComponentResult struct {
 }
 
 // CropBounds represents a crop region.
-type CropBounds struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
-}
+type CropBounds = component.CropBounds
 
 // ProjectFile mirrors the project file structure for loading.
 type ProjectFile struct {
@@ -71,19 +76,52 @@ type ProjectFile struct {
 
 var (
 	flagVerbose     = flag.Bool("v", false, "Verbose output")
-	flagParallel    = flag.Int("j", 4, "Number of parallel workers")
+	flagParallel    = flag.Int("j", 4, "Number of parallel OCR workers per annealing search")
 	flagMaxIter     = flag.Int("max-iter", 50000, "Max iterations per orientation")
 	flagMinScore    = flag.Float64("min-score", 0.5, "Minimum score to report")
 	flagOutputJSON  = flag.String("json", "", "Output results to JSON file")
 	flagLogoLib     = flag.Bool("logos", false, "Load logo library from preferences")
+	flagLogoMask    = flag.Float64("logo-mask-max", logo.DefaultMaxMaskFraction, "With -logos: max fraction of a component that logo masking may erase")
+	flagLogoThresh  = flag.Bool("logo-thresholds", false, "With -logos: store each logo's suggested detection threshold in the library")
 	flagOrientation = flag.String("orientation", "", "Test single orientation (N/S/E/W), empty=all")
 	flagComponent   = flag.String("component", "", "Test single component ID, empty=all")
 	flagDebugImg    = flag.String("debug-img", "", "Save debug image to this path")
+	flagReport      = flag.Bool("report", false, "Print a character error report for the global training database and exit")
+	flagPrune       = flag.Bool("prune", false, "Prune the global training database and exit")
+	flagPruneMax    = flag.Int("prune-max", ocr.DefaultPruneOptions().MaxPerGroup, "With -prune: max samples per orientation and manufacturer (0 = no cap)")
+	flagPruneMin    = flag.Float64("prune-min", ocr.DefaultPruneOptions().MinScore, "With -prune: drop samples scoring below this")
 )
 
 func main() {
 	flag.Parse()
 
+	if *flagReport {
+		db, err := ocr.LoadGlobalTraining()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading training database: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(db.ErrorReport().String())
+		return
+	}
+
+	if *flagPrune {
+		db, err := ocr.LoadGlobalTraining()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading training database: %v\n", err)
+			os.Exit(1)
+		}
+		stats := db.Prune(ocr.PruneOptions{MinScore: *flagPruneMin, MaxPerGroup: *flagPruneMax})
+		fmt.Printf("Pruned training database: %s\n", stats)
+		if stats.Total() > 0 {
+			if err := ocr.SaveGlobalTraining(db); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving training database: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
 	if flag.NArg() < 1 {
 		fmt.Fprintf(os.Stderr, "Usage: %s <project.pcbtrace> [options]\n", os.Args[0])
 		flag.PrintDefaults()
@@ -162,8 +200,12 @@ func main() {
 	}
 	fmt.Println()
 
+	// Ctrl-C stops training early; results found so far are still reported
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	// Run training (pass crop offsets so bounds are correctly mapped to full image)
-	results := runTraining(trainableComponents, frontImg, backImg, logoLib, proj.FrontCrop, proj.BackCrop)
+	results := runTraining(ctx, trainableComponents, frontImg, backImg, logoLib, proj.FrontCrop, proj.BackCrop)
 
 	// Print results
 	printResults(results)
@@ -182,6 +224,10 @@ func main() {
 
 	// Print summary statistics
 	printSummary(results)
+
+	if logoLib != nil && len(logoLib.Logos) > 0 {
+		evaluateLogos(trainableComponents, frontImg, backImg, logoLib, proj.FrontCrop, proj.BackCrop)
+	}
 }
 
 func loadProject(path string) (*ProjectFile, error) {
@@ -198,40 +244,20 @@ func loadProject(path string) (*ProjectFile, error) {
 	return &proj, nil
 }
 
-func runTraining(components []*component.Component, frontImg, backImg image.Image, logoLib *logo.LogoLibrary, frontCrop, backCrop *CropBounds) []ComponentResult {
+func runTraining(ctx context.Context, components []*component.Component, frontImg, backImg image.Image, logoLib *logo.LogoLibrary, frontCrop, backCrop *CropBounds) []ComponentResult {
 	results := make([]ComponentResult, len(components))
 	var wg sync.WaitGroup
-	sem := make(chan struct{}, *flagParallel)
+	// Components run one at a time: each annealing search already keeps
+	// -j OCR workers busy, which also parallelizes single-component runs
+	sem := make(chan struct{}, 1)
 	var completed int64
 	var completedMu sync.Mutex
 
 	for i, comp := range components {
 		// Prepare work item before spawning goroutine
-		img := frontImg
-		crop := frontCrop
-		if comp.Layer == pcbimage.SideBack {
-			img = backImg
-			crop = backCrop
-		}
-		if img == nil {
-			fmt.Printf("[%d/%d] %s: ERROR no image for layer %v\n", i+1, len(components), comp.ID, comp.Layer)
-			results[i] = ComponentResult{Component: comp, GroundTruth: comp.CorrectedText}
-			continue
-		}
-
-		bounds := comp.Bounds
-		if crop != nil {
-			bounds = geometry.Rect{
-				X:      comp.Bounds.X + crop.X,
-				Y:      comp.Bounds.Y + crop.Y,
-				Width:  comp.Bounds.Width,
-				Height: comp.Bounds.Height,
-			}
-		}
-
-		cropped := extractRegion(img, bounds)
-		if cropped == nil {
-			fmt.Printf("[%d/%d] %s: ERROR could not extract region\n", i+1, len(components), comp.ID)
+		cropped, err := component.CropImage(comp, frontImg, backImg, frontCrop, backCrop)
+		if err != nil {
+			fmt.Printf("[%d/%d] %s: ERROR %v\n", i+1, len(components), comp.ID, err)
 			results[i] = ComponentResult{Component: comp, GroundTruth: comp.CorrectedText}
 			continue
 		}
@@ -242,6 +268,11 @@ func runTraining(components []*component.Component, frontImg, backImg image.Imag
 			sem <- struct{}{}        // acquire worker slot
 			defer func() { <-sem }() // release worker slot
 
+			if ctx.Err() != nil {
+				results[idx] = ComponentResult{Component: comp, GroundTruth: comp.CorrectedText}
+				return
+			}
+
 			fmt.Printf("[%d/%d] Training component %s (truth: %q)\n", idx+1, len(components), comp.ID, comp.CorrectedText)
 
 			compResult := ComponentResult{
@@ -255,24 +286,30 @@ func runTraining(components []*component.Component, frontImg, backImg image.Imag
 			}
 
 			for _, orient := range orientations {
+				if ctx.Err() != nil {
+					break
+				}
 				maskOptions := []bool{false}
 				if logoLib != nil && len(logoLib.Logos) > 0 {
 					maskOptions = []bool{false, true}
 				}
 
 				for _, maskLogos := range maskOptions {
+					if ctx.Err() != nil {
+						break
+					}
 					srcImg := cropped
 					if maskLogos {
 						srcImg = maskLogosInImage(cropped, logoLib, orient)
 					}
 
-					rotated := rotateForOCR(srcImg, orient)
+					rotated := component.RotateToOrientation(srcImg, orient)
 					mat := imageToMat(rotated)
 					if mat.Empty() {
 						continue
 					}
 
-					searchResults := runExhaustiveSearch(comp.ID, comp.CorrectedText, mat, orient, maskLogos)
+					searchResults := runExhaustiveSearch(ctx, comp.ID, comp.CorrectedText, mat, orient, maskLogos)
 					compResult.Results = append(compResult.Results, searchResults...)
 					mat.Close()
 				}
@@ -310,10 +347,10 @@ func runTraining(components []*component.Component, frontImg, backImg image.Imag
 	return results
 }
 
-func runExhaustiveSearch(compID, groundTruth string, mat gocv.Mat, orientation string, maskLogos bool) []Result {
+func runExhaustiveSearch(ctx context.Context, compID, groundTruth string, mat gocv.Mat, orientation string, maskLogos bool) []Result {
 	var results []Result
 
-	// Create OCR engine
+	// Create OCR engine (used only on this goroutine; AnnealParallel makes its own)
 	engine, err := ocr.NewEngine()
 	if err != nil {
 		fmt.Printf("    ERROR creating OCR engine: %v\n", err)
@@ -331,8 +368,15 @@ func runExhaustiveSearch(compID, groundTruth string, mat gocv.Mat, orientation s
 
 	// Run parameter annealing
 	start := time.Now()
-	bestParams, bestScore, bestText := engine.AnnealOCRParams(mat, groundTruth, *flagMaxIter)
+	bestParams, bestScore, bestText, err := ocr.AnnealParallel(ctx, mat, groundTruth, *flagMaxIter, *flagParallel)
 	duration := time.Since(start)
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("    ERROR annealing: %v\n", err)
+		return results
+	}
+	if err != nil && bestText == "" {
+		return results
+	}
 
 	if *flagVerbose {
 		fmt.Printf("    [%s mask=%v] score=%.1f%% in %v -> %q\n",
@@ -357,25 +401,25 @@ func runExhaustiveSearch(compID, groundTruth string, mat gocv.Mat, orientation s
 	// Also test some additional specific strategies
 	additionalStrategies := []ocr.OCRParams{
 		// Default Otsu
-		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 150, PSMMode: 6, CLAHEClipLimit: 2.0, CLAHETileSize: 8},
+		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 150, PSMMode: 6, CLAHEClipLimit: 2.0, CLAHETileSize: 8, OEM: ocr.OEMLSTM},
 		// Aggressive CLAHE
-		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, CLAHEClipLimit: 8.0, CLAHETileSize: 4},
+		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, CLAHEClipLimit: 8.0, CLAHETileSize: 4, OEM: ocr.OEMLSTM},
 		// Fixed threshold light text
-		{FixedThreshold: 100, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6},
-		{FixedThreshold: 120, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6},
-		{FixedThreshold: 140, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6},
-		{FixedThreshold: 160, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6},
+		{FixedThreshold: 100, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, OEM: ocr.OEMLSTM},
+		{FixedThreshold: 120, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, OEM: ocr.OEMLSTM},
+		{FixedThreshold: 140, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, OEM: ocr.OEMLSTM},
+		{FixedThreshold: 160, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, OEM: ocr.OEMLSTM},
 		// Single line mode
-		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 7},
+		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 7, OEM: ocr.OEMLSTM},
 		// Sparse text mode
-		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 11},
+		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 11, OEM: ocr.OEMLSTM},
 		// Raw line mode
-		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 13},
+		{UseOtsu: true, InvertPolarity: true, MinScaleDim: 200, PSMMode: 13, OEM: ocr.OEMLSTM},
 		// Adaptive threshold
-		{UseAdaptive: true, AdaptiveBlock: 21, AdaptiveC: 10, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6},
+		{UseAdaptive: true, AdaptiveBlock: 21, AdaptiveC: 10, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, OEM: ocr.OEMLSTM},
 		// Morphological cleanup
-		{FixedThreshold: 130, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, DilateIterations: 1},
-		{FixedThreshold: 130, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, ErodeIterations: 1},
+		{FixedThreshold: 130, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, DilateIterations: 1, OEM: ocr.OEMLSTM},
+		{FixedThreshold: 130, InvertPolarity: true, MinScaleDim: 200, PSMMode: 6, ErodeIterations: 1, OEM: ocr.OEMLSTM},
 	}
 
 	for i, params := range additionalStrategies {
@@ -402,81 +446,50 @@ func runExhaustiveSearch(compID, groundTruth string, mat gocv.Mat, orientation s
 	return results
 }
 
-func extractRegion(img image.Image, bounds geometry.Rect) *image.RGBA {
-	x := int(bounds.X)
-	y := int(bounds.Y)
-	w := int(bounds.Width)
-	h := int(bounds.Height)
-
-	imgBounds := img.Bounds()
-	if x < imgBounds.Min.X {
-		x = imgBounds.Min.X
-	}
-	if y < imgBounds.Min.Y {
-		y = imgBounds.Min.Y
-	}
-	if x+w > imgBounds.Max.X {
-		w = imgBounds.Max.X - x
+// evaluateLogos measures the logo library against the <NAME> logo labels in
+// the components' ground truth and prints per-logo precision, recall and
+// suggested thresholds. With -logo-thresholds the suggestions are stored on
+// the logos and the library is saved.
+func evaluateLogos(components []*component.Component, frontImg, backImg image.Image, logoLib *logo.LogoLibrary, frontCrop, backCrop *CropBounds) {
+	var samples []logo.LabeledCrop
+	for _, comp := range components {
+		cropped, err := component.CropImage(comp, frontImg, backImg, frontCrop, backCrop)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, logo.LabeledCrop{
+			Image:    cropped,
+			Rotation: orientationToRotation(comp.OCROrientation),
+			Logos:    logo.NamesInText(comp.CorrectedText),
+		})
 	}
-	if y+h > imgBounds.Max.Y {
-		h = imgBounds.Max.Y - y
+	if len(samples) == 0 {
+		return
 	}
 
-	if w <= 0 || h <= 0 {
-		return nil
-	}
+	metrics := logoLib.EvaluateAgainst(samples, logoDetectOptions())
+	fmt.Println("\nLogo detection:")
+	fmt.Print(metrics)
 
-	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			cropped.Set(dx, dy, img.At(x+dx, y+dy))
+	if *flagLogoThresh {
+		changed := logoLib.ApplySuggestedThresholds(metrics)
+		if err := logoLib.SaveToPreferences(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving logo library: %v\n", err)
+		} else {
+			fmt.Printf("Applied suggested thresholds to %d logos\n", changed)
 		}
 	}
-
-	return cropped
 }
 
-func rotateForOCR(img *image.RGBA, orientation string) *image.RGBA {
-	bounds := img.Bounds()
-	w, h := bounds.Dx(), bounds.Dy()
-
-	switch orientation {
-	case "S": // 180 degrees
-		rotated := image.NewRGBA(image.Rect(0, 0, w, h))
-		for y := 0; y < h; y++ {
-			for x := 0; x < w; x++ {
-				rotated.Set(w-1-x, h-1-y, img.At(x, y))
-			}
-		}
-		return rotated
-
-	case "E": // 90 degrees CCW
-		rotated := image.NewRGBA(image.Rect(0, 0, h, w))
-		for y := 0; y < h; y++ {
-			for x := 0; x < w; x++ {
-				rotated.Set(y, w-1-x, img.At(x, y))
-			}
-		}
-		return rotated
-
-	case "W": // 90 degrees CW
-		rotated := image.NewRGBA(image.Rect(0, 0, h, w))
-		for y := 0; y < h; y++ {
-			for x := 0; x < w; x++ {
-				rotated.Set(h-1-y, x, img.At(x, y))
-			}
-		}
-		return rotated
-
-	default: // N or unknown
-		result := image.NewRGBA(bounds)
-		for y := 0; y < h; y++ {
-			for x := 0; x < w; x++ {
-				result.Set(x, y, img.At(x, y))
-			}
-		}
-		return result
-	}
+// logoDetectOptions returns the logo scale sweep set in the Components
+// panel, so the suggested thresholds are tuned for the search they gate.
+func logoDetectOptions() logo.DetectOptions {
+	p := prefs.Load()
+	opts := logo.DefaultDetectOptions()
+	opts.MinScale = p.FloatWithFallback("logoMinScale", opts.MinScale)
+	opts.MaxScale = p.FloatWithFallback("logoMaxScale", opts.MaxScale)
+	opts.ScaleSteps = p.FloatWithFallback("logoScaleSteps", opts.ScaleSteps)
+	return opts
 }
 
 func maskLogosInImage(img *image.RGBA, logoLib *logo.LogoLibrary, orientation string) *image.RGBA {
@@ -506,8 +519,15 @@ func maskLogosInImage(img *image.RGBA, logoLib *logo.LogoLibrary, orientation st
 	// Calculate background color
 	bgColor := ocr.CalculateBackgroundColor(img)
 
-	// Mask each logo region
-	for _, m := range matches {
+	// Mask each logo region that can go without erasing markings
+	decisions := logo.PlanMasks(img, matches, *flagLogoMask)
+	for i, m := range matches {
+		if decisions[i] != logo.MaskOK {
+			if *flagVerbose {
+				fmt.Printf("  Not masking <%s>: %s\n", m.Logo.Name, decisions[i])
+			}
+			continue
+		}
 		ocr.MaskRegion(result, m.Bounds, bgColor)
 	}
 
@@ -724,6 +744,8 @@ func printSummary(results []ComponentResult) {
 		switch {
 		case p.UseAdaptive:
 			method = fmt.Sprintf("adaptive_blk%d_c%d", p.AdaptiveBlock, p.AdaptiveC)
+		case p.UseOtsu && p.OtsuGridCols > 0 && p.OtsuGridRows > 0:
+			method = fmt.Sprintf("robust_otsu_%dx%d_trim%d", p.OtsuGridCols, p.OtsuGridRows, p.OtsuTrim)
 		case p.UseOtsu:
 			method = fmt.Sprintf("otsu_clahe%.1f", p.CLAHEClipLimit)
 		case p.FixedThreshold > 0:
@@ -734,7 +756,7 @@ func printSummary(results []ComponentResult) {
 			method = "unknown"
 		}
 
-		key := fmt.Sprintf("%s_psm%d_scale%d_inv%v", method, p.PSMMode, p.MinScaleDim, p.InvertPolarity)
+		key := fmt.Sprintf("%s_psm%d_oem%d_scale%d_inv%v", method, p.PSMMode, p.OEM, p.MinScaleDim, p.InvertPolarity)
 		paramCounts[key]++
 		paramScores[key] = append(paramScores[key], cr.BestResult.Score)
 	}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"image"
@@ -9,8 +10,10 @@ import (
 	_ "image/png"
 	"os"
 
+	"pcb-tracer/internal/alignment"
 	"pcb-tracer/internal/via"
 	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
 
 	_ "golang.org/x/image/tiff"
 )
@@ -19,13 +22,32 @@ func main() {
 	imagePath := flag.String("image", "", "Path to PCB image (TIFF, PNG, or JPEG)")
 	dpi := flag.Float64("dpi", 600, "Image DPI")
 	side := flag.String("side", "front", "Board side: front or back")
+	loadTransform := flag.String("load-transform", "", "Map via centers through a saved .align transform (back -> front)")
+	classifierPath := flag.String("classifier", "", "Train a classifier from a via_training.json and let it decide borderline candidates")
+	outlierZ := flag.Float64("outlier-z", via.DefaultOutlierZ, "Flag vias whose radius is this many deviations from the median (0 disables)")
 	flag.Parse()
 
 	if *imagePath == "" {
-		fmt.Println("Usage: viatest -image <path> [-dpi 600] [-side front|back]")
+		fmt.Println("Usage: viatest -image <path> [-dpi 600] [-side front|back] [-load-transform <file.align>] [-classifier <via_training.json>] [-outlier-z 3]")
 		os.Exit(1)
 	}
 
+	// Load the saved alignment before the (slow) detection so a bad file fails fast
+	transform := geometry.Identity()
+	if *loadTransform != "" {
+		t, meta, err := alignment.LoadTransform(*loadTransform)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load transform: %v\n", err)
+			os.Exit(1)
+		}
+		transform = t
+		fmt.Printf("Loaded transform %s (profile %q, %.0f DPI, %d vias, avg %.2f / max %.2f / rms %.2f px)\n",
+			*loadTransform, meta.Profile, meta.DPI, meta.MatchedVias, meta.AvgError, meta.MaxError, meta.RMSError)
+		if meta.DPI > 0 && meta.DPI != *dpi {
+			fmt.Printf("Warning: transform was computed at %.0f DPI, image is %.0f DPI\n", meta.DPI, *dpi)
+		}
+	}
+
 	// Load image
 	f, err := os.Open(*imagePath)
 	if err != nil {
@@ -66,23 +88,72 @@ func main() {
 	fmt.Printf("  Hough cross-validate: %v (dp=%.1f minDist=%d param1=%.0f param2=%.0f)\n",
 		params.RequireHoughConfirm, params.HoughDP, params.HoughMinDist, params.HoughParam1, params.HoughParam2)
 
+	// Optional learned classifier for borderline candidates
+	if *classifierPath != "" {
+		ts, err := via.LoadTrainingSet(*classifierPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load training set: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := ts.MeasureFeatures(img, boardSide, params); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to measure training samples: %v\n", err)
+			os.Exit(1)
+		}
+		c, err := via.TrainClassifier(ts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to train classifier: %v\n", err)
+			os.Exit(1)
+		}
+		params.UseClassifier = true
+		params.Classifier = c
+		fmt.Printf("  Classifier: %d positive, %d negative samples\n", c.Positives, c.Negatives)
+	}
+
 	// Run detection
 	fmt.Printf("\nDetecting vias...\n")
-	result, err := via.DetectViasFromImage(img, boardSide, params)
+	result, err := via.DetectViasFromImage(context.Background(), img, boardSide, params)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Detection failed: %v\n", err)
 		os.Exit(1)
 	}
 
+	outliers := make(map[int]bool)
+	for _, i := range via.FlagOutliers(result.Vias, *outlierZ) {
+		outliers[i] = true
+	}
+
 	fmt.Printf("\nDetected %d vias:\n", len(result.Vias))
-	fmt.Printf("%-12s %10s %10s %8s %8s %12s %10s\n",
+	fmt.Printf("%-12s %10s %10s %8s %8s %12s %10s",
 		"ID", "X", "Y", "Radius", "Circ", "Confidence", "Method")
+	if *loadTransform != "" {
+		fmt.Printf(" %10s %10s", "AlignedX", "AlignedY")
+	}
+	fmt.Println()
 	fmt.Println(string(make([]byte, 80)))
 
-	for _, v := range result.Vias {
-		fmt.Printf("%-12s %10.1f %10.1f %8.1f %8.2f %12.2f %10s\n",
+	for i, v := range result.Vias {
+		fmt.Printf("%-12s %10.1f %10.1f %8.1f %8.2f %12.2f %10s",
 			v.ID, v.Center.X, v.Center.Y, v.Radius, v.Circularity, v.Confidence, v.Method)
+		if *loadTransform != "" {
+			p := transform.Apply(v.Center)
+			fmt.Printf(" %10.1f %10.1f", p.X, p.Y)
+		}
+		if outliers[i] {
+			fmt.Print("  SIZE OUTLIER")
+		}
+		fmt.Println()
 	}
 
 	fmt.Printf("\nTotal: %d vias detected\n", len(result.Vias))
+
+	if len(result.Vias) > 0 {
+		fmt.Printf("\n%s", via.SizeHistogram(result.Vias))
+		if len(outliers) > 0 {
+			fmt.Printf("%d size outliers (|z| > %.1f) - likely pads or merged blobs\n", len(outliers), *outlierZ)
+		}
+	}
+
+	if result.Report != nil {
+		fmt.Printf("\n%s", result.Report)
+	}
 }
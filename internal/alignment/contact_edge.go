@@ -107,6 +107,12 @@ func detectContactsOnEdge(img gocv.Mat, goldMask gocv.Mat, boardBounds geometry.
 		expectH = cs.HeightInches * params.DPI
 	}
 
+	// Loose aspect bounds around the detection range (2:1 to 15:1 without one)
+	aspectMin, aspectMax := 2.0, 15.0
+	if params.AspectMin > 0 && params.AspectMax > params.AspectMin {
+		aspectMin, aspectMax = params.AspectMin/2, params.AspectMax*2
+	}
+
 	// First pass: collect ALL gold regions that could be contacts (loose filtering)
 	var candidates []Contact
 	totalContours := contours.Size()
@@ -128,8 +134,8 @@ func detectContactsOnEdge(img gocv.Mat, goldMask gocv.Mat, boardBounds geometry.
 				aspect = float64(w) / float64(h)
 			}
 
-			// Loose aspect ratio check (at least 2:1)
-			if aspect < 2.0 || aspect > 15.0 {
+			// Loose aspect ratio check: half the minimum to twice the maximum
+			if aspect < aspectMin || aspect > aspectMax {
 				rejectedAspect++
 				if len(rejectedAspectSamples) < 10 {
 					rejectedAspectSamples = append(rejectedAspectSamples,
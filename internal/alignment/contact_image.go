@@ -136,6 +136,46 @@ func RotateGoImage(img image.Image, angleDegrees float64) image.Image {
 	return result
 }
 
+// RescaleToMatch scales the back image by factor so that its resolution
+// matches the front image. A factor above 1 enlarges the image; a factor of
+// 1 (or a non-positive one) returns the input unchanged.
+func RescaleToMatch(back image.Image, factor float64) image.Image {
+	if factor <= 0 || factor == 1 {
+		return back
+	}
+
+	bounds := back.Bounds()
+	newW := int(math.Round(float64(bounds.Dx()) * factor))
+	newH := int(math.Round(float64(bounds.Dy()) * factor))
+	if newW < 1 || newH < 1 {
+		return back
+	}
+	fmt.Printf("RescaleToMatch: %dx%d -> %dx%d (factor=%.4f)\n",
+		bounds.Dx(), bounds.Dy(), newW, newH, factor)
+
+	mat, err := imageToMat(back)
+	if err != nil {
+		fmt.Printf("RescaleToMatch: imageToMat failed: %v\n", err)
+		return back
+	}
+	defer mat.Close()
+
+	interp := gocv.InterpolationLinear
+	if factor < 1 {
+		interp = gocv.InterpolationArea
+	}
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	gocv.Resize(mat, &scaled, image.Pt(newW, newH), 0, 0, interp)
+
+	result, err := matToImage(scaled)
+	if err != nil {
+		fmt.Printf("RescaleToMatch: matToImage failed: %v\n", err)
+		return back
+	}
+	return result
+}
+
 // rotateMatByAngle rotates a Mat by an arbitrary angle.
 func rotateMatByAngle(img gocv.Mat, angleDegrees float64) gocv.Mat {
 	h := img.Rows()
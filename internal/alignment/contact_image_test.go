@@ -0,0 +1,77 @@
+package alignment
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// pitchContacts returns n contacts spaced pitch pixels apart along a row.
+func pitchContacts(n int, x0, pitch float64) []Contact {
+	contacts := make([]Contact, n)
+	for i := range contacts {
+		contacts[i].Center = geometry.Point2D{X: x0 + float64(i)*pitch, Y: 100}
+	}
+	return contacts
+}
+
+// TestRescaleToMatch checks that a back scan 2% smaller than the front is
+// rescaled so its contact spacing matches the front's.
+func TestRescaleToMatch(t *testing.T) {
+	// S-100 contacts are on a 0.125" pitch: 75 px at 600 DPI
+	const n, frontPitch = 20, 75.0
+	backPitch := frontPitch * 0.98
+
+	frontEst := EstimateDPI(&DetectionResult{Contacts: pitchContacts(n, 50, frontPitch)}, nil)
+	backEst := EstimateDPI(&DetectionResult{Contacts: pitchContacts(n, 50, backPitch)}, nil)
+	if math.Abs(frontEst-600) > 1e-6 || math.Abs(backEst-588) > 1e-6 {
+		t.Fatalf("estimated DPI front %v back %v, want 600 and 588", frontEst, backEst)
+	}
+	factor := frontEst / backEst
+
+	// Light contact fingers on a dark board
+	back := image.NewGray(image.Rect(0, 0, 1600, 200))
+	for i := 0; i < n; i++ {
+		cx := 50 + float64(i)*backPitch
+		for y := 20; y < 180; y++ {
+			for x := int(cx - 20); x < int(cx+20); x++ {
+				back.SetGray(x, y, color.Gray{230})
+			}
+		}
+	}
+
+	scaled := RescaleToMatch(back, factor)
+	b := scaled.Bounds()
+	if wantW := int(math.Round(1600 * factor)); b.Dx() != wantW {
+		t.Errorf("rescaled width %d, want %d", b.Dx(), wantW)
+	}
+
+	// Find the finger centers along the middle row
+	var found []Contact
+	y := b.Min.Y + b.Dy()/2
+	start := -1
+	for x := b.Min.X; x <= b.Max.X; x++ {
+		light := x < b.Max.X && color.GrayModel.Convert(scaled.At(x, y)).(color.Gray).Y > 128
+		switch {
+		case light && start < 0:
+			start = x
+		case !light && start >= 0:
+			found = append(found, Contact{Center: geometry.Point2D{X: float64(start+x-1) / 2, Y: float64(y)}})
+			start = -1
+		}
+	}
+	if len(found) != n {
+		t.Fatalf("found %d contacts after rescaling, want %d", len(found), n)
+	}
+	est := EstimateDPI(&DetectionResult{Contacts: found}, nil)
+	if math.Abs(est/frontEst-1) > 0.002 {
+		t.Errorf("rescaled contacts estimate %.1f DPI, want %.1f", est, frontEst)
+	}
+
+	if RescaleToMatch(back, 1) != image.Image(back) {
+		t.Error("RescaleToMatch(factor 1) did not return the input")
+	}
+}
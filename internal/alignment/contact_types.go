@@ -81,8 +81,10 @@ func ParamsFromSpec(spec board.Spec) DetectionParams {
 	params.SatMax = det.Color.SatMax
 	params.ValMin = det.Color.ValMin
 	params.ValMax = det.Color.ValMax
-	params.AspectMin = det.AspectRatioMin
-	params.AspectMax = det.AspectRatioMax
+	if hasAspectBounds(contacts) {
+		params.AspectMin = det.AspectRatioMin
+		params.AspectMax = det.AspectRatioMax
+	}
 	params.MinArea = det.MinAreaPixels
 	params.MaxArea = det.MaxAreaPixels
 	return params
@@ -111,10 +113,13 @@ func ParamsFromSpecWithDPI(spec board.Spec, dpi float64) DetectionParams {
 	params.MinArea = int(nominalArea * 0.4)  // Allow 60% smaller
 	params.MaxArea = int(nominalArea * 1.8)  // Allow 80% larger
 
-	// Aspect ratio (height/width for vertical contacts)
-	nominalAspect := heightPixels / widthPixels
-	params.AspectMin = nominalAspect * 0.5
-	params.AspectMax = nominalAspect * 1.5
+	// Aspect ratio (height/width for vertical contacts), unless the spec's
+	// detection parameters set their own bounds
+	if !hasAspectBounds(contacts) {
+		nominalAspect := heightPixels / widthPixels
+		params.AspectMin = nominalAspect * 0.5
+		params.AspectMax = nominalAspect * 1.5
+	}
 
 	fmt.Printf("DPI-based params: contact=%.1fx%.1f px, area=%d-%d, aspect=%.1f-%.1f\n",
 		widthPixels, heightPixels, params.MinArea, params.MaxArea, params.AspectMin, params.AspectMax)
@@ -122,6 +127,13 @@ func ParamsFromSpecWithDPI(spec board.Spec, dpi float64) DetectionParams {
 	return params
 }
 
+// hasAspectBounds reports whether the contact spec's detection parameters
+// carry a usable aspect ratio range.
+func hasAspectBounds(contacts *board.ContactSpec) bool {
+	det := contacts.Detection
+	return det != nil && det.AspectRatioMin > 0 && det.AspectRatioMax > det.AspectRatioMin
+}
+
 // ExpectedContactDimensions returns expected contact dimensions in pixels for a given DPI.
 type ExpectedContactDimensions struct {
 	Width      float64 // Individual contact width in pixels
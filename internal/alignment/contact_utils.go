@@ -57,6 +57,16 @@ func calculateDPI(contacts []Contact, spec board.Spec) float64 {
 	return avgSpacing / pitch
 }
 
+// EstimateDPI estimates the scan resolution from the spacing of detected
+// contacts, ignoring any DPI that was supplied to the detector. Returns 0 if
+// too few contacts were found.
+func EstimateDPI(result *DetectionResult, spec board.Spec) float64 {
+	if result == nil {
+		return 0
+	}
+	return calculateDPI(result.Contacts, spec)
+}
+
 // findPitchFromIntervals finds the actual pitch from a list of intervals.
 // Intervals should be multiples of the pitch (1x, 2x, 3x, etc. for gaps).
 // Returns the most likely base pitch.
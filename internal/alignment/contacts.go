@@ -2,9 +2,11 @@
 package alignment
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"sort"
+	"strings"
 
 	"pcb-tracer/internal/board"
 	"pcb-tracer/pkg/geometry"
@@ -52,7 +54,76 @@ func DetectContactsOnTopEdge(img image.Image, spec board.Spec, dpi float64, colo
 	}
 	defer mat.Close()
 
-	return detectContactsOnTopEdgeOnly(mat, spec, dpi, colorParams)
+	return detectContactsOnEdgeOnly(mat, spec, dpi, colorParams, board.EdgeTop)
+}
+
+// DetectContactGroups detects every contact group of the spec on an image
+// that has been rotated so the primary group is at the top. The primary
+// group is detected as DetectContactsOnTopEdge does; each extra group is
+// searched for on its own edge relative to the primary one. Results are
+// returned in spec group order (never nil entries, possibly empty). With a
+// single group the error is the primary detection's error; otherwise it
+// joins the per-group errors, each prefixed with the group label.
+func DetectContactGroups(img image.Image, spec board.Spec, dpi float64, colorParams *DetectionParams) ([]*DetectionResult, error) {
+	if img == nil {
+		return nil, fmt.Errorf("nil image")
+	}
+	if spec == nil || len(spec.ContactGroups()) <= 1 {
+		result, err := DetectContactsOnTopEdge(img, spec, dpi, colorParams)
+		return []*DetectionResult{result}, err
+	}
+
+	mat, err := imageToMat(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert image: %w", err)
+	}
+	defer mat.Close()
+
+	groups := spec.ContactGroups()
+	primary := groups[0].Edge
+	results := make([]*DetectionResult, len(groups))
+	var errs []error
+	for i, group := range groups {
+		edge := board.OrientedEdge(group.Edge, primary)
+		fmt.Printf("Contact %s: %d contacts on %s edge\n", group.Label(i), group.Count, edge)
+		result, err := detectContactsOnEdgeOnly(mat, board.ForContactGroup(spec, group), dpi, colorParams, edge)
+		if result == nil {
+			result = &DetectionResult{Edge: string(edge)}
+		}
+		results[i] = result
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", group.Label(i), err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// MaskContacts runs only the cheap stage of top-edge contact detection:
+// gold color mask plus contour filtering. It skips grid rescue, outlier
+// removal and width normalization, so it is fast enough to re-run for a live
+// preview while tuning color parameters. The result holds the raw seed
+// contacts; use DetectContactsOnTopEdge for the full detection.
+func MaskContacts(img image.Image, spec board.Spec, dpi float64, colorParams *DetectionParams) (*DetectionResult, error) {
+	if img == nil {
+		return nil, fmt.Errorf("nil image")
+	}
+
+	mat, err := imageToMat(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert image: %w", err)
+	}
+	defer mat.Close()
+
+	params := resolveDetectionParams(spec, dpi, colorParams)
+	boardBounds, contacts, searchBounds, _ := maskContactsOnEdge(mat, spec, params, board.EdgeTop)
+
+	return &DetectionResult{
+		Contacts:     contacts,
+		Edge:         "top",
+		BoardBounds:  boardBounds,
+		SearchBounds: searchBounds,
+		DPI:          dpi,
+	}, nil
 }
 
 // DetectGoldContacts detects gold edge card contacts in an image.
@@ -69,22 +140,7 @@ func DetectGoldContactsWithColors(img gocv.Mat, spec board.Spec, dpi float64, de
 		return nil, fmt.Errorf("empty image")
 	}
 
-	var params DetectionParams
-	if colorParams != nil {
-		// Use provided color params, but get size params from spec
-		params = *colorParams
-		if dpi > 0 {
-			sizeParams := ParamsFromSpecWithDPI(spec, dpi)
-			params.MinArea = sizeParams.MinArea
-			params.MaxArea = sizeParams.MaxArea
-			params.AspectMin = sizeParams.AspectMin
-			params.AspectMax = sizeParams.AspectMax
-		}
-	} else if dpi > 0 {
-		params = ParamsFromSpecWithDPI(spec, dpi)
-	} else {
-		params = ParamsFromSpec(spec)
-	}
+	params := resolveDetectionParams(spec, dpi, colorParams)
 
 	// Find which edge has contacts (returns seed angle from raw detected contacts)
 	edge, contacts, boardBounds, searchBounds, expectedPositions, seedAngle := findContactEdge(img, spec, params, debug)
@@ -264,13 +320,9 @@ func findContactEdge(img gocv.Mat, spec board.Spec, params DetectionParams, debu
 	return bestEdge, bestContacts, boardBounds, bestSearchBounds, expectedPositions, bestSeedAngle
 }
 
-// detectContactsOnTopEdgeOnly detects contacts only on the top edge.
-// This is used after the image has been rotated so contacts are at top.
-func detectContactsOnTopEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, colorParams *DetectionParams) (*DetectionResult, error) {
-	if img.Empty() {
-		return nil, fmt.Errorf("empty image")
-	}
-
+// resolveDetectionParams builds detection parameters. Color ranges come from
+// colorParams when given; size limits always come from the spec.
+func resolveDetectionParams(spec board.Spec, dpi float64, colorParams *DetectionParams) DetectionParams {
 	var params DetectionParams
 	if colorParams != nil {
 		// Use provided color params, but get size params from spec
@@ -287,7 +339,12 @@ func detectContactsOnTopEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, col
 	} else {
 		params = ParamsFromSpec(spec)
 	}
+	return params
+}
 
+// maskContactsOnEdge finds the board bounds, builds the gold mask and
+// extracts seed contacts along the given edge.
+func maskContactsOnEdge(img gocv.Mat, spec board.Spec, params DetectionParams, edge board.Edge) (geometry.RectInt, []Contact, geometry.RectInt, *ContactLineParams) {
 	// Detect board bounds
 	boardBounds := detectBoardBounds(img)
 
@@ -295,26 +352,43 @@ func detectContactsOnTopEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, col
 	goldMask := createGoldMaskWithParams(img, params)
 	defer goldMask.Close()
 
-	// Detect on top edge only
-	fmt.Printf("Detecting contacts on TOP edge only\n")
-	seedContacts, searchBounds, lineParams := detectContactsOnEdge(img, goldMask, boardBounds, "top", spec, params)
+	// Detect on the requested edge only
+	fmt.Printf("Detecting contacts on %s edge only\n", strings.ToUpper(string(edge)))
+	seedContacts, searchBounds, lineParams := detectContactsOnEdge(img, goldMask, boardBounds, string(edge), spec, params)
+	return boardBounds, seedContacts, searchBounds, lineParams
+}
+
+// detectContactsOnEdgeOnly detects contacts only on the given edge.
+// This is used after the image has been rotated so the primary contacts are
+// at top; extra contact groups may sit on any edge.
+func detectContactsOnEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, colorParams *DetectionParams, edge board.Edge) (*DetectionResult, error) {
+	if img.Empty() {
+		return nil, fmt.Errorf("empty image")
+	}
+
+	params := resolveDetectionParams(spec, dpi, colorParams)
+	boardBounds, seedContacts, searchBounds, lineParams := maskContactsOnEdge(img, spec, params, edge)
 
 	if len(seedContacts) < 2 {
 		return &DetectionResult{
 			Contacts:     seedContacts,
-			Edge:         "top",
+			Edge:         string(edge),
 			BoardBounds:  boardBounds,
 			SearchBounds: searchBounds,
-		}, fmt.Errorf("found only %d contacts on top edge (need at least 2)", len(seedContacts))
+		}, fmt.Errorf("found only %d contacts on %s edge (need at least 2)", len(seedContacts), edge)
 	}
 
 	// Calculate seed angle
-	seedAngle := CalculateContactLineAngle(seedContacts, "top")
-	fmt.Printf("  Top edge: %d seeds, angle=%.2f°\n", len(seedContacts), seedAngle)
+	seedAngle := CalculateContactLineAngle(seedContacts, string(edge))
+	fmt.Printf("  %s edge: %d seeds, angle=%.2f°\n", edge, len(seedContacts), seedAngle)
 
-	// Sort contacts by X position
+	// Sort contacts along the edge
+	isHorizontal := edge == board.EdgeTop || edge == board.EdgeBottom
 	sort.Slice(seedContacts, func(i, j int) bool {
-		return seedContacts[i].Center.X < seedContacts[j].Center.X
+		if isHorizontal {
+			return seedContacts[i].Center.X < seedContacts[j].Center.X
+		}
+		return seedContacts[i].Center.Y < seedContacts[j].Center.Y
 	})
 
 	// Get expected count from spec
@@ -327,23 +401,26 @@ func detectContactsOnTopEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, col
 	var expectedPositions []geometry.RectInt
 	var contacts []Contact
 	if lineParams != nil {
-		expectedPositions, contacts = GridBasedRescue(img, seedContacts, lineParams, expectedCount, true, dpi, spec)
+		expectedPositions, contacts = GridBasedRescue(img, seedContacts, lineParams, expectedCount, isHorizontal, dpi, spec)
 	} else {
 		contacts = seedContacts
 	}
 
-	// Remove size/position outliers
-	if len(contacts) > 5 {
-		beforeOutlier := len(contacts)
-		contacts = removeOutliers(contacts, 0.10)
-		if len(contacts) < beforeOutlier {
-			fmt.Printf("Removed %d outliers from detection\n", beforeOutlier-len(contacts))
+	// Outlier removal and width normalization assume a horizontal row
+	if isHorizontal {
+		// Remove size/position outliers
+		if len(contacts) > 5 {
+			beforeOutlier := len(contacts)
+			contacts = removeOutliers(contacts, 0.10)
+			if len(contacts) < beforeOutlier {
+				fmt.Printf("Removed %d outliers from detection\n", beforeOutlier-len(contacts))
+			}
 		}
-	}
 
-	// Normalize contact widths
-	if len(contacts) > 5 {
-		contacts = normalizeContactWidths(contacts)
+		// Normalize contact widths
+		if len(contacts) > 5 {
+			contacts = normalizeContactWidths(contacts)
+		}
 	}
 
 	// Calculate DPI from contact spacing if not provided
@@ -355,7 +432,7 @@ func detectContactsOnTopEdgeOnly(img gocv.Mat, spec board.Spec, dpi float64, col
 	result := &DetectionResult{
 		Contacts:          contacts,
 		ExpectedPositions: expectedPositions,
-		Edge:              "top",
+		Edge:              string(edge),
 		Rotation:          0, // Already rotated, no further rotation needed
 		BoardBounds:       boardBounds,
 		SearchBounds:      searchBounds,
@@ -0,0 +1,71 @@
+package alignment
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// TestMaskContacts draws a green board on a white bed with a row of gold
+// fingers along its top edge, plus gold that is not a finger: a square pad
+// beside the row and a finger-shaped patch deep inside the board. Only the
+// fingers may come back as contacts, each exactly once and inside its box.
+func TestMaskContacts(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	fill := func(r geometry.RectInt, c color.RGBA) {
+		for y := r.Y; y < r.Y+r.Height; y++ {
+			for x := r.X; x < r.X+r.Width; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+	gold := color.RGBA{R: 220, G: 180, B: 60, A: 255}
+	fill(geometry.RectInt{Width: 800, Height: 600}, color.RGBA{R: 240, G: 240, B: 240, A: 255})
+	fill(geometry.RectInt{X: 100, Y: 100, Width: 600, Height: 400}, color.RGBA{R: 40, G: 100, B: 50, A: 255})
+
+	var fingers []geometry.RectInt
+	for i := 0; i < 10; i++ {
+		f := geometry.RectInt{X: 150 + 40*i, Y: 105, Width: 16, Height: 60}
+		fingers = append(fingers, f)
+		fill(f, gold)
+	}
+	fill(geometry.RectInt{X: 600, Y: 120, Width: 30, Height: 30}, gold) // Square pad
+	fill(geometry.RectInt{X: 400, Y: 350, Width: 16, Height: 60}, gold) // Outside the edge band
+
+	params := &DetectionParams{HueMin: 15, HueMax: 35, SatMin: 100, SatMax: 255, ValMin: 150, ValMax: 255}
+	result, err := MaskContacts(img, nil, 0, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Edge != "top" {
+		t.Errorf("edge %q, want top", result.Edge)
+	}
+	if len(result.Contacts) != len(fingers) {
+		t.Fatalf("%d contacts, want %d", len(result.Contacts), len(fingers))
+	}
+
+	inside := func(b, box geometry.RectInt) bool {
+		return b.X >= box.X-1 && b.Y >= box.Y-1 &&
+			b.X+b.Width <= box.X+box.Width+1 && b.Y+b.Height <= box.Y+box.Height+1
+	}
+	for i, c := range result.Contacts {
+		// Contacts come back sorted along the edge
+		if !inside(c.Bounds, fingers[i]) {
+			t.Errorf("contact %d bounds %v outside finger %v", i, c.Bounds, fingers[i])
+		}
+		if c.Bounds.Width*c.Bounds.Height < fingers[i].Width*fingers[i].Height*9/10 {
+			t.Errorf("contact %d bounds %v cover too little of finger %v", i, c.Bounds, fingers[i])
+		}
+		box := geometry.Rect{X: float64(fingers[i].X), Y: float64(fingers[i].Y),
+			Width: float64(fingers[i].Width), Height: float64(fingers[i].Height)}
+		if !box.Contains(c.Center) {
+			t.Errorf("contact %d center %v outside finger %v", i, c.Center, fingers[i])
+		}
+	}
+
+	if _, err := MaskContacts(nil, nil, 0, params); err == nil {
+		t.Error("nil image: want error")
+	}
+}
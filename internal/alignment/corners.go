@@ -1,6 +1,7 @@
 package alignment
 
 import (
+	"fmt"
 	"image"
 	"math"
 	"sort"
@@ -183,6 +184,19 @@ func extractCorners(contour gocv.PointVector, imgWidth, imgHeight int) []geometr
 
 // DetectEjectorHoles finds ejector/mounting holes using Hough circle detection.
 func DetectEjectorHoles(img gocv.Mat, contacts []Contact, dpi float64, spec *board.BaseSpec) []geometry.Point2D {
+	if spec == nil {
+		return nil
+	}
+	var found []geometry.Point2D
+	for _, m := range detectSpecHoleMarks(img, contacts, dpi, spec) {
+		found = append(found, m.Center)
+	}
+	return found
+}
+
+// detectSpecHoleMarks searches for each hole listed in the board spec and
+// returns the ones found, labeled with the hole's spec name.
+func detectSpecHoleMarks(img gocv.Mat, contacts []Contact, dpi float64, spec board.Spec) []EjectorMark {
 	if len(contacts) == 0 || dpi <= 0 || spec == nil {
 		return nil
 	}
@@ -213,17 +227,19 @@ func DetectEjectorHoles(img gocv.Mat, contacts []Contact, dpi float64, spec *boa
 	boardBottomY := firstY + ((fingerHeight + h) * dpi)
 
 	// Search for each expected hole
-	var foundHoles []geometry.Point2D
+	var foundHoles []EjectorMark
 
-	for _, hole := range holes {
+	for i, hole := range holes {
 		// Calculate expected position
 		var expectedX, expectedY float64
+		side := "left"
 		if hole.XInches < w/2 {
 			// Left side hole
 			expectedX = boardLeftX + (hole.XInches * dpi)
 		} else {
 			// Right side hole
 			expectedX = boardRightX - ((w - hole.XInches) * dpi)
+			side = "right"
 		}
 		expectedY = boardBottomY - ((h - hole.YInches) * dpi)
 
@@ -232,7 +248,11 @@ func DetectEjectorHoles(img gocv.Mat, contacts []Contact, dpi float64, spec *boa
 		holeFound := findHoleInRegion(img, expectedX, expectedY, searchRadius, hole.DiamInches*dpi)
 
 		if holeFound != nil {
-			foundHoles = append(foundHoles, *holeFound)
+			name := hole.Name
+			if name == "" {
+				name = fmt.Sprintf("hole%d", i+1)
+			}
+			foundHoles = append(foundHoles, EjectorMark{Center: *holeFound, Side: side, Name: name})
 		}
 	}
 
@@ -345,66 +365,119 @@ func OrderCorners(corners []geometry.Point2D) []geometry.Point2D {
 // EjectorMark represents a detected ejector registration mark.
 type EjectorMark struct {
 	Center geometry.Point2D // Center of the hole
-	Side   string           // "left" or "right"
+	Side   string           // "left" or "right" half of the board
+	Name   string           // Registration feature name (e.g. "left"), or the spec name for mounting holes
 }
 
-// DetectEjectorMarksFromImage detects ejector registration marks from a Go image.
-func DetectEjectorMarksFromImage(img image.Image, contacts []Contact, dpi float64) []EjectorMark {
+// DetectRegistrationMarksFromImage returns the registration feature marks
+// plus any holes listed in the board spec. Each mark's Name labels it
+// uniquely so front and back marks can be paired with MatchEjectorMarks.
+func DetectRegistrationMarksFromImage(img image.Image, contacts []Contact, dpi float64, spec board.Spec) []EjectorMark {
 	mat, err := imageToMat(img)
 	if err != nil {
 		return nil
 	}
 	defer mat.Close()
-	return DetectEjectorMarks(mat, contacts, dpi)
+
+	marks := DetectEjectorMarks(mat, contacts, dpi, spec)
+	if spec == nil {
+		return marks
+	}
+	// A spec hole that coincides with an ejector mark is the same feature
+	minSep := 0.25 * dpi
+	for _, h := range detectSpecHoleMarks(mat, contacts, dpi, spec) {
+		dup := false
+		for _, m := range marks {
+			if m.Center.Distance(h.Center) < minSep {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			marks = append(marks, h)
+		}
+	}
+	return marks
 }
 
-// DetectEjectorMarks finds the registration holes in the card ejectors at the bottom corners.
-// Card ejectors are bright white triangular regions with a small (~2.5mm) hole in the center.
-// Returns the hole centers which serve as precise registration marks.
-func DetectEjectorMarks(img gocv.Mat, contacts []Contact, dpi float64) []EjectorMark {
+// MatchEjectorMarks pairs front and back marks that share a Name and
+// returns the paired centers and labels in front-mark order.
+func MatchEjectorMarks(front, back []EjectorMark) (frontPts, backPts []geometry.Point2D, labels []string) {
+	backByName := make(map[string]geometry.Point2D, len(back))
+	for _, m := range back {
+		if _, seen := backByName[m.Name]; !seen {
+			backByName[m.Name] = m.Center
+		}
+	}
+	used := make(map[string]bool, len(front))
+	for _, m := range front {
+		bp, ok := backByName[m.Name]
+		if !ok || used[m.Name] {
+			continue
+		}
+		used[m.Name] = true
+		frontPts = append(frontPts, m.Center)
+		backPts = append(backPts, bp)
+		labels = append(labels, m.Name)
+	}
+	return frontPts, backPts, labels
+}
+
+// DetectEjectorMarksFromImage detects the spec's registration features from a Go image.
+func DetectEjectorMarksFromImage(img image.Image, contacts []Contact, dpi float64, spec board.Spec) []EjectorMark {
+	mat, err := imageToMat(img)
+	if err != nil {
+		return nil
+	}
+	defer mat.Close()
+	return DetectEjectorMarks(mat, contacts, dpi, spec)
+}
+
+// DetectEjectorMarks finds the registration features of the spec's primary
+// contact group, locating them from the detected contacts (at the top edge,
+// sorted by X). Ejector features are bright white card ejectors with a small
+// (~2.5mm) hole in the center; hole features are plain holes. A nil spec
+// uses the S-100 ejectors. Returns the found centers labeled with the
+// feature names.
+func DetectEjectorMarks(img gocv.Mat, contacts []Contact, dpi float64, spec board.Spec) []EjectorMark {
 	if len(contacts) < 2 || dpi <= 0 {
 		return nil
 	}
+	if spec == nil {
+		spec = board.S100Spec()
+	}
+	cs := spec.ContactSpec()
+	if cs == nil || len(cs.Registration) == 0 {
+		return nil
+	}
 
 	imgH := img.Rows()
 	imgW := img.Cols()
+	boardW, _ := spec.Dimensions()
 
-	// Estimate board bounds from contacts
-	// Contacts are at top edge, sorted by X
-	firstContact := contacts[0]
-	lastContact := contacts[len(contacts)-1]
-
-	// Contact margin from left edge is about 2.125" for S-100
-	// Board width is about 10"
-	boardMarginPx := 2.125 * dpi
-	boardLeftX := firstContact.Center.X - boardMarginPx
-	boardRightX := lastContact.Center.X + boardMarginPx
-
-	// Board height is about 5.4375" for S-100
-	// Contacts are at top, so bottom is about 5.4" down
-	boardHeightPx := 5.4375 * dpi
-	boardBottomY := firstContact.Center.Y + boardHeightPx
-
-	// Ejector hole is about 2.5mm diameter = 0.0984"
-	ejectorHoleDiam := 0.1 * dpi // ~0.1 inches
-
-	// Search region size - ejectors are in corners
-	searchSize := int(1.0 * dpi) // 1 inch search region
+	first := contacts[0]
 
 	var marks []EjectorMark
-
-	// Search bottom-left corner
-	leftMark := findEjectorMark(img, int(boardLeftX), int(boardBottomY)-searchSize,
-		searchSize, searchSize, ejectorHoleDiam, imgW, imgH)
-	if leftMark != nil {
-		marks = append(marks, EjectorMark{Center: *leftMark, Side: "left"})
-	}
-
-	// Search bottom-right corner
-	rightMark := findEjectorMark(img, int(boardRightX)-searchSize, int(boardBottomY)-searchSize,
-		searchSize, searchSize, ejectorHoleDiam, imgW, imgH)
-	if rightMark != nil {
-		marks = append(marks, EjectorMark{Center: *rightMark, Side: "right"})
+	for _, f := range cs.Registration {
+		dx, dy := cs.FeatureOffset(f)
+		x := first.Center.X + dx*dpi
+		y := first.Center.Y + dy*dpi
+		r := int(f.SearchRadius() * dpi)
+
+		var center *geometry.Point2D
+		switch f.Kind {
+		case board.FeatureEjector:
+			center = findEjectorMark(img, int(x)-r, int(y)-r, 2*r, 2*r, f.DiamInches*dpi, imgW, imgH)
+		case board.FeatureHole:
+			center = findHoleInRegion(img, x, y, r, f.DiamInches*dpi)
+		}
+		if center != nil {
+			side := "left"
+			if f.XInches >= boardW/2 {
+				side = "right"
+			}
+			marks = append(marks, EjectorMark{Center: *center, Side: side, Name: f.Name})
+		}
 	}
 
 	return marks
@@ -476,6 +549,46 @@ func DetectStepEdges(img gocv.Mat, contacts []Contact, dpi float64) []StepEdge {
 	return edges
 }
 
+// MaxStepEdgeSkewDeg is the largest angle allowed between the line through
+// the front step edges and the line through the back ones. Beyond it the
+// layers are rotated relative to each other (or an edge was misdetected)
+// and a pure vertical shift cannot register them.
+const MaxStepEdgeSkewDeg = 0.5
+
+// FineAlignFromStepEdges returns the vertical offset to add to the back
+// layer so its step edges line up with the front's. Both layers need their
+// left and right edges, so that the lines through them can be checked for
+// parallelism; ok is false when an edge is missing on either side or the
+// lines differ by more than MaxStepEdgeSkewDeg. dy is the mean of the left
+// and right differences.
+func FineAlignFromStepEdges(frontEdges, backEdges []StepEdge) (dy float64, ok bool) {
+	bySide := func(edges []StepEdge) (left, right *StepEdge) {
+		for i := range edges {
+			switch edges[i].Side {
+			case "left":
+				left = &edges[i]
+			case "right":
+				right = &edges[i]
+			}
+		}
+		return left, right
+	}
+	fl, fr := bySide(frontEdges)
+	bl, br := bySide(backEdges)
+	if fl == nil || fr == nil || bl == nil || br == nil {
+		return 0, false
+	}
+
+	angle := func(l, r *StepEdge) float64 {
+		return math.Atan2(r.EdgeY-l.EdgeY, r.Corner.X-l.Corner.X) * 180 / math.Pi
+	}
+	if math.Abs(angle(fl, fr)-angle(bl, br)) > MaxStepEdgeSkewDeg {
+		return 0, false
+	}
+
+	return ((fl.EdgeY - bl.EdgeY) + (fr.EdgeY - br.EdgeY)) / 2, true
+}
+
 // findStepEdge searches for the horizontal step edge in a region.
 // Uses Canny edge detection to find strong horizontal edges, then locates
 // the corner where the horizontal step meets the vertical board edge.
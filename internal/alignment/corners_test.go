@@ -0,0 +1,33 @@
+package alignment
+
+import (
+	"slices"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestMatchEjectorMarks(t *testing.T) {
+	pt := func(x float64) geometry.Point2D { return geometry.Point2D{X: x, Y: 10} }
+	front := []EjectorMark{
+		{Center: pt(1), Side: "left", Name: "left"},
+		{Center: pt(2), Side: "left", Name: "M1"},
+		{Center: pt(3), Side: "right", Name: "right"},
+		{Center: pt(4), Side: "right", Name: "M2"},
+	}
+	back := []EjectorMark{
+		{Center: pt(13), Side: "right", Name: "right"},
+		{Center: pt(11), Side: "left", Name: "left"},
+		{Center: pt(12), Side: "left", Name: "M1"},
+		{Center: pt(19), Side: "left", Name: "left"}, // Duplicate: first wins
+	}
+	frontPts, backPts, labels := MatchEjectorMarks(front, back)
+	if want := []string{"left", "M1", "right"}; !slices.Equal(labels, want) {
+		t.Fatalf("labels = %v, want %v", labels, want)
+	}
+	for i := range labels {
+		if backPts[i].X != frontPts[i].X+10 {
+			t.Errorf("%s: front %v paired with back %v", labels[i], frontPts[i], backPts[i])
+		}
+	}
+}
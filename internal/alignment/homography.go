@@ -0,0 +1,193 @@
+package alignment
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"pcb-tracer/pkg/geometry"
+
+	"gocv.io/x/gocv"
+	"gonum.org/v1/gonum/mat"
+)
+
+// FitHomography fits the least-squares homography mapping each src point
+// onto its dst point. It needs at least 4 pairs, no three of which may be
+// collinear for the fit to be meaningful; with exactly 4 the fit is exact.
+// Points are normalized (centroid at the origin, mean distance √2) before
+// solving so pixel-scale coordinates don't swamp the projective terms.
+func FitHomography(src, dst []geometry.Point2D) (geometry.Homography, error) {
+	if len(src) != len(dst) {
+		return geometry.Homography{}, fmt.Errorf("point count mismatch: %d vs %d", len(src), len(dst))
+	}
+	if len(src) < 4 {
+		return geometry.Homography{}, fmt.Errorf("need at least 4 point pairs, got %d", len(src))
+	}
+
+	tSrc, err := normalizingTransform(src)
+	if err != nil {
+		return geometry.Homography{}, err
+	}
+	tDst, err := normalizingTransform(dst)
+	if err != nil {
+		return geometry.Homography{}, err
+	}
+
+	// h33 = 1; each pair gives two rows of A·h = b
+	n := len(src)
+	A := mat.NewDense(n*2, 8, nil)
+	B := mat.NewVecDense(n*2, nil)
+	for i := 0; i < n; i++ {
+		s := tSrc.Apply(src[i])
+		d := tDst.Apply(dst[i])
+		A.SetRow(i*2, []float64{s.X, s.Y, 1, 0, 0, 0, -d.X * s.X, -d.X * s.Y})
+		B.SetVec(i*2, d.X)
+		A.SetRow(i*2+1, []float64{0, 0, 0, s.X, s.Y, 1, -d.Y * s.X, -d.Y * s.Y})
+		B.SetVec(i*2+1, d.Y)
+	}
+
+	var qr mat.QR
+	qr.Factorize(A)
+	var h mat.VecDense
+	if err := qr.SolveVecTo(&h, false, B); err != nil {
+		return geometry.Homography{}, fmt.Errorf("degenerate point configuration: %w", err)
+	}
+	for i := 0; i < 8; i++ {
+		if v := h.AtVec(i); math.IsNaN(v) || math.IsInf(v, 0) {
+			return geometry.Homography{}, fmt.Errorf("degenerate point configuration")
+		}
+	}
+
+	hn := geometry.Homography{
+		{h.AtVec(0), h.AtVec(1), h.AtVec(2)},
+		{h.AtVec(3), h.AtVec(4), h.AtVec(5)},
+		{h.AtVec(6), h.AtVec(7), 1},
+	}
+	tDstInv, ok := tDst.Inverse()
+	if !ok {
+		return geometry.Homography{}, fmt.Errorf("degenerate point configuration")
+	}
+	H := tDstInv.Compose(hn).Compose(tSrc)
+	if H[2][2] == 0 {
+		return geometry.Homography{}, fmt.Errorf("degenerate point configuration")
+	}
+	for i := range H {
+		for j := range H[i] {
+			H[i][j] /= H[2][2]
+		}
+	}
+	return H, nil
+}
+
+// normalizingTransform returns the similarity that moves pts' centroid to
+// the origin and scales their mean distance from it to √2.
+func normalizingTransform(pts []geometry.Point2D) (geometry.Homography, error) {
+	c := geometry.Centroid(pts)
+	var meanDist float64
+	for _, p := range pts {
+		meanDist += p.Distance(c)
+	}
+	meanDist /= float64(len(pts))
+	if meanDist < 1e-9 {
+		return geometry.Homography{}, fmt.Errorf("points are coincident")
+	}
+	s := math.Sqrt2 / meanDist
+	return geometry.Homography{{s, 0, -s * c.X}, {0, s, -s * c.Y}, {0, 0, 1}}, nil
+}
+
+// HomographyResiduals returns the average and maximum distance between
+// each dst point and its src point mapped through h.
+func HomographyResiduals(src, dst []geometry.Point2D, h geometry.Homography) (avg, maxErr float64) {
+	if len(src) == 0 {
+		return 0, 0
+	}
+	for i := range src {
+		d := dst[i].Distance(h.Apply(src[i]))
+		avg += d
+		maxErr = math.Max(maxErr, d)
+	}
+	return avg / float64(len(src)), maxErr
+}
+
+// WarpPerspective applies a homography to an image.
+func WarpPerspective(src gocv.Mat, h geometry.Homography, width, height int) gocv.Mat {
+	m := gocv.NewMatWithSize(3, 3, gocv.MatTypeCV64F)
+	defer m.Close()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m.SetDoubleAt(i, j, h[i][j])
+		}
+	}
+
+	dst := gocv.NewMat()
+	gocv.WarpPerspectiveWithParams(src, &dst, m, image.Point{width, height},
+		gocv.InterpolationLinear, gocv.BorderConstant, color.RGBA{R: 0, G: 0, B: 0, A: 0})
+	return dst
+}
+
+// WarpPerspectiveGoImage applies a homography to a Go image, producing a
+// width×height output.
+func WarpPerspectiveGoImage(img image.Image, h geometry.Homography, width, height int) (image.Image, error) {
+	mat, err := imageToMat(img)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert image: %w", err)
+	}
+	defer mat.Close()
+
+	warped := WarpPerspective(mat, h, width, height)
+	defer warped.Close()
+
+	return matToImage(warped)
+}
+
+// ContactCornerPairs pairs each front contact with the back contact whose
+// center is nearest, within half the front contact pitch, and returns the
+// four bounding-box corners of every pair as correspondences. The back
+// contacts should already be roughly registered (e.g. translated) to the
+// front.
+func ContactCornerPairs(front, back []Contact) (frontPts, backPts []geometry.Point2D) {
+	if len(front) < 2 || len(back) == 0 {
+		return nil, nil
+	}
+
+	// Pitch: median distance from each front contact to its nearest neighbor
+	var gaps []float64
+	for i, a := range front {
+		best := math.Inf(1)
+		for j, b := range front {
+			if i != j {
+				best = math.Min(best, a.Center.Distance(b.Center))
+			}
+		}
+		gaps = append(gaps, best)
+	}
+	sort.Float64s(gaps)
+	maxDist := gaps[len(gaps)/2] / 2
+
+	used := make([]bool, len(back))
+	for _, f := range front {
+		bi := -1
+		bestDist := maxDist
+		for j, b := range back {
+			if d := f.Center.Distance(b.Center); !used[j] && d < bestDist {
+				bi, bestDist = j, d
+			}
+		}
+		if bi < 0 {
+			continue
+		}
+		used[bi] = true
+		frontPts = append(frontPts, rectCorners(f.Bounds)...)
+		backPts = append(backPts, rectCorners(back[bi].Bounds)...)
+	}
+	return frontPts, backPts
+}
+
+// rectCorners returns r's corners clockwise from the top left.
+func rectCorners(r geometry.RectInt) []geometry.Point2D {
+	x0, y0 := float64(r.X), float64(r.Y)
+	x1, y1 := float64(r.X+r.Width), float64(r.Y+r.Height)
+	return []geometry.Point2D{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+}
@@ -0,0 +1,86 @@
+package alignment
+
+import (
+	"math"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestFitHomography(t *testing.T) {
+	// A phone photo of a tilted 4000×2500 board: the far (right, lower)
+	// edges shrink, moving the bottom-right corner about 8% of the diagonal
+	known := geometry.Homography{
+		{1.01, 0.02, 5},
+		{0.005, 0.99, -3},
+		{2e-5, 1e-5, 1},
+	}
+	corners := []geometry.Point2D{{X: 0, Y: 0}, {X: 4000, Y: 0}, {X: 4000, Y: 2500}, {X: 0, Y: 2500}}
+	src := append(corners[:4:4],
+		geometry.Point2D{X: 400, Y: 100}, geometry.Point2D{X: 1800, Y: 120}, geometry.Point2D{X: 3200, Y: 90},
+		geometry.Point2D{X: 300, Y: 2200}, geometry.Point2D{X: 3700, Y: 2300}, geometry.Point2D{X: 2000, Y: 1250},
+	)
+	apply := func(pts []geometry.Point2D, noise float64) []geometry.Point2D {
+		out := make([]geometry.Point2D, len(pts))
+		for i, p := range pts {
+			q := known.Apply(p)
+			// Deterministic ±noise jitter
+			q.X += noise * float64(i%3-1)
+			q.Y += noise * float64((i+1)%3-1)
+			out[i] = q
+		}
+		return out
+	}
+
+	for _, c := range []struct {
+		name     string
+		src, dst []geometry.Point2D
+		tol      float64 // Largest error, in pixels, mapping any point on the board
+	}{
+		{"exact from 4 corners", corners, apply(corners, 0), 1e-6},
+		{"least squares from 10 pairs", src, apply(src, 0), 1e-6},
+		{"noisy pairs", src, apply(src, 0.5), 1.5},
+	} {
+		h, err := FitHomography(c.src, c.dst)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		for _, p := range append(src, geometry.Point2D{X: 1000, Y: 2000}) {
+			if d := h.Apply(p).Distance(known.Apply(p)); d > c.tol {
+				t.Errorf("%s: %v maps %.3g px from the known keystone", c.name, p, d)
+				break
+			}
+		}
+		if avg, maxErr := HomographyResiduals(c.src, c.dst, h); maxErr > c.tol || avg > maxErr {
+			t.Errorf("%s: residuals avg %.3g max %.3g", c.name, avg, maxErr)
+		}
+	}
+
+	// The keystone is well past an affine fit: the best affine through the
+	// same pairs leaves residuals of many pixels
+	if a, err := FitAffineFromPairs(src, apply(src, 0)); err == nil {
+		var worst float64
+		for _, p := range src {
+			worst = math.Max(worst, a.Apply(p).Distance(known.Apply(p)))
+		}
+		if worst < 20 {
+			t.Errorf("affine fit of the keystone is within %.1f px; the test keystone is too mild", worst)
+		}
+	}
+
+	collinear := []geometry.Point2D{{X: 0, Y: 0}, {X: 100, Y: 100}, {X: 200, Y: 200}, {X: 300, Y: 300}, {X: 400, Y: 400}}
+	for _, c := range []struct {
+		name     string
+		src, dst []geometry.Point2D
+	}{
+		{"collinear", collinear, apply(collinear, 0)},
+		{"too few", corners[:3], apply(corners[:3], 0)},
+		{"count mismatch", src, apply(corners, 0)},
+		{"coincident", []geometry.Point2D{{}, {}, {}, {}}, apply(corners, 0)},
+	} {
+		if _, err := FitHomography(c.src, c.dst); err == nil {
+			t.Errorf("%s: want error", c.name)
+		}
+	}
+}
@@ -274,6 +274,41 @@ func computeAffineFromPoints(src, dst []geometry.Point2D) (geometry.AffineTransf
 	}, nil
 }
 
+// FitAffineFromPairs fits the least-squares affine transform mapping each
+// src point onto its dst point. It needs at least 3 pairs that are not all
+// collinear; with exactly 3 the fit is exact.
+func FitAffineFromPairs(src, dst []geometry.Point2D) (geometry.AffineTransform, error) {
+	if len(src) != len(dst) {
+		return geometry.AffineTransform{}, fmt.Errorf("point count mismatch: %d vs %d", len(src), len(dst))
+	}
+	if len(src) < 3 {
+		return geometry.AffineTransform{}, fmt.Errorf("need at least 3 point pairs, got %d", len(src))
+	}
+
+	// Collinear points leave the affine underdetermined: require some spread
+	// across the narrowest direction (smallest covariance eigenvalue, px²).
+	var mx, my float64
+	for _, p := range src {
+		mx += p.X
+		my += p.Y
+	}
+	n := float64(len(src))
+	mx, my = mx/n, my/n
+	var sxx, syy, sxy float64
+	for _, p := range src {
+		sxx += (p.X - mx) * (p.X - mx)
+		syy += (p.Y - my) * (p.Y - my)
+		sxy += (p.X - mx) * (p.Y - my)
+	}
+	sxx, syy, sxy = sxx/n, syy/n, sxy/n
+	minEigen := (sxx+syy)/2 - math.Sqrt((sxx-syy)*(sxx-syy)/4+sxy*sxy)
+	if minEigen < 1 {
+		return geometry.AffineTransform{}, fmt.Errorf("points are collinear")
+	}
+
+	return computeAffineLeastSquares(src, dst)
+}
+
 // computeAffineLeastSquares computes an affine transform using least squares.
 func computeAffineLeastSquares(src, dst []geometry.Point2D) (geometry.AffineTransform, error) {
 	n := len(src)
@@ -0,0 +1,82 @@
+package alignment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// AlignMeta describes how a saved transform was obtained, so a human can
+// judge whether it is safe to reuse on another scan.
+type AlignMeta struct {
+	DPI         float64 `json:"dpi"`
+	Profile     string  `json:"profile,omitempty"` // Board profile name
+	MatchedVias int     `json:"matched_vias"`
+	AvgError    float64 `json:"avg_error_px"` // Mean via residual after alignment
+	MaxError    float64 `json:"max_error_px"`
+	RMSError    float64 `json:"rms_error_px"`
+}
+
+// alignFile is the on-disk .align format. Rotation, scale and translation
+// are derived from the matrix for readability and are ignored on load.
+type alignFile struct {
+	Matrix      [2][3]float64 `json:"matrix"` // [[a b tx] [c d ty]], back -> front
+	RotationDeg float64       `json:"rotation_deg"`
+	Scale       float64       `json:"scale"`
+	Translation [2]float64    `json:"translation_px"`
+	Meta        AlignMeta     `json:"meta"`
+}
+
+// SaveTransform writes a back-to-front alignment transform and its metadata
+// to a JSON .align file.
+func SaveTransform(path string, t geometry.AffineTransform, meta AlignMeta) error {
+	f := alignFile{
+		Matrix:      t.ToMatrix(),
+		RotationDeg: math.Atan2(t.C, t.A) * 180 / math.Pi,
+		Scale:       math.Hypot(t.A, t.C),
+		Translation: [2]float64{t.TX, t.TY},
+		Meta:        meta,
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal transform: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadTransform reads a transform saved by SaveTransform.
+func LoadTransform(path string) (geometry.AffineTransform, AlignMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return geometry.AffineTransform{}, AlignMeta{}, err
+	}
+	var f alignFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return geometry.AffineTransform{}, AlignMeta{}, fmt.Errorf("unmarshal transform: %w", err)
+	}
+	t := geometry.FromMatrix(f.Matrix)
+	if _, ok := t.Inverse(); !ok {
+		return geometry.AffineTransform{}, AlignMeta{}, fmt.Errorf("%s: transform is singular", path)
+	}
+	return t, f.Meta, nil
+}
+
+// ResidualStats returns the mean, max and RMS distance between each front
+// point and its back point mapped through t.
+func ResidualStats(frontPts, backPts []geometry.Point2D, t geometry.AffineTransform) (avg, maxErr, rms float64) {
+	n := min(len(frontPts), len(backPts))
+	if n == 0 {
+		return 0, 0, 0
+	}
+	var sum, sumSq float64
+	for i := 0; i < n; i++ {
+		d := frontPts[i].Distance(t.Apply(backPts[i]))
+		sum += d
+		sumSq += d * d
+		maxErr = max(maxErr, d)
+	}
+	return sum / float64(n), maxErr, math.Sqrt(sumSq / float64(n))
+}
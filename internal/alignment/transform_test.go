@@ -0,0 +1,69 @@
+package alignment
+
+import (
+	"math"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestFitAffineFromPairs(t *testing.T) {
+	// 1.5° rotation, 2% X stretch, slight shear and an offset
+	theta := 1.5 * math.Pi / 180
+	known := geometry.AffineTransform{
+		A: 1.02 * math.Cos(theta), B: -math.Sin(theta) + 0.003, TX: 35.5,
+		C: math.Sin(theta), D: math.Cos(theta), TY: -12.25,
+	}
+	src := []geometry.Point2D{
+		{X: 100, Y: 100}, {X: 4000, Y: 120}, {X: 3900, Y: 2600},
+		{X: 150, Y: 2500}, {X: 2000, Y: 1300}, {X: 1200, Y: 400},
+	}
+	apply := func(pts []geometry.Point2D, noise float64) []geometry.Point2D {
+		out := make([]geometry.Point2D, len(pts))
+		for i, p := range pts {
+			q := known.Apply(p)
+			// Deterministic ±noise jitter
+			q.X += noise * float64(i%3-1)
+			q.Y += noise * float64((i+1)%3-1)
+			out[i] = q
+		}
+		return out
+	}
+
+	for _, c := range []struct {
+		name     string
+		src, dst []geometry.Point2D
+		tol      float64 // Largest error, in pixels, mapping any src point
+	}{
+		{"exact from 3 pairs", src[:3], apply(src[:3], 0), 1e-6},
+		{"least squares from 6 pairs", src, apply(src, 0), 1e-6},
+		{"noisy pairs", src, apply(src, 0.5), 1},
+	} {
+		got, err := FitAffineFromPairs(c.src, c.dst)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		// Check the fit over the whole board, not just at the pairs
+		for _, p := range append(src, geometry.Point2D{X: 0, Y: 0}, geometry.Point2D{X: 5000, Y: 3000}) {
+			if d := got.Apply(p).Distance(known.Apply(p)); d > c.tol {
+				t.Errorf("%s: %v maps %.3g px from the known transform", c.name, p, d)
+				break
+			}
+		}
+	}
+
+	collinear := []geometry.Point2D{{X: 0, Y: 0}, {X: 100, Y: 100}, {X: 200, Y: 200}, {X: 300, Y: 300}}
+	for _, c := range []struct {
+		name     string
+		src, dst []geometry.Point2D
+	}{
+		{"collinear", collinear, apply(collinear, 0)},
+		{"too few", src[:2], apply(src[:2], 0)},
+		{"count mismatch", src, apply(src[:4], 0)},
+	} {
+		if _, err := FitAffineFromPairs(c.src, c.dst); err == nil {
+			t.Errorf("%s: want error", c.name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package alignment
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"math"
@@ -28,6 +29,40 @@ type ViaAlignmentResult struct {
 	// Band 0 is nearest to connector, band N-1 is farthest.
 	UsedBands []int
 	BandFracs []float64 // The Y-fraction cutoffs used (e.g. [0.25, 0.50, 0.75, 1.0])
+	// Indices into FrontVias of matched via pairs that RANSAC kept (Inliers)
+	// or rejected (Outliers). Contact-edge pairs are not listed.
+	Inliers  []int
+	Outliers []int
+}
+
+// ViaAlignParams tunes the RANSAC outlier rejection in FineAlignViaTranslation.
+type ViaAlignParams struct {
+	RANSACIterations  int     // Number of minimal-sample (3-pair) fits to try
+	InlierThresholdPx float64 // Max residual for a pair to count as an inlier
+}
+
+// DefaultViaAlignParams returns RANSAC settings that suit 600 DPI scans:
+// real matches under the correct affine have < 5px residual.
+func DefaultViaAlignParams() ViaAlignParams {
+	return ViaAlignParams{
+		RANSACIterations:  2000,
+		InlierThresholdPx: 5.0,
+	}
+}
+
+// withDefaults returns p with unset (zero or negative) fields taken from
+// DefaultViaAlignParams. A nil p gives the defaults.
+func (p *ViaAlignParams) withDefaults() ViaAlignParams {
+	params := DefaultViaAlignParams()
+	if p != nil {
+		if p.RANSACIterations > 0 {
+			params.RANSACIterations = p.RANSACIterations
+		}
+		if p.InlierThresholdPx > 0 {
+			params.InlierThresholdPx = p.InlierThresholdPx
+		}
+	}
+	return params
 }
 
 // AlignWithVias performs via-based alignment between front and back images.
@@ -50,7 +85,7 @@ func AlignWithVias(frontImg, backImg image.Image, dpi float64) (*ViaAlignmentRes
 	// Detect vias on front
 	fmt.Printf("AlignWithVias: detecting front vias (DPI=%.0f, minR=%d, maxR=%d)\n",
 		dpi, params.MinRadiusPixels, params.MaxRadiusPixels)
-	frontResult, err := via.DetectViasFromImage(frontImg, pcbimage.SideFront, params)
+	frontResult, err := via.DetectViasFromImage(context.Background(), frontImg, pcbimage.SideFront, params)
 	if err != nil {
 		return nil, fmt.Errorf("front via detection failed: %w", err)
 	}
@@ -58,7 +93,7 @@ func AlignWithVias(frontImg, backImg image.Image, dpi float64) (*ViaAlignmentRes
 
 	// Detect vias on back
 	fmt.Printf("AlignWithVias: detecting back vias\n")
-	backResult, err := via.DetectViasFromImage(backImg, pcbimage.SideBack, params)
+	backResult, err := via.DetectViasFromImage(context.Background(), backImg, pcbimage.SideBack, params)
 	if err != nil {
 		return nil, fmt.Errorf("back via detection failed: %w", err)
 	}
@@ -338,7 +373,7 @@ func detectAndFilterVias(frontImg, backImg image.Image, dpi float64, params via.
 	backBCCh := make(chan detectResult, 1)
 
 	go func() {
-		r, e := via.DetectViasFromImage(frontImg, pcbimage.SideFront, params)
+		r, e := via.DetectViasFromImage(context.Background(), frontImg, pcbimage.SideFront, params)
 		frontStdCh <- detectResult{r, e}
 	}()
 	go func() {
@@ -347,7 +382,7 @@ func detectAndFilterVias(frontImg, backImg image.Image, dpi float64, params via.
 		frontBCCh <- detectResult{r, e}
 	}()
 	go func() {
-		r, e := via.DetectViasFromImage(backImg, pcbimage.SideBack, params)
+		r, e := via.DetectViasFromImage(context.Background(), backImg, pcbimage.SideBack, params)
 		backStdCh <- detectResult{r, e}
 	}()
 	go func() {
@@ -387,11 +422,17 @@ func detectAndFilterVias(frontImg, backImg image.Image, dpi float64, params via.
 	return frontResult, backResult, nil
 }
 
+// FineAlignViaTranslation matches vias (and contact upper edges, if given)
+// between front and coarsely-aligned back images and fits the affine that
+// maps back to front. Mismatched pairs are rejected with RANSAC before the
+// final least-squares fit. alignParams may be nil to use defaults.
 func FineAlignViaTranslation(frontImg, backImg image.Image, dpi float64,
-	frontContacts, backContacts *DetectionResult, coarseTransform geometry.AffineTransform) (*ViaAlignmentResult, error) {
+	frontContacts, backContacts *DetectionResult, coarseTransform geometry.AffineTransform,
+	alignParams *ViaAlignParams) (*ViaAlignmentResult, error) {
 	if frontImg == nil || backImg == nil {
 		return nil, fmt.Errorf("nil image")
 	}
+	ransac := alignParams.withDefaults()
 
 	// Detection parameter levels: start strict, relax if too few matches.
 	// Each level relaxes detection thresholds AND the dense cluster filter,
@@ -789,9 +830,9 @@ func FineAlignViaTranslation(frontImg, backImg image.Image, dpi float64,
 	}
 
 	// Fit affine with RANSAC for robustness — rejects false matches
-	// that survived Hough voting. Threshold 5px: real matches under the
-	// correct affine should have < 5px residual.
-	curTransform, inliers, err := ComputeAffineRANSAC(backPts, frontPts, 2000, 5.0)
+	// that survived Hough voting, then refits on the inliers only.
+	curTransform, inliers, err := ComputeAffineRANSAC(backPts, frontPts,
+		ransac.RANSACIterations, ransac.InlierThresholdPx)
 	if err != nil {
 		return nil, fmt.Errorf("affine fit failed: %w", err)
 	}
@@ -802,10 +843,21 @@ func FineAlignViaTranslation(frontImg, backImg image.Image, dpi float64,
 		inlierSet[idx] = true
 	}
 	var cleanMatches []matchPair
+	var inlierVias, outlierVias []int
 	for i, m := range matches {
 		if inlierSet[i] {
 			cleanMatches = append(cleanMatches, m)
 		}
+		if m.frontIdx < 0 {
+			continue // contact edge pair
+		}
+		if inlierSet[i] {
+			inlierVias = append(inlierVias, m.frontIdx)
+		} else {
+			outlierVias = append(outlierVias, m.frontIdx)
+			fmt.Printf("  RANSAC rejected %-2s F%-3d B%-3d front=(%6.1f,%6.1f)\n",
+				cornerNames[m.corner], m.frontIdx+1, m.backIdx+1, m.front.X, m.front.Y)
+		}
 	}
 
 	// Decompose affine for diagnostics: rotation, X-scale, Y-scale
@@ -877,6 +929,8 @@ func FineAlignViaTranslation(frontImg, backImg image.Image, dpi float64,
 		UsedBackPts:    usedBack,
 		UsedBands:      usedBands,
 		BandFracs:      bandFracs,
+		Inliers:        inlierVias,
+		Outliers:       outlierVias,
 	}, nil
 }
 
@@ -983,8 +1037,42 @@ func CoarseAlignFromContacts(frontResult, backResult *DetectionResult) (geometry
 	fmt.Printf("  raw angles: front=%.2f°, back=%.2f°\n",
 		frontResult.ContactAngle, backResult.ContactAngle)
 
-	// Match contacts between front and back by X coordinate.
-	// After coarse cropping, the contacts should be at similar X positions.
+	frontPts, _ := matchContactsByX(frontResult, backResult)
+	fmt.Printf("  matched %d contact pairs by X\n", len(frontPts))
+
+	// Compute rotation from per-side ContactAngle values.
+	// The contacts are collinear (all on one edge), so dY-vs-X regression
+	// can't detect rotation — grid rescue places them at uniform Y.
+	// Instead, use the angle of the contact line from each side's detection.
+	// The import pipeline processes each image independently, so the images
+	// may have significant rotation differences — no clamping here.
+	frontAngle := frontResult.ContactAngle
+	backAngle := backResult.ContactAngle
+	angleDiff := (frontAngle - backAngle) * math.Pi / 180
+	fmt.Printf("  rotation from contact angles: front=%.2f° - back=%.2f° = %.3f°\n",
+		frontAngle, backAngle, angleDiff*180/math.Pi)
+
+	// Build transform: T(frontAvg) · R(angleDiff) · T(-backAvg)
+	toOrigin := geometry.Translation(-backAvg.X, -backAvg.Y)
+	rotate := geometry.Rotation(angleDiff)
+	toFront := geometry.Translation(frontAvg.X, frontAvg.Y)
+	transform := toFront.Compose(rotate.Compose(toOrigin))
+
+	fmt.Printf("  final: rotation=%.3f°, transform: T(%.1f,%.1f)·R(%.4f°)·T(%.1f,%.1f)\n",
+		angleDiff*180/math.Pi, frontAvg.X, frontAvg.Y,
+		angleDiff*180/math.Pi, -backAvg.X, -backAvg.Y)
+
+	return transform, nil
+}
+
+// matchContactsByX pairs front and back contacts by X position after
+// removing the centroid offset. After coarse cropping the contacts sit at
+// similar X positions, so each front contact takes the nearest unused back
+// contact within about one contact pitch. Returns the paired centers.
+func matchContactsByX(frontResult, backResult *DetectionResult) (frontPts, backPts []geometry.Point2D) {
+	frontAvg := contactCentroid(frontResult.Contacts)
+	backAvg := contactCentroid(backResult.Contacts)
+
 	// Sort both by X, then match nearest within tolerance.
 	type contactByX struct {
 		x, y float64
@@ -1004,16 +1092,10 @@ func CoarseAlignFromContacts(frontResult, backResult *DetectionResult) (geometry
 	// X offset between the two images (from centroid difference)
 	xOffset := frontAvg.X - backAvg.X
 
-	// Match: for each front contact, find nearest back contact (after X offset)
-	type contactMatch struct {
-		frontX, frontY float64
-		backX, backY   float64
-	}
 	xTol := 0.015 * frontResult.DPI // ~9px at 600 DPI — one contact pitch
 	if xTol < 8 {
 		xTol = 8
 	}
-	var matched []contactMatch
 	backUsed := make(map[int]bool)
 	for _, fc := range frontByX {
 		bestDist := math.MaxFloat64
@@ -1032,39 +1114,70 @@ func CoarseAlignFromContacts(frontResult, backResult *DetectionResult) (geometry
 			}
 		}
 		if bestIdx >= 0 {
-			matched = append(matched, contactMatch{
-				frontX: fc.x, frontY: fc.y,
-				backX:  backByX[bestIdx].x, backY: backByX[bestIdx].y,
-			})
+			frontPts = append(frontPts, geometry.Point2D{X: fc.x, Y: fc.y})
+			backPts = append(backPts, geometry.Point2D{X: backByX[bestIdx].x, Y: backByX[bestIdx].y})
 			backUsed[bestIdx] = true
 		}
 	}
+	return frontPts, backPts
+}
 
-	fmt.Printf("  matched %d contact pairs by X (tolerance=%.1f px)\n", len(matched), xTol)
+// MaxCoarseScaleError is the largest departure from unit scale that
+// CoarseAlignAffine accepts. Both scans come from the same scanner, so a
+// larger fitted scale means the contact pairing went wrong.
+const MaxCoarseScaleError = 0.05
+
+// CoarseAlignAffine fits a similarity transform (rotation, uniform scale
+// and translation) mapping the back contacts onto the front ones, paired by
+// X position as in CoarseAlignFromContacts. Grid rescue levels the contacts,
+// so as there the rotation comes from the per-side ContactAngle; the
+// matched pairs then give the scale and translation by least squares. It
+// needs at least 3 matched pairs; callers fall back to
+// CoarseAlignFromContacts when it fails.
+func CoarseAlignAffine(front, back *DetectionResult) (geometry.AffineTransform, error) {
+	if front == nil || back == nil || len(front.Contacts) < 3 || len(back.Contacts) < 3 {
+		return geometry.Identity(), fmt.Errorf("need at least 3 contacts on each side")
+	}
+	frontPts, backPts := matchContactsByX(front, back)
+	if len(frontPts) < 3 {
+		return geometry.Identity(), fmt.Errorf("only %d contact pairs matched (need >= 3)", len(frontPts))
+	}
 
-	// Compute rotation from per-side ContactAngle values.
-	// The contacts are collinear (all on one edge), so dY-vs-X regression
-	// can't detect rotation — grid rescue places them at uniform Y.
-	// Instead, use the angle of the contact line from each side's detection.
-	// The import pipeline processes each image independently, so the images
-	// may have significant rotation differences — no clamping here.
-	frontAngle := frontResult.ContactAngle
-	backAngle := backResult.ContactAngle
-	angleDiff := (frontAngle - backAngle) * math.Pi / 180
-	fmt.Printf("  rotation from contact angles: front=%.2f° - back=%.2f° = %.3f°\n",
-		frontAngle, backAngle, angleDiff*180/math.Pi)
+	// Rotate the back pairs by the contact angle difference, then solve
+	// x' = s·x + tx, y' = s·y + ty about the centroids
+	rotation := front.ContactAngle - back.ContactAngle
+	rotate := geometry.Rotation(rotation * math.Pi / 180)
+	fc := geometry.Centroid(frontPts)
+	bc := rotate.Apply(geometry.Centroid(backPts))
+	var dot, norm float64
+	for i := range frontPts {
+		rb := rotate.Apply(backPts[i])
+		bx, by := rb.X-bc.X, rb.Y-bc.Y
+		dot += bx*(frontPts[i].X-fc.X) + by*(frontPts[i].Y-fc.Y)
+		norm += bx*bx + by*by
+	}
+	if norm < 1e-9 {
+		return geometry.Identity(), fmt.Errorf("matched contacts are coincident")
+	}
+	scale := dot / norm
+	t := geometry.Translation(fc.X-scale*bc.X, fc.Y-scale*bc.Y).
+		Compose(geometry.Scale(scale, scale)).
+		Compose(rotate)
 
-	// Build transform: T(frontAvg) · R(angleDiff) · T(-backAvg)
-	toOrigin := geometry.Translation(-backAvg.X, -backAvg.Y)
-	rotate := geometry.Rotation(angleDiff)
-	toFront := geometry.Translation(frontAvg.X, frontAvg.Y)
-	transform := toFront.Compose(rotate.Compose(toOrigin))
+	if math.Abs(scale-1) > MaxCoarseScaleError {
+		return geometry.Identity(), fmt.Errorf("fitted scale %.4f is implausible", scale)
+	}
 
-	fmt.Printf("  final: rotation=%.3f°, transform: T(%.1f,%.1f)·R(%.4f°)·T(%.1f,%.1f)\n",
-		angleDiff*180/math.Pi, frontAvg.X, frontAvg.Y,
-		angleDiff*180/math.Pi, -backAvg.X, -backAvg.Y)
+	var sumErr, maxErr float64
+	for i := range frontPts {
+		d := frontPts[i].Distance(t.Apply(backPts[i]))
+		sumErr += d
+		maxErr = math.Max(maxErr, d)
+	}
+	fmt.Printf("CoarseAlignAffine: %d pairs, rotation=%.3f° scale=%.5f, residual avg=%.2f max=%.2f px\n",
+		len(frontPts), rotation, scale, sumErr/float64(len(frontPts)), maxErr)
 
-	return transform, nil
+	return t, nil
 }
 
 // contactCentroid computes the centroid of a set of contacts.
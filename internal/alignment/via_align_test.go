@@ -0,0 +1,106 @@
+package alignment
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestViaAlignParamsDefaults(t *testing.T) {
+	def := DefaultViaAlignParams()
+	cases := []struct {
+		name string
+		in   *ViaAlignParams
+		want ViaAlignParams
+	}{
+		{"nil", nil, def},
+		{"zero", &ViaAlignParams{}, def},
+		{"iterations only", &ViaAlignParams{RANSACIterations: 500}, ViaAlignParams{500, def.InlierThresholdPx}},
+		{"threshold only", &ViaAlignParams{InlierThresholdPx: 2.5}, ViaAlignParams{def.RANSACIterations, 2.5}},
+		{"negative", &ViaAlignParams{RANSACIterations: -1, InlierThresholdPx: -3}, def},
+	}
+	for _, c := range cases {
+		if got := c.in.withDefaults(); got != c.want {
+			t.Errorf("%s: %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+// TestViaAlignRANSAC checks the case the RANSAC tunables are for: 2 of 8
+// matched via pairs are about 47px off, and must not skew the fit.
+func TestViaAlignRANSAC(t *testing.T) {
+	known := geometry.AffineTransform{A: 0.9998, B: -0.012, TX: 14.5, C: 0.012, D: 1.0004, TY: -6.25}
+	back := []geometry.Point2D{
+		{X: 200, Y: 150}, {X: 3800, Y: 180}, {X: 3750, Y: 2300}, {X: 250, Y: 2250},
+		{X: 1900, Y: 1200}, {X: 1000, Y: 600}, {X: 2800, Y: 1700}, {X: 600, Y: 1900},
+	}
+	front := make([]geometry.Point2D, len(back))
+	for i, p := range back {
+		front[i] = known.Apply(p)
+	}
+	// Mismatched pairs
+	front[2].X += 40
+	front[2].Y += 25
+	front[5].X -= 25
+	front[5].Y += 40
+
+	params := DefaultViaAlignParams()
+	got, inliers, err := ComputeAffineRANSAC(back, front, params.RANSACIterations, params.InlierThresholdPx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 1, 3, 4, 6, 7}; !slices.Equal(inliers, want) {
+		t.Errorf("inliers %v, want %v", inliers, want)
+	}
+	for _, p := range back {
+		if d := got.Apply(p).Distance(known.Apply(p)); d > 1e-6 {
+			t.Errorf("refit maps %v %.3g px from the true transform", p, d)
+		}
+	}
+
+	// A threshold wider than the mismatch keeps every pair
+	_, inliers, err = ComputeAffineRANSAC(back, front, params.RANSACIterations, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inliers) != len(back) {
+		t.Errorf("60px threshold kept %d pairs, want %d", len(inliers), len(back))
+	}
+}
+
+// TestCoarseAlignAffineRotation fits a contact row whose scans differ by a
+// 1° skew and 1% scale. Grid rescue has levelled both rows, so the skew is
+// only in ContactAngle and must still reach the transform.
+func TestCoarseAlignAffineRotation(t *testing.T) {
+	row := func(n int, x0, y, pitch float64) []Contact {
+		contacts := make([]Contact, n)
+		for i := range contacts {
+			contacts[i].Center = geometry.Point2D{X: x0 + float64(i)*pitch, Y: y}
+		}
+		return contacts
+	}
+	front := &DetectionResult{Contacts: row(20, 500, 2400, 75), DPI: 600, ContactAngle: 0.4}
+	back := &DetectionResult{Contacts: row(20, 480, 2390, 75/1.01), DPI: 600, ContactAngle: -0.6}
+
+	tr, err := CoarseAlignAffine(front, back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rot := math.Atan2(tr.C, tr.A) * 180 / math.Pi; math.Abs(rot-1) > 1e-9 {
+		t.Errorf("rotation %.4f°, want 1° from the contact angles", rot)
+	}
+	if scale := math.Hypot(tr.A, tr.C); math.Abs(scale-1.01) > 0.001 {
+		t.Errorf("scale %.5f, want 1.01", scale)
+	}
+	// The middle of the back row lands on the middle of the front row
+	mid := geometry.Point2D{X: 480 + 9.5*75/1.01, Y: 2390}
+	if d := tr.Apply(mid).Distance(geometry.Point2D{X: 500 + 9.5*75, Y: 2400}); d > 0.01 {
+		t.Errorf("row center maps %.3f px from the front's", d)
+	}
+
+	if _, err := CoarseAlignAffine(front, &DetectionResult{Contacts: row(2, 480, 2390, 75), DPI: 600}); err == nil {
+		t.Error("2 back contacts: want error")
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+const alignmentPresetsFile = "alignment_presets.json"
+
+// SideAlignment is the manual transform of one side.
+type SideAlignment struct {
+	OffsetX        int              `json:"offset_x"`
+	OffsetY        int              `json:"offset_y"`
+	Rotation       float64          `json:"rotation"`
+	RotationCenter geometry.Point2D `json:"rotation_center"`
+	ShearTopX      float64          `json:"shear_top_x"`
+	ShearBottomX   float64          `json:"shear_bottom_x"`
+	ShearLeftY     float64          `json:"shear_left_y"`
+	ShearRightY    float64          `json:"shear_right_y"`
+}
+
+// AlignmentPreset is a named set of manual alignment corrections, reusable
+// across scans of boards of the same type.
+type AlignmentPreset struct {
+	DPI   float64       `json:"dpi"` // DPI the pixel values were captured at
+	Front SideAlignment `json:"front"`
+	Back  SideAlignment `json:"back"`
+}
+
+// alignmentPresets maps board profile name -> preset name -> preset.
+type alignmentPresets map[string]map[string]AlignmentPreset
+
+func alignmentPresetsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine config directory: %w", err)
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "pcb-tracer", alignmentPresetsFile), nil
+}
+
+func loadAlignmentPresets() (alignmentPresets, error) {
+	path, err := alignmentPresetsPath()
+	if err != nil {
+		return nil, err
+	}
+	presets := make(alignmentPresets)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return presets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read alignment presets: %w", err)
+	}
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("cannot parse alignment presets: %w", err)
+	}
+	return presets, nil
+}
+
+func (p alignmentPresets) save() error {
+	path, err := alignmentPresetsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize alignment presets: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write alignment presets: %w", err)
+	}
+	return nil
+}
+
+// boardProfileName returns the key presets are stored under.
+func (s *State) boardProfileName() string {
+	if s.BoardSpec == nil {
+		return ""
+	}
+	return s.BoardSpec.Name()
+}
+
+// AlignmentPresetNames returns the saved preset names for the current board
+// profile, sorted.
+func (s *State) AlignmentPresetNames() []string {
+	presets, err := loadAlignmentPresets()
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return nil
+	}
+	var names []string
+	for name := range presets[s.boardProfileName()] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SaveAlignmentPreset stores the current manual offsets, rotations,
+// rotation centers and shear factors of both sides under name for the
+// current board profile, replacing any preset of that name.
+func (s *State) SaveAlignmentPreset(name string) error {
+	if name == "" {
+		return fmt.Errorf("preset name is empty")
+	}
+	presets, err := loadAlignmentPresets()
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	preset := AlignmentPreset{
+		DPI: s.DPIForSide(image.SideFront),
+		Front: SideAlignment{
+			OffsetX:        s.FrontManualOffset.X,
+			OffsetY:        s.FrontManualOffset.Y,
+			Rotation:       s.FrontManualRotation,
+			RotationCenter: s.FrontRotationCenter,
+			ShearTopX:      s.FrontShearTopX,
+			ShearBottomX:   s.FrontShearBottomX,
+			ShearLeftY:     s.FrontShearLeftY,
+			ShearRightY:    s.FrontShearRightY,
+		},
+		Back: SideAlignment{
+			OffsetX:        s.BackManualOffset.X,
+			OffsetY:        s.BackManualOffset.Y,
+			Rotation:       s.BackManualRotation,
+			RotationCenter: s.BackRotationCenter,
+			ShearTopX:      s.BackShearTopX,
+			ShearBottomX:   s.BackShearBottomX,
+			ShearLeftY:     s.BackShearLeftY,
+			ShearRightY:    s.BackShearRightY,
+		},
+	}
+	profile := s.boardProfileName()
+	s.mu.RUnlock()
+
+	if presets[profile] == nil {
+		presets[profile] = make(map[string]AlignmentPreset)
+	}
+	presets[profile][name] = preset
+	return presets.save()
+}
+
+// LoadAlignmentPreset applies the named preset of the current board profile
+// to the state and to both image layers. Offsets and rotation centers are
+// scaled when the preset was captured at a different DPI. Normalized layers
+// already have their transform baked in and are left alone.
+func (s *State) LoadAlignmentPreset(name string) error {
+	presets, err := loadAlignmentPresets()
+	if err != nil {
+		return err
+	}
+	preset, ok := presets[s.boardProfileName()][name]
+	if !ok {
+		return fmt.Errorf("no alignment preset %q for %s", name, s.boardProfileName())
+	}
+
+	s.mu.Lock()
+	scale := 1.0
+	if dpi := s.DPIForSide(image.SideFront); dpi > 0 && preset.DPI > 0 {
+		scale = dpi / preset.DPI
+	}
+	front := preset.Front.scaled(scale)
+	back := preset.Back.scaled(scale)
+
+	s.FrontManualOffset = geometry.PointInt{X: front.OffsetX, Y: front.OffsetY}
+	s.FrontManualRotation = front.Rotation
+	s.FrontRotationCenter = front.RotationCenter
+	s.FrontShearTopX = front.ShearTopX
+	s.FrontShearBottomX = front.ShearBottomX
+	s.FrontShearLeftY = front.ShearLeftY
+	s.FrontShearRightY = front.ShearRightY
+	s.BackManualOffset = geometry.PointInt{X: back.OffsetX, Y: back.OffsetY}
+	s.BackManualRotation = back.Rotation
+	s.BackRotationCenter = back.RotationCenter
+	s.BackShearTopX = back.ShearTopX
+	s.BackShearBottomX = back.ShearBottomX
+	s.BackShearLeftY = back.ShearLeftY
+	s.BackShearRightY = back.ShearRightY
+
+	if s.FrontImage != nil && !s.FrontImage.IsNormalized {
+		front.applyTo(s.FrontImage)
+	}
+	if s.BackImage != nil && !s.BackImage.IsNormalized {
+		back.applyTo(s.BackImage)
+	}
+	s.mu.Unlock()
+
+	s.SetModified(true)
+	return nil
+}
+
+// scaled returns a with its pixel quantities multiplied by k.
+func (a SideAlignment) scaled(k float64) SideAlignment {
+	a.OffsetX = int(math.Round(float64(a.OffsetX) * k))
+	a.OffsetY = int(math.Round(float64(a.OffsetY) * k))
+	a.RotationCenter = geometry.Point2D{X: a.RotationCenter.X * k, Y: a.RotationCenter.Y * k}
+	return a
+}
+
+func (a SideAlignment) applyTo(layer *image.Layer) {
+	layer.ManualOffsetX = a.OffsetX
+	layer.ManualOffsetY = a.OffsetY
+	layer.ManualRotation = a.Rotation
+	layer.RotationCenterX = a.RotationCenter.X
+	layer.RotationCenterY = a.RotationCenter.Y
+	layer.ShearTopX = a.ShearTopX
+	layer.ShearBottomX = a.ShearBottomX
+	layer.ShearLeftY = a.ShearLeftY
+	layer.ShearRightY = a.ShearRightY
+	layer.EnsureShearDefaults()
+}
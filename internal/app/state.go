@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	goimage "image"
-	"image/png"
 	"math"
 	"os"
 	"path/filepath"
@@ -16,6 +15,7 @@ import (
 	"pcb-tracer/internal/board"
 	"pcb-tracer/internal/component"
 	"pcb-tracer/internal/connector"
+	"pcb-tracer/internal/datecode"
 	"pcb-tracer/internal/features"
 	"pcb-tracer/internal/image"
 	"pcb-tracer/internal/logo"
@@ -41,14 +41,27 @@ type State struct {
 	FrontImage *image.Layer
 	BackImage  *image.Layer
 
+	// Page of the back image's file holding the back scan, for both sides
+	// imported from one multi-page TIFF (0 = first page)
+	BackImagePage int
+
+	// DPI each side's scan was resampled to when the sides' resolutions
+	// differed (0 = native) - persisted to project file and reapplied
+	// when the scan is reloaded
+	FrontResampleDPI float64
+	BackResampleDPI  float64
+
 	// Alignment
 	Aligned              bool
 	AlignedFront         *image.Layer
 	AlignedBack          *image.Layer
 	AlignmentError       float64
+	BackRescaleFactor    float64 // Scale applied to back image to match front DPI (0 = none), reapplied on reload
 	DPI                  float64
 	FrontDetectionResult *alignment.DetectionResult
 	BackDetectionResult  *alignment.DetectionResult
+	FrontExtraDetections []*alignment.DetectionResult // Extra contact groups, in BoardSpec.ContactGroups()[1:] order
+	BackExtraDetections  []*alignment.DetectionResult
 	FrontBoardBounds     *geometry.RectInt
 	BackBoardBounds      *geometry.RectInt
 
@@ -98,6 +111,10 @@ type State struct {
 	// Components
 	Components []*component.Component
 
+	// Board era: years component date codes may decode to (zero = any
+	// plausible year) - persisted to project file
+	DateCodeYears datecode.YearRange
+
 	// Component detection training set
 	ComponentTraining *component.TrainingSet
 
@@ -110,6 +127,9 @@ type State struct {
 	// Via detection color parameters (nil = use defaults)
 	ViaColorParams *ColorParams
 
+	// Auto-trace cost weights (nil = use defaults) - persisted to project file
+	AutoTraceParams *trace.AutoTraceParams
+
 	// Via training set for machine learning
 	ViaTrainingSet *via.TrainingSet
 
@@ -281,13 +301,35 @@ func (s *State) SaveGlobalOCRTraining() error {
 	return ocr.SaveGlobalTraining(db)
 }
 
+// PruneOCRTraining prunes the global OCR training database with opts and
+// saves it if anything was removed.
+func (s *State) PruneOCRTraining(opts ocr.PruneOptions) (ocr.PruneStats, error) {
+	s.mu.Lock()
+	db := s.GlobalOCRTraining
+	if db == nil {
+		s.mu.Unlock()
+		return ocr.PruneStats{}, nil
+	}
+	stats := db.Prune(opts)
+	s.mu.Unlock()
+
+	if stats.Total() == 0 {
+		return stats, nil
+	}
+	return stats, s.SaveGlobalOCRTraining()
+}
+
 // AddOCRTrainingSample adds a sample to the global OCR training database.
-func (s *State) AddOCRTrainingSample(groundTruth, detected string, score float64, orientation string, params ocr.OCRParams) {
+// manufacturer and pkg describe the part when known, so later reads of the
+// same kind of marking can use its params.
+func (s *State) AddOCRTrainingSample(groundTruth, detected string, score float64, orientation string, params ocr.OCRParams, manufacturer, pkg string) {
 	s.mu.Lock()
 	if s.GlobalOCRTraining == nil {
 		s.GlobalOCRTraining = ocr.NewGlobalTrainingDB()
 	}
 	sample := ocr.CreateSampleFromResult(groundTruth, detected, score, orientation, params)
+	sample.Manufacturer = manufacturer
+	sample.Package = pkg
 	s.GlobalOCRTraining.AddSample(sample)
 	s.mu.Unlock()
 
@@ -355,6 +397,7 @@ func (s *State) LoadProject(path string) error {
 	if err := json.Unmarshal(data, &proj); err != nil {
 		return err
 	}
+	proj.migrateShear()
 
 	// Clear all per-project state before loading new project
 	s.ResetForNewProject()
@@ -371,7 +414,16 @@ func (s *State) LoadProject(path string) error {
 	// Store alignment data
 	s.Aligned = proj.Aligned
 	s.AlignmentError = proj.AlignmentError
+	s.BackRescaleFactor = proj.BackRescaleFactor
+	s.BackImagePage = proj.BackImagePage
+	s.FrontResampleDPI = proj.FrontResampleDPI
+	s.BackResampleDPI = proj.BackResampleDPI
 	s.DPI = proj.DPI
+	s.AutoTraceParams = proj.AutoTraceParams
+	s.DateCodeYears = datecode.YearRange{}
+	if proj.DateCodeYears != nil {
+		s.DateCodeYears = *proj.DateCodeYears
+	}
 
 	// Restore manual offsets
 	s.FrontManualOffset = proj.FrontManualOffset
@@ -500,18 +552,7 @@ func (s *State) LoadProject(path string) error {
 		s.FrontImage.RotationCenterX = s.FrontRotationCenter.X
 		s.FrontImage.RotationCenterY = s.FrontRotationCenter.Y
 		// Ensure default values if not set
-		if s.FrontImage.ShearTopX == 0 {
-			s.FrontImage.ShearTopX = 1.0
-		}
-		if s.FrontImage.ShearBottomX == 0 {
-			s.FrontImage.ShearBottomX = 1.0
-		}
-		if s.FrontImage.ShearLeftY == 0 {
-			s.FrontImage.ShearLeftY = 1.0
-		}
-		if s.FrontImage.ShearRightY == 0 {
-			s.FrontImage.ShearRightY = 1.0
-		}
+		s.FrontImage.EnsureShearDefaults()
 		if s.FrontImage.AutoScaleX == 0 {
 			s.FrontImage.AutoScaleX = 1.0
 		}
@@ -536,18 +577,7 @@ func (s *State) LoadProject(path string) error {
 		s.BackImage.RotationCenterX = s.BackRotationCenter.X
 		s.BackImage.RotationCenterY = s.BackRotationCenter.Y
 		// Ensure default values if not set
-		if s.BackImage.ShearTopX == 0 {
-			s.BackImage.ShearTopX = 1.0
-		}
-		if s.BackImage.ShearBottomX == 0 {
-			s.BackImage.ShearBottomX = 1.0
-		}
-		if s.BackImage.ShearLeftY == 0 {
-			s.BackImage.ShearLeftY = 1.0
-		}
-		if s.BackImage.ShearRightY == 0 {
-			s.BackImage.ShearRightY = 1.0
-		}
+		s.BackImage.EnsureShearDefaults()
 		if s.BackImage.AutoScaleX == 0 {
 			s.BackImage.AutoScaleX = 1.0
 		}
@@ -556,8 +586,16 @@ func (s *State) LoadProject(path string) error {
 		}
 	}
 
-	// Saved contacts are in pre-alignment coordinates and are not restored.
-	// The user must click "Detect Contacts" to re-detect on aligned images.
+	// Restore contact detection only onto the images it was run on; after
+	// a rescan or normalization the user must click "Detect Contacts" again.
+	// The legacy FrontContacts/BackContacts are not restored.
+	s.FrontDetectionResult, s.FrontExtraDetections = proj.FrontDetection.restore(s.FrontImage, "front")
+	s.BackDetectionResult, s.BackExtraDetections = proj.BackDetection.restore(s.BackImage, "back")
+
+	// Restore sampled detection colors
+	s.FrontColorParams = proj.FrontColorParams
+	s.BackColorParams = proj.BackColorParams
+	s.ViaColorParams = proj.ViaColorParams
 
 	// Restore component training samples
 	if len(proj.ComponentTrainingSamples) > 0 {
@@ -624,6 +662,13 @@ func (s *State) LoadProject(path string) error {
 		fmt.Printf("[Project] Restored %d nets\n", len(proj.Nets))
 	}
 
+	// Drop references left dangling by a partial save before nets are used
+	if s.FeaturesLayer != nil {
+		if issues := s.FeaturesLayer.Repair(); len(issues) > 0 {
+			fmt.Printf("[Project] Repaired %d feature reference issues\n", len(issues))
+		}
+	}
+
 	// Logo library is now loaded from shared preferences, not project file
 	// Legacy project files with logos are ignored - logos are shared across all projects
 	s.mu.Unlock()
@@ -647,13 +692,40 @@ func (s *State) LoadProject(path string) error {
 
 // SaveProject saves the project to the specified path.
 func (s *State) SaveProject(path string) error {
+	data, err := s.MarshalProject(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.ProjectPath = path
+	s.Modified = false
+	s.mu.Unlock()
+
+	s.Emit(EventProjectSaved, path)
+	return nil
+}
+
+// MarshalProject serializes the project as SaveProject would write it to
+// path (image paths relative to path's directory) without writing it or
+// touching the project path and modified flag.
+func (s *State) MarshalProject(path string) ([]byte, error) {
 	s.mu.RLock()
 	proj := ProjectFile{
 		Version:           3,
 		BoardType:         s.BoardSpec.Name(),
 		Aligned:           s.Aligned,
 		AlignmentError:    s.AlignmentError,
+		BackRescaleFactor: s.BackRescaleFactor,
+		BackImagePage:     s.BackImagePage,
+		FrontResampleDPI:  s.FrontResampleDPI,
+		BackResampleDPI:   s.BackResampleDPI,
 		DPI:               s.DPI,
+		AutoTraceParams:   s.AutoTraceParams,
 		FrontManualOffset: s.FrontManualOffset,
 		BackManualOffset:  s.BackManualOffset,
 		// Rotation and shear
@@ -690,6 +762,17 @@ func (s *State) SaveProject(path string) error {
 		ViewScrollX: s.ViewScrollX,
 		ViewScrollY: s.ViewScrollY,
 	}
+	if s.DateCodeYears != (datecode.YearRange{}) {
+		years := s.DateCodeYears
+		proj.DateCodeYears = &years
+	}
+
+	// Serialize detection results with the images they apply to
+	proj.FrontDetection = saveDetection(s.FrontImage, s.FrontDetectionResult, s.FrontExtraDetections)
+	proj.BackDetection = saveDetection(s.BackImage, s.BackDetectionResult, s.BackExtraDetections)
+	proj.FrontColorParams = s.FrontColorParams
+	proj.BackColorParams = s.BackColorParams
+	proj.ViaColorParams = s.ViaColorParams
 
 	// Serialize contacts from detection results
 	if s.FrontDetectionResult != nil {
@@ -763,22 +846,7 @@ func (s *State) SaveProject(path string) error {
 	}
 	s.mu.RUnlock()
 
-	data, err := json.MarshalIndent(proj, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return err
-	}
-
-	s.mu.Lock()
-	s.ProjectPath = path
-	s.Modified = false
-	s.mu.Unlock()
-
-	s.Emit(EventProjectSaved, path)
-	return nil
+	return json.MarshalIndent(proj, "", "  ")
 }
 
 // ImportFrontImage loads the front image, straightens it, and crops to board bounds.
@@ -787,9 +855,16 @@ func (s *State) ImportFrontImage(path string) error {
 	if err != nil {
 		return err
 	}
+	s.importFrontLayer(layer)
+	return nil
+}
+
+// importFrontLayer straightens and crops a loaded front scan and makes it
+// the front image.
+func (s *State) importFrontLayer(layer *image.Layer) {
 	layer.Side = image.SideFront
 	fmt.Printf("ImportFrontImage: loaded %dx%d from %s\n",
-		layer.Image.Bounds().Dx(), layer.Image.Bounds().Dy(), path)
+		layer.Image.Bounds().Dx(), layer.Image.Bounds().Dy(), layer.Path)
 
 	// Detect board rotation angle and bounds
 	result := alignment.DetectBoardRotationFromImage(layer.Image)
@@ -843,8 +918,10 @@ func (s *State) ImportFrontImage(path string) error {
 	s.FrontImage = layer
 	s.FrontImportRotation = angle
 	s.FrontCropBounds = geometry.RectInt{}
+	s.FrontResampleDPI = 0
 	s.FrontBoardBounds = nil
 	s.FrontDetectionResult = nil
+	s.FrontExtraDetections = nil
 	s.Aligned = false
 	s.AlignedFront = nil
 	if layer.DPI > 0 && s.DPI == 0 {
@@ -854,7 +931,6 @@ func (s *State) ImportFrontImage(path string) error {
 
 	s.SetModified(true)
 	s.Emit(EventImageLoaded, layer)
-	return nil
 }
 
 // LoadFrontImage loads the front side image using saved crop bounds from project.
@@ -870,6 +946,7 @@ func (s *State) LoadFrontImage(path string) error {
 	cropBounds := s.FrontCropBounds
 	importRotation := s.FrontImportRotation
 	autoRotation := s.FrontAutoRotation
+	resampleDPI := s.FrontResampleDPI
 	s.mu.Unlock()
 
 	if cropBounds.Width > 0 && cropBounds.Height > 0 {
@@ -906,6 +983,14 @@ func (s *State) LoadFrontImage(path string) error {
 		s.mu.Unlock()
 	}
 
+	// Reapply a resample to the other side's DPI; saved offsets are
+	// already in resampled pixels
+	if resampleDPI > 0 {
+		if err := layer.ResampleToDPI(resampleDPI); err != nil {
+			fmt.Printf("LoadFrontImage: resample to %.0f DPI failed: %v\n", resampleDPI, err)
+		}
+	}
+
 	s.mu.Lock()
 	s.FrontImage = layer
 	s.FrontBoardBounds = nil
@@ -924,9 +1009,16 @@ func (s *State) ImportBackImage(path string) error {
 	if err != nil {
 		return err
 	}
+	s.importBackLayer(layer)
+	return nil
+}
+
+// importBackLayer flips, straightens, and crops a loaded back scan and
+// makes it the back image.
+func (s *State) importBackLayer(layer *image.Layer) {
 	layer.Side = image.SideBack
-	fmt.Printf("ImportBackImage: loaded %dx%d from %s\n",
-		layer.Image.Bounds().Dx(), layer.Image.Bounds().Dy(), path)
+	fmt.Printf("ImportBackImage: loaded %dx%d from %s page %d\n",
+		layer.Image.Bounds().Dx(), layer.Image.Bounds().Dy(), layer.Path, layer.Page)
 
 	// Flip horizontally — back is viewed from the other side
 	layer.Image = flipHorizontal(layer.Image)
@@ -983,10 +1075,14 @@ func (s *State) ImportBackImage(path string) error {
 	s.BackImage = layer
 	s.BackImportRotation = angle
 	s.BackCropBounds = geometry.RectInt{}
+	s.BackResampleDPI = 0
+	s.BackRescaleFactor = 0
 	s.BackBoardBounds = nil
 	s.BackDetectionResult = nil
+	s.BackExtraDetections = nil
 	s.Aligned = false
 	s.AlignedBack = nil
+	s.BackImagePage = layer.Page
 	if layer.DPI > 0 && s.DPI == 0 {
 		s.DPI = layer.DPI
 	}
@@ -994,13 +1090,35 @@ func (s *State) ImportBackImage(path string) error {
 
 	s.SetModified(true)
 	s.Emit(EventImageLoaded, layer)
-	return nil
+}
+
+// ImportMultiPageImage imports both sides from one multi-page TIFF: page 0
+// as the front and page 1 as the back, each with its own DPI. A single-page
+// file imports as the front only. Returns the number of sides imported.
+func (s *State) ImportMultiPageImage(path string) (int, error) {
+	pages, err := image.LoadMultiPage(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(pages) > 2 {
+		fmt.Printf("ImportMultiPageImage: %s has %d pages, using the first two\n", path, len(pages))
+	}
+
+	s.importFrontLayer(pages[0])
+	if len(pages) < 2 {
+		return 1, nil
+	}
+	s.importBackLayer(pages[1])
+	return 2, nil
 }
 
 // LoadBackImage loads the back side image using saved crop bounds from project.
 // Falls back to auto-detection if no saved bounds are available.
 func (s *State) LoadBackImage(path string) error {
-	layer, err := image.Load(path)
+	s.mu.RLock()
+	page := s.BackImagePage
+	s.mu.RUnlock()
+	layer, err := image.LoadPage(path, page)
 	if err != nil {
 		return err
 	}
@@ -1010,6 +1128,8 @@ func (s *State) LoadBackImage(path string) error {
 	cropBounds := s.BackCropBounds
 	importRotation := s.BackImportRotation
 	autoRotation := s.BackAutoRotation
+	resampleDPI := s.BackResampleDPI
+	rescaleFactor := s.BackRescaleFactor
 	s.mu.Unlock()
 
 	if cropBounds.Width > 0 && cropBounds.Height > 0 {
@@ -1048,6 +1168,19 @@ func (s *State) LoadBackImage(path string) error {
 	// Flip horizontally - back is viewed from the other side
 	layer.Image = flipHorizontal(layer.Image)
 
+	// Reapply a resample to the other side's DPI; saved offsets are
+	// already in resampled pixels
+	if resampleDPI > 0 {
+		if err := layer.ResampleToDPI(resampleDPI); err != nil {
+			fmt.Printf("LoadBackImage: resample to %.0f DPI failed: %v\n", resampleDPI, err)
+		}
+	}
+
+	// Reapply the rescale auto-align made to match the front's contact pitch
+	if rescaleFactor > 0 {
+		layer.Image = alignment.RescaleToMatch(layer.Image, rescaleFactor)
+	}
+
 	s.mu.Lock()
 	s.BackImage = layer
 	s.BackBoardBounds = nil
@@ -1069,6 +1202,9 @@ func (s *State) ResetForNewProject() {
 	// Clear images
 	s.FrontImage = nil
 	s.BackImage = nil
+	s.BackImagePage = 0
+	s.FrontResampleDPI = 0
+	s.BackResampleDPI = 0
 	s.AlignedFront = nil
 	s.AlignedBack = nil
 
@@ -1079,6 +1215,8 @@ func (s *State) ResetForNewProject() {
 	// Clear alignment state
 	s.Aligned = false
 	s.AlignmentError = 0
+	s.BackRescaleFactor = 0
+	s.AutoTraceParams = nil
 
 	// Zero all manual alignment settings
 	s.FrontManualOffset = geometry.PointInt{}
@@ -1113,6 +1251,8 @@ func (s *State) ResetForNewProject() {
 	// Clear detection results
 	s.FrontDetectionResult = nil
 	s.BackDetectionResult = nil
+	s.FrontExtraDetections = nil
+	s.BackExtraDetections = nil
 	s.FrontBoardBounds = nil
 	s.BackBoardBounds = nil
 
@@ -1147,6 +1287,9 @@ func (s *State) ResetForNewProject() {
 
 	// Clear sticky OCR orientation
 	s.LastOCROrientation = ""
+
+	// Clear board era
+	s.DateCodeYears = datecode.YearRange{}
 }
 
 // LoadRawFrontImage loads the front side image without any processing.
@@ -1175,7 +1318,10 @@ func (s *State) LoadRawFrontImage(path string) error {
 // No auto-detection, cropping, or rotation is applied.
 // The horizontal flip IS applied since it's a viewing requirement, not alignment.
 func (s *State) LoadRawBackImage(path string) error {
-	layer, err := image.Load(path)
+	s.mu.RLock()
+	page := s.BackImagePage
+	s.mu.RUnlock()
+	layer, err := image.LoadPage(path, page)
 	if err != nil {
 		return err
 	}
@@ -1198,8 +1344,10 @@ func (s *State) LoadRawBackImage(path string) error {
 	return nil
 }
 
-// NormalizeFrontImage bakes all transforms into a flat PNG and saves it.
-func (s *State) NormalizeFrontImage(projectDir string) error {
+// NormalizeFrontImage bakes all transforms into a flat PNG, tagged with its
+// DPI, and saves it as the project's front image. Unless opts are the
+// defaults, a copy is also exported at opts' resolution and format.
+func (s *State) NormalizeFrontImage(projectDir string, opts image.ExportOptions) error {
 	s.mu.Lock()
 	if s.FrontImage == nil {
 		s.mu.Unlock()
@@ -1217,15 +1365,23 @@ func (s *State) NormalizeFrontImage(projectDir string) error {
 	s.FrontImage.ShearLeftY = s.FrontShearLeftY
 	s.FrontImage.ShearRightY = s.FrontShearRightY
 
-	normalized, _ := s.FrontImage.Normalize()
+	normalized, _ := s.FrontImage.Normalize(image.ResampleOptions{Bilinear: true})
 	relName := normalizedFilename(s.ProjectPath, "front")
+	dpi := s.DPIForSide(image.SideFront)
 	s.mu.Unlock()
 
 	// Save PNG
 	normPath := filepath.Join(projectDir, relName)
-	if err := saveNormalizedPNG(normalized, normPath); err != nil {
+	if err := image.Export(normalized, dpi, normPath, image.DefaultExportOptions()); err != nil {
 		return fmt.Errorf("failed to save normalized front image: %w", err)
 	}
+	if !opts.IsDefault(dpi) {
+		exportPath := filepath.Join(projectDir, exportFilename(relName, opts))
+		if err := image.Export(normalized, dpi, exportPath, opts); err != nil {
+			return fmt.Errorf("failed to export normalized front image: %w", err)
+		}
+		fmt.Printf("Front image exported to %s\n", exportPath)
+	}
 
 	s.mu.Lock()
 	// Replace layer image
@@ -1261,8 +1417,10 @@ func (s *State) NormalizeFrontImage(projectDir string) error {
 	return nil
 }
 
-// NormalizeBackImage bakes all transforms into a flat PNG and saves it.
-func (s *State) NormalizeBackImage(projectDir string) error {
+// NormalizeBackImage bakes all transforms into a flat PNG, tagged with its
+// DPI, and saves it as the project's back image. Unless opts are the
+// defaults, a copy is also exported at opts' resolution and format.
+func (s *State) NormalizeBackImage(projectDir string, opts image.ExportOptions) error {
 	s.mu.Lock()
 	if s.BackImage == nil {
 		s.mu.Unlock()
@@ -1280,15 +1438,23 @@ func (s *State) NormalizeBackImage(projectDir string) error {
 	s.BackImage.ShearLeftY = s.BackShearLeftY
 	s.BackImage.ShearRightY = s.BackShearRightY
 
-	normalized, _ := s.BackImage.Normalize()
+	normalized, _ := s.BackImage.Normalize(image.ResampleOptions{Bilinear: true})
 	relName := normalizedFilename(s.ProjectPath, "back")
+	dpi := s.DPIForSide(image.SideBack)
 	s.mu.Unlock()
 
 	// Save PNG
 	normPath := filepath.Join(projectDir, relName)
-	if err := saveNormalizedPNG(normalized, normPath); err != nil {
+	if err := image.Export(normalized, dpi, normPath, image.DefaultExportOptions()); err != nil {
 		return fmt.Errorf("failed to save normalized back image: %w", err)
 	}
+	if !opts.IsDefault(dpi) {
+		exportPath := filepath.Join(projectDir, exportFilename(relName, opts))
+		if err := image.Export(normalized, dpi, exportPath, opts); err != nil {
+			return fmt.Errorf("failed to export normalized back image: %w", err)
+		}
+		fmt.Printf("Back image exported to %s\n", exportPath)
+	}
 
 	s.mu.Lock()
 	// Replace layer image
@@ -1334,16 +1500,15 @@ func normalizedFilename(projectPath, side string) string {
 	return name + "_" + side + "_normalized.png"
 }
 
-// saveNormalizedPNG writes an image to a PNG file with best compression.
-func saveNormalizedPNG(img goimage.Image, path string) error {
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+// exportFilename returns the name of an exported copy of the normalized
+// image relName, e.g. "dtc-scsi_front_normalized_1200dpi.tif", or
+// "dtc-scsi_front_normalized.jpg" at the source resolution.
+func exportFilename(relName string, opts image.ExportOptions) string {
+	name := strings.TrimSuffix(relName, filepath.Ext(relName))
+	if opts.DPI > 0 {
+		name += fmt.Sprintf("_%.0fdpi", opts.DPI)
 	}
-	defer f.Close()
-
-	encoder := &png.Encoder{CompressionLevel: png.BestCompression}
-	return encoder.Encode(f, img)
+	return name + opts.Ext()
 }
 
 // LoadNormalizedImage loads a pre-normalized PNG into a layer.
@@ -1372,23 +1537,93 @@ func (s *State) LoadNormalizedImage(layer *image.Layer, path string) error {
 	layer.ShearLeftY = 1.0
 	layer.ShearRightY = 1.0
 
-	// Preserve DPI: try state first, then extract from original TIFF if available
-	if s.DPI > 0 {
+	// Preserve DPI: prefer the normalized PNG's pHYs chunk, then state, then
+	// the original image metadata
+	layer.DPIAssumed = false
+	if dpi, err := image.ExtractDPI(path); err == nil && dpi > 0 {
+		layer.DPI = dpi
+	} else if s.DPI > 0 {
 		layer.DPI = s.DPI
+		layer.DPIAssumed = true
 	} else if layer.Path != "" {
-		ext := strings.ToLower(filepath.Ext(layer.Path))
-		if ext == ".tiff" || ext == ".tif" {
-			if dpi, err := image.ExtractTIFFDPI(layer.Path); err == nil && dpi > 0 {
-				layer.DPI = dpi
-				fmt.Printf("Extracted DPI %.0f from original TIFF: %s\n", dpi, layer.Path)
-			}
+		if dpi, err := image.ExtractDPI(layer.Path); err == nil && dpi > 0 {
+			layer.DPI = dpi
+			fmt.Printf("Extracted DPI %.0f from original image: %s\n", dpi, layer.Path)
+		} else {
+			layer.DPIAssumed = true
 		}
+	} else {
+		layer.DPIAssumed = true
 	}
 
 	fmt.Printf("Loaded normalized image: %s (%dx%d, DPI=%.0f)\n", path, img.Bounds().Dx(), img.Bounds().Dy(), layer.DPI)
 	return nil
 }
 
+// ResampleSideToDPI rescales one side's image to target DPI so both sides
+// share a resolution, and makes target the common DPI. The side's manual
+// offset and rotation center are scaled with the image, and target is
+// remembered so the scan is resampled again when the project is reloaded.
+func (s *State) ResampleSideToDPI(side image.Side, target float64) error {
+	s.mu.Lock()
+	layer := s.FrontImage
+	offset, center, resample := &s.FrontManualOffset, &s.FrontRotationCenter, &s.FrontResampleDPI
+	if side == image.SideBack {
+		layer = s.BackImage
+		offset, center, resample = &s.BackManualOffset, &s.BackRotationCenter, &s.BackResampleDPI
+	}
+	if layer == nil {
+		s.mu.Unlock()
+		return fmt.Errorf("no %s image loaded", side)
+	}
+	k := target / layer.DPI
+	if err := layer.ResampleToDPI(target); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	offset.X = int(math.Round(float64(offset.X) * k))
+	offset.Y = int(math.Round(float64(offset.Y) * k))
+	center.X *= k
+	center.Y *= k
+	*resample = target
+	s.DPI = target
+	s.mu.Unlock()
+
+	s.SetModified(true)
+	return nil
+}
+
+// DateCodeRange returns the years component date codes may decode to: the
+// board era if one is set, otherwise datecode.DefaultYearRange.
+func (s *State) DateCodeRange() datecode.YearRange {
+	if s.DateCodeYears != (datecode.YearRange{}) {
+		return s.DateCodeYears
+	}
+	return datecode.DefaultYearRange()
+}
+
+// DPIForSide returns the resolution of the given side's image. The front
+// uses the project DPI, falling back to the front scan's own DPI. The back
+// uses its own scan DPI until alignment, after which it has been resampled
+// into front pixel space and shares the front's resolution; an unaligned
+// back with no scan DPI also falls back to the front's. Returns 0 if
+// unknown.
+func (s *State) DPIForSide(side image.Side) float64 {
+	if side == image.SideBack && !s.Aligned && s.BackImage != nil && s.BackImage.DPI > 0 {
+		return s.BackImage.DPI
+	}
+	if s.DPI > 0 {
+		return s.DPI
+	}
+	if s.FrontImage != nil && s.FrontImage.DPI > 0 {
+		return s.FrontImage.DPI
+	}
+	if side == image.SideBack && s.BackImage != nil {
+		return s.BackImage.DPI
+	}
+	return 0
+}
+
 // HasNormalizedImages returns true if at least one layer has been normalized.
 func (s *State) HasNormalizedImages() bool {
 	return s.FrontNormalizedPath != "" || s.BackNormalizedPath != ""
@@ -1547,10 +1782,18 @@ type ProjectFile struct {
 	BoardType      string  `json:"board_type"`
 	FrontImagePath string  `json:"front_image,omitempty"`
 	BackImagePath  string  `json:"back_image,omitempty"`
+	BackImagePage  int     `json:"back_image_page,omitempty"` // Page of BackImagePath (multi-page TIFF)
 	Aligned        bool    `json:"aligned"`
 	AlignmentError float64 `json:"alignment_error,omitempty"`
 	DPI            float64 `json:"dpi,omitempty"`
 
+	// Scale applied to the back image during auto-align to match front DPI
+	BackRescaleFactor float64 `json:"back_rescale_factor,omitempty"`
+
+	// DPI a side's scan was resampled to after loading (0 = native)
+	FrontResampleDPI float64 `json:"front_resample_dpi,omitempty"`
+	BackResampleDPI  float64 `json:"back_resample_dpi,omitempty"`
+
 	// Manual alignment offsets (v2+)
 	FrontManualOffset geometry.PointInt `json:"front_offset,omitempty"`
 	BackManualOffset  geometry.PointInt `json:"back_offset,omitempty"`
@@ -1594,6 +1837,15 @@ type ProjectFile struct {
 	FrontContacts []ContactData `json:"front_contacts,omitempty"`
 	BackContacts  []ContactData `json:"back_contacts,omitempty"`
 
+	// Full contact detection results, restored only onto the same images
+	FrontDetection *SavedDetection `json:"front_detection,omitempty"`
+	BackDetection  *SavedDetection `json:"back_detection,omitempty"`
+
+	// Sampled HSV colors for contact and via detection
+	FrontColorParams *ColorParams `json:"front_color_params,omitempty"`
+	BackColorParams  *ColorParams `json:"back_color_params,omitempty"`
+	ViaColorParams   *ColorParams `json:"via_color_params,omitempty"`
+
 	// External data file paths (legacy - use inline fields when possible)
 	ComponentsPath string `json:"components_path,omitempty"`
 	TracesPath     string `json:"traces_path,omitempty"`
@@ -1619,6 +1871,9 @@ type ProjectFile struct {
 	// Traces (v10+)
 	Traces []trace.ExtendedTrace `json:"traces,omitempty"`
 
+	// Auto-trace cost weights (absent = defaults)
+	AutoTraceParams *trace.AutoTraceParams `json:"auto_trace,omitempty"`
+
 	// Connectors (v11+) - persistent board edge connectors
 	Connectors []*connector.Connector `json:"connectors,omitempty"`
 
@@ -1632,6 +1887,58 @@ type ProjectFile struct {
 	ViewZoom    float64 `json:"view_zoom,omitempty"`
 	ViewScrollX float64 `json:"view_scroll_x,omitempty"`
 	ViewScrollY float64 `json:"view_scroll_y,omitempty"`
+
+	// Board era for date code validation
+	DateCodeYears *datecode.YearRange `json:"date_code_years,omitempty"`
+}
+
+// migrateShear fills in the per-edge shear factors of projects saved before
+// shear was tracked, which have none. Absent or non-positive factors become
+// identity (1.0) rather than zero, which would collapse the layer geometry on
+// realign.
+func (p *ProjectFile) migrateShear() {
+	for _, f := range []*float64{
+		&p.FrontShearTopX, &p.FrontShearBottomX, &p.FrontShearLeftY, &p.FrontShearRightY,
+		&p.BackShearTopX, &p.BackShearBottomX, &p.BackShearLeftY, &p.BackShearRightY,
+	} {
+		*f = image.ShearFactor(*f)
+	}
+}
+
+// SavedDetection is one side's contact detection results as saved in the
+// project, with the hash of the image they were detected on.
+type SavedDetection struct {
+	ImageHash string                       `json:"image_hash"`
+	Result    *alignment.DetectionResult   `json:"result,omitempty"`
+	Extra     []*alignment.DetectionResult `json:"extra,omitempty"` // Extra contact groups
+}
+
+// saveDetection returns the detection results of a side for saving, or nil
+// if there are none or no image to tie them to.
+func saveDetection(layer *image.Layer, result *alignment.DetectionResult, extra []*alignment.DetectionResult) *SavedDetection {
+	if (result == nil && len(extra) == 0) || layer == nil || layer.Image == nil {
+		return nil
+	}
+	return &SavedDetection{ImageHash: layer.Hash(), Result: result, Extra: extra}
+}
+
+// restore returns the saved results if layer holds the image they were
+// detected on, or nils if the image has changed since, as their
+// coordinates would no longer line up with it.
+func (d *SavedDetection) restore(layer *image.Layer, side string) (*alignment.DetectionResult, []*alignment.DetectionResult) {
+	if d == nil || layer == nil || layer.Image == nil {
+		return nil, nil
+	}
+	if d.ImageHash != layer.Hash() {
+		fmt.Printf("[Project] %s image changed since contact detection; re-detect contacts\n", side)
+		return nil, nil
+	}
+	n := 0
+	if d.Result != nil {
+		n = len(d.Result.Contacts)
+	}
+	fmt.Printf("[Project] Restored %s contact detection (%d contacts, %d extra groups)\n", side, n, len(d.Extra))
+	return d.Result, d.Extra
 }
 
 // ContactData is a JSON-serializable representation of a detected contact.
@@ -1716,10 +2023,19 @@ func flipHorizontal(img goimage.Image) goimage.Image {
 	return flipped
 }
 
+// AutoTrace returns the auto-trace cost weights, falling back to defaults.
+func (s *State) AutoTrace() trace.AutoTraceParams {
+	if s.AutoTraceParams == nil {
+		return trace.DefaultAutoTraceParams()
+	}
+	return *s.AutoTraceParams
+}
+
 // CreateConnectorsFromAlignment creates Connector objects from the detected alignment contacts.
 // This should be called after alignment is complete.
 func (s *State) CreateConnectorsFromAlignment() {
-	if s.FrontDetectionResult == nil && s.BackDetectionResult == nil {
+	if s.FrontDetectionResult == nil && s.BackDetectionResult == nil &&
+		len(s.FrontExtraDetections) == 0 && len(s.BackExtraDetections) == 0 {
 		return
 	}
 
@@ -1756,5 +2072,34 @@ func (s *State) CreateConnectorsFromAlignment() {
 		}
 	}
 
+	// Extra contact groups take their pinout from the board spec
+	s.createExtraGroupConnectors(image.SideFront, s.FrontExtraDetections)
+	s.createExtraGroupConnectors(image.SideBack, s.BackExtraDetections)
+
 	s.Emit(EventConnectorsCreated, nil)
 }
+
+// createExtraGroupConnectors adds connectors for the extra contact groups
+// detected on one side, skipping the back of single-row groups. Indices continue after the preceding groups' contact
+// counts so connector IDs stay unique across groups.
+func (s *State) createExtraGroupConnectors(side image.Side, results []*alignment.DetectionResult) {
+	if s.BoardSpec == nil {
+		return
+	}
+	groups := s.BoardSpec.ContactGroups()
+	if len(groups) < 2 {
+		return
+	}
+	front := side == image.SideFront
+	base := groups[0].Count
+	for gi, group := range groups[1:] {
+		if gi < len(results) && results[gi] != nil && (front || group.HasBackRow()) {
+			for i, contact := range results[gi].Contacts {
+				c := connector.NewConnectorFromContact(base+i, side, &contact, group.PinNumber(i, front))
+				c.SignalName = group.SignalName(i, front)
+				s.FeaturesLayer.AddConnector(c)
+			}
+		}
+		base += group.Count
+	}
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	goimage "image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// writeScan writes a blank w×w PNG tagged with dpi and returns its path.
+func writeScan(t *testing.T, dir, name string, w int, dpi float64) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	src := goimage.NewRGBA(goimage.Rect(0, 0, w, w))
+	if err := image.Export(src, dpi, path, image.DefaultExportOptions()); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestResampleSurvivesReload checks that a side resampled to the other
+// side's DPI is resampled again when the project is reloaded, and that its
+// offset is scaled once, not again on every load.
+func TestResampleSurvivesReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	s := NewState()
+	s.FrontCropBounds = geometry.RectInt{Width: 40, Height: 40}
+	s.BackCropBounds = geometry.RectInt{Width: 20, Height: 20}
+	if err := s.LoadFrontImage(writeScan(t, dir, "front.png", 40, 600)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadBackImage(writeScan(t, dir, "back.png", 20, 300)); err != nil {
+		t.Fatal(err)
+	}
+	s.BackManualOffset = geometry.PointInt{X: 5, Y: -3}
+
+	if err := s.ResampleSideToDPI(image.SideBack, 600); err != nil {
+		t.Fatal(err)
+	}
+	wantOffset := geometry.PointInt{X: 10, Y: -6}
+	if s.BackImage.Width() != 40 || s.BackManualOffset != wantOffset {
+		t.Fatalf("after resample: width %d offset %v, want 40 %v", s.BackImage.Width(), s.BackManualOffset, wantOffset)
+	}
+
+	path := filepath.Join(dir, "board.pcbproj")
+	if err := s.SaveProject(path); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		r := NewState()
+		if err := r.LoadProject(path); err != nil {
+			t.Fatal(err)
+		}
+		if r.BackResampleDPI != 600 || r.DPI != 600 {
+			t.Errorf("load %d: BackResampleDPI %v DPI %v, want 600", i, r.BackResampleDPI, r.DPI)
+		}
+		if r.BackImage.Width() != 40 || r.BackImage.DPI != 600 {
+			t.Errorf("load %d: back %dpx at %v DPI, want 40px at 600", i, r.BackImage.Width(), r.BackImage.DPI)
+		}
+		if r.BackManualOffset != wantOffset || r.BackImage.ManualOffsetX != wantOffset.X || r.BackImage.ManualOffsetY != wantOffset.Y {
+			t.Errorf("load %d: offset %v, layer (%d,%d), want %v", i, r.BackManualOffset,
+				r.BackImage.ManualOffsetX, r.BackImage.ManualOffsetY, wantOffset)
+		}
+		if r.FrontResampleDPI != 0 || r.FrontImage.Width() != 40 {
+			t.Errorf("load %d: front resampled to %v (%dpx), want native 40px", i, r.FrontResampleDPI, r.FrontImage.Width())
+		}
+		if err := r.SaveProject(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDPIForSide(t *testing.T) {
+	layer := func(dpi float64) *image.Layer {
+		l := image.NewLayer()
+		l.DPI = dpi
+		return l
+	}
+	cases := []struct {
+		name                string
+		projectDPI          float64
+		front, back         *image.Layer
+		aligned             bool
+		wantFront, wantBack float64
+	}{
+		{"unaligned sides keep their own DPI", 600, layer(600), layer(300), false, 600, 300},
+		{"aligned back shares the front's", 600, layer(600), layer(300), true, 600, 600},
+		{"no project DPI uses the front scan's", 0, layer(1200), layer(600), true, 1200, 1200},
+		{"unaligned back without DPI uses the project's", 600, layer(600), layer(0), false, 600, 600},
+		{"unaligned back without DPI uses the front scan's", 0, layer(1200), layer(0), false, 1200, 1200},
+		{"nothing known", 0, layer(0), layer(0), false, 0, 0},
+		{"no images", 0, nil, nil, false, 0, 0},
+	}
+	for _, c := range cases {
+		s := &State{DPI: c.projectDPI, FrontImage: c.front, BackImage: c.back, Aligned: c.aligned}
+		if got := s.DPIForSide(image.SideFront); got != c.wantFront {
+			t.Errorf("%s: front %v, want %v", c.name, got, c.wantFront)
+		}
+		if got := s.DPIForSide(image.SideBack); got != c.wantBack {
+			t.Errorf("%s: back %v, want %v", c.name, got, c.wantBack)
+		}
+	}
+}
+
+// TestLoadLegacyShear loads a project saved before per-edge shear was
+// tracked, and one with a single edge set, and checks that missing and zero
+// factors become identity rather than collapsing the layers.
+func TestLoadLegacyShear(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	writeScan(t, dir, "front.png", 20, 600)
+	writeScan(t, dir, "back.png", 20, 600)
+
+	for _, c := range []struct {
+		name        string
+		shear       string     // Shear keys in the project file
+		front, back [4]float64 // Top X, bottom X, left Y, right Y
+	}{
+		{"no shear fields", "", [4]float64{1, 1, 1, 1}, [4]float64{1, 1, 1, 1}},
+		{"zero and partial fields",
+			`"front_shear_left_y": 0, "back_shear_top_x": 0.98, "back_shear_right_y": -1,`,
+			[4]float64{1, 1, 1, 1}, [4]float64{0.98, 1, 1, 1}},
+	} {
+		path := filepath.Join(dir, "legacy.pcbproj")
+		data := `{"version": 5, "board_type": "S-100 (IEEE 696)", ` + c.shear +
+			` "front_image": "front.png", "back_image": "back.png"}`
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		s := NewState()
+		if err := s.LoadProject(path); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		front := [4]float64{s.FrontShearTopX, s.FrontShearBottomX, s.FrontShearLeftY, s.FrontShearRightY}
+		back := [4]float64{s.BackShearTopX, s.BackShearBottomX, s.BackShearLeftY, s.BackShearRightY}
+		if front != c.front || back != c.back {
+			t.Errorf("%s: shears front %v back %v, want %v %v", c.name, front, back, c.front, c.back)
+		}
+		layer := [4]float64{s.BackImage.ShearTopX, s.BackImage.ShearBottomX, s.BackImage.ShearLeftY, s.BackImage.ShearRightY}
+		if layer != c.back {
+			t.Errorf("%s: back layer shears %v, want %v", c.name, layer, c.back)
+		}
+	}
+}
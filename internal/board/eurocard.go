@@ -44,3 +44,33 @@ func STDBusSpec() *BaseSpec {
 		},
 	}
 }
+
+// VMEbus Specifications
+// VMEbus cards use Eurocard form factors with DIN 41612 pin-and-socket
+// connectors instead of edge contacts, so they align by board corners.
+
+// VME3USpec returns the single-height (3U) VMEbus specification, P1 only.
+func VME3USpec() *BaseSpec {
+	return &BaseSpec{
+		SpecName:     "VMEbus (3U)",
+		WidthInches:  6.3,  // 160mm Eurocard
+		HeightInches: 3.94, // 100mm Eurocard
+		AlignMethods: []AlignmentMethod{
+			AlignByCorners,
+			AlignByFeatures,
+		},
+	}
+}
+
+// VME6USpec returns the double-height (6U) VMEbus specification, P1 and P2.
+func VME6USpec() *BaseSpec {
+	return &BaseSpec{
+		SpecName:     "VMEbus (6U)",
+		WidthInches:  6.3,  // 160mm Eurocard
+		HeightInches: 9.19, // 233.35mm Eurocard
+		AlignMethods: []AlignmentMethod{
+			AlignByCorners,
+			AlignByFeatures,
+		},
+	}
+}
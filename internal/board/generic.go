@@ -0,0 +1,39 @@
+package board
+
+// Generic Edge Connector Specifications
+// Starting points for boards without a built-in profile. Adjust the
+// dimensions in the board spec dialog, or copy one into a boards/*.json
+// profile (see LoadProfiles).
+
+// genericEdgeSpec returns a board with count contacts per side at pitch,
+// centered on the top edge.
+func genericEdgeSpec(name string, count int, pitch, contactWidth float64) *BaseSpec {
+	const width, height = 6.0, 4.0
+	return &BaseSpec{
+		SpecName:     name,
+		WidthInches:  width,
+		HeightInches: height,
+		Contacts: &ContactSpec{
+			Edge:         EdgeTop,
+			Count:        count,
+			PitchInches:  pitch,
+			WidthInches:  contactWidth,
+			HeightInches: 0.3,
+			MarginInches: (width - float64(count-1)*pitch) / 2,
+		},
+		AlignMethods: []AlignmentMethod{
+			AlignByContacts,
+			AlignByCorners,
+		},
+	}
+}
+
+// GenericEdge100Spec returns a generic 0.100" pitch edge connector board.
+func GenericEdge100Spec() *BaseSpec {
+	return genericEdgeSpec("Generic edge connector (0.100\")", 22, 0.1, 0.06)
+}
+
+// GenericEdge156Spec returns a generic 0.156" pitch edge connector board.
+func GenericEdge156Spec() *BaseSpec {
+	return genericEdgeSpec("Generic edge connector (0.156\")", 22, 0.156, 0.1)
+}
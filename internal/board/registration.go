@@ -0,0 +1,61 @@
+package board
+
+// FeatureKind is the kind of a registration feature, which selects how it
+// is searched for in a scan.
+type FeatureKind string
+
+const (
+	FeatureEjector FeatureKind = "ejector" // Bright card ejector with a small hole
+	FeatureHole    FeatureKind = "hole"    // Plain mounting or tooling hole
+)
+
+// DefaultFeatureSearchInches is the search radius around a registration
+// feature's expected position when the feature doesn't set its own.
+const DefaultFeatureSearchInches = 0.5
+
+// RegistrationFeature is a feature at a known position relative to a contact
+// group, used to register front and back scans beyond the contacts
+// themselves. Positions are in the frame where the group's edge is at the
+// top: X from the left board edge, Y down from the contact edge. Features
+// named "left" and "right" also drive the two-mark shear alignment.
+type RegistrationFeature struct {
+	Name         string      `json:"name"`                    // Unique label, e.g. "left"
+	Kind         FeatureKind `json:"kind"`                    // How to detect it
+	XInches      float64     `json:"x_inches"`                // From the left board edge
+	YInches      float64     `json:"y_inches"`                // Down from the contact edge
+	DiamInches   float64     `json:"diam_inches"`             // Hole diameter
+	SearchInches float64     `json:"search_inches,omitempty"` // Search radius (0 = DefaultFeatureSearchInches)
+}
+
+// SearchRadius returns the feature's search radius in inches.
+func (f RegistrationFeature) SearchRadius() float64 {
+	if f.SearchInches > 0 {
+		return f.SearchInches
+	}
+	return DefaultFeatureSearchInches
+}
+
+// FeatureOffset returns the expected position of f relative to the center
+// of the group's first contact, in inches, in the same frame as the
+// feature's own position.
+func (c *ContactSpec) FeatureOffset(f RegistrationFeature) (dx, dy float64) {
+	h := c.HeightInches
+	if h <= 0 {
+		h = S100ContactHeight
+	}
+	return f.XInches - c.MarginInches, f.YInches - h/2
+}
+
+// RowCount returns the number of contact rows: 2 for contacts on both faces
+// of the board (the default), 1 for a single-sided connector.
+func (c *ContactSpec) RowCount() int {
+	if c.Rows == 1 {
+		return 1
+	}
+	return 2
+}
+
+// HasBackRow reports whether the group has contacts on the back face.
+func (c *ContactSpec) HasBackRow() bool {
+	return c.RowCount() == 2
+}
@@ -0,0 +1,43 @@
+package board
+
+import (
+	"math"
+	"testing"
+)
+
+// TestS100RegistrationBoxes checks that the S-100 ejector features search
+// the same boxes as the original corner search: 1" squares in the corners
+// of a board 2.125" beyond the outermost contacts and 5.4375" below the
+// contact centers.
+func TestS100RegistrationBoxes(t *testing.T) {
+	cs := S100Spec().ContactSpec()
+	lastX := (S100ContactCount - 1) * S100ContactPitch
+	left, right := -2.125, lastX+2.125
+	bottom := 5.4375
+
+	// Old boxes as {x, y, size}, relative to the first contact center
+	want := map[string][3]float64{
+		"left":  {left, bottom - 1, 1},
+		"right": {right - 1, bottom - 1, 1},
+	}
+
+	if len(cs.Registration) != len(want) {
+		t.Fatalf("got %d features, want %d", len(cs.Registration), len(want))
+	}
+	for _, f := range cs.Registration {
+		box, ok := want[f.Name]
+		if !ok {
+			t.Errorf("unexpected feature %q", f.Name)
+			continue
+		}
+		dx, dy := cs.FeatureOffset(f)
+		r := f.SearchRadius()
+		got := [3]float64{dx - r, dy - r, 2 * r}
+		for i := range got {
+			if math.Abs(got[i]-box[i]) > 1e-9 {
+				t.Errorf("%s box = %v, want %v", f.Name, got, box)
+				break
+			}
+		}
+	}
+}
@@ -26,8 +26,39 @@ const (
 	S100HoleDiameter = 0.105 // ejector hole diameter
 	S100HoleInsetX   = 0.25  // inset from board edge (X)
 	S100HoleInsetY   = 0.25  // inset from top edge (Y)
+
+	// S100 card ejectors, in the corners opposite the contacts
+	S100EjectorInset        = 0.5 // approximate hole inset from the board corner
+	S100EjectorHoleDiameter = 0.1 // ~2.5mm
 )
 
+// S100Registration returns the card ejector holes as registration features
+// of the S-100 contact row. The positions reproduce the corner search boxes
+// found to work on real boards: 0.5" in from corners 2.125" beyond the
+// outermost contacts and 5.4375" below the contact centers. The right
+// corner is measured from the last contact, so it sits inside the nominal
+// 10" width.
+func S100Registration() []RegistrationFeature {
+	span := (S100ContactCount - 1) * S100ContactPitch
+	y := S100ContactHeight/2 + S100HeightInches - S100EjectorInset
+	return []RegistrationFeature{
+		{
+			Name:       "left",
+			Kind:       FeatureEjector,
+			XInches:    S100EjectorInset,
+			YInches:    y,
+			DiamInches: S100EjectorHoleDiameter,
+		},
+		{
+			Name:       "right",
+			Kind:       FeatureEjector,
+			XInches:    S100ContactMargin + span + S100ContactMargin - S100EjectorInset,
+			YInches:    y,
+			DiamInches: S100EjectorHoleDiameter,
+		},
+	}
+}
+
 // S100GoldColor returns the HSV color range for gold edge contacts.
 // Gold contacts typically appear in the yellow-orange range.
 func S100GoldColor() HSVRange {
@@ -68,6 +99,7 @@ func S100Spec() *BaseSpec {
 			HeightInches: S100ContactHeight,
 			MarginInches: S100ContactMargin,
 			Detection:    S100ContactDetection(),
+			Registration: S100Registration(),
 		},
 		MountHoles: []HoleSpec{
 			{
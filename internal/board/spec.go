@@ -3,8 +3,10 @@ package board
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 )
 
@@ -43,6 +45,21 @@ const (
 	EdgeRight  Edge = "right"
 )
 
+// OrientedEdge returns where edge lies once the board has been rotated so
+// that primary is at the top, as the import panel does before detection.
+func OrientedEdge(edge, primary Edge) Edge {
+	order := []Edge{EdgeTop, EdgeRight, EdgeBottom, EdgeLeft} // clockwise
+	idx := func(e Edge) int {
+		for i, o := range order {
+			if o == e {
+				return i
+			}
+		}
+		return 0
+	}
+	return order[(idx(edge)-idx(primary)+len(order))%len(order)]
+}
+
 // HSVRange defines a color range in HSV space for detection.
 type HSVRange struct {
 	HueMin    float64 `json:"hue_min"`    // 0-180 (OpenCV convention)
@@ -71,8 +88,51 @@ type ContactSpec struct {
 	HeightInches float64 `json:"height_inches"` // Contact height (length into board)
 	MarginInches float64 `json:"margin_inches"` // Distance from board edge to first contact center
 
+	// Contact rows: 2 = front and back (default), 1 = front only
+	Rows int `json:"rows,omitempty"`
+
 	// Detection parameters
 	Detection *ContactDetectionParams `json:"detection,omitempty"`
+
+	// Registration features located relative to this group
+	Registration []RegistrationFeature `json:"registration,omitempty"`
+
+	// Extra groups only (the primary group's pins come from the board definition)
+	Name     string   `json:"name,omitempty"`      // Group name (e.g., "P2")
+	FirstPin int      `json:"first_pin,omitempty"` // Pin number of the first front contact (0 = 1)
+	Pinout   []string `json:"pinout,omitempty"`    // Signal names in pin order, front row then back row
+}
+
+// Label returns the group's name, or "group N" for the index-th group.
+func (c *ContactSpec) Label(index int) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("group %d", index+1)
+}
+
+// PinNumber returns the pin number of the index-th detected contact (in
+// detection order) on the front or back row of the group. Front pins run
+// from FirstPin, back pins continue after the last front pin.
+func (c *ContactSpec) PinNumber(index int, front bool) int {
+	pin := max(c.FirstPin, 1) + index
+	if !front {
+		pin += c.Count
+	}
+	return pin
+}
+
+// SignalName returns the Pinout entry for the index-th contact on the front
+// or back row, or "" if the pinout doesn't cover it.
+func (c *ContactSpec) SignalName(index int, front bool) string {
+	i := index
+	if !front {
+		i += c.Count
+	}
+	if i < 0 || i >= len(c.Pinout) {
+		return ""
+	}
+	return c.Pinout[i]
 }
 
 // TotalWidthInches returns the total width of all contacts.
@@ -96,6 +156,7 @@ type Spec interface {
 	Name() string
 	Dimensions() (widthInches, heightInches float64)
 	ContactSpec() *ContactSpec
+	ContactGroups() []*ContactSpec
 	Holes() []HoleSpec
 	AlignmentMethods() []AlignmentMethod
 	Validate() error
@@ -103,12 +164,13 @@ type Spec interface {
 
 // BaseSpec provides a common implementation of Spec.
 type BaseSpec struct {
-	SpecName     string            `json:"name"`
-	WidthInches  float64           `json:"width_inches"`
-	HeightInches float64           `json:"height_inches"`
-	Contacts     *ContactSpec      `json:"contacts,omitempty"`
-	MountHoles   []HoleSpec        `json:"holes,omitempty"`
-	AlignMethods []AlignmentMethod `json:"alignment_methods"`
+	SpecName      string            `json:"name"`
+	WidthInches   float64           `json:"width_inches"`
+	HeightInches  float64           `json:"height_inches"`
+	Contacts      *ContactSpec      `json:"contacts,omitempty"`
+	ExtraContacts []*ContactSpec    `json:"extra_contacts,omitempty"` // Further contact rows (e.g., dual-edge connectors)
+	MountHoles    []HoleSpec        `json:"holes,omitempty"`
+	AlignMethods  []AlignmentMethod `json:"alignment_methods"`
 }
 
 func (s *BaseSpec) Name() string {
@@ -123,6 +185,14 @@ func (s *BaseSpec) ContactSpec() *ContactSpec {
 	return s.Contacts
 }
 
+// ContactGroups returns the primary contact row followed by any extra rows.
+func (s *BaseSpec) ContactGroups() []*ContactSpec {
+	if s.Contacts == nil {
+		return nil
+	}
+	return append([]*ContactSpec{s.Contacts}, s.ExtraContacts...)
+}
+
 func (s *BaseSpec) Holes() []HoleSpec {
 	return s.MountHoles
 }
@@ -138,12 +208,29 @@ func (s *BaseSpec) Validate() error {
 	if s.WidthInches <= 0 || s.HeightInches <= 0 {
 		return fmt.Errorf("board dimensions must be positive")
 	}
-	if s.Contacts != nil {
-		if s.Contacts.Count <= 0 {
-			return fmt.Errorf("contact count must be positive")
+	if s.Contacts == nil && len(s.ExtraContacts) > 0 {
+		return fmt.Errorf("extra contact groups require a primary contact spec")
+	}
+	for i, c := range s.ContactGroups() {
+		if c == nil {
+			return fmt.Errorf("contact group %d is empty", i+1)
+		}
+		if c.Count <= 0 {
+			return fmt.Errorf("%s: contact count must be positive", c.Label(i))
 		}
-		if s.Contacts.PitchInches <= 0 {
-			return fmt.Errorf("contact pitch must be positive")
+		if c.PitchInches <= 0 {
+			return fmt.Errorf("%s: contact pitch must be positive", c.Label(i))
+		}
+		if c.Rows < 0 || c.Rows > 2 {
+			return fmt.Errorf("%s: contact rows must be 1 or 2", c.Label(i))
+		}
+		for _, f := range c.Registration {
+			if f.Name == "" {
+				return fmt.Errorf("%s: registration feature name is required", c.Label(i))
+			}
+			if f.Kind != FeatureEjector && f.Kind != FeatureHole {
+				return fmt.Errorf("%s: registration feature %s has unknown kind %q", c.Label(i), f.Name, f.Kind)
+			}
 		}
 	}
 	if len(s.AlignMethods) == 0 {
@@ -152,6 +239,26 @@ func (s *BaseSpec) Validate() error {
 	return nil
 }
 
+// groupSpec presents one contact group of a spec as its only group, so
+// single-row detection code can run once per group.
+type groupSpec struct {
+	Spec
+	group *ContactSpec
+}
+
+func (g groupSpec) ContactSpec() *ContactSpec {
+	return g.group
+}
+
+func (g groupSpec) ContactGroups() []*ContactSpec {
+	return []*ContactSpec{g.group}
+}
+
+// ForContactGroup returns a view of spec whose contact spec is group.
+func ForContactGroup(spec Spec, group *ContactSpec) Spec {
+	return groupSpec{Spec: spec, group: group}
+}
+
 // SaveToFile saves the spec to a JSON file.
 func (s *BaseSpec) SaveToFile(path string) error {
 	data, err := json.MarshalIndent(s, "", "  ")
@@ -161,6 +268,34 @@ func (s *BaseSpec) SaveToFile(path string) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// Clone returns a deep copy of the spec, so it can be edited without
+// changing the registered original.
+func (s *BaseSpec) Clone() *BaseSpec {
+	c := *s
+	c.Contacts = s.Contacts.clone()
+	c.ExtraContacts = nil
+	for _, g := range s.ExtraContacts {
+		c.ExtraContacts = append(c.ExtraContacts, g.clone())
+	}
+	c.MountHoles = append([]HoleSpec(nil), s.MountHoles...)
+	c.AlignMethods = append([]AlignmentMethod(nil), s.AlignMethods...)
+	return &c
+}
+
+func (c *ContactSpec) clone() *ContactSpec {
+	if c == nil {
+		return nil
+	}
+	d := *c
+	if c.Detection != nil {
+		det := *c.Detection
+		d.Detection = &det
+	}
+	d.Registration = append([]RegistrationFeature(nil), c.Registration...)
+	d.Pinout = append([]string(nil), c.Pinout...)
+	return &d
+}
+
 // LoadFromFile loads a spec from a JSON file.
 func LoadFromFile(path string) (*BaseSpec, error) {
 	data, err := os.ReadFile(path)
@@ -206,6 +341,105 @@ func ListSpecs() []string {
 	return names
 }
 
+// LoadProfiles registers every *.json board spec file in dir, in
+// name order, replacing any registered spec of the same name so users can
+// override the built-ins. A missing dir is not an error; files that fail to
+// load are skipped and reported in the joined error. Returns the names
+// registered.
+func LoadProfiles(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var names []string
+	var errs []error
+	for _, path := range paths {
+		spec, err := LoadFromFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		Register(spec)
+		names = append(names, spec.Name())
+	}
+	return names, errors.Join(errs...)
+}
+
+// ProfileDirs returns the directories searched for board profiles: lib/boards
+// next to the executable, then UserProfileDir, so user profiles win over
+// shipped ones.
+func ProfileDirs() []string {
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Join(filepath.Dir(exe), "..", "lib", "boards"))
+	}
+	if dir := UserProfileDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// UserProfileDir returns ~/.config/pcb-tracer/boards, or "" if there is no
+// home directory.
+func UserProfileDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "pcb-tracer", "boards")
+}
+
+// SaveProfile writes spec to dir as a profile LoadProfiles will pick up,
+// named after the spec, and registers it. Returns the file path.
+func SaveProfile(dir string, spec *BaseSpec) (string, error) {
+	if err := spec.Validate(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, profileFileName(spec.SpecName)+".json")
+	if err := spec.SaveToFile(path); err != nil {
+		return "", err
+	}
+	Register(spec)
+	return path, nil
+}
+
+// profileFileName turns a spec name into a file name, replacing anything
+// but letters, digits, '-' and '_'.
+func profileFileName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// LoadUserProfiles loads the profiles in every ProfileDirs directory.
+func LoadUserProfiles() ([]string, error) {
+	var names []string
+	var errs []error
+	for _, dir := range ProfileDirs() {
+		loaded, err := LoadProfiles(dir)
+		names = append(names, loaded...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return names, errors.Join(errs...)
+}
+
 func init() {
 	// Register built-in board specs
 	Register(S100Spec())
@@ -215,4 +449,8 @@ func init() {
 	Register(MultibusP1P2Spec())
 	Register(ECBSpec())
 	Register(STDBusSpec())
+	Register(VME3USpec())
+	Register(VME6USpec())
+	Register(GenericEdge100Spec())
+	Register(GenericEdge156Spec())
 }
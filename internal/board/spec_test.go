@@ -0,0 +1,166 @@
+package board
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// twoGroupSpec is a dual-edge board: 25 contacts at the bottom and a
+// front-only row of 10 on the right edge.
+func twoGroupSpec() *BaseSpec {
+	return &BaseSpec{
+		SpecName:     "Dual Edge",
+		WidthInches:  6,
+		HeightInches: 4,
+		Contacts: &ContactSpec{
+			Edge: EdgeBottom, Count: 25, PitchInches: 0.156,
+			WidthInches: 0.1, HeightInches: 0.3, MarginInches: 0.5,
+		},
+		ExtraContacts: []*ContactSpec{{
+			Edge: EdgeRight, Count: 10, PitchInches: 0.1,
+			WidthInches: 0.06, HeightInches: 0.25, MarginInches: 0.4,
+			Rows: 1, Name: "P2", FirstPin: 51,
+			Pinout: []string{"A0", "A1", "A2"},
+		}},
+		AlignMethods: []AlignmentMethod{AlignByContacts},
+	}
+}
+
+func TestContactGroups(t *testing.T) {
+	spec := twoGroupSpec()
+	groups := spec.ContactGroups()
+	if len(groups) != 2 || groups[0] != spec.Contacts || groups[1] != spec.ExtraContacts[0] {
+		t.Fatalf("ContactGroups = %v, want primary then P2", groups)
+	}
+	if got := groups[0].Label(0); got != "group 1" {
+		t.Errorf("primary Label = %q, want group 1", got)
+	}
+	if got := groups[1].Label(1); got != "P2" {
+		t.Errorf("extra Label = %q, want P2", got)
+	}
+
+	// Pins: the primary row starts at 1 and its back row follows the front
+	p1, p2 := groups[0], groups[1]
+	for _, tc := range []struct {
+		group *ContactSpec
+		index int
+		front bool
+		pin   int
+		sig   string
+	}{
+		{p1, 0, true, 1, ""},
+		{p1, 24, true, 25, ""},
+		{p1, 0, false, 26, ""},
+		{p2, 0, true, 51, "A0"},
+		{p2, 2, true, 53, "A2"},
+		{p2, 3, true, 54, ""},
+		{p2, 0, false, 61, ""},
+	} {
+		if got := tc.group.PinNumber(tc.index, tc.front); got != tc.pin {
+			t.Errorf("%s PinNumber(%d, %v) = %d, want %d", tc.group.Label(0), tc.index, tc.front, got, tc.pin)
+		}
+		if got := tc.group.SignalName(tc.index, tc.front); got != tc.sig {
+			t.Errorf("%s SignalName(%d, %v) = %q, want %q", tc.group.Label(0), tc.index, tc.front, got, tc.sig)
+		}
+	}
+
+	// With the bottom edge rotated to the top, the right edge is on the left
+	if got := OrientedEdge(p2.Edge, p1.Edge); got != EdgeLeft {
+		t.Errorf("OrientedEdge(right, bottom) = %s, want left", got)
+	}
+	if got := OrientedEdge(EdgeTop, EdgeTop); got != EdgeTop {
+		t.Errorf("OrientedEdge(top, top) = %s, want top", got)
+	}
+
+	// A group view presents one group as the whole spec
+	view := ForContactGroup(spec, p2)
+	if view.ContactSpec() != p2 || len(view.ContactGroups()) != 1 || view.Name() != spec.Name() {
+		t.Errorf("ForContactGroup view = %v %v %q", view.ContactSpec(), view.ContactGroups(), view.Name())
+	}
+}
+
+func TestContactGroupsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dual.json")
+	if err := twoGroupSpec().SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := loaded.ContactGroups()
+	if len(groups) != 2 {
+		t.Fatalf("loaded %d groups, want 2", len(groups))
+	}
+	g := groups[1]
+	if g.Name != "P2" || g.Edge != EdgeRight || g.Count != 10 || g.Rows != 1 || g.FirstPin != 51 ||
+		strings.Join(g.Pinout, ",") != "A0,A1,A2" {
+		t.Errorf("loaded extra group = %+v", g)
+	}
+}
+
+func TestContactGroupsValidate(t *testing.T) {
+	if err := twoGroupSpec().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	noPrimary := twoGroupSpec()
+	noPrimary.Contacts = nil
+	if err := noPrimary.Validate(); err == nil {
+		t.Error("extra group without a primary: want error")
+	}
+
+	badExtra := twoGroupSpec()
+	badExtra.ExtraContacts[0].PitchInches = 0
+	if err := badExtra.Validate(); err == nil || !strings.Contains(err.Error(), "P2") {
+		t.Errorf("zero pitch in P2: got %v, want error naming P2", err)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	spec := S100Spec()
+	c := spec.Clone()
+	c.Contacts.Detection.Color.HueMin = 99
+	c.Contacts.Registration[0].XInches = 3
+	c.MountHoles = append(c.MountHoles, HoleSpec{Name: "extra"})
+
+	if spec.Contacts.Detection.Color.HueMin == 99 || spec.Contacts.Registration[0].XInches == 3 {
+		t.Error("editing the clone changed the original contact spec")
+	}
+	if len(spec.MountHoles) == len(c.MountHoles) {
+		t.Error("editing the clone changed the original holes")
+	}
+}
+
+func TestSaveProfile(t *testing.T) {
+	dir := t.TempDir()
+	spec := twoGroupSpec().Clone()
+	spec.SpecName = "Dual Edge/Test"
+	t.Cleanup(func() { delete(registry, spec.SpecName) })
+
+	path, err := SaveProfile(dir, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "Dual_Edge_Test.json" {
+		t.Errorf("profile saved as %s", filepath.Base(path))
+	}
+	if GetSpec(spec.SpecName) != spec {
+		t.Error("SaveProfile didn't register the spec")
+	}
+
+	delete(registry, spec.SpecName)
+	names, err := LoadProfiles(dir)
+	if err != nil || len(names) != 1 || names[0] != spec.SpecName {
+		t.Fatalf("LoadProfiles = %v, %v", names, err)
+	}
+	if got := GetSpec(spec.SpecName).ContactGroups(); len(got) != 2 {
+		t.Errorf("reloaded profile has %d groups, want 2", len(got))
+	}
+
+	spec.Contacts.PitchInches = 0
+	if _, err := SaveProfile(dir, spec); err == nil {
+		t.Error("invalid spec: want error")
+	}
+}
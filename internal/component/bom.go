@@ -0,0 +1,63 @@
+package component
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ExportBOM writes a CSV bill of materials with one row per confirmed
+// component, in natural ID order. Centers are converted to millimeters
+// using dpi. A second table after a blank row counts components by package.
+func ExportBOM(components []*Component, dpi float64, w io.Writer) error {
+	if dpi <= 0 {
+		return fmt.Errorf("invalid DPI %g", dpi)
+	}
+	mmPerPx := 25.4 / dpi
+
+	var confirmed []*Component
+	for _, c := range components {
+		if c != nil && c.Confirmed {
+			confirmed = append(confirmed, c)
+		}
+	}
+	sort.SliceStable(confirmed, func(i, j int) bool { return NaturalLess(confirmed[i].ID, confirmed[j].ID) })
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ID", "Part Number", "Package", "Manufacturer", "Date Code", "Decoded Date",
+		"Place", "Revision", "Speed Grade", "Description", "X (mm)", "Y (mm)"})
+
+	packageCounts := make(map[string]int)
+	for _, c := range confirmed {
+		center := c.Center()
+		cw.Write([]string{
+			c.ID, c.PartNumber, c.Package, c.Manufacturer, c.DateCode, c.DecodedDate,
+			c.Place, c.Revision, c.SpeedGrade, c.Description,
+			strconv.FormatFloat(center.X*mmPerPx, 'f', 2, 64),
+			strconv.FormatFloat(center.Y*mmPerPx, 'f', 2, 64),
+		})
+		pkg := c.Package
+		if pkg == "" {
+			pkg = "(none)"
+		}
+		packageCounts[pkg]++
+	}
+
+	packages := make([]string, 0, len(packageCounts))
+	for pkg := range packageCounts {
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return NaturalLess(packages[i], packages[j]) })
+
+	cw.Write(nil)
+	cw.Write([]string{"Package", "Count"})
+	for _, pkg := range packages {
+		cw.Write([]string{pkg, strconv.Itoa(packageCounts[pkg])})
+	}
+	cw.Write([]string{"Total", strconv.Itoa(len(confirmed))})
+
+	cw.Flush()
+	return cw.Error()
+}
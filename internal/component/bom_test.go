@@ -0,0 +1,72 @@
+package component
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestExportBOM(t *testing.T) {
+	// At 600 DPI 100 pixels is 4.23 mm
+	part := func(id, pn, pkg string, x, y float64, confirmed bool) *Component {
+		c := NewComponent(id)
+		c.PartNumber = pn
+		c.Package = pkg
+		c.Bounds = geometry.Rect{X: x, Y: y, Width: 100, Height: 200}
+		c.Confirmed = confirmed
+		return c
+	}
+	u10 := part("U10", "74LS74", "DIP-14", 1000, 400, true)
+	u10.Manufacturer = "TI"
+	u10.DateCode = "8423"
+	u10.DecodedDate = "1984 Jun"
+	u2 := part("U2", "SN74LS00N", "DIP-14", 200, 400, true)
+	u2.Description = "Quad NAND, \"2-input\""
+	u1 := part("U1", "MC68000P8", "DIP-64", 50, 50, true)
+	u1.Manufacturer = "Motorola"
+	u1.Place = "Malaysia"
+	u1.Revision = "G"
+	u1.SpeedGrade = "-8"
+	components := []*Component{
+		u10,
+		part("U3", "74LS04", "DIP-14", 600, 400, false), // Unconfirmed, left out
+		u2,
+		nil,
+		part("X1", "", "", 1500, 900, true), // No package
+		u1,
+	}
+
+	var buf bytes.Buffer
+	if err := ExportBOM(components, 600, &buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "bom.csv", buf.Bytes())
+
+	if err := ExportBOM(components, 0, &buf); err == nil {
+		t.Error("ExportBOM at 0 DPI: want error")
+	}
+}
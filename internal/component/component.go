@@ -26,17 +26,19 @@ type Component struct {
 	Layer       image.Side    `json:"layer"`       // Which side of the board
 	Confirmed   bool          `json:"confirmed"`   // User verified
 	Pins        []Pin         `json:"pins"`        // Pin positions and nets
-	Rotation    float64       `json:"rotation"`    // Rotation in degrees
+	Rotation    float64       `json:"rotation"`    // Rotation in degrees, clockwise; see Tilt
 	OCRText     string        `json:"ocr_text"`    // Raw OCR result from detection
 
 	// OCR orientation and corrected text for training
-	OCROrientation string `json:"ocr_orientation,omitempty"` // N/S/E/W - remembered orientation
-	CorrectedText  string `json:"corrected_text,omitempty"`  // User-verified text for training
+	OCROrientation string    `json:"ocr_orientation,omitempty"` // N/S/E/W - remembered orientation
+	CorrectedText  string    `json:"corrected_text,omitempty"`  // User-verified text for training
+	OCRCache       *OCRCache `json:"ocr_cache,omitempty"`       // Last OCR reading, reused while its key matches
 
 	// Additional component metadata
 	Manufacturer string `json:"manufacturer,omitempty"` // Manufacturer name, e.g., "Texas Instruments"
 	Place        string `json:"place,omitempty"`        // Manufacturing location
 	DateCode     string `json:"date_code,omitempty"`    // Date code, e.g., "8523" (year/week)
+	DecodedDate  string `json:"decoded_date,omitempty"` // Human-readable decode of DateCode, empty if undecodable
 	Revision     string `json:"revision,omitempty"`     // Revision/version
 	SpeedGrade   string `json:"speed_grade,omitempty"`  // Speed grade, e.g., "-25", "-45"
 }
@@ -62,6 +64,63 @@ func (c *Component) Center() geometry.Point2D {
 	return c.Bounds.Center()
 }
 
+// Tilt returns the part of Rotation not already expressed by Bounds, in
+// degrees [-45, 45). Bounds follow the nearest quarter turn (a DIP at 90° has
+// a wide box), so only the remainder tilts the body off the axes.
+func (c *Component) Tilt() float64 {
+	t := math.Mod(c.Rotation, 90)
+	if t >= 45 {
+		t -= 90
+	} else if t < -45 {
+		t += 90
+	}
+	return t
+}
+
+// Rotate turns the component by degrees clockwise about its center. When
+// the turn crosses to another nearest quarter turn, Bounds swap width and
+// height so that Tilt stays within ±45°.
+func (c *Component) Rotate(degrees float64) {
+	before := int(math.Round(c.Rotation / 90))
+	c.Rotation = math.Mod(c.Rotation+degrees, 360)
+	if c.Rotation < 0 {
+		c.Rotation += 360
+	}
+	after := int(math.Round(c.Rotation / 90))
+	if (after-before)%2 != 0 {
+		center := c.Bounds.Center()
+		w, h := c.Bounds.Height, c.Bounds.Width
+		c.Bounds = geometry.Rect{X: center.X - w/2, Y: center.Y - h/2, Width: w, Height: h}
+	}
+}
+
+// Corners returns the body outline: Bounds turned by Tilt about its center,
+// clockwise from the (untilted) top left.
+func (c *Component) Corners() []geometry.Point2D {
+	b := c.Bounds
+	corners := []geometry.Point2D{
+		{X: b.X, Y: b.Y},
+		{X: b.X + b.Width, Y: b.Y},
+		{X: b.X + b.Width, Y: b.Y + b.Height},
+		{X: b.X, Y: b.Y + b.Height},
+	}
+	if tilt := c.Tilt(); tilt != 0 {
+		rot := geometry.RotationAbout(b.Center(), tilt)
+		for i := range corners {
+			corners[i] = rot.Apply(corners[i])
+		}
+	}
+	return corners
+}
+
+// Contains reports whether p lies on the component body, following its tilt.
+func (c *Component) Contains(p geometry.Point2D) bool {
+	if c.Tilt() == 0 {
+		return c.Bounds.Contains(p)
+	}
+	return c.Bounds.Contains(geometry.RotateAbout(p, c.Bounds.Center(), -c.Tilt()))
+}
+
 // AddPin adds a pin to the component.
 func (c *Component) AddPin(number int, name string, pos geometry.Point2D) {
 	c.Pins = append(c.Pins, Pin{
@@ -81,6 +140,37 @@ func (c *Component) GetPin(number int) *Pin {
 	return nil
 }
 
+// OCRCache is the last OCR reading of a component. It is only valid while
+// Key still matches the component's bounds, orientation and OCR params.
+type OCRCache struct {
+	Key          string `json:"key"`
+	Text         string `json:"text"`                   // Part numbers fixed, logo names prepended
+	Manufacturer string `json:"manufacturer,omitempty"` // From a detected logo
+}
+
+// OCRCacheKey identifies an OCR reading of the component at its current
+// bounds with the given orientation and params hash.
+func (c *Component) OCRCacheKey(orientation, paramsHash string) string {
+	b := c.Bounds
+	if tilt := c.Tilt(); tilt != 0 {
+		return fmt.Sprintf("%.1f,%.1f,%.1fx%.1f@%.1f/%s/%s", b.X, b.Y, b.Width, b.Height, tilt, orientation, paramsHash)
+	}
+	return fmt.Sprintf("%.1f,%.1f,%.1fx%.1f/%s/%s", b.X, b.Y, b.Width, b.Height, orientation, paramsHash)
+}
+
+// CachedOCR returns the cached reading if it was made with key. A stale
+// cache is dropped so it is not saved with the project.
+func (c *Component) CachedOCR(key string) (*OCRCache, bool) {
+	if c.OCRCache == nil {
+		return nil, false
+	}
+	if c.OCRCache.Key != key {
+		c.OCRCache = nil
+		return nil, false
+	}
+	return c.OCRCache, true
+}
+
 // PackageType represents a standard component package type.
 type PackageType struct {
 	Name       string  // e.g., "DIP-20"
@@ -1104,6 +1194,120 @@ func SuggestComponentID(components []*Component, centerX, centerY, tolerance flo
 	return fallback
 }
 
+// OrientationForRotation returns the OCR orientation (N/E/S/W) of text on a
+// part turned degrees clockwise, rounded to the nearest quarter turn. Any
+// angle is accepted (-90 and 270 both give W); an exact half-way angle goes
+// to the next quarter turn clockwise, as Tilt does (45 is E, -45 is N).
+func OrientationForRotation(degrees float64) string {
+	d := math.Mod(degrees, 360)
+	if d < 0 {
+		d += 360
+	}
+	return [...]string{"N", "E", "S", "W"}[int(math.Round(d/90))%4]
+}
+
+// NearestOrientation returns the OCR orientation of the confirmed component
+// whose center is closest to center, or "" if no confirmed component has one.
+// A component without a remembered orientation that is turned on the board
+// counts with the orientation of its Rotation.
+// Adjacent ICs usually share an orientation, so this is a better default for
+// a new component than the last orientation used anywhere on the board.
+func NearestOrientation(comps []*Component, center geometry.Point2D) string {
+	best := ""
+	bestDist := math.Inf(1)
+	for _, c := range comps {
+		if c == nil || !c.Confirmed {
+			continue
+		}
+		orient := c.OCROrientation
+		if orient == "" && c.Rotation != 0 {
+			orient = OrientationForRotation(c.Rotation)
+		}
+		if orient == "" {
+			continue
+		}
+		if d := c.Center().Distance(center); d < bestDist {
+			bestDist = d
+			best = orient
+		}
+	}
+	return best
+}
+
+// FindDuplicateIDs returns the component indices for every ID used more than
+// once, keyed by ID. Components with an empty ID are ignored.
+func FindDuplicateIDs(comps []*Component) map[string][]int {
+	byID := make(map[string][]int)
+	for i, c := range comps {
+		if c == nil || c.ID == "" {
+			continue
+		}
+		byID[c.ID] = append(byID[c.ID], i)
+	}
+	for id, idxs := range byID {
+		if len(idxs) < 2 {
+			delete(byID, id)
+		}
+	}
+	return byID
+}
+
+// RenumberDuplicateIDs gives every duplicate component after the first a unique
+// ID. It first asks SuggestComponentID (using the other components' grid
+// positions) and falls back to appending a numeric suffix ("U12-2") when that
+// gives no complete, unused ID.
+// Returns the new IDs keyed by component index. The first component of each
+// group keeps its ID, so references to it (e.g. via pin assignments) still hold.
+func RenumberDuplicateIDs(comps []*Component, tolerance float64) map[int]string {
+	dups := FindDuplicateIDs(comps)
+	if len(dups) == 0 {
+		return nil
+	}
+
+	used := make(map[string]bool, len(comps))
+	for _, c := range comps {
+		if c != nil {
+			used[c.ID] = true
+		}
+	}
+
+	ids := make([]string, 0, len(dups))
+	for id := range dups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	renamed := make(map[int]string)
+	for _, id := range ids {
+		for _, idx := range dups[id][1:] {
+			comp := comps[idx]
+			others := make([]*Component, 0, len(comps)-1)
+			for i, c := range comps {
+				if i != idx && c != nil {
+					others = append(others, c)
+				}
+			}
+			center := comp.Center()
+			newID := SuggestComponentID(others, center.X, center.Y, tolerance, "")
+			// Partial grid matches ("C?") and the NEW fallback aren't real IDs
+			if newID == "" || used[newID] || strings.HasPrefix(newID, "NEW") || strings.Contains(newID, "?") {
+				newID = ""
+				for n := 2; ; n++ {
+					candidate := fmt.Sprintf("%s-%d", id, n)
+					if !used[candidate] {
+						newID = candidate
+						break
+					}
+				}
+			}
+			comp.ID = newID
+			used[newID] = true
+			renamed[idx] = newID
+		}
+	}
+	return renamed
+}
+
 // SuggestGridIDExact is like SuggestGridID but only uses exact coordinate matches
 // (within tolerance). It does not interpolate between known coordinates.
 func (m *GridMapping) SuggestGridIDExact(centerX, centerY float64) string {
@@ -0,0 +1,130 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// at returns a 40×100 component with the given ID centered at (x, y).
+func at(id string, x, y float64) *Component {
+	return &Component{ID: id, Bounds: geometry.Rect{X: x - 20, Y: y - 50, Width: 40, Height: 100}}
+}
+
+func TestFindDuplicateIDs(t *testing.T) {
+	cases := []struct {
+		name  string
+		comps []*Component
+		want  map[string][]int
+	}{
+		{"no duplicates", []*Component{at("U1", 0, 0), at("U2", 100, 0), at("R1", 200, 0)}, map[string][]int{}},
+		{"one pair", []*Component{at("U1", 0, 0), at("U12", 100, 0), at("U12", 200, 0)}, map[string][]int{"U12": {1, 2}}},
+		{"two groups", []*Component{at("C1", 0, 0), at("U3", 100, 0), at("C1", 200, 0), at("U3", 300, 0), at("C1", 400, 0)},
+			map[string][]int{"C1": {0, 2, 4}, "U3": {1, 3}}},
+		{"case matters", []*Component{at("u1", 0, 0), at("U1", 100, 0)}, map[string][]int{}},
+		{"empty and nil ignored", []*Component{at("", 0, 0), nil, at("", 100, 0), nil}, map[string][]int{}},
+		{"empty list", nil, map[string][]int{}},
+	}
+	for _, c := range cases {
+		if got := FindDuplicateIDs(c.comps); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRenumberDuplicateIDs(t *testing.T) {
+	if got := RenumberDuplicateIDs([]*Component{at("U1", 0, 0), at("U2", 100, 0)}, 50); got != nil {
+		t.Errorf("no duplicates: renamed %v", got)
+	}
+
+	// Board-grid IDs, with B1-2 already taken by hand
+	comps := []*Component{
+		at("A1", 100, 100), at("B1", 300, 100), at("C1", 500, 100),
+		at("A2", 100, 300), at("B2", 300, 300), at("A1", 500, 300),
+		at("B1", 300, 500), at("B1-2", 900, 900), at("C1", 700, 100),
+	}
+	renamed := RenumberDuplicateIDs(comps, 50)
+	want := map[int]string{5: "A1-2", 6: "B1-3", 8: "C1-2"}
+	if !reflect.DeepEqual(renamed, want) {
+		t.Errorf("renamed %v, want %v", renamed, want)
+	}
+	if comps[0].ID != "A1" || comps[1].ID != "B1" || comps[2].ID != "C1" {
+		t.Errorf("first of each group became %q %q %q, want A1 B1 C1 kept", comps[0].ID, comps[1].ID, comps[2].ID)
+	}
+	for idx, id := range renamed {
+		if comps[idx].ID != id {
+			t.Errorf("component %d has ID %q, reported %q", idx, comps[idx].ID, id)
+		}
+	}
+	if dups := FindDuplicateIDs(comps); len(dups) != 0 {
+		t.Errorf("duplicates left after renumbering: %v", dups)
+	}
+}
+
+func TestOrientationForRotation(t *testing.T) {
+	for _, c := range []struct {
+		degrees float64
+		want    string
+	}{
+		{0, "N"},
+		{44.9, "N"},
+		{45, "E"},
+		{90, "E"},
+		{134.9, "E"},
+		{135, "S"},
+		{180, "S"},
+		{225, "W"},
+		{270, "W"},
+		{314.9, "W"},
+		{315, "N"},
+		{359.5, "N"},
+		{360, "N"},
+		{450, "E"},
+		{-10, "N"},
+		{-45, "N"},
+		{-45.1, "W"},
+		{-90, "W"},
+		{-180, "S"},
+		{-360, "N"},
+	} {
+		if got := OrientationForRotation(c.degrees); got != c.want {
+			t.Errorf("OrientationForRotation(%v) = %q, want %q", c.degrees, got, c.want)
+		}
+	}
+}
+
+func TestNearestOrientation(t *testing.T) {
+	oriented := func(id string, x, y float64, orient string, rotation float64, confirmed bool) *Component {
+		c := at(id, x, y)
+		c.OCROrientation, c.Rotation, c.Confirmed = orient, rotation, confirmed
+		return c
+	}
+	comps := []*Component{
+		oriented("U1", 100, 100, "S", 0, true),
+		oriented("U2", 400, 100, "E", 0, true),
+		oriented("U3", 100, 400, "W", 0, false), // Unconfirmed
+		oriented("U4", 400, 400, "", 0, true),   // Nothing remembered
+		oriented("U5", 700, 400, "", 180, true), // Turned upside down
+		nil,
+	}
+	for _, c := range []struct {
+		name   string
+		center geometry.Point2D
+		want   string
+	}{
+		{"next to a south-oriented part", geometry.Point2D{X: 160, Y: 110}, "S"},
+		{"nearer the east one", geometry.Point2D{X: 300, Y: 100}, "E"},
+		{"unconfirmed neighbor skipped", geometry.Point2D{X: 110, Y: 390}, "S"},
+		{"neighbor without orientation skipped", geometry.Point2D{X: 420, Y: 360}, "E"},
+		{"turned neighbor", geometry.Point2D{X: 650, Y: 420}, "S"},
+	} {
+		if got := NearestOrientation(comps, c.center); got != c.want {
+			t.Errorf("%s: %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	if got := NearestOrientation([]*Component{oriented("U9", 0, 0, "", 0, true)}, geometry.Point2D{}); got != "" {
+		t.Errorf("no orientations: %q, want none", got)
+	}
+}
@@ -0,0 +1,270 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// CropBounds is the region of a layer's source image that the component
+// bounds are relative to, as stored in project files.
+type CropBounds struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// CropImage extracts comp's region from the image of its side, offsetting
+// its bounds by that side's crop (nil for none). The region is clipped to
+// the image and not rotated.
+func CropImage(comp *Component, frontImg, backImg image.Image, frontCrop, backCrop *CropBounds) (*image.RGBA, error) {
+	img := frontImg
+	crop := frontCrop
+	if comp.Layer == pcbimage.SideBack {
+		img = backImg
+		crop = backCrop
+	}
+	if img == nil {
+		return nil, fmt.Errorf("no image for layer %v", comp.Layer)
+	}
+
+	bounds := comp.Bounds
+	if crop != nil {
+		bounds = geometry.Rect{
+			X:      comp.Bounds.X + crop.X,
+			Y:      comp.Bounds.Y + crop.Y,
+			Width:  comp.Bounds.Width,
+			Height: comp.Bounds.Height,
+		}
+	}
+
+	cropped := extractRegion(img, bounds)
+	if cropped == nil {
+		return nil, fmt.Errorf("could not extract region")
+	}
+	return cropped, nil
+}
+
+// extractRegion copies bounds out of img, clipped to the image. Returns nil
+// if nothing is left after clipping.
+func extractRegion(img image.Image, bounds geometry.Rect) *image.RGBA {
+	x := int(bounds.X)
+	y := int(bounds.Y)
+	w := int(bounds.Width)
+	h := int(bounds.Height)
+
+	imgBounds := img.Bounds()
+	if x < imgBounds.Min.X {
+		x = imgBounds.Min.X
+	}
+	if y < imgBounds.Min.Y {
+		y = imgBounds.Min.Y
+	}
+	if x+w > imgBounds.Max.X {
+		w = imgBounds.Max.X - x
+	}
+	if y+h > imgBounds.Max.Y {
+		h = imgBounds.Max.Y - y
+	}
+
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
+	for dy := 0; dy < h; dy++ {
+		for dx := 0; dx < w; dx++ {
+			cropped.Set(dx, dy, img.At(x+dx, y+dy))
+		}
+	}
+
+	return cropped
+}
+
+// RotateToOrientation turns a crop so text read in the given OCR
+// orientation (N/S/E/W) comes out upright: S rotates 180°, E 90° CCW and
+// W 90° CW. Anything else returns a copy.
+func RotateToOrientation(img *image.RGBA, orientation string) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	srcPix := img.Pix
+	srcStride := img.Stride
+
+	// dst maps source pixel (x, y) to its destination pixel offset
+	var out *image.RGBA
+	var dst func(x, y int) int
+	switch orientation {
+	case "S":
+		out = image.NewRGBA(image.Rect(0, 0, w, h))
+		dst = func(x, y int) int { return (h-1-y)*out.Stride + (w-1-x)*4 }
+	case "E":
+		out = image.NewRGBA(image.Rect(0, 0, h, w))
+		dst = func(x, y int) int { return (w-1-x)*out.Stride + y*4 }
+	case "W":
+		out = image.NewRGBA(image.Rect(0, 0, h, w))
+		dst = func(x, y int) int { return x*out.Stride + (h-1-y)*4 }
+	default:
+		out = image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			copy(out.Pix[y*out.Stride:], srcPix[y*srcStride:y*srcStride+w*4])
+		}
+		return out
+	}
+
+	for y := 0; y < h; y++ {
+		srcRow := y * srcStride
+		for x := 0; x < w; x++ {
+			si := srcRow + x*4
+			copy(out.Pix[dst(x, y):], srcPix[si:si+4])
+		}
+	}
+	return out
+}
+
+// cropEntry is one exported crop as listed in the gallery index.
+type cropEntry struct {
+	File        string
+	ID          string
+	PartNumber  string
+	Package     string
+	Side        string
+	Orientation string
+	Text        string
+}
+
+var cropIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Components</title>
+<style>
+body { font-family: sans-serif; }
+.sheet { display: flex; flex-wrap: wrap; gap: 12px; }
+figure { margin: 0; padding: 6px; border: 1px solid #ccc; width: 220px; }
+img { max-width: 220px; max-height: 160px; display: block; margin: 0 auto; }
+figcaption { font-size: 12px; margin-top: 4px; }
+pre { margin: 2px 0 0; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Components ({{len .}})</h1>
+<div class="sheet">
+{{range .}}<figure>
+<a href="{{.File}}"><img src="{{.File}}" alt="{{.ID}}"></a>
+<figcaption><b>{{.ID}}</b> {{.PartNumber}}{{if .Package}} ({{.Package}}){{end}}<br>{{.Side}}, {{.Orientation}}{{if .Text}}<pre>{{.Text}}</pre>{{end}}</figcaption>
+</figure>
+{{end}}</div>
+</body>
+</html>
+`))
+
+// ExportCrops writes every confirmed component as <ID>.png in outDir, cut
+// from the image of its side with that side's crop offset (as CropImage
+// does) and turned upright by its OCR orientation, plus an index.html
+// contact sheet in natural ID order. outDir is created if needed.
+// Components that can't be cropped are skipped and reported in the joined
+// error; the rest are still written.
+func ExportCrops(components []*Component, frontImg, backImg image.Image, frontCrop, backCrop *CropBounds, outDir string) error {
+	var confirmed []*Component
+	for _, c := range components {
+		if c != nil && c.Confirmed {
+			confirmed = append(confirmed, c)
+		}
+	}
+	sort.SliceStable(confirmed, func(i, j int) bool { return NaturalLess(confirmed[i].ID, confirmed[j].ID) })
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+
+	var entries []cropEntry
+	var errs []error
+	used := make(map[string]bool)
+	for _, comp := range confirmed {
+		cropped, err := CropImage(comp, frontImg, backImg, frontCrop, backCrop)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", comp.ID, err))
+			continue
+		}
+		orientation := comp.OCROrientation
+		if orientation == "" {
+			orientation = "N"
+		}
+		cropped = RotateToOrientation(cropped, orientation)
+
+		name := cropFileName(comp.ID, used)
+		if err := writePNG(filepath.Join(outDir, name), cropped); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", comp.ID, err))
+			continue
+		}
+
+		text := comp.CorrectedText
+		if text == "" {
+			text = comp.OCRText
+		}
+		entries = append(entries, cropEntry{
+			File:        name,
+			ID:          comp.ID,
+			PartNumber:  comp.PartNumber,
+			Package:     comp.Package,
+			Side:        comp.Layer.String(),
+			Orientation: orientation,
+			Text:        strings.TrimSpace(text),
+		})
+	}
+
+	f, err := os.Create(filepath.Join(outDir, "index.html"))
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	err = cropIndexTemplate.Execute(f, entries)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// cropFileName returns a PNG file name for id that is safe on any file
+// system and not yet in used, which it is added to.
+func cropFileName(id string, used map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, id)
+	if base == "" {
+		base = "component"
+	}
+	name := base + ".png"
+	for n := 2; used[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s_%d.png", base, n)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package component
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestCropFileName(t *testing.T) {
+	used := map[string]bool{}
+	for _, tc := range []struct{ id, want string }{
+		{"U1", "U1.png"},
+		{"u1", "u1_2.png"}, // Case-insensitive file systems
+		{"U/1", "U_1.png"},
+		{"U_1", "U_1_2.png"}, // Collides once sanitized
+		{"U 1", "U_1_3.png"},
+		{"", "component.png"},
+		{"R-A3.b", "R-A3.b.png"},
+	} {
+		if got := cropFileName(tc.id, used); got != tc.want {
+			t.Errorf("cropFileName(%q) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+}
+
+// gradient returns a w×h image whose pixel (x, y) has R = x and G = y.
+func gradient(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	return img
+}
+
+func TestCropImage(t *testing.T) {
+	front, back := gradient(100, 80), gradient(100, 80)
+	frontCrop := &CropBounds{X: 10, Y: 5, Width: 60, Height: 50}
+	backCrop := &CropBounds{X: 20, Y: 30, Width: 60, Height: 50}
+
+	for _, tc := range []struct {
+		name         string
+		layer        pcbimage.Side
+		bounds       geometry.Rect
+		fc, bc       *CropBounds
+		w, h, x0, y0 int // Expected size and source of the top-left pixel
+	}{
+		{"front", pcbimage.SideFront, geometry.Rect{X: 4, Y: 6, Width: 8, Height: 5}, frontCrop, backCrop, 8, 5, 14, 11},
+		{"back", pcbimage.SideBack, geometry.Rect{X: 4, Y: 6, Width: 8, Height: 5}, frontCrop, backCrop, 8, 5, 24, 36},
+		{"no crop", pcbimage.SideBack, geometry.Rect{X: 4, Y: 6, Width: 8, Height: 5}, nil, nil, 8, 5, 4, 6},
+		{"clipped", pcbimage.SideFront, geometry.Rect{X: 85, Y: 70, Width: 20, Height: 20}, frontCrop, backCrop, 5, 5, 95, 75},
+	} {
+		comp := &Component{ID: "U1", Bounds: tc.bounds, Layer: tc.layer}
+		got, err := CropImage(comp, front, back, tc.fc, tc.bc)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got.Bounds().Dx() != tc.w || got.Bounds().Dy() != tc.h {
+			t.Errorf("%s: crop is %v, want %dx%d", tc.name, got.Bounds().Size(), tc.w, tc.h)
+		}
+		if c := got.RGBAAt(0, 0); int(c.R) != tc.x0 || int(c.G) != tc.y0 {
+			t.Errorf("%s: top-left pixel from (%d,%d), want (%d,%d)", tc.name, c.R, c.G, tc.x0, tc.y0)
+		}
+	}
+
+	outside := &Component{ID: "U2", Bounds: geometry.Rect{X: 200, Y: 200, Width: 10, Height: 10}}
+	if _, err := CropImage(outside, front, back, nil, nil); err == nil {
+		t.Error("component outside the image: want error")
+	}
+	if _, err := CropImage(&Component{Layer: pcbimage.SideBack}, front, nil, nil, nil); err == nil {
+		t.Error("no back image: want error")
+	}
+}
+
+func TestRotateToOrientation(t *testing.T) {
+	src := gradient(3, 2)
+	for _, tc := range []struct {
+		orientation string
+		w, h        int
+		topLeft     [2]uint8 // Source (x, y) of the result's top-left pixel
+	}{
+		{"N", 3, 2, [2]uint8{0, 0}},
+		{"S", 3, 2, [2]uint8{2, 1}},
+		{"E", 2, 3, [2]uint8{2, 0}},
+		{"W", 2, 3, [2]uint8{0, 1}},
+	} {
+		got := RotateToOrientation(src, tc.orientation)
+		if got.Bounds().Dx() != tc.w || got.Bounds().Dy() != tc.h {
+			t.Errorf("%s: size %v, want %dx%d", tc.orientation, got.Bounds().Size(), tc.w, tc.h)
+			continue
+		}
+		if c := got.RGBAAt(0, 0); [2]uint8{c.R, c.G} != tc.topLeft {
+			t.Errorf("%s: top-left from (%d,%d), want %v", tc.orientation, c.R, c.G, tc.topLeft)
+		}
+	}
+
+	// Sub-images rotate their own pixels, not the parent's origin
+	sub := gradient(10, 10).SubImage(image.Rect(4, 5, 7, 7)).(*image.RGBA)
+	if c := RotateToOrientation(sub, "S").RGBAAt(0, 0); c.R != 6 || c.G != 6 {
+		t.Errorf("sub-image S: top-left from (%d,%d), want (6,6)", c.R, c.G)
+	}
+	if RotateToOrientation(src, "N") == src {
+		t.Error("N returned the source, want a copy")
+	}
+}
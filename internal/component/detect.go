@@ -11,6 +11,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"pcb-tracer/pkg/colorutil"
 	"pcb-tracer/pkg/geometry"
 	"pcb-tracer/ui/canvas"
 
@@ -1833,6 +1834,37 @@ func checkWhiteMarkings(img gocv.Mat, rect image.Rectangle) float64 {
 // Used to create training samples from user-selected rectangles.
 // Analyzes histogram to find background (dark) and marking (light) peaks.
 func ExtractSampleFeatures(img image.Image, bounds geometry.Rect, dpi float64) TrainingSample {
+	// Convert Go image to Mat
+	mat, err := imageToMat(img)
+	if err != nil {
+		fmt.Printf("ExtractSampleFeatures: image conversion error: %v\n", err)
+		mmToPixels := dpi / 25.4
+		return TrainingSample{Bounds: bounds, WidthMM: bounds.Width / mmToPixels, HeightMM: bounds.Height / mmToPixels}
+	}
+	defer mat.Close()
+
+	sample, hsvHist := sampleFeaturesMat(mat, bounds, dpi)
+
+	fmt.Printf("=== Training Sample Added ===\n")
+	fmt.Printf("  Size: %.1f x %.1f mm\n", sample.WidthMM, sample.HeightMM)
+	fmt.Printf("  Mean HSV: H=%.1f S=%.1f V=%.1f\n", sample.MeanHue, sample.MeanSat, sample.MeanVal)
+	printHSVHistogram("H (Hue)", hsvHist.H, 180)
+	printHSVHistogram("S (Saturation)", hsvHist.S, 256)
+	printHSVHistogram("V (Value)", hsvHist.V, 256)
+	fmt.Printf("  Background: V=%3.0f (bucket %d, %.1f%%)\n",
+		sample.BackgroundVal, int(sample.BackgroundVal)/ColorBucketWidth, sample.BackgroundPct)
+	fmt.Printf("  Markings:   V=%3.0f (bucket %d, %.1f%%)\n",
+		sample.MarkingVal, int(sample.MarkingVal)/ColorBucketWidth, sample.MarkingPct)
+	fmt.Printf("  White ratio: %.1f%%\n", sample.WhiteRatio)
+	fmt.Printf("=============================\n")
+
+	return sample
+}
+
+// sampleFeaturesMat extracts the features of ExtractSampleFeatures from a
+// BGR Mat of the whole image, without logging. Callers that score many
+// regions convert the image once and use this directly.
+func sampleFeaturesMat(mat gocv.Mat, bounds geometry.Rect, dpi float64) (TrainingSample, HSVHistograms) {
 	sample := TrainingSample{
 		Bounds: bounds,
 	}
@@ -1842,14 +1874,6 @@ func ExtractSampleFeatures(img image.Image, bounds geometry.Rect, dpi float64) T
 	sample.WidthMM = bounds.Width / mmToPixels
 	sample.HeightMM = bounds.Height / mmToPixels
 
-	// Convert Go image to Mat
-	mat, err := imageToMat(img)
-	if err != nil {
-		fmt.Printf("ExtractSampleFeatures: image conversion error: %v\n", err)
-		return sample
-	}
-	defer mat.Close()
-
 	// Clamp bounds to image
 	rect := image.Rect(
 		int(bounds.X),
@@ -1870,7 +1894,7 @@ func ExtractSampleFeatures(img image.Image, bounds geometry.Rect, dpi float64) T
 		rect.Max.Y = mat.Rows()
 	}
 	if rect.Dx() <= 0 || rect.Dy() <= 0 {
-		return sample
+		return sample, HSVHistograms{}
 	}
 
 	// Extract region
@@ -1906,20 +1930,7 @@ func ExtractSampleFeatures(img image.Image, bounds geometry.Rect, dpi float64) T
 	// Get white ratio
 	sample.WhiteRatio = checkWhiteMarkings(mat, rect)
 
-	fmt.Printf("=== Training Sample Added ===\n")
-	fmt.Printf("  Size: %.1f x %.1f mm\n", sample.WidthMM, sample.HeightMM)
-	fmt.Printf("  Mean HSV: H=%.1f S=%.1f V=%.1f\n", sample.MeanHue, sample.MeanSat, sample.MeanVal)
-	printHSVHistogram("H (Hue)", hsvHist.H, 180)
-	printHSVHistogram("S (Saturation)", hsvHist.S, 256)
-	printHSVHistogram("V (Value)", hsvHist.V, 256)
-	fmt.Printf("  Background: V=%3.0f (bucket %d, %.1f%%)\n",
-		sample.BackgroundVal, int(sample.BackgroundVal)/ColorBucketWidth, sample.BackgroundPct)
-	fmt.Printf("  Markings:   V=%3.0f (bucket %d, %.1f%%)\n",
-		sample.MarkingVal, int(sample.MarkingVal)/ColorBucketWidth, sample.MarkingPct)
-	fmt.Printf("  White ratio: %.1f%%\n", sample.WhiteRatio)
-	fmt.Printf("=============================\n")
-
-	return sample
+	return sample, hsvHist
 }
 
 // NumColorBuckets is the number of quantized brightness buckets for histogram analysis.
@@ -2295,12 +2306,20 @@ func imageToMat(img image.Image) (gocv.Mat, error) {
 }
 
 // CreateOverlay creates a canvas overlay with white hollow rectangles for detected components.
+// Tilted components are drawn as rotated outlines.
 func CreateOverlay(components []*Component) *canvas.Overlay {
 	overlay := &canvas.Overlay{
 		Color: color.RGBA{R: 255, G: 255, B: 255, A: 255}, // White
 	}
 
 	for _, comp := range components {
+		if comp.Tilt() != 0 {
+			overlay.Polygons = append(overlay.Polygons, canvas.OverlayPolygon{
+				Points: comp.Corners(),
+				Label:  comp.ID + " " + comp.Package,
+			})
+			continue
+		}
 		rect := canvas.OverlayRect{
 			X:      int(comp.Bounds.X),
 			Y:      int(comp.Bounds.Y),
@@ -2349,6 +2368,103 @@ func CreateDebugImage(img gocv.Mat, components []*Component) gocv.Mat {
 	return debug
 }
 
+// ColorSpace selects the color distance metric used by flood fill.
+type ColorSpace int
+
+const (
+	// ColorSpaceRGB matches when every RGB channel is within the tolerance.
+	ColorSpaceRGB ColorSpace = iota
+	// ColorSpaceHSV weights hue over saturation, and both over value, so a
+	// region floods across specular glare and shading on a uniformly
+	// colored body. Hue counts only in proportion to saturation, since it
+	// is noise on near-gray pixels.
+	ColorSpaceHSV
+	// ColorSpaceLab matches on CIE76 ΔE, a roughly perceptual distance.
+	ColorSpaceLab
+)
+
+// String returns the color space name.
+func (s ColorSpace) String() string {
+	switch s {
+	case ColorSpaceRGB:
+		return "RGB"
+	case ColorSpaceHSV:
+		return "HSV"
+	case ColorSpaceLab:
+		return "Lab"
+	default:
+		return fmt.Sprintf("ColorSpace(%d)", int(s))
+	}
+}
+
+// FloodOptions configures FloodFillDetect and the grid scoring that trims
+// its result.
+type FloodOptions struct {
+	Tolerance    int        // Maximum color distance from the seed (0-255 scale; ΔE for Lab)
+	Space        ColorSpace // Distance metric
+	Connectivity int        // 4 or 8 neighbors
+}
+
+// DefaultFloodOptions returns hue-weighted HSV matching, which suits chip
+// bodies: uniformly dark with bright glare.
+func DefaultFloodOptions() FloodOptions {
+	return FloodOptions{Tolerance: 30, Space: ColorSpaceHSV, Connectivity: 4}
+}
+
+// HSV distance weights. Value barely counts so glare and shading stay
+// inside the region; saturation separates a gray body from colored mask.
+const (
+	floodHueWeight = 1.0
+	floodSatWeight = 0.5
+	floodValWeight = 0.15
+)
+
+// colorMatcher returns a function reporting whether an 8-bit RGB color is
+// within the tolerance of seed under the options' color space.
+func (o FloodOptions) colorMatcher(seed color.RGBA) func(r, g, b uint8) bool {
+	tol := float64(o.Tolerance)
+	switch o.Space {
+	case ColorSpaceHSV:
+		sh, ss, sv := colorutil.RGBToHSV(float64(seed.R), float64(seed.G), float64(seed.B))
+		return func(r, g, b uint8) bool {
+			h, s, v := colorutil.RGBToHSV(float64(r), float64(g), float64(b))
+			// Circular hue difference (0-90 in OpenCV units), scaled to
+			// 0-255 at full saturation
+			dh := math.Abs(h - sh)
+			if dh > 90 {
+				dh = 180 - dh
+			}
+			dh *= math.Min(s, ss) / 90 * floodHueWeight
+			ds := (s - ss) * floodSatWeight
+			dv := (v - sv) * floodValWeight
+			return dh*dh+ds*ds+dv*dv <= tol*tol
+		}
+	case ColorSpaceLab:
+		sl, sa, sb := colorutil.RGBToLab(float64(seed.R), float64(seed.G), float64(seed.B))
+		return func(r, g, b uint8) bool {
+			l, a, bb := colorutil.RGBToLab(float64(r), float64(g), float64(b))
+			dl, da, db := l-sl, a-sa, bb-sb
+			return dl*dl+da*da+db*db <= tol*tol
+		}
+	default:
+		seedR, seedG, seedB := int(seed.R), int(seed.G), int(seed.B)
+		return func(r, g, b uint8) bool {
+			return absDiff(int(r), seedR) <= o.Tolerance &&
+				absDiff(int(g), seedG) <= o.Tolerance &&
+				absDiff(int(b), seedB) <= o.Tolerance
+		}
+	}
+}
+
+// imageColorMatcher adapts colorMatcher to test pixels of img.
+func (o FloodOptions) imageColorMatcher(img image.Image, seed color.RGBA) func(x, y int) bool {
+	match := o.colorMatcher(seed)
+	return func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return match(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+	}
+}
+
 // FloodFillResult contains the result of a flood fill operation.
 type FloodFillResult struct {
 	Bounds     geometry.RectInt // Bounding box of filled region
@@ -2358,8 +2474,9 @@ type FloodFillResult struct {
 
 // FloodFillDetect performs a flood fill from a click point to find a component region.
 // Returns the bounding box of connected similar-color pixels.
-// colorTolerance is the maximum difference in each RGB channel to consider a match (0-255).
-func FloodFillDetect(img image.Image, clickX, clickY int, colorTolerance int) (*FloodFillResult, error) {
+// Pixels match by opts.Space within opts.Tolerance of the seed color and
+// connect through opts.Connectivity neighbors (4 unless set to 8).
+func FloodFillDetect(img image.Image, clickX, clickY int, opts FloodOptions) (*FloodFillResult, error) {
 	bounds := img.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
 
@@ -2377,8 +2494,8 @@ func FloodFillDetect(img image.Image, clickX, clickY int, colorTolerance int) (*
 	seedB := uint8(sb >> 8)
 
 	if false {
-		fmt.Printf("FloodFill: seed at (%d,%d) color RGB(%d,%d,%d) tolerance=%d\n",
-			clickX, clickY, seedR, seedG, seedB, colorTolerance)
+		fmt.Printf("FloodFill: seed at (%d,%d) color RGB(%d,%d,%d) %s tolerance=%d\n",
+			clickX, clickY, seedR, seedG, seedB, opts.Space, opts.Tolerance)
 	}
 
 	// Visited map
@@ -2393,19 +2510,7 @@ func FloodFillDetect(img image.Image, clickX, clickY int, colorTolerance int) (*
 	stack := []image.Point{{X: clickX, Y: clickY}}
 
 	// Color matching function
-	colorMatch := func(x, y int) bool {
-		c := img.At(x, y)
-		r, g, b, _ := c.RGBA()
-		pr := uint8(r >> 8)
-		pg := uint8(g >> 8)
-		pb := uint8(b >> 8)
-
-		dr := absDiff(int(pr), int(seedR))
-		dg := absDiff(int(pg), int(seedG))
-		db := absDiff(int(pb), int(seedB))
-
-		return dr <= colorTolerance && dg <= colorTolerance && db <= colorTolerance
-	}
+	colorMatch := opts.imageColorMatcher(img, color.RGBA{R: seedR, G: seedG, B: seedB, A: 255})
 
 	// Flood fill
 	for len(stack) > 0 {
@@ -2449,13 +2554,21 @@ func FloodFillDetect(img image.Image, clickX, clickY int, colorTolerance int) (*
 			maxY = y
 		}
 
-		// Add neighbors (4-connected)
+		// Add neighbors (4-connected, plus diagonals when 8-connected)
 		stack = append(stack,
 			image.Point{X: x + 1, Y: y},
 			image.Point{X: x - 1, Y: y},
 			image.Point{X: x, Y: y + 1},
 			image.Point{X: x, Y: y - 1},
 		)
+		if opts.Connectivity == 8 {
+			stack = append(stack,
+				image.Point{X: x + 1, Y: y + 1},
+				image.Point{X: x - 1, Y: y + 1},
+				image.Point{X: x + 1, Y: y - 1},
+				image.Point{X: x - 1, Y: y - 1},
+			)
+		}
 	}
 
 	if pixelCount == 0 {
@@ -2508,7 +2621,7 @@ type GridScoreResult struct {
 }
 
 // GetGridScores computes and returns the scoring grid data for debug visualization.
-func GetGridScores(img image.Image, bounds geometry.RectInt, seedColor color.RGBA, colorTolerance int, gridStep int, minScore float64) *GridScoreResult {
+func GetGridScores(img image.Image, bounds geometry.RectInt, seedColor color.RGBA, opts FloodOptions, gridStep int, minScore float64) *GridScoreResult {
 	if gridStep < 1 {
 		gridStep = 2
 	}
@@ -2516,22 +2629,8 @@ func GetGridScores(img image.Image, bounds geometry.RectInt, seedColor color.RGB
 		minScore = 0.5
 	}
 
-	seedR, seedG, seedB := int(seedColor.R), int(seedColor.G), int(seedColor.B)
-
 	// Color matching function
-	colorMatch := func(x, y int) bool {
-		c := img.At(x, y)
-		r, g, b, _ := c.RGBA()
-		pr := int(uint8(r >> 8))
-		pg := int(uint8(g >> 8))
-		pb := int(uint8(b >> 8))
-
-		dr := absDiff(pr, seedR)
-		dg := absDiff(pg, seedG)
-		db := absDiff(pb, seedB)
-
-		return dr <= colorTolerance && dg <= colorTolerance && db <= colorTolerance
-	}
+	colorMatch := opts.imageColorMatcher(img, seedColor)
 
 	// Calculate grid dimensions
 	cols := (bounds.Width + gridStep - 1) / gridStep
@@ -2685,7 +2784,7 @@ func GetGridScores(img image.Image, bounds geometry.RectInt, seedColor color.RGB
 // This removes green PCB areas and metallic pins that got included in the initial flood fill.
 // gridStep is the pixel spacing for the scoring grid (e.g., 2-4 pixels).
 // minScore is the minimum percentage (0-1) of matching pixels required to keep a row/column.
-func TrimFloodFillBounds(img image.Image, bounds geometry.RectInt, seedColor color.RGBA, colorTolerance int, gridStep int, minScore float64) geometry.RectInt {
+func TrimFloodFillBounds(img image.Image, bounds geometry.RectInt, seedColor color.RGBA, opts FloodOptions, gridStep int, minScore float64) geometry.RectInt {
 	if gridStep < 1 {
 		gridStep = 2
 	}
@@ -2693,22 +2792,8 @@ func TrimFloodFillBounds(img image.Image, bounds geometry.RectInt, seedColor col
 		minScore = 0.5 // Default: require 50% matching pixels
 	}
 
-	seedR, seedG, seedB := int(seedColor.R), int(seedColor.G), int(seedColor.B)
-
 	// Color matching function
-	colorMatch := func(x, y int) bool {
-		c := img.At(x, y)
-		r, g, b, _ := c.RGBA()
-		pr := int(uint8(r >> 8))
-		pg := int(uint8(g >> 8))
-		pb := int(uint8(b >> 8))
-
-		dr := absDiff(pr, seedR)
-		dg := absDiff(pg, seedG)
-		db := absDiff(pb, seedB)
-
-		return dr <= colorTolerance && dg <= colorTolerance && db <= colorTolerance
-	}
+	colorMatch := opts.imageColorMatcher(img, seedColor)
 
 	// Calculate grid dimensions
 	cols := (bounds.Width + gridStep - 1) / gridStep
@@ -0,0 +1,184 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// maxTrainingDistance is the largest TrainingSet.Nearest distance at which a
+// candidate still counts as resembling a training sample.
+const maxTrainingDistance = 1.0
+
+// DetectAll scans the whole board for IC bodies and returns unconfirmed
+// components for the user to review. Candidate blobs come from the grid
+// detector with params derived from ts. Each candidate must then have a
+// DIP body size and resemble a training sample in color. Where candidates
+// overlap, only the one closest to the training set is kept. Returned
+// components have a guessed DIP package but no ID.
+//
+// If ctx is canceled while candidates are being checked, the candidates
+// accepted so far are returned along with ctx.Err().
+func DetectAll(ctx context.Context, img image.Image, dpi float64, ts *TrainingSet) ([]*Component, error) {
+	if ts == nil || len(ts.Samples) == 0 || dpi <= 0 {
+		return nil, nil
+	}
+	params := ts.DeriveParams()
+	mmToPixels := dpi / 25.4
+
+	mat, err := imageToMat(img)
+	if err != nil {
+		return nil, fmt.Errorf("image conversion error: %w", err)
+	}
+	defer mat.Close()
+
+	// Restrict the scan to the board, as the Detect Components button does
+	cellMM := params.CellSizeMM
+	if cellMM <= 0 {
+		cellMM = 2.0
+	}
+	cellPx := max(int(cellMM*mmToPixels), 4)
+	var boardRect *geometry.Rect
+	if board := DetectBoardBoundsMat(mat, cellPx*5); board != nil {
+		boardRect = &board.Bounds
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result, err := DetectComponentsMatWithBounds(mat, dpi, params, boardRect)
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("detection failed: no result")
+	}
+
+	type candidate struct {
+		bounds geometry.Rect
+		dist   float64
+	}
+	var cands []candidate
+	var rejectedSize, rejectedColor int
+	for _, rb := range result.RefinedBounds {
+		if ctx.Err() != nil {
+			break
+		}
+		b := geometry.Rect{
+			X:      float64(rb.Min.X),
+			Y:      float64(rb.Min.Y),
+			Width:  float64(rb.Dx()),
+			Height: float64(rb.Dy()),
+		}
+		if !isDIPBodySize(b.Width/mmToPixels, b.Height/mmToPixels) {
+			rejectedSize++
+			continue
+		}
+		features, _ := sampleFeaturesMat(mat, b, dpi)
+		_, dist := ts.Nearest(features)
+		if dist > maxTrainingDistance {
+			rejectedColor++
+			continue
+		}
+		cands = append(cands, candidate{bounds: b, dist: dist})
+	}
+
+	// Keep the best-matching candidate of each overlapping group
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	var comps []*Component
+	for _, c := range cands {
+		overlaps := false
+		for _, kept := range comps {
+			if BoundsOverlap(c.bounds, kept.Bounds) {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		shortPx, longPx := c.bounds.Width, c.bounds.Height
+		if shortPx > longPx {
+			shortPx, longPx = longPx, shortPx
+		}
+		comps = append(comps, &Component{
+			Package: classifyPackage(shortPx, longPx, mmToPixels),
+			Bounds:  c.bounds,
+			Layer:   pcbimage.SideFront,
+		})
+	}
+
+	fmt.Printf("DetectAll: %d candidates, %d wrong size, %d unlike training, %d overlapping, %d kept\n",
+		len(result.RefinedBounds), rejectedSize, rejectedColor, len(cands)-len(comps), len(comps))
+	return comps, ctx.Err()
+}
+
+// isDIPBodySize reports whether a blob of the given size in mm could be a
+// DIP body: its short side a standard row width and its long side between
+// the shortest and longest standard packages.
+func isDIPBodySize(widthMM, heightMM float64) bool {
+	shortMM, longMM := math.Min(widthMM, heightMM), math.Max(widthMM, heightMM)
+	if !IsValidDIPWidth(shortMM) {
+		return false
+	}
+	minLen, maxLen := math.Inf(1), 0.0
+	for _, p := range StandardPackages {
+		minLen = math.Min(minLen, p.Height)
+		maxLen = math.Max(maxLen, p.Height)
+	}
+	return longMM >= minLen-LengthToleranceMM && longMM <= maxLen+LengthToleranceMM
+}
+
+// Nearest returns the index of the training sample most similar in color
+// to f and its distance. Distance is in units of the typical spread within
+// one component type: about 1.5 histogram buckets of background brightness
+// and 48 levels of saturation. It returns -1 and +Inf for an empty set.
+func (ts *TrainingSet) Nearest(f TrainingSample) (int, float64) {
+	best, bestDist := -1, math.Inf(1)
+	fv := f.BackgroundVal
+	if fv == 0 {
+		fv = f.MeanVal
+	}
+	for i, s := range ts.Samples {
+		sv := s.BackgroundVal
+		if sv == 0 {
+			sv = s.MeanVal
+		}
+		dv := (fv - sv) / (1.5 * ColorBucketWidth)
+		ds := (f.MeanSat - s.MeanSat) / 48
+		if d := math.Hypot(dv, ds); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best, bestDist
+}
+
+// BoundsOverlap reports whether two component rectangles should be treated
+// as the same component: either center lies inside the other rectangle, or
+// their intersection covers more than 25% of either area.
+func BoundsOverlap(a, b geometry.Rect) bool {
+	acx, acy := a.X+a.Width/2, a.Y+a.Height/2
+	bcx, bcy := b.X+b.Width/2, b.Y+b.Height/2
+	if acx >= b.X && acx <= b.X+b.Width && acy >= b.Y && acy <= b.Y+b.Height {
+		return true
+	}
+	if bcx >= a.X && bcx <= a.X+a.Width && bcy >= a.Y && bcy <= a.Y+a.Height {
+		return true
+	}
+	ix0 := math.Max(a.X, b.X)
+	iy0 := math.Max(a.Y, b.Y)
+	ix1 := math.Min(a.X+a.Width, b.X+b.Width)
+	iy1 := math.Min(a.Y+a.Height, b.Y+b.Height)
+	if ix1 <= ix0 || iy1 <= iy0 {
+		return false
+	}
+	inter := (ix1 - ix0) * (iy1 - iy0)
+	aArea := a.Width * a.Height
+	bArea := b.Width * b.Height
+	return aArea > 0 && inter/aArea > 0.25 || bArea > 0 && inter/bArea > 0.25
+}
@@ -0,0 +1,145 @@
+package component
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GridMarker is one coordinate label of a silkscreen grid axis.
+type GridMarker struct {
+	Label string  // Marker text, e.g. "B" or "7"
+	Pos   float64 // Center along the axis in image coordinates
+}
+
+// GridAxis is a row of silkscreen coordinate markers. Markers of the X axis
+// are positioned along X and label columns; those of the Y axis along Y and
+// label rows.
+type GridAxis struct {
+	Markers []GridMarker
+}
+
+// GridIDChange is the new ID AssignGridIDs gives a component.
+type GridIDChange struct {
+	Component *Component
+	OldID     string
+	NewID     string
+}
+
+// designatorIDPattern splits a designator-style ID into class and number.
+var designatorIDPattern = regexp.MustCompile(`^([A-Z]+)\d+$`)
+
+// AssignGridIDs computes an ID for every component from the grid cell its
+// center falls in, e.g. "U-B7": the class prefix, a dash, then the nearest
+// marker of each axis with the letter axis first. The prefix is kept from
+// an ID that already has one ("U-A3"), taken from a designator ("VR2"), or
+// defaults to "U"; a plain grid ID such as "C12" counts as a cell, not a
+// capacitor. Components sharing a cell are suffixed in reading order, top
+// to bottom then left to right ("U-B7", "U-B7-2"). The result depends only
+// on positions and current prefixes, never on the order components were
+// added. Only components whose ID changes are returned, in natural order of
+// the new IDs; nothing is modified until ApplyGridIDs.
+func AssignGridIDs(components []*Component, xAxis, yAxis *GridAxis) ([]GridIDChange, error) {
+	if xAxis == nil || len(xAxis.Markers) == 0 {
+		return nil, fmt.Errorf("no X axis markers")
+	}
+	if yAxis == nil || len(yAxis.Markers) == 0 {
+		return nil, fmt.Errorf("no Y axis markers")
+	}
+
+	var comps []*Component
+	for _, c := range components {
+		if c != nil {
+			comps = append(comps, c)
+		}
+	}
+	sort.SliceStable(comps, func(i, j int) bool {
+		ci, cj := comps[i].Center(), comps[j].Center()
+		if ci.Y != cj.Y {
+			return ci.Y < cj.Y
+		}
+		if ci.X != cj.X {
+			return ci.X < cj.X
+		}
+		return NaturalLess(comps[i].ID, comps[j].ID)
+	})
+
+	yFirst := isLetterLabel(yAxis.Markers[0].Label) && !isLetterLabel(xAxis.Markers[0].Label)
+
+	var changes []GridIDChange
+	used := make(map[string]bool, len(comps))
+	for _, c := range comps {
+		center := c.Center()
+		col := nearestMarker(xAxis.Markers, center.X)
+		row := nearestMarker(yAxis.Markers, center.Y)
+		cell := col + row
+		if yFirst {
+			cell = row + col
+		}
+
+		base := gridIDPrefix(c.ID) + "-" + cell
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+
+		if id != c.ID {
+			changes = append(changes, GridIDChange{Component: c, OldID: c.ID, NewID: id})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return NaturalLess(changes[i].NewID, changes[j].NewID)
+	})
+	return changes, nil
+}
+
+// ApplyGridIDs sets each component's ID from changes and returns the
+// renames as a map from old ID to new, for updating references to the
+// components elsewhere (see DetectedFeaturesLayer.RenameComponents).
+func ApplyGridIDs(changes []GridIDChange) map[string]string {
+	renames := make(map[string]string, len(changes))
+	for _, ch := range changes {
+		ch.Component.ID = ch.NewID
+		renames[ch.OldID] = ch.NewID
+	}
+	return renames
+}
+
+// nearestMarker returns the label of the marker closest to pos.
+func nearestMarker(markers []GridMarker, pos float64) string {
+	best := markers[0]
+	for _, m := range markers[1:] {
+		if math.Abs(m.Pos-pos) < math.Abs(best.Pos-pos) {
+			best = m
+		}
+	}
+	return strings.ToUpper(strings.TrimSpace(best.Label))
+}
+
+// isLetterLabel reports whether a marker label is a letter rather than a
+// number.
+func isLetterLabel(label string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(label))
+	return unicode.IsLetter(r)
+}
+
+// gridIDPrefix returns the component class prefix to keep for id.
+func gridIDPrefix(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if prefix, _, ok := strings.Cut(id, "-"); ok && prefix != "" && strings.Trim(prefix, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+		return prefix
+	}
+	if ParseGridID(id) == nil {
+		// "NEW" is SuggestComponentID's fallback, not a class
+		if m := designatorIDPattern.FindStringSubmatch(id); m != nil && m[1] != "NEW" {
+			return m[1]
+		}
+	}
+	return "U"
+}
@@ -0,0 +1,106 @@
+package component
+
+import (
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// gridComp returns a 20x10 component centered on (x, y).
+func gridComp(id string, x, y float64) *Component {
+	c := NewComponent(id)
+	c.Bounds = geometry.Rect{X: x - 10, Y: y - 5, Width: 20, Height: 10}
+	return c
+}
+
+func TestAssignGridIDs(t *testing.T) {
+	xAxis := &GridAxis{Markers: []GridMarker{{"1", 100}, {"2", 200}, {"3", 300}}}
+	yAxis := &GridAxis{Markers: []GridMarker{{"A", 100}, {"B", 200}}}
+
+	build := func() []*Component {
+		return []*Component{
+			gridComp("C12", 200, 100),  // Plain grid ID: a cell, not a capacitor
+			gridComp("VR2", 290, 210),  // Designator: class kept
+			gridComp("U-B1", 100, 200), // Already right
+			gridComp("NEW3", 105, 205), // Same cell, later in reading order
+			gridComp("SW-A9", 310, 95), // Grid ID with prefix: prefix kept
+		}
+	}
+	want := map[string]string{
+		"C12":   "U-A2",
+		"VR2":   "VR-B3",
+		"NEW3":  "U-B1-2",
+		"SW-A9": "SW-A3",
+	}
+
+	comps := build()
+	changes, err := AssignGridIDs(comps, xAxis, yAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, ch := range changes {
+		if want[ch.OldID] != ch.NewID {
+			t.Errorf("%s -> %s, want %s", ch.OldID, ch.NewID, want[ch.OldID])
+		}
+		if i > 0 && !NaturalLess(changes[i-1].NewID, ch.NewID) {
+			t.Errorf("changes not in natural order: %s before %s", changes[i-1].NewID, ch.NewID)
+		}
+	}
+	for i, c := range build() {
+		if comps[i].ID != c.ID {
+			t.Errorf("AssignGridIDs renamed %s to %s before ApplyGridIDs", c.ID, comps[i].ID)
+		}
+	}
+
+	// The result must not depend on the order components were added
+	rev := build()
+	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
+		rev[i], rev[j] = rev[j], rev[i]
+	}
+	revChanges, err := AssignGridIDs(rev, xAxis, yAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range revChanges {
+		if want[ch.OldID] != ch.NewID {
+			t.Errorf("reversed input: %s -> %s, want %s", ch.OldID, ch.NewID, want[ch.OldID])
+		}
+	}
+
+	renames := ApplyGridIDs(changes)
+	if len(renames) != len(want) {
+		t.Errorf("ApplyGridIDs returned %d renames, want %d", len(renames), len(want))
+	}
+	for old, id := range want {
+		if renames[old] != id {
+			t.Errorf("renames[%s] = %q, want %q", old, renames[old], id)
+		}
+	}
+	for _, c := range comps {
+		if _, old := want[c.ID]; old {
+			t.Errorf("component %s not renamed", c.ID)
+		}
+	}
+
+	// Applied IDs are stable
+	again, err := AssignGridIDs(comps, xAxis, yAxis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second pass changed %+v", again)
+	}
+}
+
+func TestAssignGridIDsNoAxes(t *testing.T) {
+	axis := &GridAxis{Markers: []GridMarker{{"1", 100}}}
+	if _, err := AssignGridIDs(nil, nil, axis); err == nil {
+		t.Error("no X axis: want error")
+	}
+	if _, err := AssignGridIDs(nil, axis, &GridAxis{}); err == nil {
+		t.Error("empty Y axis: want error")
+	}
+}
@@ -0,0 +1,64 @@
+package component
+
+import "strings"
+
+// NaturalLess compares two strings using natural numeric ordering.
+// "A2" < "A10", "U1" < "U2" < "U10", etc.
+func NaturalLess(a, b string) bool {
+	chunksA := splitNatural(a)
+	chunksB := splitNatural(b)
+	for i := 0; i < len(chunksA) && i < len(chunksB); i++ {
+		ca, cb := chunksA[i], chunksB[i]
+		if isNumeric(ca) && isNumeric(cb) {
+			na := parseNum(ca)
+			nb := parseNum(cb)
+			if na != nb {
+				return na < nb
+			}
+		} else {
+			cmp := strings.Compare(strings.ToUpper(ca), strings.ToUpper(cb))
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+	}
+	return len(chunksA) < len(chunksB)
+}
+
+func splitNatural(s string) []string {
+	var chunks []string
+	var current strings.Builder
+	wasDigit := false
+	for i, r := range s {
+		isDigit := r >= '0' && r <= '9'
+		if i > 0 && isDigit != wasDigit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		current.WriteRune(r)
+		wasDigit = isDigit
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+func parseNum(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			n = n*10 + int(r-'0')
+		}
+	}
+	return n
+}
@@ -55,9 +55,9 @@ func ExpectedDIPPinPositions(comp *Component, dpi float64) []ExpectedPin {
 		shortAxis = geometry.Point2D{X: 0, Y: 1}
 	}
 
-	// Apply component rotation if non-zero
-	if comp.Rotation != 0 {
-		rad := comp.Rotation * math.Pi / 180.0
+	// Apply the component tilt if non-zero (Bounds already give the quarter turn)
+	if tilt := comp.Tilt(); tilt != 0 {
+		rad := tilt * math.Pi / 180.0
 		cos, sin := math.Cos(rad), math.Sin(rad)
 		longAxis = geometry.Point2D{
 			X: longAxis.X*cos - longAxis.Y*sin,
@@ -104,6 +104,185 @@ func ExpectedDIPPinPositions(comp *Component, dpi float64) []ExpectedPin {
 	return pins
 }
 
+// sipPinPitchMM is the standard 0.1" pitch used for SIP packages.
+const sipPinPitchMM = 2.54
+
+// ExpectedSIPPinPositions returns the expected image-coordinate positions for
+// all pins of a SIP component: a single row along the long axis of the bounds,
+// pin 1 at the top (vertical) or left (horizontal) end.
+// Returns nil if the package is not a SIP.
+func ExpectedSIPPinPositions(comp *Component, dpi float64) []ExpectedPin {
+	pinCount, ok := ParseSIPPinCount(comp.Package)
+	if !ok {
+		return nil
+	}
+
+	pitchPx := sipPinPitchMM / 25.4 * dpi
+	center := comp.Center()
+
+	var longAxis geometry.Point2D
+	if comp.Bounds.Height >= comp.Bounds.Width {
+		longAxis = geometry.Point2D{X: 0, Y: 1}
+	} else {
+		longAxis = geometry.Point2D{X: 1, Y: 0}
+	}
+	if tilt := comp.Tilt(); tilt != 0 {
+		rad := tilt * math.Pi / 180.0
+		cos, sin := math.Cos(rad), math.Sin(rad)
+		longAxis = geometry.Point2D{
+			X: longAxis.X*cos - longAxis.Y*sin,
+			Y: longAxis.X*sin + longAxis.Y*cos,
+		}
+	}
+
+	pins := make([]ExpectedPin, pinCount)
+	halfCenter := float64(pinCount-1) / 2.0
+	for i := 0; i < pinCount; i++ {
+		offset := (float64(i) - halfCenter) * pitchPx
+		pins[i] = ExpectedPin{
+			Number: i + 1,
+			Position: geometry.Point2D{
+				X: center.X + longAxis.X*offset,
+				Y: center.Y + longAxis.Y*offset,
+			},
+			Row: 1,
+		}
+	}
+	return pins
+}
+
+// ExpectedPinPositions returns expected pin positions for any package with
+// known pin geometry (DIP or SIP). Returns nil for other packages.
+func ExpectedPinPositions(comp *Component, dpi float64) []ExpectedPin {
+	if pins := ExpectedDIPPinPositions(comp, dpi); pins != nil {
+		return pins
+	}
+	return ExpectedSIPPinPositions(comp, dpi)
+}
+
+// UnmatchedPin identifies a component pin with no via near its expected position.
+type UnmatchedPin struct {
+	ComponentID string
+	PinNumber   int
+}
+
+// PinAssignmentReport summarizes an AssignViasToPins pass.
+type PinAssignmentReport struct {
+	Assigned      int                 // Vias newly linked to a pin
+	UnmatchedVias []*via.ConfirmedVia // Unlinked vias inside a component footprint
+	UnmatchedPins []UnmatchedPin      // Pins with no via within tolerance
+}
+
+// AssignViasToPins links confirmed vias to DIP/SIP component pins by proximity.
+// Expected pin positions come from the component bounds and package geometry;
+// each pin takes the nearest free via within 40% of the pin pitch, closest
+// pairs first. Vias already linked to a component are left alone and their pins
+// count as matched. The caller is responsible for resolving signal names.
+func AssignViasToPins(comps []*Component, vias []*via.ConfirmedVia, dpi float64) PinAssignmentReport {
+	var report PinAssignmentReport
+	if dpi <= 0 {
+		return report
+	}
+
+	type candidate struct {
+		comp *Component
+		pin  int
+		cv   *via.ConfirmedVia
+		dist float64
+	}
+
+	tolerance := 0.4 * 0.1 * dpi // 40% of 0.1" pitch
+	taken := make(map[*via.ConfirmedVia]bool)
+	pinDone := make(map[string]bool) // "compID/pin"
+	pinKey := func(id string, n int) string { return id + "/" + strconv.Itoa(n) }
+
+	for _, cv := range vias {
+		if cv.ComponentID != "" {
+			taken[cv] = true
+			if n, err := strconv.Atoi(cv.PinNumber); err == nil {
+				pinDone[pinKey(cv.ComponentID, n)] = true
+			}
+		}
+	}
+
+	var cands []candidate
+	var allPins []UnmatchedPin
+	var footprints []geometry.Rect
+	for _, comp := range comps {
+		expected := ExpectedPinPositions(comp, dpi)
+		if len(expected) == 0 {
+			continue
+		}
+		footprints = append(footprints, geometry.Rect{
+			X:      comp.Bounds.X - tolerance,
+			Y:      comp.Bounds.Y - tolerance,
+			Width:  comp.Bounds.Width + 2*tolerance,
+			Height: comp.Bounds.Height + 2*tolerance,
+		})
+		for _, ep := range expected {
+			allPins = append(allPins, UnmatchedPin{ComponentID: comp.ID, PinNumber: ep.Number})
+			if pinDone[pinKey(comp.ID, ep.Number)] {
+				continue
+			}
+			for _, cv := range vias {
+				if taken[cv] {
+					continue
+				}
+				d := ep.Position.Distance(cv.Center)
+				if d <= tolerance {
+					cands = append(cands, candidate{comp: comp, pin: ep.Number, cv: cv, dist: d})
+				}
+			}
+		}
+	}
+
+	sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	for _, c := range cands {
+		key := pinKey(c.comp.ID, c.pin)
+		if taken[c.cv] || pinDone[key] {
+			continue
+		}
+		c.cv.ComponentID = c.comp.ID
+		c.cv.PinNumber = strconv.Itoa(c.pin)
+		c.cv.SignalName = ""
+		taken[c.cv] = true
+		pinDone[key] = true
+		report.Assigned++
+	}
+
+	for _, p := range allPins {
+		if !pinDone[pinKey(p.ComponentID, p.PinNumber)] {
+			report.UnmatchedPins = append(report.UnmatchedPins, p)
+		}
+	}
+	for _, cv := range vias {
+		if taken[cv] {
+			continue
+		}
+		for _, fp := range footprints {
+			if fp.Contains(cv.Center) {
+				report.UnmatchedVias = append(report.UnmatchedVias, cv)
+				break
+			}
+		}
+	}
+
+	return report
+}
+
+// ParseSIPPinCount extracts the pin count from a SIP package string (e.g. "SIP-9" → 9).
+func ParseSIPPinCount(pkg string) (int, bool) {
+	pkg = strings.ToUpper(strings.TrimSpace(pkg))
+	if !strings.HasPrefix(pkg, "SIP-") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(pkg[4:])
+	if err != nil || n < 2 {
+		return 0, false
+	}
+	return n, true
+}
+
 // DetectPins finds DIP pin pads on the back image. The approach exploits the
 // manufacturing reality that all pads for a DIP are identical in size and on a
 // perfectly regular grid:
@@ -854,3 +1033,82 @@ func ParseDIPPinCount(pkg string) (int, bool) {
 	}
 	return n, true
 }
+
+// PackagePinCount returns the pin count for a component's package. The parts
+// library definition wins when one exists; otherwise the count is parsed from
+// the package name. Returns 0 if the pin count is unknown.
+func PackagePinCount(comp *Component, lib *ComponentLibrary) int {
+	if comp == nil {
+		return 0
+	}
+	if lib != nil && comp.PartNumber != "" {
+		if partDef := lib.GetByAlias(comp.PartNumber, comp.Package); partDef != nil && partDef.PinCount > 0 {
+			return partDef.PinCount
+		}
+	}
+	if n, ok := ParseDIPPinCount(comp.Package); ok {
+		return n
+	}
+	if n, ok := ParseSIPPinCount(comp.Package); ok {
+		return n
+	}
+	if pkg, ok := StandardPackages[strings.ToUpper(comp.Package)]; ok {
+		return pkg.PinCount
+	}
+	return 0
+}
+
+// AssignViaToPin links a confirmed via to a component pin and resolves its
+// signal name from the parts library. The pin may be given as a number or as
+// a pin name from the part definition (e.g. "VCC"). When the package pin count
+// is known, numbers outside 1..N are rejected and the via is left unchanged.
+// Returns the pin direction so callers can decide whether to rename nets.
+func AssignViaToPin(cv *via.ConfirmedVia, comp *Component, pin string, lib *ComponentLibrary) (connector.SignalDirection, error) {
+	if comp == nil {
+		return connector.DirectionInput, fmt.Errorf("no component")
+	}
+	pin = strings.TrimSpace(pin)
+	if pin == "" {
+		return connector.DirectionInput, fmt.Errorf("no pin number")
+	}
+
+	pinCount := PackagePinCount(comp, lib)
+	pinNum, err := strconv.Atoi(pin)
+	if err != nil {
+		// Not a number: look the name up in the part definition
+		pinNum = 0
+		if lib != nil {
+			if partDef := lib.GetByAlias(comp.PartNumber, comp.Package); partDef != nil {
+				for _, p := range partDef.Pins {
+					if strings.EqualFold(p.Name, pin) {
+						pinNum = p.Number
+						break
+					}
+				}
+			}
+		}
+		if pinNum == 0 {
+			if pinCount > 0 {
+				return connector.DirectionInput, fmt.Errorf("%s has no pin named %q", comp.ID, pin)
+			}
+			// Unknown package: keep the free-form pin label
+			cv.ComponentID = comp.ID
+			cv.PinNumber = pin
+			cv.SignalName = ""
+			return connector.DirectionInput, nil
+		}
+	}
+
+	if pinNum < 1 || (pinCount > 0 && pinNum > pinCount) {
+		if pinCount > 0 {
+			return connector.DirectionInput, fmt.Errorf("pin %d out of range for %s (%s has %d pins)",
+				pinNum, comp.ID, comp.Package, pinCount)
+		}
+		return connector.DirectionInput, fmt.Errorf("invalid pin number %d", pinNum)
+	}
+
+	cv.ComponentID = comp.ID
+	cv.PinNumber = strconv.Itoa(pinNum)
+	cv.SignalName = ""
+	return ResolveSignalName(cv, []*Component{comp}, lib), nil
+}
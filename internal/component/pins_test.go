@@ -0,0 +1,224 @@
+package component
+
+import (
+	"testing"
+
+	"pcb-tracer/internal/connector"
+	"pcb-tracer/internal/via"
+	"pcb-tracer/pkg/geometry"
+)
+
+// timerLibrary holds a 555 timer in DIP-8 and a 20-pin part whose package
+// name carries no pin count.
+func timerLibrary() *ComponentLibrary {
+	lib := NewComponentLibrary()
+	lib.Add(&PartDefinition{PartNumber: "NE555", Package: "DIP-8", PinCount: 8, Pins: []PartPin{
+		{Number: 1, Name: "GND", Direction: connector.DirectionGround},
+		{Number: 3, Name: "OUT", Direction: connector.DirectionOutput},
+		{Number: 8, Name: "VCC", Direction: connector.DirectionPower},
+	}})
+	lib.Add(&PartDefinition{PartNumber: "AM2920", Package: "PLCC", PinCount: 20})
+	return lib
+}
+
+func TestPackagePinCount(t *testing.T) {
+	lib := timerLibrary()
+	cases := []struct {
+		name string
+		comp *Component
+		lib  *ComponentLibrary
+		want int
+	}{
+		{"nil component", nil, lib, 0},
+		{"DIP from the package name", &Component{Package: "DIP-14"}, nil, 14},
+		{"lower-case SIP", &Component{Package: "sip-9"}, nil, 9},
+		{"library part", &Component{PartNumber: "NE555", Package: "DIP-8"}, lib, 8},
+		{"library wins for an unparsed package", &Component{PartNumber: "AM2920", Package: "PLCC"}, lib, 20},
+		{"unparsed package without the library", &Component{PartNumber: "AM2920", Package: "PLCC"}, nil, 0},
+		{"part not in the library", &Component{PartNumber: "74LS00", Package: "DIP-14"}, lib, 14},
+		{"odd DIP count", &Component{Package: "DIP-7"}, nil, 0},
+		{"no package", &Component{}, lib, 0},
+	}
+	for _, c := range cases {
+		if got := PackagePinCount(c.comp, c.lib); got != c.want {
+			t.Errorf("%s: %d pins, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAssignViaToPin(t *testing.T) {
+	lib := timerLibrary()
+	timer := &Component{ID: "U3", PartNumber: "NE555", Package: "DIP-8"}
+	unknown := &Component{ID: "X1"}
+
+	cases := []struct {
+		name       string
+		comp       *Component
+		pin        string
+		wantErr    bool
+		wantPin    string
+		wantSignal string
+		wantDir    connector.SignalDirection
+	}{
+		{"pin number", timer, "3", false, "3", "U3-OUT", connector.DirectionOutput},
+		{"pin name", timer, " vcc ", false, "8", "U3-VCC", connector.DirectionPower},
+		{"unnamed pin in range", timer, "5", false, "5", "", connector.DirectionInput},
+		{"past the last pin", timer, "9", true, "", "", 0},
+		{"pin zero", timer, "0", true, "", "", 0},
+		{"unknown pin name", timer, "CLK", true, "", "", 0},
+		{"empty pin", timer, "", true, "", "", 0},
+		{"free-form pin on an unknown package", unknown, "A", false, "A", "", connector.DirectionInput},
+		{"any number on an unknown package", unknown, "42", false, "42", "", connector.DirectionInput},
+		{"no component", nil, "1", true, "", "", 0},
+	}
+	for _, c := range cases {
+		cv := &via.ConfirmedVia{ID: "cvia-001", SignalName: "stale"}
+		dir, err := AssignViaToPin(cv, c.comp, c.pin, lib)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: want error", c.name)
+			}
+			if cv.ComponentID != "" || cv.PinNumber != "" || cv.SignalName != "stale" {
+				t.Errorf("%s: rejected pin changed the via to %q pin %q signal %q", c.name, cv.ComponentID, cv.PinNumber, cv.SignalName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if cv.ComponentID != c.comp.ID || cv.PinNumber != c.wantPin || cv.SignalName != c.wantSignal || dir != c.wantDir {
+			t.Errorf("%s: %q pin %q signal %q dir %v, want %q pin %q signal %q dir %v", c.name,
+				cv.ComponentID, cv.PinNumber, cv.SignalName, dir, c.comp.ID, c.wantPin, c.wantSignal, c.wantDir)
+		}
+	}
+}
+
+// TestAssignViasToPinsNearest places vias around a vertical DIP-8 at 254 DPI
+// (10 px per mm), where the pins are 25.4 px apart and a via must be within
+// 10.16 px of a pin to take it.
+func TestAssignViasToPinsNearest(t *testing.T) {
+	// Pins 1-4 run down x=61.9 from y=61.9; pins 5-8 run back up x=138.1
+	u1 := &Component{ID: "U1", Package: "DIP-8", Bounds: geometry.Rect{X: 70, Y: 50, Width: 60, Height: 100}}
+	at := func(x, y float64) *via.ConfirmedVia {
+		return &via.ConfirmedVia{Center: geometry.Point2D{X: x, Y: y}}
+	}
+	cases := []struct {
+		name    string
+		cv      *via.ConfirmedVia
+		wantPin string // "" for no pin
+	}{
+		{"on pin 1", at(61.9, 61.9), "1"},
+		{"off pin 2", at(66, 92), "2"},
+		{"nearer pin 4 than pin 3", at(61.9, 131), "4"},
+		{"on pin 8", at(138.1, 61.9), "8"},
+		{"between pins 2 and 3", at(61.9, 100), ""},
+		{"between the rows", at(100, 61.9), ""},
+	}
+	for _, c := range cases {
+		report := AssignViasToPins([]*Component{u1}, []*via.ConfirmedVia{c.cv}, 254)
+		if c.cv.PinNumber != c.wantPin {
+			t.Errorf("%s: pin %q, want %q", c.name, c.cv.PinNumber, c.wantPin)
+		}
+		if c.wantPin == "" {
+			if report.Assigned != 0 || len(report.UnmatchedVias) != 1 || report.UnmatchedVias[0] != c.cv {
+				t.Errorf("%s: assigned %d, unmatched vias %v, want the via reported unmatched", c.name, report.Assigned, report.UnmatchedVias)
+			}
+		} else if c.cv.ComponentID != "U1" || report.Assigned != 1 || len(report.UnmatchedPins) != 7 {
+			t.Errorf("%s: component %q, assigned %d, %d unmatched pins, want U1, 1, 7",
+				c.name, c.cv.ComponentID, report.Assigned, len(report.UnmatchedPins))
+		}
+	}
+
+	// Two vias contending for pin 1: the nearer one wins, the other is left
+	near, far := at(62, 63), at(55, 60)
+	AssignViasToPins([]*Component{u1}, []*via.ConfirmedVia{far, near}, 254)
+	if near.PinNumber != "1" || far.PinNumber != "" {
+		t.Errorf("contended pin 1: near via pin %q, far via pin %q, want 1 and none", near.PinNumber, far.PinNumber)
+	}
+}
+
+func TestExpectedSIPPinPositions(t *testing.T) {
+	// At 254 DPI the 0.1" SIP pitch is 25.4 px
+	pt := func(x, y float64) geometry.Point2D { return geometry.Point2D{X: x, Y: y} }
+	cases := []struct {
+		name string
+		comp *Component
+		want []geometry.Point2D // Pins 1..N
+	}{
+		{"horizontal, pin 1 left",
+			&Component{Package: "SIP-4", Bounds: geometry.Rect{X: 50, Y: 90, Width: 110, Height: 20}},
+			[]geometry.Point2D{pt(66.9, 100), pt(92.3, 100), pt(117.7, 100), pt(143.1, 100)}},
+		{"vertical, pin 1 at the top",
+			&Component{Package: "SIP-4", Bounds: geometry.Rect{X: 95, Y: 45, Width: 20, Height: 110}},
+			[]geometry.Point2D{pt(105, 61.9), pt(105, 87.3), pt(105, 112.7), pt(105, 138.1)}},
+		{"odd count centered",
+			&Component{Package: "sip-3", Bounds: geometry.Rect{X: 60, Y: 90, Width: 90, Height: 20}},
+			[]geometry.Point2D{pt(79.6, 100), pt(105, 100), pt(130.4, 100)}},
+		{"tilted 10° clockwise",
+			&Component{Package: "SIP-2", Rotation: 10, Bounds: geometry.Rect{X: 75, Y: 90, Width: 60, Height: 20}},
+			[]geometry.Point2D{pt(105-12.507, 100-2.205), pt(105+12.507, 100+2.205)}},
+		{"not a SIP", &Component{Package: "DIP-8", Bounds: geometry.Rect{Width: 60, Height: 100}}, nil},
+	}
+	for _, c := range cases {
+		pins := ExpectedSIPPinPositions(c.comp, 254)
+		if len(pins) != len(c.want) {
+			t.Errorf("%s: %d pins, want %d", c.name, len(pins), len(c.want))
+			continue
+		}
+		for i, p := range pins {
+			if p.Number != i+1 || p.Row != 1 || p.Position.Distance(c.want[i]) > 0.01 {
+				t.Errorf("%s: pin %d (row %d) at %v, want pin %d at %v", c.name, p.Number, p.Row, p.Position, i+1, c.want[i])
+			}
+		}
+	}
+}
+
+// TestAssignViasToPinsMissingPin assigns a DIP-8 and a SIP-4 with a via on
+// every pin but one each, and a DIP pin already linked by hand.
+func TestAssignViasToPinsMissingPin(t *testing.T) {
+	u1 := &Component{ID: "U1", Package: "DIP-8", Bounds: geometry.Rect{X: 70, Y: 50, Width: 60, Height: 100}}
+	rn1 := &Component{ID: "RN1", Package: "SIP-4", Bounds: geometry.Rect{X: 50, Y: 190, Width: 110, Height: 20}}
+	manual := &via.ConfirmedVia{ID: "manual", ComponentID: "U1", PinNumber: "6", Center: geometry.Point2D{X: 300, Y: 300}}
+
+	vias := []*via.ConfirmedVia{manual}
+	for _, ep := range ExpectedPinPositions(u1, 254) {
+		if ep.Number != 6 && ep.Number != 7 {
+			vias = append(vias, &via.ConfirmedVia{Center: ep.Position})
+		}
+	}
+	for _, ep := range ExpectedPinPositions(rn1, 254) {
+		if ep.Number != 3 {
+			// Scanned a little off the ideal grid
+			vias = append(vias, &via.ConfirmedVia{Center: geometry.Point2D{X: ep.Position.X + 3, Y: ep.Position.Y - 2}})
+		}
+	}
+
+	report := AssignViasToPins([]*Component{u1, rn1}, vias, 254)
+	if report.Assigned != 9 {
+		t.Errorf("assigned %d vias, want 9", report.Assigned)
+	}
+	want := []UnmatchedPin{{"U1", 7}, {"RN1", 3}}
+	if len(report.UnmatchedPins) != len(want) {
+		t.Fatalf("unmatched pins %v, want %v", report.UnmatchedPins, want)
+	}
+	for i, p := range report.UnmatchedPins {
+		if p != want[i] {
+			t.Errorf("unmatched pin %d = %v, want %v", i, p, want[i])
+		}
+	}
+	if len(report.UnmatchedVias) != 0 {
+		t.Errorf("%d unmatched vias, want none", len(report.UnmatchedVias))
+	}
+	if manual.PinNumber != "6" {
+		t.Errorf("hand-linked via moved to pin %q", manual.PinNumber)
+	}
+	seen := map[string]bool{}
+	for _, cv := range vias[1:] {
+		key := cv.ComponentID + "/" + cv.PinNumber
+		if cv.ComponentID == "" || seen[key] {
+			t.Errorf("via at %v assigned %q", cv.Center, key)
+		}
+		seen[key] = true
+	}
+}
@@ -0,0 +1,103 @@
+package component
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// ImportPlacement reads a CSV placement list with columns ID, package,
+// X (mm), Y (mm) and an optional rotation in degrees, and returns one
+// unconfirmed front-side component per row. Bounds are sized from the
+// package and centered on the given position; at 0° and 180° the long
+// axis is vertical, matching pin 1 at top-left. A first row whose X is
+// not a number is taken as a header and skipped.
+func ImportPlacement(r io.Reader, dpi float64) ([]*Component, error) {
+	if dpi <= 0 {
+		return nil, fmt.Errorf("invalid DPI %g", dpi)
+	}
+	pxPerMM := dpi / 25.4
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read placement: %w", err)
+	}
+
+	var comps []*Component
+	for i, rec := range records {
+		line := i + 1
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if len(rec) < 4 {
+			return nil, fmt.Errorf("line %d: want ID, package, X, Y[, rotation], got %d fields", line, len(rec))
+		}
+		x, errX := strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(rec[3]), 64)
+		if errX != nil && i == 0 {
+			continue // Header
+		}
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("line %d: invalid position %q, %q", line, rec[2], rec[3])
+		}
+		var rot float64
+		if len(rec) > 4 && strings.TrimSpace(rec[4]) != "" {
+			if rot, err = strconv.ParseFloat(strings.TrimSpace(rec[4]), 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid rotation %q", line, rec[4])
+			}
+		}
+
+		id := strings.TrimSpace(rec[0])
+		pkg := strings.ToUpper(strings.TrimSpace(rec[1]))
+		widthMM, lengthMM, ok := PackageSizeMM(pkg)
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown package %q", line, rec[1])
+		}
+
+		// Quarter turns swap the axes; other angles are rounded to the nearest one
+		quarter := int(math.Round(rot/90)) & 3
+		w, h := widthMM*pxPerMM, lengthMM*pxPerMM
+		if quarter%2 == 1 {
+			w, h = h, w
+		}
+
+		c := NewComponent(id)
+		c.Package = pkg
+		c.Layer = image.SideFront
+		c.Rotation = rot
+		c.Bounds = geometry.Rect{X: x*pxPerMM - w/2, Y: y*pxPerMM - h/2, Width: w, Height: h}
+		comps = append(comps, c)
+	}
+	return comps, nil
+}
+
+// PackageSizeMM returns the body width and length of a package in mm. Sizes
+// come from StandardPackages, falling back to the pin count for other DIP
+// and SIP packages.
+func PackageSizeMM(pkg string) (widthMM, lengthMM float64, ok bool) {
+	pkg = strings.ToUpper(strings.TrimSpace(pkg))
+	if p, found := StandardPackages[pkg]; found {
+		return p.Width, p.Height, true
+	}
+	if n, found := ParseDIPPinCount(pkg); found {
+		rowSpacing := NarrowDIPWidthMM
+		if n > 28 {
+			rowSpacing = WideDIPWidthMM
+		}
+		return rowSpacing - PinPitchMM/2, float64(n/2)*PinPitchMM + PinPitchMM/2, true
+	}
+	if n, found := ParseSIPPinCount(pkg); found {
+		return PinPitchMM, float64(n) * PinPitchMM, true
+	}
+	return 0, 0, false
+}
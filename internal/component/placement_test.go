@@ -0,0 +1,92 @@
+package component
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestImportPlacement(t *testing.T) {
+	// At 254 DPI one mm is 10 pixels
+	const csv = `ID, Package, X (mm), Y (mm), Rotation
+# Logic
+U1, DIP-14, 20, 30
+U2, dip-8, 50, 30, 90
+U3, DIP-10, 80, 30, 180
+
+RN1, SIP-4, 10, 70, 271
+U1, DIP-40, 120, 60, 0
+`
+	comps, err := ImportPlacement(strings.NewReader(csv), 254)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id, pkg string
+		rot     float64
+		bounds  geometry.Rect
+	}{
+		{"U1", "DIP-14", 0, geometry.Rect{X: 168.25, Y: 204.75, Width: 63.5, Height: 190.5}},
+		{"U2", "DIP-8", 90, geometry.Rect{X: 451.75, Y: 268.25, Width: 96.5, Height: 63.5}}, // Quarter turn: wide
+		{"U3", "DIP-10", 180, geometry.Rect{X: 768.25, Y: 230.15, Width: 63.5, Height: 139.7}},
+		{"RN1", "SIP-4", 271, geometry.Rect{X: 49.2, Y: 687.3, Width: 101.6, Height: 25.4}}, // Rounds to 270°
+		{"U1", "DIP-40", 0, geometry.Rect{X: 1123.8, Y: 337.1, Width: 152.4, Height: 525.8}},
+	}
+	if len(comps) != len(want) {
+		t.Fatalf("got %d components, want %d", len(comps), len(want))
+	}
+	near := func(a, b geometry.Rect) bool {
+		return math.Abs(a.X-b.X) < 1e-6 && math.Abs(a.Y-b.Y) < 1e-6 &&
+			math.Abs(a.Width-b.Width) < 1e-6 && math.Abs(a.Height-b.Height) < 1e-6
+	}
+	for i, w := range want {
+		c := comps[i]
+		if c.ID != w.id || c.Package != w.pkg || c.Rotation != w.rot {
+			t.Errorf("row %d: %s %s at %v°, want %s %s at %v°", i, c.ID, c.Package, c.Rotation, w.id, w.pkg, w.rot)
+		}
+		if !near(c.Bounds, w.bounds) {
+			t.Errorf("row %d: bounds %+v, want %+v", i, c.Bounds, w.bounds)
+		}
+		if c.Confirmed || c.Layer != image.SideFront {
+			t.Errorf("row %d: confirmed %v on %v, want unconfirmed on the front", i, c.Confirmed, c.Layer)
+		}
+	}
+
+	// Imported IDs that clash are renumbered; components already on the
+	// board keep theirs
+	existing := NewComponent("U2")
+	existing.Bounds = geometry.Rect{X: 1000, Y: 1000, Width: 63.5, Height: 190.5}
+	board := append([]*Component{existing}, comps...)
+	renamed := RenumberDuplicateIDs(board, 100)
+	if len(renamed) != 2 || renamed[0] != "" {
+		t.Errorf("renamed %v, want the second U1 and imported U2", renamed)
+	}
+	seen := make(map[string]bool)
+	for _, c := range board {
+		if seen[c.ID] {
+			t.Errorf("ID %s still used twice", c.ID)
+		}
+		seen[c.ID] = true
+	}
+	if board[0].ID != "U2" || board[1].ID != "U1" {
+		t.Errorf("first of each ID renumbered: %s, %s", board[0].ID, board[1].ID)
+	}
+
+	for _, c := range []struct {
+		name, csv string
+		dpi       float64
+	}{
+		{"unknown package", "U1, QFP-44, 10, 10\n", 254},
+		{"bad position", "U1, DIP-14, 10, 10\nU2, DIP-14, ten, 10\n", 254},
+		{"bad rotation", "U1, DIP-14, 10, 10, left\n", 254},
+		{"too few fields", "U1, DIP-14, 10\n", 254},
+		{"invalid DPI", "U1, DIP-14, 10, 10\n", 0},
+	} {
+		if _, err := ImportPlacement(strings.NewReader(c.csv), c.dpi); err == nil {
+			t.Errorf("%s: want error", c.name)
+		}
+	}
+}
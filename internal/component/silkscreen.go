@@ -0,0 +1,137 @@
+package component
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// DesignatorLabel is a component designator read from the silkscreen.
+type DesignatorLabel struct {
+	Text     string           // Full designator, e.g. "U7"
+	Prefix   string           // Component class, e.g. "U"
+	Bounds   geometry.RectInt // Label position in image coordinates
+	Rotation int              // Rotation the text was read at (0, 90, 180, 270)
+	Side     image.Side
+}
+
+// designatorBodyMM is the typical body size (width across, length along) in
+// mm for each designator class that names a placeable component. Connector
+// prefixes (J, P) and sockets (X) are left out: they name edge fingers and
+// headers, not parts to trace.
+var designatorBodyMM = map[string][2]float64{
+	"U": {6.35, 19.05}, // DIP-14
+	"R": {2.5, 6.5},    // 1/4 W axial
+	"C": {5.0, 5.0},    // Radial or disc
+	"D": {2.7, 5.2},    // DO-35/DO-41
+	"Q": {4.8, 4.8},    // TO-92
+	"L": {5.0, 10.0},   // Axial choke
+	"Y": {4.7, 11.0},   // HC-49
+	"K": {10.0, 20.0},  // Relay
+	"T": {10.0, 10.0},  // Transformer
+}
+
+// IsComponentDesignator reports whether prefix names a placeable component
+// class (as opposed to a connector or socket).
+func IsComponentDesignator(prefix string) bool {
+	_, ok := designatorBodyMM[prefix]
+	return ok
+}
+
+// PlaceFromDesignators creates an unconfirmed component for each silkscreen
+// designator of a placeable class. The body is sized from the class and set
+// just past the label in its reading direction (below text read at 0°),
+// with the long axis across the text the way DIPs are usually labeled. A
+// designator whose body would land on an existing component of the same
+// side is skipped as already placed. An ID that is already taken is
+// replaced using SuggestComponentID, falling back to a numeric suffix
+// ("U7-2").
+func PlaceFromDesignators(existing []*Component, labels []DesignatorLabel, dpi float64) []*Component {
+	if dpi <= 0 {
+		return nil
+	}
+	pxPerMM := dpi / 25.4
+
+	used := make(map[string]bool, len(existing)+len(labels))
+	for _, c := range existing {
+		if c != nil {
+			used[c.ID] = true
+		}
+	}
+
+	var placed []*Component
+	for _, l := range labels {
+		size, ok := designatorBodyMM[l.Prefix]
+		if !ok {
+			continue
+		}
+		labelRect := l.Bounds.ToFloat()
+
+		// Narrow side along the text, long side across it; quarter turns
+		// swap the axes
+		across, along := size[0]*pxPerMM, size[1]*pxPerMM
+		quarter := (l.Rotation / 90) & 3
+		w, h := across, along
+		if quarter%2 == 1 {
+			w, h = h, w
+		}
+
+		// Step from the label center to the body center, perpendicular to
+		// the text: down at 0°, then clockwise with the rotation
+		gap := math.Max(float64(l.Bounds.Height), float64(l.Bounds.Width)) / 4
+		c := labelRect.Center()
+		var center geometry.Point2D
+		switch quarter {
+		case 0:
+			center = geometry.Point2D{X: c.X, Y: labelRect.Y + labelRect.Height + gap + h/2}
+		case 1:
+			center = geometry.Point2D{X: labelRect.X - gap - w/2, Y: c.Y}
+		case 2:
+			center = geometry.Point2D{X: c.X, Y: labelRect.Y - gap - h/2}
+		default:
+			center = geometry.Point2D{X: labelRect.X + labelRect.Width + gap + w/2, Y: c.Y}
+		}
+		if onComponent(existing, l.Side, center) || onComponent(placed, l.Side, center) {
+			continue
+		}
+
+		comp := NewComponent(l.Text)
+		comp.Layer = l.Side
+		comp.Rotation = float64(quarter * 90)
+		comp.Bounds = geometry.Rect{X: center.X - w/2, Y: center.Y - h/2, Width: w, Height: h}
+		if l.Prefix == "U" {
+			comp.Package = "DIP-14"
+		}
+
+		if used[comp.ID] {
+			others := append(append([]*Component(nil), existing...), placed...)
+			id := SuggestComponentID(others, center.X, center.Y, 100, l.Prefix)
+			if id == "" || used[id] || strings.HasPrefix(id, "NEW") {
+				for n := 2; ; n++ {
+					id = fmt.Sprintf("%s-%d", l.Text, n)
+					if !used[id] {
+						break
+					}
+				}
+			}
+			fmt.Printf("PlaceFromDesignators: %s already used, placing as %s\n", l.Text, id)
+			comp.ID = id
+		}
+		used[comp.ID] = true
+		placed = append(placed, comp)
+	}
+	return placed
+}
+
+// onComponent reports whether p lies inside a component on side.
+func onComponent(comps []*Component, side image.Side, p geometry.Point2D) bool {
+	for _, c := range comps {
+		if c != nil && c.Layer == side && c.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
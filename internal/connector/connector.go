@@ -3,6 +3,7 @@ package connector
 
 import (
 	"fmt"
+	"math"
 
 	"pcb-tracer/internal/alignment"
 	"pcb-tracer/internal/image"
@@ -69,3 +70,49 @@ func sidePrefix(side image.Side) string {
 func (c *Connector) GetBounds() geometry.RectInt {
 	return c.Bounds
 }
+
+// MoveTo moves the connector so its center is at center, shifting Bounds by
+// the same (rounded) offset.
+func (c *Connector) MoveTo(center geometry.Point2D) {
+	c.Bounds.X += int(math.Round(center.X - c.Center.X))
+	c.Bounds.Y += int(math.Round(center.Y - c.Center.Y))
+	c.Center = center
+}
+
+// SnapToPitch snaps a proposed center for c onto the contact row of the
+// other connectors on its side: X to a whole number of pitches from the
+// nearest neighbor and Y to that neighbor's row. Each axis snaps only when
+// within a quarter pitch, so a deliberate off-grid placement is kept.
+// Returns the snapped center and whether either axis moved.
+func SnapToPitch(c *Connector, others []*Connector, pos geometry.Point2D, pitchPx float64) (geometry.Point2D, bool) {
+	if pitchPx <= 0 {
+		return pos, false
+	}
+	var nearest *Connector
+	bestDist := math.Inf(1)
+	for _, o := range others {
+		if o == nil || o == c || o.Side != c.Side {
+			continue
+		}
+		if d := math.Hypot(o.Center.X-pos.X, o.Center.Y-pos.Y); d < bestDist {
+			bestDist = d
+			nearest = o
+		}
+	}
+	if nearest == nil {
+		return pos, false
+	}
+
+	tol := pitchPx / 4
+	snapped := false
+	steps := math.Round((pos.X - nearest.Center.X) / pitchPx)
+	if x := nearest.Center.X + steps*pitchPx; math.Abs(x-pos.X) <= tol {
+		pos.X = x
+		snapped = true
+	}
+	if math.Abs(nearest.Center.Y-pos.Y) <= tol {
+		pos.Y = nearest.Center.Y
+		snapped = true
+	}
+	return pos, snapped
+}
@@ -0,0 +1,83 @@
+package connector
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//go:embed pinouts/*.csv
+var pinoutFiles embed.FS
+
+// bundledPinouts maps board profile names to their bundled pinout files.
+var bundledPinouts = map[string]string{
+	"S-100 (IEEE 696)": "pinouts/s100.csv",
+}
+
+// LoadPinout reads a pin→signal CSV with columns pin number and signal
+// name. Lines starting with '#' are comments, and a first row whose pin is
+// not a number is taken as a header and skipped. Rows with an empty signal
+// name are ignored.
+func LoadPinout(r io.Reader) (map[int]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read pinout: %w", err)
+	}
+
+	pinout := make(map[int]string, len(records))
+	for i, rec := range records {
+		line := i + 1
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("line %d: want pin, signal, got %d fields", line, len(rec))
+		}
+		pin, err := strconv.Atoi(strings.TrimSpace(rec[0]))
+		if err != nil && i == 0 {
+			continue // Header
+		}
+		if err != nil || pin < 1 {
+			return nil, fmt.Errorf("line %d: invalid pin number %q", line, rec[0])
+		}
+		name := strings.TrimSpace(rec[1])
+		if name == "" {
+			continue
+		}
+		if prev, dup := pinout[pin]; dup && prev != name {
+			return nil, fmt.Errorf("line %d: pin %d already named %s", line, pin, prev)
+		}
+		pinout[pin] = name
+	}
+	if len(pinout) == 0 {
+		return nil, fmt.Errorf("pinout has no pins")
+	}
+	return pinout, nil
+}
+
+// BundledPinout returns the pinout shipped for the named board profile, and
+// false if there is none.
+func BundledPinout(boardName string) (map[int]string, bool) {
+	path, ok := bundledPinouts[boardName]
+	if !ok {
+		return nil, false
+	}
+	f, err := pinoutFiles.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	pinout, err := LoadPinout(f)
+	if err != nil {
+		fmt.Printf("BundledPinout: %s: %v\n", path, err)
+		return nil, false
+	}
+	return pinout, true
+}
@@ -0,0 +1,55 @@
+package connector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadPinout(t *testing.T) {
+	const sample = `# Sample edge connector
+Pin, Signal
+1, +8V
+2, GND
+3,
+
+4, "A0, A1"
+2, GND
+`
+	got, err := LoadPinout(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]string{1: "+8V", 2: "GND", 4: "A0, A1"} // Pin 3 has no name
+	if len(got) != len(want) {
+		t.Errorf("got %d pins %v, want %v", len(got), got, want)
+	}
+	for pin, name := range want {
+		if got[pin] != name {
+			t.Errorf("pin %d = %q, want %q", pin, got[pin], name)
+		}
+	}
+
+	for _, c := range []struct {
+		name, csv string
+	}{
+		{"renamed pin", "1, GND\n1, +5V\n"},
+		{"bad pin", "1, GND\nx, +5V\n"},
+		{"pin zero", "0, GND\n"},
+		{"too few fields", "1, GND\n2\n"},
+		{"no pins", "Pin, Signal\n# nothing\n"},
+		{"bad quoting", "1, \"GND\n"},
+	} {
+		if _, err := LoadPinout(strings.NewReader(c.csv)); err == nil {
+			t.Errorf("%s: want error", c.name)
+		}
+	}
+
+	// The bundled S-100 file loads and covers every pin
+	s100, ok := BundledPinout("S-100 (IEEE 696)")
+	if !ok || len(s100) != 100 {
+		t.Errorf("bundled S-100 pinout: ok %v, %d pins; want 100", ok, len(s100))
+	}
+	if _, ok := BundledPinout("no such board"); ok {
+		t.Error("BundledPinout for an unknown board: ok = true")
+	}
+}
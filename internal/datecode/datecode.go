@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // DecodedDate represents a decoded date code.
@@ -44,27 +45,54 @@ func (d DecodedDate) String() string {
 	return sb.String()
 }
 
-// Decode attempts to decode a date code string.
-// It tries multiple formats and returns the best match.
-// contextYear hints at the expected decade (e.g., 1990 for 1990s chips).
-func Decode(code string, contextYear int) *DecodedDate {
-	code = strings.TrimSpace(strings.ToUpper(code))
-	if len(code) < 2 {
-		return nil
+// Normalize canonicalizes a user-entered date code: surrounding and
+// embedded whitespace is removed and letters are upper-cased.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// Describe normalizes and decodes code under scheme, returning a one-line
+// description such as "8523 → Jun 1985, week 23 (from 3 Jun 1985)"
+// together with the candidates that fall in years, most confident first.
+// A year left open by the code (a single digit, or a 2-digit year's
+// century) is moved into years where it can be. Further candidates are
+// listed with their confidence after the best one. For a code that is
+// undecodable or only decodes outside years, the description says so and
+// the candidates are nil. An empty code yields "" and nil.
+func Describe(code string, scheme Scheme, years YearRange) (string, []Candidate) {
+	code = Normalize(code)
+	if code == "" {
+		return "", nil
+	}
+	all := Decode(code, scheme)
+	if len(all) == 0 {
+		return fmt.Sprintf("%s → not a recognized date code", code), nil
 	}
 
-	// Try each format in order of specificity
-	if d := decodeYYWW(code, contextYear); d != nil {
-		return d
+	var cands []Candidate
+	total := 0.0
+	for _, c := range all {
+		if years.Fit(&c.DecodedDate, 0) {
+			cands = append(cands, c)
+			total += c.Confidence
+		}
 	}
-	if d := decodeYMW(code, contextYear); d != nil {
-		return d
+	if len(cands) == 0 {
+		return fmt.Sprintf("%s → %s, outside %s", code, all[0].String(), years), nil
 	}
-	if d := decodeYWW(code, contextYear); d != nil {
-		return d
+	for i := range cands {
+		cands[i].Confidence /= total
 	}
 
-	return nil
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s → %s (from %s)", code, cands[0].String(), cands[0].Calendar().Format("2 Jan 2006"))
+	if len(cands) > 1 {
+		fmt.Fprintf(&sb, " [%s %.0f%%]", cands[0].Format, cands[0].Confidence*100)
+		for _, c := range cands[1:] {
+			fmt.Fprintf(&sb, "; or %s [%s %.0f%%]", c.String(), c.Format, c.Confidence*100)
+		}
+	}
+	return sb.String(), cands
 }
 
 // decodeYMW decodes Hitachi-style YMW (Year-Month-Week) format.
@@ -262,35 +290,121 @@ func weekToMonth(week int) int {
 	return month
 }
 
-// ExtractDateCode attempts to find and extract a date code from OCR text.
-// Returns the extracted code and decoded date, or empty/nil if not found.
-func ExtractDateCode(text string, contextYear int) (string, *DecodedDate) {
-	text = strings.ToUpper(text)
+// YearRange is the window of years a date code may decode to, such as a
+// board's rough era. A zero bound is open.
+type YearRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// DefaultMinYear is the earliest year an IC date code plausibly names.
+const DefaultMinYear = 1965
+
+// DefaultYearRange returns DefaultMinYear through the current year.
+func DefaultYearRange() YearRange {
+	return YearRange{Min: DefaultMinYear, Max: time.Now().Year()}
+}
+
+// Contains reports whether year is inside the range.
+func (r YearRange) Contains(year int) bool {
+	return (r.Min <= 0 || year >= r.Min) && (r.Max <= 0 || year <= r.Max)
+}
 
-	// Pattern for YMW (Hitachi style): digit, letter A-M (not I), digit
-	ymwPattern := regexp.MustCompile(`\b([0-9][A-HJ-M][1-5])\b`)
-	if m := ymwPattern.FindStringSubmatch(text); m != nil {
-		code := m[1]
-		if d := decodeYMW(code, contextYear); d != nil {
-			return code, d
+// String returns the range as "1975-1990", with "…" for an open bound.
+func (r YearRange) String() string {
+	bound := func(y int) string {
+		if y <= 0 {
+			return "…"
 		}
+		return strconv.Itoa(y)
 	}
+	return bound(r.Min) + "-" + bound(r.Max)
+}
 
-	// Pattern for YYWW: 4 digits where last 2 are 01-53
-	yywwPattern := regexp.MustCompile(`\b([0-9]{2})(0[1-9]|[1-4][0-9]|5[0-3])\b`)
-	if m := yywwPattern.FindStringSubmatch(text); m != nil {
-		code := m[1] + m[2]
-		if d := decodeYYWW(code, contextYear); d != nil {
-			return code, d
+// Fit moves d's year into r if its code leaves the century (YYWW, WWYY)
+// or decade (single year digit) open, picking the fitting year nearest
+// contextYear (d's own year if 0). Returns false if no reading of d fits,
+// or d's week isn't 01-53.
+func (r YearRange) Fit(d *DecodedDate, contextYear int) bool {
+	if d.Week < 1 || d.Week > 53 {
+		return false
+	}
+	if r.Contains(d.Year) {
+		return true
+	}
+	step := 0
+	switch {
+	case d.YearAmbig:
+		step = 10
+	case d.Format == "YYWW" || d.Format == "WWYY":
+		step = 100
+	default:
+		return false
+	}
+	target := contextYear
+	if target <= 0 {
+		target = d.Year
+	}
+	best, found := 0, false
+	for y := d.Year - 3*step; y <= d.Year+3*step; y += step {
+		if !r.Contains(y) {
+			continue
+		}
+		if !found || abs(y-target) < abs(best-target) {
+			best, found = y, true
 		}
 	}
+	if found {
+		d.Year = best
+	}
+	return found
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Date code patterns in OCR text
+var (
+	// YMW (Hitachi style): digit, letter A-M (not I), digit
+	ymwPattern = regexp.MustCompile(`\b([0-9][A-HJ-M][1-5])\b`)
+	// YYWW: 4 digits where last 2 are 01-53
+	yywwPattern = regexp.MustCompile(`\b([0-9]{2})(0[1-9]|[1-4][0-9]|5[0-3])\b`)
+	// YWW: 3 digits where last 2 are 01-53
+	ywwPattern = regexp.MustCompile(`\b([0-9])(0[1-9]|[1-4][0-9]|5[0-3])\b`)
+)
+
+// ExtractDateCode attempts to find and extract a date code from OCR text,
+// accepting only dates in DefaultYearRange.
+// Returns the extracted code and decoded date, or empty/nil if not found.
+func ExtractDateCode(text string, contextYear int) (string, *DecodedDate) {
+	return ExtractDateCodeInRange(text, contextYear, DefaultYearRange())
+}
+
+// ExtractDateCodeInRange is ExtractDateCode with the accepted years given,
+// e.g. from a board's era. Every candidate group in the text is tried, so a
+// lot number or part number residue that decodes outside years is skipped
+// in favor of a later group that decodes inside it.
+func ExtractDateCodeInRange(text string, contextYear int, years YearRange) (string, *DecodedDate) {
+	text = strings.ToUpper(text)
 
-	// Pattern for YWW: 3 digits where last 2 are 01-53
-	ywwPattern := regexp.MustCompile(`\b([0-9])(0[1-9]|[1-4][0-9]|5[0-3])\b`)
-	if m := ywwPattern.FindStringSubmatch(text); m != nil {
-		code := m[1] + m[2]
-		if d := decodeYWW(code, contextYear); d != nil {
-			return code, d
+	formats := []struct {
+		pattern *regexp.Regexp
+		decode  func(string, int) *DecodedDate
+	}{
+		{ymwPattern, decodeYMW},
+		{yywwPattern, decodeYYWW},
+		{ywwPattern, decodeYWW},
+	}
+	for _, f := range formats {
+		for _, m := range f.pattern.FindAllStringSubmatch(text, -1) {
+			code := strings.Join(m[1:], "")
+			if d := f.decode(code, contextYear); d != nil && years.Fit(d, contextYear) {
+				return code, d
+			}
 		}
 	}
 
@@ -320,6 +434,24 @@ func GetFormatInfo() []FormatInfo {
 			Example:     "8923",
 			Decoded:     "1989, week 23 (early June)",
 		},
+		{
+			Name:        "WWYY",
+			Description: "2-digit week (01-53) + 2-digit year, offered beside YYWW when both fit",
+			Example:     "2385",
+			Decoded:     "1985, week 23 (early June)",
+		},
+		{
+			Name:        "Intel FPO",
+			Description: "Plant letter + 1-digit year + 2-digit week + lot",
+			Example:     "L5230123",
+			Decoded:     "1995, week 23 (early June)",
+		},
+		{
+			Name:        "Letter year",
+			Description: "Year letter (A = 1980, skip I, O, Q) + 2-digit week",
+			Example:     "F23",
+			Decoded:     "1985, week 23 (early June)",
+		},
 		{
 			Name:        "YWW",
 			Description: "1-digit year + 2-digit week (01-53)",
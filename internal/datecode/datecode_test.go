@@ -0,0 +1,117 @@
+package datecode
+
+import "testing"
+
+func TestYearRangeFit(t *testing.T) {
+	era := YearRange{Min: 1975, Max: 1990}
+	cases := []struct {
+		name     string
+		date     *DecodedDate
+		years    YearRange
+		context  int
+		want     bool
+		wantYear int
+	}{
+		{"inside", decodeYYWW("8523", 0), era, 0, true, 1985},
+		{"2444 outside era", decodeYYWW("2444", 0), era, 0, false, 2024},
+		{"century shift", decodeYYWW("2812", 0), YearRange{Min: 1920, Max: 1935}, 0, true, 1928},
+		{"WWYY century shift", decodeWWYY("1231"), YearRange{Min: 2030}, 0, true, 2031},
+		{"decade shift", decodeYWW("523", 0), era, 0, true, 1985},
+		{"decade nearest context", decodeYWW("523", 0), era, 1978, true, 1975},
+		{"Hitachi decade shift", decodeYMW("3C2", 0), YearRange{Min: 1980, Max: 1989}, 0, true, 1983},
+		{"Intel decade shift", decodeIntelFPO("L5230123"), YearRange{Min: 1980, Max: 1989}, 0, true, 1985},
+		{"letter year is fixed", decodeLetterYear("F23"), YearRange{Min: 1990}, 0, false, 1985},
+		{"open range", decodeYYWW("0145", 0), YearRange{}, 0, true, 2001},
+		{"week 00", &DecodedDate{Year: 1985, Week: 0, Format: "YYWW"}, era, 0, false, 1985},
+		{"week 54", &DecodedDate{Year: 1985, Week: 54, Format: "YYWW"}, era, 0, false, 1985},
+	}
+	for _, c := range cases {
+		if c.date == nil {
+			t.Errorf("%s: test code did not decode", c.name)
+			continue
+		}
+		got := c.years.Fit(c.date, c.context)
+		if got != c.want || c.date.Year != c.wantYear {
+			t.Errorf("%s: Fit = %v, year %d; want %v, year %d", c.name, got, c.date.Year, c.want, c.wantYear)
+		}
+	}
+}
+
+func TestExtractDateCodeInRange(t *testing.T) {
+	era := YearRange{Min: 1975, Max: 1990}
+	cases := []struct {
+		name     string
+		text     string
+		years    YearRange
+		wantCode string
+		wantYear int
+	}{
+		{"plain", "SN74LS00N 8523", era, "8523", 1985},
+		{"0000", "0000", YearRange{}, "", 0},
+		{"2444 outside era", "2444", era, "", 0},
+		{"lot number skipped", "2444 8523", era, "8523", 1985},
+		{"lot number after date", "8523 2444", era, "8523", 1985},
+		{"YWW decade", "P8080A 523", era, "523", 1985},
+		{"Hitachi", "HD6301 3C2", YearRange{Min: 1980, Max: 1989}, "3C2", 1983},
+		{"nothing", "MC6800P", era, "", 0},
+	}
+	for _, c := range cases {
+		code, d := ExtractDateCodeInRange(c.text, 0, c.years)
+		year := 0
+		if d != nil {
+			year = d.Year
+		}
+		if code != c.wantCode || year != c.wantYear {
+			t.Errorf("%s: ExtractDateCodeInRange(%q) = %q, year %d; want %q, year %d",
+				c.name, c.text, code, year, c.wantCode, c.wantYear)
+		}
+	}
+
+	// The default range still rejects week 00
+	if code, d := ExtractDateCode("0000 0000", 0); code != "" || d != nil {
+		t.Errorf("ExtractDateCode(0000) = %q, %v; want nothing", code, d)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct{ code, want string }{
+		{"8523", "8523"},
+		{" 85 23 ", "8523"},
+		{"f23", "F23"},
+		{"\t9l5\n", "9L5"},
+		{"", ""},
+		{"   ", ""},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.code); got != c.want {
+			t.Errorf("Normalize(%q) = %q, want %q", c.code, got, c.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	era := YearRange{Min: 1975, Max: 1990}
+	cases := []struct {
+		code      string
+		scheme    Scheme
+		years     YearRange
+		want      string
+		wantCands int
+	}{
+		{"8523", SchemeAuto, YearRange{}, "8523 → Jun 1985, week 23 (from 3 Jun 1985)", 1},
+		{" 85 23", SchemeAuto, era, "8523 → Jun 1985, week 23 (from 3 Jun 1985)", 1},
+		{"f23", SchemeLetterYear, era, "F23 → Jun 1985, week 23 (from 3 Jun 1985)", 1},
+		{"523", SchemeAuto, era, "523 → Jun 1985 (or 1975, 1995), week 23 (from 3 Jun 1985)", 1},
+		{"2444", SchemeAuto, era, "2444 → Nov 2024, week 44, outside 1975-1990", 0},
+		{"hello", SchemeAuto, era, "HELLO → not a recognized date code", 0},
+		{"8523", SchemeIntelFPO, era, "8523 → not a recognized date code", 0},
+		{"  ", SchemeAuto, era, "", 0},
+	}
+	for _, c := range cases {
+		got, cands := Describe(c.code, c.scheme, c.years)
+		if got != c.want || len(cands) != c.wantCands {
+			t.Errorf("Describe(%q, %s, %s) = %q, %d candidates; want %q, %d",
+				c.code, c.scheme, c.years, got, len(cands), c.want, c.wantCands)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package datecode
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme selects which date code formats Decode tries.
+type Scheme int
+
+const (
+	SchemeAuto       Scheme = iota // Every known format
+	SchemeYYWW                     // 2-digit year + week (8523), or week + year (2385)
+	SchemeIntelFPO                 // Intel FPO: plant letter, year digit, week, lot (L5230123)
+	SchemeLetterYear               // Year letter + week (F23)
+	SchemeHitachiYMW               // Year digit + month letter + week of month (9L5)
+)
+
+// String returns the scheme name.
+func (s Scheme) String() string {
+	switch s {
+	case SchemeAuto:
+		return "auto"
+	case SchemeYYWW:
+		return "YYWW"
+	case SchemeIntelFPO:
+		return "Intel FPO"
+	case SchemeLetterYear:
+		return "letter year"
+	case SchemeHitachiYMW:
+		return "Hitachi YMW"
+	}
+	return fmt.Sprintf("Scheme(%d)", int(s))
+}
+
+// manufacturerSchemes maps upper-cased manufacturer names to the date code
+// scheme they mark parts with. Manufacturers not listed use SchemeAuto.
+var manufacturerSchemes = map[string]Scheme{
+	"INTEL":   SchemeIntelFPO,
+	"HITACHI": SchemeHitachiYMW,
+}
+
+// SchemeForManufacturer returns the scheme for a component's manufacturer,
+// matching the name or its first word case-insensitively ("Intel Corp"),
+// or SchemeAuto if the manufacturer has no known scheme.
+func SchemeForManufacturer(mfr string) Scheme {
+	mfr = strings.ToUpper(strings.TrimSpace(mfr))
+	if s, ok := manufacturerSchemes[mfr]; ok {
+		return s
+	}
+	if fields := strings.Fields(mfr); len(fields) > 0 {
+		if s, ok := manufacturerSchemes[fields[0]]; ok {
+			return s
+		}
+	}
+	return SchemeAuto
+}
+
+// Candidate is one reading of a date code with its share of the
+// confidence among all readings Decode found.
+type Candidate struct {
+	DecodedDate
+	Confidence float64 // 0-1; the candidates of one code sum to 1
+}
+
+// Relative likelihood of each format before plausibility is considered.
+// YYWW dominates in practice; a week-first reading is the rare alternative.
+var formatWeights = map[string]float64{
+	"YYWW":        1.0,
+	"WWYY":        0.4,
+	"Intel FPO":   1.0,
+	"Hitachi YMW": 0.8,
+	"YWW":         0.5,
+	"Letter year": 0.6,
+}
+
+// LetterYearEpoch is the year coded by "A" in the letter-year scheme.
+// Letters run A-Z skipping I, O and Q, which read as digits.
+const LetterYearEpoch = 1980
+
+var (
+	intelFPOPattern   = regexp.MustCompile(`^[A-Z]([0-9])([0-9]{2})[0-9A-Z]{3,5}$`)
+	letterYearPattern = regexp.MustCompile(`^([A-HJ-NPR-Z])([0-9]{2})$`)
+)
+
+// Decode reads code under scheme and returns every plausible reading, most
+// confident first. A code that fits several formats, such as "2312" (week
+// 12 of 2023, or week 23 of 2012), yields one candidate per format rather
+// than a silent guess. Returns nil if nothing fits.
+func Decode(code string, scheme Scheme) []Candidate {
+	code = Normalize(code)
+	if len(code) < 2 {
+		return nil
+	}
+
+	var dates []*DecodedDate
+	if scheme == SchemeAuto || scheme == SchemeYYWW {
+		dates = append(dates, decodeYYWW(code, 0), decodeWWYY(code))
+	}
+	if scheme == SchemeAuto || scheme == SchemeIntelFPO {
+		dates = append(dates, decodeIntelFPO(code))
+	}
+	if scheme == SchemeAuto || scheme == SchemeLetterYear {
+		dates = append(dates, decodeLetterYear(code))
+	}
+	if scheme == SchemeAuto || scheme == SchemeHitachiYMW {
+		dates = append(dates, decodeYMW(code, 0))
+	}
+	if scheme == SchemeAuto {
+		dates = append(dates, decodeYWW(code, 0))
+	}
+
+	plausible := DefaultYearRange()
+	var cands []Candidate
+	total := 0.0
+	for _, d := range dates {
+		if d == nil {
+			continue
+		}
+		w := formatWeights[d.Format]
+		if !plausible.Contains(d.Year) {
+			w *= 0.1
+		}
+		cands = append(cands, Candidate{DecodedDate: *d, Confidence: w})
+		total += w
+	}
+	for i := range cands {
+		cands[i].Confidence /= total
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		return cands[i].Confidence > cands[j].Confidence
+	})
+	return cands
+}
+
+// decodeWWYY decodes the week-first reading of a 4-digit code.
+// Example: 2385 = 1985 week 23
+func decodeWWYY(code string) *DecodedDate {
+	if len(code) != 4 {
+		return nil
+	}
+	d := decodeYYWW(code[2:4]+code[0:2], 0)
+	if d == nil {
+		return nil
+	}
+	d.Format = "WWYY"
+	d.Raw = code
+	return d
+}
+
+// decodeIntelFPO decodes an Intel FPO number: a plant letter, the last
+// digit of the year, the work week and a lot sequence.
+// Example: L5230123 = 1995 week 23
+func decodeIntelFPO(code string) *DecodedDate {
+	m := intelFPOPattern.FindStringSubmatch(code)
+	if m == nil {
+		return nil
+	}
+	ww, _ := strconv.Atoi(m[2])
+	if ww < 1 || ww > 53 {
+		return nil
+	}
+	return &DecodedDate{
+		Year:      resolveYear(int(m[1][0]-'0'), 0),
+		YearAmbig: true,
+		Month:     weekToMonth(ww),
+		Week:      ww,
+		Format:    "Intel FPO",
+		Raw:       code,
+	}
+}
+
+// decodeLetterYear decodes a year letter counted from LetterYearEpoch
+// followed by a 2-digit week.
+// Example: F23 = 1985 week 23
+func decodeLetterYear(code string) *DecodedDate {
+	m := letterYearPattern.FindStringSubmatch(code)
+	if m == nil {
+		return nil
+	}
+	ww, _ := strconv.Atoi(m[2])
+	if ww < 1 || ww > 53 {
+		return nil
+	}
+	offset := 0
+	for c := byte('A'); c < m[1][0]; c++ {
+		if c != 'I' && c != 'O' && c != 'Q' {
+			offset++
+		}
+	}
+	return &DecodedDate{
+		Year:   LetterYearEpoch + offset,
+		Month:  weekToMonth(ww),
+		Week:   ww,
+		Format: "Letter year",
+		Raw:    code,
+	}
+}
+
+// Calendar returns the first day the code can name: the Monday of its
+// ISO week, the first day of its week of the month, or the first of the
+// month if it has no week.
+func (d DecodedDate) Calendar() time.Time {
+	switch {
+	case d.Week > 0 && !d.WeekOfMonth:
+		// ISO week 1 holds January 4th
+		jan4 := time.Date(d.Year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, 7*(d.Week-1))
+	case d.Week > 0:
+		return time.Date(d.Year, time.Month(d.Month), 1+7*(d.Week-1), 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(d.Year, time.Month(d.Month), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
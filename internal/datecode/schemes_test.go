@@ -0,0 +1,138 @@
+package datecode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	type reading struct {
+		format string
+		year   int
+		week   int
+	}
+	cases := []struct {
+		code   string
+		scheme Scheme
+		want   []reading // Most confident first
+	}{
+		// YYWW, with a week-first reading only where both halves fit
+		{"8523", SchemeAuto, []reading{{"YYWW", 1985, 23}}},
+		{"2312", SchemeAuto, []reading{{"YYWW", 2023, 12}, {"WWYY", 2012, 23}}},
+		{"2385", SchemeYYWW, []reading{{"WWYY", 1985, 23}}},
+		{"0145", SchemeYYWW, []reading{{"YYWW", 2001, 45}, {"WWYY", 1945, 1}}},
+		{"8500", SchemeYYWW, nil},
+		// An implausible year (1950) ranks below the rarer format
+		{"5012", SchemeAuto, []reading{{"WWYY", 2012, 50}, {"YYWW", 1950, 12}}},
+
+		// Intel FPO: plant, year digit, week, lot
+		{"L5230123", SchemeIntelFPO, []reading{{"Intel FPO", 1995, 23}}},
+		{"l523 0123", SchemeAuto, []reading{{"Intel FPO", 1995, 23}}},
+		{"L5600123", SchemeIntelFPO, nil},
+		{"8523", SchemeIntelFPO, nil},
+
+		// Letter year from 1980, skipping I, O and Q
+		{"A01", SchemeLetterYear, []reading{{"Letter year", 1980, 1}}},
+		{"F23", SchemeLetterYear, []reading{{"Letter year", 1985, 23}}},
+		{"J23", SchemeLetterYear, []reading{{"Letter year", 1988, 23}}},
+		{"P10", SchemeLetterYear, []reading{{"Letter year", 1993, 10}}},
+		{"R10", SchemeLetterYear, []reading{{"Letter year", 1994, 10}}},
+		{"I23", SchemeLetterYear, nil},
+		{"O23", SchemeLetterYear, nil},
+		{"Q23", SchemeLetterYear, nil},
+		{"F54", SchemeLetterYear, nil},
+
+		// Hitachi YMW and plain YWW
+		{"9L5", SchemeHitachiYMW, []reading{{"Hitachi YMW", 1999, 5}}},
+		{"3I2", SchemeHitachiYMW, nil},
+		{"923", SchemeAuto, []reading{{"YWW", 1999, 23}}},
+		{"923", SchemeHitachiYMW, nil},
+
+		{"", SchemeAuto, nil},
+		{"HELLO", SchemeAuto, nil},
+	}
+	for _, c := range cases {
+		got := Decode(c.code, c.scheme)
+		if len(got) != len(c.want) {
+			t.Errorf("Decode(%q, %s) = %d candidates %v, want %d", c.code, c.scheme, len(got), got, len(c.want))
+			continue
+		}
+		sum := 0.0
+		for i, w := range c.want {
+			g := got[i]
+			if g.Format != w.format || g.Year != w.year || g.Week != w.week {
+				t.Errorf("Decode(%q, %s)[%d] = %s %d week %d, want %s %d week %d",
+					c.code, c.scheme, i, g.Format, g.Year, g.Week, w.format, w.year, w.week)
+			}
+			if i > 0 && g.Confidence > got[i-1].Confidence {
+				t.Errorf("Decode(%q, %s): candidate %d more confident than %d", c.code, c.scheme, i, i-1)
+			}
+			sum += g.Confidence
+		}
+		if len(got) > 0 && math.Abs(sum-1) > 1e-9 {
+			t.Errorf("Decode(%q, %s): confidences sum to %v, want 1", c.code, c.scheme, sum)
+		}
+	}
+}
+
+func TestDecodeConfidence(t *testing.T) {
+	cases := []struct {
+		code string
+		want []float64
+	}{
+		{"8523", []float64{1}},
+		{"2312", []float64{1 / 1.4, 0.4 / 1.4}},   // YYWW 1.0 against WWYY 0.4
+		{"5012", []float64{0.4 / 0.5, 0.1 / 0.5}}, // YYWW 1950 is down-weighted to 0.1
+	}
+	for _, c := range cases {
+		got := Decode(c.code, SchemeAuto)
+		if len(got) != len(c.want) {
+			t.Errorf("Decode(%q): %d candidates, want %d", c.code, len(got), len(c.want))
+			continue
+		}
+		for i, w := range c.want {
+			if math.Abs(got[i].Confidence-w) > 1e-9 {
+				t.Errorf("Decode(%q)[%d].Confidence = %v, want %v", c.code, i, got[i].Confidence, w)
+			}
+		}
+	}
+}
+
+func TestSchemeForManufacturer(t *testing.T) {
+	cases := []struct {
+		mfr  string
+		want Scheme
+	}{
+		{"Intel", SchemeIntelFPO},
+		{"intel corp", SchemeIntelFPO},
+		{" HITACHI ", SchemeHitachiYMW},
+		{"Texas Instruments", SchemeAuto},
+		{"", SchemeAuto},
+	}
+	for _, c := range cases {
+		if got := SchemeForManufacturer(c.mfr); got != c.want {
+			t.Errorf("SchemeForManufacturer(%q) = %s, want %s", c.mfr, got, c.want)
+		}
+	}
+}
+
+func TestDescribeCandidates(t *testing.T) {
+	desc, cands := Describe("2312", SchemeAuto, YearRange{})
+	want := "2312 → Mar 2023, week 12 (from 20 Mar 2023) [YYWW 71%]; or Jun 2012, week 23 [WWYY 29%]"
+	if desc != want {
+		t.Errorf("Describe(2312) = %q, want %q", desc, want)
+	}
+	if len(cands) != 2 || cands[0].Format != "YYWW" {
+		t.Errorf("Describe(2312) candidates = %v, want YYWW then WWYY", cands)
+	}
+
+	// Dropping the YYWW reading with the era leaves WWYY at full confidence
+	desc, cands = Describe("2312", SchemeAuto, YearRange{Min: 2005, Max: 2015})
+	want = "2312 → Jun 2012, week 23 (from 4 Jun 2012)"
+	if desc != want {
+		t.Errorf("Describe(2312, 2005-2015) = %q, want %q", desc, want)
+	}
+	if len(cands) != 1 || cands[0].Confidence != 1 {
+		t.Errorf("Describe(2312, 2005-2015) candidates = %v, want one at confidence 1", cands)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"image/color"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 
 	"pcb-tracer/internal/component"
@@ -886,6 +887,26 @@ func (l *DetectedFeaturesLayer) GetConnectorsBySide(side image.Side) []*connecto
 	return result
 }
 
+// ApplyPinout sets the signal name of each connector on side from pinout,
+// keyed by pin number. Connectors whose pin is not in pinout are left as
+// they are. Returns the number of connectors named.
+func (l *DetectedFeaturesLayer) ApplyPinout(side image.Side, pinout map[int]string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	named := 0
+	for _, c := range l.connectorsMap {
+		if c.Side != side {
+			continue
+		}
+		if name, ok := pinout[c.PinNumber]; ok {
+			c.SignalName = name
+			named++
+		}
+	}
+	return named
+}
+
 // ClearConnectors removes all connectors from the layer.
 func (l *DetectedFeaturesLayer) ClearConnectors() {
 	l.mu.Lock()
@@ -1035,6 +1056,56 @@ func (l *DetectedFeaturesLayer) RenameNet(netID, newName string) {
 	}
 }
 
+// RenameComponents rewrites references to renamed components: the
+// ComponentID of confirmed vias and the "ComponentID.Pin" pad IDs of nets.
+// renames maps old IDs to new ones and is applied all at once, so swapped
+// or chained IDs are handled. Returns the number of references changed.
+func (l *DetectedFeaturesLayer) RenameComponents(renames map[string]string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	changed := 0
+	for _, cv := range l.confirmedViasMap {
+		if newID, ok := renames[cv.ComponentID]; ok && cv.ComponentID != "" {
+			cv.ComponentID = newID
+			changed++
+		}
+	}
+
+	renamePad := func(padID string) (string, bool) {
+		ref, pin, ok := strings.Cut(padID, ".")
+		if !ok {
+			return padID, false
+		}
+		newRef, ok := renames[ref]
+		if !ok {
+			return padID, false
+		}
+		return newRef + "." + pin, true
+	}
+	for _, n := range l.netsMap {
+		for i, pid := range n.PadIDs {
+			if newPID, ok := renamePad(pid); ok {
+				n.PadIDs[i] = newPID
+			}
+		}
+		for i, e := range n.Elements {
+			if e.Type != netlist.ElementPad {
+				continue
+			}
+			if newPID, ok := renamePad(e.ID); ok {
+				if l.elementToNet[e.ID] == n.ID {
+					delete(l.elementToNet, e.ID)
+				}
+				n.Elements[i].ID = newPID
+				l.elementToNet[newPID] = n.ID
+				changed++
+			}
+		}
+	}
+	return changed
+}
+
 // GetNetForElement returns the net containing an element.
 // Uses the reverse index for O(1) lookup, falling back to linear scan.
 func (l *DetectedFeaturesLayer) GetNetForElement(elementID string) *netlist.ElectricalNet {
@@ -1102,72 +1173,37 @@ func (l *DetectedFeaturesLayer) RemoveNet(id string) bool {
 	return true
 }
 
-// ReconcileNets derives net membership from physical trace connectivity.
-// It walks all traces, unions elements connected by trace endpoints, and
-// ensures each connected component maps to exactly one net. Nets that span
-// multiple components are split; components that span multiple nets are merged.
-// tolerance is the maximum distance to consider two points connected.
-func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	// ── Union-Find ──────────────────────────────────────────────────────
-	parent := make(map[string]string)
-
-	var find func(string) string
-	find = func(x string) string {
-		p, ok := parent[x]
-		if !ok || p == x {
-			parent[x] = x
-			return x
-		}
-		parent[x] = find(p)
-		return parent[x]
+// tracePointsLocked returns a trace's points, or nil if the trace is missing
+// or has fewer than two points. Caller must hold l.mu.
+func (l *DetectedFeaturesLayer) tracePointsLocked(tid string) []geometry.Point2D {
+	ref := l.features[tid]
+	if ref == nil {
+		return nil
 	}
-	union := func(a, b string) {
-		ra, rb := find(a), find(b)
-		if ra != rb {
-			parent[ra] = rb
-		}
+	tf, ok := ref.Feature.(TraceFeature)
+	if !ok || len(tf.Points) < 2 {
+		return nil
 	}
+	return tf.Points
+}
 
-	// Initialise sets for all elements currently in nets.
-	for _, n := range l.netsMap {
-		for _, e := range n.Elements {
-			find(e.ID)
-		}
-	}
-	// Initialise all confirmed vias and connectors (even if unnetted).
-	for _, id := range l.confirmedVias {
-		find(id)
-	}
-	for _, id := range l.connectors {
-		find(id)
-	}
+// connectivityEdgesLocked returns the physical connections between traces,
+// confirmed vias and connectors as element-ID pairs. A trace connects to a
+// via when an endpoint is within tolerance of the via center, to a connector
+// on the same side when an endpoint hits the pad, and to another trace when
+// an endpoint of either touches a vertex of the other. Caller must hold l.mu.
+func (l *DetectedFeaturesLayer) connectivityEdgesLocked(tolerance float64) [][2]string {
+	var edges [][2]string
 
-	// Helper: get trace feature without acquiring the lock (we already hold it).
-	getTrace := func(tid string) *TraceFeature {
+	tracePoints := l.tracePointsLocked
+	// traceSide returns the image.Side corresponding to a trace's layer.
+	traceSide := func(tid string) image.Side {
 		ref := l.features[tid]
 		if ref == nil {
-			return nil
+			return image.SideUnknown
 		}
 		tf, ok := ref.Feature.(TraceFeature)
-		if !ok || len(tf.Points) < 2 {
-			return nil
-		}
-		return &tf
-	}
-	tracePoints := func(tid string) []geometry.Point2D {
-		tf := getTrace(tid)
-		if tf == nil {
-			return nil
-		}
-		return tf.Points
-	}
-	// traceSide returns the image.Side corresponding to a trace's layer.
-	traceSide := func(tid string) image.Side {
-		tf := getTrace(tid)
-		if tf == nil {
+		if !ok {
 			return image.SideUnknown
 		}
 		if tf.Layer == trace.LayerFront {
@@ -1180,13 +1216,12 @@ func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
 		return math.Hypot(a.X-b.X, a.Y-b.Y) <= tolerance
 	}
 
-	// ── Union traces with endpoint vias/connectors ──────────────────────
+	// ── Traces to endpoint vias/connectors ──────────────────────────────
 	for _, tid := range l.traces {
 		pts := tracePoints(tid)
 		if pts == nil {
 			continue
 		}
-		find(tid)
 		start, end := pts[0], pts[len(pts)-1]
 
 		for _, cvID := range l.confirmedVias {
@@ -1195,7 +1230,7 @@ func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
 				continue
 			}
 			if near(cv.Center, start) || near(cv.Center, end) {
-				union(tid, cvID)
+				edges = append(edges, [2]string{tid, cvID})
 			}
 		}
 		tSide := traceSide(tid)
@@ -1209,12 +1244,12 @@ func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
 				continue
 			}
 			if conn.HitTest(start.X, start.Y) || conn.HitTest(end.X, end.Y) {
-				union(tid, cid)
+				edges = append(edges, [2]string{tid, cid})
 			}
 		}
 	}
 
-	// ── Union traces sharing junction vertices ──────────────────────────
+	// ── Traces sharing junction vertices ────────────────────────────────
 	// For each pair of traces: if one's endpoint touches any vertex of the
 	// other, they are connected.
 	for i := 0; i < len(l.traces); i++ {
@@ -1259,11 +1294,87 @@ func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
 				}
 			}
 			if connected {
-				union(l.traces[i], l.traces[j])
+				edges = append(edges, [2]string{l.traces[i], l.traces[j]})
 			}
 		}
 	}
 
+	return edges
+}
+
+// NetPath returns the elements of a net ordered along the signal path,
+// walking physical connectivity outward from startID. If startID is empty
+// or not in the net, a default endpoint is chosen (see netlist.OrderPath).
+func (l *DetectedFeaturesLayer) NetPath(netID, startID string, tolerance float64) []netlist.PathElement {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	n := l.netsMap[netID]
+	if n == nil {
+		return nil
+	}
+	pins := make(map[string]bool)
+	for _, vid := range n.ViaIDs {
+		if cv := l.confirmedViasMap[vid]; cv != nil && cv.ComponentID != "" && cv.PinNumber != "" {
+			pins[vid] = true
+		}
+	}
+	return netlist.OrderPath(n, l.connectivityEdgesLocked(tolerance), startID, pins)
+}
+
+// ReconcileNets derives net membership from physical trace connectivity.
+// It walks all traces, unions elements connected by trace endpoints, and
+// ensures each connected component maps to exactly one net. Nets that span
+// multiple components are split; components that span multiple nets are merged.
+// tolerance is the maximum distance to consider two points connected.
+func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// ── Union-Find ──────────────────────────────────────────────────────
+	parent := make(map[string]string)
+
+	var find func(string) string
+	find = func(x string) string {
+		p, ok := parent[x]
+		if !ok || p == x {
+			parent[x] = x
+			return x
+		}
+		parent[x] = find(p)
+		return parent[x]
+	}
+	union := func(a, b string) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	// Initialise sets for all elements currently in nets.
+	for _, n := range l.netsMap {
+		for _, e := range n.Elements {
+			find(e.ID)
+		}
+	}
+	// Initialise all confirmed vias and connectors (even if unnetted).
+	for _, id := range l.confirmedVias {
+		find(id)
+	}
+	for _, id := range l.connectors {
+		find(id)
+	}
+
+	// Register every usable trace, then union along physical connections.
+	for _, tid := range l.traces {
+		if l.tracePointsLocked(tid) != nil {
+			find(tid)
+		}
+	}
+	for _, e := range l.connectivityEdgesLocked(tolerance) {
+		union(e[0], e[1])
+	}
+
 	// ── Group elements by connected component ───────────────────────────
 	groups := make(map[string][]string) // root → element IDs
 	for id := range parent {
@@ -1409,6 +1520,82 @@ func (l *DetectedFeaturesLayer) ReconcileNets(tolerance float64) {
 	}
 }
 
+// AutoNameNetsFromConnectors names each net after the signal of the named
+// connectors it touches, when they all carry the same signal (several GND
+// pins, say). Nets the user named are left alone, as are nets already named
+// for the signal (including disambiguated "GND#2"). A net touching
+// differently named connectors is a short: it keeps its name, is marked
+// HasErrors, and is returned for reporting. Call after ReconcileNets, which
+// rebuilds the nets and clears the marks.
+func (l *DetectedFeaturesLayer) AutoNameNetsFromConnectors() (named int, shorts []netlist.Short) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range l.nets {
+		net := l.netsMap[id]
+		if net == nil {
+			continue
+		}
+		signals := netlist.ConnectorSignals(net, l.connectorSignalLocked)
+
+		switch {
+		case len(signals) > 1:
+			net.HasErrors = true
+			short := netlist.Short{Kind: netlist.ShortMergedSignals, NetIDs: []string{net.ID}, Signals: signals}
+			fmt.Printf("AutoNameNetsFromConnectors: %s\n", short)
+			shorts = append(shorts, short)
+		case len(signals) == 1:
+			if net.ManualName || netlist.BaseNetName(net.Name) == signals[0] {
+				continue
+			}
+			fmt.Printf("AutoNameNetsFromConnectors: %q -> %q\n", net.Name, signals[0])
+			net.Name = signals[0]
+			if net.RootConnectorID == "" {
+				for _, cid := range net.ConnectorIDs {
+					if l.connectorSignalLocked(cid) == signals[0] {
+						net.RootConnectorID = cid
+						break
+					}
+				}
+			}
+			named++
+		}
+	}
+	return named, shorts
+}
+
+// FindShorts runs netlist.FindShorts over the layer's nets and marks every
+// net involved HasErrors.
+func (l *DetectedFeaturesLayer) FindShorts() []netlist.Short {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	nets := make([]*netlist.ElectricalNet, 0, len(l.nets))
+	for _, id := range l.nets {
+		if n := l.netsMap[id]; n != nil {
+			nets = append(nets, n)
+		}
+	}
+	shorts := netlist.FindShorts(nets, l.connectorSignalLocked)
+	for _, s := range shorts {
+		for _, id := range s.NetIDs {
+			if n := l.netsMap[id]; n != nil {
+				n.HasErrors = true
+			}
+		}
+	}
+	return shorts
+}
+
+// connectorSignalLocked returns the signal name of a connector, or "" if
+// the connector is missing. Caller must hold l.mu.
+func (l *DetectedFeaturesLayer) connectorSignalLocked(id string) string {
+	if c := l.connectorsMap[id]; c != nil {
+		return c.SignalName
+	}
+	return ""
+}
+
 // PropagateLogicNames derives net names from logic function signal flow.
 // For each net with a low-priority name, checks if it contains an output pin
 // of a known logic function. If the corresponding input pin's net has a signal
@@ -0,0 +1,48 @@
+package features
+
+import (
+	"testing"
+
+	"pcb-tracer/internal/netlist"
+	"pcb-tracer/internal/via"
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestRenameComponents(t *testing.T) {
+	l := NewDetectedFeaturesLayer()
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-1", ComponentID: "C12", PinNumber: "7"})
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-2", ComponentID: "U-A2", PinNumber: "1"})
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-3", ComponentID: "R5", PinNumber: "2"})
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-4"})
+
+	net := netlist.NewElectricalNetWithName("net-001", "D0")
+	net.AddComponentPin("C12", 7, geometry.Point2D{})
+	net.AddComponentPin("R5", 2, geometry.Point2D{})
+	l.AddNet(net)
+
+	// C12 and U-A2 swap places: the renames must apply all at once
+	n := l.RenameComponents(map[string]string{"C12": "U-A2", "U-A2": "U-A3"})
+	if n != 3 {
+		t.Errorf("RenameComponents changed %d references, want 3", n)
+	}
+
+	for id, want := range map[string]string{"cvia-1": "U-A2", "cvia-2": "U-A3", "cvia-3": "R5", "cvia-4": ""} {
+		if got := l.GetConfirmedViaByID(id).ComponentID; got != want {
+			t.Errorf("%s ComponentID = %q, want %q", id, got, want)
+		}
+	}
+
+	got := l.GetNetByID("net-001")
+	if got.PadIDs[0] != "U-A2.7" || got.PadIDs[1] != "R5.2" {
+		t.Errorf("PadIDs = %v, want [U-A2.7 R5.2]", got.PadIDs)
+	}
+	if got.Elements[0].ID != "U-A2.7" {
+		t.Errorf("pad element ID = %q, want U-A2.7", got.Elements[0].ID)
+	}
+	if n := l.GetNetForElement("U-A2.7"); n == nil || n.ID != "net-001" {
+		t.Errorf("GetNetForElement(U-A2.7) = %v, want net-001", n)
+	}
+	if n := l.GetNetForElement("C12.7"); n != nil {
+		t.Errorf("GetNetForElement(C12.7) = %s, want nil", n.ID)
+	}
+}
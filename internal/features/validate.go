@@ -0,0 +1,165 @@
+package features
+
+import (
+	"fmt"
+
+	"pcb-tracer/internal/netlist"
+)
+
+// IssueKind classifies a feature-layer integrity problem.
+type IssueKind int
+
+const (
+	// IssueDanglingNetElement is a net element whose trace, via, or
+	// connector no longer exists.
+	IssueDanglingNetElement IssueKind = iota
+	// IssueDegenerateTrace is a trace with fewer than two points.
+	IssueDegenerateTrace
+	// IssueDanglingConfirmedVia is a confirmed via referencing a missing
+	// front or back via.
+	IssueDanglingConfirmedVia
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueDanglingNetElement:
+		return "dangling net element"
+	case IssueDegenerateTrace:
+		return "degenerate trace"
+	case IssueDanglingConfirmedVia:
+		return "dangling confirmed via"
+	default:
+		return "unknown"
+	}
+}
+
+// Issue describes one integrity problem found by Validate.
+type Issue struct {
+	Kind  IssueKind
+	Owner string // ID of the net, trace, or confirmed via holding the bad reference
+	Ref   string // Missing referenced ID (empty for degenerate traces)
+}
+
+func (i Issue) String() string {
+	if i.Ref == "" {
+		return fmt.Sprintf("%s: %s", i.Kind, i.Owner)
+	}
+	return fmt.Sprintf("%s: %s -> %s", i.Kind, i.Owner, i.Ref)
+}
+
+// Validate checks the layer for references left dangling by partial saves
+// or interrupted edits: net elements pointing at missing traces, vias, or
+// connectors; traces with fewer than two points; and confirmed vias whose
+// front or back via is gone. Component pads are not checked since
+// components live outside the layer.
+func (l *DetectedFeaturesLayer) Validate() []Issue {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.validateLocked()
+}
+
+func (l *DetectedFeaturesLayer) validateLocked() []Issue {
+	var issues []Issue
+
+	for _, tid := range l.traces {
+		if l.tracePointsLocked(tid) == nil {
+			issues = append(issues, Issue{Kind: IssueDegenerateTrace, Owner: tid})
+		}
+	}
+
+	for _, id := range l.confirmedVias {
+		cv := l.confirmedViasMap[id]
+		if cv == nil {
+			continue
+		}
+		for _, ref := range []string{cv.FrontViaID, cv.BackViaID} {
+			if ref != "" && l.features[ref] == nil {
+				issues = append(issues, Issue{Kind: IssueDanglingConfirmedVia, Owner: id, Ref: ref})
+			}
+		}
+	}
+
+	for _, nid := range l.nets {
+		n := l.netsMap[nid]
+		if n == nil {
+			continue
+		}
+		for _, e := range n.Elements {
+			if !l.netElementExistsLocked(e) {
+				issues = append(issues, Issue{Kind: IssueDanglingNetElement, Owner: nid, Ref: e.ID})
+			}
+		}
+	}
+
+	return issues
+}
+
+// netElementExistsLocked reports whether a net element's target is present.
+// A trace with fewer than two points counts as missing. Caller must hold l.mu.
+func (l *DetectedFeaturesLayer) netElementExistsLocked(e netlist.NetElement) bool {
+	switch e.Type {
+	case netlist.ElementTrace:
+		return l.tracePointsLocked(e.ID) != nil
+	case netlist.ElementVia:
+		return l.confirmedViasMap[e.ID] != nil || l.features[e.ID] != nil
+	case netlist.ElementConnector:
+		return l.connectorsMap[e.ID] != nil
+	default:
+		return true
+	}
+}
+
+// Repair fixes the problems reported by Validate and returns them.
+// Degenerate traces are deleted, dangling net elements are removed (and
+// nets left empty are dropped), and dangling front/back references on
+// confirmed vias are cleared. Each change is logged.
+func (l *DetectedFeaturesLayer) Repair() []Issue {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	issues := l.validateLocked()
+	if len(issues) == 0 {
+		return nil
+	}
+
+	for _, is := range issues {
+		switch is.Kind {
+		case IssueDegenerateTrace:
+			delete(l.features, is.Owner)
+			delete(l.selected, is.Owner)
+			l.traces = removeString(l.traces, is.Owner)
+			fmt.Printf("[Features] Repair: removed trace %s with fewer than 2 points\n", is.Owner)
+
+		case IssueDanglingConfirmedVia:
+			cv := l.confirmedViasMap[is.Owner]
+			if cv.FrontViaID == is.Ref {
+				cv.FrontViaID = ""
+			}
+			if cv.BackViaID == is.Ref {
+				cv.BackViaID = ""
+			}
+			fmt.Printf("[Features] Repair: cleared missing via %s from %s\n", is.Ref, is.Owner)
+
+		case IssueDanglingNetElement:
+			n := l.netsMap[is.Owner]
+			n.RemoveElement(is.Ref)
+			if l.elementToNet[is.Ref] == is.Owner {
+				delete(l.elementToNet, is.Ref)
+			}
+			fmt.Printf("[Features] Repair: removed missing element %s from net %s\n", is.Ref, is.Owner)
+		}
+	}
+
+	// Drop nets left without elements
+	for _, nid := range append([]string(nil), l.nets...) {
+		n := l.netsMap[nid]
+		if n == nil || len(n.Elements) > 0 {
+			continue
+		}
+		delete(l.netsMap, nid)
+		l.nets = removeString(l.nets, nid)
+		fmt.Printf("[Features] Repair: removed empty net %s\n", nid)
+	}
+
+	return issues
+}
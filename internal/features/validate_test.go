@@ -0,0 +1,124 @@
+package features
+
+import (
+	"reflect"
+	"testing"
+
+	"pcb-tracer/internal/connector"
+	"pcb-tracer/internal/netlist"
+	"pcb-tracer/internal/trace"
+	"pcb-tracer/internal/via"
+	"pcb-tracer/pkg/geometry"
+)
+
+// validateLayer returns a sound layer: a two-point trace, a via confirmed
+// from both sides and a connector, all in one net with a component pad.
+func validateLayer() *DetectedFeaturesLayer {
+	l := NewDetectedFeaturesLayer()
+	l.AddTrace(traceWith("trace-1", 2))
+	l.AddVia(via.Via{ID: "via-1"})
+	l.AddVia(via.Via{ID: "via-2"})
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-1", FrontViaID: "via-1", BackViaID: "via-2"})
+	l.AddConnector(&connector.Connector{ID: "conn-1"})
+	l.AddNet(netWith("net-001",
+		netlist.NetElement{Type: netlist.ElementConnector, ID: "conn-1"},
+		netlist.NetElement{Type: netlist.ElementTrace, ID: "trace-1"},
+		netlist.NetElement{Type: netlist.ElementVia, ID: "cvia-1"},
+		netlist.NetElement{Type: netlist.ElementPad, ID: "U1.3"}))
+	return l
+}
+
+func traceWith(id string, points int) trace.ExtendedTrace {
+	t := trace.ExtendedTrace{Trace: trace.Trace{ID: id}}
+	for i := 0; i < points; i++ {
+		t.Points = append(t.Points, geometry.Point2D{X: float64(10 * i)})
+	}
+	return t
+}
+
+func netWith(id string, elements ...netlist.NetElement) *netlist.ElectricalNet {
+	n := netlist.NewElectricalNetWithName(id, id)
+	n.Elements = elements
+	n.RebuildIDLists()
+	return n
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		setup func(l *DetectedFeaturesLayer)
+		want  []Issue
+	}{
+		{"sound layer", func(*DetectedFeaturesLayer) {}, nil},
+		{"single-point trace", func(l *DetectedFeaturesLayer) { l.AddTrace(traceWith("trace-2", 1)) },
+			[]Issue{{IssueDegenerateTrace, "trace-2", ""}}},
+		{"empty trace in a net", func(l *DetectedFeaturesLayer) {
+			l.AddTrace(traceWith("trace-2", 0))
+			l.AddNet(netWith("net-002", netlist.NetElement{Type: netlist.ElementTrace, ID: "trace-2"}))
+		}, []Issue{{IssueDegenerateTrace, "trace-2", ""}, {IssueDanglingNetElement, "net-002", "trace-2"}}},
+		{"confirmed via missing its back via", func(l *DetectedFeaturesLayer) {
+			l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-2", FrontViaID: "via-1", BackViaID: "via-9"})
+		}, []Issue{{IssueDanglingConfirmedVia, "cvia-2", "via-9"}}},
+		{"net with a missing trace", func(l *DetectedFeaturesLayer) {
+			l.AddNet(netWith("net-002", netlist.NetElement{Type: netlist.ElementTrace, ID: "trace-7"}))
+		}, []Issue{{IssueDanglingNetElement, "net-002", "trace-7"}}},
+		{"net with a missing via", func(l *DetectedFeaturesLayer) {
+			l.AddNet(netWith("net-002",
+				netlist.NetElement{Type: netlist.ElementVia, ID: "via-2"},
+				netlist.NetElement{Type: netlist.ElementVia, ID: "cvia-9"}))
+		}, []Issue{{IssueDanglingNetElement, "net-002", "cvia-9"}}},
+		{"net with a missing connector", func(l *DetectedFeaturesLayer) {
+			l.AddNet(netWith("net-002", netlist.NetElement{Type: netlist.ElementConnector, ID: "conn-9"}))
+		}, []Issue{{IssueDanglingNetElement, "net-002", "conn-9"}}},
+	} {
+		l := validateLayer()
+		c.setup(l)
+		if got := l.Validate(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: issues %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	l := validateLayer()
+	l.AddTrace(traceWith("trace-2", 1))
+	l.AddConfirmedVia(&via.ConfirmedVia{ID: "cvia-2", FrontViaID: "via-8", BackViaID: "via-2"})
+	n := l.GetNetByID("net-001")
+	n.Elements = append(n.Elements, netlist.NetElement{Type: netlist.ElementTrace, ID: "trace-7"})
+	n.RebuildIDLists()
+	l.AddNet(netWith("net-002", netlist.NetElement{Type: netlist.ElementTrace, ID: "trace-2"}))
+
+	found := l.Validate()
+	if repaired := l.Repair(); !reflect.DeepEqual(repaired, found) {
+		t.Errorf("Repair fixed %v, want the %v Validate found", repaired, found)
+	}
+	if len(found) != 4 {
+		t.Errorf("found %d issues, want 4: %v", len(found), found)
+	}
+	if issues := l.Validate(); len(issues) != 0 {
+		t.Errorf("issues left after Repair: %v", issues)
+	}
+
+	if l.GetTraceFeature("trace-2") != nil || l.GetTraceFeature("trace-1") == nil {
+		t.Error("Repair should remove trace-2 and keep trace-1")
+	}
+	if cv := l.GetConfirmedViaByID("cvia-2"); cv.FrontViaID != "" || cv.BackViaID != "via-2" {
+		t.Errorf("cvia-2 front %q back %q, want none and via-2", cv.FrontViaID, cv.BackViaID)
+	}
+	var ids []string
+	for _, e := range l.GetNetByID("net-001").Elements {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"conn-1", "trace-1", "cvia-1", "U1.3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("net-001 elements %v, want %v", ids, want)
+	}
+	if l.GetNetByID("net-002") != nil {
+		t.Error("empty net-002 should be dropped")
+	}
+	if l.GetNetForElement("trace-2") != nil {
+		t.Error("trace-2 still maps to a net")
+	}
+	if l.Repair() != nil {
+		t.Error("second Repair: want nothing to fix")
+	}
+}
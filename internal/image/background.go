@@ -0,0 +1,82 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// RemoveBackground returns a copy of img in which pixels outside the board
+// outline that match the scanner background bg (every RGB channel within tol)
+// are made fully transparent. Pixels inside the outline are always kept, so
+// board areas that happen to match the background color survive.
+func RemoveBackground(img image.Image, outline geometry.RectInt, bg color.RGBA, tol int) *image.RGBA {
+	bounds := img.Bounds()
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+
+	board := image.Rect(outline.X, outline.Y, outline.X+outline.Width, outline.Y+outline.Height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if (image.Point{x, y}).In(board) {
+				continue
+			}
+			i := out.PixOffset(x, y)
+			p := out.Pix[i : i+4 : i+4]
+			if absDiff(p[0], bg.R) <= tol && absDiff(p[1], bg.G) <= tol && absDiff(p[2], bg.B) <= tol {
+				p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+			}
+		}
+	}
+	return out
+}
+
+// SampleBackground estimates the scanner background color as the per-channel
+// median of opaque pixels outside the board outline. If the outline covers the
+// whole image, a 4-pixel strip along the image border is sampled instead.
+func SampleBackground(img image.Image, outline geometry.RectInt) color.RGBA {
+	bounds := img.Bounds()
+	board := image.Rect(outline.X, outline.Y, outline.X+outline.Width, outline.Y+outline.Height)
+	inner := bounds.Inset(4)
+
+	var rs, gs, bs []uint8
+	sample := func(skip func(p image.Point) bool) {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				if skip(image.Point{x, y}) {
+					continue
+				}
+				c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+				if c.A < 255 {
+					continue
+				}
+				rs = append(rs, c.R)
+				gs = append(gs, c.G)
+				bs = append(bs, c.B)
+			}
+		}
+	}
+	sample(func(p image.Point) bool { return p.In(board) })
+	if len(rs) == 0 {
+		sample(func(p image.Point) bool { return p.In(inner) })
+	}
+	if len(rs) == 0 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: median8(rs), G: median8(gs), B: median8(bs), A: 255}
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
+
+func median8(v []uint8) uint8 {
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+	return v[len(v)/2]
+}
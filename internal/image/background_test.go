@@ -0,0 +1,97 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// scannedBoard draws a 100×60 scan: a beige background whose red channel
+// ramps from 200 to 209 across the bed, a green board at (20,10) 60×40 with
+// one background-colored pixel at (40,20), and a copper trace along y=30
+// that runs off the board's right edge to x=94.
+func scannedBoard() (*image.RGBA, geometry.RectInt) {
+	outline := geometry.RectInt{X: 20, Y: 10, Width: 60, Height: 40}
+	img := image.NewRGBA(image.Rect(0, 0, 100, 60))
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 100; x++ {
+			c := color.RGBA{R: uint8(200 + x/10), G: 190, B: 170, A: 255}
+			if x >= 20 && x < 80 && y >= 10 && y < 50 {
+				c = color.RGBA{R: 30, G: 90, B: 40, A: 255}
+			}
+			if y == 30 && x >= 20 && x < 95 {
+				c = color.RGBA{R: 190, G: 120, B: 50, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	img.SetRGBA(40, 20, color.RGBA{R: 205, G: 190, B: 170, A: 255})
+	return img, outline
+}
+
+func TestRemoveBackground(t *testing.T) {
+	src, outline := scannedBoard()
+	bg := color.RGBA{R: 205, G: 190, B: 170, A: 255}
+
+	// Background probes at the ends and middle of the ramp
+	left, middle, right := image.Pt(2, 2), image.Pt(55, 2), image.Pt(95, 55)
+	// Kept at any tolerance: board, the background-colored board pixel, and
+	// the trace where it leaves the board
+	kept := []image.Point{{30, 20}, {40, 20}, {90, 30}}
+
+	for _, c := range []struct {
+		tol     int
+		cleared []image.Point
+		opaque  []image.Point
+	}{
+		{0, []image.Point{middle}, []image.Point{left, right}},
+		{4, []image.Point{middle, right}, []image.Point{left}},
+		{5, []image.Point{left, middle, right}, nil},
+		{60, []image.Point{left, middle, right}, nil},
+	} {
+		out := RemoveBackground(src, outline, bg, c.tol)
+		if out.Bounds() != src.Bounds() {
+			t.Fatalf("tol %d: bounds %v, want %v", c.tol, out.Bounds(), src.Bounds())
+		}
+		for _, p := range c.cleared {
+			if a := out.RGBAAt(p.X, p.Y).A; a != 0 {
+				t.Errorf("tol %d: background %v alpha %d, want transparent", c.tol, p, a)
+			}
+		}
+		for _, p := range append(c.opaque, kept...) {
+			if got, want := out.RGBAAt(p.X, p.Y), src.RGBAAt(p.X, p.Y); got != want {
+				t.Errorf("tol %d: %v is %v, want %v kept", c.tol, p, got, want)
+			}
+		}
+	}
+	if src.RGBAAt(middle.X, middle.Y).A != 255 {
+		t.Error("RemoveBackground modified its input")
+	}
+}
+
+func TestSampleBackground(t *testing.T) {
+	src, outline := scannedBoard()
+	whole := geometry.RectInt{Width: 100, Height: 60}
+
+	for _, c := range []struct {
+		name    string
+		outline geometry.RectInt
+		want    color.RGBA
+	}{
+		// The trace stub is outvoted; the board hides most of the middle
+		// of the ramp, pulling the red median below 205
+		{"outside the outline", outline, color.RGBA{R: 204, G: 190, B: 170, A: 255}},
+		// The 4-pixel border strip sees the whole ramp
+		{"outline covers the image", whole, color.RGBA{R: 205, G: 190, B: 170, A: 255}},
+	} {
+		if got := SampleBackground(src, c.outline); got != c.want {
+			t.Errorf("%s: %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	if got := SampleBackground(image.NewRGBA(image.Rect(0, 0, 4, 4)), whole); got != (color.RGBA{A: 255}) {
+		t.Errorf("transparent image: %v, want opaque black", got)
+	}
+}
@@ -0,0 +1,124 @@
+package image
+
+import (
+	"image"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"pcb-tracer/pkg/colorutil"
+)
+
+// CopperMaskParams is an inclusive HSV range (OpenCV convention: H 0-180,
+// S 0-255, V 0-255) selecting copper pixels. It is comparable so it can key
+// the per-layer mask cache.
+type CopperMaskParams struct {
+	HueMin, HueMax float64
+	SatMin, SatMax float64
+	ValMin, ValMax float64
+}
+
+// DefaultCopperMaskParams returns the range for bright, warm-toned exposed
+// copper and pads, matching the warm tones via pad detection treats as metal.
+func DefaultCopperMaskParams() CopperMaskParams {
+	return CopperMaskParams{
+		HueMin: 0, HueMax: 40,
+		SatMin: 0, SatMax: 255,
+		ValMin: 180, ValMax: 255,
+	}
+}
+
+// Contains reports whether an HSV triple falls inside the range.
+func (p CopperMaskParams) Contains(h, s, v float64) bool {
+	return h >= p.HueMin && h <= p.HueMax &&
+		s >= p.SatMin && s <= p.SatMax &&
+		v >= p.ValMin && v <= p.ValMax
+}
+
+// ComputeCopperMask thresholds img against params and returns a binary mask
+// (255 = copper) with the same bounds as img.
+func ComputeCopperMask(img image.Image, params CopperMaskParams) *image.Gray {
+	bounds := img.Bounds()
+	mask := image.NewGray(bounds)
+	height := bounds.Dy()
+	if height == 0 || bounds.Dx() == 0 {
+		return mask
+	}
+
+	numWorkers := runtime.NumCPU()
+	rowsPerWorker := (height + numWorkers - 1) / numWorkers
+
+	var wg sync.WaitGroup
+	for startY := bounds.Min.Y; startY < bounds.Max.Y; startY += rowsPerWorker {
+		endY := min(startY+rowsPerWorker, bounds.Max.Y)
+		wg.Add(1)
+		go func(yStart, yEnd int) {
+			defer wg.Done()
+			for y := yStart; y < yEnd; y++ {
+				row := mask.Pix[(y-bounds.Min.Y)*mask.Stride:]
+				for x := bounds.Min.X; x < bounds.Max.X; x++ {
+					r, g, b, _ := img.At(x, y).RGBA()
+					h, s, v := colorutil.RGBToHSV(float64(r>>8), float64(g>>8), float64(b>>8))
+					if params.Contains(h, s, v) {
+						row[x-bounds.Min.X] = 255
+					}
+				}
+			}
+		}(startY, endY)
+	}
+	wg.Wait()
+
+	return mask
+}
+
+// CopperMask returns the copper mask of the layer image for params,
+// computing it on first use and serving it from cache afterwards. The cache
+// is dropped automatically when Layer.Image is replaced; call
+// InvalidateCopperMask after modifying the existing image's pixels in place.
+// The returned mask is shared and must not be modified. Returns nil if the
+// layer has no image.
+func (l *Layer) CopperMask(params CopperMaskParams) *image.Gray {
+	l.copperMu.Lock()
+	defer l.copperMu.Unlock()
+
+	if l.Image == nil {
+		l.copperSrc = nil
+		l.copperMasks = nil
+		return nil
+	}
+	if !sameImage(l.copperSrc, l.Image) {
+		l.copperSrc = l.Image
+		l.copperMasks = nil
+	}
+	if mask, ok := l.copperMasks[params]; ok {
+		return mask
+	}
+
+	mask := ComputeCopperMask(l.Image, params)
+	if l.copperMasks == nil {
+		l.copperMasks = make(map[CopperMaskParams]*image.Gray)
+	}
+	l.copperMasks[params] = mask
+	return mask
+}
+
+// InvalidateCopperMask discards all cached copper masks for the layer.
+func (l *Layer) InvalidateCopperMask() {
+	l.copperMu.Lock()
+	l.copperSrc = nil
+	l.copperMasks = nil
+	l.copperMu.Unlock()
+}
+
+// sameImage reports whether a and b are the same image value. Images whose
+// dynamic type is not comparable are never considered the same.
+func sameImage(a, b image.Image) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,82 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// copperBoard returns a w×h green board with a bright copper stripe across
+// rows 10-19.
+func copperBoard(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		c := color.RGBA{30, 90, 40, 255}
+		if y >= 10 && y < 20 {
+			c = color.RGBA{230, 190, 120, 255}
+		}
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCopperMaskCache(t *testing.T) {
+	l := &Layer{Image: copperBoard(40, 40)}
+	params := DefaultCopperMaskParams()
+
+	mask := l.CopperMask(params)
+	if mask.GrayAt(5, 15).Y != 255 || mask.GrayAt(5, 5).Y != 0 {
+		t.Fatalf("mask: stripe %d, board %d, want 255, 0", mask.GrayAt(5, 15).Y, mask.GrayAt(5, 5).Y)
+	}
+	if l.CopperMask(params) != mask {
+		t.Error("second call recomputed the mask")
+	}
+	other := params
+	other.ValMin = 250
+	if l.CopperMask(other) == mask {
+		t.Error("different params returned the same mask")
+	}
+
+	// Replacing the image drops the cache
+	l.Image = copperBoard(30, 30)
+	replaced := l.CopperMask(params)
+	if replaced == mask || replaced.Bounds() != l.Image.Bounds() {
+		t.Errorf("after replacing the image: mask %v, want a new %v mask", replaced.Bounds(), l.Image.Bounds())
+	}
+
+	// In-place edits need an explicit invalidation
+	l.Image.(*image.RGBA).SetRGBA(5, 5, color.RGBA{230, 190, 120, 255})
+	if l.CopperMask(params).GrayAt(5, 5).Y != 0 {
+		t.Error("cache changed without invalidation")
+	}
+	l.InvalidateCopperMask()
+	if l.CopperMask(params).GrayAt(5, 5).Y != 255 {
+		t.Error("InvalidateCopperMask kept the stale mask")
+	}
+
+	l.Image = nil
+	if l.CopperMask(params) != nil {
+		t.Error("no image: want nil mask")
+	}
+}
+
+func BenchmarkCopperMask(b *testing.B) {
+	img := copperBoard(2000, 1500)
+	params := DefaultCopperMaskParams()
+
+	b.Run("compute", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ComputeCopperMask(img, params)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		l := &Layer{Image: img}
+		l.CopperMask(params)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			l.CopperMask(params)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"golang.org/x/image/tiff"
+)
+
+// Export formats accepted by ExportOptions.Format.
+const (
+	FormatPNG  = "png"
+	FormatTIFF = "tiff"
+	FormatJPEG = "jpeg"
+)
+
+// DefaultJPEGQuality is the JPEG quality used when ExportOptions.Quality is 0.
+const DefaultJPEGQuality = 90
+
+// ExportOptions selects the resolution and file format of a saved image.
+// The zero value keeps the source resolution and writes PNG.
+type ExportOptions struct {
+	DPI     float64 // Output resolution; 0 keeps the source resolution
+	Format  string  // FormatPNG (default), FormatTIFF or FormatJPEG
+	Quality int     // JPEG quality 1-100; 0 uses DefaultJPEGQuality
+}
+
+// DefaultExportOptions returns options that write a PNG at the source
+// resolution.
+func DefaultExportOptions() ExportOptions {
+	return ExportOptions{Format: FormatPNG}
+}
+
+// format returns the normalized format name, or an error if unsupported.
+func (o ExportOptions) format() (string, error) {
+	switch strings.ToLower(o.Format) {
+	case "", FormatPNG:
+		return FormatPNG, nil
+	case FormatTIFF, "tif":
+		return FormatTIFF, nil
+	case FormatJPEG, "jpg":
+		return FormatJPEG, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q", o.Format)
+}
+
+// IsDefault reports whether o writes a PNG at the source resolution.
+func (o ExportOptions) IsDefault(srcDPI float64) bool {
+	f, err := o.format()
+	return err == nil && f == FormatPNG && (o.DPI <= 0 || o.DPI == srcDPI)
+}
+
+// Ext returns the file extension for the format, with the leading dot.
+func (o ExportOptions) Ext() string {
+	switch f, _ := o.format(); f {
+	case FormatTIFF:
+		return ".tif"
+	case FormatJPEG:
+		return ".jpg"
+	}
+	return ".png"
+}
+
+// Export rescales img from srcDPI to opts.DPI and writes it to path in
+// opts.Format, recording the output resolution in the file (PNG pHYs, TIFF
+// resolution tags, JPEG JFIF density) so measurements stay valid in other
+// tools. srcDPI may be 0 only when opts.DPI is 0, in which case no
+// resolution is recorded.
+func Export(img image.Image, srcDPI float64, path string, opts ExportOptions) error {
+	format, err := opts.format()
+	if err != nil {
+		return err
+	}
+	dpi := srcDPI
+	if opts.DPI > 0 && opts.DPI != srcDPI {
+		if srcDPI <= 0 {
+			return fmt.Errorf("cannot export at %.0f DPI: source DPI unknown", opts.DPI)
+		}
+		if img, err = ScaleImage(img, opts.DPI/srcDPI); err != nil {
+			return err
+		}
+		dpi = opts.DPI
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatPNG:
+		encoder := &png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return err
+		}
+		if dpi > 0 {
+			setPNGDPI(&buf, dpi)
+		}
+	case FormatTIFF:
+		if err := tiff.Encode(&buf, img, &tiff.Options{Compression: tiff.Deflate, Predictor: true}); err != nil {
+			return err
+		}
+		if dpi > 0 {
+			if err := setTIFFDPI(buf.Bytes(), dpi); err != nil {
+				return err
+			}
+		}
+	case FormatJPEG:
+		quality := opts.Quality
+		if quality <= 0 {
+			quality = DefaultJPEGQuality
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: min(quality, 100)}); err != nil {
+			return err
+		}
+		if dpi > 0 {
+			setJPEGDPI(&buf, dpi)
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// setPNGDPI inserts a pHYs chunk after the IHDR chunk of an encoded PNG.
+func setPNGDPI(buf *bytes.Buffer, dpi float64) {
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4 // Signature, then IHDR length, type, data, CRC
+	ppm := uint32(math.Round(dpi / 0.0254))
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:4], 9)
+	copy(chunk[4:8], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:12], ppm)
+	binary.BigEndian.PutUint32(chunk[12:16], ppm)
+	chunk[16] = 1 // Pixels per meter
+	binary.BigEndian.PutUint32(chunk[17:21], crc32.ChecksumIEEE(chunk[4:17]))
+
+	data := buf.Bytes()
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	out = append(out, data[ihdrEnd:]...)
+	buf.Reset()
+	buf.Write(out)
+}
+
+// setJPEGDPI inserts a JFIF APP0 segment with the density in dots per inch
+// after the SOI marker of an encoded JPEG, which image/jpeg omits.
+func setJPEGDPI(buf *bytes.Buffer, dpi float64) {
+	d := uint16(min(math.Round(dpi), math.MaxUint16))
+	app0 := []byte{
+		0xFF, 0xE0, 0, 16, // APP0, length
+		'J', 'F', 'I', 'F', 0,
+		1, 1, // Version 1.01
+		1,                     // Units: dots per inch
+		byte(d >> 8), byte(d), // X density
+		byte(d >> 8), byte(d), // Y density
+		0, 0, // No thumbnail
+	}
+
+	data := buf.Bytes()
+	out := make([]byte, 0, len(data)+len(app0))
+	out = append(out, data[:2]...)
+	out = append(out, app0...)
+	out = append(out, data[2:]...)
+	buf.Reset()
+	buf.Write(out)
+}
+
+// setTIFFDPI overwrites the XResolution and YResolution rationals of an
+// encoded single-image TIFF in place and sets the unit to inches.
+func setTIFFDPI(data []byte, dpi float64) error {
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return fmt.Errorf("not a TIFF")
+	}
+	ifd := int(order.Uint32(data[4:8]))
+	n := int(order.Uint16(data[ifd : ifd+2]))
+	const (
+		tagXResolution    = 282
+		tagYResolution    = 283
+		tagResolutionUnit = 296
+	)
+	found := 0
+	for i := 0; i < n; i++ {
+		e := data[ifd+2+12*i : ifd+2+12*(i+1)]
+		switch order.Uint16(e[0:2]) {
+		case tagXResolution, tagYResolution:
+			off := int(order.Uint32(e[8:12]))
+			order.PutUint32(data[off:off+4], uint32(math.Round(dpi*100)))
+			order.PutUint32(data[off+4:off+8], 100)
+			found++
+		case tagResolutionUnit:
+			order.PutUint16(e[8:10], 2) // Inches
+		}
+	}
+	if found != 2 {
+		return fmt.Errorf("TIFF has no resolution tags")
+	}
+	return nil
+}
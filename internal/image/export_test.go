@@ -0,0 +1,92 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExportRecordsDPI exports a 600 DPI image in each format, at its own
+// resolution and rescaled, and checks the file decodes at the expected size
+// and carries the output DPI in its metadata.
+func TestExportRecordsDPI(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 120, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 120; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x * 2), G: uint8(y * 3), B: 90, A: 255})
+		}
+	}
+
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		opts ExportOptions
+		w, h int
+	}{
+		{DefaultExportOptions(), 120, 80},
+		{ExportOptions{Format: FormatPNG, DPI: 300}, 60, 40},
+		{ExportOptions{Format: FormatTIFF, DPI: 1200}, 240, 160},
+		{ExportOptions{Format: FormatJPEG, DPI: 300, Quality: 75}, 60, 40},
+	} {
+		path := filepath.Join(dir, "out_"+tc.opts.Format+tc.opts.Ext())
+		if err := Export(src, 600, path, tc.opts); err != nil {
+			t.Fatalf("%+v: %v", tc.opts, err)
+		}
+
+		want := tc.opts.DPI
+		if want == 0 {
+			want = 600
+		}
+		if dpi, err := ExtractDPI(path); err != nil || dpi != want {
+			t.Errorf("%+v: DPI %v (%v), want %v", tc.opts, dpi, err, want)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, _, err := image.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%+v: decode: %v", tc.opts, err)
+		}
+		if got := img.Bounds().Size(); got != image.Pt(tc.w, tc.h) {
+			t.Errorf("%+v: size %v, want %dx%d", tc.opts, got, tc.w, tc.h)
+		}
+	}
+}
+
+// TestLoadMarksAssumedDPI checks that Load takes the DPI from a PNG's pHYs
+// chunk and flags a PNG without one as having an assumed DPI.
+func TestLoadMarksAssumedDPI(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	dir := t.TempDir()
+
+	tagged := filepath.Join(dir, "tagged.png")
+	if err := Export(src, 1200, tagged, DefaultExportOptions()); err != nil {
+		t.Fatal(err)
+	}
+	bare := filepath.Join(dir, "bare.png")
+	if err := Export(src, 0, bare, DefaultExportOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path    string
+		dpi     float64
+		assumed bool
+	}{
+		{tagged, 1200, false},
+		{bare, 0, true},
+	} {
+		l, err := Load(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.DPI != tc.dpi || l.DPIAssumed != tc.assumed {
+			t.Errorf("%s: DPI %v assumed %v, want %v assumed %v",
+				filepath.Base(tc.path), l.DPI, l.DPIAssumed, tc.dpi, tc.assumed)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+)
+
+// Hash returns a fingerprint of the layer's pixels, for telling whether
+// results computed on the image still apply to it. It is cached until
+// Image is replaced, and is empty if no image is loaded.
+func (l *Layer) Hash() string {
+	l.hashMu.Lock()
+	defer l.hashMu.Unlock()
+
+	if l.Image == nil {
+		return ""
+	}
+	if !sameImage(l.hashSrc, l.Image) {
+		l.hashSrc = l.Image
+		l.hash = ImageHash(l.Image)
+	}
+	return l.hash
+}
+
+// ImageHash returns a hex fingerprint of img's bounds and pixels, so the
+// same file decoded again hashes alike and any edited pixel changes it.
+func ImageHash(img image.Image) string {
+	h := sha256.New()
+	b := img.Bounds()
+	var hdr [16]byte
+	binary.BigEndian.PutUint32(hdr[0:], uint32(int32(b.Min.X)))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(int32(b.Min.Y)))
+	binary.BigEndian.PutUint32(hdr[8:], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(hdr[12:], uint32(b.Dy()))
+	h.Write(hdr[:])
+
+	rows := func(pix []uint8, stride, rowBytes int) {
+		for y := 0; y < b.Dy(); y++ {
+			h.Write(pix[y*stride : y*stride+rowBytes])
+		}
+	}
+	switch m := img.(type) {
+	case *image.RGBA:
+		rows(m.Pix, m.Stride, 4*b.Dx())
+	case *image.NRGBA:
+		rows(m.Pix, m.Stride, 4*b.Dx())
+	case *image.Gray:
+		rows(m.Pix, m.Stride, b.Dx())
+	default:
+		row := make([]byte, 8*b.Dx())
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				r, g, bl, a := img.At(x, y).RGBA()
+				i := 8 * (x - b.Min.X)
+				binary.BigEndian.PutUint16(row[i:], uint16(r))
+				binary.BigEndian.PutUint16(row[i+2:], uint16(g))
+				binary.BigEndian.PutUint16(row[i+4:], uint16(bl))
+				binary.BigEndian.PutUint16(row[i+6:], uint16(a))
+			}
+			h.Write(row)
+		}
+	}
+	sum := h.Sum(nil)
+	return hex.EncodeToString(sum[:16])
+}
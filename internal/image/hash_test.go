@@ -0,0 +1,59 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func testPattern(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x * 7), G: uint8(y * 13), B: uint8(x ^ y), A: 255})
+		}
+	}
+	return img
+}
+
+// TestImageHash checks that identical pixels hash alike and that a changed
+// pixel or size changes the hash.
+func TestImageHash(t *testing.T) {
+	a := ImageHash(testPattern(40, 30))
+	if b := ImageHash(testPattern(40, 30)); a != b {
+		t.Errorf("identical images hash %s and %s", a, b)
+	}
+
+	edited := testPattern(40, 30)
+	edited.SetRGBA(17, 9, color.RGBA{R: 1, G: 2, B: 3, A: 255})
+	if b := ImageHash(edited); a == b {
+		t.Error("edited pixel did not change the hash")
+	}
+	if b := ImageHash(testPattern(30, 40)); a == b {
+		t.Error("different size did not change the hash")
+	}
+
+	// The generic path must agree with itself for a wrapped image
+	gray := image.NewGray16(image.Rect(0, 0, 8, 8))
+	if ImageHash(gray) != ImageHash(image.NewGray16(image.Rect(0, 0, 8, 8))) {
+		t.Error("identical Gray16 images hash differently")
+	}
+}
+
+// TestLayerHashFollowsImage checks that the cached layer hash is recomputed
+// when the layer's image is replaced.
+func TestLayerHashFollowsImage(t *testing.T) {
+	l := NewLayer()
+	if h := l.Hash(); h != "" {
+		t.Errorf("empty layer hash = %q, want empty", h)
+	}
+	l.Image = testPattern(20, 20)
+	first := l.Hash()
+	if first != ImageHash(l.Image) {
+		t.Errorf("layer hash %s, want %s", first, ImageHash(l.Image))
+	}
+	l.Image = testPattern(21, 20)
+	if l.Hash() == first {
+		t.Error("layer hash not updated after the image was replaced")
+	}
+}
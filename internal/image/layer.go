@@ -8,10 +8,12 @@ import (
 	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"pcb-tracer/pkg/geometry"
 
@@ -41,6 +43,7 @@ func (s Side) String() string {
 // Layer represents a single image layer in the project.
 type Layer struct {
 	Path    string         // Original file path
+	Page    int            // Page within Path for multi-page TIFFs (0 = first)
 	Image   image.Image    // Loaded image data
 	Side    Side           // Front or back
 	DPI     float64        // Detected or user-specified DPI
@@ -48,6 +51,11 @@ type Layer struct {
 	Opacity float64        // Layer opacity (0.0 - 1.0)
 	Bounds  geometry.Rect  // Board bounds within image (if detected)
 
+	// DPIAssumed is set when the file carried no resolution metadata, so DPI
+	// is unknown (0) or was taken from the project rather than the scan.
+	// Size thresholds in inches are unreliable until the user confirms it.
+	DPIAssumed bool
+
 	// Automatic alignment parameters (from alignment process)
 	AutoRotation float64 // Rotation applied during auto-alignment (degrees)
 	AutoScaleX   float64 // X scale from auto-alignment (1.0 = no scale)
@@ -84,6 +92,20 @@ type Layer struct {
 	// Normalization state
 	NormalizedPath string // Path to normalized PNG (empty = not yet normalized)
 	IsNormalized   bool   // Whether Layer.Image is the normalized (all transforms baked) version
+
+	// Cached copper masks for Image (see CopperMask)
+	copperMu    sync.Mutex
+	copperSrc   image.Image
+	copperMasks map[CopperMaskParams]*image.Gray
+
+	// Display mipmap of Image (see Pyramid)
+	pyramidMu sync.Mutex
+	pyramid   *Pyramid
+
+	// Cached pixel fingerprint of Image (see Hash)
+	hashMu  sync.Mutex
+	hashSrc image.Image
+	hash    string
 }
 
 // NewLayer creates a new Layer with default settings.
@@ -117,12 +139,12 @@ func Load(path string) (*Layer, error) {
 	layer.Path = path
 	layer.Image = img
 
-	// Try to extract DPI from TIFF metadata
-	ext := strings.ToLower(filepath.Ext(path))
-	if ext == ".tiff" || ext == ".tif" {
-		if dpi, err := ExtractTIFFDPI(path); err == nil {
-			layer.DPI = dpi
-		}
+	// Try to extract DPI from file metadata (TIFF tags, PNG pHYs, JPEG JFIF)
+	if dpi, err := ExtractDPI(path); err == nil {
+		layer.DPI = dpi
+	} else {
+		layer.DPIAssumed = true
+		fmt.Printf("Load: no DPI metadata in %s: %v\n", filepath.Base(path), err)
 	}
 
 	// Guess side from filename
@@ -183,11 +205,30 @@ func (l *Layer) PixelAt(x, y int) color.Color {
 	return l.Image.At(x, y)
 }
 
+// ShearFactor returns f if it is a usable shear/scale factor, otherwise 1.0.
+// Zero, negative and NaN factors would collapse or mirror the geometry, so they
+// are treated as "not set" rather than applied.
+func ShearFactor(f float64) float64 {
+	if !(f > 0) || math.IsInf(f, 0) {
+		return 1.0
+	}
+	return f
+}
+
+// EnsureShearDefaults coerces any unusable shear factor to identity (1.0).
+func (l *Layer) EnsureShearDefaults() {
+	l.ShearTopX = ShearFactor(l.ShearTopX)
+	l.ShearBottomX = ShearFactor(l.ShearBottomX)
+	l.ShearLeftY = ShearFactor(l.ShearLeftY)
+	l.ShearRightY = ShearFactor(l.ShearRightY)
+}
+
 // Normalize rasterizes all manual transforms (offset, rotation, shear) into a flat
 // image with no remaining transforms. Returns the normalized image and a forward-transform
 // function that maps old image coordinates to new image coordinates (for remapping
-// component bounds, contacts, etc.).
-func (l *Layer) Normalize() (*image.RGBA, func(x, y float64) (float64, float64)) {
+// component bounds, contacts, etc.). With opts.Bilinear, rotated/sheared pixels are
+// interpolated from their four source neighbors instead of taken from the nearest one.
+func (l *Layer) Normalize(opts ResampleOptions) (*image.RGBA, func(x, y float64) (float64, float64)) {
 	src := l.Image
 	srcBounds := src.Bounds()
 	srcW := float64(srcBounds.Dx())
@@ -198,22 +239,11 @@ func (l *Layer) Normalize() (*image.RGBA, func(x, y float64) (float64, float64))
 	offsetY := float64(l.ManualOffsetY)
 	rotation := l.ManualRotation * math.Pi / 180.0
 
+	l.EnsureShearDefaults()
 	shearTopX := l.ShearTopX
 	shearBottomX := l.ShearBottomX
 	shearLeftY := l.ShearLeftY
 	shearRightY := l.ShearRightY
-	if shearTopX == 0 {
-		shearTopX = 1.0
-	}
-	if shearBottomX == 0 {
-		shearBottomX = 1.0
-	}
-	if shearLeftY == 0 {
-		shearLeftY = 1.0
-	}
-	if shearRightY == 0 {
-		shearRightY = 1.0
-	}
 
 	// Rotation center
 	var srcCx, srcCy float64
@@ -275,8 +305,13 @@ func (l *Layer) Normalize() (*image.RGBA, func(x, y float64) (float64, float64))
 				scaledX := rotX / scaleX
 				scaledY := rotY / scaleY
 
-				srcX = int(scaledX + srcCx)
-				srcY = int(scaledY + srcCy)
+				if opts.Bilinear {
+					if c, ok := SampleBilinear(src, scaledX+srcCx, scaledY+srcCy); ok {
+						output.SetRGBA(x, y, c)
+					}
+					continue
+				}
+				srcX, srcY = NearestPixel(scaledX+srcCx, scaledY+srcCy)
 			} else {
 				srcX = int(imgX) + srcBounds.Min.X
 				srcY = int(imgY) + srcBounds.Min.Y
@@ -299,8 +334,7 @@ func (l *Layer) Normalize() (*image.RGBA, func(x, y float64) (float64, float64))
 	// Old image coords had offset applied at render time; in normalized space,
 	// the offset is baked in, so new_coord = old_coord + offset.
 	// Rotation and shear are also baked in via the forward transform.
-	cosF := math.Cos(rotation)
-	sinF := math.Sin(rotation)
+	rotate := geometry.RotationAbout(geometry.Point2D{X: srcCx, Y: srcCy}, l.ManualRotation)
 
 	forwardTransform := func(ox, oy float64) (float64, float64) {
 		if !hasTransform {
@@ -331,13 +365,12 @@ func (l *Layer) Normalize() (*image.RGBA, func(x, y float64) (float64, float64))
 		sX := relX * scaleX
 		sY := relY * scaleY
 
-		// Forward rotate
-		rX := sX*cosF - sY*sinF
-		rY := sX*sinF + sY*cosF
+		// Forward rotate about the same center the rasterizer used
+		r := rotate.Apply(geometry.Point2D{X: sX + srcCx, Y: sY + srcCy})
 
-		// Translate back and apply offset
-		newX := rX + srcCx - float64(srcBounds.Min.X) + offsetX
-		newY := rY + srcCy - float64(srcBounds.Min.Y) + offsetY
+		// Apply offset
+		newX := r.X - float64(srcBounds.Min.X) + offsetX
+		newY := r.Y - float64(srcBounds.Min.Y) + offsetY
 
 		return newX, newY
 	}
@@ -380,40 +413,43 @@ func ExtractTIFFDPI(path string) (float64, error) {
 
 	// Read TIFF header to determine byte order
 	header := make([]byte, 8)
-	if _, err := file.Read(header); err != nil {
+	if _, err := io.ReadFull(file, header); err != nil {
 		return 0, err
 	}
-
-	var byteOrder binary.ByteOrder
-	if header[0] == 'I' && header[1] == 'I' {
-		byteOrder = binary.LittleEndian
-	} else if header[0] == 'M' && header[1] == 'M' {
-		byteOrder = binary.BigEndian
-	} else {
-		return 0, fmt.Errorf("not a valid TIFF file")
+	byteOrder, err := tiffByteOrder(header)
+	if err != nil {
+		return 0, err
 	}
 
-	// Get offset to first IFD
-	ifdOffset := byteOrder.Uint32(header[4:8])
+	// DPI of the first IFD (page)
+	return tiffIFDDPI(file, byteOrder, int64(byteOrder.Uint32(header[4:8])))
+}
 
-	// Seek to IFD
-	if _, err := file.Seek(int64(ifdOffset), 0); err != nil {
-		return 0, err
+// tiffByteOrder returns the byte order of a TIFF from its header.
+func tiffByteOrder(header []byte) (binary.ByteOrder, error) {
+	if header[0] == 'I' && header[1] == 'I' {
+		return binary.LittleEndian, nil
+	} else if header[0] == 'M' && header[1] == 'M' {
+		return binary.BigEndian, nil
 	}
+	return nil, fmt.Errorf("not a valid TIFF file")
+}
 
-	// Read number of directory entries
-	var numEntries uint16
-	if err := binary.Read(file, byteOrder, &numEntries); err != nil {
+// tiffIFDDPI reads the resolution tags of the IFD at offset ifd.
+func tiffIFDDPI(r io.ReaderAt, byteOrder binary.ByteOrder, ifd int64) (float64, error) {
+	count := make([]byte, 2)
+	if _, err := r.ReadAt(count, ifd); err != nil {
 		return 0, err
 	}
+	numEntries := byteOrder.Uint16(count)
 
 	var xRes, yRes float64
 	var resUnit uint16 = 2 // Default to inches
 
 	// Read directory entries
+	entry := make([]byte, 12)
 	for i := uint16(0); i < numEntries; i++ {
-		entry := make([]byte, 12)
-		if _, err := file.Read(entry); err != nil {
+		if _, err := r.ReadAt(entry, ifd+2+12*int64(i)); err != nil {
 			return 0, err
 		}
 
@@ -424,15 +460,15 @@ func ExtractTIFFDPI(path string) (float64, error) {
 		switch tag {
 		case 282: // XResolution
 			if fieldType == 5 { // RATIONAL
-				xRes = readTIFFRational(file, int64(valueOffset), byteOrder)
+				xRes = readTIFFRational(r, int64(valueOffset), byteOrder)
 			}
 		case 283: // YResolution
 			if fieldType == 5 { // RATIONAL
-				yRes = readTIFFRational(file, int64(valueOffset), byteOrder)
+				yRes = readTIFFRational(r, int64(valueOffset), byteOrder)
 			}
 		case 296: // ResolutionUnit
-			if fieldType == 3 { // SHORT
-				resUnit = uint16(valueOffset)
+			if fieldType == 3 { // SHORT, left-justified in the value field
+				resUnit = byteOrder.Uint16(entry[8:10])
 			}
 		}
 	}
@@ -460,14 +496,13 @@ func ExtractTIFFDPI(path string) (float64, error) {
 }
 
 // readTIFFRational reads a RATIONAL value (two uint32s) from a TIFF file.
-func readTIFFRational(file *os.File, offset int64, byteOrder binary.ByteOrder) float64 {
-	currentPos, _ := file.Seek(0, 1) // Save current position
-	defer file.Seek(currentPos, 0)   // Restore position
-
-	file.Seek(offset, 0)
-	var num, denom uint32
-	binary.Read(file, byteOrder, &num)
-	binary.Read(file, byteOrder, &denom)
+func readTIFFRational(r io.ReaderAt, offset int64, byteOrder binary.ByteOrder) float64 {
+	buf := make([]byte, 8)
+	if _, err := r.ReadAt(buf, offset); err != nil {
+		return 0
+	}
+	num := byteOrder.Uint32(buf[0:4])
+	denom := byteOrder.Uint32(buf[4:8])
 
 	if denom == 0 {
 		return 0
@@ -475,6 +510,155 @@ func readTIFFRational(file *os.File, offset int64, byteOrder binary.ByteOrder) f
 	return float64(num) / float64(denom)
 }
 
+// ExtractDPI extracts DPI from image metadata, choosing the reader by file
+// extension. Returns an error if the format is unsupported or carries no
+// physical resolution.
+func ExtractDPI(path string) (float64, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tiff", ".tif":
+		return ExtractTIFFDPI(path)
+	case ".png":
+		return ExtractPNGDPI(path)
+	case ".jpg", ".jpeg":
+		return ExtractJPEGDPI(path)
+	}
+	return 0, fmt.Errorf("no DPI metadata support for %s", filepath.Ext(path))
+}
+
+// ExtractPNGDPI attempts to extract DPI from a PNG pHYs chunk.
+func ExtractPNGDPI(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	sig := make([]byte, 8)
+	if _, err := io.ReadFull(file, sig); err != nil {
+		return 0, err
+	}
+	if string(sig) != "\x89PNG\r\n\x1a\n" {
+		return 0, fmt.Errorf("not a valid PNG file")
+	}
+
+	// Walk chunks until pHYs; it must precede the image data
+	hdr := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(file, hdr); err != nil {
+			return 0, err
+		}
+		length := binary.BigEndian.Uint32(hdr[0:4])
+		chunkType := string(hdr[4:8])
+
+		switch chunkType {
+		case "pHYs":
+			if length != 9 {
+				return 0, fmt.Errorf("malformed pHYs chunk")
+			}
+			data := make([]byte, 9)
+			if _, err := io.ReadFull(file, data); err != nil {
+				return 0, err
+			}
+			ppuX := binary.BigEndian.Uint32(data[0:4])
+			ppuY := binary.BigEndian.Uint32(data[4:8])
+			if data[8] != 1 { // 0 = aspect ratio only, 1 = pixels per meter
+				return 0, fmt.Errorf("pHYs has no physical unit")
+			}
+			ppu := ppuX
+			if ppu == 0 {
+				ppu = ppuY
+			}
+			if ppu == 0 {
+				return 0, fmt.Errorf("DPI is zero")
+			}
+			return metricDPI(float64(ppu) / 100), nil
+		case "IDAT", "IEND":
+			return 0, fmt.Errorf("no pHYs chunk found")
+		}
+
+		// Skip chunk data and CRC
+		if _, err := file.Seek(int64(length)+4, io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// metricDPI converts an integral dots-per-cm density to DPI, rounded to a
+// whole DPI. Scanners resolve in whole DPI, and metric densities can't
+// store them exactly: 300 DPI is written as 118 dots/cm (299.72 DPI) or
+// 11811 pixels/m (299.9994 DPI).
+func metricDPI(dotsPerCm float64) float64 {
+	return math.Round(dotsPerCm * 2.54)
+}
+
+// ExtractJPEGDPI attempts to extract DPI from a JPEG JFIF (APP0) header.
+func ExtractJPEGDPI(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(file, soi); err != nil {
+		return 0, err
+	}
+	if soi[0] != 0xFF || soi[1] != 0xD8 {
+		return 0, fmt.Errorf("not a valid JPEG file")
+	}
+
+	// Walk marker segments until APP0/JFIF or start of scan
+	seg := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(file, seg); err != nil {
+			return 0, err
+		}
+		if seg[0] != 0xFF {
+			return 0, fmt.Errorf("invalid JPEG marker")
+		}
+		marker := seg[1]
+		length := int(binary.BigEndian.Uint16(seg[2:4])) - 2 // length includes itself
+		if marker == 0xDA || marker == 0xD9 {                // SOS / EOI
+			return 0, fmt.Errorf("no JFIF density found")
+		}
+		if length < 0 {
+			return 0, fmt.Errorf("invalid JPEG segment length")
+		}
+
+		if marker == 0xE0 && length >= 12 {
+			data := make([]byte, length)
+			if _, err := io.ReadFull(file, data); err != nil {
+				return 0, err
+			}
+			if string(data[0:5]) != "JFIF\x00" {
+				continue
+			}
+			units := data[7]
+			xDensity := binary.BigEndian.Uint16(data[8:10])
+			yDensity := binary.BigEndian.Uint16(data[10:12])
+			dpi := float64(xDensity)
+			if dpi == 0 {
+				dpi = float64(yDensity)
+			}
+			switch units {
+			case 1: // dots per inch
+			case 2: // dots per cm
+				dpi = metricDPI(dpi)
+			default: // 0 = aspect ratio only
+				return 0, fmt.Errorf("JFIF has no physical density unit")
+			}
+			if dpi == 0 {
+				return 0, fmt.Errorf("DPI is zero")
+			}
+			return dpi, nil
+		}
+
+		if _, err := file.Seek(int64(length), io.SeekCurrent); err != nil {
+			return 0, err
+		}
+	}
+}
+
 // SupportedFormats returns the list of supported image formats.
 func SupportedFormats() []string {
 	return []string{".tiff", ".tif", ".png", ".jpg", ".jpeg"}
@@ -0,0 +1,175 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExtractPNGDPI reads pHYs chunks in pixels per meter, which can't hold
+// a whole DPI exactly.
+func TestExtractPNGDPI(t *testing.T) {
+	chunk := func(typ string, data []byte) []byte {
+		c := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+		c = append(append(c, typ...), data...)
+		return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+	}
+	phys := func(ppmX, ppmY uint32, unit byte) []byte {
+		data := binary.BigEndian.AppendUint32(nil, ppmX)
+		data = binary.BigEndian.AppendUint32(data, ppmY)
+		return chunk("pHYs", append(data, unit))
+	}
+	png := func(chunks ...[]byte) []byte {
+		b := []byte("\x89PNG\r\n\x1a\n")
+		b = append(b, chunk("IHDR", make([]byte, 13))...)
+		for _, c := range chunks {
+			b = append(b, c...)
+		}
+		return append(b, chunk("IEND", nil)...)
+	}
+
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want float64 // 0 for an error
+	}{
+		{"300 DPI", png(phys(11811, 11811, 1)), 300},
+		{"600 DPI", png(phys(23622, 23622, 1)), 600},
+		{"after another chunk", png(chunk("tEXt", []byte("a\x00b")), phys(47244, 47244, 1)), 1200},
+		{"Y only", png(phys(0, 11811, 1)), 300},
+		{"aspect ratio only", png(phys(1, 1, 0)), 0},
+		{"zero density", png(phys(0, 0, 1)), 0},
+		{"after image data", png(chunk("IDAT", nil), phys(11811, 11811, 1)), 0},
+		{"no pHYs", png(), 0},
+		{"not a PNG", []byte("GIF89a"), 0},
+	} {
+		path := filepath.Join(dir, "dpi.png")
+		if err := os.WriteFile(path, tc.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		dpi, err := ExtractPNGDPI(path)
+		if tc.want == 0 {
+			if err == nil {
+				t.Errorf("%s: DPI %v, want error", tc.name, dpi)
+			}
+		} else if err != nil || dpi != tc.want {
+			t.Errorf("%s: DPI %v (%v), want %v", tc.name, dpi, err, tc.want)
+		}
+	}
+}
+
+// TestExtractJPEGDPI reads JFIF densities in dots per inch and dots per cm.
+func TestExtractJPEGDPI(t *testing.T) {
+	segment := func(marker byte, data []byte) []byte {
+		s := []byte{0xFF, marker}
+		s = binary.BigEndian.AppendUint16(s, uint16(len(data)+2))
+		return append(s, data...)
+	}
+	jfif := func(units byte, x, y uint16) []byte {
+		data := []byte("JFIF\x00\x01\x02")
+		data = append(data, units)
+		data = binary.BigEndian.AppendUint16(data, x)
+		data = binary.BigEndian.AppendUint16(data, y)
+		return segment(0xE0, append(data, 0, 0))
+	}
+	jpeg := func(segments ...[]byte) []byte {
+		b := []byte{0xFF, 0xD8}
+		for _, s := range segments {
+			b = append(b, s...)
+		}
+		return append(b, segment(0xDA, make([]byte, 10))...)
+	}
+
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		data []byte
+		want float64 // 0 for an error
+	}{
+		{"dots per inch", jpeg(jfif(1, 600, 600)), 600},
+		{"dots per cm", jpeg(jfif(2, 118, 118)), 300}, // 299.72, rounded
+		{"dots per cm, rounded down", jpeg(jfif(2, 189, 189)), 480},
+		{"Y only", jpeg(jfif(1, 0, 300)), 300},
+		{"after a non-JFIF APP0", jpeg(segment(0xE0, bytes.Repeat([]byte{'x'}, 14)), jfif(1, 300, 300)), 300},
+		{"after quantization tables", jpeg(segment(0xDB, make([]byte, 65)), jfif(1, 1200, 1200)), 1200},
+		{"aspect ratio only", jpeg(jfif(0, 1, 1)), 0},
+		{"zero density", jpeg(jfif(1, 0, 0)), 0},
+		{"no JFIF", jpeg(segment(0xDB, make([]byte, 65))), 0},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 0},
+	} {
+		path := filepath.Join(dir, "dpi.jpg")
+		if err := os.WriteFile(path, tc.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		dpi, err := ExtractJPEGDPI(path)
+		if tc.want == 0 {
+			if err == nil {
+				t.Errorf("%s: DPI %v, want error", tc.name, dpi)
+			}
+		} else if err != nil || dpi != tc.want {
+			t.Errorf("%s: DPI %v (%v), want %v", tc.name, dpi, err, tc.want)
+		}
+	}
+}
+
+func TestShearFactor(t *testing.T) {
+	for _, c := range []struct {
+		in, want float64
+	}{
+		{0, 1},
+		{-0.5, 1},
+		{math.NaN(), 1},
+		{math.Inf(1), 1},
+		{1, 1},
+		{0.98, 0.98},
+		{1.015, 1.015},
+	} {
+		if got := ShearFactor(c.in); got != c.want {
+			t.Errorf("ShearFactor(%v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+// TestEnsureShearDefaults checks that an unset (zero) shear renders as
+// identity instead of collapsing the image, and that real shear factors are
+// kept as they are.
+func TestEnsureShearDefaults(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(x * 6), G: uint8(y * 8), B: 50, A: 255})
+		}
+	}
+
+	zero := &Layer{Image: src}
+	zero.EnsureShearDefaults()
+	if zero.ShearTopX != 1 || zero.ShearBottomX != 1 || zero.ShearLeftY != 1 || zero.ShearRightY != 1 {
+		t.Errorf("zero shears became %v %v %v %v, want identity",
+			zero.ShearTopX, zero.ShearBottomX, zero.ShearLeftY, zero.ShearRightY)
+	}
+	out, _ := (&Layer{Image: src}).Normalize(ResampleOptions{})
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			if out.RGBAAt(x, y) != src.RGBAAt(x, y) {
+				t.Fatalf("zero shears: pixel (%d,%d) = %v, want %v unchanged", x, y, out.RGBAAt(x, y), src.RGBAAt(x, y))
+			}
+		}
+	}
+
+	sheared := &Layer{Image: src, ShearTopX: 0.98, ShearBottomX: 1.02, ShearLeftY: 1, ShearRightY: 0.99}
+	sheared.EnsureShearDefaults()
+	if sheared.ShearTopX != 0.98 || sheared.ShearBottomX != 1.02 || sheared.ShearLeftY != 1 || sheared.ShearRightY != 0.99 {
+		t.Errorf("shears became %v %v %v %v, want 0.98 1.02 1 0.99 kept",
+			sheared.ShearTopX, sheared.ShearBottomX, sheared.ShearLeftY, sheared.ShearRightY)
+	}
+	if out, _ := sheared.Normalize(ResampleOptions{}); out.RGBAAt(20, 15) != src.RGBAAt(20, 15) {
+		t.Errorf("sheared: center pixel %v, want %v", out.RGBAAt(20, 15), src.RGBAAt(20, 15))
+	}
+}
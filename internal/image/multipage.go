@@ -0,0 +1,119 @@
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/image/tiff"
+)
+
+// maxTIFFPages bounds the IFD chain walk so a corrupt or cyclic chain
+// cannot loop forever.
+const maxTIFFPages = 64
+
+// LoadMultiPage loads every page of a multi-page TIFF as its own Layer, in
+// file order, each with the DPI of its own resolution tags. Layer.Page is
+// the page index; sides are left for the caller to assign. Non-TIFF files
+// load as a single page.
+func LoadMultiPage(path string) ([]*Layer, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tiff", ".tif":
+	default:
+		layer, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		return []*Layer{layer}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image: %w", err)
+	}
+	offsets, err := tiffPageOffsets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	byteOrder, _ := tiffByteOrder(data)
+	layers := make([]*Layer, 0, len(offsets))
+	for page, off := range offsets {
+		// The decoder reads only the first IFD, so point the header at
+		// this page's IFD; all other offsets in the file are absolute.
+		pageData := data
+		if page > 0 {
+			pageData = slices.Clone(data)
+			byteOrder.PutUint32(pageData[4:8], off)
+		}
+		img, err := tiff.Decode(bytes.NewReader(pageData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode page %d: %w", page, err)
+		}
+
+		layer := NewLayer()
+		layer.Path = path
+		layer.Page = page
+		layer.Image = img
+		if dpi, err := tiffIFDDPI(bytes.NewReader(data), byteOrder, int64(off)); err == nil {
+			layer.DPI = dpi
+		} else {
+			layer.DPIAssumed = true
+			fmt.Printf("LoadMultiPage: no DPI metadata on page %d of %s: %v\n", page, filepath.Base(path), err)
+		}
+		layers = append(layers, layer)
+	}
+	return layers, nil
+}
+
+// LoadPage loads one page of an image file. Page 0 is the whole file for
+// single-page formats.
+func LoadPage(path string, page int) (*Layer, error) {
+	if page == 0 {
+		return Load(path)
+	}
+	layers, err := LoadMultiPage(path)
+	if err != nil {
+		return nil, err
+	}
+	if page >= len(layers) {
+		return nil, fmt.Errorf("%s has %d page(s), no page %d", filepath.Base(path), len(layers), page)
+	}
+	return layers[page], nil
+}
+
+// tiffPageOffsets walks the IFD chain of a TIFF and returns the offset of
+// each page's IFD.
+func tiffPageOffsets(data []byte) ([]uint32, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("not a valid TIFF file")
+	}
+	byteOrder, err := tiffByteOrder(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var offsets []uint32
+	for off := byteOrder.Uint32(data[4:8]); off != 0; {
+		if len(offsets) == maxTIFFPages || slices.Contains(offsets, off) {
+			return nil, fmt.Errorf("TIFF IFD chain too long or cyclic")
+		}
+		if int(off)+2 > len(data) {
+			return nil, fmt.Errorf("TIFF IFD offset %d out of range", off)
+		}
+		n := int(byteOrder.Uint16(data[off : off+2]))
+		next := int(off) + 2 + 12*n
+		if next+4 > len(data) {
+			return nil, fmt.Errorf("TIFF IFD at %d truncated", off)
+		}
+		offsets = append(offsets, off)
+		off = byteOrder.Uint32(data[next : next+4])
+	}
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("TIFF has no pages")
+	}
+	return offsets, nil
+}
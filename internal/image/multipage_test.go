@@ -0,0 +1,95 @@
+package image
+
+import (
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// grayTIFF builds a little-endian, uncompressed 8-bit grayscale TIFF with
+// one IFD per page, each page filled with its value and tagged with its DPI.
+func grayTIFF(w, h int, values []byte, dpis []uint32) []byte {
+	le := binary.LittleEndian
+	data := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	next := 4 // Where to write the next IFD offset
+	for i, v := range values {
+		pix := len(data)
+		for j := 0; j < w*h; j++ {
+			data = append(data, v)
+		}
+		res := len(data)
+		data = le.AppendUint32(data, dpis[i])
+		data = le.AppendUint32(data, 1)
+
+		ifd := len(data)
+		le.PutUint32(data[next:], uint32(ifd))
+		entries := [][3]uint32{ // Tag, type, value
+			{256, 4, uint32(w)}, {257, 4, uint32(h)}, {258, 3, 8}, {259, 3, 1}, {262, 3, 1},
+			{273, 4, uint32(pix)}, {277, 3, 1}, {278, 4, uint32(h)}, {279, 4, uint32(w * h)},
+			{282, 5, uint32(res)}, {283, 5, uint32(res)}, {296, 3, 2},
+		}
+		data = le.AppendUint16(data, uint16(len(entries)))
+		for _, e := range entries {
+			data = le.AppendUint16(data, uint16(e[0]))
+			data = le.AppendUint16(data, uint16(e[1]))
+			data = le.AppendUint32(data, 1)
+			if e[1] == 3 {
+				data = le.AppendUint16(data, uint16(e[2]))
+				data = append(data, 0, 0)
+			} else {
+				data = le.AppendUint32(data, e[2])
+			}
+		}
+		next = len(data)
+		data = le.AppendUint32(data, 0)
+	}
+	return data
+}
+
+// TestLoadMultiPage loads front and back pages from one TIFF and checks
+// each keeps its own pixels and DPI, and that a single page loads alone.
+func TestLoadMultiPage(t *testing.T) {
+	dir := t.TempDir()
+	two := filepath.Join(dir, "both.tif")
+	if err := os.WriteFile(two, grayTIFF(8, 4, []byte{40, 200}, []uint32{600, 1200}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	one := filepath.Join(dir, "front.tif")
+	if err := os.WriteFile(one, grayTIFF(8, 4, []byte{40}, []uint32{600}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	layers, err := LoadMultiPage(two)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 2 {
+		t.Fatalf("got %d pages, want 2", len(layers))
+	}
+	for i, want := range []struct {
+		value byte
+		dpi   float64
+	}{{40, 600}, {200, 1200}} {
+		l := layers[i]
+		if l.Page != i || l.DPI != want.dpi || l.DPIAssumed {
+			t.Errorf("page %d: Page %d DPI %v assumed %v, want DPI %v", i, l.Page, l.DPI, l.DPIAssumed, want.dpi)
+		}
+		if g, ok := l.Image.(*image.Gray); !ok || l.Image.Bounds().Dx() != 8 || g.GrayAt(3, 2).Y != want.value {
+			t.Errorf("page %d: decoded %T %v, want 8x4 gray of %d", i, l.Image, l.Image.Bounds(), want.value)
+		}
+	}
+
+	if back, err := LoadPage(two, 1); err != nil {
+		t.Errorf("LoadPage(1): %v", err)
+	} else if back.DPI != 1200 {
+		t.Errorf("LoadPage(1): DPI %v, want 1200", back.DPI)
+	}
+	if _, err := LoadPage(one, 1); err == nil {
+		t.Error("LoadPage(1) of a single-page TIFF succeeded")
+	}
+	if layers, err := LoadMultiPage(one); err != nil || len(layers) != 1 {
+		t.Errorf("single page: %d layers (%v), want 1", len(layers), err)
+	}
+}
@@ -0,0 +1,132 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+	"sync"
+)
+
+// minPyramidSize is the largest dimension below which no further pyramid
+// levels are built; images this small draw quickly at full resolution.
+const minPyramidSize = 1024
+
+// Pyramid is a mipmap of a layer image for display. Level 0 is the image
+// itself and each further level halves it with a 2×2 box filter. Every
+// level keeps the image's bounds origin, so full-resolution coordinates map
+// to level k as Min + (p-Min)>>k. Only rendering should use the downscaled
+// levels; detection and OCR work on the full-resolution image.
+type Pyramid struct {
+	levels []image.Image
+}
+
+// NewPyramid builds the pyramid of img, halving until the largest dimension
+// is below minPyramidSize.
+func NewPyramid(img image.Image) *Pyramid {
+	p := &Pyramid{levels: []image.Image{img}}
+	for {
+		b := p.levels[len(p.levels)-1].Bounds()
+		if max(b.Dx(), b.Dy()) < 2*minPyramidSize {
+			return p
+		}
+		p.levels = append(p.levels, halve(p.levels[len(p.levels)-1]))
+	}
+}
+
+// Levels returns the number of levels, including the full-resolution one.
+func (p *Pyramid) Levels() int {
+	return len(p.levels)
+}
+
+// Level returns the index of the coarsest level that still has at least
+// one pixel per screen pixel at zoom (screen pixels per full-resolution
+// pixel). Zooms above 0.5 use the full-resolution level 0.
+func (p *Pyramid) Level(zoom float64) int {
+	k := 0
+	for k+1 < len(p.levels) && zoom*float64(int(1)<<(k+1)) <= 1 {
+		k++
+	}
+	return k
+}
+
+// At returns the pixel of level k covering full-resolution position (x, y).
+func (p *Pyramid) At(k, x, y int) color.Color {
+	img := p.levels[k]
+	if k == 0 {
+		return img.At(x, y)
+	}
+	o := img.Bounds().Min
+	return img.At(o.X+(x-o.X)>>k, o.Y+(y-o.Y)>>k)
+}
+
+// halve downscales src by 2 with a 2×2 box filter, duplicating the last row
+// and column of odd-sized images.
+func halve(src image.Image) *image.RGBA {
+	sb := src.Bounds()
+	w, h := (sb.Dx()+1)/2, (sb.Dy()+1)/2
+	dst := image.NewRGBA(image.Rect(sb.Min.X, sb.Min.Y, sb.Min.X+w, sb.Min.Y+h))
+	if w == 0 || h == 0 {
+		return dst
+	}
+	rgba, _ := src.(*image.RGBA)
+
+	numWorkers := runtime.NumCPU()
+	rowsPerWorker := (h + numWorkers - 1) / numWorkers
+
+	var wg sync.WaitGroup
+	for startY := 0; startY < h; startY += rowsPerWorker {
+		endY := min(startY+rowsPerWorker, h)
+		wg.Add(1)
+		go func(yStart, yEnd int) {
+			defer wg.Done()
+			var sum [4]uint32
+			for y := yStart; y < yEnd; y++ {
+				sy0 := sb.Min.Y + 2*y
+				sy1 := min(sy0+1, sb.Max.Y-1)
+				row := dst.Pix[y*dst.Stride:]
+				for x := 0; x < w; x++ {
+					sx0 := sb.Min.X + 2*x
+					sx1 := min(sx0+1, sb.Max.X-1)
+					sum = [4]uint32{}
+					for _, sp := range [4]image.Point{{sx0, sy0}, {sx1, sy0}, {sx0, sy1}, {sx1, sy1}} {
+						if rgba != nil {
+							px := rgba.Pix[rgba.PixOffset(sp.X, sp.Y):]
+							for c := range sum {
+								sum[c] += uint32(px[c])
+							}
+							continue
+						}
+						r, g, b, a := src.At(sp.X, sp.Y).RGBA()
+						sum[0] += r >> 8
+						sum[1] += g >> 8
+						sum[2] += b >> 8
+						sum[3] += a >> 8
+					}
+					for c := range sum {
+						row[4*x+c] = uint8((sum[c] + 2) / 4)
+					}
+				}
+			}
+		}(startY, endY)
+	}
+	wg.Wait()
+
+	return dst
+}
+
+// Pyramid returns the display pyramid of the layer image, building it on
+// first use and again whenever Layer.Image is replaced. Returns nil if the
+// layer has no image.
+func (l *Layer) Pyramid() *Pyramid {
+	l.pyramidMu.Lock()
+	defer l.pyramidMu.Unlock()
+
+	if l.Image == nil {
+		l.pyramid = nil
+		return nil
+	}
+	if l.pyramid == nil || !sameImage(l.pyramid.levels[0], l.Image) {
+		l.pyramid = NewPyramid(l.Image)
+	}
+	return l.pyramid
+}
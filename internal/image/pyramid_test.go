@@ -0,0 +1,43 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// TestPyramid checks level selection by zoom, 2×2 box averaging, and that
+// levels are addressed in full-resolution coordinates.
+func TestPyramid(t *testing.T) {
+	const w, h = 4*minPyramidSize + 2, 2 * minPyramidSize
+	src := image.NewRGBA(image.Rect(10, 20, 10+w, 20+h))
+	for y := src.Rect.Min.Y; y < src.Rect.Max.Y; y++ {
+		for x := src.Rect.Min.X; x < src.Rect.Max.X; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: uint8(40 * (x % 2)), G: uint8(80 * (y % 2)), B: 7, A: 255})
+		}
+	}
+
+	p := NewPyramid(src)
+	if p.Levels() != 3 {
+		t.Fatalf("got %d levels, want 3", p.Levels())
+	}
+	for _, tc := range []struct {
+		zoom float64
+		want int
+	}{{2, 0}, {1, 0}, {0.6, 0}, {0.5, 1}, {0.3, 1}, {0.25, 2}, {0.01, 2}} {
+		if got := p.Level(tc.zoom); got != tc.want {
+			t.Errorf("Level(%v) = %d, want %d", tc.zoom, got, tc.want)
+		}
+	}
+
+	if got := p.At(0, 11, 21); got != src.At(11, 21) {
+		t.Errorf("level 0 At = %v, want %v", got, src.At(11, 21))
+	}
+	want := color.RGBA{R: 20, G: 40, B: 7, A: 255}
+	if got := p.At(1, 10+501, 20+33); got != want {
+		t.Errorf("level 1 At = %v, want %v", got, want)
+	}
+	if got := p.At(2, 10+w-1, 20+h-1); got != want {
+		t.Errorf("level 2 At = %v, want %v", got, want)
+	}
+}
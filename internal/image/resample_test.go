@@ -0,0 +1,50 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// TestRotateAboutKnownPoint rotates an image with one marked pixel and
+// checks the mark lands where geometry.RotateAbout puts it, then rotates
+// back and checks it returns to the original pixel.
+func TestRotateAboutKnownPoint(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 200, 150))
+	mark := image.Pt(140, 40)
+	src.SetRGBA(mark.X, mark.Y, color.RGBA{R: 255, A: 255})
+
+	center := geometry.Point2D{X: 73.5, Y: 91}
+	for _, deg := range []float64{0.3, -1.7, 12, 30} {
+		for _, opts := range []ResampleOptions{{}, {Bilinear: true}} {
+			rotated := RotateAbout(src, center, deg, opts)
+			want := geometry.RotateAbout(geometry.Point2D{X: float64(mark.X), Y: float64(mark.Y)}, center, deg)
+			got := brightest(rotated)
+			if wx, wy := NearestPixel(want.X, want.Y); got != image.Pt(wx, wy) {
+				t.Errorf("%v° bilinear=%v: mark at %v, want (%d,%d)", deg, opts.Bilinear, got, wx, wy)
+			}
+
+			back := RotateAbout(rotated, center, -deg, ResampleOptions{})
+			if got := brightest(back); got != mark {
+				t.Errorf("%v° bilinear=%v: round trip mark at %v, want %v", deg, opts.Bilinear, got, mark)
+			}
+		}
+	}
+}
+
+// brightest returns the pixel with the highest red value.
+func brightest(img *image.RGBA) image.Point {
+	var best image.Point
+	var bestR uint8
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r := img.RGBAAt(x, y).R; r > bestR {
+				best, bestR = image.Pt(x, y), r
+			}
+		}
+	}
+	return best
+}
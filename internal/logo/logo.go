@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,13 +19,14 @@ import (
 // Logo represents a detected or defined logo template.
 // Logos are stored as quantized black/white bitmaps for matching.
 type Logo struct {
-	Name           string           `json:"name"`            // Template name, e.g., "ST", "NS", "TI"
-	ManufacturerID string           `json:"manufacturer_id"` // Manufacturer code for OCR output
-	Bounds         geometry.RectInt `json:"bounds"`          // Location in source image
-	Width          int              `json:"width"`           // Quantized bitmap width
-	Height         int              `json:"height"`          // Quantized bitmap height
-	QuantizedSize  int              `json:"quantized_size"`  // Target quantization size (max dimension)
-	Bits           []byte           `json:"bits"`            // Packed bitmap (1 bit per pixel, row-major)
+	Name           string           `json:"name"`                // Template name, e.g., "ST", "NS", "TI"
+	ManufacturerID string           `json:"manufacturer_id"`     // Manufacturer code for OCR output
+	Bounds         geometry.RectInt `json:"bounds"`              // Location in source image
+	Width          int              `json:"width"`               // Quantized bitmap width
+	Height         int              `json:"height"`              // Quantized bitmap height
+	QuantizedSize  int              `json:"quantized_size"`      // Target quantization size (max dimension)
+	Bits           []byte           `json:"bits"`                // Packed bitmap (1 bit per pixel, row-major)
+	MinScore       float64          `json:"min_score,omitempty"` // Per-logo detection threshold, 0 = caller's
 
 	// Source tracking
 	SourceComponent string `json:"source_component,omitempty"` // Component ID this was extracted from
@@ -962,11 +964,95 @@ func matchBits(a, b []byte, width, height int) float64 {
 	return float64(intersection) / float64(union)
 }
 
+// DetectOptions bounds the scale and rotation sweep of DetectLogosWithOptions.
+type DetectOptions struct {
+	// MinScale and MaxScale bound the logo size searched for, relative to
+	// the size DetectLogos matches: 0.5 finds logos half as large, 2 finds
+	// logos twice as large. Non-positive values mean 1.
+	MinScale float64
+	MaxScale float64
+	// ScaleSteps is the number of scales tried, spaced geometrically from
+	// MinScale to MaxScale. Below 2, only their geometric midpoint is tried.
+	ScaleSteps float64
+	// RotationTolerance is how far in degrees the logo may be rotated from
+	// the given rotation. Matching works in quarter turns, so 90 also tries
+	// the neighboring quarter turns and 180 tries all four.
+	RotationTolerance int
+	// MinConfidence is the lowest match score (0-1) reported.
+	MinConfidence float64
+}
+
+// DefaultDetectOptions returns the options DetectLogos uses: a single scale,
+// the given rotation only, and a minimum score of 0.75.
+func DefaultDetectOptions() DetectOptions {
+	return DetectOptions{
+		MinScale:      1,
+		MaxScale:      1,
+		ScaleSteps:    1,
+		MinConfidence: 0.75,
+	}
+}
+
+// scales returns the logo scales to try, smallest first.
+func (o DetectOptions) scales() []float64 {
+	lo, hi := o.MinScale, o.MaxScale
+	if lo <= 0 {
+		lo = 1
+	}
+	if hi <= 0 {
+		hi = 1
+	}
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	steps := int(math.Round(o.ScaleSteps))
+	if steps < 2 || lo == hi {
+		return []float64{math.Sqrt(lo * hi)}
+	}
+	scales := make([]float64, steps)
+	ratio := math.Pow(hi/lo, 1/float64(steps-1))
+	for i := range scales {
+		scales[i] = lo * math.Pow(ratio, float64(i))
+	}
+	return scales
+}
+
+// rotations returns the quarter-turn rotations within RotationTolerance of
+// rotation, starting with rotation itself.
+func (o DetectOptions) rotations(rotation int) []int {
+	rotation = ((rotation % 360) + 360) % 360
+	rotations := []int{rotation}
+	if o.RotationTolerance >= 90 {
+		rotations = append(rotations, (rotation+90)%360, (rotation+270)%360)
+	}
+	if o.RotationTolerance >= 180 {
+		rotations = append(rotations, (rotation+180)%360)
+	}
+	return rotations
+}
+
 // DetectLogos searches for logo templates in an image using fast pre-quantized matching.
 // Uses a Boyer-Moore inspired approach: quantize entire search area once, then scan.
 // rotation specifies the image rotation in degrees (0, 90, 180, 270) based on orientation.
-// Returns all matches with score >= minScore, sorted by score descending.
+// Returns the best match per template with score >= minScore (or the template's
+// own MinScore, if set), sorted by score descending.
 func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectInt, minScore float64, rotation int) []LogoMatch {
+	opts := DefaultDetectOptions()
+	opts.MinConfidence = minScore
+	return lib.DetectLogosWithOptions(img, searchBounds, rotation, opts)
+}
+
+// DetectLogosWithOptions is DetectLogos with a configurable sweep: the search
+// area is quantized once per scale and rotation in opts, and each template
+// keeps its best match across all of them. A template with its own MinScore
+// uses that instead of opts.MinConfidence.
+func (lib *LogoLibrary) DetectLogosWithOptions(img image.Image, searchBounds geometry.RectInt, rotation int, opts DetectOptions) []LogoMatch {
+	return lib.detect(img, searchBounds, rotation, opts, true)
+}
+
+// detect implements DetectLogosWithOptions. Without perLogo, every template
+// uses opts.MinConfidence regardless of its MinScore.
+func (lib *LogoLibrary) detect(img image.Image, searchBounds geometry.RectInt, rotation int, opts DetectOptions, perLogo bool) []LogoMatch {
 	if len(lib.Logos) == 0 {
 		return nil
 	}
@@ -981,9 +1067,47 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 		}
 	}
 
-	fmt.Printf("[Logo] DetectLogos: %d templates, search area %dx%d, rotation %d\n",
-		len(lib.Logos), searchBounds.Width, searchBounds.Height, rotation)
+	scales := opts.scales()
+	rotations := opts.rotations(rotation)
+	fmt.Printf("[Logo] DetectLogos: %d templates, search area %dx%d, rotations %v, scales %.2f-%.2f (%d)\n",
+		len(lib.Logos), searchBounds.Width, searchBounds.Height, rotations,
+		scales[0], scales[len(scales)-1], len(scales))
+
+	var matches []LogoMatch
+	for _, rot := range rotations {
+		for _, scale := range scales {
+			matches = append(matches, lib.detectPass(img, searchBounds, rot, scale, opts.MinConfidence, perLogo)...)
+		}
+	}
+	fmt.Printf("[Logo] All templates done, %d total matches\n", len(matches))
 
+	// Keep only the single best match per template name.
+	// The shift-invariant matching in matchBits produces near-identical
+	// scores at many positions; only the highest-scoring hit matters.
+	bestByName := make(map[string]LogoMatch)
+	for _, m := range matches {
+		if existing, ok := bestByName[m.Logo.Name]; !ok || m.Score > existing.Score {
+			bestByName[m.Logo.Name] = m
+		}
+	}
+
+	filtered := make([]LogoMatch, 0, len(bestByName))
+	for _, m := range bestByName {
+		filtered = append(filtered, m)
+	}
+
+	// Sort by score descending
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Score > filtered[j].Score
+	})
+
+	return filtered
+}
+
+// detectPass scans all templates over the search area quantized at one
+// rotation and logo scale. A scale below 1 quantizes the area more finely,
+// so a fixed-size template covers a smaller part of it.
+func (lib *LogoLibrary) detectPass(img image.Image, searchBounds geometry.RectInt, rotation int, scale, minScore float64, perLogo bool) []LogoMatch {
 	// Determine the common quantized size (use first template's size)
 	qSize := 64
 	if len(lib.Logos) > 0 && lib.Logos[0].QuantizedSize > 0 {
@@ -995,15 +1119,15 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 	aspect := float64(searchBounds.Width) / float64(searchBounds.Height)
 	var qWidth, qHeight int
 	if aspect >= 1.0 {
-		qWidth = int(float64(qSize) * aspect * 2) // 2x oversampling for sub-pixel accuracy
-		qHeight = qSize * 2
+		qWidth = int(float64(qSize) * aspect * 2 / scale) // 2x oversampling for sub-pixel accuracy
+		qHeight = int(float64(qSize) * 2 / scale)
 	} else {
-		qWidth = qSize * 2
-		qHeight = int(float64(qSize) / aspect * 2)
+		qWidth = int(float64(qSize) * 2 / scale)
+		qHeight = int(float64(qSize) / aspect * 2 / scale)
 	}
 
 	// Pre-quantize the entire search area (with rotation applied)
-	fmt.Printf("[Logo] Pre-quantizing search area to %dx%d (rotation %d)...\n", qWidth, qHeight, rotation)
+	fmt.Printf("[Logo] Pre-quantizing search area to %dx%d (rotation %d, scale %.2f)...\n", qWidth, qHeight, rotation, scale)
 	qi := newQuantizedImage(img, searchBounds, qWidth, qHeight, rotation)
 	fmt.Printf("[Logo] Pre-quantization complete\n")
 
@@ -1023,6 +1147,10 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 		go func(tmpl *Logo) {
 			defer wg.Done()
 			var templateMatches []LogoMatch
+			threshold := minScore
+			if perLogo {
+				threshold = tmpl.Threshold(minScore)
+			}
 
 			// Template dimensions in quantized image coordinates
 			tmplW := tmpl.Width
@@ -1057,7 +1185,7 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 
 					score := matchBits(tmpl.Bits, windowBits, tmplW, tmplH)
 
-					if score >= minScore {
+					if score >= threshold {
 						// Convert quantized position back to source coordinates
 						scaleYToSource := float64(searchBounds.Height) / float64(qHeight)
 						srcX := int(float64(x)*scaleToSource) + searchBounds.X
@@ -1066,8 +1194,8 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 						// Use template's original pixel dimensions for mask size.
 						// The quantized-to-source conversion inflates bounds when
 						// the search area is much larger than the template.
-						srcW := tmpl.Bounds.Width
-						srcH := tmpl.Bounds.Height
+						srcW := max(1, int(float64(tmpl.Bounds.Width)*scale+0.5))
+						srcH := max(1, int(float64(tmpl.Bounds.Height)*scale+0.5))
 						if rotation == 90 || rotation == 270 {
 							srcW, srcH = srcH, srcW
 						}
@@ -1105,34 +1233,7 @@ func (lib *LogoLibrary) DetectLogos(img image.Image, searchBounds geometry.RectI
 	for templateMatches := range matchChan {
 		matches = append(matches, templateMatches...)
 	}
-	fmt.Printf("[Logo] All templates done, %d total matches\n", len(matches))
-
-	// Sort by score descending
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].Score > matches[j].Score
-	})
-
-	// Keep only the single best match per template name.
-	// The shift-invariant matching in matchBits produces near-identical
-	// scores at many positions; only the highest-scoring hit matters.
-	bestByName := make(map[string]LogoMatch)
-	for _, m := range matches {
-		if existing, ok := bestByName[m.Logo.Name]; !ok || m.Score > existing.Score {
-			bestByName[m.Logo.Name] = m
-		}
-	}
-
-	filtered := make([]LogoMatch, 0, len(bestByName))
-	for _, m := range bestByName {
-		filtered = append(filtered, m)
-	}
-
-	// Sort by score descending
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].Score > filtered[j].Score
-	})
-
-	return filtered
+	return matches
 }
 
 // extractRotatedRegion extracts a region from an image with optional rotation.
@@ -0,0 +1,58 @@
+package logo
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestDetectOptionsScales(t *testing.T) {
+	cases := []struct {
+		opts DetectOptions
+		want []float64
+	}{
+		{DefaultDetectOptions(), []float64{1}},
+		{DetectOptions{}, []float64{1}},
+		{DetectOptions{MinScale: 0.5, MaxScale: 2, ScaleSteps: 3}, []float64{0.5, 1, 2}},
+		{DetectOptions{MinScale: 0.5, MaxScale: 2, ScaleSteps: 5}, []float64{0.5, 1 / math.Sqrt2, 1, math.Sqrt2, 2}},
+		{DetectOptions{MinScale: 2, MaxScale: 0.5, ScaleSteps: 3}, []float64{0.5, 1, 2}}, // Swapped bounds
+		{DetectOptions{MinScale: 0.5, MaxScale: 2, ScaleSteps: 1}, []float64{1}},         // Geometric midpoint
+		{DetectOptions{MinScale: 0.25, MaxScale: 1, ScaleSteps: 0}, []float64{0.5}},
+		{DetectOptions{MinScale: 1.5, MaxScale: 1.5, ScaleSteps: 4}, []float64{1.5}},
+		{DetectOptions{MaxScale: 4, ScaleSteps: 2.6}, []float64{1, 2, 4}}, // Steps round, open MinScale is 1
+	}
+	for _, c := range cases {
+		got := c.opts.scales()
+		if len(got) != len(c.want) {
+			t.Errorf("%+v: scales %v, want %v", c.opts, got, c.want)
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-c.want[i]) > 1e-9 {
+				t.Errorf("%+v: scales %v, want %v", c.opts, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDetectOptionsRotations(t *testing.T) {
+	cases := []struct {
+		tolerance, rotation int
+		want                []int
+	}{
+		{0, 0, []int{0}},
+		{45, 90, []int{90}},
+		{90, 0, []int{0, 90, 270}},
+		{90, 270, []int{270, 0, 180}},
+		{180, 90, []int{90, 180, 0, 270}},
+		{0, -90, []int{270}},
+		{90, 450, []int{90, 180, 0}},
+	}
+	for _, c := range cases {
+		got := DetectOptions{RotationTolerance: c.tolerance}.rotations(c.rotation)
+		if fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("tolerance %d, rotation %d: rotations %v, want %v", c.tolerance, c.rotation, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package logo
+
+import (
+	"image"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// DefaultMaxMaskFraction is the default limit on the total fraction of a
+// component's area that logo masking may erase.
+const DefaultMaxMaskFraction = 0.35
+
+// maxSingleMaskFraction is the largest fraction of a component a single
+// logo match may cover; larger matches are almost always false positives.
+const maxSingleMaskFraction = 0.25
+
+// textOverlap is the overlap with the densest text region, as a fraction
+// of the smaller of the two, above which a match is not masked.
+const textOverlap = 0.5
+
+// MaskDecision says whether a logo match may be masked out before OCR, or
+// why not.
+type MaskDecision int
+
+const (
+	MaskOK           MaskDecision = iota // Mask the match
+	MaskTooLarge                         // Covers too much of the component alone
+	MaskOverlapsText                     // Sits on the densest text region
+	MaskOverBudget                       // Would push total coverage past the limit
+)
+
+// String returns a short description of the decision for logs.
+func (d MaskDecision) String() string {
+	switch d {
+	case MaskOK:
+		return "mask"
+	case MaskTooLarge:
+		return "too large"
+	case MaskOverlapsText:
+		return "overlaps text"
+	case MaskOverBudget:
+		return "over mask budget"
+	}
+	return "unknown"
+}
+
+// PlanMasks decides which of matches (best first, as DetectLogos returns
+// them) may be masked in img without erasing markings. A match is refused
+// if it alone covers more than a quarter of img, if it overlaps the densest
+// text region, or if masking it would bring the total masked area above
+// maxFraction of img. maxFraction <= 0 means DefaultMaxMaskFraction.
+func PlanMasks(img image.Image, matches []LogoMatch, maxFraction float64) []MaskDecision {
+	if maxFraction <= 0 {
+		maxFraction = DefaultMaxMaskFraction
+	}
+	b := img.Bounds()
+	area := float64(b.Dx() * b.Dy())
+
+	text := DensestTextRegion(img)
+
+	decisions := make([]MaskDecision, len(matches))
+	covered := 0.0
+	for i, m := range matches {
+		mArea := float64(m.Bounds.Width * m.Bounds.Height)
+		switch {
+		case mArea > area*maxSingleMaskFraction:
+			decisions[i] = MaskTooLarge
+		case text.Width > 0 && boundsOverlap(m.Bounds, text, textOverlap):
+			decisions[i] = MaskOverlapsText
+		case covered+mArea > area*maxFraction:
+			decisions[i] = MaskOverBudget
+		default:
+			covered += mArea
+		}
+	}
+	return decisions
+}
+
+// DensestTextRegion returns the bounds of the text line in img with the
+// most light/dark transitions, assuming text runs horizontally. The line's
+// extent is the busiest run of active columns, bridging gaps up to the line
+// height so words join but a separate mark does not. Returns an empty
+// rectangle if img has no transitions.
+func DensestTextRegion(img image.Image) geometry.RectInt {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 2 || h < 1 {
+		return geometry.RectInt{}
+	}
+
+	gray := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gray[y*w+x] = colorToGray(img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	threshold := calculateOtsuThresholdFromGray(gray, w, h)
+
+	// transition reports whether the pixel at i differs in binarized value
+	// from its left neighbor.
+	transition := func(i int) bool {
+		return (gray[i] > threshold) != (gray[i-1] > threshold)
+	}
+
+	rows := make([]int, h)
+	peak := 0
+	for y := 0; y < h; y++ {
+		for x := 1; x < w; x++ {
+			if transition(y*w + x) {
+				rows[y]++
+			}
+		}
+		if rows[y] > rows[peak] {
+			peak = y
+		}
+	}
+	if rows[peak] == 0 {
+		return geometry.RectInt{}
+	}
+
+	// Grow the line around the peak row while rows stay busy
+	y0, y1 := peak, peak+1
+	for y0 > 0 && rows[y0-1]*3 >= rows[peak] {
+		y0--
+	}
+	for y1 < h && rows[y1]*3 >= rows[peak] {
+		y1++
+	}
+	lineHeight := y1 - y0
+
+	cols := make([]int, w)
+	for y := y0; y < y1; y++ {
+		for x := 1; x < w; x++ {
+			if transition(y*w + x) {
+				cols[x]++
+			}
+		}
+	}
+
+	// Pick the run of active columns with the most transitions
+	bestX0, bestX1, bestSum := 0, 0, 0
+	runX0, runX1, runSum := -1, -1, 0
+	for x := 0; x < w; x++ {
+		if cols[x] == 0 {
+			continue
+		}
+		if runX0 < 0 || x-runX1 > lineHeight {
+			runX0, runSum = x, 0
+		}
+		runX1 = x + 1
+		runSum += cols[x]
+		if runSum > bestSum {
+			bestX0, bestX1, bestSum = runX0, runX1, runSum
+		}
+	}
+
+	return geometry.RectInt{
+		X:      b.Min.X + bestX0,
+		Y:      b.Min.Y + y0,
+		Width:  bestX1 - bestX0,
+		Height: lineHeight,
+	}
+}
@@ -0,0 +1,105 @@
+package logo
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// markedChip returns a 200×100 dark chip with a line of text, drawn as 30
+// light 2-pixel bars on rows 60-74 from x 40 to 158, a solid logo at the
+// top left and a small separate mark at the end of the text line.
+func markedChip() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 200, 100))
+	for i := range img.Pix {
+		img.Pix[i] = 25
+	}
+	fill := func(x0, y0, x1, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				img.SetGray(x, y, color.Gray{220})
+			}
+		}
+	}
+	for x := 40; x < 160; x += 4 {
+		fill(x, 60, x+2, 75)
+	}
+	fill(10, 10, 30, 40)
+	fill(185, 62, 190, 70)
+	return img
+}
+
+func TestDensestTextRegion(t *testing.T) {
+	img := markedChip()
+	want := geometry.RectInt{X: 40, Y: 60, Width: 119, Height: 15}
+	if got := DensestTextRegion(img); got != want {
+		t.Errorf("DensestTextRegion = %+v, want %+v", got, want)
+	}
+
+	// Bounds offsets carry through to the result
+	sub := img.SubImage(image.Rect(20, 50, 200, 100))
+	if got := DensestTextRegion(sub); got != want {
+		t.Errorf("DensestTextRegion(sub-image) = %+v, want %+v", got, want)
+	}
+
+	if got := DensestTextRegion(image.NewGray(image.Rect(0, 0, 50, 50))); got != (geometry.RectInt{}) {
+		t.Errorf("DensestTextRegion(blank) = %+v, want empty", got)
+	}
+}
+
+func TestPlanMasks(t *testing.T) {
+	img := markedChip() // 20000 px²; text at (40,60) 119×15
+	match := func(x, y, w, h int) LogoMatch {
+		return LogoMatch{Bounds: geometry.RectInt{X: x, Y: y, Width: w, Height: h}}
+	}
+	cases := []struct {
+		name        string
+		matches     []LogoMatch
+		maxFraction float64
+		want        []MaskDecision
+	}{
+		{
+			name:    "logo clear of text",
+			matches: []LogoMatch{match(8, 8, 25, 35)},
+			want:    []MaskDecision{MaskOK},
+		},
+		{
+			// 5600 px² is over a quarter of the chip alone
+			name:    "single match too large",
+			matches: []LogoMatch{match(0, 0, 80, 70), match(8, 8, 25, 35)},
+			want:    []MaskDecision{MaskTooLarge, MaskOK},
+		},
+		{
+			name:    "overlaps text",
+			matches: []LogoMatch{match(60, 58, 30, 20), match(150, 70, 20, 20), match(8, 8, 25, 35)},
+			want:    []MaskDecision{MaskOverlapsText, MaskOK, MaskOK},
+		},
+		{
+			// 1600 + 1600 fits 4000 px², a third 1600 does not, 400 still does
+			name:        "total coverage budget",
+			matches:     []LogoMatch{match(0, 0, 40, 40), match(160, 0, 40, 40), match(80, 0, 40, 40), match(0, 80, 20, 20)},
+			maxFraction: 0.2,
+			want:        []MaskDecision{MaskOK, MaskOK, MaskOverBudget, MaskOK},
+		},
+		{
+			// The default budget is 35%: 7000 px²
+			name:    "default budget",
+			matches: []LogoMatch{match(0, 0, 70, 51), match(120, 0, 70, 51), match(0, 80, 20, 20)},
+			want:    []MaskDecision{MaskOK, MaskOverBudget, MaskOK},
+		},
+	}
+	for _, c := range cases {
+		got := PlanMasks(img, c.matches, c.maxFraction)
+		if len(got) != len(c.want) {
+			t.Errorf("%s: %d decisions, want %d", c.name, len(got), len(c.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: match %d: %s, want %s", c.name, i, got[i], c.want[i])
+			}
+		}
+	}
+}
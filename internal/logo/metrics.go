@@ -0,0 +1,203 @@
+package logo
+
+import (
+	"fmt"
+	"image"
+	"regexp"
+	"sort"
+	"strings"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// evalFloor is the lowest match score EvaluateAgainst records. Scores below
+// it count as no detection at every threshold it considers.
+const evalFloor = 0.4
+
+// LabeledCrop is a component image with the logos known to be on it, as
+// used for evaluating the library.
+type LabeledCrop struct {
+	Image    image.Image
+	Rotation int      // Image rotation in degrees (0, 90, 180, 270)
+	Logos    []string // Names of the logos on the component, e.g. "TI"
+}
+
+// LogoMetric is the detection quality of one logo over a set of samples.
+type LogoMetric struct {
+	Name string
+
+	// Counts at the logo's current threshold
+	Threshold      float64
+	TruePositives  int
+	FalsePositives int
+	FalseNegatives int
+	Precision      float64
+	Recall         float64
+	F1             float64
+
+	// SuggestedThreshold maximizes F1 over the samples, preferring the
+	// higher threshold on ties; SuggestedF1 is the F1 it reaches. Zero if
+	// no sample carries the logo.
+	SuggestedThreshold float64
+	SuggestedF1        float64
+}
+
+// LogoMetrics summarizes EvaluateAgainst over all logos in the library.
+type LogoMetrics struct {
+	Samples int
+	Logos   []LogoMetric // Sorted by name
+}
+
+// NamesInText returns the logo names written as <NAME> in ground-truth
+// text, upper-cased.
+func NamesInText(text string) []string {
+	re := regexp.MustCompile(`<([A-Za-z0-9]+)>`)
+	var names []string
+	for _, m := range re.FindAllStringSubmatch(text, -1) {
+		names = append(names, strings.ToUpper(m[1]))
+	}
+	return names
+}
+
+// Threshold returns the minimum score at which l is reported: its own
+// MinScore if set, otherwise fallback.
+func (l *Logo) Threshold(fallback float64) float64 {
+	if l.MinScore > 0 {
+		return l.MinScore
+	}
+	return fallback
+}
+
+// EvaluateAgainst runs detection on each sample with the scale and rotation
+// sweep of opts, which should be the search the thresholds will gate, and
+// measures, per logo name, precision and recall at the logo's current
+// threshold (opts.MinConfidence unless Logo.MinScore is set) along with the
+// threshold that would maximize F1. Names compare case-insensitively.
+func (lib *LogoLibrary) EvaluateAgainst(samples []LabeledCrop, opts DetectOptions) LogoMetrics {
+	observations := make(map[string][]observation)
+	thresholds := make(map[string]float64)
+	for _, l := range lib.Logos {
+		name := strings.ToUpper(l.Name)
+		observations[name] = nil
+		thresholds[name] = l.Threshold(opts.MinConfidence)
+	}
+
+	opts.MinConfidence = evalFloor
+	for _, s := range samples {
+		expected := make(map[string]bool)
+		for _, name := range s.Logos {
+			expected[strings.ToUpper(name)] = true
+		}
+		scores := make(map[string]float64)
+		for _, m := range lib.detect(s.Image, geometry.RectInt{}, s.Rotation, opts, false) {
+			name := strings.ToUpper(m.Logo.Name)
+			scores[name] = max(scores[name], m.Score)
+		}
+		for name := range observations {
+			observations[name] = append(observations[name], observation{scores[name], expected[name]})
+		}
+	}
+
+	metrics := LogoMetrics{Samples: len(samples)}
+	for name, obs := range observations {
+		metrics.Logos = append(metrics.Logos, logoMetric(name, thresholds[name], obs))
+	}
+
+	sort.Slice(metrics.Logos, func(i, j int) bool {
+		return metrics.Logos[i].Name < metrics.Logos[j].Name
+	})
+	return metrics
+}
+
+// observation is one sample's outcome for one logo.
+type observation struct {
+	score    float64 // Best match score, 0 if none above evalFloor
+	positive bool    // The sample carries the logo
+}
+
+// logoMetric computes the metric of the logo name from its observations.
+func logoMetric(name string, threshold float64, obs []observation) LogoMetric {
+	counts := func(threshold float64) (tp, fp, fn int) {
+		for _, o := range obs {
+			detected := o.score > 0 && o.score >= threshold
+			switch {
+			case detected && o.positive:
+				tp++
+			case detected:
+				fp++
+			case o.positive:
+				fn++
+			}
+		}
+		return tp, fp, fn
+	}
+
+	m := LogoMetric{Name: name, Threshold: threshold}
+	m.TruePositives, m.FalsePositives, m.FalseNegatives = counts(m.Threshold)
+	m.Precision, m.Recall, m.F1 = scoreCounts(m.TruePositives, m.FalsePositives, m.FalseNegatives)
+
+	// Only thresholds at observed scores change the counts
+	for _, o := range obs {
+		if o.score == 0 {
+			continue
+		}
+		_, _, f1 := scoreCounts(counts(o.score))
+		if f1 > m.SuggestedF1 || (f1 == m.SuggestedF1 && f1 > 0 && o.score > m.SuggestedThreshold) {
+			m.SuggestedThreshold, m.SuggestedF1 = o.score, f1
+		}
+	}
+	return m
+}
+
+// scoreCounts returns precision, recall and F1 for detection counts.
+// Undefined ratios are 0.
+func scoreCounts(tp, fp, fn int) (precision, recall, f1 float64) {
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return precision, recall, f1
+}
+
+// ApplySuggestedThresholds sets MinScore on every logo with a suggested
+// threshold in m, so DetectLogos uses it from then on. Returns the number
+// of logos changed.
+func (lib *LogoLibrary) ApplySuggestedThresholds(m LogoMetrics) int {
+	suggested := make(map[string]float64)
+	for _, lm := range m.Logos {
+		if lm.SuggestedThreshold > 0 {
+			suggested[lm.Name] = lm.SuggestedThreshold
+		}
+	}
+	changed := 0
+	for _, l := range lib.Logos {
+		if t, ok := suggested[strings.ToUpper(l.Name)]; ok && l.MinScore != t {
+			l.MinScore = t
+			changed++
+		}
+	}
+	return changed
+}
+
+// String formats the metrics as a table, one logo per line.
+func (m LogoMetrics) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d samples\n", m.Samples)
+	fmt.Fprintf(&sb, "%-12s %5s %4s %4s %4s %6s %6s %6s   %9s %6s\n",
+		"Logo", "Thr", "TP", "FP", "FN", "Prec", "Recall", "F1", "Suggested", "F1")
+	for _, l := range m.Logos {
+		suggested := "-"
+		if l.SuggestedThreshold > 0 {
+			suggested = fmt.Sprintf("%.3f", l.SuggestedThreshold)
+		}
+		fmt.Fprintf(&sb, "%-12s %5.2f %4d %4d %4d %6.2f %6.2f %6.2f   %9s %6.2f\n",
+			l.Name, l.Threshold, l.TruePositives, l.FalsePositives, l.FalseNegatives,
+			l.Precision, l.Recall, l.F1, suggested, l.SuggestedF1)
+	}
+	return sb.String()
+}
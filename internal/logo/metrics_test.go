@@ -0,0 +1,157 @@
+package logo
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// glyphImage returns a w×h dark image with a light 32×32 "F" whose
+// template-space origin is at (ox, oy), turned a quarter clockwise if
+// rotated.
+func glyphImage(w, h, ox, oy int, rotated bool) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 20
+	}
+	for y := 4; y < 36; y++ {
+		for x := 4; x < 36; x++ {
+			if x < 12 || y < 12 || (y >= 18 && y < 24 && x < 28) {
+				px, py := x, y
+				if rotated {
+					px, py = 39-y, x
+				}
+				img.SetGray(ox+px, oy+py, color.Gray{230})
+			}
+		}
+	}
+	return img
+}
+
+func TestScoreCounts(t *testing.T) {
+	cases := []struct {
+		tp, fp, fn    int
+		prec, rec, f1 float64
+	}{
+		{0, 0, 0, 0, 0, 0},
+		{2, 1, 1, 2.0 / 3, 2.0 / 3, 2.0 / 3},
+		{1, 0, 3, 1, 0.25, 0.4},
+		{3, 1, 0, 0.75, 1, 6.0 / 7},
+		{0, 3, 0, 0, 0, 0},
+		{0, 0, 2, 0, 0, 0},
+	}
+	for _, c := range cases {
+		p, r, f1 := scoreCounts(c.tp, c.fp, c.fn)
+		if math.Abs(p-c.prec) > 1e-9 || math.Abs(r-c.rec) > 1e-9 || math.Abs(f1-c.f1) > 1e-9 {
+			t.Errorf("scoreCounts(%d, %d, %d) = %.3f, %.3f, %.3f; want %.3f, %.3f, %.3f",
+				c.tp, c.fp, c.fn, p, r, f1, c.prec, c.rec, c.f1)
+		}
+	}
+}
+
+func TestLogoMetric(t *testing.T) {
+	pos := func(score float64) observation { return observation{score, true} }
+	neg := func(score float64) observation { return observation{score, false} }
+	cases := []struct {
+		name       string
+		threshold  float64
+		obs        []observation
+		tp, fp, fn int
+		suggested  float64
+		f1         float64
+	}{
+		{
+			// At 0.75: 0.95 and 0.85 found, 0.8 a false alarm, 0.6 missed.
+			// Lowering to 0.6 finds every positive for one more false alarm.
+			name:      "lower threshold",
+			threshold: 0.75,
+			obs:       []observation{pos(0.95), pos(0.85), pos(0.6), neg(0.8), neg(0.5), neg(0)},
+			tp:        2, fp: 1, fn: 1,
+			suggested: 0.6, f1: 6.0 / 7,
+		},
+		{
+			name:      "raise threshold",
+			threshold: 0.5,
+			obs:       []observation{pos(0.95), pos(0.9), neg(0.7), neg(0.6)},
+			tp:        2, fp: 2, fn: 0,
+			suggested: 0.9, f1: 1,
+		},
+		{
+			// 0.9 (1 found, 1 missed) and 0.7 (2 found, 2 false alarms)
+			// both reach F1 2/3; the higher threshold wins
+			name:      "tie prefers higher",
+			threshold: 0.75,
+			obs:       []observation{pos(0.9), pos(0.7), neg(0.8), neg(0.75)},
+			tp:        1, fp: 2, fn: 1,
+			suggested: 0.9, f1: 2.0 / 3,
+		},
+		{
+			name:      "never on a sample",
+			threshold: 0.75,
+			obs:       []observation{neg(0.8), neg(0)},
+			tp:        0, fp: 1, fn: 0,
+			suggested: 0, f1: 0,
+		},
+		{
+			name:      "never detected",
+			threshold: 0.75,
+			obs:       []observation{pos(0), pos(0)},
+			tp:        0, fp: 0, fn: 2,
+			suggested: 0, f1: 0,
+		},
+	}
+	for _, c := range cases {
+		m := logoMetric("TI", c.threshold, c.obs)
+		if m.TruePositives != c.tp || m.FalsePositives != c.fp || m.FalseNegatives != c.fn {
+			t.Errorf("%s: TP/FP/FN = %d/%d/%d, want %d/%d/%d", c.name,
+				m.TruePositives, m.FalsePositives, m.FalseNegatives, c.tp, c.fp, c.fn)
+		}
+		if m.SuggestedThreshold != c.suggested || math.Abs(m.SuggestedF1-c.f1) > 1e-9 {
+			t.Errorf("%s: suggested %.2f at F1 %.3f, want %.2f at F1 %.3f", c.name,
+				m.SuggestedThreshold, m.SuggestedF1, c.suggested, c.f1)
+		}
+	}
+}
+
+// TestEvaluateAgainstUsesSweep checks that samples are searched with the
+// given options: a logo turned a quarter is found exactly only when the
+// rotation tolerance covers it.
+func TestEvaluateAgainstUsesSweep(t *testing.T) {
+	lib := NewLogoLibrary()
+	lib.Add(NewLogo("F", glyphImage(40, 40, 0, 0, false), geometry.RectInt{Width: 40, Height: 40}, 16))
+	samples := []LabeledCrop{
+		{Image: glyphImage(120, 60, 30, 10, true), Logos: []string{"f"}},
+		{Image: image.NewGray(image.Rect(0, 0, 120, 60))},
+	}
+
+	opts := DefaultDetectOptions()
+	fixed := lib.EvaluateAgainst(samples, opts)
+	opts.RotationTolerance = 90
+	swept := lib.EvaluateAgainst(samples, opts)
+
+	if swept.Samples != 2 || len(swept.Logos) != 1 {
+		t.Fatalf("got %d samples, %d logos; want 2, 1", swept.Samples, len(swept.Logos))
+	}
+	m := swept.Logos[0]
+	if m.Name != "F" || m.Threshold != opts.MinConfidence {
+		t.Errorf("metric for %q at threshold %v, want F at %v", m.Name, m.Threshold, opts.MinConfidence)
+	}
+	if m.TruePositives != 1 || m.FalsePositives != 0 || m.FalseNegatives != 0 {
+		t.Errorf("swept TP/FP/FN = %d/%d/%d, want 1/0/0", m.TruePositives, m.FalsePositives, m.FalseNegatives)
+	}
+	if m.SuggestedThreshold != 1 {
+		t.Errorf("swept suggested threshold %v, want 1", m.SuggestedThreshold)
+	}
+	if got := fixed.Logos[0].SuggestedThreshold; got >= m.SuggestedThreshold {
+		t.Errorf("unrotated search suggests %v, want below the swept %v", got, m.SuggestedThreshold)
+	}
+
+	// A logo's own MinScore is its threshold
+	lib.Logos[0].MinScore = 0.9
+	if got := lib.EvaluateAgainst(samples, opts).Logos[0].Threshold; got != 0.9 {
+		t.Errorf("threshold with MinScore 0.9 = %v", got)
+	}
+}
@@ -0,0 +1,345 @@
+package netlist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pcb-tracer/internal/component"
+)
+
+// NetMatch pairs an expected net with the traced net Compare matched it to.
+type NetMatch struct {
+	Expected string
+	Actual   string
+	Renamed  bool         // Matched by pin overlap, and the names differ
+	Missing  []Connection // In the expected net but not the traced one
+	Extra    []Connection // In the traced net but not the expected one
+}
+
+// OK reports whether the traced net has exactly the expected connections.
+func (m NetMatch) OK() bool {
+	return len(m.Missing) == 0 && len(m.Extra) == 0
+}
+
+// Report is the result of comparing a traced netlist to an expected one.
+type Report struct {
+	Matches     []NetMatch // Matched nets, in natural order of expected name
+	MissingNets []*Net     // Expected nets with no traced counterpart
+	ExtraNets   []*Net     // Traced nets with no expected counterpart
+}
+
+// MatchCount returns the number of nets traced exactly as expected.
+func (r Report) MatchCount() int {
+	n := 0
+	for _, m := range r.Matches {
+		if m.OK() {
+			n++
+		}
+	}
+	return n
+}
+
+// MissingCount returns the number of expected connections not traced:
+// pins missing from matched nets plus the pins of missing nets.
+func (r Report) MissingCount() int {
+	n := 0
+	for _, m := range r.Matches {
+		n += len(m.Missing)
+	}
+	for _, net := range r.MissingNets {
+		n += len(net.Connections)
+	}
+	return n
+}
+
+// ExtraCount returns the number of traced connections not expected: extra
+// pins in matched nets plus the pins of extra nets.
+func (r Report) ExtraCount() int {
+	n := 0
+	for _, m := range r.Matches {
+		n += len(m.Extra)
+	}
+	for _, net := range r.ExtraNets {
+		n += len(net.Connections)
+	}
+	return n
+}
+
+// Summary returns a one-line summary, e.g. "12 nets match, 3 missing, 1 extra".
+func (r Report) Summary() string {
+	return fmt.Sprintf("%d nets match, %d missing, %d extra", r.MatchCount(), r.MissingCount(), r.ExtraCount())
+}
+
+// String lists every difference, one per line.
+func (r Report) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Summary())
+	sb.WriteString("\n")
+	for _, m := range r.Matches {
+		if m.Renamed {
+			fmt.Fprintf(&sb, "renamed: %s traced as %s\n", m.Expected, m.Actual)
+		}
+		for _, c := range m.Missing {
+			fmt.Fprintf(&sb, "missing: %s in %s\n", connectionLabel(c), m.Expected)
+		}
+		for _, c := range m.Extra {
+			fmt.Fprintf(&sb, "extra: %s in %s\n", connectionLabel(c), m.Actual)
+		}
+	}
+	for _, net := range r.MissingNets {
+		fmt.Fprintf(&sb, "missing net: %s (%s)\n", net.Name, connectionList(net.Connections))
+	}
+	for _, net := range r.ExtraNets {
+		fmt.Fprintf(&sb, "extra net: %s (%s)\n", net.Name, connectionList(net.Connections))
+	}
+	return sb.String()
+}
+
+// Compare checks a traced netlist against an expected one, such as the
+// connections documented in a datasheet. Nets are matched by name first
+// (ignoring case and any "#N" instance suffix), then the rest by the number
+// of pins they share, most first. Nets with fewer than two connections
+// carry no connection and are ignored.
+func Compare(actual, expected *Netlist) Report {
+	act := comparableNets(actual)
+	exp := comparableNets(expected)
+
+	var report Report
+	matchedAct := make(map[*Net]bool)
+	matchedExp := make(map[*Net]bool)
+	match := func(e, a *Net, renamed bool) {
+		matchedExp[e] = true
+		matchedAct[a] = true
+		m := NetMatch{Expected: e.Name, Actual: a.Name, Renamed: renamed}
+		m.Missing = connectionsNotIn(e.Connections, a)
+		m.Extra = connectionsNotIn(a.Connections, e)
+		report.Matches = append(report.Matches, m)
+	}
+
+	byName := make(map[string]*Net)
+	for _, a := range act {
+		key := netNameKey(a.Name)
+		if _, dup := byName[key]; !dup && key != "" {
+			byName[key] = a
+		}
+	}
+	for _, e := range exp {
+		if a := byName[netNameKey(e.Name)]; a != nil && !matchedAct[a] {
+			match(e, a, false)
+		}
+	}
+
+	type pair struct {
+		e, a          *Net
+		shared, union int
+	}
+	var pairs []pair
+	for _, e := range exp {
+		if matchedExp[e] {
+			continue
+		}
+		for _, a := range act {
+			if matchedAct[a] {
+				continue
+			}
+			shared := len(e.Connections) - len(connectionsNotIn(e.Connections, a))
+			if shared > 0 {
+				pairs = append(pairs, pair{e, a, shared, len(e.Connections) + len(a.Connections) - shared})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].shared != pairs[j].shared {
+			return pairs[i].shared > pairs[j].shared
+		}
+		return pairs[i].union < pairs[j].union
+	})
+	for _, p := range pairs {
+		if matchedExp[p.e] || matchedAct[p.a] {
+			continue
+		}
+		match(p.e, p.a, netNameKey(p.e.Name) != netNameKey(p.a.Name))
+	}
+
+	for _, e := range exp {
+		if !matchedExp[e] {
+			report.MissingNets = append(report.MissingNets, e)
+		}
+	}
+	for _, a := range act {
+		if !matchedAct[a] {
+			report.ExtraNets = append(report.ExtraNets, a)
+		}
+	}
+
+	sort.SliceStable(report.Matches, func(i, j int) bool {
+		return component.NaturalLess(report.Matches[i].Expected, report.Matches[j].Expected)
+	})
+	return report
+}
+
+// comparableNets returns the nets of n with at least two distinct
+// connections, each with duplicates removed and sorted, in natural name order.
+func comparableNets(n *Netlist) []*Net {
+	if n == nil {
+		return nil
+	}
+	var nets []*Net
+	for _, net := range n.Nets {
+		if net == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		var conns []Connection
+		for _, c := range net.Connections {
+			if k := connectionKey(c); !seen[k] {
+				seen[k] = true
+				conns = append(conns, c)
+			}
+		}
+		if len(conns) < 2 {
+			continue
+		}
+		sort.Slice(conns, func(i, j int) bool {
+			return component.NaturalLess(connectionLabel(conns[i]), connectionLabel(conns[j]))
+		})
+		nets = append(nets, &Net{Name: net.Name, Connections: conns})
+	}
+	sort.SliceStable(nets, func(i, j int) bool {
+		return component.NaturalLess(nets[i].Name, nets[j].Name)
+	})
+	return nets
+}
+
+// connectionsNotIn returns the connections of conns that net lacks.
+func connectionsNotIn(conns []Connection, net *Net) []Connection {
+	have := make(map[string]bool, len(net.Connections))
+	for _, c := range net.Connections {
+		have[connectionKey(c)] = true
+	}
+	var out []Connection
+	for _, c := range conns {
+		if !have[connectionKey(c)] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// netNameKey normalizes a net name for matching.
+func netNameKey(name string) string {
+	return strings.ToUpper(strings.TrimSpace(BaseNetName(name)))
+}
+
+// connectionKey identifies a pin regardless of case or pin name.
+func connectionKey(c Connection) string {
+	pin := strconv.Itoa(c.PinNumber)
+	if c.PinNumber == 0 {
+		pin = strings.ToUpper(c.PinName)
+	}
+	return strings.ToUpper(strings.TrimSpace(c.ComponentID)) + "." + pin
+}
+
+// connectionLabel formats a connection as "U3-7", or "U3-CLK" when it only
+// has a pin name.
+func connectionLabel(c Connection) string {
+	if c.PinNumber == 0 && c.PinName != "" {
+		return c.ComponentID + "-" + c.PinName
+	}
+	return fmt.Sprintf("%s-%d", c.ComponentID, c.PinNumber)
+}
+
+func connectionList(conns []Connection) string {
+	labels := make([]string, len(conns))
+	for i, c := range conns {
+		labels[i] = connectionLabel(c)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// FromElectricalNets builds a Netlist of the component and connector pins of
+// traced nets, for Compare. Connector pins use the component ID "CONN", as
+// in ExportNetCSV; vias with no pin assignment are left out. Nets are named
+// by Name, or by ID when unnamed. Resolvers are as for GenerateNetlistDump.
+func FromElectricalNets(
+	name string,
+	nets []*ElectricalNet,
+	viaResolver func(viaID string) (componentID, pinNumber, signalName string),
+	connResolver func(connID string) (pinNumber int, signalName string),
+) *Netlist {
+	nl := NewNetlist(name)
+	for _, en := range nets {
+		net := &Net{Name: en.Name}
+		if net.Name == "" {
+			net.Name = en.ID
+		}
+		for _, padID := range en.PadIDs {
+			if ref, pin, ok := strings.Cut(padID, "."); ok {
+				net.Connections = append(net.Connections, pinConnection(ref, pin, ""))
+			}
+		}
+		for _, connID := range en.ConnectorIDs {
+			pin, sig := connResolver(connID)
+			net.Connections = append(net.Connections, Connection{ComponentID: "CONN", PinNumber: pin, PinName: sig})
+		}
+		for _, viaID := range en.ViaIDs {
+			if compID, pin, sig := viaResolver(viaID); compID != "" && pin != "" {
+				net.Connections = append(net.Connections, pinConnection(compID, pin, sig))
+			}
+		}
+		nl.AddNet(net)
+	}
+	return nl
+}
+
+// pinConnection makes a Connection from a pin given as text: a number, or
+// a pin name when it isn't one.
+func pinConnection(ref, pin, name string) Connection {
+	c := Connection{ComponentID: ref, PinName: name}
+	if n, err := strconv.Atoi(pin); err == nil {
+		c.PinNumber = n
+	} else if c.PinName == "" {
+		c.PinName = pin
+	}
+	return c
+}
+
+// ReadNetCSV reads a CSV net listing in the format ExportNetCSV writes:
+// a header row, then one "Net,Kind,Ref,Pin,Signal,Element" row per member.
+// Bare via rows are skipped, and the Element column is optional, so a
+// hand-written listing of the expected connections needs only the first
+// four columns.
+func ReadNetCSV(name string, r io.Reader) (*Netlist, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read netlist: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty netlist")
+	}
+
+	nl := NewNetlist(name)
+	for i, row := range rows[1:] {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("line %d: expected at least 4 columns, got %d", i+2, len(row))
+		}
+		netName, kind, ref, pin := row[0], row[1], row[2], row[3]
+		if kind == csvKindVia || netName == "" {
+			continue
+		}
+		signal := ""
+		if len(row) > 4 {
+			signal = row[4]
+		}
+		net := nl.GetOrCreateNet(netName)
+		net.Connections = append(net.Connections, pinConnection(ref, pin, signal))
+	}
+	return nl, nil
+}
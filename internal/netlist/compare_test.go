@@ -0,0 +1,146 @@
+package netlist
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseNets builds a netlist from lines like "CLK: U1-1 U2-CLK".
+func parseNets(lines ...string) *Netlist {
+	nl := NewNetlist("test")
+	for _, line := range lines {
+		name, pins, _ := strings.Cut(line, ":")
+		net := &Net{Name: strings.TrimSpace(name)}
+		for _, p := range strings.Fields(pins) {
+			ref, pin, _ := strings.Cut(p, "-")
+			net.Connections = append(net.Connections, pinConnection(ref, pin, ""))
+		}
+		nl.AddNet(net)
+	}
+	return nl
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		name             string
+		expected, actual []string
+		summary          string
+		renamed          []string // "expected=actual"
+		missing, extra   []string // Unmatched net names
+	}{
+		{
+			name:     "exact",
+			expected: []string{"VCC: U1-14 U2-14", "GND: U1-7 U2-7"},
+			actual:   []string{"GND: U2-7 U1-7", "VCC: U1-14 U2-14"},
+			summary:  "2 nets match, 0 missing, 0 extra",
+		},
+		{
+			name:     "name ignores case and instance suffix",
+			expected: []string{"VCC: U1-14 U2-14"},
+			actual:   []string{"vcc#2: U2-14 U1-14 U1-14"},
+			summary:  "1 nets match, 0 missing, 0 extra",
+		},
+		{
+			name:     "renamed by pin overlap",
+			expected: []string{"CLK: U1-1 U2-3 U3-9"},
+			actual:   []string{"N$5: U1-1 U2-3"},
+			summary:  "0 nets match, 1 missing, 0 extra",
+			renamed:  []string{"CLK=N$5"},
+		},
+		{
+			name:     "overlap prefers most shared pins",
+			expected: []string{"A: U1-1 U2-1 U3-1"},
+			actual:   []string{"N1: U1-1 U9-9", "N2: U2-1 U3-1"},
+			summary:  "0 nets match, 1 missing, 2 extra",
+			renamed:  []string{"A=N2"},
+			extra:    []string{"N1"},
+		},
+		{
+			name:     "names match before pins",
+			expected: []string{"D0: U1-2 U2-2", "D1: U1-3 U2-3"},
+			actual:   []string{"D0: U1-3 U2-3", "N7: U1-2 U2-2"},
+			summary:  "0 nets match, 4 missing, 4 extra",
+			missing:  []string{"D1"},
+			extra:    []string{"N7"},
+		},
+		{
+			name:     "missing and extra nets and pins",
+			expected: []string{"GND: U1-7 U2-7", "RST: U1-2 U3-4"},
+			actual:   []string{"GND: U1-7 U2-7 U2-8", "X: U7-1 U7-2"},
+			summary:  "0 nets match, 2 missing, 3 extra",
+			missing:  []string{"RST"},
+			extra:    []string{"X"},
+		},
+		{
+			name:     "single-pin nets carry no connection",
+			expected: []string{"NC: U6-1", "VCC: U1-14 U2-14"},
+			actual:   []string{"VCC: U1-14 U2-14", "N9: U4-3"},
+			summary:  "1 nets match, 0 missing, 0 extra",
+		},
+	}
+	for _, c := range cases {
+		r := Compare(parseNets(c.actual...), parseNets(c.expected...))
+		if got := r.Summary(); got != c.summary {
+			t.Errorf("%s: Summary() = %q, want %q\n%s", c.name, got, c.summary, r)
+		}
+		var renamed, missing, extra []string
+		for _, m := range r.Matches {
+			if m.Renamed {
+				renamed = append(renamed, m.Expected+"="+m.Actual)
+			}
+		}
+		for _, n := range r.MissingNets {
+			missing = append(missing, n.Name)
+		}
+		for _, n := range r.ExtraNets {
+			extra = append(extra, n.Name)
+		}
+		for _, f := range []struct {
+			what      string
+			got, want []string
+		}{
+			{"renamed", renamed, c.renamed},
+			{"missing nets", missing, c.missing},
+			{"extra nets", extra, c.extra},
+		} {
+			if strings.Join(f.got, ",") != strings.Join(f.want, ",") {
+				t.Errorf("%s: %s = %v, want %v", c.name, f.what, f.got, f.want)
+			}
+		}
+	}
+}
+
+func TestReadNetCSV(t *testing.T) {
+	const input = `Net,Kind,Ref,Pin,Signal,Element
+CLK,pin,U1,1,,U1.1
+CLK,via,V3,,,V3
+CLK,connector,CONN,24,PHI2,C24
+RST,pin,U3,RESET
+RST, pin, U4, 2
+`
+	nl, err := ReadNetCSV("expected", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"CLK": "U1-1, CONN-24",
+		"RST": "U3-RESET, U4-2",
+	}
+	if len(nl.Nets) != len(want) {
+		t.Errorf("%d nets, want %d", len(nl.Nets), len(want))
+	}
+	for _, n := range nl.Nets {
+		if got := connectionList(n.Connections); got != want[n.Name] {
+			t.Errorf("net %s = %s, want %s", n.Name, got, want[n.Name])
+		}
+	}
+	if c := nl.FindNet("CLK").Connections[1]; c.PinName != "PHI2" {
+		t.Errorf("connector signal = %q, want PHI2", c.PinName)
+	}
+
+	for _, bad := range []string{"", "Net,Kind,Ref,Pin\nCLK,pin,U1\n"} {
+		if _, err := ReadNetCSV("bad", strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadNetCSV(%q): want error", bad)
+		}
+	}
+}
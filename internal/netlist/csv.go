@@ -0,0 +1,98 @@
+package netlist
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"pcb-tracer/internal/component"
+)
+
+// Member kinds in the CSV net listing.
+const (
+	csvKindPin       = "pin"
+	csvKindConnector = "connector"
+	csvKindVia       = "via"
+)
+
+var csvKindRank = map[string]int{csvKindPin: 0, csvKindConnector: 1, csvKindVia: 2}
+
+type csvMember struct {
+	kind, ref, pin, signal, element string
+}
+
+// ExportNetCSV writes one CSV row per net member: component pins (from pads
+// and from vias assigned to a pin), edge connector pins, and vias with no
+// pin assignment. Nets are named by Name, or by ID when unnamed, and nets
+// and members are sorted so the output diffs cleanly between runs.
+// Resolvers are as for GenerateNetlistDump.
+func ExportNetCSV(
+	nets []*ElectricalNet,
+	viaResolver func(viaID string) (componentID, pinNumber, signalName string),
+	connResolver func(connID string) (pinNumber int, signalName string),
+	w io.Writer,
+) error {
+	type namedNet struct {
+		name    string
+		members []csvMember
+	}
+	var out []namedNet
+	for _, net := range nets {
+		nn := namedNet{name: net.Name}
+		if nn.name == "" {
+			nn.name = net.ID
+		}
+		for _, padID := range net.PadIDs {
+			if ref, pin, ok := strings.Cut(padID, "."); ok {
+				nn.members = append(nn.members, csvMember{kind: csvKindPin, ref: ref, pin: pin, element: padID})
+			}
+		}
+		for _, connID := range net.ConnectorIDs {
+			pin, sig := connResolver(connID)
+			nn.members = append(nn.members, csvMember{kind: csvKindConnector, ref: "CONN",
+				pin: strconv.Itoa(pin), signal: sig, element: connID})
+		}
+		for _, viaID := range net.ViaIDs {
+			compID, pin, sig := viaResolver(viaID)
+			if compID != "" && pin != "" {
+				nn.members = append(nn.members, csvMember{kind: csvKindPin, ref: compID, pin: pin, signal: sig, element: viaID})
+			} else {
+				nn.members = append(nn.members, csvMember{kind: csvKindVia, ref: viaID, signal: sig, element: viaID})
+			}
+		}
+		sort.Slice(nn.members, func(i, j int) bool { return memberLess(nn.members[i], nn.members[j]) })
+		out = append(out, nn)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return component.NaturalLess(out[i].name, out[j].name) })
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Net", "Kind", "Ref", "Pin", "Signal", "Element"})
+	for _, nn := range out {
+		for _, m := range nn.members {
+			cw.Write([]string{nn.name, m.kind, m.ref, m.pin, m.signal, m.element})
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write netlist: %w", err)
+	}
+	return nil
+}
+
+// memberLess orders component pins first, then connectors, then bare vias,
+// each in natural ref/pin order.
+func memberLess(a, b csvMember) bool {
+	if a.kind != b.kind {
+		return csvKindRank[a.kind] < csvKindRank[b.kind]
+	}
+	if a.ref != b.ref {
+		return component.NaturalLess(a.ref, b.ref)
+	}
+	if a.pin != b.pin {
+		return component.NaturalLess(a.pin, b.pin)
+	}
+	return a.element < b.element
+}
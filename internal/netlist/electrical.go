@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 
 	"pcb-tracer/internal/connector"
@@ -382,6 +383,127 @@ func (n *ElectricalNet) ConnectedComponents(
 	return components
 }
 
+// PathRole describes an element's position along a net's signal path.
+type PathRole int
+
+const (
+	RoleSource    PathRole = iota // Endpoint the walk started from
+	RoleTrace                     // Trace segment
+	RoleVia                       // Intermediate via/pad/connector
+	RoleEnd                       // Leaf endpoint (pin, connector, dangling via)
+	RoleUnreached                 // Member not physically connected to the source
+)
+
+func (r PathRole) String() string {
+	switch r {
+	case RoleSource:
+		return "Source"
+	case RoleTrace:
+		return "Trace"
+	case RoleVia:
+		return "Via"
+	case RoleEnd:
+		return "End"
+	case RoleUnreached:
+		return "Unreached"
+	default:
+		return "Unknown"
+	}
+}
+
+// PathElement is a net element annotated with its role on the signal path.
+type PathElement struct {
+	NetElement
+	Role PathRole
+}
+
+// OrderPath orders the net's elements by a depth-first walk of edges (pairs
+// of connected element IDs) starting at startID, so a simple net reads
+// pin -> trace -> via -> trace -> pin. Branches are walked one after another
+// in ID order. If startID is not in the net the walk starts at the root
+// connector, else the first connector, else the first pin via (IDs in pins),
+// else the first pad. Elements the walk cannot reach are appended last with
+// RoleUnreached.
+func OrderPath(n *ElectricalNet, edges [][2]string, startID string, pins map[string]bool) []PathElement {
+	if n == nil || len(n.Elements) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]NetElement, len(n.Elements))
+	for _, e := range n.Elements {
+		byID[e.ID] = e
+	}
+
+	adj := make(map[string][]string)
+	for _, e := range edges {
+		if _, ok := byID[e[0]]; !ok {
+			continue
+		}
+		if _, ok := byID[e[1]]; !ok {
+			continue
+		}
+		adj[e[0]] = append(adj[e[0]], e[1])
+		adj[e[1]] = append(adj[e[1]], e[0])
+	}
+	for id := range adj {
+		sort.Strings(adj[id])
+	}
+
+	if _, ok := byID[startID]; !ok {
+		startID = defaultPathStart(n, pins)
+	}
+
+	var path []PathElement
+	visited := make(map[string]bool)
+	var walk func(id string)
+	walk = func(id string) {
+		visited[id] = true
+		e := byID[id]
+		role := RoleVia
+		switch {
+		case id == startID:
+			role = RoleSource
+		case e.Type == ElementTrace:
+			role = RoleTrace
+		case len(adj[id]) <= 1:
+			role = RoleEnd
+		}
+		path = append(path, PathElement{NetElement: e, Role: role})
+		for _, next := range adj[id] {
+			if !visited[next] {
+				walk(next)
+			}
+		}
+	}
+	walk(startID)
+
+	for _, e := range n.Elements {
+		if !visited[e.ID] {
+			path = append(path, PathElement{NetElement: e, Role: RoleUnreached})
+		}
+	}
+	return path
+}
+
+// defaultPathStart picks the endpoint a signal path is read from.
+func defaultPathStart(n *ElectricalNet, pins map[string]bool) string {
+	if n.RootConnectorID != "" && n.ContainsElement(n.RootConnectorID) {
+		return n.RootConnectorID
+	}
+	if len(n.ConnectorIDs) > 0 {
+		return n.ConnectorIDs[0]
+	}
+	for _, id := range n.ViaIDs {
+		if pins[id] {
+			return id
+		}
+	}
+	if len(n.PadIDs) > 0 {
+		return n.PadIDs[0]
+	}
+	return n.Elements[0].ID
+}
+
 // BaseNetName strips an instance suffix (e.g. "GND#2" -> "GND").
 func BaseNetName(name string) string {
 	if idx := strings.LastIndex(name, "#"); idx > 0 {
@@ -0,0 +1,60 @@
+package netlist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderPath(t *testing.T) {
+	elem := func(typ NetElementType, id string) NetElement { return NetElement{Type: typ, ID: id} }
+	net := func(elements ...NetElement) *ElectricalNet {
+		n := &ElectricalNet{ID: "net-001", Elements: elements}
+		n.RebuildIDLists()
+		return n
+	}
+	c1, pad := elem(ElementConnector, "c1"), elem(ElementPad, "U1.3")
+	t1, t2, t3 := elem(ElementTrace, "t1"), elem(ElementTrace, "t2"), elem(ElementTrace, "t3")
+	v1, v2, v3 := elem(ElementVia, "v1"), elem(ElementVia, "v2"), elem(ElementVia, "v3")
+
+	// c1 - t1 - v1 - t2 - U1.3
+	chain := [][2]string{{"c1", "t1"}, {"t1", "v1"}, {"v1", "t2"}, {"t2", "U1.3"}}
+	// c1 - t1 - v1, which forks into t3 - v3 and t2 - v2
+	fork := [][2]string{{"c1", "t1"}, {"t1", "v1"}, {"v1", "t3"}, {"t3", "v3"}, {"v1", "t2"}, {"t2", "v2"}}
+
+	cases := []struct {
+		name  string
+		net   *ElectricalNet
+		edges [][2]string
+		start string
+		pins  map[string]bool
+		want  string // id:role, in path order
+	}{
+		{"linear chain from the connector", net(pad, t2, v1, t1, c1), chain, "", nil,
+			"c1:Source t1:Trace v1:Via t2:Trace U1.3:End"},
+		{"linear chain from the middle", net(c1, t1, v1, t2, pad), chain, "v1", nil,
+			"v1:Source t1:Trace c1:End t2:Trace U1.3:End"},
+		{"unknown start falls back to the connector", net(c1, t1, v1, t2, pad), chain, "v9", nil,
+			"c1:Source t1:Trace v1:Via t2:Trace U1.3:End"},
+		{"branches walked in ID order", net(c1, t1, v1, t2, v2, t3, v3), fork, "", nil,
+			"c1:Source t1:Trace v1:Via t2:Trace v2:End t3:Trace v3:End"},
+		{"no connector starts at a pin via", net(t1, v1, t2, v2), [][2]string{{"v1", "t1"}, {"t1", "v2"}},
+			"", map[string]bool{"v2": true},
+			"v2:Source t1:Trace v1:End t2:Unreached"},
+		{"disconnected members appended last", net(c1, v3, t1, v1, t3),
+			[][2]string{{"c1", "t1"}, {"t1", "v1"}, {"t3", "v3"}, {"v1", "x9"}}, "", nil,
+			"c1:Source t1:Trace v1:End v3:Unreached t3:Unreached"},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, pe := range OrderPath(c.net, c.edges, c.start, c.pins) {
+			got = append(got, pe.ID+":"+pe.Role.String())
+		}
+		if s := strings.Join(got, " "); s != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, s, c.want)
+		}
+	}
+
+	if got := OrderPath(nil, chain, "", nil); got != nil {
+		t.Errorf("nil net: %v, want nil", got)
+	}
+}
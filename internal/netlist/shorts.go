@@ -0,0 +1,108 @@
+package netlist
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ShortKind classifies how two signals came to be connected.
+type ShortKind int
+
+const (
+	// ShortMergedSignals is a net whose connectors carry different signal
+	// names, e.g. a trace drawn from A0 to A1.
+	ShortMergedSignals ShortKind = iota
+	// ShortSharedElement is an element (connector, via, or pad) claimed by
+	// two or more nets with different names.
+	ShortSharedElement
+)
+
+func (k ShortKind) String() string {
+	switch k {
+	case ShortMergedSignals:
+		return "merged signals"
+	case ShortSharedElement:
+		return "shared element"
+	default:
+		return "unknown"
+	}
+}
+
+// Short is a short circuit between differently named signals.
+type Short struct {
+	Kind    ShortKind
+	NetIDs  []string // Nets involved: one for merged signals, two or more for a shared element
+	Element string   // Shared element ID (ShortSharedElement only)
+	Signals []string // Conflicting signal or net names, sorted
+}
+
+func (s Short) String() string {
+	if s.Kind == ShortSharedElement {
+		return fmt.Sprintf("%s in %s shorts %s", s.Element, strings.Join(s.NetIDs, ", "), strings.Join(s.Signals, ", "))
+	}
+	return fmt.Sprintf("%s shorts %s", strings.Join(s.NetIDs, ", "), strings.Join(s.Signals, ", "))
+}
+
+// ConnectorSignals returns the distinct non-empty signal names of the
+// connectors in n, sorted. signalName resolves a connector ID to its signal.
+func ConnectorSignals(n *ElectricalNet, signalName func(connID string) string) []string {
+	var signals []string
+	for _, cid := range n.ConnectorIDs {
+		if sig := signalName(cid); sig != "" && !slices.Contains(signals, sig) {
+			signals = append(signals, sig)
+		}
+	}
+	sort.Strings(signals)
+	return signals
+}
+
+// FindShorts reports nets that connect conflicting signals: nets joining
+// connectors with different signal names (several pins of the same signal,
+// such as GND, are fine), and elements that belong to nets with different
+// names. Names are compared without their "#N" instance suffix, so the
+// disambiguated halves of one signal are not reported against each other.
+// nets are as rebuilt by ReconcileNets; signalName resolves a connector ID
+// to its signal name. Shorts are sorted by their first net ID.
+func FindShorts(nets []*ElectricalNet, signalName func(connID string) string) []Short {
+	var shorts []Short
+	owners := make(map[string][]*ElectricalNet) // Element ID -> nets containing it
+	for _, n := range nets {
+		if signals := ConnectorSignals(n, signalName); len(signals) > 1 {
+			shorts = append(shorts, Short{Kind: ShortMergedSignals, NetIDs: []string{n.ID}, Signals: signals})
+		}
+		for _, e := range n.Elements {
+			if e.Type != ElementTrace {
+				owners[e.ID] = append(owners[e.ID], n)
+			}
+		}
+	}
+
+	for id, ns := range owners {
+		if len(ns) < 2 {
+			continue
+		}
+		var names, netIDs []string
+		for _, n := range ns {
+			netIDs = append(netIDs, n.ID)
+			if bn := BaseNetName(n.Name); !slices.Contains(names, bn) {
+				names = append(names, bn)
+			}
+		}
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		sort.Strings(netIDs)
+		shorts = append(shorts, Short{Kind: ShortSharedElement, NetIDs: netIDs, Element: id, Signals: names})
+	}
+
+	sort.SliceStable(shorts, func(i, j int) bool {
+		if shorts[i].NetIDs[0] != shorts[j].NetIDs[0] {
+			return shorts[i].NetIDs[0] < shorts[j].NetIDs[0]
+		}
+		return shorts[i].Element < shorts[j].Element
+	})
+	return shorts
+}
@@ -0,0 +1,52 @@
+package netlist
+
+import "testing"
+
+func TestFindShorts(t *testing.T) {
+	signals := map[string]string{"c1": "A0", "c2": "A1", "c3": "GND", "c4": "GND", "c5": "A2"}
+	signalName := func(id string) string { return signals[id] }
+	net := func(id, name string, connectors []string, elements ...NetElement) *ElectricalNet {
+		n := &ElectricalNet{ID: id, Name: name, ConnectorIDs: connectors}
+		for _, c := range connectors {
+			n.Elements = append(n.Elements, NetElement{Type: ElementConnector, ID: c})
+		}
+		n.Elements = append(n.Elements, elements...)
+		return n
+	}
+	via := func(id string) NetElement { return NetElement{Type: ElementVia, ID: id} }
+	trace := func(id string) NetElement { return NetElement{Type: ElementTrace, ID: id} }
+
+	nets := []*ElectricalNet{
+		// A trace drawn from A0 to A1
+		net("net-003", "A0", []string{"c1", "c2"}, trace("t1")),
+		// Several GND pins, and an unnamed connector, are no short
+		net("net-004", "GND", []string{"c3", "c4", "c9"}, via("v7")),
+		// v1 claimed by A2 and RESET
+		net("net-001", "A2", []string{"c5"}, via("v1"), trace("t2")),
+		net("net-002", "RESET", nil, via("v1"), via("v2"), trace("t2")),
+		// The halves of one split signal share v2 and U1.7 without a short
+		net("net-005", "GND#2", nil, via("v2"), NetElement{Type: ElementPad, ID: "U1.7"}),
+		net("net-006", "GND#3", nil, NetElement{Type: ElementPad, ID: "U1.7"}),
+	}
+	got := FindShorts(nets, signalName)
+	want := []string{
+		"v1 in net-001, net-002 shorts A2, RESET",
+		"v2 in net-002, net-005 shorts GND, RESET",
+		"net-003 shorts A0, A1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d shorts %v, want %d", len(got), got, len(want))
+	}
+	for i, s := range got {
+		if s.String() != want[i] {
+			t.Errorf("short %d = %q, want %q", i, s, want[i])
+		}
+	}
+	if got[0].Kind != ShortSharedElement || got[2].Kind != ShortMergedSignals {
+		t.Errorf("kinds %s, %s; want shared element, merged signals", got[0].Kind, got[2].Kind)
+	}
+
+	if got := FindShorts(nets[1:2], signalName); len(got) != 0 {
+		t.Errorf("FindShorts on a clean net = %v", got)
+	}
+}
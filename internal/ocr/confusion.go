@@ -0,0 +1,189 @@
+package ocr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ConfusionPair is two characters OCR mistakes for each other, in either
+// direction. Both are single uppercase characters.
+type ConfusionPair struct {
+	A string `json:"a"`
+	B string `json:"b"`
+	// InDigits marks a letter/digit pair that fixOCRPartNumbers corrects
+	// back to the digit inside a part number's digit run.
+	InDigits bool `json:"in_digits,omitempty"`
+}
+
+// ConfusionTable lists the character substitutions OCR text matching
+// tolerates. Fuzzy place-name matching treats every pair as equal; part
+// number repair only applies the InDigits pairs.
+type ConfusionTable struct {
+	Pairs []ConfusionPair `json:"pairs"`
+}
+
+// DefaultConfusionTable returns the built-in misread groups for
+// silkscreen and IC marking fonts.
+func DefaultConfusionTable() *ConfusionTable {
+	return &ConfusionTable{Pairs: []ConfusionPair{
+		{A: "0", B: "O", InDigits: true}, {A: "0", B: "D"}, {A: "O", B: "D"},
+		{A: "1", B: "I"}, {A: "1", B: "L"}, {A: "I", B: "L"},
+		{A: "5", B: "S", InDigits: true}, {A: "4", B: "A", InDigits: true},
+		{A: "8", B: "B"}, {A: "6", B: "G"},
+		{A: "2", B: "Z"},
+	}}
+}
+
+// LoadConfusionTable reads a ConfusionTable from a JSON file. The file
+// replaces the defaults rather than adding to them.
+func LoadConfusionTable(path string) (*ConfusionTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t ConfusionTable
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("cannot parse confusion table %s: %w", path, err)
+	}
+	for i, p := range t.Pairs {
+		if len(p.A) != 1 || len(p.B) != 1 {
+			return nil, fmt.Errorf("confusion table %s: pair %d (%q, %q) must be single characters", path, i, p.A, p.B)
+		}
+		t.Pairs[i].A = strings.ToUpper(p.A)
+		t.Pairs[i].B = strings.ToUpper(p.B)
+	}
+	return &t, nil
+}
+
+// ConfusionTablePath returns the path of the user's confusion table
+// override, ~/.config/pcb-tracer/ocr_confusions.json.
+func ConfusionTablePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "pcb-tracer", "ocr_confusions.json"), nil
+}
+
+var (
+	activeConfusionOnce  sync.Once
+	activeConfusionTable *ConfusionTable
+)
+
+// ActiveConfusionTable returns the user's override table if one exists at
+// ConfusionTablePath, otherwise the defaults. The file is read once.
+func ActiveConfusionTable() *ConfusionTable {
+	activeConfusionOnce.Do(func() {
+		activeConfusionTable = DefaultConfusionTable()
+		path, err := ConfusionTablePath()
+		if err != nil {
+			return
+		}
+		t, err := LoadConfusionTable(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				fmt.Printf("Warning: %v; using default OCR confusions\n", err)
+			}
+			return
+		}
+		fmt.Printf("Loaded %d OCR confusion pairs from %s\n", len(t.Pairs), path)
+		activeConfusionTable = t
+	})
+	return activeConfusionTable
+}
+
+// Same reports whether a and b are equal or a listed confusion pair.
+// Letters are compared case-insensitively.
+func (t *ConfusionTable) Same(a, b byte) bool {
+	a, b = upperByte(a), upperByte(b)
+	if a == b {
+		return true
+	}
+	for _, p := range t.Pairs {
+		if len(p.A) != 1 || len(p.B) != 1 {
+			continue
+		}
+		if (a == p.A[0] && b == p.B[0]) || (a == p.B[0] && b == p.A[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// FuzzyContains reports whether haystack contains needle with at most
+// maxErrors characters that are neither equal nor a confusion pair.
+func (t *ConfusionTable) FuzzyContains(haystack, needle string, maxErrors int) bool {
+	nLen := len(needle)
+	if nLen == 0 {
+		return true
+	}
+	if len(haystack) < nLen {
+		return false
+	}
+
+	for i := 0; i <= len(haystack)-nLen; i++ {
+		errors := 0
+		for j := 0; j < nLen; j++ {
+			if !t.Same(haystack[i+j], needle[j]) {
+				errors++
+				if errors > maxErrors {
+					break
+				}
+			}
+		}
+		if errors <= maxErrors {
+			return true
+		}
+	}
+	return false
+}
+
+// DigitLetters returns the letters that InDigits pairs map to a digit, in
+// table order.
+func (t *ConfusionTable) DigitLetters() string {
+	var sb strings.Builder
+	for _, p := range t.Pairs {
+		if l, _, ok := p.letterDigit(); ok && !strings.ContainsRune(sb.String(), l) {
+			sb.WriteRune(l)
+		}
+	}
+	return sb.String()
+}
+
+// Digit returns the digit an InDigits pair reads r as, or r unchanged.
+func (t *ConfusionTable) Digit(r rune) rune {
+	upper := unicode.ToUpper(r)
+	for _, p := range t.Pairs {
+		if l, d, ok := p.letterDigit(); ok && l == upper {
+			return d
+		}
+	}
+	return r
+}
+
+// letterDigit returns the letter and digit of an InDigits pair.
+func (p ConfusionPair) letterDigit() (letter, digit rune, ok bool) {
+	if !p.InDigits || len(p.A) != 1 || len(p.B) != 1 {
+		return 0, 0, false
+	}
+	a, b := rune(p.A[0]), rune(p.B[0])
+	switch {
+	case unicode.IsDigit(a) && unicode.IsLetter(b):
+		return b, a, true
+	case unicode.IsLetter(a) && unicode.IsDigit(b):
+		return a, b, true
+	}
+	return 0, 0, false
+}
+
+func upperByte(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
@@ -0,0 +1,42 @@
+package ocr
+
+import "testing"
+
+func TestDefaultConfusionTableFuzzyContains(t *testing.T) {
+	ct := DefaultConfusionTable()
+	cases := []struct {
+		haystack, needle string
+		maxErrors        int
+		want             bool
+	}{
+		{"MA1AYSIA", "MALAYSIA", 0, true}, // 1 <-> L
+		{"MA1AYSIA", "MALAYSIA", len("MALAYSIA") / 5, true},
+		{"MADEINMA1AYSIA", "MALAYSIA", 1, true},
+		{"5INGAP0RE", "SINGAPORE", 0, true},
+		{"MAXAYSIA", "MALAYSIA", 0, false},
+		{"MAXAYSIA", "MALAYSIA", 1, true},
+		{"JAPAN", "MALAYSIA", 1, false},
+	}
+	for _, c := range cases {
+		if got := ct.FuzzyContains(c.haystack, c.needle, c.maxErrors); got != c.want {
+			t.Errorf("FuzzyContains(%q, %q, %d) = %v, want %v", c.haystack, c.needle, c.maxErrors, got, c.want)
+		}
+	}
+}
+
+func TestConfusionTableDigits(t *testing.T) {
+	ct := DefaultConfusionTable()
+	if got := ct.DigitLetters(); got != "OSA" {
+		t.Errorf("DigitLetters() = %q, want %q", got, "OSA")
+	}
+	for in, want := range map[rune]rune{'O': '0', 'o': '0', 'S': '5', 'a': '4', 'D': 'D', '7': '7'} {
+		if got := ct.Digit(in); got != want {
+			t.Errorf("Digit(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	ct.Pairs = append(ct.Pairs, ConfusionPair{A: "B", B: "8", InDigits: true})
+	if got := ct.Digit('B'); got != '8' {
+		t.Errorf("Digit('B') with 8/B pair = %q, want '8'", got)
+	}
+}
@@ -0,0 +1,183 @@
+package ocr
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Character error kinds found by aligning detected text to ground truth.
+const (
+	ErrSubstitution = "sub" // Truth character read as a different one
+	ErrInsertion    = "ins" // Character read that is not in the truth
+	ErrDeletion     = "del" // Truth character missed entirely
+)
+
+// CharError is one kind of character error and how often it occurred.
+// Truth is 0 for insertions and Detected is 0 for deletions.
+type CharError struct {
+	Kind     string
+	Truth    rune
+	Detected rune
+	Count    int
+}
+
+// String formats the error as e.g. "O→0: 47 times".
+func (c CharError) String() string {
+	var what string
+	switch c.Kind {
+	case ErrInsertion:
+		what = fmt.Sprintf("extra %c", c.Detected)
+	case ErrDeletion:
+		what = fmt.Sprintf("missed %c", c.Truth)
+	default:
+		what = fmt.Sprintf("%c→%c", c.Truth, c.Detected)
+	}
+	return fmt.Sprintf("%s: %d times", what, c.Count)
+}
+
+// ErrorStats is a character-level accuracy report over training samples.
+type ErrorStats struct {
+	Samples       int // Samples with non-empty ground truth
+	TruthChars    int // Characters in the normalized ground truth
+	Correct       int // Truth characters read correctly
+	Substitutions int
+	Insertions    int
+	Deletions     int
+	Errors        []CharError // Most frequent first
+}
+
+// Accuracy returns the fraction of truth characters read correctly.
+func (e ErrorStats) Accuracy() float64 {
+	if e.TruthChars == 0 {
+		return 0
+	}
+	return float64(e.Correct) / float64(e.TruthChars)
+}
+
+// Top returns at most n of the most frequent errors.
+func (e ErrorStats) Top(n int) []CharError {
+	if n > len(e.Errors) {
+		n = len(e.Errors)
+	}
+	return e.Errors[:n]
+}
+
+// String returns a human-readable report listing the 20 most frequent errors.
+func (e ErrorStats) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "OCR character accuracy: %.1f%% (%d/%d characters, %d samples)\n",
+		e.Accuracy()*100, e.Correct, e.TruthChars, e.Samples)
+	fmt.Fprintf(&sb, "  Substitutions: %d  Insertions: %d  Deletions: %d\n",
+		e.Substitutions, e.Insertions, e.Deletions)
+	if len(e.Errors) > 0 {
+		sb.WriteString("  Top confusions:\n")
+		for _, c := range e.Top(20) {
+			fmt.Fprintf(&sb, "    %s\n", c)
+		}
+	}
+	return sb.String()
+}
+
+// ErrorReport aligns each sample's detected text against its ground truth
+// and tallies the character substitutions, insertions and deletions. Text
+// is normalized as for TextSimilarity, with logo markers and line breaks
+// removed.
+func (db *GlobalTrainingDB) ErrorReport() ErrorStats {
+	var stats ErrorStats
+	counts := make(map[CharError]int)
+	for _, s := range db.Samples {
+		truth := []rune(strings.ReplaceAll(normalizeText(stripLogoMarkers(s.GroundTruth)), "\n", ""))
+		if len(truth) == 0 {
+			continue
+		}
+		detected := []rune(strings.ReplaceAll(normalizeText(s.DetectedText), "\n", ""))
+		stats.Samples++
+		stats.TruthChars += len(truth)
+
+		for _, op := range alignChars(truth, detected) {
+			switch op.Kind {
+			case "":
+				stats.Correct++
+				continue
+			case ErrSubstitution:
+				stats.Substitutions++
+			case ErrInsertion:
+				stats.Insertions++
+			case ErrDeletion:
+				stats.Deletions++
+			}
+			counts[op]++
+		}
+	}
+
+	for c, n := range counts {
+		c.Count = n
+		stats.Errors = append(stats.Errors, c)
+	}
+	sort.Slice(stats.Errors, func(i, j int) bool {
+		a, b := stats.Errors[i], stats.Errors[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Truth != b.Truth {
+			return a.Truth < b.Truth
+		}
+		return a.Detected < b.Detected
+	})
+	return stats
+}
+
+// DiffText aligns detected text against truth with the same normalization
+// and alignment as ErrorReport, but keeps line breaks, and returns the
+// operations in reading order. Matches have an empty Kind.
+func DiffText(truth, detected string) []CharError {
+	ops := alignChars([]rune(normalizeText(stripLogoMarkers(truth))), []rune(normalizeText(detected)))
+	slices.Reverse(ops)
+	return ops
+}
+
+// alignChars returns the edit operations of a minimum-cost Levenshtein
+// alignment turning truth into detected. Matches have an empty Kind.
+func alignChars(truth, detected []rune) []CharError {
+	m, n := len(truth), len(detected)
+	dist := make([][]int, m+1)
+	for i := range dist {
+		dist[i] = make([]int, n+1)
+		dist[i][0] = i
+	}
+	for j := 0; j <= n; j++ {
+		dist[0][j] = j
+	}
+	for i := 1; i <= m; i++ {
+		for j := 1; j <= n; j++ {
+			cost := 1
+			if truth[i-1] == detected[j-1] {
+				cost = 0
+			}
+			dist[i][j] = min(dist[i-1][j-1]+cost, dist[i-1][j]+1, dist[i][j-1]+1)
+		}
+	}
+
+	// Walk back from the end, preferring match/substitution
+	var ops []CharError
+	i, j := m, n
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && truth[i-1] == detected[j-1] && dist[i][j] == dist[i-1][j-1]:
+			ops = append(ops, CharError{Truth: truth[i-1], Detected: detected[j-1]})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && dist[i][j] == dist[i-1][j-1]+1:
+			ops = append(ops, CharError{Kind: ErrSubstitution, Truth: truth[i-1], Detected: detected[j-1]})
+			i, j = i-1, j-1
+		case i > 0 && dist[i][j] == dist[i-1][j]+1:
+			ops = append(ops, CharError{Kind: ErrDeletion, Truth: truth[i-1]})
+			i--
+		default:
+			ops = append(ops, CharError{Kind: ErrInsertion, Detected: detected[j-1]})
+			j--
+		}
+	}
+	return ops
+}
@@ -0,0 +1,69 @@
+package ocr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorReport(t *testing.T) {
+	db := &GlobalTrainingDB{Samples: []GlobalTrainingSample{
+		{GroundTruth: "<TI>SN74LS00N", DetectedText: "SN74L500N"}, // Substitution; logo marker dropped
+		{GroundTruth: "MC68000\nP8", DetectedText: "MC6B000P"},    // Substitution and a deletion
+		{GroundTruth: "AM27C256", DetectedText: "am27-cc256"},     // Insertion; case and punctuation ignored
+		{GroundTruth: "", DetectedText: "NOISE"},                  // No truth, skipped
+		{GroundTruth: "7805", DetectedText: "7B05"},               // Substitution
+	}}
+	stats := db.ErrorReport()
+
+	if stats.Samples != 4 || stats.TruthChars != 30 || stats.Correct != 26 {
+		t.Errorf("samples %d, truth chars %d, correct %d; want 4, 30, 26", stats.Samples, stats.TruthChars, stats.Correct)
+	}
+	if stats.Substitutions != 3 || stats.Insertions != 1 || stats.Deletions != 1 {
+		t.Errorf("sub/ins/del = %d/%d/%d, want 3/1/1", stats.Substitutions, stats.Insertions, stats.Deletions)
+	}
+	// Most frequent first, then by truth character
+	want := []string{"8→B: 2 times", "extra C: 1 times", "missed 8: 1 times", "S→5: 1 times"}
+	if len(stats.Errors) != len(want) {
+		t.Fatalf("errors %v, want %v", stats.Errors, want)
+	}
+	for i, e := range stats.Errors {
+		if e.String() != want[i] {
+			t.Errorf("error %d = %q, want %q", i, e, want[i])
+		}
+	}
+	if got := stats.Top(2); len(got) != 2 || got[0] != stats.Errors[0] {
+		t.Errorf("Top(2) = %v", got)
+	}
+	if len(stats.Top(10)) != 4 {
+		t.Errorf("Top(10) returned %d errors, want 4", len(stats.Top(10)))
+	}
+	if !strings.HasPrefix(stats.String(), "OCR character accuracy: 86.7% (26/30 characters, 4 samples)") {
+		t.Errorf("String() = %q", stats.String())
+	}
+
+	if empty := (&GlobalTrainingDB{}).ErrorReport(); empty.Accuracy() != 0 || len(empty.Errors) != 0 {
+		t.Errorf("empty report: accuracy %v, %d errors", empty.Accuracy(), len(empty.Errors))
+	}
+}
+
+func TestDiffText(t *testing.T) {
+	// Reading order, keeping line breaks
+	ops := DiffText("74LS\n08", "74l5\n0")
+	var got []string
+	for _, op := range ops {
+		switch op.Kind {
+		case "":
+			got = append(got, string(op.Truth))
+		case ErrDeletion:
+			got = append(got, "-"+string(op.Truth))
+		case ErrInsertion:
+			got = append(got, "+"+string(op.Detected))
+		default:
+			got = append(got, string(op.Truth)+">"+string(op.Detected))
+		}
+	}
+	want := "7 4 L S>5 \n 0 -8"
+	if strings.Join(got, " ") != want {
+		t.Errorf("DiffText ops %q, want %q", strings.Join(got, " "), want)
+	}
+}
@@ -0,0 +1,243 @@
+package ocr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultOtsuTrim is the number of regions RobustOtsu drops from each end
+// of the brightness order, so a bright sticker or a dark shadow doesn't
+// skew the threshold.
+const DefaultOtsuTrim = 2
+
+// otsuMinWhiteRatio is the share of white pixels below which RobustOtsu
+// lowers its threshold, as for faded markings.
+const otsuMinWhiteRatio = 0.10
+
+// otsuLostWhiteRatio is the share of white pixels at the plain Otsu
+// threshold below which BinarizeThreshold takes the text to be lost, as
+// with badly faded light-on-dark markings, and keeps the brightest pixels
+// instead.
+const otsuLostWhiteRatio = 0.01
+
+// fallbackBrightestPercent is the brightest-percent threshold
+// BinarizeThreshold falls back to when params don't set their own.
+const fallbackBrightestPercent = 5.0
+
+// OtsuRegion is one cell of the RobustOtsu grid.
+type OtsuRegion struct {
+	Col, Row int
+	Mean     float64
+	Used     bool // False if trimmed as one of the darkest or brightest
+}
+
+// OtsuResult is the outcome of RobustOtsu, kept for inspection.
+type OtsuResult struct {
+	Threshold  uint8
+	Otsu       uint8 // Threshold before the faded-text adjustment
+	Cols, Rows int
+	Trim       int
+	Regions    []OtsuRegion // In brightness order, darkest first
+	WhiteRatio float64      // Share of white pixels in the used regions at Threshold
+	OtsuWhite  float64      // Share of white pixels in the used regions at Otsu
+}
+
+// Adjusted reports whether the threshold was lowered for faded text.
+func (r OtsuResult) Adjusted() bool {
+	return r.Threshold != r.Otsu
+}
+
+// String summarizes the grid, region means (x marks trimmed regions) and
+// threshold on one line.
+func (r OtsuResult) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Otsu %d (%dx%d trim %d", r.Threshold, r.Cols, r.Rows, r.Trim)
+	if r.Adjusted() {
+		fmt.Fprintf(&sb, ", faded from %d", r.Otsu)
+	}
+	sb.WriteString("), means")
+	for _, reg := range r.Regions {
+		mark := ""
+		if !reg.Used {
+			mark = "x"
+		}
+		fmt.Fprintf(&sb, " %.0f%s", reg.Mean, mark)
+	}
+	return sb.String()
+}
+
+// OtsuGrid returns the region grid and trim RobustOtsu uses for a w×h
+// image. Params that leave them zero get a grid shaped like the image:
+// 2×4 for tall crops such as DIPs, 4×2 for wide ones and 3×3 for roughly
+// square ones such as SOICs, with DefaultOtsuTrim. The trim is reduced if
+// it would leave no region.
+func (p OCRParams) OtsuGrid(w, h int) (cols, rows, trim int) {
+	cols, rows = p.OtsuGridCols, p.OtsuGridRows
+	if cols <= 0 || rows <= 0 {
+		switch aspect := float64(w) / float64(max(h, 1)); {
+		case aspect < 0.75:
+			cols, rows = 2, 4
+		case aspect > 1.0/0.75:
+			cols, rows = 4, 2
+		default:
+			cols, rows = 3, 3
+		}
+	}
+	trim = p.OtsuTrim
+	if trim <= 0 {
+		trim = DefaultOtsuTrim
+	}
+	trim = min(trim, (cols*rows-1)/2)
+	return cols, rows, trim
+}
+
+// RobustOtsu computes an Otsu threshold for a w×h grayscale image that
+// resists small bright or dark artifacts. The image is split into the grid
+// from params.OtsuGrid, the darkest and brightest regions are trimmed, and
+// Otsu runs on the histogram of the rest. If that leaves less than 10% of
+// the used pixels white, the threshold is lowered until it doesn't.
+func RobustOtsu(gray []uint8, w, h int, params OCRParams) OtsuResult {
+	cols, rows, trim := params.OtsuGrid(w, h)
+	type region struct {
+		OtsuRegion
+		hist [256]int
+		n    int
+	}
+	regions := make([]region, cols*rows)
+
+	for ry := 0; ry < rows; ry++ {
+		y0 := ry * h / rows
+		y1 := (ry + 1) * h / rows
+		for rx := 0; rx < cols; rx++ {
+			x0 := rx * w / cols
+			x1 := (rx + 1) * w / cols
+			r := &regions[ry*cols+rx]
+			r.Col, r.Row = rx, ry
+			var sum int64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					v := gray[y*w+x]
+					r.hist[v]++
+					sum += int64(v)
+					r.n++
+				}
+			}
+			if r.n > 0 {
+				r.Mean = float64(sum) / float64(r.n)
+			}
+		}
+	}
+
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].Mean < regions[j].Mean
+	})
+
+	var hist [256]int
+	total := 0
+	for i := range regions {
+		r := &regions[i]
+		if i < trim || i >= len(regions)-trim {
+			continue
+		}
+		r.Used = true
+		for v := 0; v < 256; v++ {
+			hist[v] += r.hist[v]
+		}
+		total += r.n
+	}
+
+	result := OtsuResult{Cols: cols, Rows: rows, Trim: trim}
+	for _, r := range regions {
+		result.Regions = append(result.Regions, r.OtsuRegion)
+	}
+
+	var sum float64
+	for i := 0; i < 256; i++ {
+		sum += float64(i) * float64(hist[i])
+	}
+	var sumB float64
+	var wB, wF int
+	var maxVar float64
+	thresh := uint8(128)
+	for t := 0; t < 256; t++ {
+		wB += hist[t]
+		if wB == 0 {
+			continue
+		}
+		wF = total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += float64(t) * float64(hist[t])
+		mB := sumB / float64(wB)
+		mF := (sum - sumB) / float64(wF)
+		variance := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
+		if variance > maxVar {
+			maxVar = variance
+			thresh = uint8(t)
+		}
+	}
+	result.Otsu = thresh
+
+	whiteRatio := func(t uint8) float64 {
+		if total == 0 {
+			return 0
+		}
+		n := 0
+		for i := int(t) + 1; i < 256; i++ {
+			n += hist[i]
+		}
+		return float64(n) / float64(total)
+	}
+
+	result.OtsuWhite = whiteRatio(thresh)
+	if total > 0 && result.OtsuWhite < otsuMinWhiteRatio {
+		lo, hi := uint8(0), thresh
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if whiteRatio(mid) < otsuMinWhiteRatio {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		if lo > 0 {
+			lo--
+		}
+		thresh = lo
+	}
+	result.Threshold = thresh
+	result.WhiteRatio = whiteRatio(thresh)
+	return result
+}
+
+// BinarizeThreshold returns the gray level to binarize a w×h component crop
+// at and a description of the method for logs. Params that select the
+// histogram method (see OCRParams.UsesHistogram) keep the brightest percent
+// of pixels; anything else uses RobustOtsu. When plain Otsu left almost
+// nothing white, as happens with faded markings, the lowered threshold is
+// mostly background, so the brightest percent (params', or 5%) is kept
+// instead.
+func BinarizeThreshold(gray []uint8, w, h int, params OCRParams) (uint8, string) {
+	if params.UsesHistogram() {
+		thresh := HistogramThreshold(gray, params.BrightestPercent, params.MinThreshold)
+		return thresh, fmt.Sprintf("brightest %v%% (%d)", params.BrightestPercent, thresh)
+	}
+
+	otsu := RobustOtsu(gray, w, h, params)
+	if !otsu.Adjusted() || otsu.OtsuWhite >= otsuLostWhiteRatio {
+		return otsu.Threshold, otsu.String()
+	}
+
+	pct := params.BrightestPercent
+	if pct <= 0 {
+		pct = fallbackBrightestPercent
+	}
+	// No minimum: faded markings are exactly the ones below it
+	hist := HistogramThreshold(gray, pct, 0)
+	if hist <= otsu.Threshold {
+		return otsu.Threshold, otsu.String()
+	}
+	return hist, fmt.Sprintf("brightest %v%% (%d, Otsu %d left %.1f%% white)", pct, hist, otsu.Otsu, otsu.OtsuWhite*100)
+}
@@ -0,0 +1,118 @@
+package ocr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOCRParamsOtsuGrid(t *testing.T) {
+	cases := []struct {
+		params           OCRParams
+		w, h             int
+		cols, rows, trim int
+	}{
+		{OCRParams{}, 100, 300, 2, 4, 2}, // Tall DIP
+		{OCRParams{}, 300, 100, 4, 2, 2}, // Wide
+		{OCRParams{}, 120, 100, 3, 3, 2}, // Square SOIC
+		{OCRParams{OtsuGridCols: 3, OtsuGridRows: 1, OtsuTrim: 2}, 100, 300, 3, 1, 1},
+		{OCRParams{OtsuGridCols: 2, OtsuGridRows: 2, OtsuTrim: 1}, 300, 100, 2, 2, 1},
+	}
+	for _, c := range cases {
+		cols, rows, trim := c.params.OtsuGrid(c.w, c.h)
+		if cols != c.cols || rows != c.rows || trim != c.trim {
+			t.Errorf("OtsuGrid(%d, %d) with %+v = %dx%d trim %d, want %dx%d trim %d",
+				c.w, c.h, c.params, cols, rows, trim, c.cols, c.rows, c.trim)
+		}
+	}
+}
+
+func TestRobustOtsuTrimsOutliers(t *testing.T) {
+	// 30x30 dark body with light text stripes and a bright sticker in the
+	// top-left cell of the 3x3 grid
+	const w, h = 30, 30
+	gray := make([]uint8, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(30)
+			if y%5 == 0 {
+				v = 150
+			}
+			if x < 10 && y < 10 {
+				v = 255
+			}
+			gray[y*w+x] = v
+		}
+	}
+
+	r := RobustOtsu(gray, w, h, OCRParams{})
+	if r.Cols != 3 || r.Rows != 3 || len(r.Regions) != 9 {
+		t.Fatalf("grid = %dx%d with %d regions, want 3x3 with 9", r.Cols, r.Rows, len(r.Regions))
+	}
+	brightest := r.Regions[len(r.Regions)-1]
+	if brightest.Col != 0 || brightest.Row != 0 || brightest.Used {
+		t.Errorf("brightest region = %+v, want trimmed cell (0,0)", brightest)
+	}
+	if r.Threshold < 30 || r.Threshold >= 150 {
+		t.Errorf("Threshold = %d, want between body (30) and text (150)", r.Threshold)
+	}
+}
+
+func TestBinarizeThresholdFadedText(t *testing.T) {
+	// 60x60 uniform dark body with faint text dots on 0.5% of the pixels:
+	// Otsu separates them, but leaves too little white, and RobustOtsu's
+	// lowered threshold would turn the whole body white
+	const w, h = 60, 60
+	gray := make([]uint8, w*h)
+	text := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gray[y*w+x] = 40
+			if x%10 == 5 && y%20 == 5 {
+				gray[y*w+x] = 70
+				text++
+			}
+		}
+	}
+
+	otsu := RobustOtsu(gray, w, h, OCRParams{})
+	if !otsu.Adjusted() || otsu.Threshold >= 40 {
+		t.Fatalf("RobustOtsu = %d (Otsu %d), want lowered below the body", otsu.Threshold, otsu.Otsu)
+	}
+
+	thresh, method := BinarizeThreshold(gray, w, h, OCRParams{})
+	white := 0
+	for _, v := range gray {
+		if v > thresh {
+			white++
+		}
+	}
+	if white != text {
+		t.Errorf("threshold %d (%s) leaves %d white pixels, want the %d text pixels", thresh, method, white, text)
+	}
+	if !strings.HasPrefix(method, "brightest") {
+		t.Errorf("method = %q, want brightest-percent fallback", method)
+	}
+}
+
+func TestBinarizeThresholdKeepsOtsu(t *testing.T) {
+	// Crisp text on a fifth of the pixels: plain Otsu is right
+	const w, h = 60, 60
+	gray := make([]uint8, w*h)
+	for i := range gray {
+		gray[i] = 40
+		if i%5 == 0 {
+			gray[i] = 200
+		}
+	}
+	otsu := RobustOtsu(gray, w, h, OCRParams{})
+	thresh, method := BinarizeThreshold(gray, w, h, OCRParams{})
+	if otsu.Adjusted() || thresh != otsu.Threshold || method != otsu.String() {
+		t.Errorf("BinarizeThreshold = %d (%s), want RobustOtsu %d", thresh, method, otsu.Threshold)
+	}
+
+	// Histogram params bypass Otsu
+	params := OCRParams{BrightestPercent: 20}
+	if thresh, _ := BinarizeThreshold(gray, w, h, params); thresh != HistogramThreshold(gray, 20, 0) {
+		t.Errorf("histogram params: threshold %d, want HistogramThreshold", thresh)
+	}
+}
@@ -0,0 +1,113 @@
+package ocr
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/otiai10/gosseract/v2"
+)
+
+// EnginePool keeps warm Engines for reuse. Tesseract initialization costs
+// far more than a single recognition, so short OCR calls and batch workers
+// should acquire engines from a pool instead of calling NewEngine/Close
+// each time. Acquire and Release are safe for concurrent use; an acquired
+// Engine still belongs to one goroutine until it is released.
+type EnginePool struct {
+	mu     sync.Mutex
+	idle   []*Engine
+	size   int // Idle engines kept; extra releases are closed
+	closed bool
+}
+
+// NewEnginePool returns a pool that keeps up to size idle engines. Engines
+// are created on demand, so an unused pool costs nothing.
+func NewEnginePool(size int) *EnginePool {
+	return &EnginePool{size: max(size, 1)}
+}
+
+var sharedPool = NewEnginePool(runtime.NumCPU())
+
+// SharedEnginePool returns the process-wide pool, sized to the CPU count
+// so every parallel worker can keep a warm engine.
+func SharedEnginePool() *EnginePool {
+	return sharedPool
+}
+
+// Acquire returns an idle engine, or a new one if none is idle. The engine
+// has NewEngine's settings apart from the engine mode, which is left as the
+// previous user set it; pass it back with Release when done.
+func (p *EnginePool) Acquire() (*Engine, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		e := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return e, nil
+	}
+	p.mu.Unlock()
+	return NewEngine()
+}
+
+// Release resets e's per-call settings and returns it to the pool, or
+// closes it if the pool is full or closed. e must not be used afterwards.
+// A nil e is ignored.
+func (p *EnginePool) Release(e *Engine) {
+	if e == nil {
+		return
+	}
+	if err := e.reset(); err != nil {
+		e.Close()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed || len(p.idle) >= p.size {
+		p.mu.Unlock()
+		e.Close()
+		return
+	}
+	p.idle = append(p.idle, e)
+	p.mu.Unlock()
+}
+
+// Close closes the idle engines. Engines released afterwards are closed
+// instead of pooled, and Acquire still works but no longer reuses engines.
+func (p *EnginePool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	var firstErr error
+	for _, e := range idle {
+		if err := e.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// reset restores the settings callers change between recognitions
+// (character set mode, whitelist, page segmentation, variables) to their
+// NewEngine defaults, so one caller's parameters don't leak to the next.
+// The engine mode is kept: changing it makes Tesseract re-initialize on the
+// next recognition, which is the cost the pool exists to avoid. Callers
+// that need a particular OEM set it, as RecognizeWithParams does, and that
+// is free when the pooled engine already has it.
+func (e *Engine) reset() error {
+	e.electronicsMode = true
+	if err := e.client.SetWhitelist(""); err != nil {
+		return err
+	}
+	// PSM_SINGLE_BLOCK is the Tesseract API default
+	if err := e.client.SetPageSegMode(gosseract.PSM_SINGLE_BLOCK); err != nil {
+		return err
+	}
+	for name, value := range engineVariables {
+		if err := e.client.SetVariable(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
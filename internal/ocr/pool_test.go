@@ -0,0 +1,44 @@
+package ocr
+
+import "testing"
+
+// TestEnginePoolKeepsEngineWarm acquires an engine, switches it to LSTM as
+// RecognizeWithParams does, releases it and acquires again: the same
+// engine comes back still in LSTM mode, so asking for LSTM again loads no
+// config and Tesseract is not re-initialized.
+func TestEnginePoolKeepsEngineWarm(t *testing.T) {
+	pool := NewEnginePool(1)
+	defer pool.Close()
+
+	e, err := pool.Acquire()
+	if err != nil {
+		t.Skipf("no Tesseract engine: %v", err)
+	}
+	if err := e.setEngineMode(OEMLSTM); err != nil {
+		t.Fatal(err)
+	}
+	e.SetElectronicsMode(false)
+	loads := e.configLoads
+	pool.Release(e)
+
+	again, err := pool.Acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Release(again)
+	if again != e {
+		t.Fatal("pool created a new engine instead of reusing the released one")
+	}
+	if again.oem != OEMLSTM {
+		t.Errorf("released engine mode %v, want LSTM kept", again.oem)
+	}
+	if !again.electronicsMode {
+		t.Error("electronics mode leaked across release")
+	}
+	if err := again.setEngineMode(OEMLSTM); err != nil {
+		t.Fatal(err)
+	}
+	if again.configLoads != loads {
+		t.Errorf("%d config loads after reacquiring, want %d (no re-init)", again.configLoads, loads)
+	}
+}
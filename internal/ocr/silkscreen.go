@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	pcbimage "pcb-tracer/internal/image"
 	"pcb-tracer/pkg/geometry"
 
 	"github.com/otiai10/gosseract/v2"
@@ -21,6 +22,7 @@ type ComponentDesignator struct {
 	Number   int              // Component number (e.g., 32)
 	Bounds   geometry.RectInt // Location in image
 	Rotation int              // Rotation at which it was detected (0, 90, 180, 270)
+	Side     pcbimage.Side    // Board side the label was read from
 }
 
 // CoordinateMarker represents a grid coordinate (A, B, C... or 1, 2, 3...).
@@ -46,6 +48,17 @@ type SilkscreenResult struct {
 	XAxis       *CoordinateAxis // Horizontal axis (usually numbers)
 	YAxis       *CoordinateAxis // Vertical axis (usually letters)
 	AllText     []Result        // All detected text
+	Side        pcbimage.Side   // Board side the image came from
+}
+
+// TagSide records the board side on the result and each designator.
+// The back image is mirrored when it is loaded, so back-side text reads the
+// same way as the front and needs no other handling.
+func (r *SilkscreenResult) TagSide(side pcbimage.Side) {
+	r.Side = side
+	for i := range r.Designators {
+		r.Designators[i].Side = side
+	}
 }
 
 // Regex patterns for component designators
@@ -91,6 +104,7 @@ func (e *Engine) DetectSilkscreen(img gocv.Mat) (*SilkscreenResult, error) {
 		// Adjust bounds back to original orientation
 		for i := range texts {
 			texts[i].Bounds = unrotateRect(texts[i].Bounds, rotation, whiteText.Cols(), whiteText.Rows())
+			texts[i].Rotation = rotation
 		}
 
 		// Tag results with rotation
@@ -111,6 +125,29 @@ func (e *Engine) DetectSilkscreen(img gocv.Mat) (*SilkscreenResult, error) {
 		}
 	}
 
+	// Per-region orientation trial: whole-image passes miss short sideways
+	// labels near the board edges, so read each text blob at all 4 rotations
+	// and keep the best-scoring reading.
+	regionTexts := e.detectRegionsWithOrientation(whiteText)
+	fmt.Printf("  Per-region orientation pass found %d candidates\n", len(regionTexts))
+	for _, t := range regionTexts {
+		if isDuplicateResult(t, allResults, 30) {
+			continue
+		}
+		if matches := designatorPattern.FindStringSubmatch(t.Text); matches != nil {
+			var num int
+			fmt.Sscanf(matches[2], "%d", &num)
+			result.Designators = append(result.Designators, ComponentDesignator{
+				Text:     t.Text,
+				Prefix:   matches[1],
+				Number:   num,
+				Bounds:   t.Bounds,
+				Rotation: t.Rotation,
+			})
+		}
+		allResults = append(allResults, t)
+	}
+
 	// Explicitly detect single characters (A-Z, 0-9) for coordinate grid
 	// This catches markers that PSM_SPARSE_TEXT misses
 	singleChars := e.detectSingleCharacters(whiteText)
@@ -123,6 +160,25 @@ func (e *Engine) DetectSilkscreen(img gocv.Mat) (*SilkscreenResult, error) {
 		}
 	}
 
+	// Rejoin designators that OCR split at the letter/number boundary
+	// ("U" + "12"), so they are counted instead of read as grid markers
+	allResults = mergeSplitDesignators(allResults)
+	for _, t := range allResults {
+		matches := designatorPattern.FindStringSubmatch(t.Text)
+		if matches == nil || hasDesignatorAt(result.Designators, t, 30) {
+			continue
+		}
+		var num int
+		fmt.Sscanf(matches[2], "%d", &num)
+		result.Designators = append(result.Designators, ComponentDesignator{
+			Text:     t.Text,
+			Prefix:   matches[1],
+			Number:   num,
+			Bounds:   t.Bounds,
+			Rotation: t.Rotation,
+		})
+	}
+
 	result.AllText = allResults
 
 	// Find coordinate axes from single letters/numbers
@@ -278,6 +334,145 @@ func (e *Engine) detectTextInImage(img gocv.Mat) ([]Result, error) {
 	return results, nil
 }
 
+// detectRegionsWithOrientation groups white silkscreen blobs into word-sized
+// regions and OCRs each one at 0/90/180/270 degrees, keeping the reading with
+// the best score. A reading that parses as a component designator outranks
+// any that does not. Bounds are in img coordinates; Rotation records the
+// orientation that produced the reading.
+func (e *Engine) detectRegionsWithOrientation(img gocv.Mat) []Result {
+	gray := gocv.NewMat()
+	gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
+	defer gray.Close()
+
+	// Merge the characters of a label into one blob, in either direction
+	merged := gocv.NewMat()
+	defer merged.Close()
+	kernel := gocv.GetStructuringElement(gocv.MorphRect, image.Pt(9, 9))
+	defer kernel.Close()
+	gocv.Dilate(gray, &merged, kernel)
+
+	contours := gocv.FindContours(merged, gocv.RetrievalExternal, gocv.ChainApproxSimple)
+	defer contours.Close()
+
+	// Designator-sized regions: a few characters tall text, either orientation
+	const minTextSize = 10
+	const maxTextLong = 300
+	const maxTextShort = 100
+
+	var results []Result
+	for i := 0; i < contours.Size(); i++ {
+		rect := gocv.BoundingRect(contours.At(i))
+		long, short := max(rect.Dx(), rect.Dy()), min(rect.Dx(), rect.Dy())
+		if short < minTextSize || short > maxTextShort || long > maxTextLong {
+			continue
+		}
+
+		pad := 6
+		x1 := max(0, rect.Min.X-pad)
+		y1 := max(0, rect.Min.Y-pad)
+		x2 := min(img.Cols(), rect.Max.X+pad)
+		y2 := min(img.Rows(), rect.Max.Y+pad)
+		region := img.Region(image.Rect(x1, y1, x2, y2))
+
+		best, ok := e.bestOrientationReading(region)
+		region.Close()
+		if !ok {
+			continue
+		}
+		best.Bounds = geometry.RectInt{
+			X: rect.Min.X, Y: rect.Min.Y, Width: rect.Dx(), Height: rect.Dy(),
+		}
+		results = append(results, best)
+	}
+	return results
+}
+
+// bestOrientationReading OCRs a small region at all four rotations and returns
+// the best reading, as chosen by pickOrientation.
+func (e *Engine) bestOrientationReading(region gocv.Mat) (Result, bool) {
+	var readings []Result
+	for _, rotation := range []int{0, 90, 180, 270} {
+		rotated := rotateImage(region, rotation)
+		text, conf := e.ocrWordRegion(rotated)
+		rotated.Close()
+		readings = append(readings, Result{Text: text, Confidence: conf, Rotation: rotation})
+	}
+	return pickOrientation(readings)
+}
+
+// pickOrientation returns the best of one region's readings at different
+// rotations. Designator matches win; ties go to higher confidence, then to
+// the earlier reading. Empty readings are skipped; ok is false if all are.
+func pickOrientation(readings []Result) (best Result, ok bool) {
+	bestScore := -1.0
+	for _, r := range readings {
+		if r.Text == "" {
+			continue
+		}
+		score := r.Confidence
+		if designatorPattern.MatchString(r.Text) {
+			score += 100
+		}
+		if score > bestScore {
+			bestScore = score
+			best = r
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// ocrWordRegion runs single-word OCR on a white-on-black region and returns
+// the highest-confidence word.
+func (e *Engine) ocrWordRegion(region gocv.Mat) (string, float64) {
+	// Scale up small regions for better OCR
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	minDim := min(region.Rows(), region.Cols())
+	if minDim < 40 {
+		scale := 40.0 / float64(minDim)
+		gocv.Resize(region, &scaled, image.Point{}, scale, scale, gocv.InterpolationCubic)
+	} else {
+		region.CopyTo(&scaled)
+	}
+
+	inverted := gocv.NewMat()
+	defer inverted.Close()
+	gocv.BitwiseNot(scaled, &inverted)
+
+	buf, err := gocv.IMEncode(gocv.PNGFileExt, inverted)
+	if err != nil {
+		return "", 0
+	}
+	defer buf.Close()
+
+	if err := e.client.SetPageSegMode(gosseract.PSM_SINGLE_WORD); err != nil {
+		return "", 0
+	}
+	if err := e.client.SetWhitelist(ElectronicsChars); err != nil {
+		return "", 0
+	}
+	if err := e.client.SetImageFromBytes(buf.GetBytes()); err != nil {
+		return "", 0
+	}
+
+	boxes, err := e.client.GetBoundingBoxes(gosseract.RIL_WORD)
+	if err != nil {
+		return "", 0
+	}
+	var text string
+	var conf float64
+	for _, box := range boxes {
+		word := strings.TrimSpace(strings.ToUpper(box.Word))
+		if word == "" || len(word) > 10 || box.Confidence < 30 {
+			continue
+		}
+		if box.Confidence > conf {
+			text, conf = word, box.Confidence
+		}
+	}
+	return text, conf
+}
+
 // detectSingleCharacters finds isolated white blobs and runs single-char OCR on each.
 // This catches coordinate markers (A, B, C, 1, 2, 3...) that word-level OCR misses.
 func (e *Engine) detectSingleCharacters(img gocv.Mat) []Result {
@@ -438,6 +633,123 @@ func isDuplicateResult(r Result, existing []Result, threshold int) bool {
 }
 
 
+// hasDesignatorAt reports whether designators already holds t's text at
+// roughly t's position.
+func hasDesignatorAt(designators []ComponentDesignator, t Result, threshold int) bool {
+	for _, d := range designators {
+		if d.Text != t.Text {
+			continue
+		}
+		dx, dy := d.Bounds.X-t.Bounds.X, d.Bounds.Y-t.Bounds.Y
+		if dx > -threshold && dx < threshold && dy > -threshold && dy < threshold {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// Lone designator prefix or number, as left behind by a split designator
+	designatorPrefixPattern = regexp.MustCompile(`^[CRUQDLJTPYKX]$`)
+	designatorNumberPattern = regexp.MustCompile(`^\d+$`)
+)
+
+// Split-designator merge tolerances, as fractions of the text height.
+const (
+	splitMaxGap      = 0.8  // widest letter-to-number gap
+	splitMaxOverlap  = 0.3  // tokens may overlap this much along the line
+	splitMaxBaseline = 0.25 // largest baseline offset
+)
+
+// readingSpan is a bounding box in the frame the text was read in: the
+// text runs along +start→end, and top/bottom lie across the line with the
+// baseline at bottom. Coordinates are only comparable at equal rotation.
+type readingSpan struct {
+	start, end  int
+	top, bottom int
+}
+
+// toReadingSpan maps original-image bounds read at rotation into the
+// reading frame (see unrotateRect for the forward mapping).
+func toReadingSpan(b geometry.RectInt, rotation int) readingSpan {
+	switch rotation {
+	case 90:
+		return readingSpan{start: b.Y, end: b.Y + b.Height, top: -(b.X + b.Width), bottom: -b.X}
+	case 180:
+		return readingSpan{start: -(b.X + b.Width), end: -b.X, top: -(b.Y + b.Height), bottom: -b.Y}
+	case 270:
+		return readingSpan{start: -(b.Y + b.Height), end: -b.Y, top: b.X, bottom: b.X + b.Width}
+	}
+	return readingSpan{start: b.X, end: b.X + b.Width, top: b.Y, bottom: b.Y + b.Height}
+}
+
+// unionRect returns the smallest rectangle containing a and b.
+func unionRect(a, b geometry.RectInt) geometry.RectInt {
+	x0, y0 := min(a.X, b.X), min(a.Y, b.Y)
+	x1, y1 := max(a.X+a.Width, b.X+b.Width), max(a.Y+a.Height, b.Y+b.Height)
+	return geometry.RectInt{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// mergeSplitDesignators joins a lone prefix letter and the number token
+// that directly follows it on the same baseline into one designator with
+// the union bounds, e.g. "U" + "12" -> "U12". Each letter takes the
+// nearest qualifying number; merged tokens replace their parts, and all
+// other results are returned unchanged.
+func mergeSplitDesignators(results []Result) []Result {
+	used := make([]bool, len(results))
+	var merged []Result
+
+	for i, letter := range results {
+		if used[i] || !designatorPrefixPattern.MatchString(letter.Text) {
+			continue
+		}
+		ls := toReadingSpan(letter.Bounds, letter.Rotation)
+
+		best, bestGap := -1, 0
+		for j, num := range results {
+			if used[j] || num.Rotation != letter.Rotation ||
+				!designatorNumberPattern.MatchString(num.Text) {
+				continue
+			}
+			ns := toReadingSpan(num.Bounds, num.Rotation)
+			h := float64(max(ls.bottom-ls.top, ns.bottom-ns.top))
+			gap := ns.start - ls.end
+			if float64(gap) > splitMaxGap*h || float64(-gap) > splitMaxOverlap*h {
+				continue
+			}
+			if baseline := ns.bottom - ls.bottom; float64(max(baseline, -baseline)) > splitMaxBaseline*h {
+				continue
+			}
+			if best < 0 || max(gap, -gap) < max(bestGap, -bestGap) {
+				best, bestGap = j, gap
+			}
+		}
+		if best < 0 {
+			continue
+		}
+
+		num := results[best]
+		used[i], used[best] = true, true
+		merged = append(merged, Result{
+			Text:       letter.Text + num.Text,
+			Bounds:     unionRect(letter.Bounds, num.Bounds),
+			Confidence: min(letter.Confidence, num.Confidence),
+			Rotation:   letter.Rotation,
+		})
+	}
+
+	if len(merged) == 0 {
+		return results
+	}
+	out := make([]Result, 0, len(results)-len(merged))
+	for i, r := range results {
+		if !used[i] {
+			out = append(out, r)
+		}
+	}
+	return append(out, merged...)
+}
+
 // findCoordinateAxes looks for A,B,C,D... or 1,2,3,4... patterns along edges.
 func findCoordinateAxes(results []Result, imgW, imgH int) (*CoordinateAxis, *CoordinateAxis) {
 	var letters []CoordinateMarker
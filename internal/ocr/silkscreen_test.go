@@ -0,0 +1,103 @@
+package ocr
+
+import (
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestMergeSplitDesignators(t *testing.T) {
+	rect := func(x, y, w, h int) geometry.RectInt { return geometry.RectInt{X: x, Y: y, Width: w, Height: h} }
+	for _, tc := range []struct {
+		name    string
+		results []Result
+		want    map[string]geometry.RectInt
+	}{
+		{
+			name: "adjacent",
+			results: []Result{
+				{Text: "U", Bounds: rect(100, 50, 20, 30)},
+				{Text: "12", Bounds: rect(122, 50, 40, 30)},
+			},
+			want: map[string]geometry.RectInt{"U12": rect(100, 50, 62, 30)},
+		},
+		{
+			// The overlapping number is 8px from the letter, the following
+			// one 2px: the nearer one wins, not the most overlapped
+			name: "nearest by distance",
+			results: []Result{
+				{Text: "R", Bounds: rect(100, 50, 20, 30)},
+				{Text: "7", Bounds: rect(112, 50, 15, 30)},
+				{Text: "33", Bounds: rect(122, 50, 30, 30)},
+			},
+			want: map[string]geometry.RectInt{"R33": rect(100, 50, 52, 30), "7": rect(112, 50, 15, 30)},
+		},
+		{
+			name: "rotated 90",
+			results: []Result{
+				{Text: "C", Bounds: rect(50, 100, 30, 20), Rotation: 90},
+				{Text: "4", Bounds: rect(50, 122, 30, 20), Rotation: 90},
+			},
+			want: map[string]geometry.RectInt{"C4": rect(50, 100, 30, 42)},
+		},
+		{
+			name: "too far apart",
+			results: []Result{
+				{Text: "U", Bounds: rect(100, 50, 20, 30)},
+				{Text: "12", Bounds: rect(150, 50, 40, 30)},
+			},
+			want: map[string]geometry.RectInt{"U": rect(100, 50, 20, 30), "12": rect(150, 50, 40, 30)},
+		},
+		{
+			name: "different baseline",
+			results: []Result{
+				{Text: "U", Bounds: rect(100, 50, 20, 30)},
+				{Text: "12", Bounds: rect(122, 70, 40, 30)},
+			},
+			want: map[string]geometry.RectInt{"U": rect(100, 50, 20, 30), "12": rect(122, 70, 40, 30)},
+		},
+	} {
+		got := mergeSplitDesignators(tc.results)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %+v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for _, r := range got {
+			if b, ok := tc.want[r.Text]; !ok || b != r.Bounds {
+				t.Errorf("%s: got %q at %+v, want %v", tc.name, r.Text, r.Bounds, tc.want)
+			}
+		}
+	}
+}
+
+func TestPickOrientation(t *testing.T) {
+	// readings returns a region's readings at 0, 90, 180 and 270 degrees
+	readings := func(texts [4]string, confs [4]float64) []Result {
+		var rs []Result
+		for i, text := range texts {
+			rs = append(rs, Result{Text: text, Confidence: confs[i], Rotation: 90 * i})
+		}
+		return rs
+	}
+	for _, tc := range []struct {
+		name     string
+		readings []Result
+		want     string
+		rotation int
+		ok       bool
+	}{
+		{"sideways U7", readings([4]string{"LN", "U7", "", "ZN"}, [4]float64{88, 61, 0, 45}), "U7", 90, true},
+		{"upside down", readings([4]string{"", "", "R12", "ZIU"}, [4]float64{0, 0, 70, 90}), "R12", 180, true},
+		{"designators by confidence", readings([4]string{"C3", "", "", "C8"}, [4]float64{52, 0, 0, 74}), "C8", 270, true},
+		{"no designator keeps the most confident", readings([4]string{"GND", "", "QNS", ""}, [4]float64{81, 0, 40, 0}), "GND", 0, true},
+		{"tie goes to the earlier rotation", readings([4]string{"", "D1", "", "D1"}, [4]float64{0, 60, 0, 60}), "D1", 90, true},
+		{"nothing read", readings([4]string{}, [4]float64{}), "", 0, false},
+		{"no readings", nil, "", 0, false},
+	} {
+		got, ok := pickOrientation(tc.readings)
+		if ok != tc.ok || got.Text != tc.want || got.Rotation != tc.rotation {
+			t.Errorf("%s: %q at %d° (ok %v), want %q at %d° (ok %v)",
+				tc.name, got.Text, got.Rotation, ok, tc.want, tc.rotation, tc.ok)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package ocr
 import (
 	"fmt"
 	"image"
+	"os"
 	"strings"
 
 	"pcb-tracer/pkg/geometry"
@@ -17,9 +18,28 @@ import (
 const ElectronicsChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ-/"
 
 // Engine provides OCR functionality using Tesseract.
+// An Engine wraps a single Tesseract client and is not safe for concurrent
+// use: give each goroutine its own, ideally from an EnginePool (as
+// AnnealParallel does).
 type Engine struct {
 	client          *gosseract.Client
 	electronicsMode bool
+
+	// Current engine mode and the config file used to select it.
+	// OEM is an init-only Tesseract parameter, so it can't go through SetVariable.
+	oem           OEMMode
+	oemConfigPath string
+	configLoads   int // SetConfigFile calls, each forcing a full Tesseract init
+}
+
+// engineVariables are the Tesseract variables every Engine starts with.
+// Dictionary-based word correction is disabled - part numbers aren't English
+// words. This prevents Tesseract from "correcting" DM74LS244N to something else.
+var engineVariables = map[gosseract.SettableVariable]string{
+	"load_system_dawg":                          "false",
+	"load_freq_dawg":                            "false",
+	"language_model_penalty_non_dict_word":      "0",
+	"language_model_penalty_non_freq_dict_word": "0",
 }
 
 // NewEngine creates a new OCR engine.
@@ -32,12 +52,9 @@ func NewEngine() (*Engine, error) {
 		return nil, fmt.Errorf("failed to set OCR language: %w", err)
 	}
 
-	// Disable dictionary-based word correction - part numbers aren't English words
-	// This prevents Tesseract from "correcting" DM74LS244N to something else
-	_ = client.SetVariable("load_system_dawg", "false")
-	_ = client.SetVariable("load_freq_dawg", "false")
-	_ = client.SetVariable("language_model_penalty_non_dict_word", "0")
-	_ = client.SetVariable("language_model_penalty_non_freq_dict_word", "0")
+	for name, value := range engineVariables {
+		_ = client.SetVariable(name, value)
+	}
 
 	return &Engine{
 		client:          client,
@@ -47,12 +64,45 @@ func NewEngine() (*Engine, error) {
 
 // Close releases OCR resources.
 func (e *Engine) Close() error {
+	if e.oemConfigPath != "" {
+		os.Remove(e.oemConfigPath)
+		e.oemConfigPath = ""
+	}
 	if e.client != nil {
 		return e.client.Close()
 	}
 	return nil
 }
 
+// setEngineMode selects the Tesseract OEM, re-initializing the client if it changed.
+func (e *Engine) setEngineMode(mode OEMMode) error {
+	if mode == e.oem {
+		return nil
+	}
+
+	if e.oemConfigPath == "" {
+		f, err := os.CreateTemp("", "pcb-tracer-oem-*.cfg")
+		if err != nil {
+			return fmt.Errorf("failed to create OEM config: %w", err)
+		}
+		e.oemConfigPath = f.Name()
+		f.Close()
+	}
+
+	cfg := fmt.Sprintf("tessedit_ocr_engine_mode %d\n", mode.TesseractOEM())
+	if err := os.WriteFile(e.oemConfigPath, []byte(cfg), 0644); err != nil {
+		return fmt.Errorf("failed to write OEM config: %w", err)
+	}
+
+	// Setting the config file flags the client for re-init on the next recognition
+	if err := e.client.SetConfigFile(e.oemConfigPath); err != nil {
+		return fmt.Errorf("failed to set OEM config: %w", err)
+	}
+	e.configLoads++
+	e.oem = mode
+	return nil
+}
+
 // SetElectronicsMode enables/disables electronics character set restriction.
 func (e *Engine) SetElectronicsMode(enabled bool) {
 	e.electronicsMode = enabled
@@ -206,6 +256,7 @@ type Result struct {
 	Text       string
 	Bounds     geometry.RectInt
 	Confidence float64
+	Rotation   int // Rotation at which the text was read (0, 90, 180, 270)
 }
 
 // DetectAllText finds and recognizes all text regions in an image.
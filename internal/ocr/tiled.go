@@ -0,0 +1,186 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// Tiling defaults for full-board silkscreen OCR. The overlap must exceed the
+// longest silkscreen label so every label lies wholly inside some tile.
+const (
+	DefaultTileSize    = 2048
+	DefaultTileOverlap = 256
+)
+
+// tileEdgeMargin is how close (pixels) a reading may come to a tile edge that
+// was cut from the board before it is treated as truncated.
+const tileEdgeMargin = 2
+
+// DetectSilkscreenTiled runs DetectSilkscreen over overlapping tiles of img
+// in parallel, one Tesseract engine per worker, and merges the readings back
+// into board coordinates. Readings touching an interior tile edge are dropped
+// (the neighboring tile sees them whole), and readings found in two tiles'
+// overlap are de-duplicated. Coordinate axes are found on the merged text.
+func DetectSilkscreenTiled(img gocv.Mat, tileSize, overlap, workers int) (*SilkscreenResult, error) {
+	if img.Empty() {
+		return nil, fmt.Errorf("empty image")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	tiles := silkscreenTiles(img.Cols(), img.Rows(), tileSize, overlap)
+	if workers > len(tiles) {
+		workers = len(tiles)
+	}
+	fmt.Printf("Silkscreen OCR: %d tiles of %dpx (overlap %d), %d workers\n",
+		len(tiles), tileSize, overlap, workers)
+
+	results := make([]*SilkscreenResult, len(tiles))
+	errs := make([]error, len(tiles))
+	tileChan := make(chan int, len(tiles))
+	for i := range tiles {
+		tileChan <- i
+	}
+	close(tileChan)
+
+	pool := SharedEnginePool()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		engine, err := pool.Acquire()
+		if err != nil {
+			if w == 0 {
+				return nil, err
+			}
+			break // Run with the engines we have
+		}
+		wg.Add(1)
+		go func(engine *Engine) {
+			defer wg.Done()
+			defer pool.Release(engine)
+			for i := range tileChan {
+				region := img.Region(tiles[i])
+				tile := region.Clone()
+				region.Close()
+				results[i], errs[i] = engine.DetectSilkscreen(tile)
+				tile.Close()
+			}
+		}(engine)
+	}
+	wg.Wait()
+
+	if err := allTilesFailed(errs); err != nil {
+		return nil, err
+	}
+	merged := mergeTileResults(tiles, results, img.Cols(), img.Rows())
+	for i, err := range errs {
+		if err != nil {
+			fmt.Printf("  tile %v: %v\n", tiles[i], err)
+		}
+	}
+
+	fmt.Printf("Silkscreen OCR (tiled): found %d designators, %d total text items\n",
+		len(merged.Designators), len(merged.AllText))
+	return merged, nil
+}
+
+// allTilesFailed returns an error joining the per-tile errors if every tile
+// failed, so an unusable engine is not reported as a board without text.
+// A partial failure returns nil; the caller logs the failed tiles.
+func allTilesFailed(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("all %d tiles failed: %w", len(errs), errors.Join(errs...))
+}
+
+// silkscreenTiles covers a w×h image with tiles of at most size pixels that
+// overlap their neighbors by overlap pixels.
+func silkscreenTiles(w, h, size, overlap int) []image.Rectangle {
+	if size <= overlap {
+		size = overlap + 1
+	}
+	step := size - overlap
+	starts := func(n int) []int {
+		s := []int{0}
+		for s[len(s)-1]+size < n {
+			s = append(s, s[len(s)-1]+step)
+		}
+		return s
+	}
+
+	var tiles []image.Rectangle
+	for _, y := range starts(h) {
+		for _, x := range starts(w) {
+			tiles = append(tiles, image.Rect(x, y, min(x+size, w), min(y+size, h)))
+		}
+	}
+	return tiles
+}
+
+// mergeTileResults shifts tile-local readings to image coordinates, drops
+// those truncated by an interior tile edge, and removes overlap duplicates.
+func mergeTileResults(tiles []image.Rectangle, results []*SilkscreenResult, imgW, imgH int) *SilkscreenResult {
+	merged := &SilkscreenResult{}
+
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+		t := tiles[i]
+		cut := func(x, y, w, h int) bool {
+			return (t.Min.X > 0 && x < tileEdgeMargin) ||
+				(t.Min.Y > 0 && y < tileEdgeMargin) ||
+				(t.Max.X < imgW && x+w > t.Dx()-tileEdgeMargin) ||
+				(t.Max.Y < imgH && y+h > t.Dy()-tileEdgeMargin)
+		}
+
+		for _, res := range r.AllText {
+			if cut(res.Bounds.X, res.Bounds.Y, res.Bounds.Width, res.Bounds.Height) {
+				continue
+			}
+			res.Bounds.X += t.Min.X
+			res.Bounds.Y += t.Min.Y
+			if !isDuplicateResult(res, merged.AllText, 30) {
+				merged.AllText = append(merged.AllText, res)
+			}
+		}
+
+		for _, d := range r.Designators {
+			if cut(d.Bounds.X, d.Bounds.Y, d.Bounds.Width, d.Bounds.Height) {
+				continue
+			}
+			d.Bounds.X += t.Min.X
+			d.Bounds.Y += t.Min.Y
+			if !isDuplicateDesignator(d, merged.Designators, 30) {
+				merged.Designators = append(merged.Designators, d)
+			}
+		}
+	}
+
+	merged.XAxis, merged.YAxis = findCoordinateAxes(merged.AllText, imgW, imgH)
+	return merged
+}
+
+// isDuplicateDesignator reports whether d was already found near the same place.
+func isDuplicateDesignator(d ComponentDesignator, existing []ComponentDesignator, threshold int) bool {
+	for _, e := range existing {
+		if d.Text != e.Text {
+			continue
+		}
+		dx, dy := d.Bounds.X-e.Bounds.X, d.Bounds.Y-e.Bounds.Y
+		if dx > -threshold && dx < threshold && dy > -threshold && dy < threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package ocr
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestSilkscreenTiles(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		w, h, size, overlap int
+		want                []image.Rectangle
+	}{
+		{"fits in one tile", 1000, 800, 2048, 256, []image.Rectangle{image.Rect(0, 0, 1000, 800)}},
+		{"exactly one tile", 2048, 2048, 2048, 256, []image.Rectangle{image.Rect(0, 0, 2048, 2048)}},
+		{"row of three", 5000, 1000, 2048, 256, []image.Rectangle{
+			image.Rect(0, 0, 2048, 1000), image.Rect(1792, 0, 3840, 1000), image.Rect(3584, 0, 5000, 1000)}},
+		{"two by two", 300, 250, 200, 50, []image.Rectangle{
+			image.Rect(0, 0, 200, 200), image.Rect(150, 0, 300, 200),
+			image.Rect(0, 150, 200, 250), image.Rect(150, 150, 300, 250)}},
+		{"size raised past the overlap", 40, 10, 20, 30, nil}, // 31 px tiles, 1 px apart
+	} {
+		got := silkscreenTiles(tc.w, tc.h, tc.size, tc.overlap)
+		if tc.want != nil && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: tiles %v, want %v", tc.name, got, tc.want)
+		}
+
+		// Whatever the sizes, the tiles cover the image and neighbors share
+		// at least the overlap, so every label fits wholly in some tile
+		size := max(tc.size, tc.overlap+1)
+		covered := image.Rectangle{}
+		for i, r := range got {
+			covered = covered.Union(r)
+			if r.Dx() > size || r.Dy() > size {
+				t.Errorf("%s: tile %v larger than %d", tc.name, r, size)
+			}
+			if i > 0 && got[i-1].Min.Y == r.Min.Y {
+				if shared := got[i-1].Intersect(r).Dx(); shared < tc.overlap {
+					t.Errorf("%s: tiles %v and %v share %d px, want at least %d", tc.name, got[i-1], r, shared, tc.overlap)
+				}
+			}
+		}
+		if covered != image.Rect(0, 0, tc.w, tc.h) {
+			t.Errorf("%s: tiles cover %v, want the whole %dx%d image", tc.name, covered, tc.w, tc.h)
+		}
+	}
+}
+
+// TestMergeTileResults merges the readings of two tiles of a 300×100 image
+// that overlap at x 150-200.
+func TestMergeTileResults(t *testing.T) {
+	tiles := silkscreenTiles(300, 100, 200, 50)
+	if len(tiles) != 2 {
+		t.Fatalf("tiles %v, want two", tiles)
+	}
+	rect := func(x, y, w, h int) geometry.RectInt { return geometry.RectInt{X: x, Y: y, Width: w, Height: h} }
+	text := func(s string, x, y, w, h int) Result { return Result{Text: s, Bounds: rect(x, y, w, h)} }
+	desig := func(s string, x, y, w, h int) ComponentDesignator {
+		return ComponentDesignator{Text: s, Bounds: rect(x, y, w, h)}
+	}
+
+	left := &SilkscreenResult{
+		AllText: []Result{
+			text("C3", 0, 20, 25, 20),   // On the image edge, kept
+			text("U1", 160, 20, 30, 20), // Whole, in the overlap
+			text("R2", 185, 50, 20, 20), // Cut by the interior edge at 200
+		},
+		Designators: []ComponentDesignator{desig("U1", 160, 20, 30, 20), desig("R2", 185, 50, 20, 20)},
+	}
+	right := &SilkscreenResult{
+		AllText: []Result{
+			text("U1", 10, 20, 30, 20), // Same U1, seen from this tile
+			text("R2", 35, 50, 20, 20),
+			text("U1", 90, 70, 30, 20), // Another U1 elsewhere
+			text("7", 142, 40, 8, 20),  // On the image edge, kept
+		},
+		Designators: []ComponentDesignator{desig("U1", 10, 20, 30, 20), desig("R2", 35, 50, 20, 20)},
+	}
+
+	merged := mergeTileResults(tiles, []*SilkscreenResult{left, right}, 300, 100)
+	type placed struct {
+		text   string
+		bounds geometry.RectInt
+	}
+	var got []placed
+	for _, r := range merged.AllText {
+		got = append(got, placed{r.Text, r.Bounds})
+	}
+	want := []placed{
+		{"C3", rect(0, 20, 25, 20)},
+		{"U1", rect(160, 20, 30, 20)},
+		{"R2", rect(185, 50, 20, 20)},
+		{"U1", rect(240, 70, 30, 20)},
+		{"7", rect(292, 40, 8, 20)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("text:\n got %v\nwant %v", got, want)
+	}
+	if d := merged.Designators; len(d) != 2 || d[0].Text != "U1" || d[0].Bounds.X != 160 || d[1].Text != "R2" || d[1].Bounds.X != 185 {
+		t.Errorf("designators %v, want U1 at 160 and R2 at 185", d)
+	}
+
+	// A failed tile contributes nothing
+	merged = mergeTileResults(tiles, []*SilkscreenResult{nil, right}, 300, 100)
+	if len(merged.AllText) != 4 || len(merged.Designators) != 2 {
+		t.Errorf("with the left tile failed: %d texts, %d designators, want 4 and 2", len(merged.AllText), len(merged.Designators))
+	}
+}
+
+func TestAllTilesFailed(t *testing.T) {
+	boom := errors.New("tesseract: init failed")
+	if err := allTilesFailed([]error{nil, boom, nil}); err != nil {
+		t.Errorf("partial failure: %v, want nil", err)
+	}
+	if err := allTilesFailed(nil); err != nil {
+		t.Errorf("no tiles: %v, want nil", err)
+	}
+	err := allTilesFailed([]error{boom, boom})
+	if err == nil || !errors.Is(err, boom) || !strings.Contains(err.Error(), "all 2 tiles failed") {
+		t.Errorf("every tile failed: %v, want an error wrapping %v", err, boom)
+	}
+}
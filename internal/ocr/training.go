@@ -2,16 +2,24 @@
 package ocr
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"image"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
+	"pcb-tracer/pkg/geometry"
+
 	"github.com/otiai10/gosseract/v2"
 	"gocv.io/x/gocv"
 )
@@ -40,6 +48,12 @@ type OCRParams struct {
 	// Use Otsu threshold instead of histogram-based
 	UseOtsu bool `json:"use_otsu,omitempty"`
 
+	// Robust Otsu region grid and the regions trimmed from each end of the
+	// brightness order (0 = by image aspect, see OtsuGrid)
+	OtsuGridCols int `json:"otsu_cols,omitempty"`
+	OtsuGridRows int `json:"otsu_rows,omitempty"`
+	OtsuTrim     int `json:"otsu_trim,omitempty"`
+
 	// Adaptive threshold parameters
 	UseAdaptive   bool `json:"use_adaptive,omitempty"`
 	AdaptiveBlock int  `json:"adaptive_block,omitempty"` // Block size (odd number)
@@ -49,8 +63,83 @@ type OCRParams struct {
 	DilateIterations int `json:"dilate,omitempty"`
 	ErodeIterations  int `json:"erode,omitempty"`
 
-	// PSM mode (page segmentation mode)
+	// PSM mode (page segmentation mode), 0-13
 	PSMMode int `json:"psm_mode,omitempty"`
+
+	// OEM (OCR engine mode). Zero leaves Tesseract's default engine selection.
+	OEM OEMMode `json:"oem,omitempty"`
+}
+
+// OEMMode selects the Tesseract recognition engine.
+// The zero value means "engine default" so params saved before OEM existed
+// keep their old behavior; use TesseractOEM to get the value Tesseract expects.
+type OEMMode int
+
+const (
+	OEMDefault  OEMMode = iota // Tesseract OEM_DEFAULT (3)
+	OEMLegacy                  // Tesseract OEM_TESSERACT_ONLY (0)
+	OEMLSTM                    // Tesseract OEM_LSTM_ONLY (1)
+	OEMCombined                // Tesseract OEM_TESSERACT_LSTM_COMBINED (2)
+)
+
+// Legal Tesseract page segmentation mode range.
+const (
+	MinPSMMode = 0
+	MaxPSMMode = 13
+)
+
+// TesseractOEM returns the numeric engine mode understood by Tesseract.
+func (m OEMMode) TesseractOEM() int {
+	switch m {
+	case OEMLegacy:
+		return 0
+	case OEMLSTM:
+		return 1
+	case OEMCombined:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// String returns a short name for the engine mode.
+func (m OEMMode) String() string {
+	switch m {
+	case OEMDefault:
+		return "default"
+	case OEMLegacy:
+		return "legacy"
+	case OEMLSTM:
+		return "lstm"
+	case OEMCombined:
+		return "combined"
+	default:
+		return fmt.Sprintf("OEMMode(%d)", int(m))
+	}
+}
+
+// Validate checks that the Tesseract modes in params are in their legal ranges.
+func (p OCRParams) Validate() error {
+	if p.PSMMode < MinPSMMode || p.PSMMode > MaxPSMMode {
+		return fmt.Errorf("invalid PSM mode %d: must be %d-%d", p.PSMMode, MinPSMMode, MaxPSMMode)
+	}
+	if p.OEM < OEMDefault || p.OEM > OEMCombined {
+		return fmt.Errorf("invalid OEM mode %d: must be %d-%d", int(p.OEM), int(OEMDefault), int(OEMCombined))
+	}
+	return nil
+}
+
+// UsesHistogram reports whether params binarize at the brightest-percent
+// histogram threshold, which they do when no other method is selected.
+func (p OCRParams) UsesHistogram() bool {
+	return !p.UseAdaptive && !p.UseOtsu && p.FixedThreshold <= 0 && p.BrightestPercent > 0
+}
+
+// Hash returns a short fingerprint of the params, for keying cached results.
+func (p OCRParams) Hash() string {
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
 }
 
 // DefaultOCRParams returns sensible defaults for IC package text.
@@ -66,6 +155,7 @@ func DefaultOCRParams() OCRParams {
 		DilateIterations: 0,
 		ErodeIterations:  0,
 		PSMMode:          6, // PSM_SINGLE_BLOCK
+		OEM:              OEMLSTM,
 	}
 }
 
@@ -249,45 +339,23 @@ func characterOverlap(detected, truth string) float64 {
 	return float64(matched) / float64(len(truth))
 }
 
-// AnnealOCRParams tries different OCR parameter combinations to find the best match.
-// Returns the best parameters found and the achieved similarity score.
-// This is VERY aggressive with threshold manipulation.
-func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations int) (OCRParams, float64, string) {
-	if img.Empty() || groundTruth == "" {
-		return DefaultOCRParams(), 0.0, ""
-	}
-
-	// Strip logo markers from truth for clean comparison
-	cleanTruth := stripLogoMarkers(groundTruth)
-	fmt.Printf("OCR Annealing: searching (truth=%q, clean=%q)\n", groundTruth, cleanTruth)
-
-	bestParams := DefaultOCRParams()
-	bestScore := 0.0
-	bestText := ""
-	iterations := 0
-
-	// Helper to try a param set
-	tryParams := func(params OCRParams, desc string) bool {
-		if iterations >= maxIterations {
-			return true // stop
-		}
-		text := e.recognizeWithParams(img, params)
-		score := TextSimilarity(text, groundTruth)
-		iterations++
+// annealCandidate is one parameter set tried by the OCR annealing search.
+type annealCandidate struct {
+	params OCRParams
+	desc   string
+	phase  string
+}
 
-		if score > bestScore {
-			bestScore = score
-			bestParams = params
-			bestText = text
-			fmt.Printf("  [%d] score=%.3f %s -> %q\n", iterations, score, desc, text)
-			if score >= 0.95 {
-				return true // stop early
-			}
-		}
-		return false
+// annealCandidates returns every parameter set the annealing search tries,
+// in the order it tries them (most promising phases first).
+// This is VERY aggressive with threshold manipulation.
+func annealCandidates() []annealCandidate {
+	var cands []annealCandidate
+	phase := ""
+	add := func(params OCRParams, desc string) {
+		cands = append(cands, annealCandidate{params: params, desc: desc, phase: phase})
 	}
 
-	// ========== Declare all parameter slices upfront (Go doesn't allow goto over declarations) ==========
 	fixedThresholds := []int{40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140, 150, 160, 170, 180, 190, 200, 210, 220, 230}
 	scales := []int{100, 150, 200, 300, 400}
 	psmModes := []int{6, 7, 13, 11, 3} // BLOCK, LINE, RAW_LINE, SPARSE, AUTO
@@ -299,7 +367,7 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 	// ========== PHASE 1: Fixed thresholds with CLAHE (critical for IC text) ==========
 	// IC text is typically light markings on dark plastic
 	// CLAHE is ESSENTIAL for enhancing subtle contrast before thresholding
-	fmt.Println("  Phase 1: CLAHE + Fixed thresholds...")
+	phase = "Phase 1: CLAHE + Fixed thresholds"
 
 	for _, thresh := range fixedThresholds {
 		for _, invert := range []bool{true, false} {
@@ -312,12 +380,11 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 							InvertPolarity: invert,
 							MinScaleDim:    scale,
 							PSMMode:        psm,
+							OEM:            OEMLSTM,
 							CLAHEClipLimit: clip,
 							CLAHETileSize:  8,
 						}
-						if tryParams(params, fmt.Sprintf("fixed=%d clahe=%.1f inv=%v scale=%d psm=%d", thresh, clip, invert, scale, psm)) {
-							goto done
-						}
+						add(params, fmt.Sprintf("fixed=%d clahe=%.1f inv=%v scale=%d psm=%d", thresh, clip, invert, scale, psm))
 					}
 				}
 			}
@@ -325,7 +392,7 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 	}
 
 	// ========== PHASE 2: CLAHE + Otsu ==========
-	fmt.Println("  Phase 2: CLAHE + Otsu...")
+	phase = "Phase 2: CLAHE + Otsu"
 
 	for _, clip := range claheClips {
 		for _, tile := range claheTiles {
@@ -339,10 +406,37 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 							CLAHETileSize:  tile,
 							MinScaleDim:    scale,
 							PSMMode:        psm,
+							OEM:            OEMLSTM,
 						}
-						if tryParams(params, fmt.Sprintf("otsu clip=%.1f tile=%d inv=%v scale=%d psm=%d", clip, tile, invert, scale, psm)) {
-							goto done
+						add(params, fmt.Sprintf("otsu clip=%.1f tile=%d inv=%v scale=%d psm=%d", clip, tile, invert, scale, psm))
+					}
+				}
+			}
+		}
+	}
+
+	// ========== PHASE 2b: Robust Otsu region grids ==========
+	// Grid shape matters with the crop's aspect: tall DIPs, square SOICs
+	phase = "Phase 2b: Robust Otsu grids"
+
+	for _, grid := range [][2]int{{2, 4}, {4, 2}, {3, 3}, {2, 2}} {
+		for _, trim := range []int{1, 2} {
+			for _, invert := range []bool{true, false} {
+				for _, scale := range []int{150, 200, 300} {
+					for _, psm := range []int{6, 7} {
+						params := OCRParams{
+							UseOtsu:        true,
+							OtsuGridCols:   grid[0],
+							OtsuGridRows:   grid[1],
+							OtsuTrim:       trim,
+							InvertPolarity: invert,
+							CLAHEClipLimit: 2.0,
+							CLAHETileSize:  8,
+							MinScaleDim:    scale,
+							PSMMode:        psm,
+							OEM:            OEMLSTM,
 						}
+						add(params, fmt.Sprintf("robust otsu %dx%d trim=%d inv=%v scale=%d psm=%d", grid[0], grid[1], trim, invert, scale, psm))
 					}
 				}
 			}
@@ -350,7 +444,7 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 	}
 
 	// ========== PHASE 3: Histogram-based (brightest N%) ==========
-	fmt.Println("  Phase 3: Histogram brightest %...")
+	phase = "Phase 3: Histogram brightest %"
 
 	for _, pct := range brightestPcts {
 		for _, minTh := range minThresholds {
@@ -364,10 +458,9 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 							InvertPolarity:   invert,
 							MinScaleDim:      scale,
 							PSMMode:          psm,
+							OEM:              OEMLSTM,
 						}
-						if tryParams(params, fmt.Sprintf("hist=%v%% min=%d inv=%v scale=%d psm=%d", pct, minTh, invert, scale, psm)) {
-							goto done
-						}
+						add(params, fmt.Sprintf("hist=%v%% min=%d inv=%v scale=%d psm=%d", pct, minTh, invert, scale, psm))
 					}
 				}
 			}
@@ -375,7 +468,7 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 	}
 
 	// ========== PHASE 4: Morphological operations ==========
-	fmt.Println("  Phase 4: Morphological operations...")
+	phase = "Phase 4: Morphological operations"
 	for _, thresh := range []int{80, 100, 120, 140, 160, 180} {
 		for _, dilate := range []int{0, 1, 2} {
 			for _, erode := range []int{0, 1, 2} {
@@ -387,12 +480,11 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 							InvertPolarity:   invert,
 							MinScaleDim:      scale,
 							PSMMode:          6,
+							OEM:              OEMLSTM,
 							DilateIterations: dilate,
 							ErodeIterations:  erode,
 						}
-						if tryParams(params, fmt.Sprintf("morph th=%d d=%d e=%d inv=%v", thresh, dilate, erode, invert)) {
-							goto done
-						}
+						add(params, fmt.Sprintf("morph th=%d d=%d e=%d inv=%v", thresh, dilate, erode, invert))
 					}
 				}
 			}
@@ -400,7 +492,7 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 	}
 
 	// ========== PHASE 5: Adaptive threshold ==========
-	fmt.Println("  Phase 5: Adaptive threshold...")
+	phase = "Phase 5: Adaptive threshold"
 	for _, blockSize := range []int{11, 21, 31, 51} {
 		for _, c := range []int{2, 5, 10, 15, 20} {
 			for _, invert := range []bool{true, false} {
@@ -412,20 +504,171 @@ func (e *Engine) AnnealOCRParams(img gocv.Mat, groundTruth string, maxIterations
 						InvertPolarity:   invert,
 						MinScaleDim:      scale,
 						PSMMode:          6,
+						OEM:              OEMLSTM,
 					}
-					if tryParams(params, fmt.Sprintf("adaptive blk=%d c=%d inv=%v", blockSize, c, invert)) {
-						goto done
-					}
+					add(params, fmt.Sprintf("adaptive blk=%d c=%d inv=%v", blockSize, c, invert))
 				}
 			}
 		}
 	}
 
-done:
+	return cands
+}
+
+// annealStopScore ends the search early once a result is this close to the truth.
+const annealStopScore = 0.95
+
+// AnnealOCRParams tries different OCR parameter combinations to find the best match.
+// Returns the best parameters found and the achieved similarity score.
+// If ctx is canceled the search stops between candidates and returns the
+// best result so far along with ctx.Err().
+// See AnnealParallel for a multi-worker variant.
+func (e *Engine) AnnealOCRParams(ctx context.Context, img gocv.Mat, groundTruth string, maxIterations int) (OCRParams, float64, string, error) {
+	if img.Empty() || groundTruth == "" {
+		return DefaultOCRParams(), 0.0, "", nil
+	}
+
+	// Strip logo markers from truth for clean comparison
+	cleanTruth := stripLogoMarkers(groundTruth)
+	fmt.Printf("OCR Annealing: searching (truth=%q, clean=%q)\n", groundTruth, cleanTruth)
+
+	bestParams := DefaultOCRParams()
+	bestScore := 0.0
+	bestText := ""
+	iterations := 0
+	phase := ""
+
+	var err error
+	for _, c := range annealCandidates() {
+		if iterations >= maxIterations {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			fmt.Printf("OCR Annealing: canceled\n")
+			break
+		}
+		if c.phase != phase {
+			phase = c.phase
+			fmt.Printf("  %s...\n", phase)
+		}
+		text := e.recognizeWithParams(img, c.params)
+		score := TextSimilarity(text, groundTruth)
+		iterations++
+
+		if score > bestScore {
+			bestScore = score
+			bestParams = c.params
+			bestText = text
+			fmt.Printf("  [%d] score=%.3f %s -> %q\n", iterations, score, c.desc, text)
+			if score >= annealStopScore {
+				break
+			}
+		}
+	}
+
 	fmt.Printf("OCR Annealing: best score=%.3f after %d iterations\n", bestScore, iterations)
 	fmt.Printf("  Best text: %q\n", bestText)
 
-	return bestParams, bestScore, bestText
+	return bestParams, bestScore, bestText, err
+}
+
+// AnnealParallel runs the same search as AnnealOCRParams with workers
+// concurrent chains pulling candidates from a shared queue, and returns the
+// globally best params, score and text. Ties go to the candidate that comes
+// first in search order, matching the serial search.
+//
+// A Tesseract client is not safe for concurrent use, so every worker takes
+// its own Engine from the shared pool (and its own copy of img); Engine
+// instances must never be shared between goroutines. An error is returned if no engine could be
+// created, or ctx.Err() if ctx was canceled; workers check ctx between
+// candidates and the best result so far is still returned.
+func AnnealParallel(ctx context.Context, img gocv.Mat, groundTruth string, maxIterations, workers int) (OCRParams, float64, string, error) {
+	if img.Empty() || groundTruth == "" {
+		return DefaultOCRParams(), 0.0, "", nil
+	}
+	workers = max(workers, 1)
+
+	cands := annealCandidates()
+	if len(cands) > maxIterations {
+		cands = cands[:max(maxIterations, 0)]
+	}
+	fmt.Printf("OCR Annealing: searching %d candidates with %d workers (truth=%q)\n",
+		len(cands), workers, groundTruth)
+	pool := SharedEnginePool()
+
+	var (
+		mu        sync.Mutex
+		next      int
+		stop      bool
+		bestIdx   = -1
+		bestScore float64
+		bestText  string
+		tried     int
+		engineErr error
+		engines   int
+		wg        sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			engine, err := pool.Acquire()
+			if err != nil {
+				mu.Lock()
+				engineErr = err
+				mu.Unlock()
+				return
+			}
+			defer pool.Release(engine)
+			mat := img.Clone()
+			defer mat.Close()
+
+			mu.Lock()
+			engines++
+			mu.Unlock()
+
+			for {
+				mu.Lock()
+				if stop || next >= len(cands) || ctx.Err() != nil {
+					mu.Unlock()
+					return
+				}
+				idx := next
+				next++
+				mu.Unlock()
+
+				text := engine.recognizeWithParams(mat, cands[idx].params)
+				score := TextSimilarity(text, groundTruth)
+
+				mu.Lock()
+				tried++
+				if score > bestScore || (score == bestScore && score > 0 && idx < bestIdx) {
+					bestIdx, bestScore, bestText = idx, score, text
+					fmt.Printf("  [%d] score=%.3f %s -> %q\n", idx+1, score, cands[idx].desc, text)
+					if score >= annealStopScore {
+						stop = true
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if engines == 0 {
+		return DefaultOCRParams(), 0.0, "", fmt.Errorf("creating OCR engine: %w", engineErr)
+	}
+
+	fmt.Printf("OCR Annealing: best score=%.3f after %d iterations\n", bestScore, tried)
+	fmt.Printf("  Best text: %q\n", bestText)
+
+	err := ctx.Err()
+	if bestIdx < 0 {
+		return DefaultOCRParams(), 0.0, "", err
+	}
+	return cands[bestIdx].params, bestScore, bestText, err
 }
 
 // recognizeWithParams runs OCR with specific parameters.
@@ -445,7 +688,10 @@ func (e *Engine) recognizeWithParams(img gocv.Mat, params OCRParams) string {
 	}
 	defer buf.Close()
 
-	// Set PSM mode
+	// Set engine and PSM mode
+	if err := e.setEngineMode(params.OEM); err != nil {
+		return ""
+	}
 	psmMode := gosseract.PageSegMode(params.PSMMode)
 	if err := e.client.SetPageSegMode(psmMode); err != nil {
 		return ""
@@ -483,10 +729,120 @@ func (e *Engine) recognizeWithParams(img gocv.Mat, params OCRParams) string {
 	return text
 }
 
+// LowConfidence is the Tesseract word confidence (0-100) below which a
+// reading is treated as doubtful.
+const LowConfidence = 60.0
+
+// WordConfidence is one word (or text line) Tesseract read and how sure it
+// was of it.
+type WordConfidence struct {
+	Text       string
+	Bounds     geometry.RectInt // In the coordinates of the image passed in
+	Confidence float64          // 0-100
+}
+
+// Recognition is an OCR reading with Tesseract's confidence in it.
+type Recognition struct {
+	Text           string
+	MeanConfidence float64 // Mean word confidence (0-100), 0 when no words were found
+	Words          []WordConfidence
+	Lines          []WordConfidence // Text lines, for re-reading one line on its own
+}
+
+// LowConfidenceWords returns the words read with confidence below minConf.
+func (r Recognition) LowConfidenceWords(minConf float64) []WordConfidence {
+	var low []WordConfidence
+	for _, w := range r.Words {
+		if w.Confidence < minConf {
+			low = append(low, w)
+		}
+	}
+	return low
+}
+
+// RecognizeDetailed performs OCR using specific learned parameters and
+// returns the text with Tesseract's per-word and per-line boxes and
+// confidences. This gives a quality signal when there is no ground truth
+// to compare with. Returns an error if the PSM or OEM mode is out of range.
+func (e *Engine) RecognizeDetailed(img gocv.Mat, params OCRParams) (Recognition, error) {
+	if err := params.Validate(); err != nil {
+		return Recognition{}, err
+	}
+	rec := Recognition{Text: e.recognizeWithParams(img, params)}
+	if rec.Text == "" {
+		return rec, nil
+	}
+
+	// The preprocessed image is still loaded from recognizeWithParams; map
+	// its boxes back through the upscale preprocessing applied.
+	scale := preprocessScale(img.Rows(), img.Cols(), params)
+	if boxes, err := e.client.GetBoundingBoxes(gosseract.RIL_WORD); err == nil {
+		rec.Words = e.scaleBoxes(boxes, scale)
+	}
+	if boxes, err := e.client.GetBoundingBoxes(gosseract.RIL_TEXTLINE); err == nil {
+		rec.Lines = e.scaleBoxes(boxes, scale)
+	}
+
+	var sum float64
+	for _, w := range rec.Words {
+		sum += w.Confidence
+	}
+	if len(rec.Words) > 0 {
+		rec.MeanConfidence = sum / float64(len(rec.Words))
+	}
+	return rec, nil
+}
+
+// scaleBoxes converts Tesseract boxes on an image enlarged by scale to
+// non-empty WordConfidences in the original image's coordinates.
+func (e *Engine) scaleBoxes(boxes []gosseract.BoundingBox, scale float64) []WordConfidence {
+	var out []WordConfidence
+	for _, box := range boxes {
+		text := strings.Join(strings.Fields(box.Word), " ")
+		if text == "" {
+			continue
+		}
+		if e.electronicsMode {
+			text = strings.ToUpper(text)
+		}
+		b := box.Box
+		out = append(out, WordConfidence{
+			Text: text,
+			Bounds: geometry.RectInt{
+				X:      int(float64(b.Min.X) / scale),
+				Y:      int(float64(b.Min.Y) / scale),
+				Width:  int(float64(b.Dx()) / scale),
+				Height: int(float64(b.Dy()) / scale),
+			},
+			Confidence: box.Confidence,
+		})
+	}
+	return out
+}
+
 // RecognizeWithParams performs OCR using specific learned parameters.
+// Returns an error if the PSM or OEM mode is out of range rather than
+// letting Tesseract silently fall back to garbage output.
 func (e *Engine) RecognizeWithParams(img gocv.Mat, params OCRParams) (string, error) {
-	text := e.recognizeWithParams(img, params)
-	return text, nil
+	rec, err := e.RecognizeDetailed(img, params)
+	return rec.Text, err
+}
+
+// RecognizeWithConfidence is RecognizeWithParams plus Tesseract's mean
+// word confidence (0-100) for the same image. Confidence is 0 when no
+// words were found.
+func (e *Engine) RecognizeWithConfidence(img gocv.Mat, params OCRParams) (string, float64, error) {
+	rec, err := e.RecognizeDetailed(img, params)
+	return rec.Text, rec.MeanConfidence, err
+}
+
+// preprocessScale returns the factor preprocessWithParams enlarges an h×w
+// image by.
+func preprocessScale(h, w int, params OCRParams) float64 {
+	if minDim := min(h, w); minDim > 0 && minDim < params.MinScaleDim {
+		return float64(params.MinScaleDim) / float64(minDim)
+	}
+	return 1
 }
 
 // preprocessWithParams applies preprocessing based on given parameters.
@@ -495,9 +851,7 @@ func preprocessWithParams(region gocv.Mat, params OCRParams) gocv.Mat {
 
 	// Scale up small images
 	var scaled gocv.Mat
-	minDim := min(h, w)
-	if minDim < params.MinScaleDim {
-		scale := float64(params.MinScaleDim) / float64(minDim)
+	if scale := preprocessScale(h, w, params); scale != 1 {
 		scaled = gocv.NewMat()
 		gocv.Resize(region, &scaled, image.Point{}, scale, scale, gocv.InterpolationCubic)
 	} else {
@@ -539,6 +893,10 @@ func preprocessWithParams(region gocv.Mat, params OCRParams) gocv.Mat {
 		}
 		gocv.AdaptiveThreshold(enhanced, &binary, 255,
 			gocv.AdaptiveThresholdMean, gocv.ThresholdBinary, blockSize, float32(c))
+	} else if params.UseOtsu && params.OtsuGridCols > 0 && params.OtsuGridRows > 0 {
+		// Robust Otsu - trimmed region grid, as component OCR binarizes
+		otsu := RobustOtsu(enhanced.ToBytes(), enhanced.Cols(), enhanced.Rows(), params)
+		gocv.Threshold(enhanced, &binary, float32(otsu.Threshold), 255, gocv.ThresholdBinary)
 	} else if params.UseOtsu {
 		// Otsu's method - automatic threshold selection
 		gocv.Threshold(enhanced, &binary, 0, 255, gocv.ThresholdBinary|gocv.ThresholdOtsu)
@@ -591,16 +949,30 @@ func preprocessWithParams(region gocv.Mat, params OCRParams) gocv.Mat {
 // findHistogramThreshold finds threshold that captures brightest N% of pixels.
 func findHistogramThreshold(gray gocv.Mat, brightestPct float64, minThreshold int) int {
 	// Build histogram
-	hist := make([]int, 256)
-	totalPixels := gray.Rows() * gray.Cols()
-
+	var hist [256]int
 	for y := 0; y < gray.Rows(); y++ {
 		for x := 0; x < gray.Cols(); x++ {
 			val := gray.GetUCharAt(y, x)
 			hist[val]++
 		}
 	}
+	return brightestThreshold(&hist, gray.Rows()*gray.Cols(), brightestPct, minThreshold)
+}
+
+// HistogramThreshold returns the gray level above which the brightest
+// brightestPct percent of gray lies, but no lower than minThreshold. This is
+// the threshold of params with UsesHistogram.
+func HistogramThreshold(gray []uint8, brightestPct float64, minThreshold int) uint8 {
+	var hist [256]int
+	for _, v := range gray {
+		hist[v]++
+	}
+	return uint8(brightestThreshold(&hist, len(gray), brightestPct, minThreshold))
+}
 
+// brightestThreshold finds the threshold capturing the brightest N% of a
+// histogram of totalPixels values.
+func brightestThreshold(hist *[256]int, totalPixels int, brightestPct float64, minThreshold int) int {
 	// Find threshold that captures brightest N%
 	targetPixels := int(float64(totalPixels) * brightestPct / 100.0)
 	cumulative := 0
@@ -619,7 +991,7 @@ func findHistogramThreshold(gray gocv.Mat, brightestPct float64, minThreshold in
 		threshold = minThreshold
 	}
 
-	return threshold
+	return min(threshold, 255)
 }
 
 // =============================================================================
@@ -656,6 +1028,44 @@ type GlobalTrainingDB struct {
 	// Aggregated statistics for quick lookup
 	OrientationStats map[string]*OrientationStats `json:"orientation_stats"`
 	ParamStats       *ParamStatistics             `json:"param_stats"`
+
+	// Recommendation weights GetRecommendedParams; nil weighs all good
+	// samples equally
+	Recommendation *RecommendationOptions `json:"recommendation,omitempty"`
+}
+
+// RecommendationOptions weights training samples when GetRecommendedParams
+// aggregates them. The zero value weights every sample by its score alone,
+// with no decay.
+type RecommendationOptions struct {
+	// ScoreWeight sharpens the preference for high-scoring samples: each
+	// sample counts Score^ScoreWeight times, so at 4 a 0.71 sample counts
+	// a quarter as much as a 0.99 one
+	ScoreWeight float64 `json:"score_weight"`
+	// RecencyHalfLife halves a sample's weight for each period of this
+	// length since it was trained; 0 disables decay. Stored in nanoseconds
+	RecencyHalfLife time.Duration `json:"recency_half_life"`
+}
+
+// DefaultRecommendationOptions favors high scores and tunings from the
+// last few months.
+func DefaultRecommendationOptions() RecommendationOptions {
+	return RecommendationOptions{ScoreWeight: 4, RecencyHalfLife: 90 * 24 * time.Hour}
+}
+
+// weight returns how much s counts under o at time now.
+func (o RecommendationOptions) weight(s GlobalTrainingSample, now time.Time) float64 {
+	w := 1.0
+	if o.ScoreWeight > 0 {
+		w = math.Pow(s.Score, o.ScoreWeight)
+	}
+	// Samples without a timestamp do not decay
+	if o.RecencyHalfLife > 0 && s.Timestamp > 0 {
+		if age := now.Sub(time.Unix(s.Timestamp, 0)); age > 0 {
+			w *= math.Exp2(-float64(age) / float64(o.RecencyHalfLife))
+		}
+	}
+	return w
 }
 
 // OrientationStats tracks success rates per orientation.
@@ -686,6 +1096,9 @@ type ParamStatistics struct {
 	// PSM mode success rates
 	PSMModeStats map[int]float64 `json:"psm_stats"`
 
+	// OEM (engine mode) success rates
+	OEMModeStats map[OEMMode]float64 `json:"oem_stats,omitempty"`
+
 	// Invert polarity success rate
 	InvertTrueRate  float64 `json:"invert_true_rate"`
 	InvertFalseRate float64 `json:"invert_false_rate"`
@@ -697,7 +1110,7 @@ func NewGlobalTrainingDB() *GlobalTrainingDB {
 		Version:          1,
 		Samples:          make([]GlobalTrainingSample, 0),
 		OrientationStats: make(map[string]*OrientationStats),
-		ParamStats:       &ParamStatistics{PSMModeStats: make(map[int]float64)},
+		ParamStats:       &ParamStatistics{PSMModeStats: make(map[int]float64), OEMModeStats: make(map[OEMMode]float64)},
 	}
 }
 
@@ -723,27 +1136,98 @@ func (db *GlobalTrainingDB) PurgeLowScores(minScore float64) int {
 	return removed
 }
 
+// PruneOptions controls GlobalTrainingDB.Prune.
+type PruneOptions struct {
+	MinScore    float64 // Drop samples scoring below this
+	MaxPerGroup int     // Keep at most this many per orientation and manufacturer; 0 = no cap
+}
+
+// DefaultPruneOptions returns the limits used by the UI and ocrtrain.
+func DefaultPruneOptions() PruneOptions {
+	return PruneOptions{MinScore: 0.7, MaxPerGroup: 20}
+}
+
+// PruneStats counts the samples Prune removed for each reason.
+type PruneStats struct {
+	LowScore   int
+	Duplicates int
+	OverCap    int
+}
+
+// Total returns the number of samples removed.
+func (p PruneStats) Total() int {
+	return p.LowScore + p.Duplicates + p.OverCap
+}
+
+func (p PruneStats) String() string {
+	return fmt.Sprintf("removed %d samples (%d low score, %d duplicates, %d over group cap)",
+		p.Total(), p.LowScore, p.Duplicates, p.OverCap)
+}
+
+// Prune drops samples below opts.MinScore, collapses samples with the same
+// ground truth and params to the best-scoring one, and caps each
+// (orientation, manufacturer) group at opts.MaxPerGroup samples, keeping
+// the highest scores. Surviving samples keep their order; statistics are
+// recalculated if anything was removed.
+func (db *GlobalTrainingDB) Prune(opts PruneOptions) PruneStats {
+	var stats PruneStats
+
+	// Rank best first so the first sample seen for a key is the one kept;
+	// newer samples win ties
+	order := make([]int, len(db.Samples))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := db.Samples[order[a]], db.Samples[order[b]]
+		if sa.Score != sb.Score {
+			return sa.Score > sb.Score
+		}
+		return sa.Timestamp > sb.Timestamp
+	})
+
+	keep := make([]bool, len(db.Samples))
+	seen := make(map[string]bool)
+	groupCount := make(map[string]int)
+	for _, i := range order {
+		s := db.Samples[i]
+		if s.Score < opts.MinScore {
+			stats.LowScore++
+			continue
+		}
+		dupKey := s.GroundTruth + "\x00" + s.Params.Hash()
+		if seen[dupKey] {
+			stats.Duplicates++
+			continue
+		}
+		seen[dupKey] = true
+		group := s.Orientation + "\x00" + strings.ToUpper(strings.TrimSpace(s.Manufacturer))
+		if opts.MaxPerGroup > 0 && groupCount[group] >= opts.MaxPerGroup {
+			stats.OverCap++
+			continue
+		}
+		groupCount[group]++
+		keep[i] = true
+	}
+
+	if stats.Total() == 0 {
+		return stats
+	}
+	kept := make([]GlobalTrainingSample, 0, len(db.Samples)-stats.Total())
+	for i, s := range db.Samples {
+		if keep[i] {
+			kept = append(kept, s)
+		}
+	}
+	db.Samples = kept
+	db.updateStats()
+	return stats
+}
+
 // updateStats recalculates aggregate statistics from all samples.
 func (db *GlobalTrainingDB) updateStats() {
 	// Reset stats
 	db.OrientationStats = make(map[string]*OrientationStats)
-	db.ParamStats = &ParamStatistics{PSMModeStats: make(map[int]float64)}
-
-	if len(db.Samples) == 0 {
-		return
-	}
-
-	// Track method counts and scores
-	var otsuCount, fixedCount, adaptiveCount, histCount int
-	var otsuScore, fixedScore, adaptiveScore, histScore float64
-	var invertTrueCount, invertFalseCount int
-	var invertTrueScore, invertFalseScore float64
-	fixedThresholdScores := make(map[int]float64)
-	claheClipScores := make(map[float64]float64)
-	claheTileScores := make(map[int]float64)
-	scaleScores := make(map[int]float64)
-	psmScores := make(map[int]float64)
-	psmCounts := make(map[int]int)
 
 	for _, s := range db.Samples {
 		// Only count good samples (score >= 0.7)
@@ -761,64 +1245,100 @@ func (db *GlobalTrainingDB) updateStats() {
 		if s.Score > os.Best {
 			os.Best = s.Score
 		}
+	}
+
+	db.ParamStats = db.paramStats(RecommendationOptions{}, time.Time{})
+}
+
+// paramStats aggregates parameter statistics over the good samples, each
+// weighted as opts specifies. Rates are weighted mean scores; the "best"
+// values are those with the largest weighted score sum.
+func (db *GlobalTrainingDB) paramStats(opts RecommendationOptions, now time.Time) *ParamStatistics {
+	ps := &ParamStatistics{PSMModeStats: make(map[int]float64), OEMModeStats: make(map[OEMMode]float64)}
+	if len(db.Samples) == 0 {
+		return ps
+	}
+
+	// Track method weights and weighted scores
+	var otsuCount, fixedCount, adaptiveCount, histCount float64
+	var otsuScore, fixedScore, adaptiveScore, histScore float64
+	var invertTrueCount, invertFalseCount float64
+	var invertTrueScore, invertFalseScore float64
+	fixedThresholdScores := make(map[int]float64)
+	claheClipScores := make(map[float64]float64)
+	claheTileScores := make(map[int]float64)
+	scaleScores := make(map[int]float64)
+	psmScores := make(map[int]float64)
+	psmCounts := make(map[int]float64)
+	oemScores := make(map[OEMMode]float64)
+	oemCounts := make(map[OEMMode]float64)
+
+	for _, s := range db.Samples {
+		// Only count good samples (score >= 0.7)
+		if s.Score < 0.7 {
+			continue
+		}
+		w := opts.weight(s, now)
+		ws := w * s.Score
 
 		// Threshold method stats
 		p := s.Params
 		if p.UseAdaptive {
-			adaptiveCount++
-			adaptiveScore += s.Score
+			adaptiveCount += w
+			adaptiveScore += ws
 		} else if p.UseOtsu {
-			otsuCount++
-			otsuScore += s.Score
+			otsuCount += w
+			otsuScore += ws
 		} else if p.FixedThreshold > 0 {
-			fixedCount++
-			fixedScore += s.Score
-			fixedThresholdScores[p.FixedThreshold] += s.Score
+			fixedCount += w
+			fixedScore += ws
+			fixedThresholdScores[p.FixedThreshold] += ws
 		} else if p.BrightestPercent > 0 {
-			histCount++
-			histScore += s.Score
+			histCount += w
+			histScore += ws
 		}
 
 		// CLAHE stats
 		if p.CLAHEClipLimit > 0 {
-			claheClipScores[p.CLAHEClipLimit] += s.Score
-			claheTileScores[p.CLAHETileSize] += s.Score
+			claheClipScores[p.CLAHEClipLimit] += ws
+			claheTileScores[p.CLAHETileSize] += ws
 		}
 
 		// Scale stats
 		if p.MinScaleDim > 0 {
-			scaleScores[p.MinScaleDim] += s.Score
+			scaleScores[p.MinScaleDim] += ws
 		}
 
 		// PSM stats
-		psmScores[p.PSMMode] += s.Score
-		psmCounts[p.PSMMode]++
+		psmScores[p.PSMMode] += ws
+		psmCounts[p.PSMMode] += w
+
+		// OEM stats
+		oemScores[p.OEM] += ws
+		oemCounts[p.OEM] += w
 
 		// Invert polarity stats
 		if p.InvertPolarity {
-			invertTrueCount++
-			invertTrueScore += s.Score
+			invertTrueCount += w
+			invertTrueScore += ws
 		} else {
-			invertFalseCount++
-			invertFalseScore += s.Score
+			invertFalseCount += w
+			invertFalseScore += ws
 		}
 	}
 
 	// Calculate rates
-	total := float64(otsuCount + fixedCount + adaptiveCount + histCount)
-	if total > 0 {
-		if otsuCount > 0 {
-			db.ParamStats.OtsuSuccessRate = otsuScore / float64(otsuCount)
-		}
-		if fixedCount > 0 {
-			db.ParamStats.FixedSuccessRate = fixedScore / float64(fixedCount)
-		}
-		if adaptiveCount > 0 {
-			db.ParamStats.AdaptiveSuccessRate = adaptiveScore / float64(adaptiveCount)
-		}
-		if histCount > 0 {
-			db.ParamStats.HistogramSuccessRate = histScore / float64(histCount)
-		}
+	if otsuCount > 0 {
+		ps.OtsuSuccessRate = otsuScore / otsuCount
+	}
+	if fixedCount > 0 {
+		ps.FixedSuccessRate = fixedScore / fixedCount
+	}
+	if adaptiveCount > 0 {
+		ps.AdaptiveSuccessRate = adaptiveScore / adaptiveCount
+	}
+	if histCount > 0 {
+		ps.HistogramSuccessRate = histScore / histCount
 	}
 
 	// Find best fixed thresholds
@@ -839,7 +1359,7 @@ func (db *GlobalTrainingDB) updateStats() {
 		}
 	}
 	for i := 0; i < len(thresholds) && i < 5; i++ {
-		db.ParamStats.BestFixedThresholds = append(db.ParamStats.BestFixedThresholds, thresholds[i].thresh)
+		ps.BestFixedThresholds = append(ps.BestFixedThresholds, thresholds[i].thresh)
 	}
 
 	// Find best CLAHE settings
@@ -850,7 +1370,7 @@ func (db *GlobalTrainingDB) updateStats() {
 			bestClipScore = score
 		}
 	}
-	db.ParamStats.BestCLAHEClip = bestClip
+	ps.BestCLAHEClip = bestClip
 
 	bestTile, bestTileScore := 8, 0.0
 	for tile, score := range claheTileScores {
@@ -859,7 +1379,7 @@ func (db *GlobalTrainingDB) updateStats() {
 			bestTileScore = score
 		}
 	}
-	db.ParamStats.BestCLAHETile = bestTile
+	ps.BestCLAHETile = bestTile
 
 	// Find best scale
 	bestScale, bestScaleScore := 150, 0.0
@@ -869,54 +1389,78 @@ func (db *GlobalTrainingDB) updateStats() {
 			bestScaleScore = score
 		}
 	}
-	db.ParamStats.BestMinScale = bestScale
+	ps.BestMinScale = bestScale
 
 	// PSM mode stats
 	for psm, score := range psmScores {
 		if psmCounts[psm] > 0 {
-			db.ParamStats.PSMModeStats[psm] = score / float64(psmCounts[psm])
+			ps.PSMModeStats[psm] = score / psmCounts[psm]
+		}
+	}
+
+	// OEM mode stats
+	for oem, score := range oemScores {
+		if oemCounts[oem] > 0 {
+			ps.OEMModeStats[oem] = score / oemCounts[oem]
 		}
 	}
 
 	// Invert polarity rates
 	if invertTrueCount > 0 {
-		db.ParamStats.InvertTrueRate = invertTrueScore / float64(invertTrueCount)
+		ps.InvertTrueRate = invertTrueScore / invertTrueCount
 	}
 	if invertFalseCount > 0 {
-		db.ParamStats.InvertFalseRate = invertFalseScore / float64(invertFalseCount)
+		ps.InvertFalseRate = invertFalseScore / invertFalseCount
 	}
+	return ps
 }
 
-// GetRecommendedParams returns OCR parameters based on accumulated training data.
+// GetRecommendedParams returns OCR parameters based on accumulated training data,
+// weighted by db.Recommendation. If that is unset every good sample counts
+// the same, as in databases saved before weighting existed.
 // If no training data, returns defaults.
 func (db *GlobalTrainingDB) GetRecommendedParams() OCRParams {
+	var opts RecommendationOptions
+	if db.Recommendation != nil {
+		opts = *db.Recommendation
+	}
+	return db.GetRecommendedParamsWith(opts)
+}
+
+// GetRecommendedParamsWith is GetRecommendedParams with explicit sample
+// weighting. Zero options use the unweighted stored statistics.
+func (db *GlobalTrainingDB) GetRecommendedParamsWith(opts RecommendationOptions) OCRParams {
 	if len(db.Samples) < 5 {
 		// Not enough data, use defaults
 		return DefaultOCRParams()
 	}
 
+	ps := db.ParamStats
+	if opts != (RecommendationOptions{}) || ps == nil {
+		ps = db.paramStats(opts, time.Now())
+	}
 	params := OCRParams{}
 
 	// Choose threshold method based on success rates
-	bestRate := db.ParamStats.OtsuSuccessRate
+	bestRate := ps.OtsuSuccessRate
 	method := "otsu"
-	if db.ParamStats.FixedSuccessRate > bestRate {
-		bestRate = db.ParamStats.FixedSuccessRate
+	if ps.FixedSuccessRate > bestRate {
+		bestRate = ps.FixedSuccessRate
 		method = "fixed"
 	}
-	if db.ParamStats.AdaptiveSuccessRate > bestRate {
-		bestRate = db.ParamStats.AdaptiveSuccessRate
+	if ps.AdaptiveSuccessRate > bestRate {
+		bestRate = ps.AdaptiveSuccessRate
 		method = "adaptive"
 	}
 
 	switch method {
 	case "otsu":
 		params.UseOtsu = true
-		params.CLAHEClipLimit = db.ParamStats.BestCLAHEClip
-		params.CLAHETileSize = db.ParamStats.BestCLAHETile
+		params.CLAHEClipLimit = ps.BestCLAHEClip
+		params.CLAHETileSize = ps.BestCLAHETile
 	case "fixed":
-		if len(db.ParamStats.BestFixedThresholds) > 0 {
-			params.FixedThreshold = db.ParamStats.BestFixedThresholds[0]
+		if len(ps.BestFixedThresholds) > 0 {
+			params.FixedThreshold = ps.BestFixedThresholds[0]
 		} else {
 			params.FixedThreshold = 130
 		}
@@ -927,24 +1471,24 @@ func (db *GlobalTrainingDB) GetRecommendedParams() OCRParams {
 	}
 
 	// Always apply CLAHE from training data - critical for all threshold methods
-	if params.CLAHEClipLimit == 0 && db.ParamStats.BestCLAHEClip > 0 {
-		params.CLAHEClipLimit = db.ParamStats.BestCLAHEClip
-		params.CLAHETileSize = db.ParamStats.BestCLAHETile
+	if params.CLAHEClipLimit == 0 && ps.BestCLAHEClip > 0 {
+		params.CLAHEClipLimit = ps.BestCLAHEClip
+		params.CLAHETileSize = ps.BestCLAHETile
 	}
 
 	// Set scale
-	params.MinScaleDim = db.ParamStats.BestMinScale
+	params.MinScaleDim = ps.BestMinScale
 	if params.MinScaleDim == 0 {
 		params.MinScaleDim = 150
 	}
 
 	// Set invert polarity based on success rates
-	params.InvertPolarity = db.ParamStats.InvertTrueRate >= db.ParamStats.InvertFalseRate
+	params.InvertPolarity = ps.InvertTrueRate >= ps.InvertFalseRate
 
 	// Find best PSM mode
 	bestPSM := 6
 	bestPSMRate := 0.0
-	for psm, rate := range db.ParamStats.PSMModeStats {
+	for psm, rate := range ps.PSMModeStats {
 		if rate > bestPSMRate {
 			bestPSM = psm
 			bestPSMRate = rate
@@ -952,6 +1496,18 @@ func (db *GlobalTrainingDB) GetRecommendedParams() OCRParams {
 	}
 	params.PSMMode = bestPSM
 
+	// Find best OEM. Samples from before OEM existed record the engine
+	// default, which says nothing about the engine, so they don't vote; LSTM
+	// handles part-number fonts better than the legacy engine.
+	params.OEM = OEMLSTM
+	bestOEMRate := 0.0
+	for oem, rate := range ps.OEMModeStats {
+		if oem != OEMDefault && rate > bestOEMRate {
+			params.OEM = oem
+			bestOEMRate = rate
+		}
+	}
+
 	return params
 }
 
@@ -974,12 +1530,60 @@ func (db *GlobalTrainingDB) GetBestOrientation() string {
 
 // GetParamsForOrientation returns the best params seen for a specific orientation.
 func (db *GlobalTrainingDB) GetParamsForOrientation(orientation string) (OCRParams, bool) {
+	return db.bestParamsWhere(func(s GlobalTrainingSample) bool {
+		return s.Orientation == orientation
+	})
+}
+
+// GetParamsForManufacturer returns the best params seen for parts from mfr,
+// in any orientation. Manufacturer names are compared case-insensitively.
+func (db *GlobalTrainingDB) GetParamsForManufacturer(mfr string) (OCRParams, bool) {
+	if strings.TrimSpace(mfr) == "" {
+		return OCRParams{}, false
+	}
+	return db.bestParamsWhere(func(s GlobalTrainingSample) bool {
+		return sameName(s.Manufacturer, mfr)
+	})
+}
+
+// GetParamsFor returns the best params for a part, narrowing by as many of
+// manufacturer, package and orientation as have training samples: all
+// three, then manufacturer and orientation, then manufacturer alone, then
+// orientation alone, then GetRecommendedParams. Empty mfr or pkg skips the
+// levels that need it. The second result describes the level used.
+func (db *GlobalTrainingDB) GetParamsFor(mfr, pkg, orientation string) (OCRParams, string) {
+	if strings.TrimSpace(mfr) != "" {
+		if strings.TrimSpace(pkg) != "" {
+			if p, ok := db.bestParamsWhere(func(s GlobalTrainingSample) bool {
+				return sameName(s.Manufacturer, mfr) && sameName(s.Package, pkg) && s.Orientation == orientation
+			}); ok {
+				return p, fmt.Sprintf("%s/%s/%s", mfr, pkg, orientation)
+			}
+		}
+		if p, ok := db.bestParamsWhere(func(s GlobalTrainingSample) bool {
+			return sameName(s.Manufacturer, mfr) && s.Orientation == orientation
+		}); ok {
+			return p, fmt.Sprintf("%s/%s", mfr, orientation)
+		}
+		if p, ok := db.GetParamsForManufacturer(mfr); ok {
+			return p, mfr
+		}
+	}
+	if p, ok := db.GetParamsForOrientation(orientation); ok {
+		return p, orientation
+	}
+	return db.GetRecommendedParams(), "recommended"
+}
+
+// bestParamsWhere returns the params of the highest-scoring sample that
+// satisfies match.
+func (db *GlobalTrainingDB) bestParamsWhere(match func(GlobalTrainingSample) bool) (OCRParams, bool) {
 	var bestParams OCRParams
 	bestScore := 0.0
 	found := false
 
 	for _, s := range db.Samples {
-		if s.Orientation == orientation && s.Score > bestScore {
+		if s.Score > bestScore && match(s) {
 			bestParams = s.Params
 			bestScore = s.Score
 			found = true
@@ -989,6 +1593,13 @@ func (db *GlobalTrainingDB) GetParamsForOrientation(orientation string) (OCRPara
 	return bestParams, found
 }
 
+// sameName compares manufacturer or package names ignoring case and
+// surrounding space. Empty names never match.
+func sameName(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	return a != "" && strings.EqualFold(a, b)
+}
+
 // Summary returns a human-readable summary of the training database.
 func (db *GlobalTrainingDB) Summary() string {
 	if len(db.Samples) == 0 {
@@ -1096,11 +1707,14 @@ func LoadGlobalTraining() (*GlobalTrainingDB, error) {
 					db.OrientationStats = make(map[string]*OrientationStats)
 				}
 				if db.ParamStats == nil {
-					db.ParamStats = &ParamStatistics{PSMModeStats: make(map[int]float64)}
+					db.ParamStats = &ParamStatistics{PSMModeStats: make(map[int]float64), OEMModeStats: make(map[OEMMode]float64)}
 				}
 				if db.ParamStats.PSMModeStats == nil {
 					db.ParamStats.PSMModeStats = make(map[int]float64)
 				}
+				if db.ParamStats.OEMModeStats == nil {
+					db.ParamStats.OEMModeStats = make(map[OEMMode]float64)
+				}
 				fmt.Printf("Loaded OCR training database: %d samples from %s\n", len(db.Samples), libPath)
 				return &db, nil
 			}
@@ -1130,11 +1744,14 @@ func LoadGlobalTraining() (*GlobalTrainingDB, error) {
 		db.OrientationStats = make(map[string]*OrientationStats)
 	}
 	if db.ParamStats == nil {
-		db.ParamStats = &ParamStatistics{PSMModeStats: make(map[int]float64)}
+		db.ParamStats = &ParamStatistics{PSMModeStats: make(map[int]float64), OEMModeStats: make(map[OEMMode]float64)}
 	}
 	if db.ParamStats.PSMModeStats == nil {
 		db.ParamStats.PSMModeStats = make(map[int]float64)
 	}
+	if db.ParamStats.OEMModeStats == nil {
+		db.ParamStats.OEMModeStats = make(map[OEMMode]float64)
+	}
 
 	fmt.Printf("Loaded OCR training database: %d samples from %s\n", len(db.Samples), path)
 	return &db, nil
@@ -0,0 +1,160 @@
+package ocr
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHistogramThreshold(t *testing.T) {
+	// 100 pixels: 90 dark at 20, 5 faint at 90, 5 bright at 200
+	gray := make([]uint8, 100)
+	for i := range gray {
+		switch {
+		case i < 90:
+			gray[i] = 20
+		case i < 95:
+			gray[i] = 90
+		default:
+			gray[i] = 200
+		}
+	}
+	cases := []struct {
+		pct    float64
+		minThr int
+		want   uint8
+	}{
+		{5, 0, 200},
+		{10, 0, 90},
+		{10, 128, 128},
+		{50, 0, 20},
+	}
+	for _, c := range cases {
+		if got := HistogramThreshold(gray, c.pct, c.minThr); got != c.want {
+			t.Errorf("HistogramThreshold(%v%%, min %d) = %d, want %d", c.pct, c.minThr, got, c.want)
+		}
+	}
+}
+
+func TestOCRParamsValidate(t *testing.T) {
+	cases := []struct {
+		psm     int
+		oem     OEMMode
+		wantErr string // Substring of the error, "" for valid
+	}{
+		{MinPSMMode, OEMDefault, ""},
+		{7, OEMLSTM, ""},
+		{MaxPSMMode, OEMCombined, ""},
+		{-1, OEMLSTM, "invalid PSM mode -1: must be 0-13"},
+		{14, OEMLSTM, "invalid PSM mode 14"},
+		{99, OEMDefault, "invalid PSM mode 99"},
+		{7, OEMCombined + 1, "invalid OEM mode"},
+		{7, -1, "invalid OEM mode -1"},
+	}
+	for _, c := range cases {
+		params := DefaultOCRParams()
+		params.PSMMode = c.psm
+		params.OEM = c.oem
+		err := params.Validate()
+		if c.wantErr == "" {
+			if err != nil {
+				t.Errorf("PSM %d OEM %d: %v", c.psm, c.oem, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("PSM %d OEM %d: error %v, want %q", c.psm, c.oem, err, c.wantErr)
+		}
+	}
+	if err := DefaultOCRParams().Validate(); err != nil {
+		t.Errorf("DefaultOCRParams: %v", err)
+	}
+}
+
+func TestOCRParamsUsesHistogram(t *testing.T) {
+	cases := []struct {
+		params OCRParams
+		want   bool
+	}{
+		{OCRParams{BrightestPercent: 5}, true},
+		{OCRParams{BrightestPercent: 5, UseOtsu: true}, false},
+		{OCRParams{BrightestPercent: 5, FixedThreshold: 120}, false},
+		{OCRParams{BrightestPercent: 5, UseAdaptive: true}, false},
+		{OCRParams{}, false},
+		{DefaultOCRParams(), false},
+	}
+	for _, c := range cases {
+		if got := c.params.UsesHistogram(); got != c.want {
+			t.Errorf("%+v.UsesHistogram() = %v, want %v", c.params, got, c.want)
+		}
+	}
+}
+
+func TestRecommendedOEMRoundTrip(t *testing.T) {
+	db := NewGlobalTrainingDB()
+	add := func(n int, oem OEMMode, score float64) {
+		for i := 0; i < n; i++ {
+			db.AddSample(GlobalTrainingSample{
+				GroundTruth: "74LS00",
+				Score:       score,
+				Params:      OCRParams{UseOtsu: true, PSMMode: 7, OEM: oem},
+			})
+		}
+	}
+	add(3, OEMDefault, 1.0) // Saved before OEM existed
+	add(3, OEMLSTM, 0.8)
+	add(3, OEMCombined, 0.9)
+
+	data, err := json.Marshal(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded GlobalTrainingDB
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Samples[len(loaded.Samples)-1].Params.OEM; got != OEMCombined {
+		t.Errorf("sample OEM after reload = %d, want %d", got, OEMCombined)
+	}
+	if got := loaded.ParamStats.OEMModeStats[OEMCombined]; math.Abs(got-0.9) > 1e-9 {
+		t.Errorf("OEMCombined rate after reload = %v, want 0.9", got)
+	}
+	if got := loaded.GetRecommendedParamsWith(RecommendationOptions{}); got.OEM != OEMCombined || got.PSMMode != 7 {
+		t.Errorf("recommended PSM %d OEM %d, want PSM 7 OEM %d", got.PSMMode, got.OEM, OEMCombined)
+	}
+
+	// Without OEM statistics, as in databases saved before this field,
+	// the recommendation stays LSTM
+	loaded.ParamStats.OEMModeStats = nil
+	if got := loaded.GetRecommendedParamsWith(RecommendationOptions{}); got.OEM != OEMLSTM {
+		t.Errorf("recommended OEM without stats = %d, want %d", got.OEM, OEMLSTM)
+	}
+}
+
+func TestGetRecommendedParamsWeighting(t *testing.T) {
+	db := NewGlobalTrainingDB()
+	add := func(n, scale int, score float64) {
+		for i := 0; i < n; i++ {
+			db.AddSample(GlobalTrainingSample{
+				GroundTruth: "74LS00",
+				Score:       score,
+				Params:      OCRParams{UseOtsu: true, PSMMode: 7, MinScaleDim: scale},
+			})
+		}
+	}
+	// Unweighted, five 0.72 samples outscore three perfect ones
+	add(5, 150, 0.72)
+	add(3, 200, 1.0)
+
+	if got := db.GetRecommendedParams().MinScaleDim; got != 150 {
+		t.Errorf("unset Recommendation: scale %d, want 150 as before weighting", got)
+	}
+	opts := DefaultRecommendationOptions()
+	db.Recommendation = &opts
+	if got := db.GetRecommendedParams().MinScaleDim; got != 200 {
+		t.Errorf("default weighting: scale %d, want 200", got)
+	}
+	db.Recommendation = &RecommendationOptions{}
+	if got := db.GetRecommendedParams().MinScaleDim; got != 150 {
+		t.Errorf("zero options: scale %d, want 150", got)
+	}
+}
@@ -0,0 +1,286 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshots are full copies of a project file kept in a directory next to
+// it (project.pcbproj -> project.snapshots/). Each file is named
+// "<timestamp>_<name>.pcbproj" and holds the project exactly as it would be
+// saved at the project path, so reverting is a plain copy back.
+
+const (
+	snapshotTimeFormat = "20060102-150405.000"
+	snapshotExt        = ".pcbproj"
+)
+
+// Marshaler serializes the live project as it would be saved to path.
+// app.State implements it.
+type Marshaler interface {
+	MarshalProject(path string) ([]byte, error)
+}
+
+// SnapshotInfo describes one saved snapshot.
+type SnapshotInfo struct {
+	Name    string
+	Created time.Time
+	Path    string
+}
+
+// SnapshotDir returns the directory holding projectPath's snapshots.
+func SnapshotDir(projectPath string) string {
+	return strings.TrimSuffix(projectPath, filepath.Ext(projectPath)) + ".snapshots"
+}
+
+// snapshotFileName returns a file-system-safe snapshot file name.
+func snapshotFileName(name string, created time.Time) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	return created.Format(snapshotTimeFormat) + "_" + safe + snapshotExt
+}
+
+// parseSnapshotFileName recovers the snapshot name and time from a file name.
+func parseSnapshotFileName(file string) (SnapshotInfo, bool) {
+	base := strings.TrimSuffix(file, snapshotExt)
+	stamp, name, ok := strings.Cut(base, "_")
+	if !ok || base == file {
+		return SnapshotInfo{}, false
+	}
+	created, err := time.ParseInLocation(snapshotTimeFormat, stamp, time.Local)
+	if err != nil {
+		return SnapshotInfo{}, false
+	}
+	return SnapshotInfo{Name: name, Created: created}, true
+}
+
+// Snapshot writes the live project to a new named snapshot of projectPath.
+func Snapshot(state Marshaler, projectPath, name string) (SnapshotInfo, error) {
+	if projectPath == "" {
+		return SnapshotInfo{}, fmt.Errorf("project has not been saved yet")
+	}
+	if strings.TrimSpace(name) == "" {
+		return SnapshotInfo{}, fmt.Errorf("snapshot name is required")
+	}
+
+	// Serialize for the project path so relative image paths stay valid
+	// when the snapshot is copied back on revert
+	data, err := state.MarshalProject(projectPath)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	dir := SnapshotDir(projectPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return SnapshotInfo{}, err
+	}
+	created := time.Now()
+	info := SnapshotInfo{
+		Name:    strings.TrimSpace(name),
+		Created: created,
+		Path:    filepath.Join(dir, snapshotFileName(name, created)),
+	}
+	if err := os.WriteFile(info.Path, data, 0644); err != nil {
+		return SnapshotInfo{}, err
+	}
+	return info, nil
+}
+
+// ListSnapshots returns projectPath's snapshots, newest first. A project
+// without snapshots returns an empty list.
+func ListSnapshots(projectPath string) ([]SnapshotInfo, error) {
+	dir := SnapshotDir(projectPath)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snaps []SnapshotInfo
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, ok := parseSnapshotFileName(e.Name())
+		if !ok {
+			continue
+		}
+		info.Path = filepath.Join(dir, e.Name())
+		snaps = append(snaps, info)
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		if !snaps[i].Created.Equal(snaps[j].Created) {
+			return snaps[i].Created.After(snaps[j].Created)
+		}
+		return snaps[i].Path > snaps[j].Path
+	})
+	return snaps, nil
+}
+
+// Revert replaces the project file with snap. The live project is first
+// saved as a "Before revert" snapshot so the revert itself can be undone.
+// The caller reloads the project afterwards.
+func Revert(state Marshaler, projectPath string, snap SnapshotInfo) (SnapshotInfo, error) {
+	data, err := os.ReadFile(snap.Path)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	backup, err := Snapshot(state, projectPath, "Before revert to "+snap.Name)
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("saving pre-revert snapshot: %w", err)
+	}
+	if err := os.WriteFile(projectPath, data, 0644); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// snapshotContents is the part of a project file Diff compares.
+type snapshotContents struct {
+	Components []struct {
+		ID         string `json:"id"`
+		PartNumber string `json:"part_number"`
+	} `json:"components"`
+	Nets []struct {
+		ID           string   `json:"id"`
+		Name         string   `json:"name"`
+		ConnectorIDs []string `json:"connector_ids"`
+		ViaIDs       []string `json:"via_ids"`
+		TraceIDs     []string `json:"trace_ids"`
+		PadIDs       []string `json:"pad_ids"`
+	} `json:"nets"`
+}
+
+func loadSnapshotContents(path string) (*snapshotContents, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c snapshotContents
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return &c, nil
+}
+
+// DiffSummary lists what changed between two project files. Components are
+// matched by ID; nets by ID, and count as changed when their name or
+// membership differs.
+type DiffSummary struct {
+	ComponentsAdded   []string
+	ComponentsRemoved []string
+	ComponentsChanged []string // Part number differs
+	NetsAdded         []string
+	NetsRemoved       []string
+	NetsChanged       []string
+}
+
+// Empty reports whether the two files have no differences Diff tracks.
+func (d *DiffSummary) Empty() bool {
+	return len(d.ComponentsAdded)+len(d.ComponentsRemoved)+len(d.ComponentsChanged)+
+		len(d.NetsAdded)+len(d.NetsRemoved)+len(d.NetsChanged) == 0
+}
+
+// String formats the summary one category per line.
+func (d *DiffSummary) String() string {
+	if d.Empty() {
+		return "No component or net changes"
+	}
+	var sb strings.Builder
+	line := func(label string, ids []string) {
+		if len(ids) > 0 {
+			fmt.Fprintf(&sb, "%s (%d): %s\n", label, len(ids), strings.Join(ids, ", "))
+		}
+	}
+	line("Components added", d.ComponentsAdded)
+	line("Components removed", d.ComponentsRemoved)
+	line("Components changed", d.ComponentsChanged)
+	line("Nets added", d.NetsAdded)
+	line("Nets removed", d.NetsRemoved)
+	line("Nets changed", d.NetsChanged)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Diff compares project files a (older) and b (newer), e.g. two snapshots
+// or a snapshot and the project file.
+func Diff(a, b string) (*DiffSummary, error) {
+	ca, err := loadSnapshotContents(a)
+	if err != nil {
+		return nil, err
+	}
+	cb, err := loadSnapshotContents(b)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiffSummary{}
+
+	oldParts := make(map[string]string, len(ca.Components))
+	for _, c := range ca.Components {
+		oldParts[c.ID] = c.PartNumber
+	}
+	newIDs := make(map[string]bool, len(cb.Components))
+	for _, c := range cb.Components {
+		newIDs[c.ID] = true
+		part, existed := oldParts[c.ID]
+		switch {
+		case !existed:
+			d.ComponentsAdded = append(d.ComponentsAdded, c.ID)
+		case part != c.PartNumber:
+			d.ComponentsChanged = append(d.ComponentsChanged, c.ID)
+		}
+	}
+	for _, c := range ca.Components {
+		if !newIDs[c.ID] {
+			d.ComponentsRemoved = append(d.ComponentsRemoved, c.ID)
+		}
+	}
+
+	// Nets are reported by name; membership is compared order-insensitively
+	netKey := func(name string, ids ...[]string) string {
+		var all []string
+		for _, group := range ids {
+			all = append(all, group...)
+		}
+		slices.Sort(all)
+		return name + "\x00" + strings.Join(all, "\x00")
+	}
+	oldNets := make(map[string]string, len(ca.Nets))
+	for _, n := range ca.Nets {
+		oldNets[n.ID] = netKey(n.Name, n.ConnectorIDs, n.ViaIDs, n.TraceIDs, n.PadIDs)
+	}
+	newNets := make(map[string]bool, len(cb.Nets))
+	for _, n := range cb.Nets {
+		newNets[n.ID] = true
+		key, existed := oldNets[n.ID]
+		switch {
+		case !existed:
+			d.NetsAdded = append(d.NetsAdded, n.Name)
+		case key != netKey(n.Name, n.ConnectorIDs, n.ViaIDs, n.TraceIDs, n.PadIDs):
+			d.NetsChanged = append(d.NetsChanged, n.Name)
+		}
+	}
+	for _, n := range ca.Nets {
+		if !newNets[n.ID] {
+			d.NetsRemoved = append(d.NetsRemoved, n.Name)
+		}
+	}
+
+	for _, ids := range [][]string{d.ComponentsAdded, d.ComponentsRemoved, d.ComponentsChanged,
+		d.NetsAdded, d.NetsRemoved, d.NetsChanged} {
+		sort.Strings(ids)
+	}
+	return d, nil
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	older := write("older.json", `{
+		"components": [
+			{"id": "U1", "part_number": "74LS00"},
+			{"id": "U2", "part_number": "74LS04"},
+			{"id": "U3", "part_number": "7805"}
+		],
+		"nets": [
+			{"id": "n1", "name": "CLK", "via_ids": ["v1", "v2"], "pad_ids": ["U1.1"]},
+			{"id": "n2", "name": "RST", "via_ids": ["v3"]},
+			{"id": "n3", "name": "D0", "trace_ids": ["t1"]},
+			{"id": "n4", "name": "D1", "via_ids": ["v5"]}
+		]
+	}`)
+	newer := write("newer.json", `{
+		"components": [
+			{"id": "U1", "part_number": "74LS00"},
+			{"id": "U2", "part_number": "74LS14"},
+			{"id": "U4", "part_number": "74LS74"}
+		],
+		"nets": [
+			{"id": "n1", "name": "CLK", "pad_ids": ["U1.1"], "via_ids": ["v2", "v1"]},
+			{"id": "n2", "name": "RST", "via_ids": ["v3", "v4"]},
+			{"id": "n4", "name": "DATA1", "via_ids": ["v5"]},
+			{"id": "n5", "name": "A0", "connector_ids": ["c7"]}
+		]
+	}`)
+
+	d, err := Diff(older, newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		what      string
+		got, want []string
+	}{
+		{"components added", d.ComponentsAdded, []string{"U4"}},
+		{"components removed", d.ComponentsRemoved, []string{"U3"}},
+		{"components changed", d.ComponentsChanged, []string{"U2"}},
+		{"nets added", d.NetsAdded, []string{"A0"}},
+		{"nets removed", d.NetsRemoved, []string{"D0"}},
+		{"nets changed", d.NetsChanged, []string{"DATA1", "RST"}}, // Renamed, new member
+	} {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s = %v, want %v", c.what, c.got, c.want)
+		}
+	}
+	if d.Empty() {
+		t.Error("Empty() = true for differing files")
+	}
+
+	same, err := Diff(older, older)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same.Empty() || same.String() != "No component or net changes" {
+		t.Errorf("Diff of a file with itself = %q", same)
+	}
+
+	if _, err := Diff(older, filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Diff with a missing file: want error")
+	}
+	if _, err := Diff(write("bad.json", "{"), newer); err == nil {
+		t.Error("Diff with malformed JSON: want error")
+	}
+}
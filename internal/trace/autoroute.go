@@ -0,0 +1,208 @@
+package trace
+
+import (
+	"container/heap"
+	"image"
+	"math"
+
+	"pcb-tracer/pkg/colorutil"
+	"pcb-tracer/pkg/geometry"
+)
+
+// MaxAutoRoutePixels caps the search area of AutoRoute. The search keeps
+// per-direction state for every pixel, so larger areas cost too much memory;
+// routes that need more are left to manual waypoints.
+const MaxAutoRoutePixels = 1 << 20
+
+// AutoRouteParams configures AutoRoute.
+type AutoRouteParams struct {
+	Cost       AutoTraceParams  // Step and turn weights; Cost.Copper is the fallback copper model
+	Classifier *TraceClassifier // Trained copper model; nil or untrained uses Cost.Copper
+	CopperMask *image.Gray      // Cost.Copper mask of the image (Layer.CopperMask); nil thresholds each pixel
+	Margin     float64          // Search padding around the endpoints' bounding box (pixels)
+	Simplify   float64          // SimplifyPath epsilon for the returned polyline (pixels)
+}
+
+// DefaultAutoRouteParams returns the default auto-trace weights with a
+// 60-pixel search margin and 2-pixel simplification.
+func DefaultAutoRouteParams() AutoRouteParams {
+	return AutoRouteParams{
+		Cost:     DefaultAutoTraceParams(),
+		Margin:   60,
+		Simplify: 2,
+	}
+}
+
+// routeDirs are the 8 neighbor steps in circular order, so the turn between
+// directions i and j is 45° × their circular distance.
+var routeDirs = [8]image.Point{
+	{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1},
+}
+
+// AutoRoute searches for a copper path from start to end on img (image
+// coordinates) for a "follow copper" trace. Each pixel near the endpoints
+// gets a copper likelihood, from params.Classifier when it was trained for
+// layer and from the Cost.Copper HSV range otherwise (read from
+// params.CopperMask when set). An A* search then minimizes the same step and
+// turn costs EvaluatePathCost reports, with the step cost blended by the
+// likelihood. The path is simplified and pinned to start and end exactly.
+//
+// When the search area is empty or too large, or the best path costs no
+// less than a straight line, the straight line [start, end] is returned
+// with ok false.
+func AutoRoute(img image.Image, start, end geometry.Point2D, layer TraceLayer, params AutoRouteParams) (path []geometry.Point2D, ok bool) {
+	straight := []geometry.Point2D{start, end}
+
+	margin := max(params.Margin, start.Distance(end)/4)
+	roi := image.Rect(
+		int(math.Floor(math.Min(start.X, end.X)-margin)), int(math.Floor(math.Min(start.Y, end.Y)-margin)),
+		int(math.Ceil(math.Max(start.X, end.X)+margin))+1, int(math.Ceil(math.Max(start.Y, end.Y)+margin))+1,
+	).Intersect(img.Bounds())
+	sp := image.Pt(int(math.Round(start.X)), int(math.Round(start.Y)))
+	ep := image.Pt(int(math.Round(end.X)), int(math.Round(end.Y)))
+	if roi.Empty() || roi.Dx()*roi.Dy() > MaxAutoRoutePixels || !sp.In(roi) || !ep.In(roi) || sp == ep {
+		return straight, false
+	}
+
+	field := copperLikelihood(img, roi, layer, params)
+	raw, found := routeSearch(field, roi, sp, ep, params.Cost)
+	if !found {
+		return straight, false
+	}
+
+	path = SimplifyPath(raw, params.Simplify)
+	path[0], path[len(path)-1] = start, end
+
+	// Judge both candidates on the thresholded field, as the path cost readout does
+	mask := image.NewGray(roi)
+	for i, p := range field {
+		if p >= 0.5 {
+			mask.Pix[i] = 255
+		}
+	}
+	if EvaluatePathCost(mask, path, params.Cost).Total >= EvaluatePathCost(mask, straight, params.Cost).Total {
+		return straight, false
+	}
+	return path, true
+}
+
+// copperLikelihood returns the copper probability (0-1) of each pixel of roi
+// in row-major order.
+func copperLikelihood(img image.Image, roi image.Rectangle, layer TraceLayer, params AutoRouteParams) []float32 {
+	cl := params.Classifier
+	if cl != nil && (!cl.Trained || cl.Layer != layer) {
+		cl = nil
+	}
+	field := make([]float32, roi.Dx()*roi.Dy())
+	mask := params.CopperMask
+	if cl != nil || (mask != nil && !roi.In(mask.Bounds())) {
+		mask = nil
+	}
+	i := 0
+	for y := roi.Min.Y; y < roi.Max.Y; y++ {
+		for x := roi.Min.X; x < roi.Max.X; x++ {
+			if mask != nil {
+				if mask.GrayAt(x, y).Y != 0 {
+					field[i] = 1
+				}
+				i++
+				continue
+			}
+			r, g, b, _ := img.At(x, y).RGBA()
+			h, s, v := colorutil.RGBToHSV(float64(r>>8), float64(g>>8), float64(b>>8))
+			switch {
+			case cl != nil:
+				field[i] = float32(cl.ScorePixel(h, s, v))
+			case params.Cost.Copper.Contains(h, s, v):
+				field[i] = 1
+			}
+			i++
+		}
+	}
+	return field
+}
+
+// routeSearch runs A* over (pixel, arrival direction) states so turns can
+// be charged. Returns the pixel path from sp to ep in image coordinates.
+func routeSearch(field []float32, roi image.Rectangle, sp, ep image.Point, cost AutoTraceParams) ([]geometry.Point2D, bool) {
+	w, h := roi.Dx(), roi.Dy()
+	onCost, offCost := cost.StepCost(true, 1), cost.StepCost(false, 1)
+	var turnCost [5]float64
+	for k := range turnCost {
+		turnCost[k] = cost.TurnCost(float64(45 * k))
+	}
+	heuristic := func(x, y int) float64 {
+		return onCost * math.Hypot(float64(ep.X-roi.Min.X-x), float64(ep.Y-roi.Min.Y-y))
+	}
+
+	n := w * h * len(routeDirs)
+	g := make([]float32, n)
+	parent := make([]int32, n)
+	closed := make([]bool, n)
+	for i := range g {
+		g[i] = float32(math.Inf(1))
+		parent[i] = -1
+	}
+
+	// Seed every arrival direction at the start so the first step is free
+	// of turn cost. Queue items carry the state index in x.
+	pq := &pathQueue{}
+	sx, sy := sp.X-roi.Min.X, sp.Y-roi.Min.Y
+	for d := range routeDirs {
+		s := (sy*w+sx)*len(routeDirs) + d
+		g[s] = 0
+		heap.Push(pq, &pathItem{x: s, f: heuristic(sx, sy)})
+	}
+
+	goal := (ep.Y-roi.Min.Y)*w + (ep.X - roi.Min.X)
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(*pathItem)
+		s := item.x
+		if closed[s] {
+			continue
+		}
+		closed[s] = true
+		px, d := s/len(routeDirs), s%len(routeDirs)
+		if px == goal {
+			return routePath(parent, s, w, roi.Min), true
+		}
+
+		x, y := px%w, px/w
+		for nd, step := range routeDirs {
+			nx, ny := x+step.X, y+step.Y
+			if nx < 0 || nx >= w || ny < 0 || ny >= h {
+				continue
+			}
+			length := 1.0
+			if step.X != 0 && step.Y != 0 {
+				length = math.Sqrt2
+			}
+			p := float64(field[ny*w+nx])
+			turn := (nd - d + len(routeDirs)) % len(routeDirs)
+			turn = min(turn, len(routeDirs)-turn)
+			ng := float64(g[s]) + length*(p*onCost+(1-p)*offCost) + turnCost[turn]
+
+			ns := (ny*w+nx)*len(routeDirs) + nd
+			if !closed[ns] && ng < float64(g[ns]) {
+				g[ns] = float32(ng)
+				parent[ns] = int32(s)
+				heap.Push(pq, &pathItem{x: ns, f: ng + heuristic(nx, ny)})
+			}
+		}
+	}
+	return nil, false
+}
+
+// routePath walks parent links back from state s and returns the pixel path
+// in image coordinates, start first.
+func routePath(parent []int32, s, w int, origin image.Point) []geometry.Point2D {
+	var path []geometry.Point2D
+	for ; s >= 0; s = int(parent[s]) {
+		px := s / len(routeDirs)
+		path = append(path, geometry.Point2D{X: float64(origin.X + px%w), Y: float64(origin.Y + px/w)})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
@@ -0,0 +1,66 @@
+package trace
+
+import (
+	"image"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// zigzagField returns a copper likelihood field with a 3-pixel-wide copper
+// trace zigzagging at 45° about row 10, crossing it every 12 pixels, and
+// soldermask elsewhere.
+func zigzagField(roi image.Rectangle) []float32 {
+	w := roi.Dx()
+	field := make([]float32, w*roi.Dy())
+	for x := 0; x < w; x++ {
+		var y int
+		switch p := x % 24; {
+		case p <= 6:
+			y = 10 + p
+		case p <= 18:
+			y = 22 - p
+		default:
+			y = p - 14
+		}
+		for dy := -1; dy <= 1; dy++ {
+			if yy := y + dy; yy >= 0 && yy < roi.Dy() {
+				field[yy*w+x] = 1
+			}
+		}
+	}
+	return field
+}
+
+func TestRouteSearchTurnPenalty(t *testing.T) {
+	roi := image.Rect(0, 0, 50, 21)
+	field := zigzagField(roi)
+	sp, ep := image.Pt(0, 10), image.Pt(48, 10)
+
+	turns := func(penalty float64) float64 {
+		cost := DefaultAutoTraceParams()
+		cost.TurnPenalty = penalty
+		path, ok := routeSearch(field, roi, sp, ep, cost)
+		if !ok {
+			t.Fatalf("turn penalty %v: no path", penalty)
+		}
+		start, end := path[0], path[len(path)-1]
+		if start != (geometry.Point2D{X: 0, Y: 10}) || end != (geometry.Point2D{X: 48, Y: 10}) {
+			t.Fatalf("turn penalty %v: path runs %v to %v", penalty, start, end)
+		}
+		return EvaluatePathCost(nil, path, cost).TurnDegrees
+	}
+
+	// Cheap turns follow the copper zigzag; expensive ones cut straight
+	// across the soldermask
+	low, high := turns(0.1), turns(200)
+	if low < 4*90 {
+		t.Errorf("low turn penalty turned %.0f°, want the zigzag's ≥360°", low)
+	}
+	if high >= low {
+		t.Errorf("high turn penalty turned %.0f°, low %.0f°: want a straighter path", high, low)
+	}
+	if high != 0 {
+		t.Errorf("high turn penalty turned %.0f°, want a straight line", high)
+	}
+}
@@ -0,0 +1,107 @@
+package trace
+
+import (
+	"image"
+	"math"
+
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+)
+
+// AutoTraceParams weights the cost function auto-trace minimizes when it
+// searches for a path between two points. Step costs are per pixel of travel:
+// a pixel on copper costs 1-CopperReward, a pixel off copper (soldermask or
+// bare substrate) costs 1+SoldermaskPenalty. Boards with unusual copper
+// colors or contrast need different weights, so they are user-tunable.
+type AutoTraceParams struct {
+	Copper            pcbimage.CopperMaskParams `json:"copper"`             // HSV range counted as copper
+	CopperReward      float64                   `json:"copper_reward"`      // Fraction of the step cost waived on copper (0-1)
+	SoldermaskPenalty float64                   `json:"soldermask_penalty"` // Extra cost per pixel off copper
+	TurnPenalty       float64                   `json:"turn_penalty"`       // Extra cost per 45° change of direction
+}
+
+// minCopperStepCost keeps copper steps strictly positive so a full copper
+// reward can't make path length free.
+const minCopperStepCost = 0.05
+
+// DefaultAutoTraceParams returns weights that keep paths on copper unless a
+// detour is several times longer, with a mild preference for straight runs.
+func DefaultAutoTraceParams() AutoTraceParams {
+	return AutoTraceParams{
+		Copper:            pcbimage.DefaultCopperMaskParams(),
+		CopperReward:      0.8,
+		SoldermaskPenalty: 4,
+		TurnPenalty:       2,
+	}
+}
+
+// StepCost returns the cost of travelling length pixels on or off copper.
+func (p AutoTraceParams) StepCost(onCopper bool, length float64) float64 {
+	if onCopper {
+		return max(1-p.CopperReward, minCopperStepCost) * length
+	}
+	return (1 + p.SoldermaskPenalty) * length
+}
+
+// TurnCost returns the cost of changing direction by degrees (0-180).
+func (p AutoTraceParams) TurnCost(degrees float64) float64 {
+	return p.TurnPenalty * degrees / 45
+}
+
+// PathCost is the cost of a path under AutoTraceParams, with the components
+// that make it up.
+type PathCost struct {
+	Total        float64 // Step costs plus turn costs
+	Length       float64 // Path length in pixels
+	CopperLength float64 // Portion of Length on copper
+	TurnDegrees  float64 // Sum of direction changes at the vertices
+}
+
+// CopperFraction returns the share of the path length that lies on copper.
+func (c PathCost) CopperFraction() float64 {
+	if c.Length == 0 {
+		return 0
+	}
+	return c.CopperLength / c.Length
+}
+
+// EvaluatePathCost scores a polyline against a copper mask (non-zero =
+// copper, as returned by Layer.CopperMask). Each segment is sampled once per
+// pixel of length; samples outside the mask count as off copper.
+func EvaluatePathCost(mask *image.Gray, path []geometry.Point2D, params AutoTraceParams) PathCost {
+	var c PathCost
+	prevHeading, havePrev := 0.0, false
+	for i := 1; i < len(path); i++ {
+		dx, dy := path[i].X-path[i-1].X, path[i].Y-path[i-1].Y
+		segLen := math.Hypot(dx, dy)
+		if segLen == 0 {
+			continue
+		}
+
+		heading := math.Atan2(dy, dx)
+		if havePrev {
+			turn := math.Abs(heading - prevHeading)
+			if turn > math.Pi {
+				turn = 2*math.Pi - turn
+			}
+			c.TurnDegrees += turn * 180 / math.Pi
+		}
+		prevHeading, havePrev = heading, true
+
+		n := int(math.Ceil(segLen))
+		step := segLen / float64(n)
+		for s := 0; s < n; s++ {
+			t := (float64(s) + 0.5) / float64(n)
+			x := int(math.Floor(path[i-1].X + dx*t))
+			y := int(math.Floor(path[i-1].Y + dy*t))
+			onCopper := mask != nil && image.Pt(x, y).In(mask.Bounds()) && mask.GrayAt(x, y).Y != 0
+			c.Total += params.StepCost(onCopper, step)
+			c.Length += step
+			if onCopper {
+				c.CopperLength += step
+			}
+		}
+	}
+	c.Total += params.TurnCost(c.TurnDegrees)
+	return c
+}
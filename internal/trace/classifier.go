@@ -34,6 +34,10 @@ type TraceTrainingSet struct {
 	OnTraceHSV  []HSVSample `json:"on_trace_hsv"`
 	OffTraceHSV []HSVSample `json:"off_trace_hsv"`
 	Layer       TraceLayer  `json:"layer"`
+
+	// Grayscale luminance of the same pixels, for the flood-fill auto-trace
+	OnTraceGray  []uint8 `json:"on_trace_gray,omitempty"`
+	OffTraceGray []uint8 `json:"off_trace_gray,omitempty"`
 }
 
 // Save writes the training set to a JSON file.
@@ -109,6 +113,7 @@ func CollectSamples(img image.Image, traces []ExtendedTrace, layer TraceLayer, h
 					r, g, bl, _ := img.At(sx, sy).RGBA()
 					h, s, v := colorutil.RGBToHSV(float64(r>>8), float64(g>>8), float64(bl>>8))
 					ts.OnTraceHSV = append(ts.OnTraceHSV, HSVSample{H: h, S: s, V: v})
+					ts.OnTraceGray = append(ts.OnTraceGray, luma(r, g, bl))
 				}
 
 				// Sample off-trace: at 2.5 * halfWidth perpendicular offset (both sides)
@@ -124,6 +129,7 @@ func CollectSamples(img image.Image, traces []ExtendedTrace, layer TraceLayer, h
 						r, g, bl, _ := img.At(sx, sy).RGBA()
 						h, s, v := colorutil.RGBToHSV(float64(r>>8), float64(g>>8), float64(bl>>8))
 						ts.OffTraceHSV = append(ts.OffTraceHSV, HSVSample{H: h, S: s, V: v})
+						ts.OffTraceGray = append(ts.OffTraceGray, luma(r, g, bl))
 					}
 				}
 			}
@@ -133,12 +139,70 @@ func CollectSamples(img image.Image, traces []ExtendedTrace, layer TraceLayer, h
 	return ts
 }
 
+// luma converts 16-bit RGB channels to 8-bit grayscale using the same
+// weights as OpenCV's BGR2GRAY, so thresholds learned here apply to the
+// grayscale Mat the flood-fill auto-trace walks.
+func luma(r, g, b uint32) uint8 {
+	return uint8((299*(r>>8) + 587*(g>>8) + 114*(b>>8) + 500) / 1000)
+}
+
+// minGraySamples is the minimum number of on- and off-trace gray samples
+// needed before GrayThreshold trusts the data.
+const minGraySamples = 50
+
+// GrayThreshold returns the grayscale threshold that best separates the
+// on-trace samples (counted as copper when above it) from the off-trace
+// samples, minimizing the sum of both classes' error rates. ok is false
+// when there are too few samples or copper is not brighter than the
+// background.
+func (ts *TraceTrainingSet) GrayThreshold() (uint8, bool) {
+	if len(ts.OnTraceGray) < minGraySamples || len(ts.OffTraceGray) < minGraySamples {
+		return 0, false
+	}
+
+	var onHist, offHist [256]int
+	for _, v := range ts.OnTraceGray {
+		onHist[v]++
+	}
+	for _, v := range ts.OffTraceGray {
+		offHist[v]++
+	}
+
+	// Sweep t: on-trace at or below t are misses, off-trace above t are false
+	// hits. Take the middle of the first run of minimum error so a clean
+	// separation lands midway between the classes rather than on an edge.
+	onN, offN := float64(len(ts.OnTraceGray)), float64(len(ts.OffTraceGray))
+	onBelow, offBelow := 0, 0
+	bestErr := math.Inf(1)
+	bestLo, bestHi, inRun := 0, 0, false
+	for t := 0; t < 255; t++ {
+		onBelow += onHist[t]
+		offBelow += offHist[t]
+		errRate := float64(onBelow)/onN + float64(len(ts.OffTraceGray)-offBelow)/offN
+		switch {
+		case errRate < bestErr-1e-9:
+			bestErr = errRate
+			bestLo, bestHi, inRun = t, t, true
+		case inRun && errRate <= bestErr+1e-9:
+			bestHi = t
+		default:
+			inRun = false
+		}
+	}
+
+	// An error rate of 1 is no better than chance
+	return uint8((bestLo + bestHi) / 2), bestErr < 1
+}
+
 // TraceClassifier scores pixels as on-trace vs off-trace using learned HSV statistics.
 type TraceClassifier struct {
 	OnStats  HSVStats `json:"on_stats"`
 	OffStats HSVStats `json:"off_stats"`
 	Trained  bool     `json:"trained"`
 	Layer    TraceLayer `json:"layer"`
+
+	// Learned grayscale copper threshold for flood-fill auto-trace (0 = not learned)
+	GrayThreshold uint8 `json:"gray_threshold,omitempty"`
 }
 
 // Train computes HSV statistics from the training set.
@@ -151,6 +215,9 @@ func (c *TraceClassifier) Train(ts *TraceTrainingSet) {
 	c.OnStats = computeHSVStats(ts.OnTraceHSV)
 	c.OffStats = computeHSVStats(ts.OffTraceHSV)
 	c.Trained = true
+	if t, ok := ts.GrayThreshold(); ok {
+		c.GrayThreshold = t
+	}
 }
 
 // ScorePixel returns a score from 0.0 (off-trace) to 1.0 (on-trace) for an HSV pixel.
@@ -217,6 +284,14 @@ func LoadTraceClassifier(path string) (*TraceClassifier, error) {
 
 // StatsString returns a human-readable summary of the learned statistics.
 func (c *TraceClassifier) StatsString() string {
+	if c.GrayThreshold > 0 {
+		return fmt.Sprintf("Gray threshold=%d | ", c.GrayThreshold) + c.statsHSVString()
+	}
+	return c.statsHSVString()
+}
+
+// statsHSVString formats the learned HSV statistics.
+func (c *TraceClassifier) statsHSVString() string {
 	return fmt.Sprintf("On-trace: H=%.1f±%.1f S=%.1f±%.1f V=%.1f±%.1f | Off-trace: H=%.1f±%.1f S=%.1f±%.1f V=%.1f±%.1f",
 		c.OnStats.HMean, c.OnStats.HStd, c.OnStats.SMean, c.OnStats.SStd, c.OnStats.VMean, c.OnStats.VStd,
 		c.OffStats.HMean, c.OffStats.HStd, c.OffStats.SMean, c.OffStats.SStd, c.OffStats.VMean, c.OffStats.VStd)
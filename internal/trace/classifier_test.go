@@ -0,0 +1,104 @@
+package trace
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestGrayThreshold(t *testing.T) {
+	// samples returns n gray values cycling through [lo, lo+spread)
+	samples := func(n int, lo, spread uint8) []uint8 {
+		s := make([]uint8, n)
+		for i := range s {
+			s[i] = lo + uint8(i%int(spread))
+		}
+		return s
+	}
+	outliers := func(s []uint8, v uint8, n int) []uint8 {
+		for i := 0; i < n; i++ {
+			s = append(s, v)
+		}
+		return s
+	}
+
+	for _, c := range []struct {
+		name    string
+		on, off []uint8
+		want    uint8
+		ok      bool
+	}{
+		// Zero error for any t in [60, 179]: the middle is taken
+		{"clean", samples(120, 180, 1), samples(120, 60, 1), 119, true},
+		{"spread", samples(200, 150, 40), samples(200, 50, 40), 119, true},
+		// A few glints on the background and dull spots on the copper
+		// don't move the threshold off the gap
+		{"outliers", outliers(samples(200, 150, 40), 70, 5), outliers(samples(200, 50, 40), 160, 5), 119, true},
+		{"too few copper samples", samples(minGraySamples-1, 180, 1), samples(120, 60, 1), 0, false},
+		{"too few background samples", samples(120, 180, 1), samples(minGraySamples-1, 60, 1), 0, false},
+		{"copper darker than background", samples(120, 60, 1), samples(120, 180, 1), 0, false},
+		{"indistinguishable", samples(120, 100, 1), samples(120, 100, 1), 0, false},
+	} {
+		ts := &TraceTrainingSet{OnTraceGray: c.on, OffTraceGray: c.off}
+		got, ok := ts.GrayThreshold()
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("%s: threshold %d (ok %v), want %d (ok %v)", c.name, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+// TestCollectSamplesGrayThreshold samples a manual trace drawn over copper
+// on soldermask and learns a threshold between the two.
+func TestCollectSamplesGrayThreshold(t *testing.T) {
+	mask := color.RGBA{R: 40, G: 100, B: 50, A: 255}    // Gray 76
+	copper := color.RGBA{R: 200, G: 140, B: 60, A: 255} // Gray 149
+	img := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 200; x++ {
+			if y >= 46 && y <= 54 {
+				img.SetRGBA(x, y, copper)
+			} else {
+				img.SetRGBA(x, y, mask)
+			}
+		}
+	}
+	drawn := ExtendedTrace{
+		Trace:  Trace{ID: "trace-001", Layer: LayerFront, Points: []geometry.Point2D{{X: 20, Y: 50}, {X: 180, Y: 50}}},
+		Source: SourceManual,
+	}
+	detected := drawn
+	detected.Source = SourceDetected
+	detected.Points = []geometry.Point2D{{X: 20, Y: 20}, {X: 180, Y: 20}} // Over soldermask
+
+	ts := CollectSamples(img, []ExtendedTrace{drawn, detected}, LayerFront, 3)
+	if len(ts.OnTraceGray) != len(ts.OnTraceHSV) || len(ts.OffTraceGray) != len(ts.OffTraceHSV) {
+		t.Fatalf("gray and HSV sample counts differ: %d/%d on, %d/%d off",
+			len(ts.OnTraceGray), len(ts.OnTraceHSV), len(ts.OffTraceGray), len(ts.OffTraceHSV))
+	}
+	if len(ts.OnTraceGray) < minGraySamples || len(ts.OffTraceGray) < minGraySamples {
+		t.Fatalf("%d on and %d off samples, want at least %d each", len(ts.OnTraceGray), len(ts.OffTraceGray), minGraySamples)
+	}
+	for _, v := range ts.OnTraceGray {
+		if v != 149 {
+			t.Fatalf("on-trace sample gray %d, want copper (149)", v)
+		}
+	}
+	for _, v := range ts.OffTraceGray {
+		if v != 76 {
+			t.Fatalf("off-trace sample gray %d, want soldermask (76)", v)
+		}
+	}
+
+	got, ok := ts.GrayThreshold()
+	if !ok || got != 112 {
+		t.Errorf("threshold %d (ok %v), want 112 midway between 76 and 149", got, ok)
+	}
+
+	var c TraceClassifier
+	c.Train(ts)
+	if !c.Trained || c.GrayThreshold != got {
+		t.Errorf("trained %v with gray threshold %d, want %d", c.Trained, c.GrayThreshold, got)
+	}
+}
@@ -1,9 +1,13 @@
 package via
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
 
 	"pcb-tracer/pkg/colorutil"
 	"pcb-tracer/pkg/geometry"
@@ -17,6 +21,49 @@ type BoundaryResult struct {
 	IsCircle bool               // True if the boundary is approximately circular
 }
 
+// RefineBoundaries runs DetectMetalBoundary on every via in parallel and
+// replaces each via's center, radius and pad boundary with the result.
+// progress, if non-nil, is called from the worker goroutines as vias finish.
+// Once ctx is canceled the remaining vias keep their detected center and
+// radius, with a circular pad boundary, and ctx.Err() is returned.
+func RefineBoundaries(ctx context.Context, img image.Image, vias []Via, maxRadius float64, progress ProgressFunc) error {
+	numWorkers := min(runtime.NumCPU(), len(vias))
+	if numWorkers < 1 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var done atomic.Int64
+	viaChan := make(chan int, len(vias))
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range viaChan {
+				v := &vias[i]
+				if ctx.Err() != nil {
+					v.PadBoundary = geometry.GenerateCirclePoints(v.Center.X, v.Center.Y, v.Radius, 32)
+					continue
+				}
+				boundary := DetectMetalBoundary(img, v.Center.X, v.Center.Y, maxRadius)
+				v.PadBoundary = boundary.Boundary
+				v.Center = boundary.Center
+				v.Radius = boundary.Radius
+				n := done.Add(1)
+				if progress != nil {
+					progress(int(n), len(vias))
+				}
+			}
+		}()
+	}
+	for i := range vias {
+		viaChan <- i
+	}
+	close(viaChan)
+	wg.Wait()
+	return ctx.Err()
+}
+
 // DetectMetalBoundary finds the metallic pad boundary around a clicked point.
 // Uses radial search to expand outward from the click point until hitting
 // the green PCB substrate in each direction. Also handles open vias with
@@ -0,0 +1,38 @@
+package via
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// TestRefineBoundariesStopped checks that vias left unrefined by a stop
+// still get a pad boundary around their detected circle.
+func TestRefineBoundariesStopped(t *testing.T) {
+	src := syntheticBoard()
+	vias := []Via{
+		{Center: geometry.Point2D{X: 60, Y: 60}, Radius: 14},
+		{Center: geometry.Point2D{X: 160, Y: 60}, Radius: 14},
+		{Center: geometry.Point2D{X: 260, Y: 60}, Radius: 16},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := RefineBoundaries(ctx, src, vias, 18, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err %v, want context.Canceled", err)
+	}
+	for i, v := range vias {
+		if len(v.PadBoundary) == 0 {
+			t.Errorf("via %d has no pad boundary", i)
+			continue
+		}
+		for _, p := range v.PadBoundary {
+			if d := p.Distance(v.Center); d < v.Radius-0.5 || d > v.Radius+0.5 {
+				t.Errorf("via %d boundary point %v is %.1f px from the center, want %.0f", i, p, d, v.Radius)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package via
+
+import (
+	"fmt"
+	"image"
+
+	"pcb-tracer/pkg/geometry"
+
+	"gocv.io/x/gocv"
+)
+
+// HoughCalibration is the outcome of CalibrateHough: the tuned parameters
+// and how they scored against the known vias.
+type HoughCalibration struct {
+	Params         DetectionParams
+	Known          int // Known vias evaluated
+	Found          int // Known vias matched by a Hough circle
+	FalsePositives int // Hough circles near a known via that matched none
+}
+
+// Recall returns the fraction of known vias found.
+func (c HoughCalibration) Recall() float64 {
+	if c.Known == 0 {
+		return 0
+	}
+	return float64(c.Found) / float64(c.Known)
+}
+
+// Hough settings swept by CalibrateHough. minDist is in multiples of
+// MinRadiusPixels.
+var (
+	calibrateParam2  = []float64{10, 15, 20, 25, 30, 35, 40, 45, 50, 55, 60, 70, 80}
+	calibrateMinDist = []float64{1, 1.5, 2, 3, 4}
+)
+
+// CalibrateHough sweeps HoughParam2 and HoughMinDist to find the setting
+// that finds the most knownCenters with the fewest extra circles, and
+// returns params with those values. Hough runs on a window around each
+// known via rather than the whole board, so vias nobody labeled are not
+// counted as false positives (unless they share a window with a known one).
+// Ties go to the stricter (higher) param2.
+func CalibrateHough(srcImg image.Image, knownCenters []geometry.Point2D, params DetectionParams) (HoughCalibration, error) {
+	if len(knownCenters) == 0 {
+		return HoughCalibration{}, fmt.Errorf("no known vias to calibrate against")
+	}
+	if params.MinRadiusPixels <= 0 || params.MaxRadiusPixels <= 0 {
+		return HoughCalibration{}, fmt.Errorf("invalid radius parameters: min=%d, max=%d (set DPI first)",
+			params.MinRadiusPixels, params.MaxRadiusPixels)
+	}
+
+	// Prepare each window the way DetectVias prepares the board for Hough
+	type window struct {
+		known         geometry.Point2D
+		origin        image.Point
+		blurred, mask gocv.Mat
+	}
+	half := 4 * params.MaxRadiusPixels
+	bounds := srcImg.Bounds()
+	var windows []window
+	defer func() {
+		for _, w := range windows {
+			w.blurred.Close()
+			w.mask.Close()
+		}
+	}()
+	for _, c := range knownCenters {
+		cx, cy := int(c.X+0.5), int(c.Y+0.5)
+		r := image.Rect(cx-half, cy-half, cx+half+1, cy+half+1).Intersect(bounds)
+		if r.Dx() < 2*params.MaxRadiusPixels || r.Dy() < 2*params.MaxRadiusPixels {
+			continue
+		}
+		blurred, mask := prepareHoughWindow(srcImg, r, params)
+		windows = append(windows, window{known: c, origin: r.Min, blurred: blurred, mask: mask})
+	}
+	if len(windows) == 0 {
+		return HoughCalibration{}, fmt.Errorf("all known vias are too close to the image edge")
+	}
+
+	tol := float64(params.MinRadiusPixels)
+	best := HoughCalibration{Params: params, Known: len(windows), Found: -1}
+	bestScore := 0.0
+	for _, p2 := range calibrateParam2 {
+		for _, md := range calibrateMinDist {
+			trial := params
+			trial.HoughParam2 = p2
+			trial.HoughMinDist = max(5, int(md*float64(params.MinRadiusPixels)+0.5))
+
+			found, fp := 0, 0
+			for _, w := range windows {
+				hit := false
+				for _, h := range detectHoughCenters(w.blurred, w.mask, trial) {
+					center := geometry.Point2D{X: h.center.X + float64(w.origin.X), Y: h.center.Y + float64(w.origin.Y)}
+					if center.Distance(w.known) < tol {
+						hit = true
+					} else if !matchesKnown(center, knownCenters, tol) {
+						fp++
+					}
+				}
+				if hit {
+					found++
+				}
+			}
+
+			// A missed via costs two extra circles
+			score := float64(found) - 0.5*float64(fp)
+			if best.Found < 0 || score > bestScore || (score == bestScore && p2 > best.Params.HoughParam2) {
+				best.Params = trial
+				best.Found = found
+				best.FalsePositives = fp
+				bestScore = score
+			}
+		}
+	}
+	return best, nil
+}
+
+// prepareHoughWindow converts region r of srcImg to the blurred grayscale
+// and bright mask that detectHoughCenters expects. The caller owns both Mats.
+func prepareHoughWindow(srcImg image.Image, r image.Rectangle, params DetectionParams) (blurred, mask gocv.Mat) {
+	mat := gocv.NewMatWithSize(r.Dy(), r.Dx(), gocv.MatTypeCV8UC3)
+	defer mat.Close()
+	for y := 0; y < r.Dy(); y++ {
+		for x := 0; x < r.Dx(); x++ {
+			cr, cg, cb, _ := srcImg.At(r.Min.X+x, r.Min.Y+y).RGBA()
+			mat.SetUCharAt(y, x*3+0, uint8(cb>>8))
+			mat.SetUCharAt(y, x*3+1, uint8(cg>>8))
+			mat.SetUCharAt(y, x*3+2, uint8(cr>>8))
+		}
+	}
+
+	filtered := mat
+	if params.PreBlur != PreBlurNone && params.PreBlurRadius > 0 {
+		pre := applyPreBlur(mat, params)
+		defer pre.Close()
+		filtered = pre
+	}
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(filtered, &gray, gocv.ColorBGRToGray)
+	mask = createBrightMask(gray, params)
+
+	blurred = gocv.NewMat()
+	gocv.GaussianBlur(gray, &blurred, image.Point{9, 9}, 2, 2, gocv.BorderDefault)
+	return blurred, mask
+}
+
+// matchesKnown reports whether p is within tol of any known center.
+func matchesKnown(p geometry.Point2D, known []geometry.Point2D, tol float64) bool {
+	for _, k := range known {
+		if p.Distance(k) < tol {
+			return true
+		}
+	}
+	return false
+}
@@ -472,6 +472,10 @@ func FilterWithClassifier(result *ViaDetectionResult, classifier *ViaClassifier,
 			filtered = append(filtered, v)
 		}
 	}
+	if result.Report != nil {
+		result.Report.RejectedConfidence += len(result.Vias) - len(filtered)
+		result.Report.summarize(filtered)
+	}
 	result.Vias = filtered
 }
 
@@ -11,18 +11,19 @@ import (
 // This provides stronger confidence that the via is real, as it was independently
 // detected from both sides at the same location.
 type ConfirmedVia struct {
-	ID                   string             `json:"id"`                    // Unified ID, e.g., "cvia-001"
-	FrontViaID           string             `json:"front_via_id"`          // Reference to front side via
-	BackViaID            string             `json:"back_via_id"`           // Reference to back side via
-	Center               geometry.Point2D   `json:"center"`                // Averaged center from both sides
-	Radius               float64            `json:"radius"`                // Average radius
-	IntersectionBoundary []geometry.Point2D `json:"intersection_boundary"` // Computed polygon intersection
-	Confidence           float64            `json:"confidence"`            // Combined confidence score (boosted)
-	ComponentID          string             `json:"component_id,omitempty"`  // Associated component (e.g., "B13")
-	PinNumber            string             `json:"pin_number,omitempty"`    // Pin on that component (e.g., "1")
-	SignalName           string             `json:"signal_name,omitempty"`   // e.g. "C3-GND" from library lookup
-	PullUp               bool               `json:"pull_up,omitempty"`       // Marked as pull-up resistor connection
-	PullDown             bool               `json:"pull_down,omitempty"`     // Marked as pull-down resistor connection
+	ID                   string             `json:"id"`                     // Unified ID, e.g., "cvia-001"
+	FrontViaID           string             `json:"front_via_id"`           // Reference to front side via
+	BackViaID            string             `json:"back_via_id"`            // Reference to back side via
+	Center               geometry.Point2D   `json:"center"`                 // Averaged center from both sides
+	Radius               float64            `json:"radius"`                 // Average radius
+	IntersectionBoundary []geometry.Point2D `json:"intersection_boundary"`  // Computed polygon intersection
+	Confidence           float64            `json:"confidence"`             // Combined confidence score (boosted)
+	ComponentID          string             `json:"component_id,omitempty"` // Associated component (e.g., "B13")
+	PinNumber            string             `json:"pin_number,omitempty"`   // Pin on that component (e.g., "1")
+	SignalName           string             `json:"signal_name,omitempty"`  // e.g. "C3-GND" from library lookup
+	PullUp               bool               `json:"pull_up,omitempty"`      // Marked as pull-up resistor connection
+	PullDown             bool               `json:"pull_down,omitempty"`    // Marked as pull-down resistor connection
+	Label                string             `json:"label,omitempty"`        // User override for the display label
 }
 
 // NewConfirmedVia creates a new confirmed via from matched front and back vias.
@@ -141,6 +142,33 @@ func ComputeIntersection(front, back *Via) []geometry.Point2D {
 	return geometry.GenerateCirclePoints(avgCenter.X, avgCenter.Y, avgRadius, 32)
 }
 
+// DisplayLabel returns the label to draw for this via. A user-set Label
+// wins; otherwise pin names (signal name, then component-pin) are used when
+// showPinNames is set, and the via number when showViaNumbers is set.
+// Returns "" when no labeling applies.
+func (cv *ConfirmedVia) DisplayLabel(showPinNames, showViaNumbers bool) string {
+	if !showPinNames && !showViaNumbers {
+		return ""
+	}
+	if cv.Label != "" {
+		return cv.Label
+	}
+	if showPinNames && cv.SignalName != "" {
+		return cv.SignalName
+	}
+	if showPinNames && cv.ComponentID != "" && cv.PinNumber != "" {
+		return fmt.Sprintf("%s-%s", cv.ComponentID, cv.PinNumber)
+	}
+	if showViaNumbers {
+		var viaNum int
+		if _, err := fmt.Sscanf(cv.ID, "cvia-%d", &viaNum); err == nil {
+			return fmt.Sprintf("%d", viaNum)
+		}
+		return cv.ID
+	}
+	return ""
+}
+
 // HitTest returns true if the point (x, y) is inside this confirmed via.
 func (cv *ConfirmedVia) HitTest(x, y float64) bool {
 	p := geometry.Point2D{X: x, Y: y}
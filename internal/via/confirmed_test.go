@@ -0,0 +1,61 @@
+package via
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDisplayLabel(t *testing.T) {
+	cases := []struct {
+		name          string
+		cv            ConfirmedVia
+		pins, numbers bool
+		want          string
+	}{
+		{"override wins over the signal", ConfirmedVia{ID: "cvia-007", SignalName: "U3-VCC", Label: "VCC in"}, true, true, "VCC in"},
+		{"override wins over the pin", ConfirmedVia{ID: "cvia-007", ComponentID: "U3", PinNumber: "8", Label: "TP1"}, true, false, "TP1"},
+		{"override with numbers only", ConfirmedVia{ID: "cvia-007", SignalName: "U3-VCC", Label: "TP1"}, false, true, "TP1"},
+		{"override hidden with labels off", ConfirmedVia{ID: "cvia-007", Label: "TP1"}, false, false, ""},
+		{"signal", ConfirmedVia{ID: "cvia-007", SignalName: "U3-VCC", ComponentID: "U3", PinNumber: "8"}, true, true, "U3-VCC"},
+		{"component pin", ConfirmedVia{ID: "cvia-007", ComponentID: "U3", PinNumber: "8"}, true, true, "U3-8"},
+		{"number", ConfirmedVia{ID: "cvia-007"}, true, true, "7"},
+		{"number without pin names", ConfirmedVia{ID: "cvia-007", SignalName: "U3-VCC"}, false, true, "7"},
+		{"unnumbered ID", ConfirmedVia{ID: "manual"}, false, true, "manual"},
+		{"pin names but none set", ConfirmedVia{ID: "cvia-007"}, true, false, ""},
+	}
+	for _, c := range cases {
+		if got := c.cv.DisplayLabel(c.pins, c.numbers); got != c.want {
+			t.Errorf("%s: %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	// Clearing the override restores the usual precedence
+	cv := ConfirmedVia{ID: "cvia-007", SignalName: "U3-VCC", Label: "VCC in"}
+	cv.Label = ""
+	if got := cv.DisplayLabel(true, true); got != "U3-VCC" {
+		t.Errorf("cleared override: %q, want U3-VCC", got)
+	}
+}
+
+func TestConfirmedViaLabelJSON(t *testing.T) {
+	data, err := json.Marshal(&ConfirmedVia{ID: "cvia-007", Label: "TP1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ConfirmedVia
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "TP1" {
+		t.Errorf("round-tripped label %q, want TP1", got.Label)
+	}
+
+	// Vias saved before the override existed load without one
+	var legacy ConfirmedVia
+	if err := json.Unmarshal([]byte(`{"id": "cvia-003", "signal_name": "A0"}`), &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Label != "" || legacy.DisplayLabel(true, true) != "A0" {
+		t.Errorf("legacy via label %q displays %q, want none and A0", legacy.Label, legacy.DisplayLabel(true, true))
+	}
+}
@@ -1,6 +1,7 @@
 package via
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"math"
@@ -13,21 +14,66 @@ import (
 )
 
 // DetectViasFromImage detects vias from a Go image.Image.
-func DetectViasFromImage(srcImg image.Image, side img.Side, params DetectionParams) (*ViaDetectionResult, error) {
+// See DetectVias for how cancellation of ctx is reported.
+func DetectViasFromImage(ctx context.Context, srcImg image.Image, side img.Side, params DetectionParams) (*ViaDetectionResult, error) {
 	mat, err := imageToMat(srcImg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert image: %w", err)
 	}
 	defer mat.Close()
 
-	return DetectVias(mat, side, params)
+	return DetectVias(ctx, mat, side, params)
+}
+
+// DetectViasInRegion detects vias only inside region of srcImg, for quick
+// re-runs while tuning params. The search is padded by the maximum via
+// diameter so pads straddling the edge are measured whole; only vias whose
+// centers fall inside region are returned. Coordinates are in srcImg's
+// pixels. IDs are numbered as for a full run and will collide with vias
+// from other runs, so callers merging results must renumber them.
+func DetectViasInRegion(ctx context.Context, srcImg image.Image, side img.Side, params DetectionParams, region geometry.RectInt) (*ViaDetectionResult, error) {
+	pad := 2 * params.MaxRadiusPixels
+	search := image.Rect(region.X-pad, region.Y-pad, region.X+region.Width+pad, region.Y+region.Height+pad).
+		Intersect(srcImg.Bounds())
+	if search.Empty() {
+		return nil, fmt.Errorf("region (%d,%d) %dx%d is outside the image",
+			region.X, region.Y, region.Width, region.Height)
+	}
+
+	mat := imageRegionToMat(srcImg, search)
+	defer mat.Close()
+
+	result, err := DetectVias(ctx, mat, side, params)
+	if result == nil {
+		return nil, err
+	}
+
+	// Back to full-image coordinates, keeping only vias centered in region
+	dx, dy := float64(search.Min.X), float64(search.Min.Y)
+	inside := region.ToFloat()
+	kept := result.Vias[:0]
+	for _, v := range result.Vias {
+		v.Center.X += dx
+		v.Center.Y += dy
+		if !inside.Contains(v.Center) {
+			continue
+		}
+		for i := range v.PadBoundary {
+			v.PadBoundary[i].X += dx
+			v.PadBoundary[i].Y += dy
+		}
+		kept = append(kept, v)
+	}
+	result.Vias = kept
+	result.Report.summarize(result.Vias)
+	return result, err
 }
 
 // DetectViaLocations detects vias and returns just their centers and radii.
 // This is the general-purpose entry point for callers that just need via positions.
 func DetectViaLocations(srcImg image.Image, side img.Side, dpi float64) ([]ViaLocation, error) {
 	params := DefaultParams().WithDPI(dpi)
-	result, err := DetectViasFromImage(srcImg, side, params)
+	result, err := DetectViasFromImage(context.Background(), srcImg, side, params)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +87,11 @@ func DetectViaLocations(srcImg image.Image, side img.Side, dpi float64) ([]ViaLo
 // DetectVias detects vias in an OpenCV Mat using a hybrid pipeline:
 // grayscale distance-transform finds candidates, then color analysis
 // confirms metallic pad material (low saturation) vs solder mask artifacts.
-func DetectVias(srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDetectionResult, error) {
+//
+// If ctx is canceled during candidate verification, the remaining candidates
+// are skipped and the vias verified so far are finished and returned along
+// with ctx.Err().
+func DetectVias(ctx context.Context, srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDetectionResult, error) {
 	if srcImg.Empty() {
 		return nil, fmt.Errorf("empty image")
 	}
@@ -50,7 +100,9 @@ func DetectVias(srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDet
 		Side:   side,
 		DPI:    params.DPI,
 		Params: params,
+		Report: &DetectionReport{Side: side},
 	}
+	report := result.Report
 
 	// Validate radius parameters
 	if params.MinRadiusPixels <= 0 || params.MaxRadiusPixels <= 0 {
@@ -58,29 +110,46 @@ func DetectVias(srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDet
 			params.MinRadiusPixels, params.MaxRadiusPixels)
 	}
 
+	// Optional noise pre-filter for grainy or JPEG-compressed scans
+	filtered := srcImg
+	if params.PreBlur != PreBlurNone && params.PreBlurRadius > 0 {
+		blurred := applyPreBlur(srcImg, params)
+		defer blurred.Close()
+		filtered = blurred
+	}
+
 	// Convert to grayscale for geometry detection
 	gray := gocv.NewMat()
 	defer gray.Close()
-	gocv.CvtColor(srcImg, &gray, gocv.ColorBGRToGray)
+	gocv.CvtColor(filtered, &gray, gocv.ColorBGRToGray)
 
 	// Convert to HSV for color confirmation
 	hsv := gocv.NewMat()
 	defer hsv.Close()
-	gocv.CvtColor(srcImg, &hsv, gocv.ColorBGRToHSV)
+	gocv.CvtColor(filtered, &hsv, gocv.ColorBGRToHSV)
 
 	// Create brightness mask from grayscale
 	brightMask := createBrightMask(gray, params)
 	defer brightMask.Close()
 
 	// Step 1: Distance transform to find centers of round bright regions.
-	candidates := findDistTransformPeaks(brightMask, params, side)
+	candidates := findDistTransformPeaks(brightMask, params, side, report)
+	report.Candidates = len(candidates)
 
-	// Step 2: Verify radial symmetry and contrast
-	verified := verifyRadialSymmetry(candidates, brightMask, gray, params)
+	var verified []Via
+	if params.UseClassifier && params.Classifier != nil {
+		// Steps 2-3 with the learned classifier deciding borderline cases
+		verified = classifyCandidates(ctx, candidates, brightMask, gray, hsv, params, report)
+	} else {
+		// Step 2: Verify radial symmetry and contrast
+		verified = verifyRadialSymmetry(ctx, candidates, brightMask, gray, params, report)
 
-	// Step 3: Color confirmation — reject candidates that are solder mask
-	// (high saturation) rather than metallic via pads (low saturation)
-	verified = confirmMetallicColor(verified, hsv, params)
+		// Step 3: Color confirmation — reject candidates that are solder mask
+		// (high saturation) rather than metallic via pads (low saturation)
+		n := len(verified)
+		verified = confirmMetallicColor(verified, hsv, params)
+		report.RejectedColor = n - len(verified)
+	}
 
 	// Step 4: Optional Hough cross-validation
 	if params.RequireHoughConfirm {
@@ -89,11 +158,15 @@ func DetectVias(srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDet
 		gocv.GaussianBlur(gray, &blurred, image.Point{9, 9}, 2, 2, gocv.BorderDefault)
 
 		houghCandidates := detectHoughCenters(blurred, brightMask, params)
+		n := len(verified)
 		verified = crossValidateWithHough(verified, houghCandidates, params)
+		report.RejectedHough = n - len(verified)
 	}
 
 	// Step 5: Deduplicate (prefer largest radius)
+	n := len(verified)
 	result.Vias = deduplicateVias(verified, params)
+	report.RejectedDuplicate = n - len(result.Vias)
 
 	// Step 6: Refine center and find outer radius for each via
 	for i := range result.Vias {
@@ -104,8 +177,25 @@ func DetectVias(srcImg gocv.Mat, side img.Side, params DetectionParams) (*ViaDet
 	for i := range result.Vias {
 		result.Vias[i].ID = fmt.Sprintf("via-%s-%03d", side.String()[:1], i+1)
 	}
+	report.summarize(result.Vias)
+
+	return result, ctx.Err()
+}
 
-	return result, nil
+// applyPreBlur returns a filtered copy of src according to params.PreBlur.
+// The caller owns the returned Mat.
+func applyPreBlur(src gocv.Mat, params DetectionParams) gocv.Mat {
+	dst := gocv.NewMat()
+	ksize := 2*params.PreBlurRadius + 1
+	switch params.PreBlur {
+	case PreBlurMedian:
+		gocv.MedianBlur(src, &dst, ksize)
+	case PreBlurGaussian:
+		gocv.GaussianBlur(src, &dst, image.Point{ksize, ksize}, 0, 0, gocv.BorderDefault)
+	default:
+		src.CopyTo(&dst)
+	}
+	return dst
 }
 
 // createBrightMask creates a binary mask of bright regions using grayscale
@@ -139,7 +229,9 @@ func createBrightMask(gray gocv.Mat, params DetectionParams) gocv.Mat {
 // dark pixel. Local maxima correspond to centers of round bright regions —
 // a via pad connected to a narrow trace still peaks at the pad center because
 // the trace is narrow and produces small distance values.
-func findDistTransformPeaks(mask gocv.Mat, params DetectionParams, side img.Side) []Via {
+//
+// Peaks outside the radius range are counted in report.RejectedRadius.
+func findDistTransformPeaks(mask gocv.Mat, params DetectionParams, side img.Side, report *DetectionReport) []Via {
 	dist := gocv.NewMat()
 	defer dist.Close()
 	labels := gocv.NewMat()
@@ -168,13 +260,17 @@ func findDistTransformPeaks(mask gocv.Mat, params DetectionParams, side img.Side
 	for y := margin; y < rows-margin; y++ {
 		for x := margin; x < cols-margin; x++ {
 			val := dist.GetFloatAt(y, x)
-			if val < minR || val > maxR {
+			if val <= 0 {
 				continue
 			}
 			// Local maximum: value equals dilated value
 			if val < dilated.GetFloatAt(y, x) {
 				continue
 			}
+			if val < minR || val > maxR {
+				report.RejectedRadius++
+				continue
+			}
 			vias = append(vias, Via{
 				Center: geometry.Point2D{X: float64(x), Y: float64(y)},
 				Radius: float64(val),
@@ -190,16 +286,25 @@ func findDistTransformPeaks(mask gocv.Mat, params DetectionParams, side img.Side
 // at multiple angles and measuring where the bright mask ends. A round via has
 // a uniform transition radius in all directions; a via merged with a trace will
 // have some directions that extend much farther.
-func verifyRadialSymmetry(candidates []Via, mask, gray gocv.Mat, params DetectionParams) []Via {
+func verifyRadialSymmetry(ctx context.Context, candidates []Via, mask, gray gocv.Mat, params DetectionParams, report *DetectionReport) []Via {
 	var verified []Via
-	for _, v := range candidates {
+	for i, v := range candidates {
+		if ctx.Err() != nil {
+			report.Skipped += len(candidates) - i
+			break
+		}
+		if params.Progress != nil {
+			params.Progress(i+1, len(candidates))
+		}
 		symmetry := computeRadialSymmetry(mask, v.Center, v.Radius)
 		if symmetry < params.CircularityMin {
+			report.RejectedCircularity++
 			continue
 		}
 
 		contrast := computeContrast(gray, v.Center, v.Radius)
 		if contrast < params.ContrastMin {
+			report.RejectedContrast++
 			continue
 		}
 
@@ -557,7 +662,12 @@ func deduplicateVias(vias []Via, params DetectionParams) []Via {
 
 // imageToMat converts a Go image.Image to an OpenCV Mat.
 func imageToMat(srcImg image.Image) (gocv.Mat, error) {
-	bounds := srcImg.Bounds()
+	return imageRegionToMat(srcImg, srcImg.Bounds()), nil
+}
+
+// imageRegionToMat converts the bounds rectangle of a Go image to a BGR
+// OpenCV Mat whose origin is bounds.Min.
+func imageRegionToMat(srcImg image.Image, bounds image.Rectangle) gocv.Mat {
 	w, h := bounds.Dx(), bounds.Dy()
 
 	mat := gocv.NewMatWithSize(h, w, gocv.MatTypeCV8UC3)
@@ -572,7 +682,7 @@ func imageToMat(srcImg image.Image) (gocv.Mat, error) {
 		}
 	}
 
-	return mat, nil
+	return mat
 }
 
 
@@ -0,0 +1,72 @@
+package via
+
+import (
+	"math"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// MaxGridSnapFraction is the largest snap, as a fraction of the grid pitch,
+// that SnapToGrid applies. Vias further than this from a grid node are taken
+// to be genuinely off-grid features and are left where they are.
+const MaxGridSnapFraction = 0.25
+
+// GridSnap records how far a via was (or would have been) moved by SnapToGrid.
+type GridSnap struct {
+	Via      *ConfirmedVia
+	Distance float64 // Pixels from the original center to the nearest grid node
+	Snapped  bool    // False when Distance exceeded the snap threshold
+}
+
+// SnapToGrid moves each via center onto the nearest node of a square grid
+// with the given pitch, anchored at origin (pixels). Vias further than
+// MaxGridSnapFraction of the pitch from a node are left alone. The boundary
+// of each moved via is regenerated as a circle about its new center, as for
+// a manual nudge. Returns one GridSnap per via, in input order.
+func SnapToGrid(vias []*ConfirmedVia, dpi, pitchInches float64, origin geometry.Point2D) []GridSnap {
+	pitch := pitchInches * dpi
+	if pitch <= 0 {
+		return nil
+	}
+	maxDist := pitch * MaxGridSnapFraction
+
+	snaps := make([]GridSnap, len(vias))
+	for i, cv := range vias {
+		node := geometry.Point2D{
+			X: origin.X + math.Round((cv.Center.X-origin.X)/pitch)*pitch,
+			Y: origin.Y + math.Round((cv.Center.Y-origin.Y)/pitch)*pitch,
+		}
+		d := cv.Center.Distance(node)
+		snaps[i] = GridSnap{Via: cv, Distance: d}
+		if d > maxDist {
+			continue
+		}
+		cv.Center = node
+		cv.IntersectionBoundary = geometry.GenerateCirclePoints(node.X, node.Y, cv.Radius, 32)
+		snaps[i].Snapped = true
+	}
+	return snaps
+}
+
+// GridOrigin estimates the grid origin (pixels) that best fits the via
+// centers, as the circular mean of their positions modulo the pitch in each
+// axis. Off-grid outliers pull the estimate only weakly.
+func GridOrigin(vias []*ConfirmedVia, dpi, pitchInches float64) geometry.Point2D {
+	pitch := pitchInches * dpi
+	if pitch <= 0 || len(vias) == 0 {
+		return geometry.Point2D{}
+	}
+	var sx, cx, sy, cy float64
+	for _, cv := range vias {
+		ax := 2 * math.Pi * cv.Center.X / pitch
+		ay := 2 * math.Pi * cv.Center.Y / pitch
+		sx += math.Sin(ax)
+		cx += math.Cos(ax)
+		sy += math.Sin(ay)
+		cy += math.Cos(ay)
+	}
+	return geometry.Point2D{
+		X: math.Atan2(sx, cx) / (2 * math.Pi) * pitch,
+		Y: math.Atan2(sy, cy) / (2 * math.Pi) * pitch,
+	}
+}
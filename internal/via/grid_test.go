@@ -0,0 +1,60 @@
+package via
+
+import (
+	"math"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+func TestSnapToGrid(t *testing.T) {
+	// At 100 DPI a 0.1" pitch is 10 pixels, so vias within 2.5 px snap
+	cases := []struct {
+		name     string
+		center   geometry.Point2D
+		origin   geometry.Point2D
+		want     geometry.Point2D
+		distance float64
+		snapped  bool
+	}{
+		{"on a node", geometry.Point2D{X: 30, Y: 40}, geometry.Point2D{}, geometry.Point2D{X: 30, Y: 40}, 0, true},
+		{"near a node", geometry.Point2D{X: 31.5, Y: 38}, geometry.Point2D{}, geometry.Point2D{X: 30, Y: 40}, 2.5, true},
+		{"too far", geometry.Point2D{X: 33, Y: 40}, geometry.Point2D{}, geometry.Point2D{X: 33, Y: 40}, 3, false},
+		{"diagonal too far", geometry.Point2D{X: 32, Y: 42}, geometry.Point2D{}, geometry.Point2D{X: 32, Y: 42}, 2 * math.Sqrt2, false},
+		{"shifted origin", geometry.Point2D{X: 34, Y: 45}, geometry.Point2D{X: 3, Y: 6}, geometry.Point2D{X: 33, Y: 46}, math.Sqrt2, true},
+		{"too far from origin", geometry.Point2D{X: 1, Y: 2}, geometry.Point2D{X: 3, Y: 6}, geometry.Point2D{X: 1, Y: 2}, math.Sqrt(20), false},
+		{"left of origin", geometry.Point2D{X: -8, Y: 5}, geometry.Point2D{X: 3, Y: 6}, geometry.Point2D{X: -7, Y: 6}, math.Sqrt2, true},
+	}
+	for _, c := range cases {
+		cv := &ConfirmedVia{ID: "cvia-001", Center: c.center, Radius: 4}
+		snaps := SnapToGrid([]*ConfirmedVia{cv}, 100, 0.1, c.origin)
+		if len(snaps) != 1 || snaps[0].Via != cv {
+			t.Fatalf("%s: got %d snaps", c.name, len(snaps))
+		}
+		s := snaps[0]
+		if s.Snapped != c.snapped || math.Abs(s.Distance-c.distance) > 1e-9 {
+			t.Errorf("%s: snapped %v at %.3f px, want %v at %.3f px", c.name, s.Snapped, s.Distance, c.snapped, c.distance)
+		}
+		if cv.Center.Distance(c.want) > 1e-9 {
+			t.Errorf("%s: center %v, want %v", c.name, cv.Center, c.want)
+		}
+		if c.snapped {
+			// The boundary is redrawn about the new center
+			if len(cv.IntersectionBoundary) == 0 {
+				t.Errorf("%s: no boundary after snapping", c.name)
+			}
+			for _, p := range cv.IntersectionBoundary {
+				if math.Abs(p.Distance(c.want)-cv.Radius) > 1e-6 {
+					t.Errorf("%s: boundary point %v is not on the snapped circle", c.name, p)
+					break
+				}
+			}
+		} else if cv.IntersectionBoundary != nil {
+			t.Errorf("%s: boundary set on a via left in place", c.name)
+		}
+	}
+
+	if got := SnapToGrid([]*ConfirmedVia{{Center: geometry.Point2D{X: 1, Y: 1}}}, 0, 0.1, geometry.Point2D{}); got != nil {
+		t.Errorf("SnapToGrid at 0 DPI = %v, want nil", got)
+	}
+}
@@ -0,0 +1,35 @@
+package via
+
+import (
+	"fmt"
+	"io"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// kicadDrillRatio is the drill diameter as a fraction of the pad diameter.
+// The hole itself is not measured, so this is a typical through-via ratio.
+const kicadDrillRatio = 0.5
+
+// ExportKiCad writes confirmed vias as a .kicad_pcb fragment that can be
+// pasted into a board file. Positions are converted from image pixels to
+// millimeters relative to origin (an image position). KiCad's board Y axis
+// grows downward like image Y, so no flip is needed. Vias are left
+// unassigned (net 0) and span F.Cu to B.Cu.
+func ExportKiCad(vias []ConfirmedVia, dpi float64, origin geometry.Point2D, w io.Writer) error {
+	if dpi <= 0 {
+		return fmt.Errorf("invalid DPI %g", dpi)
+	}
+	mmPerPx := 25.4 / dpi
+
+	for _, v := range vias {
+		x := (v.Center.X - origin.X) * mmPerPx
+		y := (v.Center.Y - origin.Y) * mmPerPx
+		size := 2 * v.Radius * mmPerPx
+		if _, err := fmt.Fprintf(w, "(via (at %.4f %.4f) (size %.4f) (drill %.4f) (layers \"F.Cu\" \"B.Cu\") (net 0))\n",
+			x, y, size, size*kicadDrillRatio); err != nil {
+			return err
+		}
+	}
+	return nil
+}
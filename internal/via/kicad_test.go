@@ -0,0 +1,50 @@
+package via
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestExportKiCad(t *testing.T) {
+	// At 600 DPI one pixel is 0.042333 mm
+	vias := []ConfirmedVia{
+		{ID: "cvia-001", Center: geometry.Point2D{X: 100, Y: 100}, Radius: 12},
+		{ID: "cvia-002", Center: geometry.Point2D{X: 700, Y: 100}, Radius: 15},
+		{ID: "cvia-003", Center: geometry.Point2D{X: 400, Y: 1300}, Radius: 9.5},
+		{ID: "cvia-004", Center: geometry.Point2D{X: 50, Y: 40}, Radius: 12}, // Above and left of the origin
+	}
+	var buf bytes.Buffer
+	if err := ExportKiCad(vias, 600, geometry.Point2D{X: 100, Y: 100}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "vias.kicad_pcb", buf.Bytes())
+
+	if err := ExportKiCad(vias, 0, geometry.Point2D{}, &buf); err == nil {
+		t.Error("DPI 0: want error")
+	}
+}
@@ -0,0 +1,298 @@
+package via
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	img "pcb-tracer/internal/image"
+	"pcb-tracer/pkg/geometry"
+
+	"gocv.io/x/gocv"
+)
+
+// CandidateFeatures are the measurements the threshold pipeline makes on a
+// via candidate, kept so a classifier can weigh them together.
+type CandidateFeatures struct {
+	DiamInches  float64 `json:"diam_in"`     // Candidate diameter
+	Circularity float64 `json:"circularity"` // Radial symmetry (computeRadialSymmetry)
+	FillRatio   float64 `json:"fill_ratio"`  // Fraction of the circle inside the bright mask
+	Contrast    float64 `json:"contrast"`    // Inside/annulus brightness (computeContrast)
+	Hue         float64 `json:"hue"`         // Mean HSV inside the circle
+	Sat         float64 `json:"sat"`
+	Val         float64 `json:"val"`
+}
+
+func (f CandidateFeatures) vector() []float64 {
+	return []float64{f.DiamInches, f.Circularity, f.FillRatio, f.Contrast, f.Hue, f.Sat, f.Val}
+}
+
+// measureCandidate computes the features of a candidate circle. mask, gray
+// and hsv are the intermediate images of DetectVias.
+func measureCandidate(center geometry.Point2D, radius float64, mask, gray, hsv gocv.Mat, dpi float64) CandidateFeatures {
+	f := CandidateFeatures{
+		Circularity: computeRadialSymmetry(mask, center, radius),
+		Contrast:    computeContrast(gray, center, radius),
+	}
+	if dpi > 0 {
+		f.DiamInches = 2 * radius / dpi
+	}
+
+	cx, cy := int(center.X+0.5), int(center.Y+0.5)
+	r := max(int(radius+0.5), 1)
+	rows, cols := hsv.Rows(), hsv.Cols()
+	var hSum, sSum, vSum float64
+	var count, filled int
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if dx*dx+dy*dy > r*r {
+				continue
+			}
+			px, py := cx+dx, cy+dy
+			if px < 0 || px >= cols || py < 0 || py >= rows {
+				continue
+			}
+			hSum += float64(hsv.GetUCharAt(py, px*3))
+			sSum += float64(hsv.GetUCharAt(py, px*3+1))
+			vSum += float64(hsv.GetUCharAt(py, px*3+2))
+			if mask.GetUCharAt(py, px) > 0 {
+				filled++
+			}
+			count++
+		}
+	}
+	if count > 0 {
+		n := float64(count)
+		f.Hue, f.Sat, f.Val = hSum/n, sSum/n, vSum/n
+		f.FillRatio = float64(filled) / n
+	}
+	return f
+}
+
+// MeasureFeatures fills in Features for the samples on side that lack them,
+// measuring srcImg the way DetectVias would with params. Returns the number
+// of samples measured.
+func (ts *TrainingSet) MeasureFeatures(srcImg image.Image, side img.Side, params DetectionParams) (int, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	var todo []int
+	for i, s := range ts.Samples {
+		if s.Side == side && s.Features == nil {
+			todo = append(todo, i)
+		}
+	}
+	if len(todo) == 0 {
+		return 0, nil
+	}
+
+	mat, err := imageToMat(srcImg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert image: %w", err)
+	}
+	defer mat.Close()
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(mat, &gray, gocv.ColorBGRToGray)
+	hsv := gocv.NewMat()
+	defer hsv.Close()
+	gocv.CvtColor(mat, &hsv, gocv.ColorBGRToHSV)
+	mask := createBrightMask(gray, params)
+	defer mask.Close()
+
+	for _, i := range todo {
+		s := &ts.Samples[i]
+		f := measureCandidate(s.Center, s.Radius, mask, gray, hsv, params.DPI)
+		s.Features = &f
+	}
+	return len(todo), nil
+}
+
+// Classifier is a logistic regression over CandidateFeatures, trained from
+// the labeled via samples.
+type Classifier struct {
+	mean, std []float64 // Feature standardization
+	weights   []float64
+	bias      float64
+
+	Positives, Negatives int // Samples used for training
+}
+
+// Minimum samples of each label needed to train a classifier.
+const minClassifierSamples = 3
+
+// TrainClassifier fits a classifier to the samples that have Features (see
+// MeasureFeatures). It needs a few positive and negative samples.
+func TrainClassifier(ts *TrainingSet) (*Classifier, error) {
+	ts.mu.RLock()
+	var xs [][]float64
+	var ys []float64
+	c := &Classifier{}
+	for _, s := range ts.Samples {
+		if s.Features == nil {
+			continue
+		}
+		xs = append(xs, s.Features.vector())
+		if s.IsVia {
+			ys = append(ys, 1)
+			c.Positives++
+		} else {
+			ys = append(ys, 0)
+			c.Negatives++
+		}
+	}
+	ts.mu.RUnlock()
+
+	if c.Positives < minClassifierSamples || c.Negatives < minClassifierSamples {
+		return nil, fmt.Errorf("need at least %d measured positive and negative samples, have %d and %d",
+			minClassifierSamples, c.Positives, c.Negatives)
+	}
+
+	// Standardize so one learning rate suits every feature
+	dim := len(xs[0])
+	c.mean = make([]float64, dim)
+	c.std = make([]float64, dim)
+	for _, x := range xs {
+		for j, v := range x {
+			c.mean[j] += v
+		}
+	}
+	for j := range c.mean {
+		c.mean[j] /= float64(len(xs))
+	}
+	for _, x := range xs {
+		for j, v := range x {
+			d := v - c.mean[j]
+			c.std[j] += d * d
+		}
+	}
+	for j := range c.std {
+		c.std[j] = math.Sqrt(c.std[j] / float64(len(xs)))
+		if c.std[j] < 1e-9 {
+			c.std[j] = 1
+		}
+	}
+	zs := make([][]float64, len(xs))
+	for i, x := range xs {
+		zs[i] = c.standardize(x)
+	}
+
+	// Batch gradient descent with a little L2 so separable data stays finite.
+	// Classes are weighted equally however unbalanced the samples are.
+	const (
+		iterations = 2000
+		rate       = 0.1
+		l2         = 0.01
+	)
+	posWeight := float64(len(xs)) / (2 * float64(c.Positives))
+	negWeight := float64(len(xs)) / (2 * float64(c.Negatives))
+	c.weights = make([]float64, dim)
+	grad := make([]float64, dim)
+	for it := 0; it < iterations; it++ {
+		clear(grad)
+		var gradBias float64
+		for i, z := range zs {
+			w := negWeight
+			if ys[i] == 1 {
+				w = posWeight
+			}
+			e := w * (c.predict(z) - ys[i])
+			for j, v := range z {
+				grad[j] += e * v
+			}
+			gradBias += e
+		}
+		n := float64(len(zs))
+		for j := range c.weights {
+			c.weights[j] -= rate * (grad[j]/n + l2*c.weights[j])
+		}
+		c.bias -= rate * gradBias / n
+	}
+	return c, nil
+}
+
+func (c *Classifier) standardize(x []float64) []float64 {
+	z := make([]float64, len(x))
+	for j, v := range x {
+		z[j] = (v - c.mean[j]) / c.std[j]
+	}
+	return z
+}
+
+func (c *Classifier) predict(z []float64) float64 {
+	s := c.bias
+	for j, v := range z {
+		s += c.weights[j] * v
+	}
+	return 1 / (1 + math.Exp(-s))
+}
+
+// Score returns the probability (0-1) that a candidate with features f is
+// a via.
+func (c *Classifier) Score(f CandidateFeatures) float64 {
+	return c.predict(c.standardize(f.vector()))
+}
+
+// Relative distance from a threshold within which a candidate counts as
+// borderline and the classifier is consulted (UseClassifier).
+const classifierMargin = 0.15
+
+// nearThreshold reports whether v is within classifierMargin of limit.
+func nearThreshold(v, limit float64) bool {
+	return math.Abs(v-limit) <= classifierMargin*math.Abs(limit)
+}
+
+// classifyCandidates replaces the symmetry, contrast and color steps of
+// DetectVias when params.UseClassifier is set. Clear-cut candidates are
+// decided by the thresholds as usual; borderline ones by the classifier,
+// with overrides counted in report.ClassifierAccepted/Rejected.
+func classifyCandidates(ctx context.Context, candidates []Via, mask, gray, hsv gocv.Mat, params DetectionParams, report *DetectionReport) []Via {
+	var accepted []Via
+	for i, v := range candidates {
+		if ctx.Err() != nil {
+			report.Skipped += len(candidates) - i
+			break
+		}
+		if params.Progress != nil {
+			params.Progress(i+1, len(candidates))
+		}
+		f := measureCandidate(v.Center, v.Radius, mask, gray, hsv, params.DPI)
+
+		// Threshold decision, keeping the first failing step for the report
+		var failed *int
+		switch {
+		case f.Circularity < params.CircularityMin:
+			failed = &report.RejectedCircularity
+		case f.Contrast < params.ContrastMin:
+			failed = &report.RejectedContrast
+		case f.Sat > params.SatMax:
+			failed = &report.RejectedColor
+		}
+		pass := failed == nil
+
+		borderline := nearThreshold(f.Circularity, params.CircularityMin) ||
+			nearThreshold(f.Contrast, params.ContrastMin) ||
+			nearThreshold(f.Sat, params.SatMax)
+		if borderline {
+			keep := params.Classifier.Score(f) >= 0.5
+			if keep && !pass {
+				report.ClassifierAccepted++
+			} else if !keep && pass {
+				report.ClassifierRejected++
+			}
+			pass = keep
+		}
+		if !pass {
+			if failed != nil {
+				*failed++
+			}
+			continue
+		}
+
+		v.Circularity = f.Circularity
+		v.Confidence = f.Circularity * math.Min(f.Contrast/2.0, 1.0)
+		accepted = append(accepted, v)
+	}
+	return accepted
+}
@@ -57,6 +57,18 @@ func (p DetectionParams) WithDPI(dpi float64) DetectionParams {
 	return p
 }
 
+// AtDPI returns a copy of params saved for one scan, adapted to an image at
+// dpi: pixel sizes are recomputed from the physical ones and a Hough minimum
+// distance tuned at the saved DPI is scaled to match.
+func (p DetectionParams) AtDPI(dpi float64) DetectionParams {
+	minDist, savedDPI := p.HoughMinDist, p.DPI
+	p = p.WithDPI(dpi)
+	if savedDPI > 0 && dpi > 0 {
+		p.HoughMinDist = int(float64(minDist)*dpi/savedDPI + 0.5)
+	}
+	return p
+}
+
 // WithHSV returns a copy of params with custom HSV color ranges.
 // Useful when user has sampled via colors from the image.
 func (p DetectionParams) WithHSV(hMin, hMax, sMin, sMax, vMin, vMax float64) DetectionParams {
@@ -69,6 +81,18 @@ func (p DetectionParams) WithHSV(hMin, hMax, sMin, sMax, vMin, vMax float64) Det
 	return p
 }
 
+// WithPreBlur returns a copy of params with a noise pre-filter of the given
+// radius in pixels. A radius of 0 or PreBlurNone disables the filter, as
+// does an unknown mode (e.g. -1 from a combo box with nothing selected).
+func (p DetectionParams) WithPreBlur(mode PreBlurMode, radius int) DetectionParams {
+	if mode <= PreBlurNone || mode > PreBlurGaussian || radius <= 0 {
+		mode, radius = PreBlurNone, 0
+	}
+	p.PreBlur = mode
+	p.PreBlurRadius = radius
+	return p
+}
+
 // WithSizeRange returns a copy of params with custom via size range in inches.
 func (p DetectionParams) WithSizeRange(minDiamInches, maxDiamInches float64) DetectionParams {
 	p.MinDiamInches = minDiamInches
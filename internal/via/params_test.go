@@ -0,0 +1,77 @@
+package via
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPreBlurModeString(t *testing.T) {
+	for _, c := range []struct {
+		mode PreBlurMode
+		want string
+	}{
+		{PreBlurNone, "None"},
+		{PreBlurMedian, "Median"},
+		{PreBlurGaussian, "Gaussian"},
+		{PreBlurMode(-1), "None"},
+		{PreBlurMode(7), "None"},
+	} {
+		if got := c.mode.String(); got != c.want {
+			t.Errorf("PreBlurMode(%d) = %q, want %q", int(c.mode), got, c.want)
+		}
+	}
+}
+
+func TestWithPreBlur(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		mode       PreBlurMode
+		radius     int
+		wantMode   PreBlurMode
+		wantRadius int
+	}{
+		{"median", PreBlurMedian, 2, PreBlurMedian, 2},
+		{"gaussian", PreBlurGaussian, 1, PreBlurGaussian, 1},
+		{"none keeps no radius", PreBlurNone, 3, PreBlurNone, 0},
+		{"zero radius disables", PreBlurMedian, 0, PreBlurNone, 0},
+		{"negative radius disables", PreBlurGaussian, -2, PreBlurNone, 0},
+		{"nothing selected", PreBlurMode(-1), 1, PreBlurNone, 0},
+		{"unknown mode", PreBlurMode(3), 1, PreBlurNone, 0},
+	} {
+		p := DefaultParams().WithDPI(600).WithPreBlur(c.mode, c.radius)
+		if p.PreBlur != c.wantMode || p.PreBlurRadius != c.wantRadius {
+			t.Errorf("%s: %v radius %d, want %v radius %d", c.name, p.PreBlur, p.PreBlurRadius, c.wantMode, c.wantRadius)
+		}
+		if p.DPI != 600 || p.MinRadiusPixels == 0 {
+			t.Errorf("%s: pre-blur changed the DPI settings", c.name)
+		}
+	}
+}
+
+// TestPreBlurDefaults checks that params saved before pre-blur existed load
+// with the filter off.
+func TestPreBlurDefaults(t *testing.T) {
+	if p := DefaultParams(); p.PreBlur != PreBlurNone || p.PreBlurRadius != 0 {
+		t.Errorf("default params pre-blur %v radius %d, want off", p.PreBlur, p.PreBlurRadius)
+	}
+
+	p := DefaultParams()
+	if err := json.Unmarshal([]byte(`{"DPI": 600, "ValMin": 160}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.PreBlur != PreBlurNone || p.PreBlurRadius != 0 {
+		t.Errorf("legacy params pre-blur %v radius %d, want off", p.PreBlur, p.PreBlurRadius)
+	}
+
+	saved, err := json.Marshal(DefaultParams().WithPreBlur(PreBlurGaussian, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = DefaultParams()
+	if err := json.Unmarshal(saved, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.PreBlur != PreBlurGaussian || p.PreBlurRadius != 2 {
+		t.Errorf("round-tripped pre-blur %v radius %d, want Gaussian radius 2", p.PreBlur, p.PreBlurRadius)
+	}
+}
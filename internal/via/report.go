@@ -0,0 +1,137 @@
+package via
+
+import (
+	"fmt"
+	"strings"
+
+	"pcb-tracer/internal/image"
+)
+
+// DetectionReport tallies how via candidates fared at each detection stage,
+// so a poor result can be traced to the criterion that is rejecting vias.
+type DetectionReport struct {
+	Side image.Side
+
+	Candidates int // Distance-transform peaks within the radius range
+
+	// Rejections per criterion, in pipeline order
+	RejectedRadius      int // Peaks outside MinRadiusPixels..MaxRadiusPixels
+	RejectedCircularity int // Radial symmetry below CircularityMin
+	RejectedContrast    int // Inside/outside brightness ratio below ContrastMin
+	RejectedColor       int // Pad saturation above SatMax (solder mask, silkscreen)
+	RejectedHough       int // No Hough circle nearby (RequireHoughConfirm only)
+	RejectedDuplicate   int // Overlapped a larger detection
+	RejectedConfidence  int // Below the classifier threshold (FilterWithClassifier)
+
+	// Borderline candidates where the learned classifier overrode the
+	// thresholds (UseClassifier only)
+	ClassifierAccepted int // Failed a threshold, accepted by the classifier
+	ClassifierRejected int // Passed the thresholds, rejected by the classifier
+
+	Skipped  int // Candidates not examined because detection was canceled
+	Accepted int
+
+	// Distributions over the accepted vias
+	Confidence  Distribution
+	Circularity Distribution
+	Radius      Distribution
+	Methods     map[DetectionMethod]int
+}
+
+// Distribution summarizes a set of values.
+type Distribution struct {
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+// Add includes v in the distribution.
+func (d *Distribution) Add(v float64) {
+	if d.Count == 0 || v < d.Min {
+		d.Min = v
+	}
+	if d.Count == 0 || v > d.Max {
+		d.Max = v
+	}
+	d.Mean += (v - d.Mean) / float64(d.Count+1)
+	d.Count++
+}
+
+// String formats the distribution as "min-max (mean m)".
+func (d Distribution) String() string {
+	if d.Count == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f-%.2f (mean %.2f)", d.Min, d.Max, d.Mean)
+}
+
+// Rejected returns the total number of rejected candidates, excluding
+// radius rejections which are counted before candidates are formed.
+// Candidates the classifier rejected after they passed the thresholds count
+// too, so Candidates == Rejected() + Skipped + Accepted, with Skipped zero
+// for a run that was not canceled.
+func (r *DetectionReport) Rejected() int {
+	return r.RejectedCircularity + r.RejectedContrast + r.RejectedColor +
+		r.RejectedHough + r.RejectedDuplicate + r.RejectedConfidence +
+		r.ClassifierRejected
+}
+
+// summarize recomputes Accepted and the distributions from vias.
+func (r *DetectionReport) summarize(vias []Via) {
+	r.Accepted = len(vias)
+	r.Confidence = Distribution{}
+	r.Circularity = Distribution{}
+	r.Radius = Distribution{}
+	r.Methods = make(map[DetectionMethod]int)
+	for _, v := range vias {
+		r.Confidence.Add(v.Confidence)
+		r.Circularity.Add(v.Circularity)
+		r.Radius.Add(v.Radius)
+		r.Methods[v.Method]++
+	}
+}
+
+// Summary returns a one-line description suitable for a status bar.
+func (r *DetectionReport) Summary() string {
+	s := fmt.Sprintf("%d/%d accepted; rejected: circ %d, contrast %d, color %d, hough %d, dup %d, conf %d",
+		r.Accepted, r.Candidates, r.RejectedCircularity, r.RejectedContrast,
+		r.RejectedColor, r.RejectedHough, r.RejectedDuplicate, r.RejectedConfidence)
+	if r.ClassifierAccepted+r.ClassifierRejected > 0 {
+		s += fmt.Sprintf("; classifier +%d/-%d", r.ClassifierAccepted, r.ClassifierRejected)
+	}
+	if r.Skipped > 0 {
+		s += fmt.Sprintf("; %d skipped (stopped)", r.Skipped)
+	}
+	return s
+}
+
+// String returns a multi-line report.
+func (r *DetectionReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Via detection report (%s):\n", r.Side)
+	fmt.Fprintf(&b, "  Peaks outside radius range: %d\n", r.RejectedRadius)
+	fmt.Fprintf(&b, "  Candidates:                 %d\n", r.Candidates)
+	fmt.Fprintf(&b, "  Rejected by circularity:    %d\n", r.RejectedCircularity)
+	fmt.Fprintf(&b, "  Rejected by contrast:       %d\n", r.RejectedContrast)
+	fmt.Fprintf(&b, "  Rejected by color:          %d\n", r.RejectedColor)
+	fmt.Fprintf(&b, "  Rejected by Hough:          %d\n", r.RejectedHough)
+	fmt.Fprintf(&b, "  Rejected as duplicate:      %d\n", r.RejectedDuplicate)
+	fmt.Fprintf(&b, "  Rejected by confidence:     %d\n", r.RejectedConfidence)
+	if r.ClassifierAccepted+r.ClassifierRejected > 0 {
+		fmt.Fprintf(&b, "  Classifier flips:           +%d accepted, -%d rejected\n", r.ClassifierAccepted, r.ClassifierRejected)
+	}
+	if r.Skipped > 0 {
+		fmt.Fprintf(&b, "  Skipped (stopped):          %d\n", r.Skipped)
+	}
+	fmt.Fprintf(&b, "  Accepted:                   %d\n", r.Accepted)
+	fmt.Fprintf(&b, "  Confidence:  %s\n", r.Confidence)
+	fmt.Fprintf(&b, "  Circularity: %s\n", r.Circularity)
+	fmt.Fprintf(&b, "  Radius (px): %s\n", r.Radius)
+	for _, m := range []DetectionMethod{MethodHoughCircle, MethodContourFit, MethodManual} {
+		if n := r.Methods[m]; n > 0 {
+			fmt.Fprintf(&b, "  Method %s: %d\n", m, n)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package via
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	img "pcb-tracer/internal/image"
+)
+
+// syntheticBoard draws tinned via pads, an overlapping pair, and a thick
+// trace stub on dark solder mask at 600 DPI.
+func syntheticBoard() *image.RGBA {
+	b := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	draw.Draw(b, b.Bounds(), &image.Uniform{color.RGBA{20, 60, 30, 255}}, image.Point{}, draw.Src)
+	pad := color.RGBA{210, 210, 210, 255}
+	disk := func(cx, cy, r int) {
+		for y := cy - r; y <= cy+r; y++ {
+			for x := cx - r; x <= cx+r; x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) <= r*r {
+					b.SetRGBA(x, y, pad)
+				}
+			}
+		}
+	}
+	disk(60, 60, 14)
+	disk(160, 60, 14)
+	disk(260, 60, 16)
+	disk(60, 200, 14)
+	disk(84, 200, 14)
+	for y := 190; y < 210; y++ {
+		for x := 180; x < 340; x++ {
+			b.SetRGBA(x, y, pad)
+		}
+	}
+	return b
+}
+
+func checkReportInvariant(t *testing.T, name string, r *DetectionReport) {
+	t.Helper()
+	if r.Candidates == 0 {
+		t.Fatalf("%s: no candidates", name)
+	}
+	if r.Candidates != r.Rejected()+r.Skipped+r.Accepted {
+		t.Errorf("%s: Candidates %d != Rejected() %d + Skipped %d + Accepted %d\n%s",
+			name, r.Candidates, r.Rejected(), r.Skipped, r.Accepted, r)
+	}
+}
+
+func TestDetectionReportAddsUp(t *testing.T) {
+	src := syntheticBoard()
+	params := DefaultParams().WithDPI(600)
+
+	result, err := DetectViasFromImage(context.Background(), src, img.SideFront, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Report.Accepted == 0 {
+		t.Fatalf("no vias accepted:\n%s", result.Report)
+	}
+	checkReportInvariant(t, "thresholds", result.Report)
+
+	// A classifier that rejects everything it is asked about. Gray pads have
+	// zero saturation, so with SatMax 0 every candidate is borderline and
+	// those passing the thresholds become classifier rejections.
+	params.UseClassifier = true
+	params.SatMax = 0
+	params.Classifier = &Classifier{
+		mean:    make([]float64, 7),
+		std:     []float64{1, 1, 1, 1, 1, 1, 1},
+		weights: make([]float64, 7),
+		bias:    -10,
+	}
+	result, err = DetectViasFromImage(context.Background(), src, img.SideFront, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Report.ClassifierRejected == 0 {
+		t.Fatalf("classifier rejected nothing:\n%s", result.Report)
+	}
+	checkReportInvariant(t, "classifier", result.Report)
+}
+
+// TestDetectionReportStopped stops detection after the first candidate on
+// both verification paths: the rest are counted as skipped.
+func TestDetectionReportStopped(t *testing.T) {
+	src := syntheticBoard()
+	for _, classifier := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		params := DefaultParams().WithDPI(600)
+		params.Progress = func(done, total int) {
+			if done == 1 {
+				cancel()
+			}
+		}
+		if classifier {
+			params.UseClassifier = true
+			params.Classifier = &Classifier{
+				mean:    make([]float64, 7),
+				std:     []float64{1, 1, 1, 1, 1, 1, 1},
+				weights: make([]float64, 7),
+			}
+		}
+		result, err := DetectViasFromImage(ctx, src, img.SideFront, params)
+		cancel()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("classifier %v: err %v, want context.Canceled", classifier, err)
+		}
+		r := result.Report
+		if r.Skipped != r.Candidates-1 {
+			t.Errorf("classifier %v: skipped %d of %d candidates, want all but one", classifier, r.Skipped, r.Candidates)
+		}
+		checkReportInvariant(t, fmt.Sprintf("stopped (classifier %v)", classifier), r)
+	}
+}
+
+func TestDetectionReportRejected(t *testing.T) {
+	r := DetectionReport{
+		Candidates:          20,
+		RejectedRadius:      100, // Not candidates
+		RejectedCircularity: 1,
+		RejectedContrast:    2,
+		RejectedColor:       3,
+		RejectedHough:       4,
+		RejectedDuplicate:   1,
+		RejectedConfidence:  2,
+		ClassifierAccepted:  5, // Already in Accepted
+		ClassifierRejected:  3,
+		Skipped:             2, // Stopped before examining
+		Accepted:            2,
+	}
+	if got := r.Rejected(); got != 16 {
+		t.Errorf("Rejected() = %d, want 16", got)
+	}
+	if r.Candidates != r.Rejected()+r.Skipped+r.Accepted {
+		t.Errorf("Candidates %d != Rejected() %d + Skipped %d + Accepted %d", r.Candidates, r.Rejected(), r.Skipped, r.Accepted)
+	}
+}
@@ -0,0 +1,118 @@
+package via
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultOutlierZ is the FlagOutliers threshold used by the UI and viatest:
+// a via whose radius is further than this many deviations from the median
+// is more likely a component pad or a merged blob than a via.
+const DefaultOutlierZ = 3.0
+
+// Histogram counts via radii in 1-pixel bins.
+type Histogram struct {
+	Min    float64 // Lower edge of the first bin (pixels)
+	Counts []int   // Vias per bin; bin i covers [Min+i, Min+i+1)
+	Median float64 // Median radius
+	Sigma  float64 // Robust standard deviation of the radius (see FlagOutliers)
+}
+
+// SizeHistogram bins the via radii and summarizes their spread.
+func SizeHistogram(vias []Via) Histogram {
+	if len(vias) == 0 {
+		return Histogram{}
+	}
+	radii := sortedRadii(vias)
+	h := Histogram{
+		Min:    math.Floor(radii[0]),
+		Median: median(radii),
+	}
+	h.Sigma = radiusSigma(radii, h.Median)
+	h.Counts = make([]int, int(math.Floor(radii[len(radii)-1])-h.Min)+1)
+	for _, r := range radii {
+		h.Counts[int(r-h.Min)]++
+	}
+	return h
+}
+
+// String draws the histogram as one text bar per bin.
+func (h Histogram) String() string {
+	if len(h.Counts) == 0 {
+		return "No vias\n"
+	}
+	peak := 0
+	for _, c := range h.Counts {
+		peak = max(peak, c)
+	}
+	const barWidth = 40
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Radius histogram (median %.1f px, sigma %.2f px):\n", h.Median, h.Sigma)
+	for i, c := range h.Counts {
+		bar := (c*barWidth + peak - 1) / peak
+		fmt.Fprintf(&sb, "  %5.0f-%-5.0f %5d %s\n", h.Min+float64(i), h.Min+float64(i+1), c, strings.Repeat("#", bar))
+	}
+	return sb.String()
+}
+
+// FlagOutliers returns the indices of vias whose radius is more than
+// zThreshold standard deviations from the median radius. The deviation is
+// estimated from the median absolute deviation so that the outliers being
+// looked for do not inflate it.
+func FlagOutliers(vias []Via, zThreshold float64) []int {
+	if len(vias) < 3 || zThreshold <= 0 {
+		return nil
+	}
+	radii := sortedRadii(vias)
+	med := median(radii)
+	sigma := radiusSigma(radii, med)
+	if sigma == 0 {
+		return nil
+	}
+
+	var outliers []int
+	for i, v := range vias {
+		if math.Abs(v.Radius-med)/sigma > zThreshold {
+			outliers = append(outliers, i)
+		}
+	}
+	return outliers
+}
+
+// radiusSigma estimates the standard deviation of normally distributed
+// radii as 1.4826 × the median absolute deviation from med, or, when most
+// radii equal the median and the MAD is zero, as 1.2533 × the mean
+// absolute deviation.
+func radiusSigma(sorted []float64, med float64) float64 {
+	dev := make([]float64, len(sorted))
+	var sum float64
+	for i, r := range sorted {
+		dev[i] = math.Abs(r - med)
+		sum += dev[i]
+	}
+	sort.Float64s(dev)
+	if mad := median(dev); mad > 0 {
+		return 1.4826 * mad
+	}
+	return 1.2533 * sum / float64(len(dev))
+}
+
+func sortedRadii(vias []Via) []float64 {
+	radii := make([]float64, len(vias))
+	for i, v := range vias {
+		radii[i] = v.Radius
+	}
+	sort.Float64s(radii)
+	return radii
+}
+
+// median returns the middle value of sorted values.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -21,6 +21,9 @@ type TrainingSample struct {
 	IsVia     bool             `json:"is_via"`     // True = positive sample, False = negative
 	Source    string           `json:"source"`     // "manual", "confirmed", "rejected"
 	Timestamp time.Time        `json:"timestamp"`
+
+	// Measured from the image for TrainClassifier; nil until MeasureFeatures
+	Features *CandidateFeatures `json:"features,omitempty"`
 }
 
 // TrainingSet holds a collection of labeled via samples.
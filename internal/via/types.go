@@ -113,6 +113,10 @@ type ViaDetectionResult struct {
 	Side   image.Side     // Which side was scanned
 	DPI    float64        // Image DPI used for detection
 	Params DetectionParams // Parameters used for detection
+
+	// Report tallies per-criterion rejections; nil for detectors that
+	// don't produce one.
+	Report *DetectionReport
 }
 
 // DetectionParams holds parameters for via detection.
@@ -145,6 +149,49 @@ type DetectionParams struct {
 	HoughParam2         float64 // Accumulator threshold for circle detection
 	RequireHoughConfirm bool    // If true, only keep candidates also found by Hough
 
+	// Optional pre-filter applied to the source image before the brightness
+	// mask and Hough steps. It trades fine detail for fewer false positives on
+	// noisy or JPEG-compressed scans. The radius should scale with DPI (about
+	// 1 pixel per 300 DPI) and stay well below MinRadiusPixels, or small vias
+	// blur into the background.
+	PreBlur       PreBlurMode
+	PreBlurRadius int // Kernel radius in pixels (kernel size = 2*radius+1)
+
 	// DPI for size calculations
 	DPI float64
+
+	// Let Classifier decide candidates within classifierMargin of a
+	// circularity, contrast or color threshold. Clear-cut candidates are
+	// still decided by the thresholds.
+	UseClassifier bool
+	Classifier    *Classifier `json:"-"`
+
+	// Optional progress callback, called as candidates are verified
+	Progress ProgressFunc `json:"-"`
+}
+
+// ProgressFunc reports that done of total items have been processed. It may
+// be called from worker goroutines, so implementations must be safe for
+// concurrent use (UI callers marshal to the main thread themselves).
+type ProgressFunc func(done, total int)
+
+// PreBlurMode selects the noise filter applied before via detection.
+type PreBlurMode int
+
+const (
+	PreBlurNone     PreBlurMode = iota // No pre-filter
+	PreBlurMedian                      // Median filter: removes salt-and-pepper noise, keeps edges
+	PreBlurGaussian                    // Gaussian blur: smooths JPEG block artifacts
+)
+
+// String returns the display name of the pre-blur mode.
+func (m PreBlurMode) String() string {
+	switch m {
+	case PreBlurMedian:
+		return "Median"
+	case PreBlurGaussian:
+		return "Gaussian"
+	default:
+		return "None"
+	}
 }
@@ -4,9 +4,11 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"pcb-tracer/internal/app"
+	"pcb-tracer/internal/board"
 	"pcb-tracer/ui/mainwindow"
 	"pcb-tracer/ui/prefs"
 
@@ -25,6 +27,14 @@ func main() {
 
 	gtk.Init(nil)
 
+	names, err := board.LoadUserProfiles()
+	if len(names) > 0 {
+		log.Printf("Board profiles: loaded %s", strings.Join(names, ", "))
+	}
+	if err != nil {
+		log.Printf("Board profiles: %v", err)
+	}
+
 	appState := app.NewState()
 	appPrefs := prefs.Load()
 
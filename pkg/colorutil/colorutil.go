@@ -54,3 +54,31 @@ func RGBToHSV(r, g, b float64) (h, s, v float64) {
 
 	return h, s, v
 }
+
+// RGBToLab converts sRGB (0-255) to CIE L*a*b* (D65 white: L 0-100, a and b
+// roughly -128 to 127).
+func RGBToLab(r, g, b float64) (l, a, bb float64) {
+	linear := func(c float64) float64 {
+		c /= 255.0
+		if c <= 0.04045 {
+			return c / 12.92
+		}
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	rl, gl, bl := linear(r), linear(g), linear(b)
+
+	// Linear sRGB to XYZ, normalized to the D65 white point
+	x := (0.4124*rl + 0.3576*gl + 0.1805*bl) / 0.95047
+	y := 0.2126*rl + 0.7152*gl + 0.0722*bl
+	z := (0.0193*rl + 0.1192*gl + 0.9505*bl) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389.0 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27.0*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
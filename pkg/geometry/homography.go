@@ -0,0 +1,71 @@
+package geometry
+
+import "math"
+
+// Homography is a 3x3 projective transform in row-major order. Unlike an
+// AffineTransform it can map a keystoned quadrilateral onto a rectangle.
+type Homography [3][3]float64
+
+// IdentityHomography returns the identity homography.
+func IdentityHomography() Homography {
+	return Homography{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+}
+
+// HomographyFromAffine returns the homography equivalent of t.
+func HomographyFromAffine(t AffineTransform) Homography {
+	return Homography{{t.A, t.B, t.TX}, {t.C, t.D, t.TY}, {0, 0, 1}}
+}
+
+// Apply maps p through the homography. Points on the line at infinity map
+// to +Inf.
+func (h Homography) Apply(p Point2D) Point2D {
+	w := h[2][0]*p.X + h[2][1]*p.Y + h[2][2]
+	if w == 0 {
+		return Point2D{X: math.Inf(1), Y: math.Inf(1)}
+	}
+	return Point2D{
+		X: (h[0][0]*p.X + h[0][1]*p.Y + h[0][2]) / w,
+		Y: (h[1][0]*p.X + h[1][1]*p.Y + h[1][2]) / w,
+	}
+}
+
+// Compose returns h applied after other (h * other).
+func (h Homography) Compose(other Homography) Homography {
+	var out Homography
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				out[i][j] += h[i][k] * other[k][j]
+			}
+		}
+	}
+	return out
+}
+
+// Inverse returns the inverse homography, if it exists.
+func (h Homography) Inverse() (Homography, bool) {
+	det := h[0][0]*(h[1][1]*h[2][2]-h[1][2]*h[2][1]) -
+		h[0][1]*(h[1][0]*h[2][2]-h[1][2]*h[2][0]) +
+		h[0][2]*(h[1][0]*h[2][1]-h[1][1]*h[2][0])
+	if math.Abs(det) < 1e-12 {
+		return Homography{}, false
+	}
+	inv := 1 / det
+	return Homography{
+		{
+			(h[1][1]*h[2][2] - h[1][2]*h[2][1]) * inv,
+			(h[0][2]*h[2][1] - h[0][1]*h[2][2]) * inv,
+			(h[0][1]*h[1][2] - h[0][2]*h[1][1]) * inv,
+		},
+		{
+			(h[1][2]*h[2][0] - h[1][0]*h[2][2]) * inv,
+			(h[0][0]*h[2][2] - h[0][2]*h[2][0]) * inv,
+			(h[0][2]*h[1][0] - h[0][0]*h[1][2]) * inv,
+		},
+		{
+			(h[1][0]*h[2][1] - h[1][1]*h[2][0]) * inv,
+			(h[0][1]*h[2][0] - h[0][0]*h[2][1]) * inv,
+			(h[0][0]*h[1][1] - h[0][1]*h[1][0]) * inv,
+		},
+	}, true
+}
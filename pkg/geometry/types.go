@@ -136,6 +136,20 @@ func Rotation(radians float64) AffineTransform {
 	return AffineTransform{A: cos, B: -sin, C: sin, D: cos}
 }
 
+// RotationAbout returns a rotation by degrees around center. Positive
+// angles turn clockwise in image coordinates (Y down), matching a layer's
+// ManualRotation.
+func RotationAbout(center Point2D, degrees float64) AffineTransform {
+	return Translation(center.X, center.Y).
+		Compose(Rotation(degrees * math.Pi / 180)).
+		Compose(Translation(-center.X, -center.Y))
+}
+
+// RotateAbout rotates p by degrees around center.
+func RotateAbout(p Point2D, center Point2D, degrees float64) Point2D {
+	return RotationAbout(center, degrees).Apply(p)
+}
+
 // Scale returns a scaling transform.
 func Scale(sx, sy float64) AffineTransform {
 	return AffineTransform{A: sx, D: sy}
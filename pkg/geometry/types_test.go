@@ -0,0 +1,32 @@
+package geometry
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRotateAbout(t *testing.T) {
+	center := Point2D{X: 5, Y: 5}
+
+	// Clockwise on screen (Y down): right of center goes below it
+	got := RotateAbout(Point2D{X: 10, Y: 5}, center, 90)
+	if got.Distance(Point2D{X: 5, Y: 10}) > 1e-9 {
+		t.Errorf("RotateAbout((10,5), (5,5), 90) = %v, want (5,10)", got)
+	}
+
+	if got := RotateAbout(center, center, 37); got.Distance(center) > 1e-9 {
+		t.Errorf("center moved to %v", got)
+	}
+
+	p := Point2D{X: 1234.5, Y: -87.25}
+	for _, deg := range []float64{0.1, -0.35, 2.5, 90, 180, -179} {
+		back := RotateAbout(RotateAbout(p, center, deg), center, -deg)
+		if back.Distance(p) > 1e-9 {
+			t.Errorf("round trip by %v° moved %v to %v", deg, p, back)
+		}
+		r := RotateAbout(p, center, deg)
+		if d0, d1 := p.Distance(center), r.Distance(center); math.Abs(d0-d1) > 1e-9 {
+			t.Errorf("rotation by %v° changed radius %v -> %v", deg, d0, d1)
+		}
+	}
+}
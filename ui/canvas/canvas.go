@@ -7,6 +7,7 @@ import (
 	"image/draw"
 	"math"
 	"sort"
+	"time"
 
 	pcbimage "pcb-tracer/internal/image"
 	"pcb-tracer/pkg/geometry"
@@ -23,6 +24,10 @@ const (
 	zoomStep = 1.25
 )
 
+// defaultDoubleClickTime is used when GTK's gtk-double-click-time setting
+// can't be read.
+const defaultDoubleClickTime = 400 * time.Millisecond
+
 // Tool represents the current interaction tool.
 type Tool int
 
@@ -61,6 +66,10 @@ type ImageCanvas struct {
 	// Overlays (keyed by name, e.g., "front_contacts", "back_contacts")
 	overlays map[string]*Overlay
 
+	// Cached overlay rasterizations (see overlaycache.go)
+	overlayRasters map[*Overlay]*overlayRaster
+	overlayScratch *image.RGBA
+
 	// Connector labels (drawn with layer opacity)
 	connectorLabels []ConnectorLabel
 
@@ -83,6 +92,17 @@ type ImageCanvas struct {
 	selectEnd     geometry.Point2D
 	selectionRect *OverlayRect // Current selection rectangle (in image coords)
 
+	// Replaces onSelect for the drag started by SelectRegion
+	selectOnce func(x1, y1, x2, y2 float64)
+
+	// Ruler (measure mode)
+	measureMode  bool // When true, left-drag draws a ruler
+	measuring    bool // A ruler drag is in progress
+	measureShown bool // Draw the last ruler
+	measureStart geometry.Point2D
+	measureEnd   geometry.Point2D
+	onMeasure    func(p1, p2 geometry.Point2D, dist Measurement)
+
 	// Canvas size tracking
 	imgWidth  int
 	imgHeight int
@@ -103,14 +123,16 @@ type ImageCanvas struct {
 	lastModifiers uint
 
 	// Callbacks
-	onZoomChange   func(zoom float64)
-	onSelect       func(x1, y1, x2, y2 float64) // Called with image coordinates
-	onRightSelect  func(x1, y1, x2, y2 float64) // Called on shift+right-drag selection
-	onLeftClick    func(x, y float64)            // Left click at image coordinates
-	onRightClick   func(x, y float64)            // Right click at image coordinates
-	onMiddleClick  func(x, y float64)            // Middle click at image coordinates
-	onMouseMove    func(x, y float64)            // Mouse move at image coordinates
-	onHover        func(x, y float64)            // Always-active hover callback
+	onZoomChange  func(zoom float64)
+	onSelect      func(x1, y1, x2, y2 float64) // Called with image coordinates
+	onRightSelect func(x1, y1, x2, y2 float64) // Called on shift+right-drag selection
+	onLeftClick   func(x, y float64)           // Left click at image coordinates
+	onDoubleClick func(x, y float64)           // Second of two quick left clicks at image coordinates
+	onRightClick  func(x, y float64)           // Right click at image coordinates
+	onMiddleClick func(x, y float64)           // Middle click at image coordinates
+	onMouseMove   func(x, y float64)           // Mouse move at image coordinates
+	onHover       func(x, y float64)           // Always-active hover callback
+	onLayerRaise  func(side pcbimage.Side)     // Called after a layer is raised to the top
 
 	// Right-button drag selection (shift+right-click)
 	rightDragging    bool
@@ -131,6 +153,13 @@ type ImageCanvas struct {
 	// Left-button drag for selection
 	leftDragging bool
 
+	// Editable rectangle (see EditRect), nil when inactive
+	rectEdit *rectEdit
+
+	// Marker sizing (see SetMarkerStyle)
+	markerScale          float64
+	markerScreenConstant bool
+
 	// Pending labels accumulated during draw(), rendered with Cairo text
 	pendingLabels []pendingLabel
 }
@@ -151,6 +180,8 @@ func NewImageCanvas() *ImageCanvas {
 		tool:     ToolPan,
 		layers:   make([]*pcbimage.Layer, 0),
 		overlays: make(map[string]*Overlay),
+
+		markerScale: 1.0,
 	}
 
 	da, _ := gtk.DrawingAreaNew()
@@ -191,6 +222,35 @@ func NewImageCanvas() *ImageCanvas {
 
 		switch btn.Button() {
 		case 1: // Left click
+			switch btn.Type() {
+			case gdk.EVENT_2BUTTON_PRESS:
+				// Follows the pair's second press, already delivered as a click
+				if ic.onDoubleClick != nil && ic.rectEdit == nil && !ic.measureMode && !ic.selectMode {
+					ic.onDoubleClick(imgX, imgY)
+				}
+				return true
+			case gdk.EVENT_3BUTTON_PRESS:
+				return true
+			}
+			if re := ic.rectEdit; re != nil {
+				if edges := HitTestRectEdges(re.rect, imgX, imgY, 6/ic.zoom); edges != 0 {
+					re.dragging = true
+					re.edges = edges
+					re.start = geometry.Point2D{X: imgX, Y: imgY}
+					re.orig = re.rect
+					ic.leftDragging = true
+					return true
+				}
+			}
+			if ic.measureMode {
+				ic.leftDragging = true
+				ic.measuring = true
+				ic.measureShown = true
+				ic.measureStart = geometry.Point2D{X: imgX, Y: imgY}
+				ic.measureEnd = ic.measureStart
+				ic.drawArea.QueueDraw()
+				return true
+			}
 			if ic.selectMode {
 				ic.leftDragging = true
 				ic.selecting = true
@@ -228,13 +288,32 @@ func NewImageCanvas() *ImageCanvas {
 		btn := gdk.EventButtonNewFromEvent(ev)
 		switch btn.Button() {
 		case 1: // Left release
+			if re := ic.rectEdit; re != nil && re.dragging {
+				re.dragging = false
+				ic.leftDragging = false
+				return true
+			}
+			if ic.leftDragging && ic.measuring {
+				ic.leftDragging = false
+				ic.measuring = false
+				if ic.onMeasure != nil {
+					ic.onMeasure(ic.measureStart, ic.measureEnd,
+						NewMeasurement(ic.measureStart, ic.measureEnd, ic.gridDPI))
+				}
+				ic.drawArea.QueueDraw()
+				return true
+			}
 			if ic.leftDragging && ic.selecting {
 				ic.leftDragging = false
 				ic.selecting = false
 				ic.selectMode = false
-				if ic.onSelect != nil && ic.selectionRect != nil {
+				onSelect := ic.onSelect
+				if ic.selectOnce != nil {
+					onSelect, ic.selectOnce = ic.selectOnce, nil
+				}
+				if onSelect != nil && ic.selectionRect != nil {
 					rect := ic.selectionRect
-					ic.onSelect(
+					onSelect(
 						float64(rect.X), float64(rect.Y),
 						float64(rect.X+rect.Width), float64(rect.Y+rect.Height),
 					)
@@ -282,6 +361,27 @@ func NewImageCanvas() *ImageCanvas {
 			return true
 		}
 
+		// Editable rectangle drag
+		if re := ic.rectEdit; re != nil && re.dragging {
+			dx := int(math.Round(imgX - re.start.X))
+			dy := int(math.Round(imgY - re.start.Y))
+			r := DragRectEdges(re.orig, re.edges, dx, dy, re.clamp, re.minSize)
+			if r != re.rect {
+				re.rect = r
+				if re.onChange != nil {
+					re.onChange(r)
+				}
+			}
+			return true
+		}
+
+		// Ruler drag
+		if ic.leftDragging && ic.measuring {
+			ic.measureEnd = geometry.Point2D{X: imgX, Y: imgY}
+			ic.drawArea.QueueDraw()
+			return true
+		}
+
 		// Left-button or right-button drag for selection
 		if (ic.leftDragging || ic.rightDragging) && ic.selecting {
 			var start geometry.Point2D
@@ -358,6 +458,14 @@ func (ic *ImageCanvas) EnableSelectMode() {
 	ic.selectMode = true
 	ic.selecting = false
 	ic.selectionRect = nil
+	ic.selectOnce = nil
+}
+
+// SelectRegion enables selection mode for the next drag and reports that
+// selection to callback instead of the OnSelect callback.
+func (ic *ImageCanvas) SelectRegion(callback func(x1, y1, x2, y2 float64)) {
+	ic.EnableSelectMode()
+	ic.selectOnce = callback
 }
 
 // SetLayers sets the layers to display.
@@ -389,6 +497,9 @@ func (ic *ImageCanvas) RaiseLayer(layer *pcbimage.Layer) {
 		if l == layer {
 			ic.layers = append(ic.layers[:i], ic.layers[i+1:]...)
 			ic.layers = append(ic.layers, layer)
+			if ic.onLayerRaise != nil {
+				ic.onLayerRaise(layer.Side)
+			}
 			ic.Refresh()
 			return
 		}
@@ -401,14 +512,29 @@ func (ic *ImageCanvas) RaiseLayerBySide(side pcbimage.Side) {
 		if l != nil && l.Side == side {
 			ic.layers = append(ic.layers[:i], ic.layers[i+1:]...)
 			ic.layers = append(ic.layers, l)
+			if ic.onLayerRaise != nil {
+				ic.onLayerRaise(side)
+			}
 			ic.Refresh()
 			return
 		}
 	}
 }
 
-// SetOverlay sets an overlay with the given name.
+// ActiveSide returns the side of the topmost layer, or SideUnknown if none.
+func (ic *ImageCanvas) ActiveSide() pcbimage.Side {
+	if len(ic.layers) == 0 || ic.layers[len(ic.layers)-1] == nil {
+		return pcbimage.SideUnknown
+	}
+	return ic.layers[len(ic.layers)-1].Side
+}
+
+// SetOverlay sets an overlay with the given name. Overlays are rasterized
+// once and cached; set the overlay again (or call InvalidateOverlays) after
+// editing its shapes in place.
 func (ic *ImageCanvas) SetOverlay(name string, overlay *Overlay) {
+	ic.invalidateOverlayRaster(ic.overlays[name])
+	ic.invalidateOverlayRaster(overlay)
 	ic.overlays[name] = overlay
 	ic.Refresh()
 }
@@ -420,6 +546,7 @@ func (ic *ImageCanvas) GetOverlay(name string) *Overlay {
 
 // ClearOverlay removes an overlay by name.
 func (ic *ImageCanvas) ClearOverlay(name string) {
+	ic.invalidateOverlayRaster(ic.overlays[name])
 	delete(ic.overlays, name)
 	ic.Refresh()
 }
@@ -427,6 +554,7 @@ func (ic *ImageCanvas) ClearOverlay(name string) {
 // ClearAllOverlays removes all overlays.
 func (ic *ImageCanvas) ClearAllOverlays() {
 	ic.overlays = make(map[string]*Overlay)
+	ic.overlayRasters = nil
 	ic.Refresh()
 }
 
@@ -696,6 +824,27 @@ func (ic *ImageCanvas) OnLeftClick(callback func(x, y float64)) {
 	ic.onLeftClick = callback
 }
 
+// OnDoubleClick sets a callback for left double-clicks. Both clicks are
+// still delivered to the OnLeftClick callback first; a click handler that
+// must not act on the first click of a pair can wait DoubleClickTime.
+func (ic *ImageCanvas) OnDoubleClick(callback func(x, y float64)) {
+	ic.onDoubleClick = callback
+}
+
+// DoubleClickTime returns the longest gap between the two clicks of a
+// double-click, from the GTK settings.
+func DoubleClickTime() time.Duration {
+	settings, err := gtk.SettingsGetDefault()
+	if err != nil {
+		return defaultDoubleClickTime
+	}
+	v, err := settings.GetProperty("gtk-double-click-time")
+	if ms, ok := v.(int); err == nil && ok && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultDoubleClickTime
+}
+
 // OnRightClick sets a callback for right-click events.
 func (ic *ImageCanvas) OnRightClick(callback func(x, y float64)) {
 	ic.onRightClick = callback
@@ -717,11 +866,97 @@ func (ic *ImageCanvas) OnHover(callback func(x, y float64)) {
 	ic.onHover = callback
 }
 
+// OnLayerRaise sets a callback invoked when a layer is raised to the top.
+func (ic *ImageCanvas) OnLayerRaise(callback func(side pcbimage.Side)) {
+	ic.onLayerRaise = callback
+}
+
 // GetRenderedOutput returns the last rendered canvas output for sampling.
 func (ic *ImageCanvas) GetRenderedOutput() *image.RGBA {
 	return ic.lastOutput
 }
 
+// RenderOverlayLayer rasterizes a single named overlay, labels included, onto
+// a transparent image at full source resolution (zoom 1, covering the loaded
+// layers). Returns nil if the overlay does not exist or no image is loaded.
+// Labels use the built-in pixel font since there is no Cairo context.
+func (ic *ImageCanvas) RenderOverlayLayer(name string) *image.RGBA {
+	overlay := ic.overlays[name]
+	bounds := ic.getLayerBounds()
+	if overlay == nil || bounds.Empty() {
+		return nil
+	}
+
+	savedZoom := ic.zoom
+	savedLabels := ic.pendingLabels
+	ic.zoom = 1.0
+	ic.pendingLabels = nil
+	defer func() {
+		ic.zoom = savedZoom
+		ic.pendingLabels = savedLabels
+	}()
+
+	output := image.NewRGBA(bounds)
+	ic.drawOverlay(output, overlay)
+	ic.rasterizePendingLabels(output)
+	return output
+}
+
+// RenderLayers composites the visible image layers at full source resolution
+// (zoom 1) onto a transparent image, without overlays or the background grid.
+// Returns nil if no image is loaded.
+func (ic *ImageCanvas) RenderLayers() *image.RGBA {
+	bounds := ic.getLayerBounds()
+	if bounds.Empty() {
+		return nil
+	}
+
+	savedZoom := ic.zoom
+	ic.zoom = 1.0
+	defer func() { ic.zoom = savedZoom }()
+
+	output := image.NewRGBA(bounds)
+	for _, layer := range ic.layers {
+		if layer == nil || layer.Image == nil || !layer.Visible {
+			continue
+		}
+		ic.compositeLayer(output, layer, bounds.Dx(), bounds.Dy())
+		ic.drawConnectorLabelsForLayer(output, layer)
+	}
+	return output
+}
+
+// RenderOverlays draws every visible overlay, labels included, onto dst at
+// zoom 1, as they appear on screen. Pair with RenderLayers for a full-size
+// annotated image.
+func (ic *ImageCanvas) RenderOverlays(dst *image.RGBA) {
+	savedZoom := ic.zoom
+	savedLabels := ic.pendingLabels
+	ic.zoom = 1.0
+	ic.pendingLabels = nil
+	defer func() {
+		ic.zoom = savedZoom
+		ic.pendingLabels = savedLabels
+	}()
+
+	for _, overlay := range ic.visibleOverlays() {
+		ic.drawOverlay(dst, overlay)
+	}
+	ic.rasterizePendingLabels(dst)
+}
+
+// rasterizePendingLabels draws queued labels with the built-in pixel font,
+// for offscreen renders that have no Cairo context.
+func (ic *ImageCanvas) rasterizePendingLabels(output *image.RGBA) {
+	for _, pl := range ic.pendingLabels {
+		if pl.rotated {
+			DrawRotatedLabel(output, pl.text, pl.x, pl.y, pl.col, 2)
+		} else {
+			ic.drawLabel(output, pl.text, pl.x, pl.y, pl.x, pl.y, pl.col)
+		}
+	}
+}
+
 // Refresh redraws the canvas.
 func (ic *ImageCanvas) Refresh() {
 	ic.drawArea.QueueDraw()
@@ -810,6 +1045,20 @@ func (ic *ImageCanvas) drawGridBackground(output *image.RGBA, w, h int) {
 	}
 }
 
+// displayLevel returns the layer's display pyramid and the level to sample
+// at the current zoom, or nil when zoomed in far enough to need full
+// resolution (the pyramid is then not built at all).
+func (ic *ImageCanvas) displayLevel(layer *pcbimage.Layer) (*pcbimage.Pyramid, int) {
+	if ic.zoom > 0.5 {
+		return nil, 0
+	}
+	pyramid := layer.Pyramid()
+	if pyramid == nil {
+		return nil, 0
+	}
+	return pyramid, pyramid.Level(ic.zoom)
+}
+
 // compositeLayer draws a single layer onto the output with opacity.
 func (ic *ImageCanvas) compositeLayer(output *image.RGBA, layer *pcbimage.Layer, w, h int) {
 	src := layer.Image
@@ -825,22 +1074,10 @@ func (ic *ImageCanvas) compositeLayer(output *image.RGBA, layer *pcbimage.Layer,
 	offsetY := float64(layer.ManualOffsetY)
 	rotation := layer.ManualRotation * math.Pi / 180.0
 
-	shearTopX := layer.ShearTopX
-	shearBottomX := layer.ShearBottomX
-	shearLeftY := layer.ShearLeftY
-	shearRightY := layer.ShearRightY
-	if shearTopX == 0 {
-		shearTopX = 1.0
-	}
-	if shearBottomX == 0 {
-		shearBottomX = 1.0
-	}
-	if shearLeftY == 0 {
-		shearLeftY = 1.0
-	}
-	if shearRightY == 0 {
-		shearRightY = 1.0
-	}
+	shearTopX := pcbimage.ShearFactor(layer.ShearTopX)
+	shearBottomX := pcbimage.ShearFactor(layer.ShearBottomX)
+	shearLeftY := pcbimage.ShearFactor(layer.ShearLeftY)
+	shearRightY := pcbimage.ShearFactor(layer.ShearRightY)
 
 	cosR := math.Cos(-rotation)
 	sinR := math.Sin(-rotation)
@@ -863,6 +1100,7 @@ func (ic *ImageCanvas) compositeLayer(output *image.RGBA, layer *pcbimage.Layer,
 	vizEnabled := ic.stepEdgeViz.Enabled
 	vizBandWidth := ic.stepEdgeViz.BandWidth
 	isFront := layer.Side == pcbimage.SideFront
+	pyramid, level := ic.displayLevel(layer)
 
 	for y := 0; y < h; y++ {
 		for x := 0; x < w; x++ {
@@ -900,8 +1138,8 @@ func (ic *ImageCanvas) compositeLayer(output *image.RGBA, layer *pcbimage.Layer,
 				scaledX := rotX / scaleX
 				scaledY := rotY / scaleY
 
-				srcX = int(scaledX + srcCx)
-				srcY = int(scaledY + srcCy)
+				// Round as the saved (bilinear) output does
+				srcX, srcY = pcbimage.NearestPixel(scaledX+srcCx, scaledY+srcCy)
 			} else {
 				srcX = int(imgX) + srcBounds.Min.X
 				srcY = int(imgY) + srcBounds.Min.Y
@@ -912,7 +1150,12 @@ func (ic *ImageCanvas) compositeLayer(output *image.RGBA, layer *pcbimage.Layer,
 				continue
 			}
 
-			srcColor := src.At(srcX, srcY)
+			var srcColor color.Color
+			if pyramid != nil {
+				srcColor = pyramid.At(level, srcX, srcY)
+			} else {
+				srcColor = src.At(srcX, srcY)
+			}
 			sr, sg, sb, sa := srcColor.RGBA()
 			effectiveAlpha := float64(sa) / 0xffff * opacity
 
@@ -950,6 +1193,7 @@ func (ic *ImageCanvas) compositeLayerNormalized(output *image.RGBA, layer *pcbim
 	vizEnabled := ic.stepEdgeViz.Enabled
 	vizBandWidth := ic.stepEdgeViz.BandWidth
 	isFront := layer.Side == pcbimage.SideFront
+	pyramid, level := ic.displayLevel(layer)
 
 	for y := 0; y < h; y++ {
 		for x := 0; x < w; x++ {
@@ -961,7 +1205,12 @@ func (ic *ImageCanvas) compositeLayerNormalized(output *image.RGBA, layer *pcbim
 				continue
 			}
 
-			srcColor := src.At(srcX, srcY)
+			var srcColor color.Color
+			if pyramid != nil {
+				srcColor = pyramid.At(level, srcX, srcY)
+			} else {
+				srcColor = src.At(srcX, srcY)
+			}
 			sr, sg, sb, sa := srcColor.RGBA()
 			effectiveAlpha := float64(sa) / 0xffff * opacity
 
@@ -1015,27 +1264,9 @@ func (ic *ImageCanvas) drawConnectorLabelsForLayer(output *image.RGBA, layer *pc
 	}
 }
 
-// draw is the raster drawing function.
-func (ic *ImageCanvas) draw(w, h int) image.Image {
-	output := image.NewRGBA(image.Rect(0, 0, w, h))
-	ic.pendingLabels = ic.pendingLabels[:0]
-
-	// Fill with 1mm grid background (black and white squares)
-	ic.drawGridBackground(output, w, h)
-
-	// Composite each visible layer and draw connector labels with matching opacity
-	for _, layer := range ic.layers {
-		if layer == nil || layer.Image == nil || !layer.Visible {
-			continue
-		}
-		ic.compositeLayer(output, layer, w, h)
-		ic.drawConnectorLabelsForLayer(output, layer)
-	}
-
-	// Store for sampling (copy to avoid including overlays)
-	ic.lastOutput = image.NewRGBA(output.Bounds())
-	draw.Draw(ic.lastOutput, ic.lastOutput.Bounds(), output, image.Point{}, draw.Src)
-
+// visibleOverlays returns the overlays to draw sorted by ZOrder, skipping
+// layer-specific overlays whose layer is not on top.
+func (ic *ImageCanvas) visibleOverlays() []*Overlay {
 	// Determine which layer is on top for overlay filtering
 	topLayerRef := LayerNone
 	if len(ic.layers) > 0 {
@@ -1049,7 +1280,6 @@ func (ic *ImageCanvas) draw(w, h int) image.Image {
 		}
 	}
 
-	// Draw overlays sorted by ZOrder, skipping layer-specific overlays not on the top layer
 	sortedOverlays := make([]*Overlay, 0, len(ic.overlays))
 	for _, overlay := range ic.overlays {
 		if overlay != nil {
@@ -1062,8 +1292,33 @@ func (ic *ImageCanvas) draw(w, h int) image.Image {
 	sort.Slice(sortedOverlays, func(i, j int) bool {
 		return sortedOverlays[i].ZOrder < sortedOverlays[j].ZOrder
 	})
-	for _, overlay := range sortedOverlays {
-		ic.drawOverlay(output, overlay)
+	return sortedOverlays
+}
+
+// draw is the raster drawing function.
+func (ic *ImageCanvas) draw(w, h int) image.Image {
+	output := image.NewRGBA(image.Rect(0, 0, w, h))
+	ic.pendingLabels = ic.pendingLabels[:0]
+
+	// Fill with 1mm grid background (black and white squares)
+	ic.drawGridBackground(output, w, h)
+
+	// Composite each visible layer and draw connector labels with matching opacity
+	for _, layer := range ic.layers {
+		if layer == nil || layer.Image == nil || !layer.Visible {
+			continue
+		}
+		ic.compositeLayer(output, layer, w, h)
+		ic.drawConnectorLabelsForLayer(output, layer)
+	}
+
+	// Store for sampling (copy to avoid including overlays)
+	ic.lastOutput = image.NewRGBA(output.Bounds())
+	draw.Draw(ic.lastOutput, ic.lastOutput.Bounds(), output, image.Point{}, draw.Src)
+
+	// Draw overlays sorted by ZOrder
+	for _, overlay := range ic.visibleOverlays() {
+		ic.drawOverlayCached(output, overlay)
 	}
 
 	// Draw rubber band line or rectangle if active
@@ -1088,6 +1343,10 @@ func (ic *ImageCanvas) draw(w, h int) image.Image {
 		ic.drawSelectionRect(output, ic.selectionRect)
 	}
 
+	if ic.measureMode && ic.measureShown {
+		ic.drawMeasurement(output)
+	}
+
 	return output
 }
 
@@ -0,0 +1,84 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	pcbimage "pcb-tracer/internal/image"
+)
+
+// TestRenderOverlayLayer renders a component overlay with a filled,
+// labeled box and an outlined box: pixels outside the boxes stay
+// transparent, the boxes are opaque, and other overlays are left out.
+func TestRenderOverlayLayer(t *testing.T) {
+	front := &pcbimage.Layer{Side: pcbimage.SideFront, IsNormalized: true, Image: image.NewRGBA(image.Rect(0, 0, 200, 120))}
+	red := color.RGBA{R: 255, A: 255}
+	ic := &ImageCanvas{
+		zoom:   3,
+		layers: []*pcbimage.Layer{front},
+		overlays: map[string]*Overlay{
+			"components": {Color: red, Layer: LayerFront, Rectangles: []OverlayRect{
+				{X: 20, Y: 20, Width: 60, Height: 40, Fill: FillSolid, Label: "U1"},
+				{X: 120, Y: 30, Width: 50, Height: 50},
+			}},
+			"vias": {Color: red, Layer: LayerFront, Circles: []OverlayCircle{{X: 100, Y: 100, Radius: 5, Filled: true}}},
+		},
+	}
+
+	img := ic.RenderOverlayLayer("components")
+	if img == nil {
+		t.Fatal("no image for the component overlay")
+	}
+	if img.Bounds() != image.Rect(0, 0, 200, 120) {
+		t.Fatalf("bounds %v, want the 200×120 layer at zoom 1", img.Bounds())
+	}
+	in := func(x, y, x1, y1, x2, y2 int) bool { return x >= x1 && x <= x2 && y >= y1 && y <= y2 }
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 200; x++ {
+			a := img.RGBAAt(x, y).A
+			switch {
+			case in(x, y, 20, 20, 80, 60):
+				if a != 255 {
+					t.Fatalf("filled box pixel (%d,%d) alpha %d, want opaque", x, y, a)
+				}
+			case in(x, y, 122, 32, 168, 78):
+				if a != 0 {
+					t.Fatalf("inside the outline (%d,%d) alpha %d, want transparent", x, y, a)
+				}
+			case in(x, y, 120, 30, 170, 80):
+				if a != 255 {
+					t.Fatalf("outline pixel (%d,%d) alpha %d, want opaque", x, y, a)
+				}
+			default:
+				if a != 0 {
+					t.Fatalf("pixel (%d,%d) outside the boxes alpha %d, want transparent", x, y, a)
+				}
+			}
+		}
+	}
+
+	// The label is drawn in black over the fill
+	var label int
+	for y := 20; y <= 60; y++ {
+		for x := 20; x <= 80; x++ {
+			if img.RGBAAt(x, y) == (color.RGBA{A: 255}) {
+				label++
+			}
+		}
+	}
+	if label == 0 {
+		t.Error("no label pixels in the filled box")
+	}
+
+	if ic.zoom != 3 || ic.pendingLabels != nil {
+		t.Errorf("canvas left at zoom %v with %d pending labels, want 3 and none", ic.zoom, len(ic.pendingLabels))
+	}
+	if ic.RenderOverlayLayer("missing") != nil {
+		t.Error("unknown overlay: want nil")
+	}
+	ic.layers = nil
+	if ic.RenderOverlayLayer("components") != nil {
+		t.Error("no layers loaded: want nil")
+	}
+}
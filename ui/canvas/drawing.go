@@ -57,6 +57,7 @@ var letterPatterns = map[rune][5]uint8{
 	'+': {0b000, 0b010, 0b111, 0b010, 0b000},
 	'-': {0b000, 0b000, 0b111, 0b000, 0b000},
 	'*': {0b000, 0b101, 0b010, 0b101, 0b000},
+	'.': {0b000, 0b000, 0b000, 0b000, 0b010},
 	' ': {0b000, 0b000, 0b000, 0b000, 0b000},
 }
 
@@ -76,31 +77,56 @@ func getCharPattern(ch rune) [5]uint8 {
 	return [5]uint8{} // Empty pattern for unsupported characters
 }
 
-// drawOverlay draws an overlay on the output image.
-func (ic *ImageCanvas) drawOverlay(output *image.RGBA, overlay *Overlay) {
-	col := overlay.Color
+// blendPixel sets an overlay pixel, alpha-blending over the existing pixel when
+// col is translucent. col is treated as straight (non-premultiplied) alpha,
+// which is how overlay colors are built. Opaque colors take the fast path.
+func blendPixel(output *image.RGBA, x, y int, col color.RGBA) {
+	if col.A == 255 {
+		output.Set(x, y, col)
+		return
+	}
+	if col.A == 0 {
+		return
+	}
+	i := output.PixOffset(x, y)
+	a := uint32(col.A)
+	inv := 255 - a
+	output.Pix[i+0] = uint8((uint32(col.R)*a + uint32(output.Pix[i+0])*inv) / 255)
+	output.Pix[i+1] = uint8((uint32(col.G)*a + uint32(output.Pix[i+1])*inv) / 255)
+	output.Pix[i+2] = uint8((uint32(col.B)*a + uint32(output.Pix[i+2])*inv) / 255)
+	output.Pix[i+3] = uint8(a + uint32(output.Pix[i+3])*inv/255)
+}
 
-	// Get layer offset if overlay is associated with a non-normalized layer.
-	// Normalized layers have all transforms baked in, so overlay coordinates
-	// are already in the correct image space — no offset adjustment needed.
-	var offsetX, offsetY float64
-	if overlay.Layer != LayerNone {
-		for _, layer := range ic.layers {
-			if overlay.Layer == LayerFront && layer.Side == pcbimage.SideFront {
-				if !layer.IsNormalized {
-					offsetX = float64(layer.ManualOffsetX)
-					offsetY = float64(layer.ManualOffsetY)
-				}
-				break
-			} else if overlay.Layer == LayerBack && layer.Side == pcbimage.SideBack {
-				if !layer.IsNormalized {
-					offsetX = float64(layer.ManualOffsetX)
-					offsetY = float64(layer.ManualOffsetY)
-				}
-				break
+// overlayOffset returns the layer offset applied to an overlay associated
+// with a non-normalized layer. Normalized layers have all transforms baked
+// in, so overlay coordinates are already in the correct image space — no
+// offset adjustment needed.
+func (ic *ImageCanvas) overlayOffset(overlay *Overlay) (offsetX, offsetY float64) {
+	if overlay.Layer == LayerNone {
+		return 0, 0
+	}
+	for _, layer := range ic.layers {
+		if overlay.Layer == LayerFront && layer.Side == pcbimage.SideFront {
+			if !layer.IsNormalized {
+				offsetX = float64(layer.ManualOffsetX)
+				offsetY = float64(layer.ManualOffsetY)
 			}
+			break
+		} else if overlay.Layer == LayerBack && layer.Side == pcbimage.SideBack {
+			if !layer.IsNormalized {
+				offsetX = float64(layer.ManualOffsetX)
+				offsetY = float64(layer.ManualOffsetY)
+			}
+			break
 		}
 	}
+	return offsetX, offsetY
+}
+
+// drawOverlay draws an overlay on the output image.
+func (ic *ImageCanvas) drawOverlay(output *image.RGBA, overlay *Overlay) {
+	col := overlay.Color
+	offsetX, offsetY := ic.overlayOffset(overlay)
 
 	for _, rect := range overlay.Rectangles {
 		// Per-rect color override
@@ -114,6 +140,14 @@ func (ic *ImageCanvas) drawOverlay(output *image.RGBA, overlay *Overlay) {
 		y1 := int((float64(rect.Y) + offsetY) * ic.zoom)
 		x2 := int((float64(rect.X+rect.Width) + offsetX) * ic.zoom)
 		y2 := int((float64(rect.Y+rect.Height) + offsetY) * ic.zoom)
+		if rect.Marker {
+			// Resize about the center by the marker zoom instead
+			cx := (float64(rect.X) + float64(rect.Width)/2 + offsetX) * ic.zoom
+			cy := (float64(rect.Y) + float64(rect.Height)/2 + offsetY) * ic.zoom
+			hw := float64(rect.Width) / 2 * ic.markerZoom()
+			hh := float64(rect.Height) / 2 * ic.markerZoom()
+			x1, y1, x2, y2 = int(cx-hw), int(cy-hh), int(cx+hw), int(cy+hh)
+		}
 
 		bounds := output.Bounds()
 
@@ -137,25 +171,25 @@ func (ic *ImageCanvas) drawOverlay(output *image.RGBA, overlay *Overlay) {
 			// Top edge
 			for x := x1; x <= x2; x++ {
 				if x >= bounds.Min.X && x < bounds.Max.X && y1+t >= bounds.Min.Y && y1+t < bounds.Max.Y {
-					output.Set(x, y1+t, rectCol)
+					blendPixel(output, x, y1+t, rectCol)
 				}
 			}
 			// Bottom edge
 			for x := x1; x <= x2; x++ {
 				if x >= bounds.Min.X && x < bounds.Max.X && y2-t >= bounds.Min.Y && y2-t < bounds.Max.Y {
-					output.Set(x, y2-t, rectCol)
+					blendPixel(output, x, y2-t, rectCol)
 				}
 			}
 			// Left edge
 			for y := y1; y <= y2; y++ {
 				if x1+t >= bounds.Min.X && x1+t < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					output.Set(x1+t, y, rectCol)
+					blendPixel(output, x1+t, y, rectCol)
 				}
 			}
 			// Right edge
 			for y := y1; y <= y2; y++ {
 				if x2-t >= bounds.Min.X && x2-t < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					output.Set(x2-t, y, rectCol)
+					blendPixel(output, x2-t, y, rectCol)
 				}
 			}
 		}
@@ -327,7 +361,7 @@ func (ic *ImageCanvas) drawPolygon(output *image.RGBA, poly OverlayPolygon, col
 				x2 := int(xIntersections[i+1])
 				for x := x1; x <= x2; x++ {
 					if x >= bounds.Min.X && x < bounds.Max.X {
-						output.Set(x, y, col)
+						blendPixel(output, x, y, col)
 					}
 				}
 			}
@@ -364,6 +398,9 @@ func (ic *ImageCanvas) drawCircle(output *image.RGBA, circle OverlayCircle, col
 	cx := (circle.X + offsetX) * ic.zoom
 	cy := (circle.Y + offsetY) * ic.zoom
 	r := circle.Radius * ic.zoom
+	if circle.Marker {
+		r = circle.Radius * ic.markerZoom()
+	}
 
 	// Integer bounds for iteration
 	minX := int(cx - r - 1)
@@ -390,12 +427,12 @@ func (ic *ImageCanvas) drawCircle(output *image.RGBA, circle OverlayCircle, col
 			if circle.Filled {
 				// Fill entire circle
 				if dist2 <= r2 {
-					output.Set(x, y, col)
+					blendPixel(output, x, y, col)
 				}
 			} else {
 				// Draw outline only (ring between innerR and r)
 				if dist2 <= r2 && dist2 >= innerR2 {
-					output.Set(x, y, col)
+					blendPixel(output, x, y, col)
 				}
 			}
 		}
@@ -439,7 +476,7 @@ func (ic *ImageCanvas) drawLine(output *image.RGBA, x1, y1, x2, y2 int, col colo
 			for s := -thickness / 2; s <= thickness/2; s++ {
 				px, py := x1+s, y1+t
 				if px >= bounds.Min.X && px < bounds.Max.X && py >= bounds.Min.Y && py < bounds.Max.Y {
-					output.Set(px, py, col)
+					blendPixel(output, px, py, col)
 				}
 			}
 		}
@@ -470,7 +507,7 @@ func (ic *ImageCanvas) drawFillPattern(output *image.RGBA, x1, y1, x2, y2 int, c
 		for y := y1; y <= y2; y++ {
 			for x := x1; x <= x2; x++ {
 				if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					output.Set(x, y, col)
+					blendPixel(output, x, y, col)
 				}
 			}
 		}
@@ -486,7 +523,7 @@ func (ic *ImageCanvas) drawFillPattern(output *image.RGBA, x1, y1, x2, y2 int, c
 			for x := x1; x <= x2; x++ {
 				if ((x + y) % interval) < lineWidth {
 					if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-						output.Set(x, y, col)
+						blendPixel(output, x, y, col)
 					}
 				}
 			}
@@ -505,7 +542,7 @@ func (ic *ImageCanvas) drawFillPattern(output *image.RGBA, x1, y1, x2, y2 int, c
 				stripe2 := ((x - y + 10000*interval) % interval) < lineWidth // +10000*interval to keep positive
 				if stripe1 || stripe2 {
 					if x >= bounds.Min.X && x < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-						output.Set(x, y, col)
+						blendPixel(output, x, y, col)
 					}
 				}
 			}
@@ -525,7 +562,7 @@ func (ic *ImageCanvas) drawFillPattern(output *image.RGBA, x1, y1, x2, y2 int, c
 			for t := -lineWidth / 2; t <= lineWidth/2; t++ {
 				py := centerY + t
 				if x >= bounds.Min.X && x < bounds.Max.X && py >= bounds.Min.Y && py < bounds.Max.Y {
-					output.Set(x, py, col)
+					blendPixel(output, x, py, col)
 				}
 			}
 		}
@@ -535,7 +572,7 @@ func (ic *ImageCanvas) drawFillPattern(output *image.RGBA, x1, y1, x2, y2 int, c
 			for t := -lineWidth / 2; t <= lineWidth/2; t++ {
 				px := centerX + t
 				if px >= bounds.Min.X && px < bounds.Max.X && y >= bounds.Min.Y && y < bounds.Max.Y {
-					output.Set(px, y, col)
+					blendPixel(output, px, y, col)
 				}
 			}
 		}
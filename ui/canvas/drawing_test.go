@@ -0,0 +1,28 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBlendPixel(t *testing.T) {
+	cases := []struct {
+		name string
+		col  color.RGBA
+		want color.RGBA
+	}{
+		{"opaque replaces", color.RGBA{255, 0, 255, 255}, color.RGBA{255, 0, 255, 255}},
+		{"transparent leaves", color.RGBA{255, 0, 255, 0}, color.RGBA{200, 200, 200, 255}},
+		{"faded blends", color.RGBA{255, 0, 255, 63}, color.RGBA{213, 150, 213, 255}},
+		{"half blends", color.RGBA{0, 0, 0, 128}, color.RGBA{99, 99, 99, 255}},
+	}
+	for _, c := range cases {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		img.SetRGBA(1, 1, color.RGBA{200, 200, 200, 255})
+		blendPixel(img, 1, 1, c.col)
+		if got := img.RGBAAt(1, 1); got != c.want {
+			t.Errorf("%s: %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package canvas
+
+// MarkerKind identifies a class of point marker drawn in overlays. Marker
+// sizes are physical (inches on the board) so they look the same relative to
+// the board at any scan DPI; see MarkerSize.
+type MarkerKind int
+
+const (
+	MarkerTraceVertex MarkerKind = iota // Trace vertex dot (radius)
+	MarkerViaSideDot                    // Dot on vias traced on the other side (radius)
+	MarkerEjector                       // Ejector target square (side length)
+)
+
+// markerInches is the size of each marker kind at marker scale 1.0.
+var markerInches = map[MarkerKind]float64{
+	MarkerTraceVertex: 0.010, // 6px radius at 600 DPI
+	MarkerViaSideDot:  0.005,
+	MarkerEjector:     0.250,
+}
+
+// fallbackMarkerDPI is assumed when the image DPI is unknown.
+const fallbackMarkerDPI = 600.0
+
+// MarkerPixels returns the size in image pixels of a marker of the given
+// kind on a scan of the given DPI, multiplied by scale. The result is at
+// least 1 pixel.
+func MarkerPixels(kind MarkerKind, dpi, scale float64) float64 {
+	if dpi <= 0 {
+		dpi = fallbackMarkerDPI
+	}
+	if scale <= 0 {
+		scale = 1.0
+	}
+	px := markerInches[kind] * dpi * scale
+	if px < 1 {
+		px = 1
+	}
+	return px
+}
+
+// SetMarkerStyle sets the base marker scale (1.0 = default size) and whether
+// markers keep a constant on-screen size regardless of zoom. Overlays must be
+// rebuilt for a scale change to take effect.
+func (ic *ImageCanvas) SetMarkerStyle(scale float64, screenConstant bool) {
+	if scale <= 0 {
+		scale = 1.0
+	}
+	ic.markerScale = scale
+	ic.markerScreenConstant = screenConstant
+	ic.drawArea.QueueDraw()
+}
+
+// MarkerSize returns the image-pixel size of a marker of the given kind at
+// the canvas's marker scale.
+func (ic *ImageCanvas) MarkerSize(kind MarkerKind, dpi float64) float64 {
+	return MarkerPixels(kind, dpi, ic.markerScale)
+}
+
+// markerZoom is the factor applied to marker sizes when drawing: the zoom
+// level normally, or 1 when markers keep a constant screen size (they then
+// appear as they would at 100% zoom).
+func (ic *ImageCanvas) markerZoom() float64 {
+	if ic.markerScreenConstant {
+		return 1.0
+	}
+	return ic.zoom
+}
@@ -0,0 +1,70 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestMarkerPixels(t *testing.T) {
+	cases := []struct {
+		kind       MarkerKind
+		dpi, scale float64
+		want       float64
+	}{
+		{MarkerTraceVertex, 600, 1, 6},
+		{MarkerTraceVertex, 1200, 1, 12}, // Twice the DPI, twice the pixels
+		{MarkerTraceVertex, 300, 1, 3},
+		{MarkerTraceVertex, 600, 2, 12},
+		{MarkerTraceVertex, 0, 1, 6},    // Unknown DPI assumes 600
+		{MarkerTraceVertex, 600, 0, 6},  // Unset scale is 1
+		{MarkerViaSideDot, 100, 1, 1},   // 0.5 px, raised to the 1 px minimum
+		{MarkerEjector, 600, 1, 150},    // 0.25"
+		{MarkerEjector, 2400, 0.5, 300}, // Quarter inch at half scale
+	}
+	for _, c := range cases {
+		if got := MarkerPixels(c.kind, c.dpi, c.scale); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("MarkerPixels(%d, %v, %v) = %v, want %v", c.kind, c.dpi, c.scale, got, c.want)
+		}
+	}
+}
+
+// TestMarkerDrawnSize draws a vertex dot on a blank image and measures it:
+// it scales with the scan DPI, and with zoom unless markers keep a
+// constant screen size.
+func TestMarkerDrawnSize(t *testing.T) {
+	// width counts the painted pixels on row y
+	width := func(img *image.RGBA, y int) int {
+		n := 0
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			if img.RGBAAt(x, y).A != 0 {
+				n++
+			}
+		}
+		return n
+	}
+	cases := []struct {
+		dpi, zoom      float64
+		screenConstant bool
+		want           int // Painted diameter in screen pixels
+	}{
+		{600, 1, false, 13},  // Radius 6
+		{1200, 1, false, 25}, // Radius 12
+		{1200, 0.5, false, 13},
+		{1200, 0.5, true, 25},
+		{600, 2, true, 13},
+	}
+	for _, c := range cases {
+		ic := &ImageCanvas{zoom: c.zoom, markerScale: 1, markerScreenConstant: c.screenConstant}
+		r := ic.MarkerSize(MarkerTraceVertex, c.dpi)
+		o := &Overlay{Color: color.RGBA{255, 0, 0, 255}, Circles: []OverlayCircle{
+			{X: 100 / c.zoom, Y: 100 / c.zoom, Radius: r, Filled: true, Marker: true},
+		}}
+		img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+		ic.drawOverlay(img, o)
+		if got := width(img, 100); got != c.want {
+			t.Errorf("%v DPI at zoom %v (constant %v): dot %d px wide, want %d", c.dpi, c.zoom, c.screenConstant, got, c.want)
+		}
+	}
+}
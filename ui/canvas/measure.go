@@ -0,0 +1,122 @@
+package canvas
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// Measurement is the length and direction of a ruler drawn on the canvas.
+// Inches and Millimeters are zero when the DPI is unknown.
+type Measurement struct {
+	Pixels      float64
+	Inches      float64
+	Millimeters float64
+	AngleDeg    float64 // Screen angle from +X, counterclockwise (Y up), in (-180, 180]
+}
+
+// NewMeasurement measures from p1 to p2 in image coordinates at the given DPI.
+func NewMeasurement(p1, p2 geometry.Point2D, dpi float64) Measurement {
+	m := Measurement{
+		Pixels:   p1.Distance(p2),
+		AngleDeg: math.Atan2(p1.Y-p2.Y, p2.X-p1.X) * 180 / math.Pi,
+	}
+	if dpi > 0 {
+		m.Inches = m.Pixels / dpi
+		m.Millimeters = m.Inches * 25.4
+	}
+	return m
+}
+
+// String formats the measurement as e.g.
+// "123.4 px  0.412 in (412 mil)  10.46 mm  12.3°".
+func (m Measurement) String() string {
+	if m.Inches == 0 && m.Pixels > 0 {
+		return fmt.Sprintf("%.1f px  %.1f° (DPI unknown)", m.Pixels, m.AngleDeg)
+	}
+	return fmt.Sprintf("%.1f px  %.3f in (%.0f mil)  %.2f mm  %.1f°",
+		m.Pixels, m.Inches, m.Inches*1000, m.Millimeters, m.AngleDeg)
+}
+
+// label returns the short text drawn on the ruler itself.
+func (m Measurement) label() string {
+	if m.Inches == 0 {
+		return fmt.Sprintf("%.1f px", m.Pixels)
+	}
+	return fmt.Sprintf("%.0f mil %.2f mm", m.Inches*1000, m.Millimeters)
+}
+
+// EnableMeasureMode turns left-drag into a ruler until DisableMeasureMode is
+// called. The live length is drawn while dragging and onMeasure is called
+// with the endpoints (image coordinates) on release. Lengths are converted
+// with the DPI passed to SetDPI.
+func (ic *ImageCanvas) EnableMeasureMode(onMeasure func(p1, p2 geometry.Point2D, dist Measurement)) {
+	ic.measureMode = true
+	ic.measuring = false
+	ic.onMeasure = onMeasure
+	ic.selectMode = false
+}
+
+// DisableMeasureMode ends measure mode and removes the ruler.
+func (ic *ImageCanvas) DisableMeasureMode() {
+	ic.measureMode = false
+	ic.measuring = false
+	ic.measureShown = false
+	ic.onMeasure = nil
+	ic.drawArea.QueueDraw()
+}
+
+// drawMeasurement draws the ruler from measureStart to measureEnd: the line,
+// end stops, a tick every millimeter (every 5 mm when that would crowd the
+// line, longer every 5 and 10 mm), and the length near the midpoint.
+func (ic *ImageCanvas) drawMeasurement(output *image.RGBA) {
+	rulerColor := color.RGBA{R: 255, G: 255, B: 0, A: 255}
+	p1 := geometry.Point2D{X: ic.measureStart.X * ic.zoom, Y: ic.measureStart.Y * ic.zoom}
+	p2 := geometry.Point2D{X: ic.measureEnd.X * ic.zoom, Y: ic.measureEnd.Y * ic.zoom}
+	length := p1.Distance(p2)
+
+	ic.drawLine(output, int(p1.X), int(p1.Y), int(p2.X), int(p2.Y), rulerColor, 1)
+	if length < 1 {
+		return
+	}
+
+	// Unit vectors along and across the ruler
+	ux, uy := (p2.X-p1.X)/length, (p2.Y-p1.Y)/length
+	nx, ny := -uy, ux
+	tick := func(p geometry.Point2D, half float64) {
+		ic.drawLine(output,
+			int(p.X-nx*half), int(p.Y-ny*half), int(p.X+nx*half), int(p.Y+ny*half),
+			rulerColor, 1)
+	}
+	tick(p1, 8)
+	tick(p2, 8)
+
+	if ic.gridDPI > 0 {
+		mm := ic.gridDPI / 25.4 * ic.zoom // Screen pixels per millimeter
+		step := 1
+		if mm < 4 {
+			step = 5
+		}
+		if mm*float64(step) >= 4 {
+			for i := step; float64(i)*mm < length; i += step {
+				half := 2.0
+				switch {
+				case i%10 == 0:
+					half = 6
+				case i%5 == 0:
+					half = 4
+				}
+				d := float64(i) * mm
+				tick(geometry.Point2D{X: p1.X + ux*d, Y: p1.Y + uy*d}, half)
+			}
+		}
+	}
+
+	m := NewMeasurement(ic.measureStart, ic.measureEnd, ic.gridDPI)
+	mid := geometry.Point2D{X: (p1.X + p2.X) / 2, Y: (p1.Y + p2.Y) / 2}
+	lx, ly := int(mid.X+nx*16), int(mid.Y+ny*16)
+	ic.drawLabel(output, m.label(), lx, ly, lx, ly, rulerColor)
+}
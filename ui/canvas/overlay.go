@@ -34,6 +34,10 @@ type OverlayCircle struct {
 	Filled bool    // If true, fill the circle; otherwise just outline
 	Color  *color.RGBA // Per-circle color override (nil = use overlay Color)
 	Label  string      // Optional label drawn to the right of the circle
+
+	// Marker circles follow the canvas marker style: with constant screen
+	// size enabled their radius is not scaled by zoom.
+	Marker bool
 }
 
 // FillPattern indicates how to fill a rectangle.
@@ -55,6 +59,10 @@ type OverlayRect struct {
 	Fill                FillPattern // Fill pattern for the rectangle
 	StripeInterval      int         // Interval for stripe/crosshatch patterns (0 = use width)
 	Color               *color.RGBA // Per-rect color override (nil = use overlay Color)
+
+	// Marker rects follow the canvas marker style: with constant screen size
+	// enabled their size is not scaled by zoom (they stay centered).
+	Marker bool
 }
 
 // OverlayPolygon represents a polygon to draw on the overlay.
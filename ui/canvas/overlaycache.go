@@ -0,0 +1,168 @@
+package canvas
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+)
+
+// Overlays are rasterized once into a sparse off-screen form and blitted on
+// every redraw, so scrolling, rubber-banding and other redraws that leave the
+// overlays alone don't re-rasterize thousands of shapes. A cached raster is
+// rebuilt when its overlay is replaced or grows/shrinks, or when zoom,
+// marker style, the layer offset or the canvas size changes.
+
+// overlaySpan is a horizontal run of overlay pixels starting at (x, y),
+// stored as pix[off:off+n] of its raster in premultiplied RGBA.
+type overlaySpan struct {
+	x, y   int
+	off, n int
+}
+
+// overlayRasterKey captures everything a cached raster depends on.
+type overlayRasterKey struct {
+	bounds           image.Rectangle
+	zoom, markerZoom float64
+	offsetX, offsetY float64
+	color            color.RGBA
+
+	// Shape fingerprint: catches slices being rebuilt, appended to or filtered
+	rects  *OverlayRect
+	polys  *OverlayPolygon
+	circs  *OverlayCircle
+	lines  *OverlayLine
+	counts [4]int
+}
+
+// overlayRaster is the cached rasterization of one overlay.
+type overlayRaster struct {
+	key    overlayRasterKey
+	spans  []overlaySpan
+	pix    []uint8
+	labels []pendingLabel
+}
+
+// firstElem returns a pointer to s[0], or nil for an empty slice.
+func firstElem[T any](s []T) *T {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s[0]
+}
+
+// overlayRasterKey returns the cache key for drawing overlay into bounds.
+func (ic *ImageCanvas) overlayRasterKey(overlay *Overlay, bounds image.Rectangle) overlayRasterKey {
+	offsetX, offsetY := ic.overlayOffset(overlay)
+	return overlayRasterKey{
+		bounds:     bounds,
+		zoom:       ic.zoom,
+		markerZoom: ic.markerZoom(),
+		offsetX:    offsetX,
+		offsetY:    offsetY,
+		color:      overlay.Color,
+		rects:      firstElem(overlay.Rectangles),
+		polys:      firstElem(overlay.Polygons),
+		circs:      firstElem(overlay.Circles),
+		lines:      firstElem(overlay.Lines),
+		counts: [4]int{len(overlay.Rectangles), len(overlay.Polygons),
+			len(overlay.Circles), len(overlay.Lines)},
+	}
+}
+
+// drawOverlayCached draws overlay onto output from its cached raster,
+// rasterizing it first if the cache is missing or stale, and queues the
+// overlay's labels exactly as drawOverlay would.
+func (ic *ImageCanvas) drawOverlayCached(output *image.RGBA, overlay *Overlay) {
+	key := ic.overlayRasterKey(overlay, output.Bounds())
+	r := ic.overlayRasters[overlay]
+	if r == nil || r.key != key {
+		r = ic.rasterizeOverlay(overlay, output.Bounds())
+		r.key = key
+		if ic.overlayRasters == nil {
+			ic.overlayRasters = make(map[*Overlay]*overlayRaster)
+		}
+		ic.overlayRasters[overlay] = r
+	}
+	r.blit(output)
+	ic.pendingLabels = append(ic.pendingLabels, r.labels...)
+}
+
+// rasterizeOverlay draws overlay onto a transparent scratch image and
+// extracts the touched pixels as spans, leaving the scratch clear again.
+func (ic *ImageCanvas) rasterizeOverlay(overlay *Overlay, bounds image.Rectangle) *overlayRaster {
+	if ic.overlayScratch == nil || ic.overlayScratch.Bounds() != bounds {
+		ic.overlayScratch = image.NewRGBA(bounds)
+	}
+	scratch := ic.overlayScratch
+
+	nLabels := len(ic.pendingLabels)
+	ic.drawOverlay(scratch, overlay)
+	r := &overlayRaster{
+		labels: append([]pendingLabel(nil), ic.pendingLabels[nLabels:]...),
+	}
+	ic.pendingLabels = ic.pendingLabels[:nLabels]
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		off := scratch.PixOffset(bounds.Min.X, y)
+		row := scratch.Pix[off : off+bounds.Dx()*4]
+		for i := 0; i < len(row); {
+			// Skip empty pixel pairs a word at a time
+			if i+8 <= len(row) && binary.LittleEndian.Uint64(row[i:]) == 0 {
+				i += 8
+				continue
+			}
+			if row[i+3] == 0 {
+				i += 4
+				continue
+			}
+			end := i + 4
+			for end < len(row) && row[end+3] != 0 {
+				end += 4
+			}
+			r.spans = append(r.spans, overlaySpan{
+				x: bounds.Min.X + i/4, y: y,
+				off: len(r.pix), n: end - i,
+			})
+			r.pix = append(r.pix, row[i:end]...)
+			clear(row[i:end])
+			i = end
+		}
+	}
+	return r
+}
+
+// blit composites the cached spans over output (premultiplied "over", the
+// same blend drawOverlay applies pixel by pixel).
+func (r *overlayRaster) blit(output *image.RGBA) {
+	for _, span := range r.spans {
+		src := r.pix[span.off : span.off+span.n]
+		dst := output.Pix[output.PixOffset(span.x, span.y):]
+		for i := 0; i < len(src); i += 4 {
+			a := uint32(src[i+3])
+			if a == 255 {
+				copy(dst[i:i+4], src[i:i+4])
+				continue
+			}
+			inv := 255 - a
+			dst[i+0] = src[i+0] + uint8(uint32(dst[i+0])*inv/255)
+			dst[i+1] = src[i+1] + uint8(uint32(dst[i+1])*inv/255)
+			dst[i+2] = src[i+2] + uint8(uint32(dst[i+2])*inv/255)
+			dst[i+3] = uint8(a + uint32(dst[i+3])*inv/255)
+		}
+	}
+}
+
+// invalidateOverlayRaster drops the cached raster for overlay.
+func (ic *ImageCanvas) invalidateOverlayRaster(overlay *Overlay) {
+	if overlay != nil {
+		delete(ic.overlayRasters, overlay)
+	}
+}
+
+// InvalidateOverlays drops all cached overlay rasters. Call it after
+// modifying an overlay's shapes in place without changing how many there
+// are (SetOverlay with a new overlay needs no extra call).
+func (ic *ImageCanvas) InvalidateOverlays() {
+	ic.overlayRasters = nil
+	ic.Refresh()
+}
@@ -0,0 +1,121 @@
+package canvas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// heavyOverlay returns an overlay of n via circles in a grid over a w×h
+// image, a third of them translucent, plus a few labeled rectangles.
+func heavyOverlay(n, w, h int) *Overlay {
+	o := &Overlay{Color: color.RGBA{0, 255, 255, 255}}
+	translucent := &color.RGBA{255, 0, 255, 96}
+	cols := 100
+	for i := 0; i < n; i++ {
+		c := OverlayCircle{
+			X:      float64(w) * (float64(i%cols) + 0.5) / float64(cols),
+			Y:      float64(h) * (float64(i/cols) + 0.5) / float64((n+cols-1)/cols),
+			Radius: 12,
+			Filled: i%2 == 0,
+		}
+		if i%3 == 0 {
+			c.Color = translucent
+		}
+		o.Circles = append(o.Circles, c)
+	}
+	for i := 0; i < 10; i++ {
+		o.Rectangles = append(o.Rectangles, OverlayRect{
+			X: 100 + i*200, Y: 100, Width: 60, Height: 180, Fill: FillSolid, Label: "P1",
+		})
+	}
+	return o
+}
+
+// TestOverlayCacheMatchesDirect checks that blitting the cached raster
+// gives the same picture as drawing the overlay directly, up to rounding
+// where translucent shapes overlap.
+func TestOverlayCacheMatchesDirect(t *testing.T) {
+	const w, h = 1200, 800
+	ic := &ImageCanvas{zoom: 0.5}
+	o := heavyOverlay(600, w*2, h*2)
+	bg := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range bg.Pix {
+		bg.Pix[i] = uint8(i * 7)
+	}
+
+	direct := image.NewRGBA(bg.Bounds())
+	copy(direct.Pix, bg.Pix)
+	ic.drawOverlay(direct, o)
+	directLabels := len(ic.pendingLabels)
+	ic.pendingLabels = nil
+
+	for pass := 0; pass < 2; pass++ { // Rasterize, then blit from cache
+		cached := image.NewRGBA(bg.Bounds())
+		copy(cached.Pix, bg.Pix)
+		ic.drawOverlayCached(cached, o)
+		if len(ic.pendingLabels) != directLabels {
+			t.Errorf("pass %d: %d labels, want %d", pass, len(ic.pendingLabels), directLabels)
+		}
+		ic.pendingLabels = nil
+
+		maxDiff := 0
+		for i := range direct.Pix {
+			d := int(direct.Pix[i]) - int(cached.Pix[i])
+			maxDiff = max(maxDiff, d, -d)
+		}
+		if maxDiff > 2 {
+			t.Errorf("pass %d: cached output differs by up to %d/255", pass, maxDiff)
+		}
+	}
+	if len(ic.overlayRasters) != 1 {
+		t.Errorf("%d cached rasters, want 1", len(ic.overlayRasters))
+	}
+
+	// A zoom change or a new shape rebuilds the raster
+	r := ic.overlayRasters[o]
+	ic.zoom = 0.25
+	ic.drawOverlayCached(image.NewRGBA(bg.Bounds()), o)
+	if ic.overlayRasters[o] == r {
+		t.Error("zoom change kept the stale raster")
+	}
+	r = ic.overlayRasters[o]
+	o.Circles = append(o.Circles, OverlayCircle{X: 10, Y: 10, Radius: 5})
+	ic.drawOverlayCached(image.NewRGBA(bg.Bounds()), o)
+	if ic.overlayRasters[o] == r {
+		t.Error("added circle kept the stale raster")
+	}
+}
+
+// BenchmarkOverlayRedraw compares drawing a heavy overlay directly, as every
+// redraw did before the cache, with blitting its cached raster.
+func BenchmarkOverlayRedraw(b *testing.B) {
+	const w, h = 3000, 2000
+	o := heavyOverlay(6000, w, h)
+	output := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	b.Run("direct", func(b *testing.B) {
+		ic := &ImageCanvas{zoom: 1}
+		for i := 0; i < b.N; i++ {
+			ic.drawOverlay(output, o)
+			ic.pendingLabels = ic.pendingLabels[:0]
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		ic := &ImageCanvas{zoom: 1}
+		ic.drawOverlayCached(output, o)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ic.drawOverlayCached(output, o)
+			ic.pendingLabels = ic.pendingLabels[:0]
+		}
+	})
+	b.Run("rebuild", func(b *testing.B) {
+		ic := &ImageCanvas{zoom: 1}
+		for i := 0; i < b.N; i++ {
+			ic.invalidateOverlayRaster(o)
+			ic.drawOverlayCached(output, o)
+			ic.pendingLabels = ic.pendingLabels[:0]
+		}
+	})
+}
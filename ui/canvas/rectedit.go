@@ -0,0 +1,124 @@
+package canvas
+
+import (
+	"image"
+
+	"pcb-tracer/pkg/geometry"
+)
+
+// RectEdges is a bitmask of rectangle edges grabbed by a drag.
+type RectEdges int
+
+const (
+	EdgeLeft RectEdges = 1 << iota
+	EdgeTop
+	EdgeRight
+	EdgeBottom
+
+	// EdgesAll moves the whole rectangle (drag started inside it).
+	EdgesAll = EdgeLeft | EdgeTop | EdgeRight | EdgeBottom
+)
+
+// rectEdit holds the state of an editable rectangle on the canvas.
+type rectEdit struct {
+	rect     OverlayRect
+	clamp    image.Rectangle
+	minSize  int
+	onChange func(OverlayRect)
+
+	dragging bool
+	edges    RectEdges
+	start    geometry.Point2D
+	orig     OverlayRect
+}
+
+// HitTestRectEdges returns the edges of r within tol of (x, y). A corner
+// yields two edges; a point inside r away from all edges yields EdgesAll;
+// a point outside yields 0.
+func HitTestRectEdges(r OverlayRect, x, y, tol float64) RectEdges {
+	x1, y1 := float64(r.X), float64(r.Y)
+	x2, y2 := float64(r.X+r.Width), float64(r.Y+r.Height)
+	if x < x1-tol || x > x2+tol || y < y1-tol || y > y2+tol {
+		return 0
+	}
+
+	var edges RectEdges
+	if x <= x1+tol && x >= x1-tol {
+		edges |= EdgeLeft
+	} else if x >= x2-tol && x <= x2+tol {
+		edges |= EdgeRight
+	}
+	if y <= y1+tol && y >= y1-tol {
+		edges |= EdgeTop
+	} else if y >= y2-tol && y <= y2+tol {
+		edges |= EdgeBottom
+	}
+	if edges == 0 {
+		return EdgesAll
+	}
+	return edges
+}
+
+// DragRectEdges returns r with the given edges moved by (dx, dy). Moved edges
+// stay within clamp and never bring the width or height below minSize;
+// EdgesAll translates the rectangle, keeping it inside clamp.
+func DragRectEdges(r OverlayRect, edges RectEdges, dx, dy int, clamp image.Rectangle, minSize int) OverlayRect {
+	if edges == EdgesAll {
+		r.X = clampInt(r.X+dx, clamp.Min.X, clamp.Max.X-r.Width)
+		r.Y = clampInt(r.Y+dy, clamp.Min.Y, clamp.Max.Y-r.Height)
+		return r
+	}
+
+	x1, y1 := r.X, r.Y
+	x2, y2 := r.X+r.Width, r.Y+r.Height
+	if edges&EdgeLeft != 0 {
+		x1 = clampInt(x1+dx, clamp.Min.X, x2-minSize)
+	}
+	if edges&EdgeRight != 0 {
+		x2 = clampInt(x2+dx, x1+minSize, clamp.Max.X)
+	}
+	if edges&EdgeTop != 0 {
+		y1 = clampInt(y1+dy, clamp.Min.Y, y2-minSize)
+	}
+	if edges&EdgeBottom != 0 {
+		y2 = clampInt(y2+dy, y1+minSize, clamp.Max.Y)
+	}
+	r.X, r.Y = x1, y1
+	r.Width, r.Height = x2-x1, y2-y1
+	return r
+}
+
+// clampInt limits v to [lo, hi]; lo wins if the range is empty.
+func clampInt(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
+
+// EditRect makes rect (image coordinates) draggable: grabbing an edge or
+// corner resizes it, grabbing the interior moves it. onChange is called on
+// every drag step with the updated rectangle. The canvas does not draw the
+// rectangle; callers show it with an overlay.
+func (ic *ImageCanvas) EditRect(rect OverlayRect, clamp image.Rectangle, minSize int, onChange func(OverlayRect)) {
+	ic.rectEdit = &rectEdit{
+		rect:     rect,
+		clamp:    clamp,
+		minSize:  minSize,
+		onChange: onChange,
+	}
+}
+
+// StopEditRect ends rectangle editing started with EditRect.
+func (ic *ImageCanvas) StopEditRect() {
+	ic.rectEdit = nil
+	ic.leftDragging = false
+}
+
+// IsEditingRect returns true while EditRect is active.
+func (ic *ImageCanvas) IsEditingRect() bool {
+	return ic.rectEdit != nil
+}
@@ -0,0 +1,61 @@
+package canvas
+
+import (
+	"image"
+	"testing"
+)
+
+func TestHitTestRectEdges(t *testing.T) {
+	r := OverlayRect{X: 100, Y: 50, Width: 200, Height: 100}
+	for _, c := range []struct {
+		name string
+		x, y float64
+		want RectEdges
+	}{
+		{"left edge", 98, 100, EdgeLeft},
+		{"right edge", 304, 100, EdgeRight},
+		{"top edge", 200, 50, EdgeTop},
+		{"bottom edge", 200, 146, EdgeBottom},
+		{"top-left corner", 103, 47, EdgeLeft | EdgeTop},
+		{"bottom-right corner", 300, 150, EdgeRight | EdgeBottom},
+		{"interior", 200, 100, EdgesAll},
+		{"outside", 90, 100, 0},
+		{"outside below", 200, 160, 0},
+	} {
+		if got := HitTestRectEdges(r, c.x, c.y, 5); got != c.want {
+			t.Errorf("%s: edges %b, want %b", c.name, got, c.want)
+		}
+	}
+}
+
+// TestDragRectEdges drags a crop rectangle inside a 1000×800 image with a
+// 50 px minimum size.
+func TestDragRectEdges(t *testing.T) {
+	clamp := image.Rect(0, 0, 1000, 800)
+	r := OverlayRect{X: 100, Y: 50, Width: 200, Height: 100}
+	rect := func(x, y, w, h int) OverlayRect { return OverlayRect{X: x, Y: y, Width: w, Height: h} }
+	for _, c := range []struct {
+		name   string
+		edges  RectEdges
+		dx, dy int
+		want   OverlayRect
+	}{
+		{"left out", EdgeLeft, -30, 7, rect(70, 50, 230, 100)},
+		{"right in", EdgeRight, -40, 7, rect(100, 50, 160, 100)},
+		{"top up", EdgeTop, 9, -20, rect(100, 30, 200, 120)},
+		{"bottom down", EdgeBottom, 9, 25, rect(100, 50, 200, 125)},
+		{"corner", EdgeRight | EdgeBottom, 10, 20, rect(100, 50, 210, 120)},
+		{"left past the image", EdgeLeft, -500, 0, rect(0, 50, 300, 100)},
+		{"bottom past the image", EdgeBottom, 0, 1000, rect(100, 50, 200, 750)},
+		{"left past the minimum", EdgeLeft, 400, 0, rect(250, 50, 50, 100)},
+		{"top past the minimum", EdgeTop, 0, 90, rect(100, 100, 200, 50)},
+		{"right past the left edge", EdgeRight, -300, 0, rect(100, 50, 50, 100)},
+		{"move", EdgesAll, 30, -20, rect(130, 30, 200, 100)},
+		{"move past the corner", EdgesAll, 2000, 2000, rect(800, 700, 200, 100)},
+		{"no edges", 0, 30, 30, r},
+	} {
+		if got := DragRectEdges(r, c.edges, c.dx, c.dy, clamp, 50); got != c.want {
+			t.Errorf("%s: %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
@@ -265,6 +265,8 @@ func (d *BoardSpecDialog) buildContent(box *gtk.Box) {
 		d.areaMaxEntry = newEntry("20000")
 	}
 
+	d.aspectMinEntry.SetTooltipText("Long side / short side of a contact")
+	d.aspectMaxEntry.SetTooltipText("Long side / short side of a contact")
 	addRow(detBox, "Aspect Ratio Min:", d.aspectMinEntry)
 	addRow(detBox, "Aspect Ratio Max:", d.aspectMaxEntry)
 	addRow(detBox, "Min Area (px @600dpi):", d.areaMinEntry)
@@ -335,6 +337,12 @@ func (d *BoardSpecDialog) applyChanges() {
 	if v, err := strconv.ParseFloat(getText(d.aspectMaxEntry), 64); err == nil {
 		det.AspectRatioMax = v
 	}
+	det.Color.HueMin, det.Color.HueMax = clampRange(det.Color.HueMin, det.Color.HueMax, 180)
+	det.Color.SatMin, det.Color.SatMax = clampRange(det.Color.SatMin, det.Color.SatMax, 255)
+	det.Color.ValMin, det.Color.ValMax = clampRange(det.Color.ValMin, det.Color.ValMax, 255)
+	if det.AspectRatioMin > det.AspectRatioMax {
+		det.AspectRatioMin, det.AspectRatioMax = det.AspectRatioMax, det.AspectRatioMin
+	}
 	if v, err := strconv.Atoi(getText(d.areaMinEntry)); err == nil {
 		det.MinAreaPixels = v
 	}
@@ -343,6 +351,16 @@ func (d *BoardSpecDialog) applyChanges() {
 	}
 }
 
+// clampRange limits lo and hi to 0..limit and swaps them if reversed.
+func clampRange(lo, hi, limit float64) (float64, float64) {
+	lo = math.Max(0, math.Min(lo, limit))
+	hi = math.Max(0, math.Min(hi, limit))
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return lo, hi
+}
+
 func (d *BoardSpecDialog) updateColorSwatches() {
 	getText := func(e *gtk.Entry) float64 {
 		t, _ := e.GetText()
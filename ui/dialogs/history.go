@@ -0,0 +1,243 @@
+package dialogs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"pcb-tracer/internal/project"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+)
+
+// HistoryDialog lists a project's named snapshots and lets the user take a
+// new one, compare snapshots, and revert to one.
+type HistoryDialog struct {
+	state       project.Marshaler
+	projectPath string
+	win         *gtk.Window
+
+	dlg         *gtk.Dialog
+	list        *gtk.ListBox
+	resultLabel *gtk.Label
+	snapshots   []project.SnapshotInfo // in list row order
+
+	// Called after the project file was replaced by a snapshot
+	onRevert func()
+}
+
+// NewHistoryDialog creates a history dialog for the project saved at
+// projectPath, whose live contents come from state.
+func NewHistoryDialog(state project.Marshaler, projectPath string, win *gtk.Window, onRevert func()) *HistoryDialog {
+	return &HistoryDialog{
+		state:       state,
+		projectPath: projectPath,
+		win:         win,
+		onRevert:    onRevert,
+	}
+}
+
+// Show displays the dialog.
+func (d *HistoryDialog) Show() {
+	dlg, _ := gtk.DialogNewWithButtons("Project History", d.win,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Close", gtk.RESPONSE_CLOSE})
+	dlg.SetDefaultSize(460, 420)
+	d.dlg = dlg
+
+	contentArea, _ := dlg.GetContentArea()
+	contentBox, _ := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
+	contentBox.SetMarginStart(8)
+	contentBox.SetMarginEnd(8)
+	contentBox.SetMarginTop(8)
+	contentBox.SetMarginBottom(8)
+
+	hint, _ := gtk.LabelNew("Select one snapshot to compare with the current project, or two to compare them.")
+	hint.SetLineWrap(true)
+	hint.SetHAlign(gtk.ALIGN_START)
+	contentBox.PackStart(hint, false, false, 0)
+
+	scroll, _ := gtk.ScrolledWindowNew(nil, nil)
+	scroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	scroll.SetSizeRequest(-1, 200)
+	d.list, _ = gtk.ListBoxNew()
+	d.list.SetSelectionMode(gtk.SELECTION_MULTIPLE)
+	scroll.Add(d.list)
+	contentBox.PackStart(scroll, true, true, 0)
+
+	btnRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	snapBtn, _ := gtk.ButtonNewWithLabel("Snapshot...")
+	snapBtn.Connect("clicked", func() { d.onSnapshot() })
+	compareBtn, _ := gtk.ButtonNewWithLabel("Compare")
+	compareBtn.Connect("clicked", func() { d.onCompare() })
+	revertBtn, _ := gtk.ButtonNewWithLabel("Revert")
+	revertBtn.Connect("clicked", func() { d.onRevertClicked() })
+	btnRow.PackStart(snapBtn, false, false, 0)
+	btnRow.PackStart(compareBtn, false, false, 0)
+	btnRow.PackStart(revertBtn, false, false, 0)
+	contentBox.PackStart(btnRow, false, false, 0)
+
+	d.resultLabel, _ = gtk.LabelNew("")
+	d.resultLabel.SetLineWrap(true)
+	d.resultLabel.SetSelectable(true)
+	d.resultLabel.SetHAlign(gtk.ALIGN_START)
+	d.resultLabel.SetVAlign(gtk.ALIGN_START)
+	contentBox.PackStart(d.resultLabel, false, false, 0)
+
+	contentArea.PackStart(contentBox, true, true, 0)
+	d.refreshList()
+
+	dlg.ShowAll()
+	dlg.Run()
+	dlg.Destroy()
+}
+
+// refreshList reloads the snapshot list from disk.
+func (d *HistoryDialog) refreshList() {
+	d.list.GetChildren().Foreach(func(item interface{}) {
+		if w, ok := item.(*gtk.Widget); ok {
+			d.list.Remove(w)
+		}
+	})
+
+	snaps, err := project.ListSnapshots(d.projectPath)
+	if err != nil {
+		d.showError(err)
+	}
+	d.snapshots = snaps
+	for _, s := range snaps {
+		lbl, _ := gtk.LabelNew(fmt.Sprintf("%s  —  %s", s.Created.Format("2006-01-02 15:04"), s.Name))
+		lbl.SetHAlign(gtk.ALIGN_START)
+		d.list.Add(lbl)
+	}
+	if len(snaps) == 0 {
+		d.resultLabel.SetText("No snapshots yet")
+	}
+	d.list.ShowAll()
+}
+
+// selectedSnapshots returns the selected snapshots, oldest first.
+func (d *HistoryDialog) selectedSnapshots() []project.SnapshotInfo {
+	var sel []project.SnapshotInfo
+	rows := d.list.GetSelectedRows()
+	if rows == nil {
+		return nil
+	}
+	rows.Foreach(func(item interface{}) {
+		row, ok := item.(*gtk.ListBoxRow)
+		if !ok {
+			return
+		}
+		if idx := row.GetIndex(); idx >= 0 && idx < len(d.snapshots) {
+			sel = append(sel, d.snapshots[idx])
+		}
+	})
+	sort.Slice(sel, func(i, j int) bool {
+		return sel[i].Created.Before(sel[j].Created)
+	})
+	return sel
+}
+
+func (d *HistoryDialog) showError(err error) {
+	d.resultLabel.SetMarkup(fmt.Sprintf("<span foreground='red'>%s</span>", glib.MarkupEscapeText(err.Error())))
+}
+
+// onSnapshot asks for a name and snapshots the current project.
+func (d *HistoryDialog) onSnapshot() {
+	dlg, _ := gtk.DialogNewWithButtons("Take Snapshot", &d.dlg.Window,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL},
+		[]interface{}{"OK", gtk.RESPONSE_OK})
+	contentArea, _ := dlg.GetContentArea()
+	entry, _ := gtk.EntryNew()
+	entry.SetPlaceholderText("Snapshot name")
+	entry.SetActivatesDefault(true)
+	dlg.SetDefaultResponse(gtk.RESPONSE_OK)
+	contentArea.PackStart(entry, false, false, 4)
+	dlg.ShowAll()
+
+	response := dlg.Run()
+	name, _ := entry.GetText()
+	dlg.Destroy()
+	if response != gtk.RESPONSE_OK {
+		return
+	}
+
+	snap, err := project.Snapshot(d.state, d.projectPath, name)
+	if err != nil {
+		d.showError(err)
+		return
+	}
+	d.refreshList()
+	d.resultLabel.SetText("Saved snapshot: " + snap.Name)
+}
+
+// onCompare diffs two selected snapshots, or one against the current project.
+func (d *HistoryDialog) onCompare() {
+	sel := d.selectedSnapshots()
+	switch len(sel) {
+	case 1:
+		// Compare against the live project, including unsaved changes
+		data, err := d.state.MarshalProject(d.projectPath)
+		if err != nil {
+			d.showError(err)
+			return
+		}
+		tmp, err := os.CreateTemp("", "pcbproj-current-*.pcbproj")
+		if err != nil {
+			d.showError(err)
+			return
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.Write(data)
+		tmp.Close()
+		if err != nil {
+			d.showError(err)
+			return
+		}
+		d.showDiff(sel[0].Path, tmp.Name(), sel[0].Name+" → current")
+	case 2:
+		d.showDiff(sel[0].Path, sel[1].Path, sel[0].Name+" → "+sel[1].Name)
+	default:
+		d.resultLabel.SetText("Select one or two snapshots to compare")
+	}
+}
+
+func (d *HistoryDialog) showDiff(a, b, title string) {
+	diff, err := project.Diff(a, b)
+	if err != nil {
+		d.showError(err)
+		return
+	}
+	d.resultLabel.SetText(title + "\n" + diff.String())
+}
+
+// onRevertClicked confirms and reverts the project to the selected snapshot.
+func (d *HistoryDialog) onRevertClicked() {
+	sel := d.selectedSnapshots()
+	if len(sel) != 1 {
+		d.resultLabel.SetText("Select one snapshot to revert to")
+		return
+	}
+	snap := sel[0]
+
+	confirm := gtk.MessageDialogNew(&d.dlg.Window, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_OK_CANCEL,
+		"Revert the project to \"%s\"? The current state is kept as a snapshot first.", snap.Name)
+	response := confirm.Run()
+	confirm.Destroy()
+	if response != gtk.RESPONSE_OK {
+		return
+	}
+
+	backup, err := project.Revert(d.state, d.projectPath, snap)
+	if err != nil {
+		d.showError(err)
+		return
+	}
+	if d.onRevert != nil {
+		d.onRevert()
+	}
+	d.refreshList()
+	d.resultLabel.SetText(fmt.Sprintf("Reverted to %s (previous state saved as \"%s\")", snap.Name, backup.Name))
+}
@@ -3,15 +3,25 @@ package mainwindow
 
 import (
 	"fmt"
+	"image"
+	"image/draw"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"pcb-tracer/internal/alignment"
 	"pcb-tracer/internal/app"
 	"pcb-tracer/internal/board"
+	"pcb-tracer/internal/component"
+	"pcb-tracer/internal/datecode"
+	pcbimage "pcb-tracer/internal/image"
 	"pcb-tracer/internal/netlist"
 	"pcb-tracer/internal/version"
+	"pcb-tracer/pkg/geometry"
 	"pcb-tracer/ui/canvas"
+	"pcb-tracer/ui/dialogs"
 	"pcb-tracer/ui/panels"
 	"pcb-tracer/ui/prefs"
 	"pcb-tracer/ui/schematic"
@@ -275,9 +285,32 @@ func (mw *MainWindow) createToolbar() *gtk.Box {
 	})
 	hbox.PackStart(mw.checkerboardCheck, false, false, 0)
 
+	// Ruler
+	measureBtn, _ := gtk.ToggleButtonNewWithLabel("Measure")
+	measureBtn.SetTooltipText("Drag on the image to measure a distance")
+	measureBtn.Connect("toggled", func() {
+		mw.onToggleMeasure(measureBtn.GetActive())
+	})
+	hbox.PackStart(measureBtn, false, false, 0)
+
 	return hbox
 }
 
+// onToggleMeasure turns the canvas ruler on or off, reporting each
+// measurement in the status bar.
+func (mw *MainWindow) onToggleMeasure(active bool) {
+	if !active {
+		mw.canvas.DisableMeasureMode()
+		mw.updateStatus("Ready")
+		return
+	}
+	mw.canvas.EnableMeasureMode(func(p1, p2 geometry.Point2D, dist canvas.Measurement) {
+		mw.updateStatus(fmt.Sprintf("Measure (%.0f, %.0f) → (%.0f, %.0f): %s",
+			p1.X, p1.Y, p2.X, p2.Y, dist))
+	})
+	mw.updateStatus("Measure: drag on the image")
+}
+
 // setupMenus creates the application menus.
 func (mw *MainWindow) setupMenus() {
 	menuBar, _ := gtk.MenuBarNew()
@@ -289,8 +322,11 @@ func (mw *MainWindow) setupMenus() {
 		menuEntry{}, // separator
 		menuEntry{"Save Project", mw.onSaveProject},
 		menuEntry{"Save Project As...", mw.onSaveProjectAs},
+		menuEntry{"History...", mw.onProjectHistory},
 		menuEntry{}, // separator
 		menuEntry{"Export Netlist...", mw.onExportNetlist},
+		menuEntry{"Export Overlay Layers...", mw.onExportOverlayLayers},
+		menuEntry{"Export Annotated Image...", mw.onExportAnnotatedImage},
 		menuEntry{"Open Schematic...", mw.onGenerateSchematic},
 		menuEntry{}, // separator
 		menuEntry{"Quit", func() { mw.win.Close() }},
@@ -367,6 +403,13 @@ func (mw *MainWindow) setupMenus() {
 	}
 	menuBar.Append(boardMenuItem)
 
+	// Components menu
+	componentsMenu := mw.createMenu("Components",
+		menuEntry{"Assign Grid IDs...", mw.onAssignGridIDs},
+		menuEntry{"Board Era...", mw.onBoardEra},
+	)
+	menuBar.Append(componentsMenu)
+
 	// Help menu
 	helpMenu := mw.createMenu("Help",
 		menuEntry{"About", mw.onAbout},
@@ -719,6 +762,24 @@ func (mw *MainWindow) onOpenProject() {
 	mw.syncLayers()
 }
 
+// onProjectHistory opens the snapshot history of the saved project.
+func (mw *MainWindow) onProjectHistory() {
+	if mw.state.ProjectPath == "" {
+		mw.showError("Save the project before taking snapshots")
+		return
+	}
+	path := mw.state.ProjectPath
+	dialogs.NewHistoryDialog(mw.state, path, mw.win, func() {
+		mw.canvas.ClearAllOverlays()
+		mw.canvas.ClearConnectorLabels()
+		if err := mw.state.LoadProject(path); err != nil {
+			mw.showError("Failed to load project: " + err.Error())
+			return
+		}
+		mw.syncLayers()
+	}).Show()
+}
+
 func (mw *MainWindow) snapshotViewport() {
 	mw.state.ViewZoom = mw.canvas.GetZoom()
 	mw.state.ViewScrollX, mw.state.ViewScrollY = mw.canvas.ScrollOffset()
@@ -731,6 +792,9 @@ func (mw *MainWindow) onSaveProject() {
 		mw.onSaveProjectAs()
 		return
 	}
+	if !mw.confirmDuplicateIDs() {
+		return
+	}
 	mw.snapshotViewport()
 	mw.sidePanel.SavePreferences()
 	if err := mw.state.SaveProject(mw.state.ProjectPath); err != nil {
@@ -773,6 +837,9 @@ func (mw *MainWindow) onSaveProjectAs() {
 	}
 	mw.prefs.SetString(prefKeyLastDir, filepath.Dir(path))
 
+	if !mw.confirmDuplicateIDs() {
+		return
+	}
 	mw.snapshotViewport()
 	mw.sidePanel.SavePreferences()
 	if err := mw.state.SaveProject(path); err != nil {
@@ -783,6 +850,32 @@ func (mw *MainWindow) onSaveProjectAs() {
 	mw.prefs.Save()
 }
 
+// confirmDuplicateIDs warns when components share an ID and asks whether to
+// save anyway. Returns true if saving should proceed.
+func (mw *MainWindow) confirmDuplicateIDs() bool {
+	dups := component.FindDuplicateIDs(mw.state.Components)
+	if len(dups) == 0 {
+		return true
+	}
+	ids := make([]string, 0, len(dups))
+	for id := range dups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	dlg := gtk.MessageDialogNew(mw.win, gtk.DIALOG_MODAL,
+		gtk.MESSAGE_WARNING, gtk.BUTTONS_NONE,
+		"Duplicate component IDs: %s\n\nBOM export and pin assignment will be ambiguous. "+
+			"Use Renumber in the Components panel to fix them.\n\nSave anyway?",
+		strings.Join(ids, ", "))
+	dlg.AddButton("Cancel", gtk.RESPONSE_CANCEL)
+	dlg.AddButton("Save Anyway", gtk.RESPONSE_ACCEPT)
+	dlg.SetDefaultResponse(gtk.RESPONSE_CANCEL)
+	response := dlg.Run()
+	dlg.Destroy()
+	return response == gtk.RESPONSE_ACCEPT
+}
+
 func (mw *MainWindow) onExportNetlist() {
 	nets := mw.state.FeaturesLayer.GetNets()
 	if len(nets) == 0 {
@@ -836,6 +929,132 @@ func (mw *MainWindow) onExportNetlist() {
 	}
 }
 
+// onExportOverlayLayers writes each annotation overlay as its own transparent
+// PNG at source resolution, for compositing in an external editor.
+func (mw *MainWindow) onExportOverlayLayers() {
+	layers := []struct {
+		suffix   string
+		overlays []string
+	}{
+		{"components", []string{"components"}},
+		{"vias", []string{panels.OverlayFeaturesVias}},
+		{"traces_front", []string{panels.OverlayFeaturesFront}},
+		{"traces_back", []string{panels.OverlayFeaturesBack}},
+		{"alignment", []string{"front_contacts", "back_contacts", "front_ejectors", "back_ejectors"}},
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export Overlay Layers", mw.win, gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Export", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	base := "board"
+	if mw.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(mw.state.ProjectPath))
+		base = strings.TrimSuffix(filepath.Base(mw.state.ProjectPath), filepath.Ext(mw.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	dir := dlg.GetFilename()
+
+	var written []string
+	for _, l := range layers {
+		// Several overlays may share one output layer; stack them in order.
+		var out *image.RGBA
+		for _, name := range l.overlays {
+			img := mw.canvas.RenderOverlayLayer(name)
+			if img == nil {
+				continue
+			}
+			if out == nil {
+				out = img
+			} else {
+				draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+			}
+		}
+		if out == nil {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.png", base, l.suffix))
+		if err := pcbimage.Export(out, mw.state.DPI, path, pcbimage.DefaultExportOptions()); err != nil {
+			mw.updateStatus(fmt.Sprintf("Export error: %v", err))
+			return
+		}
+		written = append(written, filepath.Base(path))
+	}
+
+	if len(written) == 0 {
+		mw.updateStatus("No overlays to export")
+		return
+	}
+	mw.updateStatus(fmt.Sprintf("Exported %d overlay layers to %s: %s",
+		len(written), dir, strings.Join(written, ", ")))
+}
+
+// cutoutTolerance is the per-channel RGB distance from the sampled scanner
+// background within which pixels outside the board become transparent.
+const cutoutTolerance = 40
+
+// onExportAnnotatedImage writes the visible layers with all visible overlays
+// as a PNG at source resolution, tagged with the project DPI. Optionally the scanner background outside
+// the detected board outline is made transparent, leaving a board cutout.
+func (mw *MainWindow) onExportAnnotatedImage() {
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export Annotated Image", mw.win, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Export", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	dlg.SetDoOverwriteConfirmation(true)
+
+	cutoutCheck, _ := gtk.CheckButtonNewWithLabel("Transparent background (cut out board)")
+	dlg.SetExtraWidget(cutoutCheck)
+
+	name := "board_annotated.png"
+	if mw.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(mw.state.ProjectPath))
+		base := strings.TrimSuffix(filepath.Base(mw.state.ProjectPath), filepath.Ext(mw.state.ProjectPath))
+		name = base + "_annotated.png"
+	}
+	dlg.SetCurrentName(name)
+
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+	if !strings.HasSuffix(strings.ToLower(path), ".png") {
+		path += ".png"
+	}
+
+	out := mw.canvas.RenderLayers()
+	if out == nil {
+		mw.updateStatus("No image to export")
+		return
+	}
+
+	note := ""
+	if cutoutCheck.GetActive() {
+		result := alignment.DetectBoardRotationFromImage(out)
+		if !result.Detected {
+			note = " (board outline not detected, no cutout)"
+		} else {
+			bg := pcbimage.SampleBackground(out, result.Bounds)
+			fmt.Printf("Export cutout: board (%d,%d) %dx%d, background %v\n",
+				result.Bounds.X, result.Bounds.Y, result.Bounds.Width, result.Bounds.Height, bg)
+			out = pcbimage.RemoveBackground(out, result.Bounds, bg, cutoutTolerance)
+		}
+	}
+	mw.canvas.RenderOverlays(out)
+
+	if err := pcbimage.Export(out, mw.state.DPI, path, pcbimage.DefaultExportOptions()); err != nil {
+		mw.updateStatus(fmt.Sprintf("Export error: %v", err))
+		return
+	}
+	mw.updateStatus(fmt.Sprintf("Exported annotated image to %s%s", path, note))
+}
+
 func (mw *MainWindow) onGenerateSchematic() {
 	if mw.state.FeaturesLayer == nil || mw.state.FeaturesLayer.NetCount() == 0 {
 		mw.updateStatus("No nets to generate schematic from")
@@ -931,6 +1150,60 @@ func (mw *MainWindow) onSelectBoard(name string) {
 	}
 }
 
+func (mw *MainWindow) onAssignGridIDs() {
+	if !mw.sidePanel.IsPanelEnabled(panels.PanelComponents) {
+		mw.updateStatus("Align the board before assigning grid IDs")
+		return
+	}
+	mw.sidePanel.ShowPanel(panels.PanelComponents)
+	mw.sidePanel.AssignGridIDs()
+}
+
+// onBoardEra sets the years component date codes may decode to, so OCR
+// noise and lot numbers outside the board's era aren't taken for dates.
+func (mw *MainWindow) onBoardEra() {
+	dlg, _ := gtk.DialogNewWithButtons("Board Era", mw.win,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL},
+		[]interface{}{"OK", gtk.RESPONSE_OK})
+	dlg.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	contentArea, _ := dlg.GetContentArea()
+	lbl, _ := gtk.LabelNew("Years component date codes may name (0 = no limit):")
+	lbl.SetHAlign(gtk.ALIGN_START)
+	contentArea.PackStart(lbl, false, false, 4)
+
+	row, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	minSpin, _ := gtk.SpinButtonNewWithRange(0, 2100, 1)
+	minSpin.SetValue(float64(mw.state.DateCodeYears.Min))
+	maxSpin, _ := gtk.SpinButtonNewWithRange(0, 2100, 1)
+	maxSpin.SetValue(float64(mw.state.DateCodeYears.Max))
+	fromLabel, _ := gtk.LabelNew("From")
+	toLabel, _ := gtk.LabelNew("to")
+	row.PackStart(fromLabel, false, false, 0)
+	row.PackStart(minSpin, false, false, 0)
+	row.PackStart(toLabel, false, false, 0)
+	row.PackStart(maxSpin, false, false, 0)
+	contentArea.PackStart(row, false, false, 4)
+	dlg.ShowAll()
+
+	resp := dlg.Run()
+	years := datecode.YearRange{Min: minSpin.GetValueAsInt(), Max: maxSpin.GetValueAsInt()}
+	dlg.Destroy()
+	if resp != gtk.RESPONSE_OK {
+		return
+	}
+	if years.Min > 0 && years.Max > 0 && years.Min > years.Max {
+		years.Min, years.Max = years.Max, years.Min
+	}
+	if years == mw.state.DateCodeYears {
+		return
+	}
+	mw.state.DateCodeYears = years
+	mw.state.SetModified(true)
+	mw.updateStatus("Board era: " + mw.state.DateCodeRange().String())
+}
+
 func (mw *MainWindow) onAbout() {
 	dlg := gtk.MessageDialogNew(
 		mw.win,
@@ -1,16 +1,22 @@
 package panels
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"math"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"pcb-tracer/internal/app"
 	"pcb-tracer/internal/component"
@@ -18,8 +24,10 @@ import (
 	pcbimage "pcb-tracer/internal/image"
 	"pcb-tracer/internal/logo"
 	"pcb-tracer/internal/ocr"
+	"pcb-tracer/pkg/colorutil"
 	"pcb-tracer/pkg/geometry"
 	"pcb-tracer/ui/canvas"
+	"pcb-tracer/ui/prefs"
 
 	"github.com/gotk3/gotk3/cairo"
 	"github.com/gotk3/gotk3/gdk"
@@ -56,6 +64,10 @@ type ComponentsPanel struct {
 	paned         *gtk.Paned // Draggable split between list and edit form
 	sortedIndices []int      // Indices into state.Components, sorted by ID
 
+	// Duplicate-ID warning + renumber button, hidden when IDs are unique
+	dupRow   *gtk.Box
+	dupLabel *gtk.Label
+
 	// Inline edit form
 	editingComp        *component.Component
 	editingIndex       int
@@ -66,24 +78,61 @@ type ComponentsPanel struct {
 	manufacturerEntry  *gtk.Entry
 	placeEntry         *gtk.Entry
 	dateCodeEntry      *gtk.Entry
+	dateCodeLabel      *gtk.Label // Inline decode of dateCodeEntry
 	revisionEntry      *gtk.Entry
 	speedGradeEntry    *gtk.Entry
 	descriptionEntry   *gtk.TextView
 	ocrTextEntry       *gtk.TextView
 	correctedTextEntry *gtk.TextView
-	ocrOrientation     []*gtk.RadioButton // N, S, E, W
+	ocrOrientation     []*gtk.RadioButton // N, S, E, W, Auto
 	ocrTrainingLabel   *gtk.Label
+	ocrDiffLabel       *gtk.Label        // Corrected vs OCR text, differences colored
 	previewArea        *gtk.DrawingArea  // Raw component image preview
 	previewRGBA        *image.RGBA       // Current preview image (rotated, unscaled)
+
+	// Detect All runs in the background; Stop cancels it
+	detectAllBtn    *gtk.Button
+	stopDetectBtn   *gtk.Button
+	detectAllCancel context.CancelFunc // nil when idle
+
+	// Train All runs in the background; its Stop button cancels it
+	trainAllBtn    *gtk.Button
+	stopTrainBtn   *gtk.Button
+	trainAllCancel context.CancelFunc // nil when idle
+
+	// Silkscreen OCR side selector (front, back or both) and the last
+	// result for each side, for Create from Silkscreen
+	ocrSideCombo      *gtk.ComboBoxText
+	silkscreenResults map[pcbimage.Side]*ocr.SilkscreenResult
+
+	// Middle-click flood fill color metric and tolerance
+	floodSpaceCombo *gtk.ComboBoxText
+	floodTolScale   *gtk.Scale
+	floodDiagCheck  *gtk.CheckButton
+
+	// Grid-score trimming of the flood fill bounds
+	prefs          *prefs.Prefs
+	gridStepSpin   *gtk.SpinButton
+	minScoreSpin   *gtk.SpinButton
+	gridDebugCheck *gtk.CheckButton
 }
 
+const prefKeyFloodGridStep = "floodGridStep"
+const prefKeyFloodMinScore = "floodMinScore"
+const prefKeyFloodGridDebug = "floodGridDebug"
+const prefKeyLogoMinScale = "logoMinScale"
+const prefKeyLogoMaxScale = "logoMaxScale"
+const prefKeyLogoScaleSteps = "logoScaleSteps"
+const prefKeyLogoMaxMask = "logoMaxMask"
+
 // NewComponentsPanel creates a new components panel.
-func NewComponentsPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window) *ComponentsPanel {
+func NewComponentsPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window, p *prefs.Prefs) *ComponentsPanel {
 	cp := &ComponentsPanel{
 		state:        state,
 		canvas:       cvs,
 		win:          win,
 		editingIndex: -1,
+		prefs:        p,
 	}
 
 	cp.rebuildSortedIndices()
@@ -101,12 +150,184 @@ func NewComponentsPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Wind
 	ocrSilkscreenBtn.Connect("clicked", func() { cp.onOCRSilkscreen() })
 	btnRow.PackStart(ocrSilkscreenBtn, true, true, 0)
 
+	cp.ocrSideCombo, _ = gtk.ComboBoxTextNew()
+	cp.ocrSideCombo.Append("front", "Front")
+	cp.ocrSideCombo.Append("back", "Back")
+	cp.ocrSideCombo.Append("both", "Both")
+	cp.ocrSideCombo.SetActiveID("front")
+	cp.ocrSideCombo.SetTooltipText("Which side's silkscreen to OCR")
+	btnRow.PackStart(cp.ocrSideCombo, false, false, 0)
+
+	placeSilkBtn, _ := gtk.ButtonNewWithLabel("Create from Silkscreen")
+	placeSilkBtn.SetTooltipText("Create unconfirmed components named and placed from the silkscreen designators")
+	placeSilkBtn.Connect("clicked", func() { cp.onCreateFromSilkscreen() })
+	btnRow.PackStart(placeSilkBtn, true, true, 0)
+
 	detectBtn, _ := gtk.ButtonNewWithLabel("Detect Components")
 	detectBtn.Connect("clicked", func() { cp.onDetectComponents() })
 	btnRow.PackStart(detectBtn, true, true, 0)
 
+	cp.detectAllBtn, _ = gtk.ButtonNewWithLabel("Detect All Components")
+	cp.detectAllBtn.SetTooltipText("Scan the whole front image and propose components that match the training samples")
+	cp.detectAllBtn.Connect("clicked", func() { cp.onDetectAllComponents() })
+	btnRow.PackStart(cp.detectAllBtn, true, true, 0)
+
+	cp.stopDetectBtn, _ = gtk.ButtonNewWithLabel("Stop")
+	cp.stopDetectBtn.SetTooltipText("Stop Detect All and keep the components found so far")
+	cp.stopDetectBtn.SetSensitive(false)
+	cp.stopDetectBtn.Connect("clicked", func() {
+		if cp.detectAllCancel != nil {
+			cp.detectAllCancel()
+		}
+	})
+	btnRow.PackStart(cp.stopDetectBtn, false, false, 0)
+
+	bomBtn, _ := gtk.ButtonNewWithLabel("Export BOM...")
+	bomBtn.Connect("clicked", func() { cp.onExportBOM() })
+	btnRow.PackStart(bomBtn, false, false, 0)
+
+	cropsBtn, _ := gtk.ButtonNewWithLabel("Export Crops...")
+	cropsBtn.SetTooltipText("Save each confirmed component as a PNG named by ID, with an index.html contact sheet")
+	cropsBtn.Connect("clicked", func() { cp.onExportCrops() })
+	btnRow.PackStart(cropsBtn, false, false, 0)
+
+	placementBtn, _ := gtk.ButtonNewWithLabel("Import Placement...")
+	placementBtn.Connect("clicked", func() { cp.onImportPlacement() })
+	btnRow.PackStart(placementBtn, false, false, 0)
+
 	cp.box.PackStart(btnRow, false, false, 0)
 
+	// Flood fill settings, used by middle-click on the canvas
+	floodDefaults := component.DefaultFloodOptions()
+	floodRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	floodLabel, _ := gtk.LabelNew("Flood fill:")
+	floodRow.PackStart(floodLabel, false, false, 0)
+
+	cp.floodSpaceCombo, _ = gtk.ComboBoxTextNew()
+	for _, space := range []component.ColorSpace{component.ColorSpaceHSV, component.ColorSpaceLab, component.ColorSpaceRGB} {
+		cp.floodSpaceCombo.Append(space.String(), space.String())
+	}
+	cp.floodSpaceCombo.SetActiveID(floodDefaults.Space.String())
+	cp.floodSpaceCombo.SetTooltipText("Color distance for middle-click flood fill: HSV follows hue and ignores glare, Lab is perceptual, RGB matches each channel")
+	floodRow.PackStart(cp.floodSpaceCombo, false, false, 0)
+
+	floodTolLabel, _ := gtk.LabelNew(fmt.Sprintf("Tolerance: %d", floodDefaults.Tolerance))
+	cp.floodTolScale, _ = gtk.ScaleNewWithRange(gtk.ORIENTATION_HORIZONTAL, 1, 100, 1)
+	cp.floodTolScale.SetValue(float64(floodDefaults.Tolerance))
+	cp.floodTolScale.SetDrawValue(false)
+	cp.floodTolScale.Connect("value-changed", func() {
+		floodTolLabel.SetText(fmt.Sprintf("Tolerance: %d", int(cp.floodTolScale.GetValue())))
+	})
+	floodRow.PackStart(floodTolLabel, false, false, 0)
+	floodRow.PackStart(cp.floodTolScale, true, true, 0)
+
+	cp.floodDiagCheck, _ = gtk.CheckButtonNewWithLabel("8-connected")
+	cp.floodDiagCheck.SetActive(floodDefaults.Connectivity == 8)
+	cp.floodDiagCheck.SetTooltipText("Also flood through diagonal neighbors")
+	floodRow.PackStart(cp.floodDiagCheck, false, false, 0)
+
+	cp.box.PackStart(floodRow, false, false, 0)
+
+	// Trimming: rows and columns of the flood fill bounds whose grid samples
+	// match the seed less than the minimum score are cut from the edges.
+	// A finer step and a higher score trim harder (dense boards); a lower
+	// score keeps more (sparse boards).
+	trimRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	stepLabel, _ := gtk.LabelNew("Trim grid step:")
+	cp.gridStepSpin, _ = gtk.SpinButtonNewWithRange(1, 10, 1)
+	cp.gridStepSpin.SetValue(p.FloatWithFallback(prefKeyFloodGridStep, 3))
+	cp.gridStepSpin.SetTooltipText("Spacing in pixels of the samples used to score rows and columns")
+	scoreLabel, _ := gtk.LabelNew("Min score:")
+	cp.minScoreSpin, _ = gtk.SpinButtonNewWithRange(0.05, 0.95, 0.05)
+	cp.minScoreSpin.SetDigits(2)
+	cp.minScoreSpin.SetValue(p.FloatWithFallback(prefKeyFloodMinScore, 0.25))
+	cp.minScoreSpin.SetTooltipText("Fraction of samples in an edge row or column that must match to keep it")
+	saveTrim := func() {
+		cp.prefs.SetFloat(prefKeyFloodGridStep, cp.gridStepSpin.GetValue())
+		cp.prefs.SetFloat(prefKeyFloodMinScore, cp.minScoreSpin.GetValue())
+		cp.prefs.Save()
+	}
+	cp.gridStepSpin.Connect("value-changed", saveTrim)
+	cp.minScoreSpin.Connect("value-changed", saveTrim)
+
+	cp.gridDebugCheck, _ = gtk.CheckButtonNewWithLabel("Show grid")
+	cp.gridDebugCheck.SetActive(p.Bool(prefKeyFloodGridDebug, false))
+	cp.gridDebugCheck.SetTooltipText("Show the trimming samples after a flood fill: green matched, red unmatched, gray trimmed off")
+	cp.gridDebugCheck.Connect("toggled", func() {
+		cp.prefs.SetBool(prefKeyFloodGridDebug, cp.gridDebugCheck.GetActive())
+		cp.prefs.Save()
+		if !cp.gridDebugCheck.GetActive() {
+			cp.clearGridDebugOverlay()
+		}
+	})
+
+	trimRow.PackStart(stepLabel, false, false, 0)
+	trimRow.PackStart(cp.gridStepSpin, false, false, 0)
+	trimRow.PackStart(scoreLabel, false, false, 0)
+	trimRow.PackStart(cp.minScoreSpin, false, false, 0)
+	trimRow.PackStart(cp.gridDebugCheck, false, false, 0)
+	cp.box.PackStart(trimRow, false, false, 0)
+
+	// Logo scale sweep used when masking logos for OCR. A range around 1
+	// also finds logos smaller or larger than their library template. The
+	// mask limit keeps false matches from erasing the markings.
+	logoDefaults := logo.DefaultDetectOptions()
+	logoRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	logoLabel, _ := gtk.LabelNew("Logo scale:")
+	logoMinSpin, _ := gtk.SpinButtonNewWithRange(0.25, 1, 0.05)
+	logoMinSpin.SetDigits(2)
+	logoMinSpin.SetValue(p.FloatWithFallback(prefKeyLogoMinScale, logoDefaults.MinScale))
+	logoMinSpin.SetTooltipText("Smallest logo size searched for, relative to the library template")
+	logoToLabel, _ := gtk.LabelNew("to")
+	logoMaxSpin, _ := gtk.SpinButtonNewWithRange(1, 4, 0.05)
+	logoMaxSpin.SetDigits(2)
+	logoMaxSpin.SetValue(p.FloatWithFallback(prefKeyLogoMaxScale, logoDefaults.MaxScale))
+	logoMaxSpin.SetTooltipText("Largest logo size searched for, relative to the library template")
+	logoStepsLabel, _ := gtk.LabelNew("Steps:")
+	logoStepsSpin, _ := gtk.SpinButtonNewWithRange(1, 9, 1)
+	logoStepsSpin.SetValue(p.FloatWithFallback(prefKeyLogoScaleSteps, logoDefaults.ScaleSteps))
+	logoStepsSpin.SetTooltipText("Number of scales tried between the smallest and largest; each adds a full scan")
+	logoMaskLabel, _ := gtk.LabelNew("Max masked:")
+	logoMaskSpin, _ := gtk.SpinButtonNewWithRange(0.05, 0.9, 0.05)
+	logoMaskSpin.SetDigits(2)
+	logoMaskSpin.SetValue(p.FloatWithFallback(prefKeyLogoMaxMask, logo.DefaultMaxMaskFraction))
+	logoMaskSpin.SetTooltipText("Largest fraction of a component that logo masking may erase in total; further logos are left for OCR")
+	saveLogo := func() {
+		cp.prefs.SetFloat(prefKeyLogoMinScale, logoMinSpin.GetValue())
+		cp.prefs.SetFloat(prefKeyLogoMaxScale, logoMaxSpin.GetValue())
+		cp.prefs.SetFloat(prefKeyLogoScaleSteps, logoStepsSpin.GetValue())
+		cp.prefs.SetFloat(prefKeyLogoMaxMask, logoMaskSpin.GetValue())
+		cp.prefs.Save()
+	}
+	logoMinSpin.Connect("value-changed", saveLogo)
+	logoMaxSpin.Connect("value-changed", saveLogo)
+	logoStepsSpin.Connect("value-changed", saveLogo)
+	logoMaskSpin.Connect("value-changed", saveLogo)
+
+	logoRow.PackStart(logoLabel, false, false, 0)
+	logoRow.PackStart(logoMinSpin, false, false, 0)
+	logoRow.PackStart(logoToLabel, false, false, 0)
+	logoRow.PackStart(logoMaxSpin, false, false, 0)
+	logoRow.PackStart(logoStepsLabel, false, false, 0)
+	logoRow.PackStart(logoStepsSpin, false, false, 0)
+	logoRow.PackStart(logoMaskLabel, false, false, 0)
+	logoRow.PackStart(logoMaskSpin, false, false, 0)
+	cp.box.PackStart(logoRow, false, false, 0)
+
+	// Duplicate ID warning (hidden unless duplicates exist)
+	cp.dupRow, _ = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	cp.dupLabel, _ = gtk.LabelNew("")
+	cp.dupLabel.SetHAlign(gtk.ALIGN_START)
+	cp.dupLabel.SetLineWrap(true)
+	renumberBtn, _ := gtk.ButtonNewWithLabel("Renumber")
+	renumberBtn.Connect("clicked", func() { cp.onRenumberDuplicates() })
+	cp.dupRow.PackStart(cp.dupLabel, true, true, 0)
+	cp.dupRow.PackStart(renumberBtn, false, false, 0)
+	cp.dupLabel.Show()
+	renumberBtn.Show()
+	cp.dupRow.SetNoShowAll(true)
+	cp.box.PackStart(cp.dupRow, false, false, 0)
+
 	// Create the list
 	cp.listBox, _ = gtk.ListBoxNew()
 	cp.listBox.SetSelectionMode(gtk.SELECTION_NONE)
@@ -215,6 +436,15 @@ func (cp *ComponentsPanel) buildEditForm() *gtk.ScrolledWindow {
 
 	cp.dateCodeEntry, _ = gtk.EntryNew()
 	cp.dateCodeEntry.SetPlaceholderText("e.g., 8523")
+	cp.dateCodeLabel, _ = gtk.LabelNew("")
+	cp.dateCodeLabel.SetHAlign(gtk.ALIGN_START)
+	cp.dateCodeLabel.SetLineWrap(true)
+	cp.dateCodeEntry.Connect("changed", func() { cp.updateDateCodeLabel() })
+	// The manufacturer selects the date code scheme
+	cp.manufacturerEntry.Connect("changed", func() { cp.updateDateCodeLabel() })
+	dateBox, _ := gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 0)
+	dateBox.PackStart(cp.dateCodeEntry, false, false, 0)
+	dateBox.PackStart(cp.dateCodeLabel, false, false, 0)
 
 	cp.revisionEntry, _ = gtk.EntryNew()
 
@@ -237,11 +467,24 @@ func (cp *ComponentsPanel) buildEditForm() *gtk.ScrolledWindow {
 
 	cp.ocrTextEntry, _ = gtk.TextViewNew()
 	cp.ocrTextEntry.SetWrapMode(gtk.WRAP_WORD_CHAR)
+	if buf, err := cp.ocrTextEntry.GetBuffer(); err == nil {
+		buf.CreateTag(ocrLowConfidenceTag, map[string]interface{}{"foreground": "#e05000"})
+	}
 	ocrScroll, _ := gtk.ScrolledWindowNew(nil, nil)
 	ocrScroll.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
 	ocrScroll.SetSizeRequest(-1, 40)
 	ocrScroll.Add(cp.ocrTextEntry)
 
+	cp.ocrDiffLabel, _ = gtk.LabelNew("")
+	cp.ocrDiffLabel.SetHAlign(gtk.ALIGN_START)
+	cp.ocrDiffLabel.SetSelectable(true)
+	cp.ocrDiffLabel.SetTooltipText("Corrected text over OCR result: orange misread, red missed, blue extra")
+	for _, tv := range []*gtk.TextView{cp.correctedTextEntry, cp.ocrTextEntry} {
+		if buf, err := tv.GetBuffer(); err == nil {
+			buf.Connect("changed", func() { cp.updateOCRDiff() })
+		}
+	}
+
 	// Form grid
 	grid, _ := gtk.GridNew()
 	grid.SetColumnSpacing(4)
@@ -268,14 +511,14 @@ func (cp *ComponentsPanel) buildEditForm() *gtk.ScrolledWindow {
 	addRow(2, "Package:", cp.packageEntry)
 	addRow(3, "Mfr:", cp.manufacturerEntry)
 	addRow(4, "Place:", cp.placeEntry)
-	addRow(5, "Date:", cp.dateCodeEntry)
+	addRow(5, "Date:", dateBox)
 	addRow(6, "Rev:", cp.revisionEntry)
 	addRow(7, "Speed:", cp.speedGradeEntry)
 
 	// OCR orientation radio buttons
 	orientBox, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
 	var firstRadio *gtk.RadioButton
-	for i, dir := range []string{"N", "S", "E", "W"} {
+	for i, dir := range ocrOrientationChoices {
 		var rb *gtk.RadioButton
 		if i == 0 {
 			rb, _ = gtk.RadioButtonNewWithLabel(nil, dir)
@@ -297,17 +540,42 @@ func (cp *ComponentsPanel) buildEditForm() *gtk.ScrolledWindow {
 
 	// OCR buttons
 	ocrBtn, _ := gtk.ButtonNewWithLabel("OCR")
-	ocrBtn.Connect("clicked", func() { cp.runOCR() })
+	ocrBtn.Connect("clicked", func() { cp.runOCR(false) })
+
+	reOCRBtn, _ := gtk.ButtonNewWithLabel("Force re-OCR")
+	reOCRBtn.SetTooltipText("Run OCR again even if a cached result matches")
+	reOCRBtn.Connect("clicked", func() { cp.runOCR(true) })
 
 	trainBtn, _ := gtk.ButtonNewWithLabel("Train")
 	trainBtn.Connect("clicked", func() { cp.runOCRTraining() })
 
+	cp.trainAllBtn, _ = gtk.ButtonNewWithLabel("Train All")
+	cp.trainAllBtn.SetTooltipText("Search OCR params for every component with corrected text and keep the good results")
+	cp.trainAllBtn.Connect("clicked", func() { cp.onTrainAll() })
+
+	cp.stopTrainBtn, _ = gtk.ButtonNewWithLabel("Stop")
+	cp.stopTrainBtn.SetTooltipText("Stop Train All and keep the samples found so far")
+	cp.stopTrainBtn.SetSensitive(false)
+	cp.stopTrainBtn.Connect("clicked", func() {
+		if cp.trainAllCancel != nil {
+			cp.trainAllCancel()
+		}
+	})
+
+	pruneBtn, _ := gtk.ButtonNewWithLabel("Prune")
+	pruneBtn.SetTooltipText("Remove duplicate and low-score samples from the OCR training database")
+	pruneBtn.Connect("clicked", func() { cp.onPruneOCRTraining() })
+
 	cp.ocrTrainingLabel, _ = gtk.LabelNew("")
 	cp.updateOCRTrainingLabel()
 
 	ocrRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
 	ocrRow.PackStart(ocrBtn, false, false, 0)
+	ocrRow.PackStart(reOCRBtn, false, false, 0)
 	ocrRow.PackStart(trainBtn, false, false, 0)
+	ocrRow.PackStart(cp.trainAllBtn, false, false, 0)
+	ocrRow.PackStart(cp.stopTrainBtn, false, false, 0)
+	ocrRow.PackStart(pruneBtn, false, false, 0)
 	dirLabel, _ := gtk.LabelNew("Dir:")
 	ocrRow.PackStart(dirLabel, false, false, 0)
 	ocrRow.PackStart(orientBox, false, false, 0)
@@ -357,6 +625,10 @@ func (cp *ComponentsPanel) buildEditForm() *gtk.ScrolledWindow {
 	ocrLabel.SetHAlign(gtk.ALIGN_START)
 	formBox.PackStart(ocrLabel, false, false, 0)
 	formBox.PackStart(ocrScroll, false, false, 0)
+	diffLabel, _ := gtk.LabelNew("Diff:")
+	diffLabel.SetHAlign(gtk.ALIGN_START)
+	formBox.PackStart(diffLabel, false, false, 0)
+	formBox.PackStart(cp.ocrDiffLabel, false, false, 0)
 
 	sep3, _ := gtk.SeparatorNew(gtk.ORIENTATION_HORIZONTAL)
 	formBox.PackStart(sep3, false, false, 2)
@@ -423,6 +695,8 @@ func (cp *ComponentsPanel) refreshList() {
 		}
 	})
 
+	dups := component.FindDuplicateIDs(cp.state.Components)
+
 	for _, compIdx := range cp.sortedIndices {
 		if compIdx >= len(cp.state.Components) {
 			continue
@@ -433,6 +707,9 @@ func (cp *ComponentsPanel) refreshList() {
 		if !comp.Confirmed {
 			prefix = "* "
 		}
+		if _, dup := dups[comp.ID]; dup {
+			prefix = "⚠ " + prefix
+		}
 		detail := comp.PartNumber
 		if detail == "" {
 			detail = comp.Package
@@ -451,14 +728,49 @@ func (cp *ComponentsPanel) refreshList() {
 	}
 
 	cp.listBox.ShowAll()
+	cp.updateDuplicateWarning(dups)
+}
+
+// updateDuplicateWarning shows or hides the duplicate-ID warning row.
+func (cp *ComponentsPanel) updateDuplicateWarning(dups map[string][]int) {
+	if len(dups) == 0 {
+		cp.dupRow.Hide()
+		return
+	}
+	ids := make([]string, 0, len(dups))
+	for id := range dups {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return component.NaturalLess(ids[i], ids[j]) })
+	cp.dupLabel.SetText(fmt.Sprintf("⚠ Duplicate IDs: %s", strings.Join(ids, ", ")))
+	cp.dupRow.Show()
 }
 
+// onRenumberDuplicates gives every duplicate component after the first a unique ID.
+func (cp *ComponentsPanel) onRenumberDuplicates() {
+	renamed := component.RenumberDuplicateIDs(cp.state.Components, 100)
+	if len(renamed) == 0 {
+		return
+	}
+	for idx, id := range renamed {
+		fmt.Printf("[components] Renumbered duplicate component %d -> %s\n", idx, id)
+	}
+	cp.state.SetModified(true)
+	cp.state.Emit(app.EventComponentsChanged, nil)
+}
+
+// ocrOrientationAuto is the orientation choice that has runOCR try all four
+// rotations and keep the best reading. It is never stored on a component.
+const ocrOrientationAuto = "Auto"
+
+// ocrOrientationChoices lists the orientation radio buttons in order.
+var ocrOrientationChoices = []string{"N", "S", "E", "W", ocrOrientationAuto}
+
 // getSelectedOrientation returns the currently selected OCR orientation string.
 func (cp *ComponentsPanel) getSelectedOrientation() string {
-	dirs := []string{"N", "S", "E", "W"}
 	for i, rb := range cp.ocrOrientation {
 		if rb.GetActive() {
-			return dirs[i]
+			return ocrOrientationChoices[i]
 		}
 	}
 	return "N"
@@ -466,8 +778,7 @@ func (cp *ComponentsPanel) getSelectedOrientation() string {
 
 // setSelectedOrientation sets the OCR orientation radio button.
 func (cp *ComponentsPanel) setSelectedOrientation(orient string) {
-	dirs := []string{"N", "S", "E", "W"}
-	for i, dir := range dirs {
+	for i, dir := range ocrOrientationChoices {
 		if dir == orient {
 			cp.ocrOrientation[i].SetActive(true)
 			return
@@ -476,6 +787,63 @@ func (cp *ComponentsPanel) setSelectedOrientation(orient string) {
 	cp.ocrOrientation[0].SetActive(true)
 }
 
+// nextOCROrientation returns the orientation a quarter turn clockwise from
+// orient (N→E→S→W→N). An empty orientation is treated as N.
+func nextOCROrientation(orient string) string {
+	switch orient {
+	case "N", "":
+		return "E"
+	case "E":
+		return "S"
+	case "S":
+		return "W"
+	}
+	return "N"
+}
+
+// cycleOCROrientation advances the editing component's OCR orientation by a
+// quarter turn and re-runs OCR with it.
+func (cp *ComponentsPanel) cycleOCROrientation() {
+	cycleComponentOrientation(cp.editingComp, cp.getSelectedOrientation(), cp.setSelectedOrientation, func(orient string) {
+		cp.state.LastOCROrientation = orient
+		cp.state.SetModified(true)
+		fmt.Printf("[OCR] Orientation -> %s\n", orient)
+		cp.runOCR(false)
+	})
+}
+
+// cycleComponentOrientation sets comp's OCR orientation a quarter turn on
+// from current, shows it (the radio group), then calls ocr with it, so the
+// OCR pass reads the new orientation. Returns the new orientation, or "" if
+// comp is nil.
+func cycleComponentOrientation(comp *component.Component, current string, show func(string), ocr func(string)) string {
+	if comp == nil {
+		return ""
+	}
+	orient := nextOCROrientation(current)
+	comp.OCROrientation = orient
+	show(orient)
+	ocr(orient)
+	return orient
+}
+
+// textInputFocused reports whether keyboard focus is in a text entry, where
+// plain letter keys must reach the widget instead of acting as shortcuts.
+func (cp *ComponentsPanel) textInputFocused() bool {
+	if cp.win == nil {
+		return false
+	}
+	focus, err := cp.win.GetFocus()
+	if err != nil || focus == nil {
+		return false
+	}
+	switch focus.(type) {
+	case gtk.IEditable, *gtk.TextView:
+		return true
+	}
+	return false
+}
+
 // getTextViewText returns the text content of a TextView.
 func getTextViewText(tv *gtk.TextView) string {
 	buf, _ := tv.GetBuffer()
@@ -490,6 +858,69 @@ func setTextViewText(tv *gtk.TextView, text string) {
 	buf.SetText(text)
 }
 
+// updateOCRDiff shows the corrected text aligned over the OCR result, or
+// nothing until both are filled in.
+func (cp *ComponentsPanel) updateOCRDiff() {
+	if cp.ocrDiffLabel == nil {
+		return
+	}
+	truth := getTextViewText(cp.correctedTextEntry)
+	detected := getTextViewText(cp.ocrTextEntry)
+	if strings.TrimSpace(truth) == "" || strings.TrimSpace(detected) == "" {
+		cp.ocrDiffLabel.SetMarkup("")
+		return
+	}
+	cp.ocrDiffLabel.SetMarkup(ocrDiffMarkup(ocr.DiffText(truth, detected)))
+}
+
+// ocrDiffMarkup renders aligned text as Pango markup: each line of the
+// corrected text in monospace over the OCR line it was aligned to, with
+// misread characters orange, missed ones red and extra ones blue. A gap
+// in either line is drawn as '·' and an unmatched line break as '⏎'.
+func ocrDiffMarkup(ops []ocr.CharError) string {
+	var out, truthRow, detRow strings.Builder
+	flush := func() {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "%s\n%s", truthRow.String(), detRow.String())
+		truthRow.Reset()
+		detRow.Reset()
+	}
+	show := func(r rune) string {
+		if r == '\n' {
+			return "⏎"
+		}
+		return string(r)
+	}
+	colored := func(color, text string) string {
+		return fmt.Sprintf("<span foreground='%s' weight='bold'>%s</span>", color, text)
+	}
+
+	for _, op := range ops {
+		switch op.Kind {
+		case "":
+			if op.Truth == '\n' {
+				flush()
+				continue
+			}
+			truthRow.WriteRune(op.Truth)
+			detRow.WriteRune(op.Detected)
+		case ocr.ErrSubstitution:
+			truthRow.WriteString(colored("#e05000", show(op.Truth)))
+			detRow.WriteString(colored("#e05000", show(op.Detected)))
+		case ocr.ErrDeletion:
+			truthRow.WriteString(colored("red", show(op.Truth)))
+			detRow.WriteString(colored("red", "·"))
+		case ocr.ErrInsertion:
+			truthRow.WriteString(colored("blue", "·"))
+			detRow.WriteString(colored("blue", show(op.Detected)))
+		}
+	}
+	flush()
+	return "<tt>" + out.String() + "</tt>"
+}
+
 // rebuildSortedIndices rebuilds the sorted indices using natural numeric sorting by component ID.
 func (cp *ComponentsPanel) rebuildSortedIndices() {
 	n := len(cp.state.Components)
@@ -500,7 +931,7 @@ func (cp *ComponentsPanel) rebuildSortedIndices() {
 	sort.Slice(cp.sortedIndices, func(i, j int) bool {
 		ci := cp.state.Components[cp.sortedIndices[i]]
 		cj := cp.state.Components[cp.sortedIndices[j]]
-		return naturalLess(ci.ID, cj.ID)
+		return component.NaturalLess(ci.ID, cj.ID)
 	})
 }
 
@@ -522,6 +953,33 @@ func (cp *ComponentsPanel) updateOCRTrainingLabel() {
 		len(db.Samples), orientCounts["N"], orientCounts["S"], orientCounts["E"], orientCounts["W"]))
 }
 
+// onPruneOCRTraining prunes the global OCR training database after
+// confirmation and reports how many samples were removed.
+func (cp *ComponentsPanel) onPruneOCRTraining() {
+	if cp.state.GlobalOCRTraining == nil || len(cp.state.GlobalOCRTraining.Samples) == 0 {
+		return
+	}
+	opts := ocr.DefaultPruneOptions()
+	dlg := gtk.MessageDialogNew(cp.win, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
+		"Prune the OCR training database? Duplicates, samples scoring below %.0f%% and all but the best %d per orientation and manufacturer will be removed.",
+		opts.MinScore*100, opts.MaxPerGroup)
+	resp := dlg.Run()
+	dlg.Destroy()
+	if resp != gtk.RESPONSE_YES {
+		return
+	}
+
+	stats, err := cp.state.PruneOCRTraining(opts)
+	if err != nil {
+		fmt.Printf("[OCR Train] Prune: failed to save: %v\n", err)
+	}
+	fmt.Printf("[OCR Train] Prune: %s\n", stats)
+	cp.updateOCRTrainingLabel()
+	if cp.ocrTrainingLabel != nil {
+		cp.ocrTrainingLabel.SetTooltipText(fmt.Sprintf("Last prune %s", stats))
+	}
+}
+
 // showEditDialog populates the inline edit form for the given component index.
 func (cp *ComponentsPanel) showEditDialog(index int) {
 	if index < 0 || index >= len(cp.state.Components) {
@@ -542,13 +1000,14 @@ func (cp *ComponentsPanel) showEditDialog(index int) {
 	cp.speedGradeEntry.SetText(comp.SpeedGrade)
 	setTextViewText(cp.descriptionEntry, comp.Description)
 	setTextViewText(cp.ocrTextEntry, comp.OCRText)
+	cp.ocrTextEntry.SetTooltipText("")
 	setTextViewText(cp.correctedTextEntry, comp.CorrectedText)
 
-	// Set orientation: sticky direction always takes precedence
-	if cp.state.LastOCROrientation != "" {
-		cp.setSelectedOrientation(cp.state.LastOCROrientation)
-	} else if comp.OCROrientation != "" {
+	// Set orientation: the component's own orientation, then the sticky one
+	if comp.OCROrientation != "" {
 		cp.setSelectedOrientation(comp.OCROrientation)
+	} else if cp.state.LastOCROrientation != "" {
+		cp.setSelectedOrientation(cp.state.LastOCROrientation)
 	} else {
 		cp.setSelectedOrientation("N")
 	}
@@ -579,36 +1038,17 @@ func (cp *ComponentsPanel) updatePreviewImage() {
 		return
 	}
 
-	bounds := cp.editingComp.Bounds
-	x, y := int(bounds.X), int(bounds.Y)
-	w, h := int(bounds.Width), int(bounds.Height)
-
-	imgBounds := img.Bounds()
-	if x < imgBounds.Min.X {
-		x = imgBounds.Min.X
-	}
-	if y < imgBounds.Min.Y {
-		y = imgBounds.Min.Y
-	}
-	w = min(w, imgBounds.Max.X-x)
-	h = min(h, imgBounds.Max.Y-y)
-	if w <= 0 || h <= 0 {
+	// Crop the component region
+	cropped := cropComponent(img, cp.editingComp)
+	if cropped == nil {
 		cp.previewRGBA = nil
 		cp.previewArea.QueueDraw()
 		return
 	}
 
-	// Crop the component region
-	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			cropped.Set(dx, dy, img.At(x+dx, y+dy))
-		}
-	}
-
 	// Rotate according to the selected OCR orientation
 	orientation := cp.getSelectedOrientation()
-	cp.previewRGBA = rotateForOCR(cropped, orientation)
+	cp.previewRGBA = component.RotateToOrientation(cropped, orientation)
 	cp.previewArea.QueueDraw()
 }
 
@@ -682,6 +1122,17 @@ func (cp *ComponentsPanel) saveEditingComponent() {
 	cp.editingComp.Package = pkgText
 	cp.editingComp.Manufacturer = mfrText
 	cp.editingComp.Place = placeText
+	// Store the normalized code when it decodes; keep the raw entry otherwise
+	if _, cands := describeDateCode(dateText, mfrText, cp.state.DateCodeRange()); len(cands) > 0 {
+		dateText = datecode.Normalize(dateText)
+		cp.dateCodeEntry.SetText(dateText)
+		cp.editingComp.DecodedDate = cands[0].String()
+	} else {
+		cp.editingComp.DecodedDate = ""
+		if strings.TrimSpace(dateText) != "" {
+			fmt.Printf("[Save] Date code %q not recognized\n", dateText)
+		}
+	}
 	cp.editingComp.DateCode = dateText
 	cp.editingComp.Revision = revText
 	cp.editingComp.SpeedGrade = speedText
@@ -691,7 +1142,7 @@ func (cp *ComponentsPanel) saveEditingComponent() {
 	// Always update sticky orientation for next component
 	cp.state.LastOCROrientation = orientation
 	// Only persist orientation on the component if OCR was performed or it already had one
-	if strings.TrimSpace(ocrText) != "" || cp.editingComp.OCROrientation != "" {
+	if orientation != ocrOrientationAuto && (strings.TrimSpace(ocrText) != "" || cp.editingComp.OCROrientation != "") {
 		cp.editingComp.OCROrientation = orientation
 	}
 
@@ -700,16 +1151,12 @@ func (cp *ComponentsPanel) saveEditingComponent() {
 		score := ocr.TextSimilarity(ocrText, corrText)
 		var params ocr.OCRParams
 		if cp.state.GlobalOCRTraining != nil {
-			if p, ok := cp.state.GlobalOCRTraining.GetParamsForOrientation(orientation); ok {
-				params = p
-			} else {
-				params = cp.state.GlobalOCRTraining.GetRecommendedParams()
-			}
+			params, _ = cp.state.GlobalOCRTraining.GetParamsFor(mfrText, pkgText, orientation)
 		} else {
 			params = ocr.DefaultOCRParams()
 		}
 		if score >= 0.7 {
-			cp.state.AddOCRTrainingSample(corrText, ocrText, score, orientation, params)
+			cp.state.AddOCRTrainingSample(corrText, ocrText, score, orientation, params, mfrText, pkgText)
 			cp.updateOCRTrainingLabel()
 			fmt.Printf("[Save] Added training sample: score=%.1f%% orientation=%s\n", score*100, orientation)
 		} else {
@@ -756,7 +1203,7 @@ func (cp *ComponentsPanel) saveEditingComponent() {
 
 	// Add to global component training set
 	if cp.state.FrontImage != nil && cp.state.FrontImage.Image != nil {
-		dpi := cp.state.DPI
+		dpi := cp.state.DPIForSide(pcbimage.SideFront)
 		if dpi <= 0 {
 			dpi = 1200
 		}
@@ -794,6 +1241,37 @@ func (cp *ComponentsPanel) deleteEditingComponent() {
 	cp.clearEditForm()
 }
 
+// dateCodeContextYear is the decade hint used when extracting date codes
+// from OCR text.
+const dateCodeContextYear = 1990
+
+// describeDateCode decodes a date code within years under the scheme of the
+// component's manufacturer, falling back to every scheme if that one
+// doesn't fit.
+func describeDateCode(code, manufacturer string, years datecode.YearRange) (string, []datecode.Candidate) {
+	scheme := datecode.SchemeForManufacturer(manufacturer)
+	desc, cands := datecode.Describe(code, scheme, years)
+	if len(cands) == 0 && scheme != datecode.SchemeAuto {
+		if autoDesc, autoCands := datecode.Describe(code, datecode.SchemeAuto, years); len(autoCands) > 0 {
+			return autoDesc, autoCands
+		}
+	}
+	return desc, cands
+}
+
+// updateDateCodeLabel shows the decoded calendar date next to the
+// date-code entry, or flags the entry when it cannot be decoded.
+func (cp *ComponentsPanel) updateDateCodeLabel() {
+	text, _ := cp.dateCodeEntry.GetText()
+	mfr, _ := cp.manufacturerEntry.GetText()
+	desc, cands := describeDateCode(text, mfr, cp.state.DateCodeRange())
+	if len(cands) == 0 && desc != "" {
+		cp.dateCodeLabel.SetMarkup(fmt.Sprintf("<span foreground='red'>%s</span>", glib.MarkupEscapeText(desc)))
+		return
+	}
+	cp.dateCodeLabel.SetText(desc)
+}
+
 // clearEditForm clears all form fields and the highlight.
 func (cp *ComponentsPanel) clearEditForm() {
 	cp.idEntry.SetText("")
@@ -806,6 +1284,7 @@ func (cp *ComponentsPanel) clearEditForm() {
 	cp.speedGradeEntry.SetText("")
 	setTextViewText(cp.descriptionEntry, "")
 	setTextViewText(cp.ocrTextEntry, "")
+	cp.ocrTextEntry.SetTooltipText("")
 	setTextViewText(cp.correctedTextEntry, "")
 	cp.setSelectedOrientation("N")
 
@@ -835,13 +1314,26 @@ func (cp *ComponentsPanel) getComponentImage() image.Image {
 	return img
 }
 
-// runOCR performs OCR on the currently editing component.
-func (cp *ComponentsPanel) runOCR() {
+// runOCR performs OCR on the currently editing component. Unless force is
+// set, a cached reading for the same bounds, orientation and params is
+// reused instead of running Tesseract again.
+func (cp *ComponentsPanel) runOCR(force bool) {
 	if cp.editingComp == nil {
 		fmt.Println("[OCR] No component selected")
 		return
 	}
 
+	orientation := cp.getSelectedOrientation()
+	if !force && orientation != ocrOrientationAuto {
+		params, _ := cp.ocrParamsFor(orientation)
+		if cached, ok := cp.editingComp.CachedOCR(cp.editingComp.OCRCacheKey(orientation, params.Hash())); ok {
+			fmt.Println("[OCR] Using cached result")
+			cp.applyOCRResult(cached.Text, cached.Manufacturer)
+			cp.state.LastOCROrientation = orientation
+			return
+		}
+	}
+
 	img := cp.getComponentImage()
 	if img == nil {
 		fmt.Println("[OCR] No image available")
@@ -849,147 +1341,115 @@ func (cp *ComponentsPanel) runOCR() {
 	}
 
 	bounds := cp.editingComp.Bounds
-	x, y := int(bounds.X), int(bounds.Y)
-	w, h := int(bounds.Width), int(bounds.Height)
-	fmt.Printf("[OCR] Component bounds: (%d,%d) %dx%d\n", x, y, w, h)
+	fmt.Printf("[OCR] Component bounds: (%d,%d) %dx%d tilt %.1f°\n",
+		int(bounds.X), int(bounds.Y), int(bounds.Width), int(bounds.Height), cp.editingComp.Tilt())
 
-	imgBounds := img.Bounds()
-	if x < imgBounds.Min.X {
-		x = imgBounds.Min.X
-	}
-	if y < imgBounds.Min.Y {
-		y = imgBounds.Min.Y
-	}
-	w = min(w, imgBounds.Max.X-x)
-	h = min(h, imgBounds.Max.Y-y)
-
-	if w <= 0 || h <= 0 {
+	// Straighten a tilted body first; the N/S/E/W orientation then turns
+	// the upright crop
+	cropped := cropComponent(img, cp.editingComp)
+	if cropped == nil {
 		fmt.Println("[OCR] Invalid bounds")
 		return
 	}
 
-	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			cropped.Set(dx, dy, img.At(x+dx, y+dy))
-		}
-	}
-
-	orientation := cp.getSelectedOrientation()
-	logoRotation := orientationToRotation(orientation)
-	rotated := rotateForOCR(cropped, orientation)
-
-	rotBounds := rotated.Bounds()
-	masked := image.NewRGBA(rotBounds)
-	copy(masked.Pix, rotated.Pix)
-
-	// Detect logos and fill them
-	var detectedLogos []logo.LogoMatch
-	if cp.state.LogoLibrary != nil && len(cp.state.LogoLibrary.Logos) > 0 {
-		mw, mh := rotBounds.Dx(), rotBounds.Dy()
-		searchBounds := geometry.RectInt{X: 0, Y: 0, Width: mw, Height: mh}
-		detectedLogos = cp.state.LogoLibrary.DetectLogos(masked, searchBounds, 0.75, logoRotation)
-		if len(detectedLogos) > 0 {
-			fmt.Printf("[OCR] Detected %d logos\n", len(detectedLogos))
-			bgColor := ocr.CalculateBackgroundColor(masked)
-			compArea := mw * mh
-			for _, m := range detectedLogos {
-				logoArea := m.Bounds.Width * m.Bounds.Height
-				pct := logoArea * 100 / compArea
-				fmt.Printf("[OCR Logo] name=%q score=%.3f rot=%d scale=%.2f bounds=(%d,%d %dx%d) area=%d%% of component\n",
-					m.Logo.Name, m.Score, m.Rotation, m.ScaleFactor,
-					m.Bounds.X, m.Bounds.Y, m.Bounds.Width, m.Bounds.Height, pct)
-				if logoArea > compArea/4 {
-					fmt.Printf("[OCR Logo] SKIP: too large (%d%% > 25%%)\n", pct)
-					continue
-				}
-				fmt.Printf("[OCR Logo] MASK: filling (%d,%d)-(%d,%d) with bg=(%d,%d,%d)\n",
-					m.Bounds.X, m.Bounds.Y,
-					m.Bounds.X+m.Bounds.Width, m.Bounds.Y+m.Bounds.Height,
-					bgColor.R, bgColor.G, bgColor.B)
-				ocr.MaskRegion(masked, m.Bounds, bgColor)
-			}
-		}
-	}
-
-	// Show OCR preview
-	cp.showOCRPreview(rotated, masked, orientation)
-
-	// OCR runs on the masked image, binarized and despeckled
-	ocrGray, mw, mh := rgbaToGray(masked)
-	ocrThresh := robustOtsu(ocrGray, mw, mh)
-
-	ocrBW := make([]bool, mw*mh)
-	for i := 0; i < mw*mh; i++ {
-		ocrBW[i] = ocrGray[i] > ocrThresh
-	}
-	despeckleBW(ocrBW, mw, mh)
-
-	ocrBytes := make([]byte, mw*mh)
-	for i := 0; i < mw*mh; i++ {
-		if ocrBW[i] {
-			ocrBytes[i] = 255
-		}
-	}
-
-	grayMat, err := gocv.NewMatFromBytes(mh, mw, gocv.MatTypeCV8UC1, ocrBytes)
-	if err != nil {
-		fmt.Printf("[OCR] Mat conversion failed: %v\n", err)
-		return
-	}
-	defer grayMat.Close()
-
-	bgr := gocv.NewMat()
-	defer bgr.Close()
-	gocv.CvtColor(grayMat, &bgr, gocv.ColorGrayToBGR)
-
-	engine, err := ocr.NewEngine()
+	pool := ocr.SharedEnginePool()
+	engine, err := pool.Acquire()
 	if err != nil {
 		fmt.Printf("[OCR] Engine creation failed: %v\n", err)
 		return
 	}
-	defer engine.Close()
-
-	var text string
-	var params ocr.OCRParams
-	paramsSource := "default"
+	defer pool.Release(engine)
 
-	if cp.state.GlobalOCRTraining != nil && len(cp.state.GlobalOCRTraining.Samples) >= 5 {
-		if orientParams, ok := cp.state.GlobalOCRTraining.GetParamsForOrientation(orientation); ok {
-			params = orientParams
-			paramsSource = fmt.Sprintf("global/%s (%d samples)", orientation, len(cp.state.GlobalOCRTraining.Samples))
-		} else {
-			params = cp.state.GlobalOCRTraining.GetRecommendedParams()
-			paramsSource = fmt.Sprintf("global (%d samples)", len(cp.state.GlobalOCRTraining.Samples))
+	var pass ocrPass
+	if orientation == ocrOrientationAuto {
+		// Read all four rotations and keep the one that scores best
+		var best ocrPass
+		bestScore := -1.0
+		for _, dir := range []string{"N", "S", "E", "W"} {
+			p, ok := cp.ocrOrientationPass(engine, cropped, dir)
+			if !ok {
+				continue
+			}
+			score := cp.ocrVoteScore(p.text, p.confidence)
+			fmt.Printf("[OCR] Auto %s: conf=%.1f score=%.3f text=%q\n",
+				dir, p.confidence, score, strings.ReplaceAll(p.text, "\n", " | "))
+			if score > bestScore {
+				best, bestScore = p, score
+			}
 		}
+		if bestScore < 0 {
+			return
+		}
+		pass = best
+		orientation = pass.orientation
+		fmt.Printf("[OCR] Auto orientation -> %s\n", orientation)
+		cp.setSelectedOrientation(orientation)
+		cp.editingComp.OCROrientation = orientation
+		cp.state.SetModified(true)
 	} else {
-		params = ocr.DefaultOCRParams()
+		p, ok := cp.ocrOrientationPass(engine, cropped, orientation)
+		if !ok {
+			return
+		}
+		pass = p
 	}
 
-	fmt.Printf("[OCR] Using %s params\n", paramsSource)
-	text, err = engine.RecognizeWithParams(bgr, params)
-	if err != nil {
-		fmt.Printf("[OCR] Failed: %v\n", err)
-		return
+	// Show OCR preview
+	cp.showOCRPreview(pass)
+
+	// Cache under the orientation actually read, which is what the radio
+	// shows from now on even after an Auto vote.
+	params, _ := cp.ocrParamsFor(orientation)
+	cp.editingComp.OCRCache = &component.OCRCache{
+		Key:          cp.editingComp.OCRCacheKey(orientation, params.Hash()),
+		Text:         pass.text,
+		Manufacturer: pass.manufacturer,
 	}
+	cp.state.SetModified(true)
 
-	text = fixOCRPartNumbers(text)
+	cp.applyOCRResult(pass.text, pass.manufacturer)
+	cp.markOCRConfidence(pass.confidence, pass.words)
+	cp.state.LastOCROrientation = orientation
+	fmt.Printf("[OCR] Complete: %s\n", pass.text)
+}
 
-	// Prepend detected logos
-	var detectedManufacturer string
-	if len(detectedLogos) > 0 {
-		var logoNames []string
-		for _, m := range detectedLogos {
-			logoNames = append(logoNames, fmt.Sprintf("<%s>", m.Logo.Name))
-			if detectedManufacturer == "" && m.Logo.ManufacturerID != "" {
-				detectedManufacturer = m.Logo.ManufacturerID
+// ocrLowConfidenceTag colors words in the OCR text that Tesseract was
+// unsure of.
+const ocrLowConfidenceTag = "low-confidence"
+
+// markOCRConfidence colors the words in the OCR text view read with less
+// than ocr.LowConfidence and shows the mean confidence as its tooltip.
+// Words changed by part-number fixing are no longer found and stay plain.
+func (cp *ComponentsPanel) markOCRConfidence(mean float64, words []ocr.WordConfidence) {
+	buf, _ := cp.ocrTextEntry.GetBuffer()
+	text := getTextViewText(cp.ocrTextEntry)
+	var low int
+	for _, w := range words {
+		if w.Confidence >= ocr.LowConfidence {
+			continue
+		}
+		low++
+		for from := 0; ; {
+			i := strings.Index(text[from:], w.Text)
+			if i < 0 {
+				break
 			}
+			start := utf8.RuneCountInString(text[:from+i])
+			end := start + utf8.RuneCountInString(w.Text)
+			buf.ApplyTagByName(ocrLowConfidenceTag, buf.GetIterAtOffset(start), buf.GetIterAtOffset(end))
+			from += i + len(w.Text)
 		}
-		text = strings.Join(logoNames, " ") + "\n" + text
 	}
+	cp.ocrTextEntry.SetTooltipText(fmt.Sprintf("OCR confidence %.0f%%, %d of %d words below %.0f%%",
+		mean, low, len(words), ocr.LowConfidence))
+}
 
+// applyOCRResult shows OCR text in the form and fills any empty fields
+// parsed from it.
+func (cp *ComponentsPanel) applyOCRResult(text, detectedManufacturer string) {
 	// Update form fields
 	setTextViewText(cp.ocrTextEntry, text)
+	cp.ocrTextEntry.SetTooltipText("") // Confidence is only known for a fresh reading
 	cp.editingComp.OCRText = text
 
 	info := parseComponentInfo(text)
@@ -1011,7 +1471,7 @@ func (cp *ComponentsPanel) runOCR() {
 	}
 	dateText, _ := cp.dateCodeEntry.GetText()
 	if dateText == "" {
-		if code, decoded := datecode.ExtractDateCode(text, 1990); decoded != nil {
+		if code, decoded := datecode.ExtractDateCodeInRange(text, dateCodeContextYear, cp.state.DateCodeRange()); decoded != nil {
 			cp.dateCodeEntry.SetText(code)
 			fmt.Printf("[OCR] Decoded date: %s -> %s\n", code, decoded.String())
 		} else if info.DateCode != "" {
@@ -1036,15 +1496,170 @@ func (cp *ComponentsPanel) runOCR() {
 			fmt.Printf("[OCR] Library lookup: %s -> %s (%d pins)\n", partNum, libPart.Package, libPart.PinCount)
 		}
 	}
+}
+
+// ocrPass is the result of reading a component crop at one orientation.
+type ocrPass struct {
+	orientation     string
+	rotated, masked *image.RGBA
+	text            string               // Part numbers fixed, detected logos prepended
+	manufacturer    string               // From the first detected logo, if any
+	confidence      float64              // Tesseract mean word confidence, 0-100
+	words, lines    []ocr.WordConfidence // Boxes in rotated's coordinates
+}
+
+// recognizeBinarized binarizes img at ocr.BinarizeThreshold and despeckles it,
+// then reads it with params. Word and line boxes are in img's coordinates.
+func recognizeBinarized(engine *ocr.Engine, img *image.RGBA, params ocr.OCRParams) (ocr.Recognition, error) {
+	gray, w, h := rgbaToGray(img)
+	thresh, method := ocr.BinarizeThreshold(gray, w, h, params)
+	fmt.Printf("[OCR] Binarized with %s\n", method)
+
+	bw := make([]bool, w*h)
+	for i := 0; i < w*h; i++ {
+		bw[i] = gray[i] > thresh
+	}
+	despeckleBW(bw, w, h)
 
-	cp.state.LastOCROrientation = orientation
-	fmt.Printf("[OCR] Complete: %s\n", text)
+	pix := make([]byte, w*h)
+	for i := 0; i < w*h; i++ {
+		if bw[i] {
+			pix[i] = 255
+		}
+	}
+
+	grayMat, err := gocv.NewMatFromBytes(h, w, gocv.MatTypeCV8UC1, pix)
+	if err != nil {
+		return ocr.Recognition{}, fmt.Errorf("mat conversion: %w", err)
+	}
+	defer grayMat.Close()
+
+	bgr := gocv.NewMat()
+	defer bgr.Close()
+	gocv.CvtColor(grayMat, &bgr, gocv.ColorGrayToBGR)
+
+	return engine.RecognizeDetailed(bgr, params)
+}
+
+// ocrOrientationPass rotates the cropped component to orientation, masks
+// known logos, binarizes and runs OCR with the params trained for that
+// orientation.
+func (cp *ComponentsPanel) ocrOrientationPass(engine *ocr.Engine, cropped *image.RGBA, orientation string) (ocrPass, bool) {
+	logoRotation := orientationToRotation(orientation)
+	rotated := component.RotateToOrientation(cropped, orientation)
+
+	rotBounds := rotated.Bounds()
+	masked := image.NewRGBA(rotBounds)
+	copy(masked.Pix, rotated.Pix)
+
+	// Detect logos and fill them
+	var detectedLogos []logo.LogoMatch
+	if cp.state.LogoLibrary != nil && len(cp.state.LogoLibrary.Logos) > 0 {
+		mw, mh := rotBounds.Dx(), rotBounds.Dy()
+		searchBounds := geometry.RectInt{X: 0, Y: 0, Width: mw, Height: mh}
+		detectedLogos = cp.state.LogoLibrary.DetectLogosWithOptions(masked, searchBounds, logoRotation, cp.logoDetectOptions(0.75))
+		if len(detectedLogos) > 0 {
+			fmt.Printf("[OCR] Detected %d logos\n", len(detectedLogos))
+			bgColor := ocr.CalculateBackgroundColor(masked)
+			compArea := mw * mh
+			decisions := logo.PlanMasks(masked, detectedLogos, cp.prefs.FloatWithFallback(prefKeyLogoMaxMask, logo.DefaultMaxMaskFraction))
+			for i, m := range detectedLogos {
+				logoArea := m.Bounds.Width * m.Bounds.Height
+				pct := logoArea * 100 / compArea
+				fmt.Printf("[OCR Logo] name=%q score=%.3f rot=%d scale=%.2f bounds=(%d,%d %dx%d) area=%d%% of component\n",
+					m.Logo.Name, m.Score, m.Rotation, m.ScaleFactor,
+					m.Bounds.X, m.Bounds.Y, m.Bounds.Width, m.Bounds.Height, pct)
+				if decisions[i] != logo.MaskOK {
+					fmt.Printf("[OCR Logo] SKIP: %s\n", decisions[i])
+					continue
+				}
+				fmt.Printf("[OCR Logo] MASK: filling (%d,%d)-(%d,%d) with bg=(%d,%d,%d)\n",
+					m.Bounds.X, m.Bounds.Y,
+					m.Bounds.X+m.Bounds.Width, m.Bounds.Y+m.Bounds.Height,
+					bgColor.R, bgColor.G, bgColor.B)
+				ocr.MaskRegion(masked, m.Bounds, bgColor)
+			}
+		}
+	}
+
+	// OCR runs on the masked image, binarized and despeckled
+	params, paramsSource := cp.ocrParamsFor(orientation)
+	fmt.Printf("[OCR] Using %s params\n", paramsSource)
+	rec, err := recognizeBinarized(engine, masked, params)
+	if err != nil {
+		fmt.Printf("[OCR] Failed: %v\n", err)
+		return ocrPass{}, false
+	}
+
+	text := fixOCRPartNumbers(rec.Text)
+
+	// Prepend detected logos
+	var detectedManufacturer string
+	if len(detectedLogos) > 0 {
+		var logoNames []string
+		for _, m := range detectedLogos {
+			logoNames = append(logoNames, fmt.Sprintf("<%s>", m.Logo.Name))
+			if detectedManufacturer == "" && m.Logo.ManufacturerID != "" {
+				detectedManufacturer = m.Logo.ManufacturerID
+			}
+		}
+		text = strings.Join(logoNames, " ") + "\n" + text
+	}
+
+	return ocrPass{
+		orientation:  orientation,
+		rotated:      rotated,
+		masked:       masked,
+		text:         text,
+		manufacturer: detectedManufacturer,
+		confidence:   rec.MeanConfidence,
+		words:        rec.Words,
+		lines:        rec.Lines,
+	}, true
+}
+
+// ocrParamsFor returns the OCR params to use for orientation and a short
+// description of where they came from. Samples from the editing
+// component's manufacturer and package are preferred when there are any.
+func (cp *ComponentsPanel) ocrParamsFor(orientation string) (ocr.OCRParams, string) {
+	if cp.state.GlobalOCRTraining != nil && len(cp.state.GlobalOCRTraining.Samples) >= 5 {
+		var mfr, pkg string
+		if cp.editingComp != nil {
+			mfr, pkg = cp.editingComp.Manufacturer, cp.editingComp.Package
+		}
+		params, source := cp.state.GlobalOCRTraining.GetParamsFor(mfr, pkg, orientation)
+		return params, fmt.Sprintf("global/%s (%d samples)", source, len(cp.state.GlobalOCRTraining.Samples))
+	}
+	return ocr.DefaultOCRParams(), "default"
 }
 
-// showOCRPreview displays a window with three processing phases.
-func (cp *ComponentsPanel) showOCRPreview(raw, masked *image.RGBA, orientation string) {
+// ocrVoteScore rates an Auto orientation reading. Tesseract's confidence
+// alone favors confident garbage from sideways text, so a reading that
+// parses as a plausible part number gets a bonus on top of it.
+func (cp *ComponentsPanel) ocrVoteScore(text string, confidence float64) float64 {
+	score := confidence / 100
+	pn := parseComponentInfo(text).PartNumber
+	if pn == "" {
+		return score
+	}
+	score += 0.25
+	if corrected, changed := component.CorrectOCRPartNumber(pn); changed {
+		pn = corrected
+	}
+	if component.ExtractLogicPart(pn) != "" ||
+		(cp.state.ComponentLibrary != nil && cp.state.ComponentLibrary.FindByPartNumber(pn) != nil) {
+		score += 0.25
+	}
+	return score
+}
+
+// showOCRPreview displays a window with three processing phases. The color
+// phase shows the pass's word and line boxes, which can be re-read singly.
+func (cp *ComponentsPanel) showOCRPreview(pass ocrPass) {
+	raw, masked, orientation := pass.rotated, pass.masked, pass.orientation
 	w, h := raw.Bounds().Dx(), raw.Bounds().Dy()
 	fmt.Printf("[OCR Preview] %dx%d orientation=%s\n", w, h, orientation)
+	params, _ := cp.ocrParamsFor(orientation)
 
 	// Helper: scale an RGBA image to 2x NRGBA
 	scale2x := func(src *image.RGBA) *image.NRGBA {
@@ -1072,10 +1687,10 @@ func (cp *ComponentsPanel) showOCRPreview(raw, masked *image.RGBA, orientation s
 		return out
 	}
 
-	// Helper: Otsu threshold an RGBA image to 2x B&W NRGBA
-	otsuBW := func(src *image.RGBA) (*image.NRGBA, uint8) {
+	// Helper: binarize an RGBA image as OCR does, to 2x B&W NRGBA
+	binarizeBW := func(src *image.RGBA) (*image.NRGBA, string) {
 		gray, sw, sh := rgbaToGray(src)
-		thresh := robustOtsu(gray, sw, sh)
+		thresh, method := ocr.BinarizeThreshold(gray, sw, sh, params)
 		bw := make([]bool, sw*sh)
 		for i := 0; i < sw*sh; i++ {
 			bw[i] = gray[i] > thresh
@@ -1101,14 +1716,14 @@ func (cp *ComponentsPanel) showOCRPreview(raw, masked *image.RGBA, orientation s
 				}
 			}
 		}
-		return out, thresh
+		return out, method
 	}
 
 	rawScaled := scale2x(raw)
-	rawBW, rawThresh := otsuBW(raw)
-	fmt.Printf("[OCR Preview] Raw Otsu threshold: %d\n", rawThresh)
-	maskedBW, maskedThresh := otsuBW(masked)
-	fmt.Printf("[OCR Preview] Masked Otsu threshold: %d\n", maskedThresh)
+	rawBW, rawMethod := binarizeBW(raw)
+	fmt.Printf("[OCR Preview] Raw threshold: %s\n", rawMethod)
+	maskedBW, maskedMethod := binarizeBW(masked)
+	fmt.Printf("[OCR Preview] Masked threshold: %s\n", maskedMethod)
 
 	// Build GTK preview window
 	title := fmt.Sprintf("OCR Preview — %s (%s)", cp.editingComp.ID, orientation)
@@ -1123,36 +1738,76 @@ func (cp *ComponentsPanel) showOCRPreview(raw, masked *image.RGBA, orientation s
 	content.SetMarginTop(8)
 	content.SetMarginBottom(8)
 
-	addImage := func(label string, img *image.NRGBA) {
-		lbl, _ := gtk.LabelNew(label)
-		lbl.SetHAlign(gtk.ALIGN_START)
-		content.PackStart(lbl, false, false, 0)
+	reread := cp.newOCRRereadControls()
 
+	newImage := func(img *image.NRGBA) *gtk.Image {
 		iw, ih := img.Bounds().Dx(), img.Bounds().Dy()
 		pixbuf, err := gdk.PixbufNew(gdk.COLORSPACE_RGB, true, 8, iw, ih)
-		if err == nil {
-			pixels := pixbuf.GetPixels()
-			stride := pixbuf.GetRowstride()
-			for y := 0; y < ih; y++ {
-				for x := 0; x < iw; x++ {
-					si := y*img.Stride + x*4
-					di := y*stride + x*4
-					if di+3 < len(pixels) && si+3 < len(img.Pix) {
-						pixels[di] = img.Pix[si]
-						pixels[di+1] = img.Pix[si+1]
-						pixels[di+2] = img.Pix[si+2]
-						pixels[di+3] = img.Pix[si+3]
-					}
+		if err != nil {
+			return nil
+		}
+		pixels := pixbuf.GetPixels()
+		stride := pixbuf.GetRowstride()
+		for y := 0; y < ih; y++ {
+			for x := 0; x < iw; x++ {
+				si := y*img.Stride + x*4
+				di := y*stride + x*4
+				if di+3 < len(pixels) && si+3 < len(img.Pix) {
+					pixels[di] = img.Pix[si]
+					pixels[di+1] = img.Pix[si+1]
+					pixels[di+2] = img.Pix[si+2]
+					pixels[di+3] = img.Pix[si+3]
 				}
 			}
-			gtkImg, _ := gtk.ImageNewFromPixbuf(pixbuf)
+		}
+		gtkImg, _ := gtk.ImageNewFromPixbuf(pixbuf)
+		return gtkImg
+	}
+	addImage := func(label string, img *image.NRGBA) {
+		lbl, _ := gtk.LabelNew(label)
+		lbl.SetHAlign(gtk.ALIGN_START)
+		content.PackStart(lbl, false, false, 0)
+		if gtkImg := newImage(img); gtkImg != nil {
 			content.PackStart(gtkImg, false, false, 0)
 		}
 	}
 
-	addImage("Color", rawScaled)
-	addImage(fmt.Sprintf("B&W (Otsu %d)", rawThresh), rawBW)
-	addImage(fmt.Sprintf("Logo Masked B&W (Otsu %d)", maskedThresh), maskedBW)
+	// Color image with the word (green, orange when doubtful) and line
+	// (blue) boxes; clicking a box re-reads just that part
+	boxed := image.NewNRGBA(rawScaled.Bounds())
+	copy(boxed.Pix, rawScaled.Pix)
+	for _, l := range pass.lines {
+		drawOCRBox(boxed, l.Bounds, color.NRGBA{R: 60, G: 120, B: 255, A: 255})
+	}
+	for _, wd := range pass.words {
+		col := color.NRGBA{G: 200, A: 255}
+		if wd.Confidence < ocr.LowConfidence {
+			col = color.NRGBA{R: 255, G: 120, A: 255}
+		}
+		drawOCRBox(boxed, wd.Bounds, col)
+	}
+	colorLbl, _ := gtk.LabelNew("Color (click a word or line box to re-read it)")
+	colorLbl.SetHAlign(gtk.ALIGN_START)
+	content.PackStart(colorLbl, false, false, 0)
+	if gtkImg := newImage(boxed); gtkImg != nil {
+		eb, _ := gtk.EventBoxNew()
+		eb.SetHAlign(gtk.ALIGN_START)
+		eb.Add(gtkImg)
+		eb.Connect("button-press-event", func(_ *gtk.EventBox, ev *gdk.Event) bool {
+			btn := gdk.EventButtonNewFromEvent(ev)
+			// The preview is shown at 2x
+			if box, ok := ocrBoxAt(pass, btn.X()/2, btn.Y()/2); ok {
+				cp.rereadOCRBox(pass, box, reread)
+			}
+			return true
+		})
+		content.PackStart(eb, false, false, 0)
+	}
+	content.PackStart(reread.row, false, false, 0)
+	content.PackStart(reread.result, false, false, 0)
+
+	addImage(fmt.Sprintf("B&W (%s)", rawMethod), rawBW)
+	addImage(fmt.Sprintf("Logo Masked B&W (%s)", maskedMethod), maskedBW)
 
 	sizeLabel, _ := gtk.LabelNew(fmt.Sprintf("%dx%d", w, h))
 	content.PackStart(sizeLabel, false, false, 0)
@@ -1169,6 +1824,131 @@ func (cp *ComponentsPanel) showOCRPreview(raw, masked *image.RGBA, orientation s
 	}()
 }
 
+// ocrRereadControls are the OCR preview's widgets for re-reading one word
+// or line box on its own.
+type ocrRereadControls struct {
+	row              *gtk.Box
+	params           *gtk.ComboBoxText
+	result           *gtk.Label
+	apply            *gtk.Button
+	oldText, newText string
+}
+
+// newOCRRereadControls builds the params selector, result label and Apply
+// button for re-reading a box. Apply replaces the box's text in the OCR text
+// of the component being edited.
+func (cp *ComponentsPanel) newOCRRereadControls() *ocrRereadControls {
+	rc := &ocrRereadControls{}
+	rc.row, _ = gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	lbl, _ := gtk.LabelNew("Re-read with:")
+	rc.row.PackStart(lbl, false, false, 0)
+
+	rc.params, _ = gtk.ComboBoxTextNew()
+	rc.params.Append("trained", "Trained params")
+	rc.params.Append("line", "Trained, single line")
+	rc.params.Append("word", "Trained, single word")
+	rc.params.Append("default", "Default params")
+	rc.params.SetActiveID("line")
+	rc.row.PackStart(rc.params, false, false, 0)
+
+	rc.apply, _ = gtk.ButtonNewWithLabel("Apply")
+	rc.apply.SetSensitive(false)
+	comp := cp.editingComp
+	rc.apply.Connect("clicked", func() {
+		if cp.editingComp != comp || rc.newText == "" {
+			return
+		}
+		text := getTextViewText(cp.ocrTextEntry)
+		if !strings.Contains(text, rc.oldText) {
+			fmt.Printf("[OCR] %q no longer in the OCR text\n", rc.oldText)
+			return
+		}
+		text = strings.Replace(text, rc.oldText, rc.newText, 1)
+		setTextViewText(cp.ocrTextEntry, text)
+		comp.OCRText = text
+		cp.state.SetModified(true)
+		rc.oldText = rc.newText
+		rc.apply.SetSensitive(false)
+	})
+	rc.row.PackStart(rc.apply, false, false, 0)
+
+	rc.result, _ = gtk.LabelNew("")
+	rc.result.SetHAlign(gtk.ALIGN_START)
+	rc.result.SetSelectable(true)
+	return rc
+}
+
+// rereadOCRBox runs OCR on just box (plus a small margin) of the pass's
+// logo-masked image with the params chosen in rc, and shows the reading.
+func (cp *ComponentsPanel) rereadOCRBox(pass ocrPass, box ocr.WordConfidence, rc *ocrRereadControls) {
+	params, _ := cp.ocrParamsFor(pass.orientation)
+	switch rc.params.GetActiveID() {
+	case "default":
+		params = ocr.DefaultOCRParams()
+	case "line":
+		params.PSMMode = 7 // PSM_SINGLE_LINE
+	case "word":
+		params.PSMMode = 8 // PSM_SINGLE_WORD
+	}
+
+	pad := max(4, box.Bounds.Height/4)
+	r := image.Rect(box.Bounds.X-pad, box.Bounds.Y-pad,
+		box.Bounds.X+box.Bounds.Width+pad, box.Bounds.Y+box.Bounds.Height+pad).Intersect(pass.masked.Bounds())
+	if r.Dx() < 8 || r.Dy() < 8 {
+		rc.result.SetText("Box too small to re-read")
+		return
+	}
+	sub := pass.masked.SubImage(r).(*image.RGBA)
+
+	pool := ocr.SharedEnginePool()
+	engine, err := pool.Acquire()
+	if err != nil {
+		rc.result.SetText(fmt.Sprintf("OCR engine: %v", err))
+		return
+	}
+	defer pool.Release(engine)
+
+	rec, err := recognizeBinarized(engine, sub, params)
+	if err != nil {
+		rc.result.SetText(fmt.Sprintf("Re-read failed: %v", err))
+		return
+	}
+	text := fixOCRPartNumbers(rec.Text)
+	fmt.Printf("[OCR] Re-read %q (%s) -> %q conf=%.1f\n", box.Text, rc.params.GetActiveID(), text, rec.MeanConfidence)
+
+	rc.oldText, rc.newText = box.Text, text
+	rc.result.SetText(fmt.Sprintf("%q → %q (confidence %.0f%%)", box.Text, text, rec.MeanConfidence))
+	rc.apply.SetSensitive(text != "" && text != box.Text)
+}
+
+// ocrBoxAt returns the word box containing (x, y) in the pass's rotated
+// image, or else the line box containing it.
+func ocrBoxAt(pass ocrPass, x, y float64) (ocr.WordConfidence, bool) {
+	p := geometry.Point2D{X: x, Y: y}
+	for _, boxes := range [][]ocr.WordConfidence{pass.words, pass.lines} {
+		for _, b := range boxes {
+			if b.Bounds.ToFloat().Contains(p) {
+				return b, true
+			}
+		}
+	}
+	return ocr.WordConfidence{}, false
+}
+
+// drawOCRBox outlines r, given in preview source pixels, on the 2x preview.
+func drawOCRBox(img *image.NRGBA, r geometry.RectInt, col color.NRGBA) {
+	x0, y0 := r.X*2, r.Y*2
+	x1, y1 := (r.X+r.Width)*2-1, (r.Y+r.Height)*2-1
+	for x := x0; x <= x1; x++ {
+		img.SetNRGBA(x, y0, col)
+		img.SetNRGBA(x, y1, col)
+	}
+	for y := y0; y <= y1; y++ {
+		img.SetNRGBA(x0, y, col)
+		img.SetNRGBA(x1, y, col)
+	}
+}
+
 // runOCRTraining adds the current component image + corrected text as a training sample.
 // Uses current best params to run OCR once, scores the result, and stores the sample.
 func (cp *ComponentsPanel) runOCRTraining() {
@@ -1189,33 +1969,22 @@ func (cp *ComponentsPanel) runOCRTraining() {
 		return
 	}
 
-	bounds := cp.editingComp.Bounds
-	x, y := int(bounds.X), int(bounds.Y)
-	w, h := int(bounds.Width), int(bounds.Height)
-
-	imgBounds := img.Bounds()
-	if x < imgBounds.Min.X {
-		x = imgBounds.Min.X
-	}
-	if y < imgBounds.Min.Y {
-		y = imgBounds.Min.Y
-	}
-	w = min(w, imgBounds.Max.X-x)
-	h = min(h, imgBounds.Max.Y-y)
-	if w <= 0 || h <= 0 {
+	cropped := cropComponent(img, cp.editingComp)
+	if cropped == nil {
 		return
 	}
 
-	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			cropped.Set(dx, dy, img.At(x+dx, y+dy))
+	orientation := cp.getSelectedOrientation()
+	if orientation == ocrOrientationAuto {
+		// Train against the orientation Auto last settled on
+		orientation = cp.editingComp.OCROrientation
+		if orientation == "" {
+			orientation = "N"
 		}
 	}
-
-	orientation := cp.getSelectedOrientation()
 	logoRotation := orientationToRotation(orientation)
-	cp.trainLogoDetection(cropped, w, h, groundTruth, logoRotation)
+	cb := cropped.Bounds()
+	cp.trainLogoDetection(cropped, cb.Dx(), cb.Dy(), groundTruth, logoRotation)
 
 	comp := cp.editingComp
 	compID := comp.ID
@@ -1223,15 +1992,14 @@ func (cp *ComponentsPanel) runOCRTraining() {
 	// Get current best params from training DB (or defaults)
 	params := cp.state.GetRecommendedOCRParams()
 	if cp.state.GlobalOCRTraining != nil {
-		if p, ok := cp.state.GlobalOCRTraining.GetParamsForOrientation(orientation); ok {
-			params = p
-		}
+		params, _ = cp.state.GlobalOCRTraining.GetParamsFor(comp.Manufacturer, comp.Package, orientation)
 	}
+	mfr, pkg := comp.Manufacturer, comp.Package
 
 	fmt.Printf("[OCR Train] %s: adding training sample (orientation %s)\n", compID, orientation)
 
 	go func() {
-		rotated := rotateForOCR(cropped, orientation)
+		rotated := component.RotateToOrientation(cropped, orientation)
 		rotBounds := rotated.Bounds()
 		mat, err := gocv.NewMatFromBytes(rotBounds.Dy(), rotBounds.Dx(), gocv.MatTypeCV8UC4, rotated.Pix)
 		if err != nil {
@@ -1244,12 +2012,13 @@ func (cp *ComponentsPanel) runOCRTraining() {
 		defer bgr.Close()
 		gocv.CvtColor(mat, &bgr, gocv.ColorRGBAToBGR)
 
-		engine, err := ocr.NewEngine()
+		pool := ocr.SharedEnginePool()
+		engine, err := pool.Acquire()
 		if err != nil {
 			fmt.Printf("[OCR Train] %s: failed to create engine: %v\n", compID, err)
 			return
 		}
-		defer engine.Close()
+		defer pool.Release(engine)
 
 		// Run OCR once with current best params
 		ocrText, _ := engine.RecognizeWithParams(bgr, params)
@@ -1257,7 +2026,7 @@ func (cp *ComponentsPanel) runOCRTraining() {
 		fmt.Printf("[OCR Train] %s: score=%.1f%% text=%q\n", compID, score*100, ocrText)
 
 		// Always add — the ground truth is known, that's the whole point
-		cp.state.AddOCRTrainingSample(groundTruth, ocrText, score, orientation, params)
+		cp.state.AddOCRTrainingSample(groundTruth, ocrText, score, orientation, params, mfr, pkg)
 		fmt.Printf("[OCR Train] %s: added to training database\n", compID)
 
 		glib.IdleAdd(func() {
@@ -1300,19 +2069,149 @@ func (cp *ComponentsPanel) runOCRTraining() {
 		info.PartNumber, info.Manufacturer, info.DateCode, info.Place)
 }
 
+// trainAllMaxIterations caps the annealing search for each component in
+// Train All; trainAllMinScore is the lowest result kept, as in ocrtrain.
+const (
+	trainAllMaxIterations = 50000
+	trainAllMinScore      = 0.7
+)
+
+// trainAllJob is one component's input to Train All, captured on the GTK
+// thread.
+type trainAllJob struct {
+	id, groundTruth, orientation string
+	mfr, pkg                     string
+	cropped                      *image.RGBA
+}
+
+// onTrainAll anneals OCR params for every component with corrected text,
+// one component at a time with a worker per CPU, and adds each result
+// scoring at least trainAllMinScore to the global training database.
+// Progress shows in the training label; Stop keeps the samples so far.
+func (cp *ComponentsPanel) onTrainAll() {
+	var jobs []trainAllJob
+	for _, comp := range cp.state.Components {
+		groundTruth := strings.TrimSpace(comp.CorrectedText)
+		if groundTruth == "" {
+			continue
+		}
+		layer := cp.state.FrontImage
+		if comp.Layer == pcbimage.SideBack {
+			layer = cp.state.BackImage
+		}
+		if layer == nil || layer.Image == nil {
+			fmt.Printf("[OCR Train] %s: no %s image\n", comp.ID, comp.Layer)
+			continue
+		}
+		cropped := cropComponent(layer.Image, comp)
+		if cropped == nil {
+			continue
+		}
+		orientation := comp.OCROrientation
+		if orientation == "" {
+			orientation = "N"
+		}
+		jobs = append(jobs, trainAllJob{
+			id:          comp.ID,
+			groundTruth: groundTruth,
+			orientation: orientation,
+			mfr:         comp.Manufacturer,
+			pkg:         comp.Package,
+			cropped:     cropped,
+		})
+	}
+	if len(jobs) == 0 {
+		fmt.Println("[OCR Train] No components with corrected text to train")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cp.trainAllCancel = cancel
+	cp.trainAllBtn.SetSensitive(false)
+	cp.stopTrainBtn.SetSensitive(true)
+	fmt.Printf("[OCR Train] Training %d components\n", len(jobs))
+
+	go func() {
+		added := 0
+		done := 0
+		for i, job := range jobs {
+			if ctx.Err() != nil {
+				break
+			}
+			glib.IdleAdd(func() {
+				cp.ocrTrainingLabel.SetText(fmt.Sprintf("Training %d/%d: %s", i+1, len(jobs), job.id))
+			})
+
+			params, score, text, err := annealComponent(ctx, job)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				fmt.Printf("[OCR Train] %s: %v\n", job.id, err)
+				continue
+			}
+			done++
+			fmt.Printf("[OCR Train] %s: best score=%.1f%% text=%q\n", job.id, score*100, text)
+			if score >= trainAllMinScore {
+				cp.state.AddOCRTrainingSample(job.groundTruth, text, score, job.orientation, params, job.mfr, job.pkg)
+				added++
+			}
+		}
+
+		glib.IdleAdd(func() {
+			cancel()
+			cp.trainAllCancel = nil
+			cp.trainAllBtn.SetSensitive(true)
+			cp.stopTrainBtn.SetSensitive(false)
+			if ctx.Err() != nil {
+				fmt.Printf("[OCR Train] Stopped after %d of %d components\n", done, len(jobs))
+			}
+			fmt.Printf("[OCR Train] Train All added %d samples\n", added)
+			cp.updateOCRTrainingLabel()
+		})
+	}()
+}
+
+// annealComponent searches OCR params for one Train All job. On
+// cancellation it returns the best result so far with ctx.Err().
+func annealComponent(ctx context.Context, job trainAllJob) (ocr.OCRParams, float64, string, error) {
+	rotated := component.RotateToOrientation(job.cropped, job.orientation)
+	rotBounds := rotated.Bounds()
+	mat, err := gocv.NewMatFromBytes(rotBounds.Dy(), rotBounds.Dx(), gocv.MatTypeCV8UC4, rotated.Pix)
+	if err != nil {
+		return ocr.OCRParams{}, 0, "", fmt.Errorf("failed to convert image: %w", err)
+	}
+	defer mat.Close()
+
+	bgr := gocv.NewMat()
+	defer bgr.Close()
+	gocv.CvtColor(mat, &bgr, gocv.ColorRGBAToBGR)
+
+	return ocr.AnnealParallel(ctx, bgr, job.groundTruth, trainAllMaxIterations, runtime.NumCPU())
+}
+
+// logoDetectOptions returns the logo scale sweep from the preferences with
+// the given minimum score. It reads prefs rather than the spin buttons so
+// OCR workers can call it off the GTK thread.
+func (cp *ComponentsPanel) logoDetectOptions(minConfidence float64) logo.DetectOptions {
+	opts := logo.DefaultDetectOptions()
+	opts.MinScale = cp.prefs.FloatWithFallback(prefKeyLogoMinScale, opts.MinScale)
+	opts.MaxScale = cp.prefs.FloatWithFallback(prefKeyLogoMaxScale, opts.MaxScale)
+	opts.ScaleSteps = cp.prefs.FloatWithFallback(prefKeyLogoScaleSteps, opts.ScaleSteps)
+	opts.MinConfidence = minConfidence
+	return opts
+}
+
 // trainLogoDetection compares detected logos to ground truth.
 func (cp *ComponentsPanel) trainLogoDetection(cropped *image.RGBA, w, h int, groundTruth string, rotation int) {
 	if cp.state.LogoLibrary == nil || len(cp.state.LogoLibrary.Logos) == 0 {
 		return
 	}
-	expectedLogos := extractLogoNames(groundTruth)
+	expectedLogos := logo.NamesInText(groundTruth)
 	if len(expectedLogos) == 0 {
 		return
 	}
 	fmt.Printf("[Logo Train] Expected: %v\n", expectedLogos)
 
 	searchBounds := geometry.RectInt{X: 0, Y: 0, Width: w, Height: h}
-	detectedMatches := cp.state.LogoLibrary.DetectLogos(cropped, searchBounds, 0.70, rotation)
+	detectedMatches := cp.state.LogoLibrary.DetectLogosWithOptions(cropped, searchBounds, rotation, cp.logoDetectOptions(0.70))
 	detectedLogos := make(map[string]logo.LogoMatch)
 	for _, m := range detectedMatches {
 		detectedLogos[m.Logo.Name] = m
@@ -1375,7 +2274,9 @@ func (cp *ComponentsPanel) highlightComponent(index int, scrollToView bool) {
 	}
 }
 
-// OnKeyPressed handles keyboard input for component adjustment.
+// OnKeyPressed handles keyboard input for component adjustment. R cycles the
+// OCR orientation and re-runs OCR; [ and ] turn the component by 1° ({ and }
+// by 15°).
 func (cp *ComponentsPanel) OnKeyPressed(ev *gdk.EventKey) bool {
 	if cp.editingIndex < 0 || cp.editingIndex >= len(cp.state.Components) {
 		return false
@@ -1393,6 +2294,33 @@ func (cp *ComponentsPanel) OnKeyPressed(ev *gdk.EventKey) bool {
 	comp := cp.state.Components[cp.editingIndex]
 	keyval := ev.KeyVal()
 
+	if (keyval == gdk.KEY_r || keyval == gdk.KEY_R) && !cp.textInputFocused() {
+		cp.cycleOCROrientation()
+		return true
+	}
+
+	if !cp.textInputFocused() {
+		turn := 0.0
+		switch keyval {
+		case gdk.KEY_bracketleft:
+			turn = -1
+		case gdk.KEY_bracketright:
+			turn = 1
+		case gdk.KEY_braceleft:
+			turn = -15
+		case gdk.KEY_braceright:
+			turn = 15
+		}
+		if turn != 0 {
+			comp.Rotate(turn)
+			cp.state.SetModified(true)
+			cp.updateComponentOverlay()
+			cp.updatePreviewImage()
+			fmt.Printf("Rotated component %s to %.0f° (tilt %.0f°)\n", comp.ID, comp.Rotation, comp.Tilt())
+			return true
+		}
+	}
+
 	switch keyval {
 	case gdk.KEY_Up, gdk.KEY_KP_Up:
 		comp.Bounds.Y -= step
@@ -1442,8 +2370,7 @@ func (cp *ComponentsPanel) frontLayerOffset() (float64, float64) {
 func (cp *ComponentsPanel) onRightClickDeleteComponent(x, y float64) {
 	offX, offY := cp.frontLayerOffset()
 	for i, comp := range cp.state.Components {
-		if x >= comp.Bounds.X+offX && x <= comp.Bounds.X+offX+comp.Bounds.Width &&
-			y >= comp.Bounds.Y+offY && y <= comp.Bounds.Y+offY+comp.Bounds.Height {
+		if comp.Contains(geometry.Point2D{X: x - offX, Y: y - offY}) {
 			cp.deleteComponent(i)
 			return
 		}
@@ -1584,6 +2511,7 @@ func (cp *ComponentsPanel) OnLeftClick(x, y float64) {
 		},
 		Confirmed: true,
 	}
+	newComp.OCROrientation = component.NearestOrientation(cp.state.Components, newComp.Center())
 
 	cp.state.Components = append(cp.state.Components, newComp)
 	cp.state.SetModified(true)
@@ -1668,6 +2596,25 @@ func (cp *ComponentsPanel) DeselectComponent() {
 	cp.updateComponentOverlay()
 }
 
+// floodOptions returns the flood fill settings chosen in the panel.
+func (cp *ComponentsPanel) floodOptions() component.FloodOptions {
+	opts := component.DefaultFloodOptions()
+	switch cp.floodSpaceCombo.GetActiveID() {
+	case component.ColorSpaceRGB.String():
+		opts.Space = component.ColorSpaceRGB
+	case component.ColorSpaceLab.String():
+		opts.Space = component.ColorSpaceLab
+	case component.ColorSpaceHSV.String():
+		opts.Space = component.ColorSpaceHSV
+	}
+	opts.Tolerance = int(cp.floodTolScale.GetValue())
+	opts.Connectivity = 4
+	if cp.floodDiagCheck.GetActive() {
+		opts.Connectivity = 8
+	}
+	return opts
+}
+
 // OnMiddleClickFloodFill handles middle-click for flood fill component detection.
 func (cp *ComponentsPanel) OnMiddleClickFloodFill(x, y float64) {
 	img := cp.canvas.GetRenderedOutput()
@@ -1677,21 +2624,25 @@ func (cp *ComponentsPanel) OnMiddleClickFloodFill(x, y float64) {
 	}
 
 	clickX, clickY := int(x), int(y)
-	const colorTolerance = 25
+	opts := cp.floodOptions()
 
-	fmt.Printf("Middle-click flood fill at canvas (%d, %d)\n", clickX, clickY)
+	fmt.Printf("Middle-click flood fill at canvas (%d, %d), %s tolerance %d\n",
+		clickX, clickY, opts.Space, opts.Tolerance)
 
-	result, err := component.FloodFillDetect(img, clickX, clickY, colorTolerance)
+	result, err := component.FloodFillDetect(img, clickX, clickY, opts)
 	if err != nil {
 		fmt.Printf("Flood fill failed: %v\n", err)
 		return
 	}
 
-	const gridStep = 3
-	const minScore = 0.25
+	gridStep := int(cp.gridStepSpin.GetValue())
+	minScore := cp.minScoreSpin.GetValue()
 
-	gridScores := component.GetGridScores(img, result.Bounds, result.SeedColor, colorTolerance, gridStep, minScore)
+	gridScores := component.GetGridScores(img, result.Bounds, result.SeedColor, opts, gridStep, minScore)
 	trimmedBounds := gridScores.TrimBounds
+	if cp.gridDebugCheck.GetActive() {
+		cp.showGridDebugOverlay(gridScores)
+	}
 
 	zoom := cp.canvas.GetZoom()
 
@@ -1730,6 +2681,7 @@ func (cp *ComponentsPanel) OnMiddleClickFloodFill(x, y float64) {
 		},
 		Confirmed: true,
 	}
+	newComp.OCROrientation = component.NearestOrientation(cp.state.Components, newComp.Center())
 
 	cp.state.Components = append(cp.state.Components, newComp)
 	cp.state.SetModified(true)
@@ -1801,6 +2753,14 @@ func (cp *ComponentsPanel) showGridDebugOverlay(gridScores *component.GridScoreR
 	cp.canvas.SetOverlay("grid_trimmed", trimmedOutOverlay)
 }
 
+// clearGridDebugOverlay removes the grid scoring overlays.
+func (cp *ComponentsPanel) clearGridDebugOverlay() {
+	cp.canvas.SetOverlay("grid_match", nil)
+	cp.canvas.SetOverlay("grid_nomatch", nil)
+	cp.canvas.SetOverlay("grid_trimmed", nil)
+	cp.canvas.Refresh()
+}
+
 // updateComponentOverlay refreshes the component overlay on the canvas.
 func (cp *ComponentsPanel) updateComponentOverlay() {
 	if len(cp.state.Components) == 0 {
@@ -1814,6 +2774,18 @@ func (cp *ComponentsPanel) updateComponentOverlay() {
 		Layer: canvas.LayerFront,
 	}
 	for i, comp := range cp.state.Components {
+		var highlight *color.RGBA
+		if i == cp.editingIndex {
+			highlight = &magenta
+		}
+		if comp.Tilt() != 0 {
+			overlay.Polygons = append(overlay.Polygons, canvas.OverlayPolygon{
+				Points: comp.Corners(),
+				Label:  comp.ID + " " + comp.Package,
+				Color:  highlight,
+			})
+			continue
+		}
 		rect := canvas.OverlayRect{
 			X:      int(comp.Bounds.X),
 			Y:      int(comp.Bounds.Y),
@@ -1821,9 +2793,7 @@ func (cp *ComponentsPanel) updateComponentOverlay() {
 			Height: int(comp.Bounds.Height),
 			Label:  comp.ID + " " + comp.Package,
 			Fill:   canvas.FillNone,
-		}
-		if i == cp.editingIndex {
-			rect.Color = &magenta
+			Color:  highlight,
 		}
 		overlay.Rectangles = append(overlay.Rectangles, rect)
 	}
@@ -1831,6 +2801,139 @@ func (cp *ComponentsPanel) updateComponentOverlay() {
 	cp.canvas.Refresh()
 }
 
+// onExportBOM writes confirmed components to a CSV bill of materials.
+func (cp *ComponentsPanel) onExportBOM() {
+	dpi := cp.state.DPIForSide(pcbimage.SideFront)
+	if dpi <= 0 {
+		fmt.Println("[BOM] Cannot export: DPI unknown")
+		return
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export BOM", cp.win, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Save", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	dlg.SetDoOverwriteConfirmation(true)
+	dlg.SetCurrentName("bom.csv")
+	if cp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(cp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+
+	f, err := os.Create(path)
+	if err == nil {
+		err = component.ExportBOM(cp.state.Components, dpi, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Printf("[BOM] Export failed: %v\n", err)
+		return
+	}
+	fmt.Printf("[BOM] Exported to %s\n", path)
+}
+
+// onExportCrops writes every confirmed component's image to a chosen folder
+// as a PNG gallery with an index page.
+func (cp *ComponentsPanel) onExportCrops() {
+	var frontImg, backImg image.Image
+	if cp.state.FrontImage != nil {
+		frontImg = cp.state.FrontImage.Image
+	}
+	if cp.state.BackImage != nil {
+		backImg = cp.state.BackImage.Image
+	}
+	if frontImg == nil && backImg == nil {
+		fmt.Println("[Crops] Cannot export: no images loaded")
+		return
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export Component Crops", cp.win, gtk.FILE_CHOOSER_ACTION_SELECT_FOLDER,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Export", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	if cp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(cp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	dir := dlg.GetFilename()
+
+	// Layer images are already cropped, so component bounds need no offset
+	if err := component.ExportCrops(cp.state.Components, frontImg, backImg, nil, nil, dir); err != nil {
+		fmt.Printf("[Crops] Export finished with errors: %v\n", err)
+	}
+	fmt.Printf("[Crops] Exported to %s\n", filepath.Join(dir, "index.html"))
+}
+
+// onImportPlacement adds components from a CSV placement list. Imported IDs
+// that clash with existing components are renumbered; existing IDs are kept.
+func (cp *ComponentsPanel) onImportPlacement() {
+	dpi := cp.state.DPIForSide(pcbimage.SideFront)
+	if dpi <= 0 {
+		fmt.Println("[Placement] Cannot import: DPI unknown")
+		return
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Import Placement", cp.win, gtk.FILE_CHOOSER_ACTION_OPEN,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Open", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	filter, _ := gtk.FileFilterNew()
+	filter.SetName("CSV files")
+	filter.AddPattern("*.csv")
+	dlg.AddFilter(filter)
+	if cp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(cp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("[Placement] %v\n", err)
+		return
+	}
+	imported, err := component.ImportPlacement(f, dpi)
+	f.Close()
+	if err != nil {
+		fmt.Printf("[Placement] %s: %v\n", filepath.Base(path), err)
+		return
+	}
+	if len(imported) == 0 {
+		fmt.Printf("[Placement] %s: no components\n", filepath.Base(path))
+		return
+	}
+
+	for _, c := range imported {
+		if c.ID == "" {
+			center := c.Center()
+			c.ID = component.SuggestComponentID(cp.state.Components, center.X, center.Y, 100, "U")
+		}
+		cp.state.Components = append(cp.state.Components, c)
+	}
+	renamed := component.RenumberDuplicateIDs(cp.state.Components, 100)
+	for idx, id := range renamed {
+		fmt.Printf("[Placement] Renumbered conflicting component %d -> %s\n", idx, id)
+	}
+	fmt.Printf("[Placement] Imported %d components from %s\n", len(imported), filepath.Base(path))
+	cp.state.SetModified(true)
+	cp.state.Emit(app.EventComponentsChanged, nil)
+}
+
 // onDetectComponents detects components using global training data plus any on this board.
 func (cp *ComponentsPanel) onDetectComponents() {
 	if cp.state.FrontImage == nil || cp.state.FrontImage.Image == nil {
@@ -2116,49 +3219,10 @@ func (cp *ComponentsPanel) onDetectComponents() {
 		return
 	}
 
-	// Filter out detections that overlap existing components.
-	// Check both directions: new center in existing bounds, existing center in new bounds,
-	// and significant bounding box intersection (>25% of either area).
+	// Filter out detections that overlap existing components
 	var newBounds []geometry.Rect
 	for _, db := range detectedBounds {
-		dbCX := db.X + db.Width/2
-		dbCY := db.Y + db.Height/2
-
-		overlaps := false
-		for _, existing := range cp.state.Components {
-			eb := existing.Bounds
-			ebCX := eb.X + eb.Width/2
-			ebCY := eb.Y + eb.Height/2
-
-			// New center inside existing bounds
-			if dbCX >= eb.X && dbCX <= eb.X+eb.Width &&
-				dbCY >= eb.Y && dbCY <= eb.Y+eb.Height {
-				overlaps = true
-				break
-			}
-			// Existing center inside new bounds
-			if ebCX >= db.X && ebCX <= db.X+db.Width &&
-				ebCY >= db.Y && ebCY <= db.Y+db.Height {
-				overlaps = true
-				break
-			}
-			// Bounding box intersection area > 25% of either
-			ix0 := math.Max(db.X, eb.X)
-			iy0 := math.Max(db.Y, eb.Y)
-			ix1 := math.Min(db.X+db.Width, eb.X+eb.Width)
-			iy1 := math.Min(db.Y+db.Height, eb.Y+eb.Height)
-			if ix1 > ix0 && iy1 > iy0 {
-				interArea := (ix1 - ix0) * (iy1 - iy0)
-				dbArea := db.Width * db.Height
-				ebArea := eb.Width * eb.Height
-				if dbArea > 0 && interArea/dbArea > 0.25 ||
-					ebArea > 0 && interArea/ebArea > 0.25 {
-					overlaps = true
-					break
-				}
-			}
-		}
-		if !overlaps {
+		if !cp.overlapsExistingComponent(db) {
 			newBounds = append(newBounds, db)
 		}
 	}
@@ -2186,23 +3250,249 @@ func (cp *ComponentsPanel) onDetectComponents() {
 }
 
 
-// onOCRSilkscreen runs OCR on the silkscreen to find component labels.
-func (cp *ComponentsPanel) onOCRSilkscreen() {
+// overlapsExistingComponent reports whether b would duplicate a component
+// already on the board.
+func (cp *ComponentsPanel) overlapsExistingComponent(b geometry.Rect) bool {
+	for _, existing := range cp.state.Components {
+		if component.BoundsOverlap(b, existing.Bounds) {
+			return true
+		}
+	}
+	return false
+}
+
+// onDetectAllComponents proposes unconfirmed components for every IC body
+// on the front image that matches the global training samples.
+func (cp *ComponentsPanel) onDetectAllComponents() {
 	if cp.state.FrontImage == nil || cp.state.FrontImage.Image == nil {
-		fmt.Println("No front image loaded for OCR")
+		fmt.Println("[Detect] No front image loaded")
+		return
+	}
+	ts := cp.state.GlobalComponentTraining
+	if ts == nil || len(ts.Samples) == 0 {
+		fmt.Println("[Detect] No training data available — use Train Components first")
+		return
+	}
+	dpi := cp.state.DPIForSide(pcbimage.SideFront)
+	if dpi <= 0 {
+		fmt.Println("[Detect] Cannot detect: DPI unknown")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cp.detectAllCancel = cancel
+	cp.detectAllBtn.SetSensitive(false)
+	cp.stopDetectBtn.SetSensitive(true)
+	img := cp.state.FrontImage.Image
+
+	go func() {
+		comps, err := component.DetectAll(ctx, img, dpi, ts)
+		glib.IdleAdd(func() {
+			cancel()
+			cp.detectAllCancel = nil
+			cp.detectAllBtn.SetSensitive(true)
+			cp.stopDetectBtn.SetSensitive(false)
+
+			if errors.Is(err, context.Canceled) {
+				fmt.Println("[Detect] Stopped; keeping components found so far")
+			} else if err != nil {
+				fmt.Printf("[Detect] %v\n", err)
+				return
+			}
+			added := 0
+			for _, c := range comps {
+				if cp.overlapsExistingComponent(c.Bounds) {
+					continue
+				}
+				c.ID = cp.nextNewID()
+				cp.state.Components = append(cp.state.Components, c)
+				added++
+			}
+			fmt.Printf("[Detect] Proposed %d new components for review\n", added)
+			if added == 0 {
+				return
+			}
+			cp.state.SetModified(true)
+			cp.state.Emit(app.EventComponentsChanged, nil)
+		})
+	}()
+}
+
+// maxSilkscreenOCRWorkers caps parallel Tesseract engines for board OCR.
+const maxSilkscreenOCRWorkers = 4
+
+// onOCRSilkscreen runs OCR on the silkscreen of the selected side(s) to find
+// component labels.
+func (cp *ComponentsPanel) onOCRSilkscreen() {
+	var sides []pcbimage.Side
+	switch cp.ocrSideCombo.GetActiveID() {
+	case "back":
+		sides = []pcbimage.Side{pcbimage.SideBack}
+	case "both":
+		sides = []pcbimage.Side{pcbimage.SideFront, pcbimage.SideBack}
+	default:
+		sides = []pcbimage.Side{pcbimage.SideFront}
+	}
+
+	for _, side := range sides {
+		layer := cp.state.FrontImage
+		if side == pcbimage.SideBack {
+			layer = cp.state.BackImage
+		}
+		if layer == nil || layer.Image == nil {
+			fmt.Printf("No %s image loaded for OCR\n", side)
+			continue
+		}
+
+		fmt.Printf("Starting silkscreen OCR (%s)...\n", side)
+		result, err := detectSilkscreen(layer.Image)
+		if err != nil {
+			fmt.Printf("Silkscreen OCR error: %v\n", err)
+			continue
+		}
+		result.TagSide(side)
+		printSilkscreenResult(result)
+		if cp.silkscreenResults == nil {
+			cp.silkscreenResults = make(map[pcbimage.Side]*ocr.SilkscreenResult)
+		}
+		cp.silkscreenResults[side] = result
+		cp.updateOCROverlay(result)
+	}
+}
+
+// onCreateFromSilkscreen creates unconfirmed components from the designators
+// found by the last silkscreen OCR of each side, so they only need their
+// bounds adjusting rather than naming and placing by hand.
+func (cp *ComponentsPanel) onCreateFromSilkscreen() {
+	if len(cp.silkscreenResults) == 0 {
+		fmt.Println("[Silkscreen] No silkscreen OCR results — run OCR All Silkscreen first")
+		return
+	}
+	dpi := cp.state.DPI
+	if dpi <= 0 {
+		fmt.Println("[Silkscreen] DPI unknown, cannot size components")
+		return
+	}
+
+	var labels []component.DesignatorLabel
+	for _, side := range []pcbimage.Side{pcbimage.SideFront, pcbimage.SideBack} {
+		result := cp.silkscreenResults[side]
+		if result == nil {
+			continue
+		}
+		for _, d := range result.Designators {
+			labels = append(labels, component.DesignatorLabel{
+				Text:     d.Text,
+				Prefix:   d.Prefix,
+				Bounds:   d.Bounds,
+				Rotation: d.Rotation,
+				Side:     d.Side,
+			})
+		}
+	}
+
+	placed := component.PlaceFromDesignators(cp.state.Components, labels, dpi)
+	fmt.Printf("[Silkscreen] Created %d components from %d designators\n", len(placed), len(labels))
+	if len(placed) == 0 {
 		return
 	}
+	cp.state.Components = append(cp.state.Components, placed...)
+	cp.state.SetModified(true)
+	cp.state.Emit(app.EventComponentsChanged, nil)
+}
 
-	fmt.Println("Starting silkscreen OCR...")
+// AssignGridIDs relabels all components by the silkscreen grid cell they
+// sit in, using the coordinate axes found by the last silkscreen OCR (front
+// preferred). The remapping is previewed and only applied if accepted.
+func (cp *ComponentsPanel) AssignGridIDs() {
+	var xAxis, yAxis *component.GridAxis
+	for _, side := range []pcbimage.Side{pcbimage.SideFront, pcbimage.SideBack} {
+		result := cp.silkscreenResults[side]
+		if result == nil || result.XAxis == nil || result.YAxis == nil {
+			continue
+		}
+		xAxis = gridAxisFromOCR(result.XAxis, false)
+		yAxis = gridAxisFromOCR(result.YAxis, true)
+		break
+	}
+	if xAxis == nil {
+		fmt.Println("[Grid IDs] No silkscreen grid axes — run OCR All Silkscreen first")
+		return
+	}
 
-	engine, err := ocr.NewEngine()
+	changes, err := component.AssignGridIDs(cp.state.Components, xAxis, yAxis)
 	if err != nil {
-		fmt.Printf("Failed to create OCR engine: %v\n", err)
+		fmt.Printf("[Grid IDs] %v\n", err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Println("[Grid IDs] All components already have their grid IDs")
 		return
 	}
-	defer engine.Close()
 
-	img := cp.state.FrontImage.Image
+	dlg, _ := gtk.DialogNewWithButtons("Assign Grid IDs", cp.win,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL},
+		[]interface{}{"Apply", gtk.RESPONSE_OK})
+	dlg.SetDefaultSize(320, 400)
+	dlg.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	contentArea, _ := dlg.GetContentArea()
+	lbl, _ := gtk.LabelNew(fmt.Sprintf("%d components will be renamed:", len(changes)))
+	lbl.SetHAlign(gtk.ALIGN_START)
+	contentArea.PackStart(lbl, false, false, 4)
+
+	var sb strings.Builder
+	for _, ch := range changes {
+		fmt.Fprintf(&sb, "%-12s → %s\n", ch.OldID, ch.NewID)
+	}
+	view, _ := gtk.TextViewNew()
+	view.SetEditable(false)
+	if buf, err := view.GetBuffer(); err == nil {
+		buf.SetText(sb.String())
+	}
+	scroll, _ := gtk.ScrolledWindowNew(nil, nil)
+	scroll.SetVExpand(true)
+	scroll.Add(view)
+	contentArea.PackStart(scroll, true, true, 4)
+	dlg.ShowAll()
+
+	resp := dlg.Run()
+	dlg.Destroy()
+	if resp != gtk.RESPONSE_OK {
+		return
+	}
+
+	renames := component.ApplyGridIDs(changes)
+	refs := 0
+	if cp.state.FeaturesLayer != nil {
+		refs = cp.state.FeaturesLayer.RenameComponents(renames)
+	}
+	fmt.Printf("[Grid IDs] Renamed %d components (%d via/pad references)\n", len(changes), refs)
+	cp.state.SetModified(true)
+	cp.state.Emit(app.EventComponentsChanged, nil)
+	if refs > 0 {
+		cp.state.Emit(app.EventFeaturesChanged, nil)
+	}
+}
+
+// gridAxisFromOCR converts silkscreen coordinate markers to a grid axis,
+// positioning each marker at its center along X, or along Y if vertical.
+func gridAxisFromOCR(axis *ocr.CoordinateAxis, vertical bool) *component.GridAxis {
+	ga := &component.GridAxis{}
+	for _, m := range axis.Markers {
+		center := m.Bounds.ToFloat().Center()
+		pos := center.X
+		if vertical {
+			pos = center.Y
+		}
+		ga.Markers = append(ga.Markers, component.GridMarker{Label: m.Text, Pos: pos})
+	}
+	return ga
+}
+
+// detectSilkscreen runs tiled silkscreen OCR over a whole board image.
+func detectSilkscreen(img image.Image) (*ocr.SilkscreenResult, error) {
 	bounds := img.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
 
@@ -2215,8 +3505,7 @@ func (cp *ComponentsPanel) onOCRSilkscreen() {
 
 	mat, err := gocv.NewMatFromBytes(h, w, gocv.MatTypeCV8UC4, rgba.Pix)
 	if err != nil {
-		fmt.Printf("Failed to convert image: %v\n", err)
-		return
+		return nil, fmt.Errorf("failed to convert image: %w", err)
 	}
 	defer mat.Close()
 
@@ -2224,13 +3513,15 @@ func (cp *ComponentsPanel) onOCRSilkscreen() {
 	defer bgr.Close()
 	gocv.CvtColor(mat, &bgr, gocv.ColorRGBAToBGR)
 
-	result, err := engine.DetectSilkscreen(bgr)
-	if err != nil {
-		fmt.Printf("Silkscreen OCR error: %v\n", err)
-		return
-	}
+	// Tile the board so Tesseract sees local text and tiles run in parallel;
+	// each worker holds its own engine, so cap workers to bound memory.
+	workers := min(runtime.NumCPU(), maxSilkscreenOCRWorkers)
+	return ocr.DetectSilkscreenTiled(bgr, ocr.DefaultTileSize, ocr.DefaultTileOverlap, workers)
+}
 
-	fmt.Printf("\n=== Silkscreen OCR Results ===\n")
+// printSilkscreenResult logs the designators and coordinate axes found.
+func printSilkscreenResult(result *ocr.SilkscreenResult) {
+	fmt.Printf("\n=== Silkscreen OCR Results (%s) ===\n", result.Side)
 	fmt.Printf("Found %d component designators:\n", len(result.Designators))
 
 	counts := result.GetDesignatorCounts()
@@ -2238,7 +3529,7 @@ func (cp *ComponentsPanel) onOCRSilkscreen() {
 		fmt.Printf("  %s: %d\n", prefix, count)
 		designators := result.GetDesignatorsByType(prefix)
 		for _, d := range designators {
-			fmt.Printf("    %s at (%d,%d)\n", d.Text, d.Bounds.X, d.Bounds.Y)
+			fmt.Printf("    %s at (%d,%d) rot=%d\n", d.Text, d.Bounds.X, d.Bounds.Y, d.Rotation)
 		}
 	}
 
@@ -2257,21 +3548,25 @@ func (cp *ComponentsPanel) onOCRSilkscreen() {
 
 	fmt.Printf("\nTotal text items found: %d\n", len(result.AllText))
 	fmt.Printf("==============================\n")
-
-	cp.updateOCROverlay(result)
 }
 
-// updateOCROverlay shows detected silkscreen text on the canvas.
+// updateOCROverlay shows detected silkscreen text on the canvas, cyan for
+// the front and magenta for the back as for vias. Each side has its own
+// overlay so OCR of one side leaves the other's labels in place.
 func (cp *ComponentsPanel) updateOCROverlay(result *ocr.SilkscreenResult) {
+	name, col, layer := "ocr_front", colorutil.Cyan, canvas.LayerFront
+	if result != nil && result.Side == pcbimage.SideBack {
+		name, col, layer = "ocr_back", colorutil.Magenta, canvas.LayerBack
+	}
 	if result == nil || len(result.AllText) == 0 {
-		cp.canvas.SetOverlay("ocr", nil)
+		cp.canvas.SetOverlay(name, nil)
 		cp.canvas.Refresh()
 		return
 	}
 
 	overlay := &canvas.Overlay{
-		Color: color.RGBA{R: 0, G: 255, B: 255, A: 255},
-		Layer: canvas.LayerFront,
+		Color: col,
+		Layer: layer,
 	}
 	for _, d := range result.Designators {
 		rect := canvas.OverlayRect{
@@ -2281,128 +3576,12 @@ func (cp *ComponentsPanel) updateOCROverlay(result *ocr.SilkscreenResult) {
 		}
 		overlay.Rectangles = append(overlay.Rectangles, rect)
 	}
-	cp.canvas.SetOverlay("ocr", overlay)
+	cp.canvas.SetOverlay(name, overlay)
 	cp.canvas.Refresh()
 }
 
 // ---- Standalone helper functions for OCR processing ----
 
-// robustOtsu computes an Otsu threshold resistant to small bright/dark artifacts.
-func robustOtsu(gray []uint8, w, h int) uint8 {
-	cols, rows := 2, 4
-	type region struct {
-		mean float64
-		hist [256]int
-		n    int
-	}
-	regions := make([]region, cols*rows)
-
-	for ry := 0; ry < rows; ry++ {
-		y0 := ry * h / rows
-		y1 := (ry + 1) * h / rows
-		for rx := 0; rx < cols; rx++ {
-			x0 := rx * w / cols
-			x1 := (rx + 1) * w / cols
-			r := &regions[ry*cols+rx]
-			var sum int64
-			for y := y0; y < y1; y++ {
-				for x := x0; x < x1; x++ {
-					v := gray[y*w+x]
-					r.hist[v]++
-					sum += int64(v)
-					r.n++
-				}
-			}
-			if r.n > 0 {
-				r.mean = float64(sum) / float64(r.n)
-			}
-		}
-	}
-
-	sort.Slice(regions, func(i, j int) bool {
-		return regions[i].mean < regions[j].mean
-	})
-
-	var hist [256]int
-	total := 0
-	for _, r := range regions[2:6] {
-		for i := 0; i < 256; i++ {
-			hist[i] += r.hist[i]
-		}
-		total += r.n
-	}
-
-	fmt.Printf("[Otsu] Region means: ")
-	for i, r := range regions {
-		mark := " "
-		if i < 2 || i >= 6 {
-			mark = "x"
-		}
-		fmt.Printf("%.0f%s ", r.mean, mark)
-	}
-	fmt.Println()
-
-	var sum float64
-	for i := 0; i < 256; i++ {
-		sum += float64(i) * float64(hist[i])
-	}
-	var sumB float64
-	var wB, wF int
-	var maxVar float64
-	thresh := uint8(128)
-	for t := 0; t < 256; t++ {
-		wB += hist[t]
-		if wB == 0 {
-			continue
-		}
-		wF = total - wB
-		if wF == 0 {
-			break
-		}
-		sumB += float64(t) * float64(hist[t])
-		mB := sumB / float64(wB)
-		mF := (sum - sumB) / float64(wF)
-		variance := float64(wB) * float64(wF) * (mB - mF) * (mB - mF)
-		if variance > maxVar {
-			maxVar = variance
-			thresh = uint8(t)
-		}
-	}
-
-	minWhiteRatio := 0.10
-	countWhite := func(t uint8) int {
-		n := 0
-		for i := int(t) + 1; i < 256; i++ {
-			n += hist[i]
-		}
-		return n
-	}
-	whiteCount := countWhite(thresh)
-	whiteRatio := float64(whiteCount) / float64(total)
-	fmt.Printf("[Otsu] thresh=%d white=%.1f%%\n", thresh, whiteRatio*100)
-
-	if whiteRatio < minWhiteRatio {
-		lo, hi := uint8(0), thresh
-		for lo < hi {
-			mid := (lo + hi) / 2
-			ratio := float64(countWhite(mid)) / float64(total)
-			if ratio < minWhiteRatio {
-				hi = mid
-			} else {
-				lo = mid + 1
-			}
-		}
-		if lo > 0 {
-			lo--
-		}
-		newRatio := float64(countWhite(lo)) / float64(total)
-		fmt.Printf("[Otsu] Adjusted for faded: %d -> %d (white %.1f%% -> %.1f%%)\n",
-			thresh, lo, whiteRatio*100, newRatio*100)
-		thresh = lo
-	}
-	return thresh
-}
-
 // rgbaToGray converts an RGBA image to a grayscale byte slice.
 func rgbaToGray(src *image.RGBA) ([]uint8, int, int) {
 	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
@@ -2453,76 +3632,58 @@ func orientationToRotation(orientation string) int {
 	}
 }
 
-func rotateForOCR(img *image.RGBA, orientation string) *image.RGBA {
-	w, h := img.Bounds().Dx(), img.Bounds().Dy()
-	srcPix := img.Pix
-	srcStride := img.Stride
-
-	switch orientation {
-	case "S":
-		out := image.NewRGBA(image.Rect(0, 0, w, h))
-		dstPix := out.Pix
-		for y := 0; y < h; y++ {
-			srcRow := y * srcStride
-			dstRow := (h - 1 - y) * out.Stride
-			for x := 0; x < w; x++ {
-				si := srcRow + x*4
-				di := dstRow + (w-1-x)*4
-				dstPix[di] = srcPix[si]
-				dstPix[di+1] = srcPix[si+1]
-				dstPix[di+2] = srcPix[si+2]
-				dstPix[di+3] = srcPix[si+3]
-			}
-		}
-		return out
-	case "E":
-		out := image.NewRGBA(image.Rect(0, 0, h, w))
-		dstPix := out.Pix
-		dstStride := out.Stride
-		for y := 0; y < h; y++ {
-			srcRow := y * srcStride
-			for x := 0; x < w; x++ {
-				si := srcRow + x*4
-				di := (w-1-x)*dstStride + y*4
-				dstPix[di] = srcPix[si]
-				dstPix[di+1] = srcPix[si+1]
-				dstPix[di+2] = srcPix[si+2]
-				dstPix[di+3] = srcPix[si+3]
-			}
-		}
-		return out
-	case "W":
-		out := image.NewRGBA(image.Rect(0, 0, h, w))
-		dstPix := out.Pix
-		dstStride := out.Stride
-		for y := 0; y < h; y++ {
-			srcRow := y * srcStride
-			for x := 0; x < w; x++ {
-				si := srcRow + x*4
-				di := x*dstStride + (h-1-y)*4
-				dstPix[di] = srcPix[si]
-				dstPix[di+1] = srcPix[si+1]
-				dstPix[di+2] = srcPix[si+2]
-				dstPix[di+3] = srcPix[si+3]
+// cropComponent copies the component body out of img, clipped to the image.
+// A tilted body is sampled along its own axes so the crop comes out
+// straight, with pixels outside the image left transparent. Returns nil
+// when nothing of the body is in the image.
+func cropComponent(img image.Image, comp *component.Component) *image.RGBA {
+	b := comp.Bounds
+	imgBounds := img.Bounds()
+	tilt := comp.Tilt()
+	if tilt == 0 {
+		x, y := int(b.X), int(b.Y)
+		w, h := int(b.Width), int(b.Height)
+		if x < imgBounds.Min.X {
+			x = imgBounds.Min.X
+		}
+		if y < imgBounds.Min.Y {
+			y = imgBounds.Min.Y
+		}
+		w = min(w, imgBounds.Max.X-x)
+		h = min(h, imgBounds.Max.Y-y)
+		if w <= 0 || h <= 0 {
+			return nil
+		}
+		cropped := image.NewRGBA(image.Rect(0, 0, w, h))
+		for dy := 0; dy < h; dy++ {
+			for dx := 0; dx < w; dx++ {
+				cropped.Set(dx, dy, img.At(x+dx, y+dy))
 			}
 		}
-		return out
-	default:
-		return img
+		return cropped
 	}
-}
 
-
-func extractLogoNames(text string) []string {
-	re := regexp.MustCompile(`<([A-Za-z0-9]+)>`)
-	matches := re.FindAllStringSubmatch(text, -1)
-	var names []string
-	for _, m := range matches {
-		if len(m) >= 2 {
-			names = append(names, strings.ToUpper(m[1]))
+	w, h := int(b.Width), int(b.Height)
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+	rot := geometry.RotationAbout(b.Center(), tilt)
+	cropped := image.NewRGBA(image.Rect(0, 0, w, h))
+	inside := false
+	for dy := 0; dy < h; dy++ {
+		for dx := 0; dx < w; dx++ {
+			p := rot.Apply(geometry.Point2D{X: b.X + float64(dx) + 0.5, Y: b.Y + float64(dy) + 0.5})
+			pt := image.Point{X: int(math.Floor(p.X)), Y: int(math.Floor(p.Y))}
+			if pt.In(imgBounds) {
+				cropped.Set(dx, dy, img.At(pt.X, pt.Y))
+				inside = true
+			}
 		}
 	}
-	return names
+	if !inside {
+		return nil
+	}
+	return cropped
 }
 
 func fixOCRPartNumbers(text string) string {
@@ -2555,7 +3716,10 @@ func fixOCRPartNumbers(text string) string {
 		"UA": "UA", "CA": "CA", "CD": "CD",
 	}
 
-	pat := regexp.MustCompile(`(?i)\b([A-Z0-9]{0,3})([7T][A4]|[5S][A4])([A-Z]{0,4})([0-9OSA]{1,4})([A-Z]{0,3})\b`)
+	// Letters the confusion table reads as digits may appear in the digit run
+	confusions := ocr.ActiveConfusionTable()
+	pat := regexp.MustCompile(`(?i)\b([A-Z0-9]{0,3})([7T][A4]|[5S][A4])([A-Z]{0,4})([0-9` +
+		regexp.QuoteMeta(confusions.DigitLetters()) + `]{1,4})([A-Z]{0,3})\b`)
 
 	result := pat.ReplaceAllStringFunc(text, func(match string) string {
 		sub := pat.FindStringSubmatch(match)
@@ -2592,18 +3756,7 @@ func fixOCRPartNumbers(text string) string {
 			}
 		}
 
-		fixedDigits := strings.Map(func(r rune) rune {
-			switch r {
-			case 'S', 's':
-				return '5'
-			case 'A', 'a':
-				return '4'
-			case 'O', 'o':
-				return '0'
-			default:
-				return r
-			}
-		}, digits)
+		fixedDigits := strings.Map(confusions.Digit, digits)
 
 		fixed := prefix + series + family + fixedDigits + suffix
 		fmt.Printf("[OCR Fix] %q -> %q (prefix=%s series=%s family=%s digits=%s suffix=%s)\n",
@@ -2817,48 +3970,9 @@ func parseComponentInfo(text string) componentInfo {
 	return info
 }
 
+// fuzzyContains reports whether haystack contains needle allowing
+// maxErrors misreads beyond the active OCR confusion table.
 func fuzzyContains(haystack, needle string, maxErrors int) bool {
-	nLen := len(needle)
-	if nLen == 0 {
-		return true
-	}
-	if len(haystack) < nLen {
-		return false
-	}
-
-	sameGroup := func(a, b byte) bool {
-		if a == b {
-			return true
-		}
-		groups := [][2]byte{
-			{'0', 'O'}, {'0', 'D'}, {'O', 'D'},
-			{'1', 'I'}, {'1', 'L'}, {'I', 'L'},
-			{'5', 'S'}, {'4', 'A'},
-			{'8', 'B'}, {'6', 'G'},
-			{'2', 'Z'},
-		}
-		for _, g := range groups {
-			if (a == g[0] && b == g[1]) || (a == g[1] && b == g[0]) {
-				return true
-			}
-		}
-		return false
-	}
-
-	for i := 0; i <= len(haystack)-nLen; i++ {
-		errors := 0
-		for j := 0; j < nLen; j++ {
-			if !sameGroup(haystack[i+j], needle[j]) {
-				errors++
-				if errors > maxErrors {
-					break
-				}
-			}
-		}
-		if errors <= maxErrors {
-			return true
-		}
-	}
-	return false
+	return ocr.ActiveConfusionTable().FuzzyContains(haystack, needle, maxErrors)
 }
 
@@ -0,0 +1,52 @@
+package panels
+
+import (
+	"testing"
+
+	"pcb-tracer/internal/component"
+)
+
+func TestNextOCROrientation(t *testing.T) {
+	for _, c := range []struct{ orient, want string }{
+		{"N", "E"},
+		{"E", "S"},
+		{"S", "W"},
+		{"W", "N"},
+		{"", "E"},
+		{ocrOrientationAuto, "N"},
+	} {
+		if got := nextOCROrientation(c.orient); got != c.want {
+			t.Errorf("nextOCROrientation(%q) = %q, want %q", c.orient, got, c.want)
+		}
+	}
+}
+
+// TestCycleComponentOrientation presses R four times on a component read
+// upright: each press turns it a quarter turn and re-runs OCR, which must
+// already see the new orientation on the component and in the radio group.
+func TestCycleComponentOrientation(t *testing.T) {
+	comp := &component.Component{ID: "U7", OCROrientation: "N"}
+	shown := "N"
+	var ocrRuns []string
+	show := func(orient string) { shown = orient }
+	ocr := func(orient string) {
+		if comp.OCROrientation != orient || shown != orient {
+			t.Errorf("OCR for %s ran with component at %q and radio at %q", orient, comp.OCROrientation, shown)
+		}
+		ocrRuns = append(ocrRuns, orient)
+	}
+
+	for _, want := range []string{"E", "S", "W", "N"} {
+		if got := cycleComponentOrientation(comp, shown, show, ocr); got != want || comp.OCROrientation != want {
+			t.Errorf("cycled to %q (component %q), want %q", got, comp.OCROrientation, want)
+		}
+	}
+	if len(ocrRuns) != 4 {
+		t.Errorf("OCR ran %d times for 4 presses, want 4", len(ocrRuns))
+	}
+
+	ocrRuns = nil
+	if got := cycleComponentOrientation(nil, "N", show, ocr); got != "" || len(ocrRuns) != 0 {
+		t.Errorf("no component: cycled to %q with %d OCR runs, want nothing", got, len(ocrRuns))
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"pcb-tracer/internal/alignment"
+	"pcb-tracer/internal/app"
+	pcbimage "pcb-tracer/internal/image"
 	"pcb-tracer/pkg/colorutil"
 	"pcb-tracer/pkg/geometry"
 )
@@ -18,7 +20,23 @@ type hsvStats struct {
 	valMean, valStd float64
 }
 
-func applyShearAlignment(img image.Image, backLeft, backRight, frontLeft, frontRight geometry.Point2D, contactY float64) (image.Image, string) {
+// colorParams returns HSV bounds of mean ± 2σ, clamped to the OpenCV
+// ranges, for detection that should match colors like the sampled region.
+func (s hsvStats) colorParams() *app.ColorParams {
+	return &app.ColorParams{
+		HueMin: max(0, s.hueMean-2*s.hueStd),
+		HueMax: min(180, s.hueMean+2*s.hueStd),
+		SatMin: max(0, s.satMean-2*s.satStd),
+		SatMax: min(255, s.satMean+2*s.satStd),
+		ValMin: max(0, s.valMean-2*s.valStd),
+		ValMax: min(255, s.valMean+2*s.valStd),
+	}
+}
+
+// applyShearAlignment scales the back image in Y about contactY and shears it
+// in X so its ejector marks land on the front's. opts selects nearest-neighbor
+// or bilinear sampling.
+func applyShearAlignment(img image.Image, backLeft, backRight, frontLeft, frontRight geometry.Point2D, contactY float64, opts pcbimage.ResampleOptions) (image.Image, string) {
 	backYDistLeft := backLeft.Y - contactY
 	backYDistRight := backRight.Y - contactY
 	frontYDistLeft := frontLeft.Y - contactY
@@ -72,6 +90,13 @@ func applyShearAlignment(img image.Image, backLeft, backRight, frontLeft, frontR
 			xShift := shear * outYDist
 			srcX := float64(x) - xShift
 
+			if opts.Bilinear {
+				if c, ok := pcbimage.SampleBilinear(img, srcX+float64(bounds.Min.X), srcY+float64(bounds.Min.Y)); ok {
+					result.SetRGBA(x, y, c)
+				}
+				continue
+			}
+
 			sx := int(srcX + 0.5)
 			sy := int(srcY + 0.5)
 
@@ -87,6 +112,118 @@ func applyShearAlignment(img image.Image, backLeft, backRight, frontLeft, frontR
 	return result, fmt.Sprintf("yScale=%.4f, shear L=%.4f R=%.4f", yScale, shearLeft, shearRight)
 }
 
+// alignToRegistrationMarks fits the (contact-translated) back image to the
+// front using every registration mark found on both sides: a least-squares
+// affine for three or more pairs, the two-ejector shear otherwise. ok is false
+// when the marks don't give a usable fit.
+func alignToRegistrationMarks(back image.Image, frontMarks, backMarks []alignment.EjectorMark, contactY, dpi float64) (image.Image, string, bool) {
+	frontPts, backPts, labels := alignment.MatchEjectorMarks(frontMarks, backMarks)
+	fmt.Printf("Auto-align: %d registration mark pairs: %s\n", len(labels), strings.Join(labels, ", "))
+
+	if len(frontPts) >= 3 {
+		t, err := alignment.FitAffineFromPairs(backPts, frontPts)
+		if err == nil {
+			// After contact translation only a small residual rotation and
+			// scale remain; anything larger means a mark was misdetected.
+			angle := math.Atan2(t.C, t.A) * 180 / math.Pi
+			sx := math.Hypot(t.A, t.C)
+			sy := (t.A*t.D - t.B*t.C) / sx
+			if math.Abs(angle) > 2 || math.Abs(sx-1) > 0.02 || math.Abs(sy-1) > 0.02 {
+				err = fmt.Errorf("implausible fit (rot=%.3f° sx=%.4f sy=%.4f)", angle, sx, sy)
+			}
+		}
+		if err == nil {
+			avg, maxErr, _ := alignment.ResidualStats(frontPts, backPts, t)
+			for i := range frontPts {
+				fmt.Printf("Auto-align:   %-18s err=%.1f px\n", labels[i], frontPts[i].Distance(t.Apply(backPts[i])))
+			}
+			b := back.Bounds()
+			warped, werr := alignment.WarpAffineGoImage(back, t, b.Dx(), b.Dy())
+			if werr == nil {
+				return warped, fmt.Sprintf("%d-mark affine, rot=%.3f° avg=%.1f max=%.1f px",
+					len(frontPts), math.Atan2(t.C, t.A)*180/math.Pi, avg, maxErr), true
+			}
+			err = werr
+		}
+		fmt.Printf("Auto-align: %d-mark affine failed (%v), trying two-mark shear\n", len(frontPts), err)
+	}
+
+	var frontLeft, frontRight, backLeft, backRight *geometry.Point2D
+	for i, l := range labels {
+		switch l {
+		case "left":
+			frontLeft, backLeft = &frontPts[i], &backPts[i]
+		case "right":
+			frontRight, backRight = &frontPts[i], &backPts[i]
+		}
+	}
+	if frontLeft == nil || frontRight == nil {
+		return back, "", false
+	}
+	// The sheared image replaces the back layer's pixels and ends up in the
+	// saved PNG, so it is sampled bilinearly
+	img, info := applyShearAlignment(back, *backLeft, *backRight, *frontLeft, *frontRight,
+		contactY, pcbimage.ResampleOptions{Bilinear: true})
+	fmt.Printf("Auto-align: shear alignment: %s\n", info)
+	return img, info, true
+}
+
+// maxPerspectiveCornerShift bounds how far, as a fraction of the image
+// diagonal, alignPerspective may move any image corner.
+const maxPerspectiveCornerShift = 0.1
+
+// alignPerspective fits a homography mapping the (contact-translated) back
+// image onto the front from the corners of every paired edge contact plus
+// the matched registration marks, for scans keystoned by a tilted board or
+// lid. backContacts must already be shifted by the same offset as back. ok
+// is false when there are too few correspondences or the fit is implausible.
+func alignPerspective(back image.Image, frontContacts, backContacts []alignment.Contact, frontMarks, backMarks []alignment.EjectorMark) (image.Image, string, bool) {
+	frontPts, backPts := alignment.ContactCornerPairs(frontContacts, backContacts)
+	contactPairs := len(frontPts) / 4
+	markFront, markBack, labels := alignment.MatchEjectorMarks(frontMarks, backMarks)
+	frontPts = append(frontPts, markFront...)
+	backPts = append(backPts, markBack...)
+	fmt.Printf("Auto-align: perspective from %d contact pairs + %d marks (%s)\n",
+		contactPairs, len(labels), strings.Join(labels, ", "))
+
+	// Contacts all lie along one edge, so without marks elsewhere on the
+	// board the fit is unconstrained away from the fingers.
+	if len(labels) < 2 {
+		fmt.Printf("Auto-align: perspective needs at least 2 registration marks, got %d\n", len(labels))
+		return back, "", false
+	}
+
+	h, err := alignment.FitHomography(backPts, frontPts)
+	if err != nil {
+		fmt.Printf("Auto-align: perspective fit failed: %v\n", err)
+		return back, "", false
+	}
+	avg, maxErr := alignment.HomographyResiduals(backPts, frontPts, h)
+
+	// A keystoned photo can move a corner several percent of the board,
+	// far more than the affine path accepts, but a corner moving more than
+	// maxPerspectiveCornerShift of the image diagonal is a bad fit.
+	b := back.Bounds()
+	limit := maxPerspectiveCornerShift * math.Hypot(float64(b.Dx()), float64(b.Dy()))
+	for _, c := range []geometry.Point2D{
+		{X: 0, Y: 0}, {X: float64(b.Dx()), Y: 0},
+		{X: float64(b.Dx()), Y: float64(b.Dy())}, {X: 0, Y: float64(b.Dy())},
+	} {
+		if d := c.Distance(h.Apply(c)); d > limit {
+			fmt.Printf("Auto-align: implausible perspective fit (corner moves %.1f px)\n", d)
+			return back, "", false
+		}
+	}
+
+	warped, err := alignment.WarpPerspectiveGoImage(back, h, b.Dx(), b.Dy())
+	if err != nil {
+		fmt.Printf("Auto-align: perspective warp failed: %v\n", err)
+		return back, "", false
+	}
+	return warped, fmt.Sprintf("perspective (%d contacts, %d marks), avg=%.1f max=%.1f px",
+		contactPairs, len(labels), avg, maxErr), true
+}
+
 func translateImage(img image.Image, dx, dy int) image.Image {
 	bounds := img.Bounds()
 	w, h := bounds.Dx(), bounds.Dy()
@@ -180,68 +317,6 @@ func stdDev(values []float64) float64 {
 	return math.Sqrt(sumSq / float64(len(values)))
 }
 
-
-// naturalLess compares two strings using natural numeric ordering.
-// "A2" < "A10", "U1" < "U2" < "U10", etc.
-func naturalLess(a, b string) bool {
-	chunksA := splitNatural(a)
-	chunksB := splitNatural(b)
-	for i := 0; i < len(chunksA) && i < len(chunksB); i++ {
-		ca, cb := chunksA[i], chunksB[i]
-		if isNumeric(ca) && isNumeric(cb) {
-			na := parseNum(ca)
-			nb := parseNum(cb)
-			if na != nb {
-				return na < nb
-			}
-		} else {
-			cmp := strings.Compare(strings.ToUpper(ca), strings.ToUpper(cb))
-			if cmp != 0 {
-				return cmp < 0
-			}
-		}
-	}
-	return len(chunksA) < len(chunksB)
-}
-
-func splitNatural(s string) []string {
-	var chunks []string
-	var current strings.Builder
-	wasDigit := false
-	for i, r := range s {
-		isDigit := r >= '0' && r <= '9'
-		if i > 0 && isDigit != wasDigit {
-			chunks = append(chunks, current.String())
-			current.Reset()
-		}
-		current.WriteRune(r)
-		wasDigit = isDigit
-	}
-	if current.Len() > 0 {
-		chunks = append(chunks, current.String())
-	}
-	return chunks
-}
-
-func isNumeric(s string) bool {
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			return false
-		}
-	}
-	return len(s) > 0
-}
-
-func parseNum(s string) int {
-	n := 0
-	for _, r := range s {
-		if r >= '0' && r <= '9' {
-			n = n*10 + int(r-'0')
-		}
-	}
-	return n
-}
-
 func printContactStats(img image.Image, contacts []alignment.Contact, dpi float64, layerName string) {
 	if len(contacts) == 0 {
 		return
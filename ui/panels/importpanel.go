@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -45,7 +46,9 @@ type ImportPanel struct {
 	detectButton     *gtk.Button
 	sampleButton     *gtk.Button
 	alignButton      *gtk.Button
+	perspectiveCheck *gtk.CheckButton // Fit a homography instead of an affine
 	alignStatus      *gtk.Label
+	previewGen       int // Bumped per preview request; stale results are dropped
 
 	// Manual alignment
 	offsetLabel   *gtk.Label
@@ -53,15 +56,30 @@ type ImportPanel struct {
 	shearLabel    *gtk.Label
 	cropLabel     *gtk.Label
 
+	// Crop drag-to-edit on the canvas
+	cropEditBtn *gtk.ToggleButton
+
+	// Named alignment presets for the current board profile
+	presetCombo *gtk.ComboBoxText
+
 	// Auto align
 	autoAlignButton    *gtk.Button
 	coarseAlignButton  *gtk.Button
 	fineAlignButton    *gtk.Button
+	stepEdgeButton     *gtk.Button
 
 	// Save / Re-align
 	saveAlignedBtn *gtk.Button
 	realignBtn     *gtk.Button
 	alignControls  *gtk.Box // Manual controls (hidden after normalization)
+
+	// Export copy written alongside the normalized images
+	exportFormat      *gtk.ComboBoxText
+	exportDPISpin     *gtk.SpinButton // 0 keeps the source DPI
+	exportQualitySpin *gtk.SpinButton // JPEG only
+
+	dpiPromptOpen bool       // Rescale dialog for a DPI mismatch is pending or showing
+	dpiDeclined   [2]float64 // Front and back DPI of a mismatch the user chose to keep
 }
 
 // NewImportPanel creates a new import panel.
@@ -99,6 +117,7 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 		if spec := board.GetSpec(selected); spec != nil {
 			state.BoardSpec = spec
 			ip.updateBoardSpecInfo()
+			ip.refreshPresetList()
 		}
 	})
 	if state.BoardSpec != nil {
@@ -137,6 +156,9 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	ip.alignButton, _ = gtk.ButtonNewWithLabel("Align Images")
 	ip.alignButton.Connect("clicked", func() { ip.onAlignImages() })
 
+	ip.perspectiveCheck, _ = gtk.CheckButtonNewWithLabel("Perspective")
+	ip.perspectiveCheck.SetTooltipText("Correct keystone distortion from contact corners and registration marks")
+
 	ip.autoAlignButton, _ = gtk.ButtonNewWithLabel("Auto Align")
 	ip.autoAlignButton.Connect("clicked", func() { ip.onAutoAlign() })
 
@@ -146,9 +168,39 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	ip.fineAlignButton, _ = gtk.ButtonNewWithLabel("Align Fine (Vias)")
 	ip.fineAlignButton.Connect("clicked", func() { ip.onFineAlign() })
 
+	ip.stepEdgeButton, _ = gtk.ButtonNewWithLabel("Align to Step Edge")
+	ip.stepEdgeButton.SetTooltipText("Shift the back layer vertically to register the board step below the contact fingers; for boards with few vias")
+	ip.stepEdgeButton.Connect("clicked", func() { ip.onAlignStepEdges() })
+
 	ip.saveAlignedBtn, _ = gtk.ButtonNewWithLabel("Save Aligned")
 	ip.saveAlignedBtn.Connect("clicked", func() { ip.onSaveAligned() })
 
+	// Export options: the normalized images are always saved as PNG at the
+	// working DPI; anything else also writes an export copy.
+	ip.exportFormat, _ = gtk.ComboBoxTextNew()
+	ip.exportFormat.Append(pcbimage.FormatPNG, "PNG")
+	ip.exportFormat.Append(pcbimage.FormatTIFF, "TIFF")
+	ip.exportFormat.Append(pcbimage.FormatJPEG, "JPEG")
+	ip.exportFormat.SetActiveID(pcbimage.FormatPNG)
+	ip.exportDPISpin, _ = gtk.SpinButtonNewWithRange(0, 4800, 50)
+	ip.exportDPISpin.SetValue(0)
+	ip.exportDPISpin.SetTooltipText("Export resolution; 0 keeps the scan's DPI")
+	ip.exportQualitySpin, _ = gtk.SpinButtonNewWithRange(1, 100, 1)
+	ip.exportQualitySpin.SetValue(pcbimage.DefaultJPEGQuality)
+	ip.exportQualitySpin.SetTooltipText("JPEG quality")
+	ip.exportQualitySpin.SetSensitive(false)
+	ip.exportFormat.Connect("changed", func() {
+		ip.exportQualitySpin.SetSensitive(ip.exportFormat.GetActiveID() == pcbimage.FormatJPEG)
+	})
+	exportBox, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	exportBox.PackStart(ip.exportFormat, false, false, 0)
+	exportDPILbl, _ := gtk.LabelNew("DPI:")
+	exportBox.PackStart(exportDPILbl, false, false, 0)
+	exportBox.PackStart(ip.exportDPISpin, true, true, 0)
+	exportQLbl, _ := gtk.LabelNew("Q:")
+	exportBox.PackStart(exportQLbl, false, false, 0)
+	exportBox.PackStart(ip.exportQualitySpin, false, false, 0)
+
 	ip.realignBtn, _ = gtk.ButtonNewWithLabel("Re-align")
 	ip.realignBtn.Connect("clicked", func() { ip.onRealign() })
 
@@ -240,6 +292,9 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 		cropSizeBox.PackStart(btn, true, true, 0)
 	}
 
+	ip.cropEditBtn, _ = gtk.ToggleButtonNewWithLabel("Drag Crop on Canvas")
+	ip.cropEditBtn.Connect("toggled", func() { ip.refreshCropEdit() })
+
 	reImportBtn, _ := gtk.ButtonNewWithLabel("Re-import with Crop")
 	reImportBtn.Connect("clicked", func() { ip.onReImportWithCrop() })
 
@@ -260,6 +315,19 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	bgBox.PackStart(bgCheckRadio, false, false, 0)
 	bgBox.PackStart(bgBlackRadio, false, false, 0)
 
+	// Alignment presets: pick or type a name, then Load or Save
+	presetBox, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	ip.presetCombo, _ = gtk.ComboBoxTextNewWithEntry()
+	ip.presetCombo.SetTooltipText("Manual alignment saved for this board type")
+	presetLoadBtn, _ := gtk.ButtonNewWithLabel("Load")
+	presetLoadBtn.Connect("clicked", func() { ip.onLoadAlignmentPreset() })
+	presetSaveBtn, _ := gtk.ButtonNewWithLabel("Save")
+	presetSaveBtn.Connect("clicked", func() { ip.onSaveAlignmentPreset() })
+	presetBox.PackStart(ip.presetCombo, true, true, 0)
+	presetBox.PackStart(presetLoadBtn, false, false, 0)
+	presetBox.PackStart(presetSaveBtn, false, false, 0)
+	ip.refreshPresetList()
+
 	// Manual alignment controls container
 	ip.alignControls, _ = gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
 	ip.alignControls.SetMarginStart(4)
@@ -268,7 +336,9 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	addToBox(ip.alignControls, ip.autoAlignButton)
 	addToBox(ip.alignControls, ip.coarseAlignButton)
 	addToBox(ip.alignControls, ip.fineAlignButton)
+	addToBox(ip.alignControls, ip.stepEdgeButton)
 	addToBox(ip.alignControls, ip.alignButton)
+	addToBox(ip.alignControls, ip.perspectiveCheck)
 	addToBox(ip.alignControls, ip.alignStatus)
 	addSep(ip.alignControls)
 	addLabel(ip.alignControls, "Background:")
@@ -284,12 +354,18 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	addToBox(ip.alignControls, shearBox)
 	addToBox(ip.alignControls, ip.shearLabel)
 	addSep(ip.alignControls)
+	addLabel(ip.alignControls, "Alignment Preset:")
+	addToBox(ip.alignControls, presetBox)
+	addSep(ip.alignControls)
 	addLabel(ip.alignControls, "Crop Bounds:")
 	addToBox(ip.alignControls, cropPosBox)
 	addToBox(ip.alignControls, cropSizeBox)
 	addToBox(ip.alignControls, ip.cropLabel)
+	addToBox(ip.alignControls, ip.cropEditBtn)
 	addToBox(ip.alignControls, reImportBtn)
 	addSep(ip.alignControls)
+	addLabel(ip.alignControls, "Export:")
+	addToBox(ip.alignControls, exportBox)
 	addToBox(ip.alignControls, ip.saveAlignedBtn)
 
 	// If already normalized, hide alignment controls and show Re-align
@@ -325,7 +401,12 @@ func NewImportPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 		addToBox(box, editSpecBtn)
 	})
 
+	multiPageBtn, _ := gtk.ButtonNewWithLabel("Import Both Sides from Multi-Page TIFF...")
+	multiPageBtn.SetTooltipText("Page 1 is the front, page 2 the back (flipped)")
+	multiPageBtn.Connect("clicked", func() { ip.onImportMultiPage() })
+
 	addFrame(ip.widget, "Images", func(box *gtk.Box) {
+		addToBox(box, multiPageBtn)
 		addLabel(box, "Front (Component Side):")
 		addToBox(box, ip.frontLabel)
 		addLabel(box, "Back (Solder Side):")
@@ -408,6 +489,7 @@ func (ip *ImportPanel) updateAlignmentUI() {
 func (ip *ImportPanel) RefreshLabels() {
 	ip.updateBoardSpecInfo()
 	ip.updateCropLabel()
+	ip.refreshCropEdit()
 	ip.updateRotationCenterOverlay()
 
 	isFront := ip.selectedLayer() == "Front"
@@ -500,15 +582,36 @@ func (ip *ImportPanel) showBoardSpecDialog() {
 		return
 	}
 
-	dlg := dialogs.NewBoardSpecDialog(spec, ip.win, func(updated *board.BaseSpec) {
+	// Edit a copy: the registered spec is shared by every project using it
+	dlg := dialogs.NewBoardSpecDialog(spec.Clone(), ip.win, func(updated *board.BaseSpec) {
+		// Save as a user profile so the project's board type finds the
+		// edited spec again on the next load
+		path, err := board.SaveProfile(board.UserProfileDir(), updated)
+		if err != nil {
+			ip.alignStatus.SetText(fmt.Sprintf("Board spec not saved: %v", err))
+			return
+		}
 		ip.state.BoardSpec = updated
+		// Sampled contact colors would override an edited HSV range
+		if contactColor(spec) != contactColor(updated) {
+			ip.state.FrontColorParams = nil
+			ip.state.BackColorParams = nil
+		}
 		ip.updateBoardSpecInfo()
 		ip.state.SetModified(true)
-		fmt.Println("Board spec updated")
+		fmt.Printf("Board spec saved to %s\n", path)
 	})
 	dlg.Show()
 }
 
+// contactColor returns the contact HSV range of spec's primary group.
+func contactColor(spec *board.BaseSpec) board.HSVRange {
+	if spec.Contacts == nil || spec.Contacts.Detection == nil {
+		return board.HSVRange{}
+	}
+	return spec.Contacts.Detection.Color
+}
+
 func (ip *ImportPanel) updateImageStatus() {
 	var frontDPI, backDPI float64
 
@@ -531,6 +634,10 @@ func (ip *ImportPanel) updateImageStatus() {
 	if frontDPI > 0 && backDPI > 0 && frontDPI != backDPI {
 		ip.dpiLabel.SetText(fmt.Sprintf("DPI MISMATCH: %.0f vs %.0f", frontDPI, backDPI))
 		ip.state.DPI = 0
+		if !ip.dpiPromptOpen && ip.dpiDeclined != [2]float64{frontDPI, backDPI} {
+			ip.dpiPromptOpen = true
+			glib.IdleAdd(func() { ip.promptDPIRescale(frontDPI, backDPI) })
+		}
 	} else if frontDPI > 0 {
 		ip.state.DPI = frontDPI
 		ip.dpiLabel.SetText(fmt.Sprintf("DPI: %.0f", frontDPI))
@@ -542,6 +649,95 @@ func (ip *ImportPanel) updateImageStatus() {
 	} else {
 		ip.dpiLabel.SetText("DPI: Unknown")
 	}
+
+	// Warn when a scan carried no resolution metadata: via and contact size
+	// thresholds are in inches, so a wrong DPI silently ruins detection.
+	ip.dpiLabel.SetTooltipText("")
+	if dpi := ip.state.DPI; dpi > 0 {
+		var assumed []string
+		if ip.state.FrontImage != nil && ip.state.FrontImage.DPIAssumed {
+			assumed = append(assumed, "front")
+		}
+		if ip.state.BackImage != nil && ip.state.BackImage.DPIAssumed {
+			assumed = append(assumed, "back")
+		}
+		if len(assumed) > 0 {
+			ip.dpiLabel.SetMarkup(fmt.Sprintf("<span foreground='red'>DPI: %.0f (assumed)</span>", dpi))
+			ip.dpiLabel.SetTooltipText(fmt.Sprintf("No resolution metadata in the %s scan; check the DPI before detecting vias",
+				strings.Join(assumed, " and ")))
+		}
+	}
+}
+
+// onImportMultiPage imports the front and back from one multi-page TIFF.
+// A single-page file imports as the front only.
+func (ip *ImportPanel) onImportMultiPage() {
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons("Import Multi-Page TIFF", ip.win,
+		gtk.FILE_CHOOSER_ACTION_OPEN,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Open", gtk.RESPONSE_ACCEPT)
+	filter, _ := gtk.FileFilterNew()
+	filter.SetName("TIFF Images")
+	filter.AddPattern("*.tif")
+	filter.AddPattern("*.tiff")
+	dlg.AddFilter(filter)
+	if ip.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(ip.state.ProjectPath))
+	}
+
+	response := dlg.Run()
+	path := dlg.GetFilename()
+	dlg.Destroy()
+	if response != gtk.RESPONSE_ACCEPT {
+		return
+	}
+
+	ip.alignStatus.SetText("Importing " + filepath.Base(path) + "...")
+	sides, err := ip.state.ImportMultiPageImage(path)
+	switch {
+	case err != nil:
+		ip.alignStatus.SetText(fmt.Sprintf("Import failed: %v", err))
+	case sides == 1:
+		ip.alignStatus.SetText("Single-page TIFF: imported front only")
+	default:
+		ip.alignStatus.SetText("Imported front (page 1) and back (page 2)")
+	}
+}
+
+// promptDPIRescale offers to resample the lower-resolution side up to the
+// other side's DPI so alignment can proceed. Declining leaves the mismatch,
+// and the same mismatch is not asked about again.
+func (ip *ImportPanel) promptDPIRescale(frontDPI, backDPI float64) {
+	defer func() { ip.dpiPromptOpen = false }()
+
+	side, from, to := pcbimage.SideBack, backDPI, frontDPI
+	name, other := "back", "Front"
+	if frontDPI < backDPI {
+		side, from, to = pcbimage.SideFront, frontDPI, backDPI
+		name, other = "front", "Back"
+	}
+
+	dlg := gtk.MessageDialogNew(ip.win, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO,
+		"%s is %.0f DPI, %s is %.0f DPI — rescale %s to %.0f?",
+		other, to, name, from, name, to)
+	resp := dlg.Run()
+	dlg.Destroy()
+	if resp != gtk.RESPONSE_YES {
+		ip.dpiDeclined = [2]float64{frontDPI, backDPI}
+		return
+	}
+
+	if err := ip.state.ResampleSideToDPI(side, to); err != nil {
+		ip.alignStatus.SetText(fmt.Sprintf("Rescale failed: %v", err))
+		return
+	}
+	ip.updateImageStatus()
+	if ip.sidePanel != nil {
+		ip.sidePanel.SyncLayers()
+	}
+	ip.RefreshLabels()
+	ip.canvas.Refresh()
+	ip.alignStatus.SetText(fmt.Sprintf("Rescaled %s from %.0f to %.0f DPI", name, from, to))
 }
 
 func (ip *ImportPanel) updateCropLabel() {
@@ -594,6 +790,9 @@ func (ip *ImportPanel) onDetectContacts() {
 	ip.alignStatus.SetText(fmt.Sprintf("Detecting contacts on %s...", layerName))
 	ip.detectButton.SetSensitive(false)
 
+	ip.previewGen++
+	ip.canvas.ClearOverlay("front_contact_preview")
+	ip.canvas.ClearOverlay("back_contact_preview")
 	ip.canvas.ClearOverlay("front_contacts")
 	ip.canvas.ClearOverlay("back_contacts")
 	ip.canvas.ClearOverlay("front_expected")
@@ -604,10 +803,7 @@ func (ip *ImportPanel) onDetectContacts() {
 	ip.canvas.ClearOverlay("back_ejectors")
 
 	go func() {
-		dpi := ip.state.DPI
-		if dpi == 0 && img.DPI > 0 {
-			dpi = img.DPI
-		}
+		dpi := ip.state.DPIForSide(img.Side)
 
 		var colorParams *alignment.DetectionParams
 		var sampledParams *app.ColorParams
@@ -624,7 +820,12 @@ func (ip *ImportPanel) onDetectContacts() {
 			}
 		}
 
-		result, err := alignment.DetectContactsOnTopEdge(img.Image, ip.state.BoardSpec, dpi, colorParams)
+		results, err := alignment.DetectContactGroups(img.Image, ip.state.BoardSpec, dpi, colorParams)
+		var result *alignment.DetectionResult
+		if len(results) > 0 {
+			result = results[0]
+		}
+		groupInfo := contactGroupSummary(ip.state.BoardSpec, results)
 
 		var contactCount int
 		if result != nil {
@@ -632,8 +833,10 @@ func (ip *ImportPanel) onDetectContacts() {
 
 			if isFront {
 				ip.state.FrontDetectionResult = result
+				ip.state.FrontExtraDetections = results[1:]
 			} else {
 				ip.state.BackDetectionResult = result
+				ip.state.BackExtraDetections = results[1:]
 			}
 
 			glib.IdleAdd(func() {
@@ -656,7 +859,7 @@ func (ip *ImportPanel) onDetectContacts() {
 					ejectorDPI = result.DPI
 				}
 				if ejectorDPI > 0 {
-					ejectorMarks := alignment.DetectEjectorMarksFromImage(img.Image, result.Contacts, ejectorDPI)
+					ejectorMarks := alignment.DetectEjectorMarksFromImage(img.Image, result.Contacts, ejectorDPI, ip.state.BoardSpec)
 					if len(ejectorMarks) > 0 {
 						var ejectorName string
 						if isFront {
@@ -669,12 +872,12 @@ func (ip *ImportPanel) onDetectContacts() {
 							Layer:      overlayLayer,
 							Rectangles: make([]canvas.OverlayRect, len(ejectorMarks)),
 						}
-						markSize := int(0.25 * ejectorDPI)
+						markSize := int(ip.canvas.MarkerSize(canvas.MarkerEjector, ejectorDPI))
 						for i, mark := range ejectorMarks {
 							ejectorOverlay.Rectangles[i] = canvas.OverlayRect{
 								X: int(mark.Center.X) - markSize/2, Y: int(mark.Center.Y) - markSize/2,
 								Width: markSize, Height: markSize,
-								Fill: canvas.FillTarget, Label: mark.Side,
+								Fill: canvas.FillTarget, Label: mark.Name, Marker: true,
 							}
 						}
 						ip.canvas.SetOverlay(ejectorName, ejectorOverlay)
@@ -721,14 +924,80 @@ func (ip *ImportPanel) onDetectContacts() {
 		glib.IdleAdd(func() {
 			ip.detectButton.SetSensitive(true)
 			if err != nil {
-				ip.alignStatus.SetText(fmt.Sprintf("%s: %d contacts%s\n%v", layerName, contactCount, sizeInfo, err))
+				ip.alignStatus.SetText(fmt.Sprintf("%s: %d contacts%s%s\n%v", layerName, contactCount, sizeInfo, groupInfo, err))
 			} else {
-				ip.alignStatus.SetText(fmt.Sprintf("%s: %d contacts%s", layerName, contactCount, sizeInfo))
+				ip.alignStatus.SetText(fmt.Sprintf("%s: %d contacts%s%s", layerName, contactCount, sizeInfo, groupInfo))
 			}
 		})
 	}()
 }
 
+// shiftBackExtraDetections moves the back side's extra contact groups by the
+// translation just applied to the back image, keeping them on their contacts.
+func (ip *ImportPanel) shiftBackExtraDetections(dx, dy float64) {
+	for _, result := range ip.state.BackExtraDetections {
+		if result == nil {
+			continue
+		}
+		for i := range result.Contacts {
+			c := &result.Contacts[i]
+			c.Bounds.X += int(dx)
+			c.Bounds.Y += int(dy)
+			c.Center.X += dx
+			c.Center.Y += dy
+		}
+	}
+}
+
+// warpBackExtraDetections maps the back side's extra contact groups through
+// t, the transform just used to warp the back image, so they stay on their
+// contacts.
+func (ip *ImportPanel) warpBackExtraDetections(t geometry.AffineTransform) {
+	for _, result := range ip.state.BackExtraDetections {
+		if result == nil {
+			continue
+		}
+		for i := range result.Contacts {
+			c := &result.Contacts[i]
+			b := c.Bounds
+			minX, minY := math.Inf(1), math.Inf(1)
+			maxX, maxY := math.Inf(-1), math.Inf(-1)
+			for _, p := range []geometry.Point2D{
+				{X: float64(b.X), Y: float64(b.Y)},
+				{X: float64(b.X + b.Width), Y: float64(b.Y)},
+				{X: float64(b.X), Y: float64(b.Y + b.Height)},
+				{X: float64(b.X + b.Width), Y: float64(b.Y + b.Height)},
+			} {
+				q := t.Apply(p)
+				minX, minY = math.Min(minX, q.X), math.Min(minY, q.Y)
+				maxX, maxY = math.Max(maxX, q.X), math.Max(maxY, q.Y)
+			}
+			c.Bounds = geometry.RectInt{
+				X: int(math.Round(minX)), Y: int(math.Round(minY)),
+				Width: int(math.Round(maxX - minX)), Height: int(math.Round(maxY - minY)),
+			}
+			c.Center = t.Apply(c.Center)
+		}
+	}
+}
+
+// contactGroupSummary lists detected/expected contact counts per group when
+// the spec has more than one contact group, one line each.
+func contactGroupSummary(spec board.Spec, results []*alignment.DetectionResult) string {
+	if spec == nil || len(spec.ContactGroups()) < 2 {
+		return ""
+	}
+	var sb strings.Builder
+	for i, group := range spec.ContactGroups() {
+		found := 0
+		if i < len(results) && results[i] != nil {
+			found = len(results[i].Contacts)
+		}
+		fmt.Fprintf(&sb, "\n%s (%s): %d/%d", group.Label(i), group.Edge, found, group.Count)
+	}
+	return sb.String()
+}
+
 func (ip *ImportPanel) onNudgeAlignment(dx, dy int) {
 	isFront := ip.selectedLayer() == "Front"
 
@@ -892,6 +1161,99 @@ func (ip *ImportPanel) onNudgeCrop(dimension string, delta int) {
 	}
 
 	ip.updateCropLabel()
+	ip.refreshCropEdit()
+}
+
+// minCropSize is the smallest crop width/height, in pixels.
+const minCropSize = 10
+
+// refreshCropEdit starts, restarts, or stops crop dragging on the canvas to
+// match the toggle button and selected layer, and redraws the crop overlay.
+//
+// Crop bounds are in (import-rotated) source image coordinates, while the
+// canvas shows the cropped layer shifted by its manual offset, so the
+// rectangle is mapped by -Crop{X,Y} + ManualOffset on the way in and back on
+// the way out.
+func (ip *ImportPanel) refreshCropEdit() {
+	if ip.cropEditBtn == nil {
+		return
+	}
+
+	isFront := ip.selectedLayer() == "Front"
+	var layer *pcbimage.Layer
+	var crop *geometry.RectInt
+	var rotation float64
+	if isFront {
+		layer = ip.state.FrontImage
+		crop = &ip.state.FrontCropBounds
+		rotation = ip.state.FrontImportRotation
+	} else {
+		layer = ip.state.BackImage
+		crop = &ip.state.BackCropBounds
+		rotation = ip.state.BackImportRotation
+	}
+
+	if !ip.cropEditBtn.GetActive() || layer == nil || layer.Image == nil {
+		ip.canvas.StopEditRect()
+		ip.canvas.ClearOverlay("crop_bounds")
+		ip.canvas.Refresh()
+		return
+	}
+
+	// Source image -> canvas translation
+	dx := layer.ManualOffsetX - layer.CropX
+	dy := layer.ManualOffsetY - layer.CropY
+
+	if crop.Width <= 0 || crop.Height <= 0 {
+		b := layer.Image.Bounds()
+		*crop = geometry.RectInt{X: layer.CropX, Y: layer.CropY, Width: b.Dx(), Height: b.Dy()}
+	}
+
+	srcW, srcH := sourceImageSize(layer, rotation)
+	clamp := image.Rect(dx, dy, dx+srcW, dy+srcH)
+
+	rect := canvas.OverlayRect{X: crop.X + dx, Y: crop.Y + dy, Width: crop.Width, Height: crop.Height}
+	ip.setCropOverlay(rect)
+	ip.canvas.EditRect(rect, clamp, minCropSize, func(r canvas.OverlayRect) {
+		*crop = geometry.RectInt{X: r.X - dx, Y: r.Y - dy, Width: r.Width, Height: r.Height}
+		ip.state.SetModified(true)
+		ip.updateCropLabel()
+		ip.setCropOverlay(r)
+	})
+}
+
+// setCropOverlay draws the crop rectangle being edited.
+func (ip *ImportPanel) setCropOverlay(r canvas.OverlayRect) {
+	ip.canvas.SetOverlay("crop_bounds", &canvas.Overlay{
+		Rectangles: []canvas.OverlayRect{r},
+		Color:      colorutil.Yellow,
+		ZOrder:     100,
+	})
+	ip.canvas.Refresh()
+}
+
+// sourceImageSize returns the dimensions of the layer's source image after
+// import rotation, which bound the crop rectangle. Falls back to the current
+// crop origin plus the loaded image size if the file can't be read.
+func sourceImageSize(layer *pcbimage.Layer, rotation float64) (int, int) {
+	b := layer.Image.Bounds()
+	w, h := layer.CropX+b.Dx(), layer.CropY+b.Dy()
+
+	f, err := os.Open(layer.Path)
+	if err != nil {
+		return w, h
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return w, h
+	}
+
+	w, h = cfg.Width, cfg.Height
+	if r := math.Mod(math.Abs(rotation), 180); r > 45 && r < 135 {
+		w, h = h, w
+	}
+	return w, h
 }
 
 func (ip *ImportPanel) onReImportWithCrop() {
@@ -979,14 +1341,7 @@ func (ip *ImportPanel) onSampleSelected(x1, y1, x2, y2 float64) {
 
 	stats := extractHSVStats(canvasOutput, ix1, iy1, ix2, iy2)
 
-	colorParams := &app.ColorParams{
-		HueMin: max(0, stats.hueMean-2*stats.hueStd),
-		HueMax: min(180, stats.hueMean+2*stats.hueStd),
-		SatMin: max(0, stats.satMean-2*stats.satStd),
-		SatMax: min(255, stats.satMean+2*stats.satStd),
-		ValMin: max(0, stats.valMean-2*stats.valStd),
-		ValMax: min(255, stats.valMean+2*stats.valStd),
-	}
+	colorParams := stats.colorParams()
 
 	if ip.selectedLayer() == "Front" {
 		ip.state.FrontColorParams = colorParams
@@ -1001,8 +1356,68 @@ func (ip *ImportPanel) onSampleSelected(x1, y1, x2, y2 float64) {
 		stats.satMean, stats.satStd,
 		stats.valMean, stats.valStd,
 	))
+
+	ip.previewContacts(ip.selectedLayer() == "Front", colorParams)
+}
+
+// previewContacts runs the lightweight mask/contour contact pass with the
+// given color params and draws the candidates as outlines, so the effect of
+// a new sample is visible without a full Detect Contacts run.
+func (ip *ImportPanel) previewContacts(isFront bool, cp *app.ColorParams) {
+	img := ip.state.BackImage
+	name := "back_contact_preview"
+	layer := canvas.LayerBack
+	if isFront {
+		img = ip.state.FrontImage
+		name = "front_contact_preview"
+		layer = canvas.LayerFront
+	}
+	if img == nil || img.Image == nil || cp == nil {
+		return
+	}
+
+	ip.previewGen++
+	gen := ip.previewGen
+	status, _ := ip.alignStatus.GetText()
+
+	dpi := ip.state.DPIForSide(img.Side)
+	params := &alignment.DetectionParams{
+		HueMin: cp.HueMin, HueMax: cp.HueMax,
+		SatMin: cp.SatMin, SatMax: cp.SatMax,
+		ValMin: cp.ValMin, ValMax: cp.ValMax,
+	}
+
+	go func() {
+		result, err := alignment.MaskContacts(img.Image, ip.state.BoardSpec, dpi, params)
+		glib.IdleAdd(func() {
+			if gen != ip.previewGen {
+				return
+			}
+			if err != nil {
+				fmt.Printf("Contact preview failed: %v\n", err)
+				return
+			}
+			overlay := &canvas.Overlay{
+				Color:      color.RGBA{R: 0, G: 255, B: 255, A: 255},
+				Layer:      layer,
+				Rectangles: make([]canvas.OverlayRect, len(result.Contacts)),
+			}
+			for i, c := range result.Contacts {
+				overlay.Rectangles[i] = canvas.OverlayRect{
+					X: c.Bounds.X, Y: c.Bounds.Y,
+					Width: c.Bounds.Width, Height: c.Bounds.Height,
+				}
+			}
+			ip.canvas.SetOverlay(name, overlay)
+			ip.alignStatus.SetText(fmt.Sprintf("%s — preview: %d candidates", status, len(result.Contacts)))
+		})
+	}()
 }
 
+// dpiMismatchThreshold is the relative front/back DPI difference above which
+// auto-align rescales the back image before aligning.
+const dpiMismatchThreshold = 0.005
+
 func (ip *ImportPanel) onAutoAlign() {
 	if ip.state.FrontImage == nil || ip.state.BackImage == nil {
 		ip.alignStatus.SetText("Need both front and back images")
@@ -1063,6 +1478,34 @@ func (ip *ImportPanel) onAutoAlign() {
 			ip.state.FrontDetectionResult = frontContactResult
 		}
 
+		// Step 1b: The coarse/fine pipeline assumes both scans share a scale.
+		// If the contact pitch says otherwise, rescale the back to the front's
+		// resolution and re-detect its contacts before going further.
+		// The back may already carry an earlier rescale, reapplied on load;
+		// record the combined factor.
+		rescaleFactor := 0.0
+		totalRescale := ip.state.BackRescaleFactor
+		rescale := geometry.Identity() // Maps the back image into backImg
+		if frontContactErr == nil && backContactErr == nil {
+			frontEst := alignment.EstimateDPI(frontContactResult, ip.state.BoardSpec)
+			backEst := alignment.EstimateDPI(backContactResult, ip.state.BoardSpec)
+			if frontEst > 0 && backEst > 0 && math.Abs(frontEst/backEst-1) > dpiMismatchThreshold {
+				rescaleFactor = frontEst / backEst
+				fmt.Printf("onAutoAlign: DPI mismatch front=%.1f back=%.1f, rescaling back by %.4f\n",
+					frontEst, backEst, rescaleFactor)
+				setStatus(fmt.Sprintf("Rescaling back image by %.4f to match front DPI...", rescaleFactor))
+				backImg = alignment.RescaleToMatch(backImg, rescaleFactor)
+				rescale = geometry.Scale(rescaleFactor, rescaleFactor)
+				if totalRescale > 0 {
+					totalRescale *= rescaleFactor
+				} else {
+					totalRescale = rescaleFactor
+				}
+				backContactResult, backContactErr = alignment.DetectContactsOnTopEdge(
+					backImg, ip.state.BoardSpec, dpi, backColorParams)
+			}
+		}
+
 		// Step 2: Compute coarse transform from contacts
 		hasCoarse := false
 		var coarseTransform geometry.AffineTransform
@@ -1071,7 +1514,11 @@ func (ip *ImportPanel) onAutoAlign() {
 			len(frontContactResult.Contacts) >= 10 && len(backContactResult.Contacts) >= 10 {
 
 			var err error
-			coarseTransform, err = alignment.CoarseAlignFromContacts(frontContactResult, backContactResult)
+			coarseTransform, err = alignment.CoarseAlignAffine(frontContactResult, backContactResult)
+			if err != nil {
+				fmt.Printf("onAutoAlign: similarity fit failed (%v), using contact angles\n", err)
+				coarseTransform, err = alignment.CoarseAlignFromContacts(frontContactResult, backContactResult)
+			}
 			if err == nil {
 				hasCoarse = true
 				fmt.Printf("onAutoAlign: coarse alignment from contacts succeeded\n")
@@ -1099,7 +1546,7 @@ func (ip *ImportPanel) onAutoAlign() {
 		// Step 4: Fine alignment via iterative via matching on the coarsely-aligned images
 		setStatus("Fine alignment: detecting and matching vias...")
 		viaResult, viaErr := alignment.FineAlignViaTranslation(frontImg, coarseBack, dpi,
-			frontContactResult, backContactResult, coarseTransform)
+			frontContactResult, backContactResult, coarseTransform, nil)
 
 		if viaErr == nil && viaResult != nil && viaResult.MatchedVias >= 1 {
 			// Compose: fine (maps coarse-back → front) with coarse (maps original-back → coarse-back)
@@ -1114,9 +1561,11 @@ func (ip *ImportPanel) onAutoAlign() {
 			}
 			ip.state.BackImage.Image = warpedBack
 			ip.state.BackDetectionResult = nil
+			ip.warpBackExtraDetections(finalTransform.Compose(rescale))
 
 			ip.state.ViaAlignResult = viaResult
 			ip.state.AlignmentError = viaResult.MaxError
+			ip.state.BackRescaleFactor = totalRescale
 			ip.resetAlignmentParams()
 
 			ip.state.Aligned = true
@@ -1128,6 +1577,12 @@ func (ip *ImportPanel) onAutoAlign() {
 			fScale := math.Sqrt(ft.A*ft.A + ft.C*ft.C)
 			matchInfo := fmt.Sprintf("Aligned: %d vias, avg=%.1f max=%.1f px (rot=%.3f° scale=%.4f)",
 				viaResult.MatchedVias, viaResult.AvgError, viaResult.MaxError, fAngle, fScale)
+			if len(viaResult.Outliers) > 0 {
+				matchInfo += fmt.Sprintf(", %d rejected", len(viaResult.Outliers))
+			}
+			if rescaleFactor != 0 {
+				matchInfo += fmt.Sprintf(", back rescaled %.4f", rescaleFactor)
+			}
 
 			glib.IdleAdd(func() {
 				ip.clearAlignmentOverlays()
@@ -1148,9 +1603,11 @@ func (ip *ImportPanel) onAutoAlign() {
 
 		ip.state.BackImage.Image = coarseBack
 		ip.state.BackDetectionResult = nil
+		ip.warpBackExtraDetections(coarseTransform.Compose(rescale))
 		if viaResult != nil {
 			ip.state.ViaAlignResult = viaResult
 		}
+		ip.state.BackRescaleFactor = totalRescale
 
 		ip.resetAlignmentParams()
 		ip.state.Aligned = true
@@ -1160,8 +1617,11 @@ func (ip *ImportPanel) onAutoAlign() {
 		angleDiff := frontContactResult.ContactAngle - backContactResult.ContactAngle
 		glib.IdleAdd(func() {
 			ip.clearAlignmentOverlays()
-			ip.alignStatus.SetText(fmt.Sprintf("Contact-only: rot=%.2f°, vias failed: %v",
-				angleDiff, viaErr))
+			msg := fmt.Sprintf("Contact-only: rot=%.2f°, vias failed: %v", angleDiff, viaErr)
+			if rescaleFactor != 0 {
+				msg += fmt.Sprintf(", back rescaled %.4f", rescaleFactor)
+			}
+			ip.alignStatus.SetText(msg)
 			ip.autoAlignButton.SetSensitive(true)
 			ip.alignButton.SetSensitive(true)
 			if ip.sidePanel != nil {
@@ -1226,8 +1686,16 @@ func (ip *ImportPanel) onCoarseAlign() {
 		ip.state.BackDetectionResult = backContactResult
 
 		// Step 2: Compute coarse transform
+		// Fit scale from the matched contacts, with rotation from the per-side
+		// contact angles; fall back to the angles alone when too few pair up
 		setStatus("Computing coarse alignment...")
-		coarseTransform, err := alignment.CoarseAlignFromContacts(frontContactResult, backContactResult)
+		method := "similarity"
+		coarseTransform, err := alignment.CoarseAlignAffine(frontContactResult, backContactResult)
+		if err != nil {
+			fmt.Printf("onCoarseAlign: similarity fit failed (%v), using contact angles\n", err)
+			method = "contact angles"
+			coarseTransform, err = alignment.CoarseAlignFromContacts(frontContactResult, backContactResult)
+		}
 		if err != nil {
 			finishError(fmt.Sprintf("Coarse alignment failed: %v", err))
 			return
@@ -1242,17 +1710,20 @@ func (ip *ImportPanel) onCoarseAlign() {
 		ip.state.BackImage.Image = warpedBack
 		// Clear stale back detection result — positions are now invalid
 		ip.state.BackDetectionResult = nil
+		ip.warpBackExtraDetections(coarseTransform)
 
 		ip.resetAlignmentParams()
 		ip.state.Aligned = true
 		ip.state.SetModified(true)
 		ip.state.Emit(app.EventAlignmentComplete, nil)
 
-		angleDiff := frontContactResult.ContactAngle - backContactResult.ContactAngle
+		t := coarseTransform
+		tAngle := math.Atan2(t.C, t.A) * 180 / math.Pi
+		tScale := math.Sqrt(t.A*t.A + t.C*t.C)
 		glib.IdleAdd(func() {
 			ip.clearAlignmentOverlays()
-			ip.alignStatus.SetText(fmt.Sprintf("Contact-aligned: rot=%.2f° (front=%.2f° back=%.2f°)",
-				angleDiff, frontContactResult.ContactAngle, backContactResult.ContactAngle))
+			ip.alignStatus.SetText(fmt.Sprintf("Contact-aligned (%s): rot=%.3f° scale=%.4f (front=%.2f° back=%.2f°)",
+				method, tAngle, tScale, frontContactResult.ContactAngle, backContactResult.ContactAngle))
 			ip.coarseAlignButton.SetSensitive(true)
 			if ip.sidePanel != nil {
 				ip.sidePanel.SyncLayers()
@@ -1291,7 +1762,7 @@ func (ip *ImportPanel) onFineAlign() {
 		backImg := ip.state.BackImage.Image
 
 		viaResult, err := alignment.FineAlignViaTranslation(ip.state.FrontImage.Image, backImg, dpi,
-			nil, nil, geometry.Identity())
+			nil, nil, geometry.Identity(), nil)
 		if err != nil || viaResult == nil || viaResult.MatchedVias < 1 {
 			errMsg := "unknown error"
 			if err != nil {
@@ -1314,6 +1785,7 @@ func (ip *ImportPanel) onFineAlign() {
 			return
 		}
 		ip.state.BackImage.Image = warpedBack
+		ip.warpBackExtraDetections(viaResult.Transform)
 
 		ip.state.ViaAlignResult = viaResult
 		ip.state.AlignmentError = viaResult.MaxError
@@ -1328,6 +1800,9 @@ func (ip *ImportPanel) onFineAlign() {
 		tScale := math.Sqrt(t.A*t.A + t.C*t.C)
 		matchInfo := fmt.Sprintf("Via-fine: %d matched, avg=%.1f max=%.1f px (rot=%.3f° scale=%.4f)",
 			viaResult.MatchedVias, viaResult.AvgError, viaResult.MaxError, tAngle, tScale)
+		if len(viaResult.Outliers) > 0 {
+			matchInfo += fmt.Sprintf(", %d rejected", len(viaResult.Outliers))
+		}
 
 		glib.IdleAdd(func() {
 			ip.clearAlignmentOverlays()
@@ -1342,6 +1817,78 @@ func (ip *ImportPanel) onFineAlign() {
 	}()
 }
 
+// onAlignStepEdges shifts the back layer vertically so its step edges (where
+// the board widens below the contact fingers) line up with the front's. The
+// layers must already be roughly registered: the front contacts locate the
+// edge search on both images.
+func (ip *ImportPanel) onAlignStepEdges() {
+	front, back := ip.state.FrontImage, ip.state.BackImage
+	if front == nil || back == nil {
+		ip.alignStatus.SetText("Need both front and back images")
+		return
+	}
+	if ip.state.FrontDetectionResult == nil || len(ip.state.FrontDetectionResult.Contacts) < 2 {
+		ip.alignStatus.SetText("Detect front contacts first")
+		return
+	}
+	dpi := ip.state.DPI
+	if dpi == 0 && front.DPI > 0 {
+		dpi = front.DPI
+	}
+	if dpi <= 0 {
+		ip.alignStatus.SetText("Step edge align: DPI unknown")
+		return
+	}
+
+	// Contacts are in front image pixels; move them into the back image's
+	// pixels through the current layer offsets
+	frontContacts := ip.state.FrontDetectionResult.Contacts
+	offX := float64(front.ManualOffsetX - back.ManualOffsetX)
+	offY := float64(front.ManualOffsetY - back.ManualOffsetY)
+	backContacts := make([]alignment.Contact, len(frontContacts))
+	for i, c := range frontContacts {
+		c.Center.X += offX
+		c.Center.Y += offY
+		c.Bounds.X += int(offX)
+		c.Bounds.Y += int(offY)
+		backContacts[i] = c
+	}
+	frontImg, backImg := front.Image, back.Image
+
+	ip.alignStatus.SetText("Step edge align: detecting edges...")
+	ip.stepEdgeButton.SetSensitive(false)
+
+	go func() {
+		frontEdges := alignment.DetectStepEdgesFromImage(frontImg, frontContacts, dpi)
+		backEdges := alignment.DetectStepEdgesFromImage(backImg, backContacts, dpi)
+		rawDY, ok := alignment.FineAlignFromStepEdges(frontEdges, backEdges)
+
+		glib.IdleAdd(func() {
+			ip.stepEdgeButton.SetSensitive(true)
+			if !ok {
+				ip.alignStatus.SetText(fmt.Sprintf(
+					"Step edge align failed: %d front, %d back edges found, or edges not parallel",
+					len(frontEdges), len(backEdges)))
+				return
+			}
+
+			// rawDY compares image pixels; the layers are drawn at their offsets
+			dy := int(math.Round(rawDY + offY))
+			if dy == 0 {
+				ip.alignStatus.SetText(fmt.Sprintf("Step edges already aligned (%.1f px)", rawDY+offY))
+				return
+			}
+			ip.state.BackManualOffset.Y += dy
+			back.ManualOffsetY += dy
+			ip.state.SetModified(true)
+			ip.canvas.Refresh()
+			ip.offsetLabel.SetText(fmt.Sprintf("Offset: (%d, %d)", back.ManualOffsetX, back.ManualOffsetY))
+			ip.alignStatus.SetText(fmt.Sprintf("Step edge: moved back layer %+d px", dy))
+			fmt.Printf("Step edge align: front %v back %v -> dy=%.2f\n", frontEdges, backEdges, rawDY+offY)
+		})
+	}()
+}
+
 // resetAlignmentParams resets all manual alignment parameters to defaults.
 // Used when alignment is baked directly into image pixels (via-based alignment).
 func (ip *ImportPanel) resetAlignmentParams() {
@@ -1384,6 +1931,50 @@ func (ip *ImportPanel) resetShearDefaults() {
 	ip.state.BackImage.ShearRightY = 1.0
 }
 
+// refreshPresetList fills the preset dropdown with the presets saved for
+// the current board profile.
+func (ip *ImportPanel) refreshPresetList() {
+	if ip.presetCombo == nil {
+		return
+	}
+	ip.presetCombo.RemoveAll()
+	for _, name := range ip.state.AlignmentPresetNames() {
+		ip.presetCombo.AppendText(name)
+	}
+}
+
+// onSaveAlignmentPreset saves the manual alignment of both sides under the
+// name in the preset dropdown.
+func (ip *ImportPanel) onSaveAlignmentPreset() {
+	name := strings.TrimSpace(ip.presetCombo.GetActiveText())
+	if name == "" {
+		ip.alignStatus.SetText("Enter a preset name")
+		return
+	}
+	if err := ip.state.SaveAlignmentPreset(name); err != nil {
+		ip.alignStatus.SetText(fmt.Sprintf("Preset save failed: %v", err))
+		return
+	}
+	ip.refreshPresetList()
+	ip.alignStatus.SetText(fmt.Sprintf("Saved alignment preset %q", name))
+}
+
+// onLoadAlignmentPreset applies the selected preset to both sides.
+func (ip *ImportPanel) onLoadAlignmentPreset() {
+	name := strings.TrimSpace(ip.presetCombo.GetActiveText())
+	if name == "" {
+		ip.alignStatus.SetText("Select a preset")
+		return
+	}
+	if err := ip.state.LoadAlignmentPreset(name); err != nil {
+		ip.alignStatus.SetText(fmt.Sprintf("Preset load failed: %v", err))
+		return
+	}
+	ip.RefreshLabels()
+	ip.canvas.Refresh()
+	ip.alignStatus.SetText(fmt.Sprintf("Applied alignment preset %q", name))
+}
+
 // clearAlignmentOverlays removes all alignment-related overlays from the canvas.
 func (ip *ImportPanel) clearAlignmentOverlays() {
 	ip.canvas.ClearOverlay("front_contacts")
@@ -1419,6 +2010,7 @@ func (ip *ImportPanel) onAlignImages() {
 
 	ip.alignStatus.SetText("Aligning images...")
 	ip.alignButton.SetSensitive(false)
+	perspective := ip.perspectiveCheck.GetActive()
 
 	go func() {
 		dpi := ip.state.DPI
@@ -1445,49 +2037,31 @@ func (ip *ImportPanel) onAlignImages() {
 
 		translatedBack := translateImage(ip.state.BackImage.Image, int(deltaX), int(deltaY))
 
-		frontMarks := alignment.DetectEjectorMarksFromImage(ip.state.FrontImage.Image, frontContacts, dpi)
+		frontMarks := alignment.DetectRegistrationMarksFromImage(ip.state.FrontImage.Image, frontContacts, dpi, ip.state.BoardSpec)
 
 		translatedBackContacts := make([]alignment.Contact, len(backContacts))
 		for i, c := range backContacts {
 			translatedBackContacts[i] = c
 			translatedBackContacts[i].Center.X += deltaX
 			translatedBackContacts[i].Center.Y += deltaY
+			translatedBackContacts[i].Bounds.X += int(deltaX)
+			translatedBackContacts[i].Bounds.Y += int(deltaY)
 		}
-		backMarks := alignment.DetectEjectorMarksFromImage(translatedBack, translatedBackContacts, dpi)
+		backMarks := alignment.DetectRegistrationMarksFromImage(translatedBack, translatedBackContacts, dpi, ip.state.BoardSpec)
 
 		var finalImage image.Image = translatedBack
 		var alignInfo string
 
-		if len(frontMarks) >= 2 && len(backMarks) >= 2 {
-			var frontLeft, frontRight, backLeft, backRight *alignment.EjectorMark
-			for i := range frontMarks {
-				if frontMarks[i].Side == "left" {
-					frontLeft = &frontMarks[i]
-				} else if frontMarks[i].Side == "right" {
-					frontRight = &frontMarks[i]
-				}
-			}
-			for i := range backMarks {
-				if backMarks[i].Side == "left" {
-					backLeft = &backMarks[i]
-				} else if backMarks[i].Side == "right" {
-					backRight = &backMarks[i]
-				}
-			}
-
-			if frontLeft != nil && frontRight != nil && backLeft != nil && backRight != nil {
-				contactY := frontAvgY
-				finalImage, alignInfo = applyShearAlignment(
-					translatedBack,
-					backLeft.Center, backRight.Center,
-					frontLeft.Center, frontRight.Center,
-					contactY,
-				)
+		aligned := false
+		if perspective {
+			finalImage, alignInfo, aligned = alignPerspective(translatedBack, frontContacts, translatedBackContacts, frontMarks, backMarks)
+		}
+		if !aligned {
+			if img, info, ok := alignToRegistrationMarks(translatedBack, frontMarks, backMarks, frontAvgY, dpi); ok {
+				finalImage, alignInfo = img, info
 			} else {
-				alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (missing ejector marks)", deltaX, deltaY)
+				alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (too few registration marks)", deltaX, deltaY)
 			}
-		} else {
-			alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (no ejector marks)", deltaX, deltaY)
 		}
 
 		ip.state.BackImage.Image = finalImage
@@ -1505,6 +2079,7 @@ func (ip *ImportPanel) onAlignImages() {
 			}
 		}
 		ip.state.BackDetectionResult.Contacts = alignedBackContacts
+		ip.shiftBackExtraDetections(deltaX, deltaY)
 
 		glib.IdleAdd(func() {
 			ip.createContactOverlay("back_contacts", alignedBackContacts, color.RGBA{R: 0, G: 0, B: 255, A: 255}, canvas.LayerBack)
@@ -1527,6 +2102,20 @@ func (ip *ImportPanel) onAlignImages() {
 	}()
 }
 
+// exportOptions returns the export format, DPI and quality selected in the
+// alignment controls.
+func (ip *ImportPanel) exportOptions() pcbimage.ExportOptions {
+	opts := pcbimage.DefaultExportOptions()
+	if id := ip.exportFormat.GetActiveID(); id != "" {
+		opts.Format = id
+	}
+	opts.DPI = ip.exportDPISpin.GetValue()
+	if opts.Format == pcbimage.FormatJPEG {
+		opts.Quality = ip.exportQualitySpin.GetValueAsInt()
+	}
+	return opts
+}
+
 func (ip *ImportPanel) onSaveAligned() {
 	if ip.state.ProjectPath == "" {
 		dlg := gtk.MessageDialogNew(ip.win, gtk.DIALOG_MODAL, gtk.MESSAGE_INFO, gtk.BUTTONS_OK,
@@ -1547,6 +2136,7 @@ func (ip *ImportPanel) onSaveAligned() {
 	}
 
 	projectDir := filepath.Dir(ip.state.ProjectPath)
+	opts := ip.exportOptions()
 
 	ip.saveAlignedBtn.SetSensitive(false)
 	ip.alignStatus.SetText("Normalizing images...")
@@ -1555,12 +2145,12 @@ func (ip *ImportPanel) onSaveAligned() {
 		var errs []string
 
 		if ip.state.FrontImage != nil {
-			if err := ip.state.NormalizeFrontImage(projectDir); err != nil {
+			if err := ip.state.NormalizeFrontImage(projectDir, opts); err != nil {
 				errs = append(errs, "Front: "+err.Error())
 			}
 		}
 		if ip.state.BackImage != nil {
-			if err := ip.state.NormalizeBackImage(projectDir); err != nil {
+			if err := ip.state.NormalizeBackImage(projectDir, opts); err != nil {
 				errs = append(errs, "Back: "+err.Error())
 			}
 		}
@@ -1616,6 +2206,16 @@ func (ip *ImportPanel) onRealign() {
 		ip.state.FrontNormalizedPath = ""
 		ip.state.BackNormalizedPath = ""
 
+		// Unset (0) shear in a fresh or legacy state means identity, not collapse.
+		for _, v := range []*float64{
+			&ip.state.FrontShearTopX, &ip.state.FrontShearBottomX,
+			&ip.state.FrontShearLeftY, &ip.state.FrontShearRightY,
+			&ip.state.BackShearTopX, &ip.state.BackShearBottomX,
+			&ip.state.BackShearLeftY, &ip.state.BackShearRightY,
+		} {
+			*v = pcbimage.ShearFactor(*v)
+		}
+
 		if ip.state.FrontImage != nil {
 			ip.state.FrontImage.ManualOffsetX = ip.state.FrontManualOffset.X
 			ip.state.FrontImage.ManualOffsetY = ip.state.FrontManualOffset.Y
@@ -1678,12 +2278,12 @@ func (ip *ImportPanel) createEjectorOverlay(name string, marks []alignment.Eject
 		Layer:      layer,
 		Rectangles: make([]canvas.OverlayRect, len(marks)),
 	}
-	markerSize := 40
+	markerSize := int(ip.canvas.MarkerSize(canvas.MarkerEjector, ip.state.DPI))
 	for i, mark := range marks {
 		overlay.Rectangles[i] = canvas.OverlayRect{
 			X: int(mark.Center.X) - markerSize/2, Y: int(mark.Center.Y) - markerSize/2,
 			Width: markerSize, Height: markerSize,
-			Label: mark.Side, Fill: canvas.FillTarget,
+			Label: mark.Name, Fill: canvas.FillTarget, Marker: true,
 		}
 	}
 	ip.canvas.SetOverlay(name, overlay)
@@ -1769,15 +2369,17 @@ func (ip *ImportPanel) performAlignment(frontContacts, backContacts []alignment.
 
 	translatedBack := translateImage(ip.state.BackImage.Image, int(deltaX), int(deltaY))
 
-	frontMarks := alignment.DetectEjectorMarksFromImage(ip.state.FrontImage.Image, frontContacts, dpi)
+	frontMarks := alignment.DetectRegistrationMarksFromImage(ip.state.FrontImage.Image, frontContacts, dpi, ip.state.BoardSpec)
 
 	translatedBackContacts := make([]alignment.Contact, len(backContacts))
 	for i, c := range backContacts {
 		translatedBackContacts[i] = c
 		translatedBackContacts[i].Center.X += deltaX
 		translatedBackContacts[i].Center.Y += deltaY
+		translatedBackContacts[i].Bounds.X += int(deltaX)
+		translatedBackContacts[i].Bounds.Y += int(deltaY)
 	}
-	backMarks := alignment.DetectEjectorMarksFromImage(translatedBack, translatedBackContacts, dpi)
+	backMarks := alignment.DetectRegistrationMarksFromImage(translatedBack, translatedBackContacts, dpi, ip.state.BoardSpec)
 
 	fmt.Printf("Auto-align: front ejector marks=%d, back ejector marks=%d\n", len(frontMarks), len(backMarks))
 
@@ -1787,37 +2389,16 @@ func (ip *ImportPanel) performAlignment(frontContacts, backContacts []alignment.
 	var finalImage image.Image = translatedBack
 	var alignInfo string
 
-	if len(frontMarks) >= 2 && len(backMarks) >= 2 {
-		var frontLeft, frontRight, backLeft, backRight *alignment.EjectorMark
-		for i := range frontMarks {
-			if frontMarks[i].Side == "left" {
-				frontLeft = &frontMarks[i]
-			} else if frontMarks[i].Side == "right" {
-				frontRight = &frontMarks[i]
-			}
-		}
-		for i := range backMarks {
-			if backMarks[i].Side == "left" {
-				backLeft = &backMarks[i]
-			} else if backMarks[i].Side == "right" {
-				backRight = &backMarks[i]
-			}
-		}
-
-		if frontLeft != nil && frontRight != nil && backLeft != nil && backRight != nil {
-			contactY := frontAvgY
-			finalImage, alignInfo = applyShearAlignment(
-				translatedBack,
-				backLeft.Center, backRight.Center,
-				frontLeft.Center, frontRight.Center,
-				contactY,
-			)
-			fmt.Printf("Auto-align: shear alignment: %s\n", alignInfo)
+	aligned := false
+	if ip.perspectiveCheck.GetActive() {
+		finalImage, alignInfo, aligned = alignPerspective(translatedBack, frontContacts, translatedBackContacts, frontMarks, backMarks)
+	}
+	if !aligned {
+		if img, info, ok := alignToRegistrationMarks(translatedBack, frontMarks, backMarks, frontAvgY, dpi); ok {
+			finalImage, alignInfo = img, info
 		} else {
-			alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (missing ejector marks)", deltaX, deltaY)
+			alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (too few registration marks)", deltaX, deltaY)
 		}
-	} else {
-		alignInfo = fmt.Sprintf("translated (%.1f, %.1f) px (no ejector marks)", deltaX, deltaY)
 	}
 
 	ip.state.BackImage.Image = finalImage
@@ -1835,6 +2416,7 @@ func (ip *ImportPanel) performAlignment(frontContacts, backContacts []alignment.
 		}
 	}
 	ip.state.BackDetectionResult.Contacts = alignedBackContacts
+	ip.shiftBackExtraDetections(deltaX, deltaY)
 	ip.createContactOverlay("back_contacts", alignedBackContacts, color.RGBA{R: 0, G: 0, B: 255, A: 255}, canvas.LayerBack)
 
 	ip.alignStatus.SetText("Aligned: " + alignInfo)
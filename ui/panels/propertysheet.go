@@ -443,7 +443,8 @@ func (ps *PropertySheet) applyAll() {
 		ps.state.FrontImage.AutoScaleY = ps.state.FrontAutoScaleY
 		ps.state.FrontImage.RotationCenterX = ps.state.FrontRotationCenter.X
 		ps.state.FrontImage.RotationCenterY = ps.state.FrontRotationCenter.Y
-		ensureShearDefaults(ps.state.FrontImage)
+		ps.state.FrontImage.EnsureShearDefaults()
+		ensureAutoScaleDefaults(ps.state.FrontImage)
 	}
 
 	if ps.state.BackImage != nil {
@@ -459,7 +460,8 @@ func (ps *PropertySheet) applyAll() {
 		ps.state.BackImage.AutoScaleY = ps.state.BackAutoScaleY
 		ps.state.BackImage.RotationCenterX = ps.state.BackRotationCenter.X
 		ps.state.BackImage.RotationCenterY = ps.state.BackRotationCenter.Y
-		ensureShearDefaults(ps.state.BackImage)
+		ps.state.BackImage.EnsureShearDefaults()
+		ensureAutoScaleDefaults(ps.state.BackImage)
 	}
 
 	ps.state.SetModified(true)
@@ -470,19 +472,8 @@ func (ps *PropertySheet) applyAll() {
 	}
 }
 
-func ensureShearDefaults(img *pcbimage.Layer) {
-	if img.ShearTopX == 0 {
-		img.ShearTopX = 1.0
-	}
-	if img.ShearBottomX == 0 {
-		img.ShearBottomX = 1.0
-	}
-	if img.ShearLeftY == 0 {
-		img.ShearLeftY = 1.0
-	}
-	if img.ShearRightY == 0 {
-		img.ShearRightY = 1.0
-	}
+// ensureAutoScaleDefaults treats an unset (zero) auto scale as 1.0.
+func ensureAutoScaleDefaults(img *pcbimage.Layer) {
 	if img.AutoScaleX == 0 {
 		img.AutoScaleX = 1.0
 	}
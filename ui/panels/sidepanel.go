@@ -74,7 +74,7 @@ func NewSidePanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window, p
 	stack.AddNamed(sp.importPanel.Widget(), PanelImport)
 
 	// Create components panel
-	sp.componentsPanel = NewComponentsPanel(state, cvs, win)
+	sp.componentsPanel = NewComponentsPanel(state, cvs, win, p)
 	stack.AddNamed(sp.componentsPanel.Widget(), PanelComponents)
 
 	// Create traces panel
@@ -147,11 +147,13 @@ func (sp *SidePanel) ShowPanel(name string) {
 		sp.canvas.OnHover(nil)
 		sp.canvas.OnMiddleClick(sp.componentsPanel.OnMiddleClickFloodFill)
 		sp.canvas.OnLeftClick(func(x, y float64) { sp.componentsPanel.OnLeftClick(x, y) })
+		sp.canvas.OnDoubleClick(nil)
 		sp.canvas.OnRightClick(func(x, y float64) { sp.componentsPanel.onRightClickDeleteComponent(x, y) })
 		sp.componentsPanel.Refresh()
 	case PanelTraces:
 		sp.canvas.OnMiddleClick(func(x, y float64) { sp.tracesPanel.onMiddleClick(x, y) })
 		sp.canvas.OnLeftClick(func(x, y float64) { sp.tracesPanel.onLeftClick(x, y) })
+		sp.canvas.OnDoubleClick(func(x, y float64) { sp.tracesPanel.onDoubleClick(x, y) })
 		sp.canvas.OnRightClick(func(x, y float64) { sp.tracesPanel.onRightClickVia(x, y) })
 		sp.canvas.OnRightSelect(func(x1, y1, x2, y2 float64) { sp.tracesPanel.onRightSelect(x1, y1, x2, y2) })
 		sp.canvas.OnHover(func(x, y float64) { sp.tracesPanel.onHover(x, y) })
@@ -159,17 +161,20 @@ func (sp *SidePanel) ShowPanel(name string) {
 		sp.canvas.OnHover(nil)
 		sp.canvas.OnMiddleClick(func(x, y float64) { sp.logosPanel.OnMiddleClick(x, y) })
 		sp.canvas.OnLeftClick(nil)
+		sp.canvas.OnDoubleClick(nil)
 		sp.canvas.OnRightClick(nil)
 	case PanelLibrary:
 		sp.canvas.OnHover(nil)
 		sp.canvas.OnMiddleClick(nil)
 		sp.canvas.OnLeftClick(nil)
+		sp.canvas.OnDoubleClick(nil)
 		sp.canvas.OnRightClick(nil)
 		sp.libraryPanel.RefreshPartList()
 	default:
 		sp.canvas.OnHover(nil)
 		sp.canvas.OnMiddleClick(nil)
 		sp.canvas.OnLeftClick(nil)
+		sp.canvas.OnDoubleClick(nil)
 		sp.canvas.OnRightClick(nil)
 	}
 
@@ -268,6 +273,11 @@ func (sp *SidePanel) SyncBoardSelection() {
 	sp.importPanel.syncBoardSelection()
 }
 
+// AssignGridIDs relabels components by silkscreen grid cell after a preview.
+func (sp *SidePanel) AssignGridIDs() {
+	sp.componentsPanel.AssignGridIDs()
+}
+
 // OnKeyPressed dispatches key events to the active panel.
 func (sp *SidePanel) OnKeyPressed(ev *gdk.EventKey) bool {
 	switch sp.currentPanel {
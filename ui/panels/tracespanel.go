@@ -1,17 +1,20 @@
 package panels
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"math"
+	"os"
 	"path/filepath"
-	"runtime"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"pcb-tracer/internal/app"
@@ -51,9 +54,21 @@ type TracesPanel struct {
 	viaLayerFront       *gtk.RadioButton
 	viaLayerBack        *gtk.RadioButton
 	detectViasBtn       *gtk.Button
+	regionViasBtn       *gtk.Button
+	stopViasBtn         *gtk.Button
+	viaCancel           context.CancelFunc // Cancels the running detection; nil when idle
 	clearViasBtn        *gtk.Button
 	matchViasBtn        *gtk.Button
+	snapGridBtn         *gtk.Button
+	preBlurCombo        *gtk.ComboBoxText
+	preBlurRadiusSpin   *gtk.SpinButton
+	useClassifierCheck  *gtk.CheckButton
+	houghConfirmCheck   *gtk.CheckButton
+	calibrateBtn        *gtk.Button
+	houghCalibration    *via.HoughCalibration // From Calibrate; nil = defaults
+	viaProfile          *via.DetectionParams  // Loaded board via params; nil = defaults
 	viaStatusLabel      *gtk.Label
+	viaProgress         *gtk.ProgressBar
 	viaCountLabel       *gtk.Label
 	confirmedCountLabel *gtk.Label
 	trainingLabel       *gtk.Label
@@ -61,6 +76,13 @@ type TracesPanel struct {
 	// Trace detection UI
 	traceStatusLabel *gtk.Label
 
+	// Auto-trace cost tuning UI
+	copperRewardSpin      *gtk.SpinButton
+	soldermaskPenaltySpin *gtk.SpinButton
+	turnPenaltySpin       *gtk.SpinButton
+	pathCostLabel         *gtk.Label
+	syncingCostSpins      bool // Suppress value-changed while loading params into the spins
+
 	// Add-component mode: click to set second corner of selection rectangle
 	addComponentMode  bool
 	addComponentStart geometry.Point2D // first corner (from right-click position)
@@ -79,6 +101,9 @@ type TracesPanel struct {
 	// Last created trace ID (for adding to net)
 	lastTraceID string
 
+	// Deferred click on a trace segment; a double-click bumps it to cancel
+	pendingClick int
+
 	// Vertex drag state
 	draggingVertex bool
 	dragTraceID    string
@@ -90,8 +115,14 @@ type TracesPanel struct {
 	// Selected connector for arrow-key nudging
 	selectedConnector *connector.Connector
 
+	// Connector drag state
+	draggingConnector bool
+	dragConnOffset    geometry.Point2D // Connector center minus the grab point
+
 	// Add connectors button
 	addConnectorsBtn *gtk.Button
+	boardPinoutBtn   *gtk.Button
+	loadPinoutBtn    *gtk.Button
 
 	// Default via radius for manual addition
 	defaultViaRadius float64
@@ -110,15 +141,41 @@ type TracesPanel struct {
 	selectedNetID string     // currently selected net ID
 	netIDs        []string   // cached net IDs in display order for row-index mapping
 
+	netElementIDs  []string // element IDs in element-list row order
+	netPathStartID string   // element the signal path is ordered from ("" = default)
+
+	// Half (unmatched) vias list
+	unmatchedLabel   *gtk.Label
+	unmatchedListBox *gtk.ListBox
+	unmatchedViaIDs  []string // via IDs in list row order
+
 	// Preferences
-	prefs          *prefs.Prefs
-	showViaNumbers bool
-	showPinNames   bool
+	prefs            *prefs.Prefs
+	showViaNumbers   bool
+	showPinNames     bool
+	fadeInactiveSide bool // Draw the non-raised side's detected vias translucent
+	showSizeOutliers bool // Outline detected vias with an anomalous radius in red
+	followCopper     bool // Suggest a copper route when a trace's end via is clicked
 }
 
 // NewTracesPanel creates a new traces panel.
 const prefKeyShowViaNumbers = "showViaNumbers"
 const prefKeyShowPinNames = "showPinNames"
+const prefKeyFadeInactiveSide = "fadeInactiveSide"
+const prefKeyViaPreBlur = "viaPreBlur"
+const prefKeyViaPreBlurRadius = "viaPreBlurRadius"
+const prefKeyViaUseClassifier = "viaUseClassifier"
+const prefKeyMarkerScale = "markerScale"
+const prefKeyMarkerScreenConstant = "markerScreenConstant"
+const prefKeyShowSizeOutliers = "showViaSizeOutliers"
+const prefKeyFollowCopper = "followCopper"
+
+// prefKeyViaParamsPrefix prefixes the board profile name in the key of
+// saved via detection params.
+const prefKeyViaParamsPrefix = "viaParams."
+
+// inactiveSideAlpha is the overlay alpha for elements on the non-raised side.
+const inactiveSideAlpha = 0.25
 
 func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window, p *prefs.Prefs) *TracesPanel {
 	tp := &TracesPanel{
@@ -129,8 +186,13 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 		prefs:            p,
 		showViaNumbers:   p.Bool(prefKeyShowViaNumbers, true),
 		showPinNames:     p.Bool(prefKeyShowPinNames, true),
+		fadeInactiveSide: p.Bool(prefKeyFadeInactiveSide, true),
+		showSizeOutliers: p.Bool(prefKeyShowSizeOutliers, false),
+		followCopper:     p.Bool(prefKeyFollowCopper, false),
 	}
 
+	cvs.SetMarkerStyle(p.FloatWithFallback(prefKeyMarkerScale, 1.0), p.Bool(prefKeyMarkerScreenConstant, false))
+
 	tp.box, _ = gtk.BoxNew(gtk.ORIENTATION_VERTICAL, 4)
 	tp.box.SetMarginStart(4)
 	tp.box.SetMarginEnd(4)
@@ -172,20 +234,121 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	btnRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
 	tp.detectViasBtn, _ = gtk.ButtonNewWithLabel("Detect Vias")
 	tp.detectViasBtn.Connect("clicked", func() { tp.onDetectVias() })
+	tp.regionViasBtn, _ = gtk.ButtonNewWithLabel("In Region")
+	tp.regionViasBtn.SetTooltipText("Drag a rectangle to re-detect vias only inside it, replacing the unmatched vias there")
+	tp.regionViasBtn.Connect("clicked", func() { tp.onDetectViasInRegion() })
+	tp.stopViasBtn, _ = gtk.ButtonNewWithLabel("Stop")
+	tp.stopViasBtn.SetSensitive(false)
+	tp.stopViasBtn.Connect("clicked", func() {
+		if tp.viaCancel != nil {
+			tp.viaCancel()
+		}
+	})
 	tp.clearViasBtn, _ = gtk.ButtonNewWithLabel("Clear")
 	tp.clearViasBtn.Connect("clicked", func() { tp.onClearVias() })
 	btnRow.PackStart(tp.detectViasBtn, false, false, 0)
+	btnRow.PackStart(tp.regionViasBtn, false, false, 0)
+	btnRow.PackStart(tp.stopViasBtn, false, false, 0)
 	btnRow.PackStart(tp.clearViasBtn, false, false, 0)
 	viaBox.PackStart(btnRow, false, false, 0)
 
+	// Noise pre-filter: fewer false positives on grainy/JPEG scans at the
+	// cost of fine detail. Radius is in image pixels and should grow with DPI.
+	blurRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	blurLabel, _ := gtk.LabelNew("Pre-blur:")
+	tp.preBlurCombo, _ = gtk.ComboBoxTextNew()
+	for _, m := range []via.PreBlurMode{via.PreBlurNone, via.PreBlurMedian, via.PreBlurGaussian} {
+		tp.preBlurCombo.AppendText(m.String())
+	}
+	tp.preBlurCombo.SetActive(int(p.FloatWithFallback(prefKeyViaPreBlur, float64(via.PreBlurNone))))
+	tp.preBlurRadiusSpin, _ = gtk.SpinButtonNewWithRange(1, 15, 1)
+	tp.preBlurRadiusSpin.SetValue(p.FloatWithFallback(prefKeyViaPreBlurRadius, 1))
+	tp.preBlurRadiusSpin.SetTooltipText("Filter radius in pixels (~1 per 300 DPI)")
+	savePreBlur := func() {
+		tp.prefs.SetFloat(prefKeyViaPreBlur, float64(tp.preBlurCombo.GetActive()))
+		tp.prefs.SetFloat(prefKeyViaPreBlurRadius, tp.preBlurRadiusSpin.GetValue())
+		tp.prefs.Save()
+	}
+	tp.preBlurCombo.Connect("changed", savePreBlur)
+	tp.preBlurRadiusSpin.Connect("value-changed", savePreBlur)
+	blurRow.PackStart(blurLabel, false, false, 0)
+	blurRow.PackStart(tp.preBlurCombo, false, false, 0)
+	blurRow.PackStart(tp.preBlurRadiusSpin, false, false, 0)
+	viaBox.PackStart(blurRow, false, false, 0)
+
+	// Learned classifier: trained from the via training samples and used to
+	// decide candidates that sit right at a detection threshold
+	tp.useClassifierCheck, _ = gtk.CheckButtonNewWithLabel("Use learned classifier")
+	tp.useClassifierCheck.SetActive(p.Bool(prefKeyViaUseClassifier, false))
+	tp.useClassifierCheck.SetTooltipText("Let a classifier trained on the via training samples decide borderline candidates")
+	tp.useClassifierCheck.Connect("toggled", func() {
+		tp.prefs.SetBool(prefKeyViaUseClassifier, tp.useClassifierCheck.GetActive())
+		tp.prefs.Save()
+	})
+	viaBox.PackStart(tp.useClassifierCheck, false, false, 0)
+
+	// Hough cross-validation, with param2/minDist tuned against the positive
+	// training samples by Calibrate
+	houghRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	tp.houghConfirmCheck, _ = gtk.CheckButtonNewWithLabel("Hough confirm")
+	tp.houghConfirmCheck.SetTooltipText("Keep only candidates also found by Hough circle detection")
+	tp.calibrateBtn, _ = gtk.ButtonNewWithLabel("Calibrate")
+	tp.calibrateBtn.SetTooltipText("Tune Hough parameters to find the positive training vias")
+	tp.calibrateBtn.Connect("clicked", func() { tp.onCalibrateHough() })
+	houghRow.PackStart(tp.houghConfirmCheck, false, false, 0)
+	houghRow.PackStart(tp.calibrateBtn, false, false, 0)
+	viaBox.PackStart(houghRow, false, false, 0)
+
+	// Via color: sampled from one good via for boards whose vias are
+	// tarnished or plated differently from the defaults
+	colorRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	sampleViaBtn, _ := gtk.ButtonNewWithLabel("Sample Via")
+	sampleViaBtn.SetTooltipText("Drag a rectangle over a good via to detect vias of its color")
+	sampleViaBtn.Connect("clicked", func() { tp.onSampleVia() })
+	defaultColorBtn, _ := gtk.ButtonNewWithLabel("Default Color")
+	defaultColorBtn.SetTooltipText("Forget the sampled via color")
+	defaultColorBtn.Connect("clicked", func() {
+		tp.state.ViaColorParams = nil
+		tp.viaStatusLabel.SetText("Via color reset to defaults")
+	})
+	colorRow.PackStart(sampleViaBtn, false, false, 0)
+	colorRow.PackStart(defaultColorBtn, false, false, 0)
+	viaBox.PackStart(colorRow, false, false, 0)
+
+	// Tuned params are saved per board profile and reloaded with projects
+	// for the same board
+	paramsRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	saveParamsBtn, _ := gtk.ButtonNewWithLabel("Save via params")
+	saveParamsBtn.SetTooltipText("Remember the pre-blur and Hough settings for this board type")
+	saveParamsBtn.Connect("clicked", func() { tp.onSaveViaParams() })
+	loadParamsBtn, _ := gtk.ButtonNewWithLabel("Load via params")
+	loadParamsBtn.SetTooltipText("Restore the via settings saved for this board type")
+	loadParamsBtn.Connect("clicked", func() {
+		if !tp.loadViaParams() {
+			tp.viaStatusLabel.SetText(fmt.Sprintf("No via params saved for %s", tp.viaProfileName()))
+		}
+	})
+	paramsRow.PackStart(saveParamsBtn, false, false, 0)
+	paramsRow.PackStart(loadParamsBtn, false, false, 0)
+	viaBox.PackStart(paramsRow, false, false, 0)
+
 	tp.matchViasBtn, _ = gtk.ButtonNewWithLabel("Match Vias")
 	tp.matchViasBtn.Connect("clicked", func() { tp.tryMatchVias() })
 	viaBox.PackStart(tp.matchViasBtn, false, false, 0)
 
+	tp.snapGridBtn, _ = gtk.ButtonNewWithLabel("Snap to Grid")
+	tp.snapGridBtn.SetTooltipText("Move confirmed via centers onto the board's contact-pitch grid")
+	tp.snapGridBtn.Connect("clicked", func() { tp.onSnapViasToGrid() })
+	viaBox.PackStart(tp.snapGridBtn, false, false, 0)
+
 	detectPinsBtn, _ := gtk.ButtonNewWithLabel("Detect Pins")
 	detectPinsBtn.Connect("clicked", func() { tp.onDetectPins() })
 	viaBox.PackStart(detectPinsBtn, false, false, 0)
 
+	assignPinsBtn, _ := gtk.ButtonNewWithLabel("Assign Vias to Pins")
+	assignPinsBtn.Connect("clicked", func() { tp.onAssignViasToPins() })
+	viaBox.PackStart(assignPinsBtn, false, false, 0)
+
 	showViaNumCheck, _ := gtk.CheckButtonNewWithLabel("Show via numbers")
 	showViaNumCheck.SetActive(tp.showViaNumbers)
 	showViaNumCheck.Connect("toggled", func() {
@@ -208,15 +371,84 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	})
 	viaBox.PackStart(showPinNameCheck, false, false, 0)
 
+	fadeCheck, _ := gtk.CheckButtonNewWithLabel("Fade inactive side")
+	fadeCheck.SetActive(tp.fadeInactiveSide)
+	fadeCheck.Connect("toggled", func() {
+		tp.fadeInactiveSide = fadeCheck.GetActive()
+		tp.prefs.SetBool(prefKeyFadeInactiveSide, tp.fadeInactiveSide)
+		tp.prefs.Save()
+		tp.rebuildFeaturesOverlay()
+		tp.canvas.Refresh()
+	})
+	viaBox.PackStart(fadeCheck, false, false, 0)
+
+	outlierCheck, _ := gtk.CheckButtonNewWithLabel("Mark size outliers")
+	outlierCheck.SetActive(tp.showSizeOutliers)
+	outlierCheck.SetTooltipText("Ring detected vias whose radius is far from the median in red (likely pads or merged blobs)")
+	outlierCheck.Connect("toggled", func() {
+		tp.showSizeOutliers = outlierCheck.GetActive()
+		tp.prefs.SetBool(prefKeyShowSizeOutliers, tp.showSizeOutliers)
+		tp.prefs.Save()
+		tp.rebuildFeaturesOverlay()
+		tp.canvas.Refresh()
+	})
+	viaBox.PackStart(outlierCheck, false, false, 0)
+
+	// Marker size: scales trace vertex dots and alignment marks
+	markerRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	markerLabel, _ := gtk.LabelNew("Marker size:")
+	markerRow.PackStart(markerLabel, false, false, 0)
+	markerScaleSpin, _ := gtk.SpinButtonNewWithRange(0.25, 4.0, 0.25)
+	markerScaleSpin.SetDigits(2)
+	markerScaleSpin.SetValue(p.FloatWithFallback(prefKeyMarkerScale, 1.0))
+	markerRow.PackStart(markerScaleSpin, false, false, 0)
+	markerScreenCheck, _ := gtk.CheckButtonNewWithLabel("Constant on screen")
+	markerScreenCheck.SetActive(p.Bool(prefKeyMarkerScreenConstant, false))
+	markerRow.PackStart(markerScreenCheck, false, false, 0)
+	onMarkerStyle := func() {
+		tp.prefs.SetFloat(prefKeyMarkerScale, markerScaleSpin.GetValue())
+		tp.prefs.SetBool(prefKeyMarkerScreenConstant, markerScreenCheck.GetActive())
+		tp.prefs.Save()
+		tp.canvas.SetMarkerStyle(markerScaleSpin.GetValue(), markerScreenCheck.GetActive())
+		if tp.state.FeaturesLayer != nil {
+			tp.rebuildFeaturesOverlayFast()
+		}
+	}
+	markerScaleSpin.Connect("value-changed", onMarkerStyle)
+	markerScreenCheck.Connect("toggled", onMarkerStyle)
+	viaBox.PackStart(markerRow, false, false, 0)
+
+	// Side-specific overlay alpha depends on which layer is raised
+	cvs.OnLayerRaise(func(side pcbimage.Side) {
+		if tp.fadeInactiveSide && tp.state.FeaturesLayer != nil {
+			tp.rebuildFeaturesOverlayFast()
+		}
+	})
+
 	tp.addConnectorsBtn, _ = gtk.ButtonNewWithLabel("Add Connectors")
 	tp.addConnectorsBtn.Connect("clicked", func() { tp.onAddConnectors() })
-	viaBox.PackStart(tp.addConnectorsBtn, false, false, 0)
+	tp.boardPinoutBtn, _ = gtk.ButtonNewWithLabel("Board Pinout")
+	tp.boardPinoutBtn.SetTooltipText("Name connectors from the pinout bundled for the board profile")
+	tp.boardPinoutBtn.Connect("clicked", func() { tp.onApplyBoardPinout() })
+	tp.loadPinoutBtn, _ = gtk.ButtonNewWithLabel("Load Pinout...")
+	tp.loadPinoutBtn.SetTooltipText("Name connectors from a pin,signal CSV file")
+	tp.loadPinoutBtn.Connect("clicked", func() { tp.onLoadPinout() })
+	connRow, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	connRow.PackStart(tp.addConnectorsBtn, false, false, 0)
+	connRow.PackStart(tp.boardPinoutBtn, false, false, 0)
+	connRow.PackStart(tp.loadPinoutBtn, false, false, 0)
+	viaBox.PackStart(connRow, false, false, 0)
 
 	tp.viaStatusLabel, _ = gtk.LabelNew("")
 	tp.viaStatusLabel.SetLineWrap(true)
 	tp.viaStatusLabel.SetHAlign(gtk.ALIGN_START)
 	viaBox.PackStart(tp.viaStatusLabel, false, false, 0)
 
+	tp.viaProgress, _ = gtk.ProgressBarNew()
+	tp.viaProgress.SetShowText(true)
+	tp.viaProgress.SetNoShowAll(true)
+	viaBox.PackStart(tp.viaProgress, false, false, 0)
+
 	tp.viaCountLabel, _ = gtk.LabelNew("No vias detected")
 	tp.viaCountLabel.SetHAlign(gtk.ALIGN_START)
 	viaBox.PackStart(tp.viaCountLabel, false, false, 0)
@@ -225,6 +457,27 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	tp.confirmedCountLabel.SetHAlign(gtk.ALIGN_START)
 	viaBox.PackStart(tp.confirmedCountLabel, false, false, 0)
 
+	// Half vias: detected on one side with no match on the other
+	tp.unmatchedLabel, _ = gtk.LabelNew("Unmatched: 0 front, 0 back")
+	tp.unmatchedLabel.SetHAlign(gtk.ALIGN_START)
+	viaBox.PackStart(tp.unmatchedLabel, false, false, 0)
+	unmatchedSW, _ := gtk.ScrolledWindowNew(nil, nil)
+	unmatchedSW.SetPolicy(gtk.POLICY_NEVER, gtk.POLICY_AUTOMATIC)
+	unmatchedSW.SetSizeRequest(-1, 80)
+	tp.unmatchedListBox, _ = gtk.ListBoxNew()
+	tp.unmatchedListBox.SetSelectionMode(gtk.SELECTION_SINGLE)
+	tp.unmatchedListBox.Connect("row-activated", func() { tp.onJumpToUnmatchedVia() })
+	unmatchedSW.Add(tp.unmatchedListBox)
+	viaBox.PackStart(unmatchedSW, false, false, 0)
+	unmatchedBtnBox, _ := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 4)
+	jumpBtn, _ := gtk.ButtonNewWithLabel("Jump To")
+	jumpBtn.Connect("clicked", func() { tp.onJumpToUnmatchedVia() })
+	unmatchedBtnBox.PackStart(jumpBtn, true, true, 0)
+	deleteUnmatchedBtn, _ := gtk.ButtonNewWithLabel("Delete")
+	deleteUnmatchedBtn.Connect("clicked", func() { tp.onDeleteUnmatchedVia() })
+	unmatchedBtnBox.PackStart(deleteUnmatchedBtn, true, true, 0)
+	viaBox.PackStart(unmatchedBtnBox, false, false, 0)
+
 	tp.trainingLabel, _ = gtk.LabelNew("Training: 0 pos, 0 neg")
 	tp.trainingLabel.SetHAlign(gtk.ALIGN_START)
 	viaBox.PackStart(tp.trainingLabel, false, false, 0)
@@ -233,7 +486,7 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	viaBox.PackStart(sep, false, false, 2)
 
 	helpTexts := []string{
-		"Cyan=front  Magenta=back  Blue=both",
+		"Cyan=front  Magenta=back  Blue=both  ?=unmatched",
 		"Click via/conn: start trace  Click empty: add via",
 		"While drawing: click waypoints, right/mid cancels",
 		"Right-click: menu  Arrow keys: nudge selected via",
@@ -267,11 +520,61 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 	traceAutoRow.PackStart(autoTraceLayerBtn, false, false, 0)
 	traceBox.PackStart(traceAutoRow, false, false, 0)
 
+	followCopperCheck, _ := gtk.CheckButtonNewWithLabel("Follow copper")
+	followCopperCheck.SetTooltipText("When a trace's end via is clicked, suggest a route along the copper; click the via again to accept it, or edit the waypoints")
+	followCopperCheck.SetActive(tp.followCopper)
+	followCopperCheck.Connect("toggled", func() {
+		tp.followCopper = followCopperCheck.GetActive()
+		tp.prefs.SetBool(prefKeyFollowCopper, tp.followCopper)
+		tp.prefs.Save()
+	})
+	traceBox.PackStart(followCopperCheck, false, false, 0)
+
+	exportNetsBtn, _ := gtk.ButtonNewWithLabel("Export Netlist...")
+	exportNetsBtn.SetTooltipText("Write every net and its pins, connectors and vias to CSV")
+	exportNetsBtn.Connect("clicked", func() { tp.onExportNetCSV() })
+	traceBox.PackStart(exportNetsBtn, false, false, 0)
+
+	compareNetsBtn, _ := gtk.ButtonNewWithLabel("Compare Netlist...")
+	compareNetsBtn.SetTooltipText("Check the traced nets against an expected netlist (CSV as exported, or JSON) and list missing and extra connections")
+	compareNetsBtn.Connect("clicked", func() { tp.onCompareNetlist() })
+	traceBox.PackStart(compareNetsBtn, false, false, 0)
+
 	tp.traceStatusLabel, _ = gtk.LabelNew("Click via/connector to start trace")
 	tp.traceStatusLabel.SetLineWrap(true)
 	tp.traceStatusLabel.SetHAlign(gtk.ALIGN_START)
 	traceBox.PackStart(tp.traceStatusLabel, false, false, 0)
 
+	// Auto-trace cost weights: boards with unusual copper color or contrast
+	// need different trade-offs between staying on copper and path length
+	costExpander, _ := gtk.ExpanderNew("Auto-trace cost")
+	costGrid, _ := gtk.GridNew()
+	costGrid.SetColumnSpacing(4)
+	costGrid.SetRowSpacing(2)
+	addCostRow := func(row int, label, tooltip string, min, max, step float64) *gtk.SpinButton {
+		lbl, _ := gtk.LabelNew(label)
+		lbl.SetHAlign(gtk.ALIGN_END)
+		spin, _ := gtk.SpinButtonNewWithRange(min, max, step)
+		spin.SetDigits(2)
+		spin.SetTooltipText(tooltip)
+		spin.Connect("value-changed", func() { tp.onAutoTraceCostChanged() })
+		costGrid.Attach(lbl, 0, row, 1, 1)
+		costGrid.Attach(spin, 1, row, 1, 1)
+		return spin
+	}
+	tp.copperRewardSpin = addCostRow(0, "Copper reward:",
+		"Fraction of the per-pixel cost waived on copper", 0, 1, 0.05)
+	tp.soldermaskPenaltySpin = addCostRow(1, "Mask penalty:",
+		"Extra per-pixel cost off copper", 0, 50, 0.5)
+	tp.turnPenaltySpin = addCostRow(2, "Turn penalty:",
+		"Extra cost per 45° change of direction", 0, 50, 0.5)
+	tp.pathCostLabel, _ = gtk.LabelNew("Path cost: -")
+	tp.pathCostLabel.SetHAlign(gtk.ALIGN_START)
+	costGrid.Attach(tp.pathCostLabel, 0, 3, 2, 1)
+	costExpander.Add(costGrid)
+	traceBox.PackStart(costExpander, false, false, 0)
+	tp.syncAutoTraceCostSpins()
+
 	traceFrame.Add(traceBox)
 	tp.box.PackStart(traceFrame, false, false, 0)
 
@@ -304,6 +607,9 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 		}
 		idx := row.GetIndex()
 		if idx >= 0 && idx < len(tp.netIDs) {
+			if tp.selectedNetID != tp.netIDs[idx] {
+				tp.netPathStartID = ""
+			}
 			tp.selectedNetID = tp.netIDs[idx]
 			tp.highlightNet(tp.netIDs[idx])
 			tp.refreshNetElements()
@@ -419,6 +725,8 @@ func NewTracesPanel(state *app.State, cvs *canvas.ImageCanvas, win *gtk.Window,
 			if len(confirmed) > 0 {
 				tp.confirmedCountLabel.SetText(fmt.Sprintf("Confirmed: %d", len(confirmed)))
 			}
+			tp.syncAutoTraceCostSpins()
+			tp.loadViaParams()
 		})
 	})
 
@@ -446,6 +754,14 @@ func (tp *TracesPanel) selectedSide() pcbimage.Side {
 	return pcbimage.SideBack
 }
 
+// traceLayerSide returns the image side a trace layer is drawn on.
+func traceLayerSide(layer pcbtrace.TraceLayer) pcbimage.Side {
+	if layer == pcbtrace.LayerFront {
+		return pcbimage.SideFront
+	}
+	return pcbimage.SideBack
+}
+
 // selectedTraceLayer returns the trace layer matching the current layer selection.
 func (tp *TracesPanel) selectedTraceLayer() pcbtrace.TraceLayer {
 	if tp.viaLayerFront.GetActive() {
@@ -457,9 +773,13 @@ func (tp *TracesPanel) selectedTraceLayer() pcbtrace.TraceLayer {
 // SetEnabled enables or disables the panel's interactive widgets.
 func (tp *TracesPanel) SetEnabled(enabled bool) {
 	tp.detectViasBtn.SetSensitive(enabled)
+	tp.regionViasBtn.SetSensitive(enabled)
 	tp.clearViasBtn.SetSensitive(enabled)
 	tp.matchViasBtn.SetSensitive(enabled)
+	tp.snapGridBtn.SetSensitive(enabled)
 	tp.addConnectorsBtn.SetSensitive(enabled)
+	tp.boardPinoutBtn.SetSensitive(enabled)
+	tp.loadPinoutBtn.SetSensitive(enabled)
 }
 
 // OnKeyPressed handles keyboard input for arrow-key via nudging and Escape cancellation.
@@ -473,6 +793,10 @@ func (tp *TracesPanel) OnKeyPressed(ev *gdk.EventKey) bool {
 			tp.cancelVertexDrag()
 			return true
 		}
+		if tp.draggingConnector {
+			tp.cancelConnectorDrag()
+			return true
+		}
 		if tp.traceMode {
 			tp.cancelTrace()
 			return true
@@ -636,6 +960,7 @@ func (tp *TracesPanel) cancelTrace() {
 	tp.canvas.ClearOverlay("trace_segments")
 	tp.canvas.OnMouseMove(nil)
 	tp.traceMode = false
+	tp.updatePathCostLabel()
 	tp.traceStartVia = nil
 	tp.traceStartConn = nil
 	tp.traceStartJunctionTrace = ""
@@ -649,6 +974,57 @@ func (tp *TracesPanel) cancelTrace() {
 
 // onDetectVias runs via detection on the selected layer.
 func (tp *TracesPanel) onDetectVias() {
+	tp.runViaDetection(nil)
+}
+
+// onDetectViasInRegion waits for a rectangle to be dragged on the canvas and
+// runs via detection on the selected layer inside it only.
+func (tp *TracesPanel) onDetectViasInRegion() {
+	tp.viaStatusLabel.SetText("Drag a rectangle to detect vias in")
+	tp.canvas.SelectRegion(func(x1, y1, x2, y2 float64) {
+		region := geometry.RectInt{X: int(x1), Y: int(y1), Width: int(x2 - x1), Height: int(y2 - y1)}
+		if region.Width < 2 || region.Height < 2 {
+			tp.viaStatusLabel.SetText("Region too small")
+			return
+		}
+		tp.runViaDetection(&region)
+	})
+}
+
+// onSampleVia lets the user drag a rectangle over a via on the selected
+// layer and sets the via color to the region's HSV mean ± 2σ.
+func (tp *TracesPanel) onSampleVia() {
+	layer := tp.state.BackImage
+	if tp.selectedSide() == pcbimage.SideFront {
+		layer = tp.state.FrontImage
+	}
+	if layer == nil || layer.Image == nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("No %s image loaded", tp.selectedLayer()))
+		return
+	}
+
+	tp.viaStatusLabel.SetText("Drag a rectangle over a good via...")
+	tp.canvas.SelectRegion(func(x1, y1, x2, y2 float64) {
+		ix1, iy1, ix2, iy2 := int(x1), int(y1), int(x2), int(y2)
+		if ix2-ix1 < 2 || iy2-iy1 < 2 {
+			tp.viaStatusLabel.SetText("Region too small")
+			return
+		}
+		stats := extractHSVStats(layer.Image, ix1, iy1, ix2, iy2)
+		tp.state.ViaColorParams = stats.colorParams()
+		tp.viaStatusLabel.SetText(fmt.Sprintf(
+			"Via sampled: H(%.0f±%.0f) S(%.0f±%.0f) V(%.0f±%.0f)",
+			stats.hueMean, stats.hueStd,
+			stats.satMean, stats.satStd,
+			stats.valMean, stats.valStd,
+		))
+	})
+}
+
+// runViaDetection detects vias on the selected layer, in the whole image or
+// only inside region. A region run replaces the unmatched detected vias
+// centered in it and leaves the rest of the layer alone.
+func (tp *TracesPanel) runViaDetection(region *geometry.RectInt) {
 	isFront := tp.selectedLayer() == "Front"
 
 	var img *pcbimage.Layer
@@ -670,10 +1046,7 @@ func (tp *TracesPanel) onDetectVias() {
 		return
 	}
 
-	dpi := tp.state.DPI
-	if dpi == 0 && img.DPI > 0 {
-		dpi = img.DPI
-	}
+	dpi := tp.state.DPIForSide(side)
 	if dpi == 0 {
 		tp.viaStatusLabel.SetText("DPI unknown - load a TIFF with DPI metadata")
 		return
@@ -681,59 +1054,74 @@ func (tp *TracesPanel) onDetectVias() {
 
 	tp.viaStatusLabel.SetText(fmt.Sprintf("Detecting vias on %s...", layerName))
 	tp.detectViasBtn.SetSensitive(false)
+	tp.regionViasBtn.SetSensitive(false)
+
+	params := tp.viaParams(dpi)
+	params.Progress = tp.viaProgressFunc("Verifying")
+	tp.viaProgress.SetFraction(0)
+	tp.viaProgress.SetText("")
+	tp.viaProgress.Show()
+	useClassifier := tp.useClassifierCheck.GetActive()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tp.viaCancel = cancel
+	tp.stopViasBtn.SetSensitive(true)
 
 	go func() {
-		params := via.DefaultParams().WithDPI(dpi)
-		result, err := via.DetectViasFromImage(img.Image, side, params)
+		defer glib.IdleAdd(func() {
+			cancel()
+			tp.viaCancel = nil
+			tp.stopViasBtn.SetSensitive(false)
+		})
+
+		if useClassifier {
+			if c, err := tp.trainViaClassifier(img.Image, side, params); err != nil {
+				fmt.Printf("Via classifier not used: %v\n", err)
+			} else {
+				params.UseClassifier = true
+				params.Classifier = c
+			}
+		}
+
+		var result *via.ViaDetectionResult
+		var err error
+		if region != nil {
+			result, err = via.DetectViasInRegion(ctx, img.Image, side, params, *region)
+		} else {
+			result, err = via.DetectViasFromImage(ctx, img.Image, side, params)
+		}
 
 		glib.IdleAdd(func() {
 			tp.detectViasBtn.SetSensitive(true)
+			tp.regionViasBtn.SetSensitive(true)
 		})
 
-		if err != nil {
+		// A stopped run still keeps the vias verified before the stop
+		stopped := errors.Is(err, context.Canceled)
+		if err != nil && !stopped {
 			glib.IdleAdd(func() {
+				tp.viaProgress.Hide()
 				tp.viaStatusLabel.SetText(fmt.Sprintf("Error: %v", err))
 			})
 			return
 		}
 
+		if result.Report != nil {
+			fmt.Print(result.Report.String())
+		}
+
 		// Post-process: detect metal boundaries
 		numVias := len(result.Vias)
 		fmt.Printf("Post-processing %d detected vias to find metal boundaries (parallel)...\n", numVias)
 		maxRadius := 0.030 * dpi
 
 		startTime := time.Now()
-		numWorkers := runtime.NumCPU()
-		if numWorkers > numVias {
-			numWorkers = numVias
-		}
-		if numWorkers < 1 {
-			numWorkers = 1
-		}
-
-		var wg sync.WaitGroup
-		viaChan := make(chan int, numVias)
-
-		for w := 0; w < numWorkers; w++ {
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				for i := range viaChan {
-					v := &result.Vias[i]
-					boundary := via.DetectMetalBoundary(img.Image, v.Center.X, v.Center.Y, maxRadius)
-					v.PadBoundary = boundary.Boundary
-					v.Center = boundary.Center
-					v.Radius = boundary.Radius
-				}
-			}()
+		if err := via.RefineBoundaries(ctx, img.Image, result.Vias, maxRadius, tp.viaProgressFunc("Boundaries")); err != nil {
+			stopped = true
 		}
-		for i := range result.Vias {
-			viaChan <- i
-		}
-		close(viaChan)
-		wg.Wait()
 		elapsed := time.Since(startTime)
-		fmt.Printf("Post-processing complete (%d workers, %.1fms)\n", numWorkers, float64(elapsed.Microseconds())/1000)
+		fmt.Printf("Post-processing complete (%.1fms)\n", float64(elapsed.Microseconds())/1000)
+		glib.IdleAdd(func() { tp.viaProgress.Hide() })
 
 		// Filter out vias that overlap with existing detected pins
 		pinVias := tp.state.FeaturesLayer.GetConfirmedVias()
@@ -761,19 +1149,251 @@ func (tp *TracesPanel) onDetectVias() {
 		}
 		result.Vias = filtered
 
+		if region != nil {
+			result.Vias = tp.mergeRegionVias(side, *region, result.Vias)
+		}
 		tp.state.FeaturesLayer.AddVias(result.Vias)
 
 		glib.IdleAdd(func() {
 			tp.rebuildFeaturesOverlay()
 			front, back := tp.state.FeaturesLayer.ViaCountBySide()
 			tp.viaCountLabel.SetText(fmt.Sprintf("Vias: %d front, %d back", front, back))
-			tp.viaStatusLabel.SetText(fmt.Sprintf("%s: %d vias detected",
-				layerName, len(result.Vias)))
+			status := fmt.Sprintf("%s: %d vias detected", layerName, len(result.Vias))
+			if region != nil {
+				status = fmt.Sprintf("%s: %d vias detected in %dx%d region", layerName, len(result.Vias), region.Width, region.Height)
+			}
+			if stopped {
+				status += " (stopped early)"
+			}
+			if result.Report != nil {
+				status += "\n" + result.Report.Summary()
+				tp.viaStatusLabel.SetTooltipText(result.Report.String())
+			}
+			tp.viaStatusLabel.SetText(status)
 			tp.state.Emit(app.EventFeaturesChanged, nil)
 		})
 	}()
 }
 
+// mergeRegionVias prepares vias found by a region run for adding to the
+// features layer. Unmatched detected vias on side centered in region are
+// removed, as the new run supersedes them; vias already matched across
+// sides are kept, and new vias overlapping them are dropped. The new vias
+// are renumbered after the highest existing ID on side.
+func (tp *TracesPanel) mergeRegionVias(side pcbimage.Side, region geometry.RectInt, found []via.Via) []via.Via {
+	inside := region.ToFloat()
+	prefix := fmt.Sprintf("via-%s-", side.String()[:1])
+	maxN := 0
+	var kept []via.Via
+	for _, v := range tp.state.FeaturesLayer.GetViasBySide(side) {
+		if inside.Contains(v.Center) && v.MatchedViaID == "" {
+			tp.state.FeaturesLayer.RemoveVia(v.ID)
+			continue
+		}
+		if inside.Contains(v.Center) {
+			kept = append(kept, v)
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(v.ID, prefix)); err == nil && n > maxN {
+			maxN = n
+		}
+	}
+
+	var merged []via.Via
+	for _, v := range found {
+		overlaps := false
+		for _, k := range kept {
+			if v.Center.Distance(k.Center) < v.Radius+k.Radius {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		maxN++
+		v.ID = fmt.Sprintf("%s%03d", prefix, maxN)
+		merged = append(merged, v)
+	}
+	return merged
+}
+
+// viaProgressFunc returns a via.ProgressFunc that shows stage progress in
+// the via progress bar. It may be called from any goroutine; updates are
+// posted to the main loop only when the percentage changes.
+func (tp *TracesPanel) viaProgressFunc(stage string) via.ProgressFunc {
+	var last atomic.Int64
+	last.Store(-1)
+	return func(done, total int) {
+		if total <= 0 {
+			return
+		}
+		pct := int64(done * 100 / total)
+		if prev := last.Load(); pct <= prev || !last.CompareAndSwap(prev, pct) {
+			return
+		}
+		glib.IdleAdd(func() {
+			tp.viaProgress.SetFraction(float64(pct) / 100)
+			tp.viaProgress.SetText(fmt.Sprintf("%s %d/%d", stage, done, total))
+		})
+	}
+}
+
+// viaParams returns the detection params for an image at dpi: the board's
+// loaded via params (or the defaults) with the sampled via color and the
+// panel's pre-blur and Hough settings.
+func (tp *TracesPanel) viaParams(dpi float64) via.DetectionParams {
+	params := via.DefaultParams()
+	if tp.viaProfile != nil {
+		params = *tp.viaProfile
+	}
+	params = params.AtDPI(dpi).WithPreBlur(
+		via.PreBlurMode(tp.preBlurCombo.GetActive()), tp.preBlurRadiusSpin.GetValueAsInt())
+	if c := tp.state.ViaColorParams; c != nil {
+		params = params.WithHSV(c.HueMin, c.HueMax, c.SatMin, c.SatMax, c.ValMin, c.ValMax)
+	}
+	params.RequireHoughConfirm = tp.houghConfirmCheck.GetActive()
+	if c := tp.houghCalibration; c != nil && params.RequireHoughConfirm {
+		params.HoughParam2 = c.Params.HoughParam2
+		// minDist is in pixels; rescale if the layers differ in DPI
+		params.HoughMinDist = int(float64(c.Params.HoughMinDist)*dpi/c.Params.DPI + 0.5)
+	}
+	return params
+}
+
+// viaProfileName returns the board profile that via params are saved under.
+func (tp *TracesPanel) viaProfileName() string {
+	if tp.state.BoardSpec == nil {
+		return "default"
+	}
+	return tp.state.BoardSpec.Name()
+}
+
+// onSaveViaParams saves the current via detection params under the board
+// profile, at the DPI of the selected side.
+func (tp *TracesPanel) onSaveViaParams() {
+	dpi := tp.state.DPIForSide(tp.selectedSide())
+	params := tp.viaParams(dpi)
+	data, err := json.Marshal(params)
+	if err != nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("Save via params failed: %v", err))
+		return
+	}
+	tp.prefs.SetString(prefKeyViaParamsPrefix+tp.viaProfileName(), string(data))
+	if err := tp.prefs.Save(); err != nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("Save via params failed: %v", err))
+		return
+	}
+	tp.viaProfile = &params
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Saved via params for %s", tp.viaProfileName()))
+}
+
+// loadViaParams restores the via params saved for the board profile and
+// syncs the pre-blur and Hough controls to them. Returns false if none are
+// saved.
+func (tp *TracesPanel) loadViaParams() bool {
+	name := tp.viaProfileName()
+	data := tp.prefs.String(prefKeyViaParamsPrefix + name)
+	if data == "" {
+		return false
+	}
+	params := via.DefaultParams()
+	if err := json.Unmarshal([]byte(data), &params); err != nil {
+		fmt.Printf("Via params for %s unreadable: %v\n", name, err)
+		return false
+	}
+	params = params.WithPreBlur(params.PreBlur, params.PreBlurRadius)
+
+	tp.viaProfile = &params
+	tp.houghCalibration = nil     // The saved Hough values take its place
+	tp.state.ViaColorParams = nil // As do the saved HSV bounds
+	tp.preBlurCombo.SetActive(int(params.PreBlur))
+	if params.PreBlurRadius > 0 {
+		tp.preBlurRadiusSpin.SetValue(float64(params.PreBlurRadius))
+	}
+	tp.houghConfirmCheck.SetActive(params.RequireHoughConfirm)
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Loaded via params for %s", name))
+	return true
+}
+
+// onCalibrateHough tunes the Hough cross-validation parameters on the
+// selected layer, using the positive training samples as ground truth.
+func (tp *TracesPanel) onCalibrateHough() {
+	layerName := tp.selectedLayer()
+	side := tp.selectedSide()
+	img := tp.state.BackImage
+	if side == pcbimage.SideFront {
+		img = tp.state.FrontImage
+	}
+	if img == nil || img.Image == nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("No %s image loaded", layerName))
+		return
+	}
+	dpi := tp.state.DPIForSide(side)
+	if dpi == 0 {
+		tp.viaStatusLabel.SetText("DPI unknown - load a TIFF with DPI metadata")
+		return
+	}
+	if tp.state.ViaTrainingSet == nil {
+		tp.viaStatusLabel.SetText("No via training set loaded")
+		return
+	}
+
+	var known []geometry.Point2D
+	for _, s := range tp.state.ViaTrainingSet.GetPositiveSamples() {
+		if s.Side == side {
+			known = append(known, s.Center)
+		}
+	}
+	if len(known) == 0 {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("No positive training vias on %s to calibrate against", layerName))
+		return
+	}
+
+	params := tp.viaParams(dpi)
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Calibrating Hough on %d %s vias...", len(known), layerName))
+	tp.calibrateBtn.SetSensitive(false)
+
+	go func() {
+		cal, err := via.CalibrateHough(img.Image, known, params)
+		glib.IdleAdd(func() {
+			tp.calibrateBtn.SetSensitive(true)
+			if err != nil {
+				tp.viaStatusLabel.SetText(fmt.Sprintf("Calibration failed: %v", err))
+				return
+			}
+			tp.houghCalibration = &cal
+			tp.houghConfirmCheck.SetActive(true)
+			tp.viaStatusLabel.SetText(fmt.Sprintf("Hough param2=%.0f minDist=%d: found %d/%d known vias, %d extra circles",
+				cal.Params.HoughParam2, cal.Params.HoughMinDist, cal.Found, cal.Known, cal.FalsePositives))
+		})
+	}()
+}
+
+// trainViaClassifier measures any unmeasured training samples on side and
+// fits a classifier to the measured samples. Newly measured features are
+// saved so later runs skip the measurement.
+func (tp *TracesPanel) trainViaClassifier(srcImg image.Image, side pcbimage.Side, params via.DetectionParams) (*via.Classifier, error) {
+	ts := tp.state.ViaTrainingSet
+	if ts == nil {
+		return nil, fmt.Errorf("no training set loaded")
+	}
+	n, err := ts.MeasureFeatures(srcImg, side, params)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		if err := ts.Save(); err != nil {
+			fmt.Printf("Warning: failed to save via training features: %v\n", err)
+		}
+	}
+	c, err := via.TrainClassifier(ts)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Via classifier trained on %d positive, %d negative samples\n", c.Positives, c.Negatives)
+	return c, nil
+}
+
 // rebuildFeaturesOverlay rebuilds the three feature overlays from all model data.
 
 // Front/back overlays track their respective image layers for visibility;
@@ -791,7 +1411,9 @@ func (tp *TracesPanel) rebuildFeaturesOverlay() {
 func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 	if reconcileNets {
 		tp.state.FeaturesLayer.ReconcileNets(5.0)
+		tp.state.FeaturesLayer.AutoNameNetsFromConnectors()
 		tp.state.FeaturesLayer.PropagateLogicNames(tp.state.Components, tp.state.ComponentLibrary)
+		tp.state.FeaturesLayer.FindShorts()
 	}
 
 	// Clear import panel diagnostic overlays so they don't cover features
@@ -802,9 +1424,17 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 	backOverlay := &canvas.Overlay{Layer: canvas.LayerBack, ZOrder: 10}
 	viasOverlay := &canvas.Overlay{ZOrder: 0}
 
+	vertexRadius := tp.canvas.MarkerSize(canvas.MarkerTraceVertex, tp.state.DPI)
+	sideDotRadius := tp.canvas.MarkerSize(canvas.MarkerViaSideDot, tp.state.DPI)
+
 	cyan := &colorutil.Cyan
 	magenta := &colorutil.Magenta
 	blue := &colorutil.Blue
+	red := &colorutil.Red
+
+	// With fading enabled, elements of the side that isn't raised are drawn
+	// translucent
+	activeSide := tp.canvas.ActiveSide()
 
 	// 1. Connectors: split by side
 	for _, c := range tp.state.FeaturesLayer.GetConnectors() {
@@ -824,6 +1454,7 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 			col = magenta
 			target = backOverlay
 		}
+		col = sideOverlayColor(col, c.Side, activeSide, tp.fadeInactiveSide)
 		target.Rectangles = append(target.Rectangles, canvas.OverlayRect{
 			X: c.Bounds.X, Y: c.Bounds.Y, Width: c.Bounds.Width, Height: c.Bounds.Height,
 			Fill:         canvas.FillSolid,
@@ -837,19 +1468,7 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 	green := &color.RGBA{R: 0, G: 200, B: 0, A: 255}
 	orange := &color.RGBA{R: 255, G: 165, B: 0, A: 255}
 	for _, cv := range tp.state.FeaturesLayer.GetConfirmedVias() {
-		label := ""
-		if tp.showPinNames && cv.SignalName != "" {
-			label = cv.SignalName
-		} else if tp.showPinNames && cv.ComponentID != "" && cv.PinNumber != "" {
-			label = fmt.Sprintf("%s-%s", cv.ComponentID, cv.PinNumber)
-		} else if tp.showViaNumbers {
-			var viaNum int
-			if _, err := fmt.Sscanf(cv.ID, "cvia-%d", &viaNum); err == nil {
-				label = fmt.Sprintf("%d", viaNum)
-			} else {
-				label = cv.ID
-			}
-		}
+		label := cv.DisplayLabel(tp.showPinNames, tp.showViaNumbers)
 		// Add pull-up/pull-down indicator to label
 		if cv.PullUp {
 			label = "↑" + label
@@ -880,8 +1499,11 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 		}
 	}
 
-	// 3. Detected vias: cyan (front) / magenta (back) solid filled circles
-	skipMatched := tp.state.FeaturesLayer.ConfirmedViaCount() > 0
+	// 3. Detected vias: cyan (front) / magenta (back) solid filled circles.
+	// Once matching has run, matched vias are hidden (their confirmed via is
+	// drawn) and unmatched "half" vias are drawn as outlines marked "?".
+	// Faded like the connectors.
+	matchingDone := tp.state.FeaturesLayer.ConfirmedViaCount() > 0
 	for _, side := range []pcbimage.Side{pcbimage.SideFront, pcbimage.SideBack} {
 		var col *color.RGBA
 		if side == pcbimage.SideFront {
@@ -889,31 +1511,46 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 		} else {
 			col = magenta
 		}
-		for _, v := range tp.state.FeaturesLayer.GetViasBySide(side) {
-			if skipMatched && v.BothSidesConfirmed {
-				continue
+		col = sideOverlayColor(col, side, activeSide, tp.fadeInactiveSide)
+		vias := tp.state.FeaturesLayer.GetViasBySide(side)
+		if tp.showSizeOutliers {
+			for _, i := range via.FlagOutliers(vias, via.DefaultOutlierZ) {
+				v := vias[i]
+				viasOverlay.Circles = append(viasOverlay.Circles, canvas.OverlayCircle{
+					X: v.Center.X, Y: v.Center.Y, Radius: v.Radius + 3, Color: red,
+				})
 			}
-			viasOverlay.Circles = append(viasOverlay.Circles, canvas.OverlayCircle{
+		}
+		for _, v := range vias {
+			circle := canvas.OverlayCircle{
 				X:      v.Center.X,
 				Y:      v.Center.Y,
 				Radius: v.Radius,
-				Filled: true,
 				Color:  col,
-			})
+			}
+			switch detectedViaStyleFor(v, matchingDone) {
+			case viaStyleHidden:
+				continue
+			case viaStyleUnmatched:
+				circle.Label = "?"
+			default:
+				circle.Filled = true
+			}
+			viasOverlay.Circles = append(viasOverlay.Circles, circle)
 		}
 	}
+	tp.refreshUnmatchedVias(matchingDone)
 
 	// 4. Completed traces: split by layer, colored by net status
-	red := &color.RGBA{R: 255, G: 0, B: 0, A: 255}
 	for _, tid := range tp.state.FeaturesLayer.GetTraces() {
 		tf := tp.state.FeaturesLayer.GetTraceFeature(tid)
 		if tf == nil || len(tf.Points) < 2 {
 			continue
 		}
 		var target *canvas.Overlay
-		// Pick color: named net → cyan (front) / magenta (back), unnamed → red
+		// Pick color: named net → cyan (front) / magenta (back), unnamed or shorted → red
 		net := tp.state.FeaturesLayer.GetNetForElement(tid)
-		hasNamedNet := net != nil && net.Name != "" && net.Name != net.ID
+		hasNamedNet := net != nil && net.Name != "" && net.Name != net.ID && !net.HasErrors
 		var traceColor *color.RGBA
 		if tf.Layer == pcbtrace.LayerBack {
 			target = backOverlay
@@ -942,8 +1579,8 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 		// so they are always pickable on top of vias.
 		for _, pt := range tf.Points {
 			target.Circles = append(target.Circles, canvas.OverlayCircle{
-				X: pt.X, Y: pt.Y, Radius: 6, Filled: true,
-				Color: traceColor,
+				X: pt.X, Y: pt.Y, Radius: vertexRadius, Filled: true,
+				Color: traceColor, Marker: true,
 			})
 		}
 	}
@@ -954,12 +1591,14 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 	for _, cv := range tp.state.FeaturesLayer.GetConfirmedVias() {
 		if tp.state.FeaturesLayer.ViaHasTraceOnLayer(cv.Center, 5.0, pcbtrace.LayerBack) {
 			frontOverlay.Circles = append(frontOverlay.Circles, canvas.OverlayCircle{
-				X: cv.Center.X, Y: cv.Center.Y, Radius: 3, Filled: true, Color: black,
+				X: cv.Center.X, Y: cv.Center.Y, Radius: sideDotRadius, Filled: true, Color: black,
+				Marker: true,
 			})
 		}
 		if tp.state.FeaturesLayer.ViaHasTraceOnLayer(cv.Center, 5.0, pcbtrace.LayerFront) {
 			backOverlay.Circles = append(backOverlay.Circles, canvas.OverlayCircle{
-				X: cv.Center.X, Y: cv.Center.Y, Radius: 3, Filled: true, Color: black,
+				X: cv.Center.X, Y: cv.Center.Y, Radius: sideDotRadius, Filled: true, Color: black,
+				Marker: true,
 			})
 		}
 	}
@@ -969,6 +1608,116 @@ func (tp *TracesPanel) rebuildFeaturesOverlayImpl(reconcileNets bool) {
 	tp.canvas.SetOverlay(OverlayFeaturesVias, viasOverlay)
 }
 
+// detectedViaStyle selects how a single-side detected via is drawn.
+type detectedViaStyle int
+
+const (
+	viaStyleFilled    detectedViaStyle = iota // Matching not run yet: filled circle
+	viaStyleUnmatched                         // Half via (no partner on the other side): outline + "?"
+	viaStyleHidden                            // Matched: shown as its confirmed via instead
+)
+
+// detectedViaStyleFor returns the style for v. Until front/back matching
+// has produced confirmed vias there is no notion of unmatched, so every via
+// is drawn filled.
+func detectedViaStyleFor(v via.Via, matchingDone bool) detectedViaStyle {
+	switch {
+	case !matchingDone:
+		return viaStyleFilled
+	case v.BothSidesConfirmed:
+		return viaStyleHidden
+	default:
+		return viaStyleUnmatched
+	}
+}
+
+// refreshUnmatchedVias rebuilds the list of half vias, front then back.
+func (tp *TracesPanel) refreshUnmatchedVias(matchingDone bool) {
+	if tp.unmatchedListBox == nil {
+		return
+	}
+	tp.unmatchedListBox.GetChildren().Foreach(func(item interface{}) {
+		if w, ok := item.(*gtk.Widget); ok {
+			tp.unmatchedListBox.Remove(w)
+		}
+	})
+
+	tp.unmatchedViaIDs = tp.unmatchedViaIDs[:0]
+	var front, back int
+	if matchingDone {
+		for _, side := range []pcbimage.Side{pcbimage.SideFront, pcbimage.SideBack} {
+			for _, v := range tp.state.FeaturesLayer.GetViasBySide(side) {
+				if detectedViaStyleFor(v, true) != viaStyleUnmatched {
+					continue
+				}
+				if side == pcbimage.SideFront {
+					front++
+				} else {
+					back++
+				}
+				tp.unmatchedViaIDs = append(tp.unmatchedViaIDs, v.ID)
+				row, _ := gtk.LabelNew(fmt.Sprintf("%s %s (%.0f, %.0f)",
+					side, v.ID, v.Center.X, v.Center.Y))
+				row.SetHAlign(gtk.ALIGN_START)
+				tp.unmatchedListBox.Add(row)
+			}
+		}
+	}
+	tp.unmatchedListBox.ShowAll()
+	tp.unmatchedLabel.SetText(fmt.Sprintf("Unmatched: %d front, %d back", front, back))
+}
+
+// selectedUnmatchedVia returns the via selected in the unmatched list.
+func (tp *TracesPanel) selectedUnmatchedVia() *via.Via {
+	row := tp.unmatchedListBox.GetSelectedRow()
+	if row == nil {
+		return nil
+	}
+	idx := row.GetIndex()
+	if idx < 0 || idx >= len(tp.unmatchedViaIDs) {
+		return nil
+	}
+	return tp.state.FeaturesLayer.GetViaByID(tp.unmatchedViaIDs[idx])
+}
+
+// onJumpToUnmatchedVia raises the via's side and scrolls it into view.
+func (tp *TracesPanel) onJumpToUnmatchedVia() {
+	v := tp.selectedUnmatchedVia()
+	if v == nil {
+		return
+	}
+	tp.canvas.RaiseLayerBySide(v.Side)
+	r := int(v.Radius) * 4
+	tp.canvas.ScrollToRegion(int(v.Center.X)-r, int(v.Center.Y)-r, 2*r, 2*r)
+	tp.canvas.Refresh()
+}
+
+// onDeleteUnmatchedVia removes the selected half via.
+func (tp *TracesPanel) onDeleteUnmatchedVia() {
+	v := tp.selectedUnmatchedVia()
+	if v == nil {
+		return
+	}
+	tp.state.FeaturesLayer.RemoveVia(v.ID)
+	tp.state.SetModified(true)
+	tp.rebuildFeaturesOverlayFast()
+	tp.canvas.Refresh()
+	front, back := tp.state.FeaturesLayer.ViaCountBySide()
+	tp.viaCountLabel.SetText(fmt.Sprintf("Vias: %d front, %d back", front, back))
+	tp.state.Emit(app.EventFeaturesChanged, nil)
+}
+
+// sideOverlayColor returns col unchanged for elements on the active side, or a
+// translucent copy when fade is enabled and side is not the raised layer.
+func sideOverlayColor(col *color.RGBA, side, activeSide pcbimage.Side, fade bool) *color.RGBA {
+	if !fade || activeSide == pcbimage.SideUnknown || side == activeSide {
+		return col
+	}
+	faded := *col
+	faded.A = uint8(float64(col.A) * inactiveSideAlpha)
+	return &faded
+}
+
 // onClearVias clears all detected features — vias, confirmed vias, nets, and traces.
 func (tp *TracesPanel) onClearVias() {
 	tp.state.FeaturesLayer.Clear()
@@ -982,13 +1731,56 @@ func (tp *TracesPanel) onClearVias() {
 	tp.state.Emit(app.EventConfirmedViasChanged, nil)
 }
 
+// onAssignViasToPins links existing confirmed vias to the nearest DIP/SIP pin
+// of each component, then resolves signal names from the parts library.
+func (tp *TracesPanel) onAssignViasToPins() {
+	if tp.state.FeaturesLayer == nil || len(tp.state.Components) == 0 {
+		tp.viaStatusLabel.SetText("No components defined")
+		return
+	}
+	dpi := tp.state.DPI
+	if dpi <= 0 {
+		tp.viaStatusLabel.SetText("DPI not set")
+		return
+	}
+
+	vias := tp.state.FeaturesLayer.GetConfirmedVias()
+	report := component.AssignViasToPins(tp.state.Components, vias, dpi)
+
+	for _, cv := range vias {
+		if cv.ComponentID == "" || cv.SignalName != "" {
+			continue
+		}
+		pinDir := component.ResolveSignalName(cv, tp.state.Components, tp.state.ComponentLibrary)
+		if pinDir == connector.DirectionOutput && cv.SignalName != "" {
+			tp.renameNetForOutputPin(cv)
+		}
+	}
+
+	for _, cv := range report.UnmatchedVias {
+		fmt.Printf("[AssignPins] unmatched via %s at (%.0f,%.0f)\n", cv.ID, cv.Center.X, cv.Center.Y)
+	}
+	for _, p := range report.UnmatchedPins {
+		fmt.Printf("[AssignPins] no via for %s pin %d\n", p.ComponentID, p.PinNumber)
+	}
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Assigned %d vias; %d unmatched vias, %d unmatched pins",
+		report.Assigned, len(report.UnmatchedVias), len(report.UnmatchedPins)))
+
+	if report.Assigned > 0 {
+		tp.state.SetModified(true)
+		tp.rebuildFeaturesOverlay()
+		tp.canvas.Refresh()
+		tp.state.Emit(app.EventConfirmedViasChanged, nil)
+	}
+}
+
 // onDetectPins detects solder joint pins on the back image for all DIP components.
 func (tp *TracesPanel) onDetectPins() {
 	if tp.state.BackImage == nil || tp.state.BackImage.Image == nil {
 		fmt.Println("[DetectPins] No back image loaded")
 		return
 	}
-	dpi := tp.state.DPI
+	dpi := tp.state.DPIForSide(pcbimage.SideBack)
 	if dpi <= 0 {
 		fmt.Println("[DetectPins] DPI not set")
 		return
@@ -1130,6 +1922,12 @@ func (tp *TracesPanel) onLeftClick(x, y float64) {
 		return
 	}
 
+	// If dragging a connector, drop it
+	if tp.draggingConnector {
+		tp.finishConnectorDrag(x, y)
+		return
+	}
+
 	// If in trace drawing mode
 	if tp.traceMode {
 		// Hit-test confirmed via (not the start via) → finish trace
@@ -1140,6 +1938,11 @@ func (tp *TracesPanel) onLeftClick(x, y float64) {
 				startID = tp.traceStartVia.ID
 			}
 			if cv.ID != startID {
+				// Follow copper: the first click on the end via only suggests a route
+				if tp.followCopper && len(tp.tracePoints) == 1 {
+					tp.suggestCopperRoute(cv)
+					return
+				}
 				tp.tracePoints = append(tp.tracePoints, cv.Center)
 				tp.finishTraceAtVia(cv)
 				return
@@ -1188,9 +1991,14 @@ func (tp *TracesPanel) onLeftClick(x, y float64) {
 		return
 	}
 
-	// Hit-test connector on selected side → start trace at click position
+	// Hit-test connector on selected side → drag if already selected,
+	// otherwise start trace at click position
 	conn := tp.state.FeaturesLayer.HitTestConnectorOnSide(x, y, tp.selectedSide())
 	if conn != nil {
+		if conn == tp.selectedConnector {
+			tp.startConnectorDrag(conn, x, y)
+			return
+		}
 		tp.startTraceFromConnector(conn, x, y)
 		return
 	}
@@ -1204,10 +2012,35 @@ func (tp *TracesPanel) onLeftClick(x, y float64) {
 		return
 	}
 
+	// Trace segment → add a via once the click turns out not to be the
+	// start of a double-click, which inserts a vertex instead
+	if tp.hitTestTraceSegment(x, y) != nil {
+		tp.pendingClick++
+		seq := tp.pendingClick
+		glib.TimeoutAdd(uint(canvas.DoubleClickTime().Milliseconds()), func() bool {
+			if seq == tp.pendingClick && !tp.traceMode && !tp.addComponentMode {
+				tp.addConfirmedViaAt(x, y)
+			}
+			return false
+		})
+		return
+	}
+
 	// Empty space → add confirmed via
 	tp.addConfirmedViaAt(x, y)
 }
 
+// onDoubleClick inserts a vertex into the trace segment under (x, y).
+func (tp *TracesPanel) onDoubleClick(x, y float64) {
+	if tp.addComponentMode || tp.traceMode || tp.draggingVertex || tp.draggingConnector {
+		return
+	}
+	tp.pendingClick++ // Cancel the via both clicks would have added
+	if hit := tp.hitTestTraceSegment(x, y); hit != nil {
+		tp.insertVertex(hit, x, y)
+	}
+}
+
 // traceStartLabel returns a display label for the trace start point.
 func (tp *TracesPanel) traceStartLabel() string {
 	if tp.traceStartVia != nil {
@@ -1398,7 +2231,7 @@ func (tp *TracesPanel) selectConnector(conn *connector.Connector) {
 	if label == "" {
 		label = fmt.Sprintf("P%d", conn.PinNumber)
 	}
-	tp.viaStatusLabel.SetText(fmt.Sprintf("Selected %s (%s) — arrow keys to nudge, Shift for 5px", conn.ID, label))
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Selected %s (%s) — click to drag, arrow keys to nudge, Shift for 5px", conn.ID, label))
 	tp.updateSelectedConnectorOverlay()
 }
 
@@ -1444,6 +2277,11 @@ func (tp *TracesPanel) onRightClickVia(x, y float64) {
 	if tp.draggingVertex {
 		tp.cancelVertexDrag()
 	}
+	// Cancel connector drag on right-click
+	if tp.draggingConnector {
+		tp.cancelConnectorDrag()
+		return
+	}
 	// During polyline drawing, right-click cancels the trace
 	if tp.traceMode {
 		tp.cancelTrace()
@@ -1498,6 +2336,10 @@ func (tp *TracesPanel) onMiddleClick(x, y float64) {
 		tp.cancelVertexDrag()
 		return
 	}
+	if tp.draggingConnector {
+		tp.cancelConnectorDrag()
+		return
+	}
 	if tp.traceMode {
 		tp.cancelTrace()
 		return
@@ -1539,6 +2381,11 @@ func (tp *TracesPanel) showConfirmedViaMenu(cv *via.ConfirmedVia) {
 	if cv.ComponentID != "" && cv.PinNumber != "" {
 		addItem("Renumber Pin (whole package)...", func() { tp.renumberPin(cv) })
 	}
+	labelItem := "Set Label..."
+	if cv.Label != "" {
+		labelItem = fmt.Sprintf("Label: %s...", cv.Label)
+	}
+	addItem(labelItem, func() { tp.renameConfirmedViaLabel(cv) })
 	addSep()
 
 	// Pull-up / Pull-down toggles
@@ -1590,36 +2437,229 @@ func (tp *TracesPanel) showConfirmedViaMenu(cv *via.ConfirmedVia) {
 	menu.PopupAtPointer(nil)
 }
 
-// showGeneralViaMenu shows the context menu when not on a confirmed via.
-func (tp *TracesPanel) showGeneralViaMenu(imgX, imgY float64) {
-	menu, _ := gtk.MenuNew()
+// showGeneralViaMenu shows the context menu when not on a confirmed via.
+func (tp *TracesPanel) showGeneralViaMenu(imgX, imgY float64) {
+	menu, _ := gtk.MenuNew()
+
+	addItem := func(label string, cb func()) {
+		item, _ := gtk.MenuItemNewWithLabel(label)
+		item.Connect("activate", cb)
+		menu.Append(item)
+	}
+
+	addItem("Dump Grayscale Region", func() { tp.dumpGrayscaleRegion(imgX, imgY) })
+	addItem("Add Confirmed Via", func() { tp.addConfirmedViaAt(imgX, imgY) })
+	addItem("Delete Front Via", func() { tp.deleteNearestVia(imgX, imgY, pcbimage.SideFront) })
+	addItem("Delete Back Via", func() { tp.deleteNearestVia(imgX, imgY, pcbimage.SideBack) })
+
+	sep2, _ := gtk.SeparatorMenuItemNew()
+	menu.Append(sep2)
+	addItem("Add Component...", func() {
+		tp.startAddComponentMode(imgX, imgY)
+	})
+	addItem("Export Vias to KiCad (origin here)...", func() {
+		tp.exportViasKiCad(geometry.Point2D{X: imgX, Y: imgY})
+	})
+
+	if hit := tp.hitTestTraceSegment(imgX, imgY); hit != nil {
+		h := hit
+		sep, _ := gtk.SeparatorMenuItemNew()
+		menu.Append(sep)
+		addItem("Delete Segment", func() { tp.deleteTraceSegment(h) })
+	}
+
+	menu.ShowAll()
+	menu.PopupAtPointer(nil)
+}
+
+// exportViasKiCad writes all confirmed vias to a .kicad_pcb fragment with
+// coordinates relative to origin.
+func (tp *TracesPanel) exportViasKiCad(origin geometry.Point2D) {
+	if tp.state.FeaturesLayer == nil {
+		return
+	}
+	cvs := tp.state.FeaturesLayer.GetConfirmedVias()
+	if len(cvs) == 0 {
+		tp.traceStatusLabel.SetText("No confirmed vias to export")
+		return
+	}
+	dpi := tp.state.DPIForSide(pcbimage.SideFront)
+	if dpi <= 0 {
+		tp.traceStatusLabel.SetText("Cannot export vias: DPI unknown")
+		return
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export Vias to KiCad", tp.win, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Save", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	dlg.SetDoOverwriteConfirmation(true)
+	dlg.SetCurrentName("vias.kicad_pcb")
+	if tp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(tp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+
+	vias := make([]via.ConfirmedVia, len(cvs))
+	for i, cv := range cvs {
+		vias[i] = *cv
+	}
+	f, err := os.Create(path)
+	if err == nil {
+		err = via.ExportKiCad(vias, dpi, origin, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		tp.traceStatusLabel.SetText(fmt.Sprintf("Via export error: %v", err))
+		return
+	}
+	fmt.Printf("[Export] %d vias to %s (origin %.0f,%.0f px, %.0f DPI)\n", len(vias), path, origin.X, origin.Y, dpi)
+	tp.traceStatusLabel.SetText(fmt.Sprintf("Exported %d vias to %s", len(vias), filepath.Base(path)))
+}
+
+// onExportNetCSV writes the traced nets as a CSV net listing, for comparing
+// against a schematic.
+func (tp *TracesPanel) onExportNetCSV() {
+	nets := tp.state.FeaturesLayer.GetNets()
+	if len(nets) == 0 {
+		tp.traceStatusLabel.SetText("No nets to export")
+		return
+	}
+
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Export Netlist", tp.win, gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Save", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	dlg.SetDoOverwriteConfirmation(true)
+	dlg.SetCurrentName("netlist.csv")
+	if tp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(tp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+
+	f, err := os.Create(path)
+	if err == nil {
+		err = netlist.ExportNetCSV(nets, tp.netViaResolver, tp.netConnResolver, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		tp.traceStatusLabel.SetText(fmt.Sprintf("Netlist export error: %v", err))
+		return
+	}
+	fmt.Printf("[Export] %d nets to %s\n", len(nets), path)
+	tp.traceStatusLabel.SetText(fmt.Sprintf("Exported %d nets to %s", len(nets), filepath.Base(path)))
+}
+
+// netViaResolver resolves a confirmed via to the component pin it is
+// assigned to, for netlist export.
+func (tp *TracesPanel) netViaResolver(viaID string) (componentID, pinNumber, signalName string) {
+	cv := tp.state.FeaturesLayer.GetConfirmedViaByID(viaID)
+	if cv == nil {
+		return "", "", ""
+	}
+	return cv.ComponentID, cv.PinNumber, cv.SignalName
+}
+
+// netConnResolver resolves a connector to its pin number and signal, for
+// netlist export.
+func (tp *TracesPanel) netConnResolver(connID string) (pinNumber int, signalName string) {
+	conn := tp.state.FeaturesLayer.GetConnectorByID(connID)
+	if conn == nil {
+		return 0, ""
+	}
+	return conn.PinNumber, conn.SignalName
+}
 
-	addItem := func(label string, cb func()) {
-		item, _ := gtk.MenuItemNewWithLabel(label)
-		item.Connect("activate", cb)
-		menu.Append(item)
+// onCompareNetlist checks the traced nets against an expected netlist
+// chosen by the user and shows the missing and extra connections.
+func (tp *TracesPanel) onCompareNetlist() {
+	nets := tp.state.FeaturesLayer.GetNets()
+	if len(nets) == 0 {
+		tp.traceStatusLabel.SetText("No nets to compare")
+		return
 	}
 
-	addItem("Dump Grayscale Region", func() { tp.dumpGrayscaleRegion(imgX, imgY) })
-	addItem("Add Confirmed Via", func() { tp.addConfirmedViaAt(imgX, imgY) })
-	addItem("Delete Front Via", func() { tp.deleteNearestVia(imgX, imgY, pcbimage.SideFront) })
-	addItem("Delete Back Via", func() { tp.deleteNearestVia(imgX, imgY, pcbimage.SideBack) })
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Compare Netlist", tp.win, gtk.FILE_CHOOSER_ACTION_OPEN,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Open", gtk.RESPONSE_ACCEPT,
+	)
+	filter, _ := gtk.FileFilterNew()
+	filter.SetName("Netlists (CSV, JSON)")
+	filter.AddPattern("*.csv")
+	filter.AddPattern("*.json")
+	dlg.AddFilter(filter)
+	if tp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(tp.state.ProjectPath))
+	}
+	resp := dlg.Run()
+	path := dlg.GetFilename()
+	dlg.Destroy()
+	if resp != gtk.RESPONSE_ACCEPT {
+		return
+	}
 
-	sep2, _ := gtk.SeparatorMenuItemNew()
-	menu.Append(sep2)
-	addItem("Add Component...", func() {
-		tp.startAddComponentMode(imgX, imgY)
-	})
+	var expected *netlist.Netlist
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		expected, err = netlist.LoadJSON(path)
+	} else {
+		var f *os.File
+		if f, err = os.Open(path); err == nil {
+			expected, err = netlist.ReadNetCSV(filepath.Base(path), f)
+			f.Close()
+		}
+	}
+	if err != nil {
+		tp.traceStatusLabel.SetText(fmt.Sprintf("Netlist compare error: %v", err))
+		return
+	}
 
-	if hit := tp.hitTestTraceSegment(imgX, imgY); hit != nil {
-		h := hit
-		sep, _ := gtk.SeparatorMenuItemNew()
-		menu.Append(sep)
-		addItem("Delete Segment", func() { tp.deleteTraceSegment(h) })
+	actual := netlist.FromElectricalNets("traced", nets, tp.netViaResolver, tp.netConnResolver)
+	report := netlist.Compare(actual, expected)
+	fmt.Printf("[Compare] %s against %s\n", report.Summary(), path)
+	for _, line := range strings.Split(strings.TrimSpace(report.String()), "\n")[1:] {
+		fmt.Printf("[Compare]   %s\n", line)
 	}
+	tp.traceStatusLabel.SetText(report.Summary())
 
-	menu.ShowAll()
-	menu.PopupAtPointer(nil)
+	rdlg, _ := gtk.DialogNewWithButtons("Netlist Comparison", tp.win,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Close", gtk.RESPONSE_CLOSE})
+	rdlg.SetDefaultSize(420, 400)
+
+	contentArea, _ := rdlg.GetContentArea()
+	lbl, _ := gtk.LabelNew(fmt.Sprintf("Against %s: %s", filepath.Base(path), report.Summary()))
+	lbl.SetHAlign(gtk.ALIGN_START)
+	contentArea.PackStart(lbl, false, false, 4)
+
+	view, _ := gtk.TextViewNew()
+	view.SetEditable(false)
+	if buf, err := view.GetBuffer(); err == nil {
+		_, details, _ := strings.Cut(report.String(), "\n")
+		buf.SetText(details)
+	}
+	scroll, _ := gtk.ScrolledWindowNew(nil, nil)
+	scroll.SetVExpand(true)
+	scroll.Add(view)
+	contentArea.PackStart(scroll, true, true, 4)
+	rdlg.ShowAll()
+	rdlg.Run()
+	rdlg.Destroy()
 }
 
 // startAddComponentMode enters add-component mode with the first corner at the given position.
@@ -1860,10 +2900,7 @@ func (tp *TracesPanel) dumpGrayscaleRegion(imgX, imgY float64) {
 		return
 	}
 
-	dpi := tp.state.DPI
-	if dpi == 0 && layer.DPI > 0 {
-		dpi = layer.DPI
-	}
+	dpi := tp.state.DPIForSide(layer.Side)
 	if dpi == 0 {
 		fmt.Println("DPI unknown")
 		return
@@ -2027,11 +3064,11 @@ func (tp *TracesPanel) deleteTraceSegment(hit *traceHit) {
 // findViaAtPoint detects a via pad around the click point by walking outward
 // to find the pad boundary, then fitting a circle.  Returns found=false if
 // the click isn't on a bright pad or DPI is unknown.
-func (tp *TracesPanel) findViaAtPoint(srcImg image.Image, x, y float64) (geometry.Point2D, float64, bool) {
+func (tp *TracesPanel) findViaAtPoint(srcImg image.Image, side pcbimage.Side, x, y float64) (geometry.Point2D, float64, bool) {
 	if srcImg == nil {
 		return geometry.Point2D{}, 0, false
 	}
-	dpi := tp.state.DPI
+	dpi := tp.state.DPIForSide(side)
 	if dpi == 0 {
 		return geometry.Point2D{}, 0, false
 	}
@@ -2059,7 +3096,7 @@ func (tp *TracesPanel) addConfirmedViaAt(x, y float64) {
 			imgLayer = tp.state.BackImage
 		}
 		if imgLayer != nil && imgLayer.Image != nil {
-			if c, r, ok := tp.findViaAtPoint(imgLayer.Image, x, y); ok {
+			if c, r, ok := tp.findViaAtPoint(imgLayer.Image, imgLayer.Side, x, y); ok {
 				center = c
 				radius = r
 				fmt.Printf("Smart via placement: snapped to (%.1f, %.1f) r=%.1f\n", c.X, c.Y, r)
@@ -2539,7 +3576,8 @@ func (tp *TracesPanel) getSortedNets() []*netlist.ElectricalNet {
 }
 
 // refreshNetList rebuilds the net list widget from current features layer data.
-// Each net gets its own row showing name and element counts.
+// Each net gets its own row showing name and element counts, with a red
+// badge on nets that short two signals.
 func (tp *TracesPanel) refreshNetList() {
 	if tp.netListBox == nil {
 		return
@@ -2550,6 +3588,7 @@ func (tp *TracesPanel) refreshNetList() {
 		}
 	})
 
+	shorts := tp.state.FeaturesLayer.FindShorts()
 	nets := tp.getSortedNets()
 	tp.netIDs = make([]string, 0, len(nets))
 
@@ -2558,11 +3597,19 @@ func (tp *TracesPanel) refreshNetList() {
 		label := fmt.Sprintf("%s (%dv, %dc, %dt)",
 			net.Name, len(net.ViaIDs), len(net.ConnectorIDs), len(net.TraceIDs))
 		row, _ := gtk.LabelNew(label)
+		if net.HasErrors {
+			row.SetMarkup(fmt.Sprintf("%s <span foreground='white' background='red'><b> SHORT </b></span>",
+				glib.MarkupEscapeText(label)))
+		}
 		row.SetHAlign(gtk.ALIGN_START)
 		tp.netListBox.Add(row)
 	}
 	tp.netListBox.ShowAll()
-	tp.netCountLabel.SetText(fmt.Sprintf("Nets: %d", len(nets)))
+	if len(shorts) > 0 {
+		tp.netCountLabel.SetMarkup(fmt.Sprintf("Nets: %d  <span foreground='red'><b>Shorts: %d</b></span>", len(nets), len(shorts)))
+	} else {
+		tp.netCountLabel.SetText(fmt.Sprintf("Nets: %d  Shorts: 0", len(nets)))
+	}
 	tp.refreshNetElements()
 }
 
@@ -2576,6 +3623,7 @@ func (tp *TracesPanel) refreshNetElements() {
 			tp.netElementsBox.Remove(w)
 		}
 	})
+	tp.netElementIDs = nil
 
 	if tp.selectedNetID == "" {
 		tp.netElementsBox.ShowAll()
@@ -2589,7 +3637,9 @@ func (tp *TracesPanel) refreshNetElements() {
 		return
 	}
 
-	for _, elem := range net.Elements {
+	// List members in signal-path order from the chosen endpoint
+	for i, pe := range tp.state.FeaturesLayer.NetPath(net.ID, tp.netPathStartID, 5.0) {
+		elem := pe.NetElement
 		label := fmt.Sprintf("[%s] %s", elem.Type.String(), elem.ID)
 		switch elem.Type {
 		case netlist.ElementVia:
@@ -2607,9 +3657,13 @@ func (tp *TracesPanel) refreshNetElements() {
 				label = fmt.Sprintf("[Conn] %s %s", elem.ID, c.SignalName)
 			}
 		}
-		row, _ := gtk.LabelNew(label)
+		if pe.Role != netlist.RoleTrace && pe.Role != netlist.RoleVia {
+			label = fmt.Sprintf("%s · %s", pe.Role, label)
+		}
+		row, _ := gtk.LabelNew(fmt.Sprintf("%d. %s", i+1, label))
 		row.SetHAlign(gtk.ALIGN_START)
 		tp.netElementsBox.Add(row)
+		tp.netElementIDs = append(tp.netElementIDs, elem.ID)
 	}
 	tp.netElementsBox.ShowAll()
 }
@@ -2620,10 +3674,16 @@ func (tp *TracesPanel) resolveNetElement(rowIdx int) (*netlist.ElectricalNet, *n
 		return nil, nil
 	}
 	net := tp.state.FeaturesLayer.GetNetByID(tp.selectedNetID)
-	if net == nil || rowIdx >= len(net.Elements) {
+	if net == nil || rowIdx >= len(tp.netElementIDs) {
 		return nil, nil
 	}
-	return net, &net.Elements[rowIdx]
+	id := tp.netElementIDs[rowIdx]
+	for i := range net.Elements {
+		if net.Elements[i].ID == id {
+			return net, &net.Elements[i]
+		}
+	}
+	return nil, nil
 }
 
 // showNetElementMenu shows a right-click context menu for a net element row.
@@ -2641,6 +3701,13 @@ func (tp *TracesPanel) showNetElementMenu(rowIdx int) {
 		menu.Append(item)
 	}
 
+	if elem.Type != netlist.ElementTrace {
+		addItem("Order Path From Here", func() {
+			tp.netPathStartID = elem.ID
+			tp.refreshNetElements()
+		})
+	}
+
 	addItem(fmt.Sprintf("Remove %s from net", elem.ID), func() {
 		net.RemoveElement(elem.ID)
 		if len(net.Elements) == 0 {
@@ -2905,6 +3972,8 @@ func (tp *TracesPanel) renameNet(net *netlist.ElectricalNet) {
 }
 
 // namePin shows a dialog to associate a confirmed via with a component pin.
+// The pin is validated against the component's package pin count; an invalid
+// pin reopens the dialog with the error shown.
 func (tp *TracesPanel) namePin(cv *via.ConfirmedVia) {
 	closestID := ""
 	if len(tp.state.Components) > 0 {
@@ -2930,7 +3999,17 @@ func (tp *TracesPanel) namePin(cv *via.ConfirmedVia) {
 
 	contentArea, _ := dlg.GetContentArea()
 
-	compEntry, _ := gtk.EntryNew()
+	// Component picker: all board components, editable for connectors etc.
+	compCombo, _ := gtk.ComboBoxTextNewWithEntry()
+	ids := make([]string, 0, len(tp.state.Components))
+	for _, comp := range tp.state.Components {
+		ids = append(ids, comp.ID)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		compCombo.Append(id, id)
+	}
+	compEntry, _ := compCombo.GetEntry()
 	compEntry.SetActivatesDefault(true)
 	if cv.ComponentID != "" {
 		compEntry.SetText(cv.ComponentID)
@@ -2956,22 +4035,55 @@ func (tp *TracesPanel) namePin(cv *via.ConfirmedVia) {
 	compLabel.SetHAlign(gtk.ALIGN_START)
 	pinLabel, _ := gtk.LabelNew("Pin:")
 	pinLabel.SetHAlign(gtk.ALIGN_START)
+	errLabel, _ := gtk.LabelNew("")
+	errLabel.SetHAlign(gtk.ALIGN_START)
+	errLabel.SetLineWrap(true)
+
+	// Show the valid pin range for the chosen component
+	updatePinHint := func() {
+		compText, _ := compEntry.GetText()
+		hint := "Pin:"
+		if comp := tp.findComponentByID(strings.TrimSpace(compText)); comp != nil {
+			if n := component.PackagePinCount(comp, tp.state.ComponentLibrary); n > 0 {
+				hint = fmt.Sprintf("Pin (1-%d):", n)
+			}
+		}
+		pinLabel.SetText(hint)
+	}
+	updatePinHint()
+	compCombo.Connect("changed", updatePinHint)
 
 	contentArea.PackStart(compLabel, false, false, 4)
-	contentArea.PackStart(compEntry, false, false, 4)
+	contentArea.PackStart(compCombo, false, false, 4)
 	contentArea.PackStart(pinLabel, false, false, 4)
 	contentArea.PackStart(pinEntry, false, false, 4)
+	contentArea.PackStart(errLabel, false, false, 4)
 	dlg.ShowAll()
 
-	response := dlg.Run()
-	if response == gtk.RESPONSE_OK {
+	for {
+		if dlg.Run() != gtk.RESPONSE_OK {
+			break
+		}
 		compText, _ := compEntry.GetText()
 		pinText, _ := pinEntry.GetText()
-		cv.ComponentID = compText
-		cv.PinNumber = pinText
+		compText = strings.TrimSpace(compText)
+		pinText = strings.TrimSpace(pinText)
+
+		var pinDir connector.SignalDirection
+		if comp := tp.findComponentByID(compText); comp != nil {
+			dir, err := component.AssignViaToPin(cv, comp, pinText, tp.state.ComponentLibrary)
+			if err != nil {
+				errLabel.SetMarkup(fmt.Sprintf("<span foreground='red'>%s</span>", glib.MarkupEscapeText(err.Error())))
+				continue
+			}
+			pinDir = dir
+		} else {
+			// Not a board component (e.g. an edge connector name): store as typed
+			cv.ComponentID = compText
+			cv.PinNumber = pinText
+			cv.SignalName = ""
+		}
 
-		// Look up signal name from parts library
-		pinDir := component.ResolveSignalName(cv, tp.state.Components, tp.state.ComponentLibrary)
 		if pinDir == connector.DirectionOutput && cv.SignalName != "" {
 			tp.renameNetForOutputPin(cv)
 		}
@@ -2990,7 +4102,9 @@ func (tp *TracesPanel) namePin(cv *via.ConfirmedVia) {
 		}
 		tp.rebuildFeaturesOverlay()
 		tp.canvas.Refresh()
+		tp.state.SetModified(true)
 		tp.state.Emit(app.EventConfirmedViasChanged, nil)
+		break
 	}
 	dlg.Destroy()
 }
@@ -3719,6 +4833,7 @@ func (tp *TracesPanel) associateTraceEndpoints() {
 	// Reconcile nets from physical connectivity — ensures all elements
 	// connected by traces end up in the same net.
 	tp.state.FeaturesLayer.ReconcileNets(5.0)
+	tp.state.FeaturesLayer.AutoNameNetsFromConnectors()
 	tp.state.FeaturesLayer.PropagateLogicNames(tp.state.Components, tp.state.ComponentLibrary)
 
 	tp.disambiguateNetNames()
@@ -3822,12 +4937,51 @@ func (tp *TracesPanel) showConnectorMenu(conn *connector.Connector) {
 	}
 
 	addItem(signalLabel, func() { tp.renameConnectorSignal(conn) })
+	addItem("Move Connector", func() { tp.startConnectorDrag(conn, conn.Center.X, conn.Center.Y) })
 	addItem("Delete Connector", func() { tp.deleteConnector(conn) })
 
 	menu.ShowAll()
 	menu.PopupAtPointer(nil)
 }
 
+// renameConfirmedViaLabel opens a dialog to set a custom display label on a
+// confirmed via. Clearing the entry restores the default label precedence.
+func (tp *TracesPanel) renameConfirmedViaLabel(cv *via.ConfirmedVia) {
+	dlg, _ := gtk.DialogNewWithButtons("Via Label", tp.win,
+		gtk.DIALOG_MODAL|gtk.DIALOG_DESTROY_WITH_PARENT,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL},
+		[]interface{}{"OK", gtk.RESPONSE_OK})
+	dlg.SetDefaultSize(300, 150)
+	dlg.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	contentArea, _ := dlg.GetContentArea()
+	entry, _ := gtk.EntryNew()
+	entry.SetActivatesDefault(true)
+	entry.SetText(cv.Label)
+	entry.SetPlaceholderText("e.g. RESET, TP1")
+
+	lbl, _ := gtk.LabelNew("Label (empty = automatic):")
+	lbl.SetHAlign(gtk.ALIGN_START)
+	contentArea.PackStart(lbl, false, false, 4)
+	contentArea.PackStart(entry, false, false, 4)
+	dlg.ShowAll()
+
+	response := dlg.Run()
+	if response == gtk.RESPONSE_OK {
+		name, _ := entry.GetText()
+		cv.Label = strings.TrimSpace(name)
+		tp.rebuildFeaturesOverlay()
+		tp.canvas.Refresh()
+		if cv.Label == "" {
+			tp.viaStatusLabel.SetText(fmt.Sprintf("%s label cleared", cv.ID))
+		} else {
+			tp.viaStatusLabel.SetText(fmt.Sprintf("%s label: %s", cv.ID, cv.Label))
+		}
+		tp.state.SetModified(true)
+	}
+	dlg.Destroy()
+}
+
 // renameConnectorSignal opens a dialog to edit a connector's signal name.
 func (tp *TracesPanel) renameConnectorSignal(conn *connector.Connector) {
 	dlg, _ := gtk.DialogNewWithButtons("Rename Signal", tp.win,
@@ -3871,6 +5025,72 @@ func (tp *TracesPanel) deleteConnector(conn *connector.Connector) {
 	tp.state.SetModified(true)
 }
 
+// onApplyBoardPinout names the connectors from the pinout bundled for the
+// current board profile.
+func (tp *TracesPanel) onApplyBoardPinout() {
+	if tp.state.BoardSpec == nil {
+		tp.viaStatusLabel.SetText("No board profile selected")
+		return
+	}
+	name := tp.state.BoardSpec.Name()
+	pinout, ok := connector.BundledPinout(name)
+	if !ok {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("No bundled pinout for %s", name))
+		return
+	}
+	tp.applyPinout(pinout, name)
+}
+
+// onLoadPinout names the connectors from a pin,signal CSV chosen by the user.
+func (tp *TracesPanel) onLoadPinout() {
+	dlg, _ := gtk.FileChooserDialogNewWith2Buttons(
+		"Load Connector Pinout", tp.win, gtk.FILE_CHOOSER_ACTION_OPEN,
+		"Cancel", gtk.RESPONSE_CANCEL,
+		"Open", gtk.RESPONSE_ACCEPT,
+	)
+	defer dlg.Destroy()
+	filter, _ := gtk.FileFilterNew()
+	filter.SetName("CSV files")
+	filter.AddPattern("*.csv")
+	dlg.AddFilter(filter)
+	if tp.state.ProjectPath != "" {
+		dlg.SetCurrentFolder(filepath.Dir(tp.state.ProjectPath))
+	}
+	if dlg.Run() != gtk.RESPONSE_ACCEPT {
+		return
+	}
+	path := dlg.GetFilename()
+
+	f, err := os.Open(path)
+	if err != nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("Pinout error: %v", err))
+		return
+	}
+	defer f.Close()
+	pinout, err := connector.LoadPinout(f)
+	if err != nil {
+		tp.viaStatusLabel.SetText(fmt.Sprintf("Pinout error: %v", err))
+		return
+	}
+	tp.applyPinout(pinout, filepath.Base(path))
+}
+
+// applyPinout sets the connector signal names on both sides from pinout and
+// redraws the connector labels.
+func (tp *TracesPanel) applyPinout(pinout map[int]string, source string) {
+	if tp.state.FeaturesLayer.ConnectorCount() == 0 {
+		tp.viaStatusLabel.SetText("No connectors — add connectors first")
+		return
+	}
+	front := tp.state.FeaturesLayer.ApplyPinout(pcbimage.SideFront, pinout)
+	back := tp.state.FeaturesLayer.ApplyPinout(pcbimage.SideBack, pinout)
+	fmt.Printf("[Pinout] %s: named %d front, %d back connectors\n", source, front, back)
+	tp.rebuildFeaturesOverlay()
+	tp.canvas.Refresh()
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Pinout %s: named %d connectors (%d front, %d back)", source, front+back, front, back))
+	tp.state.SetModified(true)
+}
+
 // refreshConnectors rebuilds connectors from detection results.
 // The EventConnectorsCreated listener will update the overlay.
 func (tp *TracesPanel) refreshConnectors() {
@@ -3925,8 +5145,104 @@ func (tp *TracesPanel) tryMatchVias() {
 	tp.state.Emit(app.EventConfirmedViasChanged, nil)
 }
 
+// onSnapViasToGrid snaps confirmed via centers to the grid given by the
+// board's contact pitch. Vias far from any grid node are left in place.
+func (tp *TracesPanel) onSnapViasToGrid() {
+	vias := tp.state.FeaturesLayer.GetConfirmedVias()
+	if len(vias) == 0 {
+		tp.viaStatusLabel.SetText("No confirmed vias to snap")
+		return
+	}
+	if tp.state.BoardSpec == nil || tp.state.BoardSpec.ContactSpec() == nil {
+		tp.viaStatusLabel.SetText("Board spec has no contact pitch")
+		return
+	}
+	pitch := tp.state.BoardSpec.ContactSpec().PitchInches
+	dpi := tp.state.DPI
+	if pitch <= 0 || dpi <= 0 {
+		tp.viaStatusLabel.SetText("Need contact pitch and DPI to snap")
+		return
+	}
+
+	origin := via.GridOrigin(vias, dpi, pitch)
+	snaps := via.SnapToGrid(vias, dpi, pitch, origin)
+
+	var snapped, skipped int
+	var sum, maxDist float64
+	for _, s := range snaps {
+		if !s.Snapped {
+			skipped++
+			fmt.Printf("Snap to grid: %s left off-grid (%.1f px from node)\n", s.Via.ID, s.Distance)
+			continue
+		}
+		snapped++
+		sum += s.Distance
+		maxDist = math.Max(maxDist, s.Distance)
+	}
+	avg := 0.0
+	if snapped > 0 {
+		avg = sum / float64(snapped)
+	}
+	fmt.Printf("Snap to grid: pitch=%.3f\" origin=(%.1f, %.1f) snapped=%d avg=%.1f max=%.1f px skipped=%d\n",
+		pitch, origin.X, origin.Y, snapped, avg, maxDist, skipped)
+
+	tp.rebuildFeaturesOverlay()
+	tp.updateSelectedViaOverlay()
+	tp.canvas.Refresh()
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Snapped %d vias (avg %.1f, max %.1f px), %d left off-grid",
+		snapped, avg, maxDist, skipped))
+	tp.state.Emit(app.EventConfirmedViasChanged, nil)
+}
+
+// syncAutoTraceCostSpins loads the state's auto-trace weights into the spins.
+func (tp *TracesPanel) syncAutoTraceCostSpins() {
+	params := tp.state.AutoTrace()
+	tp.syncingCostSpins = true
+	tp.copperRewardSpin.SetValue(params.CopperReward)
+	tp.soldermaskPenaltySpin.SetValue(params.SoldermaskPenalty)
+	tp.turnPenaltySpin.SetValue(params.TurnPenalty)
+	tp.syncingCostSpins = false
+}
+
+// onAutoTraceCostChanged stores edited auto-trace weights in the project and
+// refreshes the path cost readout.
+func (tp *TracesPanel) onAutoTraceCostChanged() {
+	if tp.syncingCostSpins {
+		return
+	}
+	params := tp.state.AutoTrace()
+	params.CopperReward = tp.copperRewardSpin.GetValue()
+	params.SoldermaskPenalty = tp.soldermaskPenaltySpin.GetValue()
+	params.TurnPenalty = tp.turnPenaltySpin.GetValue()
+	tp.state.AutoTraceParams = &params
+	tp.state.SetModified(true)
+	tp.updatePathCostLabel()
+}
+
+// updatePathCostLabel shows the auto-trace cost of the trace being drawn, so
+// the weights can be judged against a path the user knows is right.
+func (tp *TracesPanel) updatePathCostLabel() {
+	if !tp.traceMode || len(tp.tracePoints) < 2 {
+		tp.pathCostLabel.SetText("Path cost: -")
+		return
+	}
+	layer := tp.state.FrontImage
+	if traceLayerSide(tp.traceLayer) == pcbimage.SideBack {
+		layer = tp.state.BackImage
+	}
+	if layer == nil || layer.Image == nil {
+		tp.pathCostLabel.SetText("Path cost: no image")
+		return
+	}
+	params := tp.state.AutoTrace()
+	cost := pcbtrace.EvaluatePathCost(layer.CopperMask(params.Copper), tp.tracePoints, params)
+	tp.pathCostLabel.SetText(fmt.Sprintf("Path cost: %.0f (%.0f px, %.0f%% copper, %.0f° turns)",
+		cost.Total, cost.Length, cost.CopperFraction()*100, cost.TurnDegrees))
+}
+
 // updateTraceOverlay rebuilds the in-progress trace overlay.
 func (tp *TracesPanel) updateTraceOverlay() {
+	tp.updatePathCostLabel()
 	if len(tp.tracePoints) < 2 {
 		tp.canvas.ClearOverlay("trace_segments")
 		return
@@ -3970,6 +5286,7 @@ func (tp *TracesPanel) finishTraceCommon(endLabel string) string {
 	tp.canvas.ClearOverlay("trace_segments")
 	tp.canvas.OnMouseMove(nil)
 	tp.traceMode = false
+	tp.updatePathCostLabel()
 
 	nSegs := len(tp.tracePoints) - 1
 	startLabel := tp.traceStartLabel()
@@ -4005,6 +5322,9 @@ func (tp *TracesPanel) finishTraceAtVia(endVia *via.ConfirmedVia) {
 	netInfo := ""
 	if net != nil {
 		netInfo = fmt.Sprintf(" [%s]", net.Name)
+		if net.HasErrors {
+			netInfo += " SHORT: connectors with different signals"
+		}
 	}
 	nSegs := len(tp.tracePoints) - 1
 	tp.traceStatusLabel.SetText(fmt.Sprintf("Trace: %s -> %s (%d segments)%s",
@@ -4035,6 +5355,9 @@ func (tp *TracesPanel) finishTraceAtConnector(conn *connector.Connector) {
 	netInfo := ""
 	if net != nil {
 		netInfo = fmt.Sprintf(" [%s]", net.Name)
+		if net.HasErrors {
+			netInfo += " SHORT: connectors with different signals"
+		}
 	}
 	nSegs := len(tp.tracePoints) - 1
 	tp.traceStatusLabel.SetText(fmt.Sprintf("Trace: %s -> %s (%d segments)%s",
@@ -4183,6 +5506,95 @@ func (tp *TracesPanel) cancelVertexDrag() {
 	tp.traceStatusLabel.SetText("Vertex drag cancelled")
 }
 
+// startConnectorDrag begins dragging a connector grabbed at (x, y).
+func (tp *TracesPanel) startConnectorDrag(conn *connector.Connector, x, y float64) {
+	tp.selectConnector(conn)
+	tp.draggingConnector = true
+	tp.dragConnOffset = geometry.Point2D{X: conn.Center.X - x, Y: conn.Center.Y - y}
+
+	tp.updateConnectorDragOverlay(x, y)
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Dragging %s — click to drop, Esc to cancel", conn.ID))
+
+	tp.canvas.OnMouseMove(func(x, y float64) {
+		tp.updateConnectorDragOverlay(x, y)
+		tp.canvas.Refresh()
+	})
+}
+
+// connectorDragCenter returns where the dragged connector's center lands for
+// a cursor at (x, y), snapped to the contact pitch when close to it.
+func (tp *TracesPanel) connectorDragCenter(x, y float64) (geometry.Point2D, bool) {
+	pos := geometry.Point2D{X: x + tp.dragConnOffset.X, Y: y + tp.dragConnOffset.Y}
+	if tp.state.BoardSpec == nil || tp.state.BoardSpec.ContactSpec() == nil || tp.state.DPI <= 0 {
+		return pos, false
+	}
+	pitchPx := tp.state.BoardSpec.ContactSpec().PitchInches * tp.state.DPI
+	return connector.SnapToPitch(tp.selectedConnector, tp.state.FeaturesLayer.GetConnectors(), pos, pitchPx)
+}
+
+// updateConnectorDragOverlay draws the dragged connector's outline where it
+// would be dropped, green when snapped to the contact pitch.
+func (tp *TracesPanel) updateConnectorDragOverlay(x, y float64) {
+	c := tp.selectedConnector
+	if c == nil {
+		return
+	}
+	center, snapped := tp.connectorDragCenter(x, y)
+	col := color.RGBA{R: 255, G: 255, B: 0, A: 255}
+	if snapped {
+		col = color.RGBA{R: 0, G: 255, B: 0, A: 255}
+	}
+	dx := int(math.Round(center.X - c.Center.X))
+	dy := int(math.Round(center.Y - c.Center.Y))
+	tp.canvas.SetOverlay("connector_drag", &canvas.Overlay{
+		Rectangles: []canvas.OverlayRect{
+			{
+				X: c.Bounds.X + dx, Y: c.Bounds.Y + dy,
+				Width: c.Bounds.Width, Height: c.Bounds.Height,
+				Fill: canvas.FillNone,
+			},
+		},
+		Circles: []canvas.OverlayCircle{
+			{X: center.X, Y: center.Y, Radius: 3, Filled: true},
+		},
+		Color: col,
+	})
+}
+
+// finishConnectorDrag drops the dragged connector for a cursor at (x, y).
+func (tp *TracesPanel) finishConnectorDrag(x, y float64) {
+	tp.draggingConnector = false
+	tp.canvas.ClearOverlay("connector_drag")
+	tp.canvas.OnMouseMove(nil)
+
+	conn := tp.selectedConnector
+	if conn == nil {
+		return
+	}
+	center, snapped := tp.connectorDragCenter(x, y)
+	conn.MoveTo(center)
+
+	tp.rebuildFeaturesOverlay()
+	tp.updateSelectedConnectorOverlay()
+	tp.canvas.Refresh()
+	suffix := ""
+	if snapped {
+		suffix = " (snapped to pitch)"
+	}
+	tp.viaStatusLabel.SetText(fmt.Sprintf("Moved %s to (%.0f, %.0f)%s", conn.ID, conn.Center.X, conn.Center.Y, suffix))
+	tp.state.SetModified(true)
+	tp.state.Emit(app.EventConnectorsChanged, nil)
+}
+
+// cancelConnectorDrag cancels an in-progress connector drag.
+func (tp *TracesPanel) cancelConnectorDrag() {
+	tp.draggingConnector = false
+	tp.canvas.ClearOverlay("connector_drag")
+	tp.canvas.OnMouseMove(nil)
+	tp.canvas.Refresh()
+	tp.viaStatusLabel.SetText("Connector drag cancelled")
+}
+
 // showVertexMenu shows a right-click context menu for a trace vertex.
 func (tp *TracesPanel) showVertexMenu(traceID string, pointIdx int) {
 	tf := tp.state.FeaturesLayer.GetTraceFeature(traceID)
@@ -4201,8 +5613,9 @@ func (tp *TracesPanel) showVertexMenu(traceID string, pointIdx int) {
 		tp.startVertexDrag(traceID, pointIdx)
 	})
 
-	// Only allow deleting interior vertices (not endpoints) unless trace has 2 points
-	if len(tf.Points) > 2 {
+	// A trace keeps at least 2 points, and an endpoint on a via or
+	// connector anchors the trace's net membership
+	if len(tf.Points) > 2 && !tp.vertexAnchored(tf, pointIdx) {
 		addItem("Delete Vertex", func() {
 			tp.deleteVertex(traceID, pointIdx)
 		})
@@ -4225,6 +5638,10 @@ func (tp *TracesPanel) deleteVertex(traceID string, pointIdx int) {
 	if tf == nil || len(tf.Points) <= 2 {
 		return
 	}
+	if tp.vertexAnchored(tf, pointIdx) {
+		tp.traceStatusLabel.SetText(fmt.Sprintf("Vertex %d of %s anchors it to a via or connector", pointIdx, traceID))
+		return
+	}
 	newPoints := make([]geometry.Point2D, 0, len(tf.Points)-1)
 	newPoints = append(newPoints, tf.Points[:pointIdx]...)
 	newPoints = append(newPoints, tf.Points[pointIdx+1:]...)
@@ -4236,6 +5653,48 @@ func (tp *TracesPanel) deleteVertex(traceID string, pointIdx int) {
 	tp.rebuildFeaturesOverlay()
 	tp.canvas.Refresh()
 	tp.traceStatusLabel.SetText(fmt.Sprintf("Deleted vertex %d of %s (%d pts remain)", pointIdx, traceID, len(newPoints)))
+	tp.state.SetModified(true)
+}
+
+// vertexAnchored reports whether vertex idx of tf is an endpoint lying on a
+// confirmed via or on a connector of the trace's side.
+func (tp *TracesPanel) vertexAnchored(tf *pcbtrace.ExtendedTrace, idx int) bool {
+	if idx != 0 && idx != len(tf.Points)-1 {
+		return false
+	}
+	pt := tf.Points[idx]
+	if tp.state.FeaturesLayer.HitTestConfirmedVia(pt.X, pt.Y) != nil {
+		return true
+	}
+	return tp.state.FeaturesLayer.HitTestConnectorOnSide(pt.X, pt.Y, traceLayerSide(tf.Layer)) != nil
+}
+
+// insertVertex splits the hit segment with a new vertex at the point on it
+// nearest (x, y), leaving the trace's shape unchanged until it is moved.
+func (tp *TracesPanel) insertVertex(hit *traceHit, x, y float64) {
+	tf := tp.state.FeaturesLayer.GetTraceFeature(hit.traceID)
+	if tf == nil || hit.segIndex+1 >= len(tf.Points) {
+		return
+	}
+	a, b := tf.Points[hit.segIndex], tf.Points[hit.segIndex+1]
+	pt := a
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if lenSq := dx*dx + dy*dy; lenSq > 0 {
+		t := math.Max(0, math.Min(1, ((x-a.X)*dx+(y-a.Y)*dy)/lenSq))
+		pt = geometry.Point2D{X: a.X + t*dx, Y: a.Y + t*dy}
+	}
+
+	idx := hit.segIndex + 1
+	newPoints := make([]geometry.Point2D, 0, len(tf.Points)+1)
+	newPoints = append(newPoints, tf.Points[:idx]...)
+	newPoints = append(newPoints, pt)
+	newPoints = append(newPoints, tf.Points[idx:]...)
+
+	tp.state.FeaturesLayer.UpdateTracePoints(hit.traceID, newPoints)
+	tp.rebuildFeaturesOverlay()
+	tp.canvas.Refresh()
+	tp.traceStatusLabel.SetText(fmt.Sprintf("Inserted vertex %d in %s — right-click it to move", idx, hit.traceID))
+	tp.state.SetModified(true)
 }
 
 // collapseCollinear removes interior vertices where the turn angle is < 1 degree
@@ -4403,8 +5862,13 @@ func (tp *TracesPanel) autoTraceFromVia(cv *via.ConfirmedVia) {
 
 		// Flood fill from via center on grayscale
 		// Probe diameter 8px (radius 4), step 4px (50% overlap)
-		// Iterate: threshold 110→95, percentage 90→85%, diameter 6→10px
+		// Iterate: threshold 110→95 (or around the trained threshold), percentage 90→85%, diameter 6→10px
 		const stepSize = 4
+		threshHi, threshLo := 110, 95
+		if learned, ok := tp.learnedGrayThreshold(traceLayer); ok {
+			threshHi = min(int(learned)+7, 255)
+			threshLo = max(int(learned)-8, 0)
+		}
 
 		type attempt struct {
 			threshold  uint8
@@ -4412,7 +5876,7 @@ func (tp *TracesPanel) autoTraceFromVia(cv *via.ConfirmedVia) {
 			probeR     int
 		}
 		var attempts []attempt
-		for thresh := 110; thresh >= 95; thresh-- {
+		for thresh := threshHi; thresh >= threshLo; thresh-- {
 			for pct := 90; pct >= 85; pct-- {
 				for diam := 6; diam <= 6; diam += 2 {
 					attempts = append(attempts, attempt{uint8(thresh), float64(pct) / 100.0, diam / 2})
@@ -4678,7 +6142,7 @@ func (tp *TracesPanel) autoTraceToAdjacentVia(cv *via.ConfirmedVia, direction in
 			X: endCenter.X - float64(roiX),
 			Y: endCenter.Y - float64(roiY),
 		}
-		padRadius := int(0.020 * tp.state.DPI / 2)
+		padRadius := int(0.020 * tp.state.DPIForSide(traceLayerSide(traceLayer)) / 2)
 		if padRadius < 5 {
 			padRadius = 5
 		}
@@ -4858,8 +6322,79 @@ func (tp *TracesPanel) showNetInfoForElement(elementID, displayLabel string) {
 	tp.traceStatusLabel.SetText(info)
 }
 
+// trainedTraceClassifier returns the classifier onTrainTraceDetection saved
+// for layer, or nil if the project has none.
+func (tp *TracesPanel) trainedTraceClassifier(layer pcbtrace.TraceLayer) *pcbtrace.TraceClassifier {
+	if tp.state.ProjectPath == "" {
+		return nil
+	}
+	clPath := filepath.Join(filepath.Dir(tp.state.ProjectPath), pcbtrace.TraceClassifierFilename(layer))
+	cl, err := pcbtrace.LoadTraceClassifier(clPath)
+	if err != nil || !cl.Trained {
+		return nil
+	}
+	return cl
+}
+
+// learnedGrayThreshold returns the flood-fill copper threshold learned by
+// onTrainTraceDetection for layer, if the project has a trained classifier.
+func (tp *TracesPanel) learnedGrayThreshold(layer pcbtrace.TraceLayer) (uint8, bool) {
+	cl := tp.trainedTraceClassifier(layer)
+	if cl == nil || cl.GrayThreshold == 0 {
+		return 0, false
+	}
+	return cl.GrayThreshold, true
+}
+
+// suggestCopperRoute routes the trace being drawn from its start to endVia
+// along copper and loads the route as waypoints, leaving the trace open so
+// the route can be edited or accepted by clicking endVia again. The copper
+// model is the layer's trained trace classifier when there is one.
+func (tp *TracesPanel) suggestCopperRoute(endVia *via.ConfirmedVia) {
+	layer := tp.state.FrontImage
+	if traceLayerSide(tp.traceLayer) == pcbimage.SideBack {
+		layer = tp.state.BackImage
+	}
+	if layer == nil || layer.Image == nil {
+		tp.traceStatusLabel.SetText("Follow copper: no image for this layer")
+		return
+	}
+
+	params := pcbtrace.DefaultAutoRouteParams()
+	params.Cost = tp.state.AutoTrace()
+	params.Classifier = tp.trainedTraceClassifier(tp.traceLayer)
+	img, traceLayer := layer.Image, tp.traceLayer
+	start := tp.tracePoints[0]
+	tp.traceStatusLabel.SetText(fmt.Sprintf("Following copper to %s...", endVia.ID))
+
+	go func() {
+		if params.Classifier == nil {
+			params.CopperMask = layer.CopperMask(params.Cost.Copper)
+		}
+		path, ok := pcbtrace.AutoRoute(img, start, endVia.Center, traceLayer, params)
+		glib.IdleAdd(func() {
+			// Drop the result if the trace was cancelled or edited meanwhile
+			if !tp.traceMode || len(tp.tracePoints) != 1 || tp.tracePoints[0] != start {
+				return
+			}
+			if !ok {
+				tp.traceStatusLabel.SetText(fmt.Sprintf("No copper path to %s — click it again for a straight trace", endVia.ID))
+				return
+			}
+			tp.tracePoints = path[:len(path)-1]
+			last := tp.tracePoints[len(tp.tracePoints)-1]
+			tp.canvas.ShowRubberBand(last.X, last.Y)
+			tp.updateTraceOverlay()
+			fmt.Printf("Follow copper %s -> %s: %d points\n", tp.traceStartLabel(), endVia.ID, len(path))
+			tp.traceStatusLabel.SetText(fmt.Sprintf("Suggested route to %s (%d segments) — click it to accept, or edit the waypoints",
+				endVia.ID, len(path)-1))
+		})
+	}()
+}
+
 // onTrainTraceDetection collects color samples from existing manual traces and trains
-// a trace classifier for the selected layer.
+// a trace classifier for the selected layer, including the grayscale copper
+// threshold the flood-fill auto-trace uses.
 func (tp *TracesPanel) onTrainTraceDetection() {
 	layer := tp.selectedTraceLayer()
 
@@ -4893,7 +6428,7 @@ func (tp *TracesPanel) onTrainTraceDetection() {
 	}
 
 	// Estimate trace half-width from DPI (15 mil default trace width)
-	halfWidth := 0.015 * tp.state.DPI / 2.0
+	halfWidth := 0.015 * tp.state.DPIForSide(traceLayerSide(layer)) / 2.0
 	if halfWidth < 3 {
 		halfWidth = 4.5 // Fallback for low/zero DPI
 	}
@@ -5023,11 +6558,14 @@ func (tp *TracesPanel) onAutoTraceLayer() {
 
 		// Conservative parameters for layer-wide auto-trace
 		const (
-			layerProbeRadius  = 3   // 6px diameter
-			layerStepSize     = 4   // 50% overlap
-			layerThreshold    = 105
-			layerMinFraction  = 0.95
+			layerProbeRadius = 3 // 6px diameter
+			layerStepSize    = 4 // 50% overlap
+			layerMinFraction = 0.95
 		)
+		var layerThreshold uint8 = 105
+		if learned, ok := tp.learnedGrayThreshold(layer); ok {
+			layerThreshold = learned
+		}
 
 		type traceData struct {
 			path       []geometry.Point2D
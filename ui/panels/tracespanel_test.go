@@ -0,0 +1,59 @@
+package panels
+
+import (
+	"image/color"
+	"testing"
+
+	pcbimage "pcb-tracer/internal/image"
+	"pcb-tracer/internal/via"
+)
+
+func TestSideOverlayColor(t *testing.T) {
+	magenta := &color.RGBA{255, 0, 255, 255}
+	translucent := &color.RGBA{0, 255, 255, 200}
+	front, back, unknown := pcbimage.SideFront, pcbimage.SideBack, pcbimage.SideUnknown
+	cases := []struct {
+		name         string
+		col          *color.RGBA
+		side, active pcbimage.Side
+		fade         bool
+		wantA        uint8
+	}{
+		{"back with front raised", magenta, back, front, true, 63},
+		{"front with back raised", magenta, front, back, true, 63},
+		{"translucent back with front raised", translucent, back, front, true, 50},
+		{"back with back raised", magenta, back, back, true, 255},
+		{"fading off", magenta, back, front, false, 255},
+		{"no layer raised", magenta, back, unknown, true, 255},
+	}
+	for _, c := range cases {
+		got := sideOverlayColor(c.col, c.side, c.active, c.fade)
+		if got.A != c.wantA || got.R != c.col.R || got.G != c.col.G || got.B != c.col.B {
+			t.Errorf("%s: %v, want %v with alpha %d", c.name, *got, *c.col, c.wantA)
+		}
+	}
+	if magenta.A != 255 || translucent.A != 200 {
+		t.Error("sideOverlayColor modified the shared color")
+	}
+}
+
+func TestDetectedViaStyleFor(t *testing.T) {
+	matched := via.Via{ID: "via-001", BothSidesConfirmed: true}
+	half := via.Via{ID: "via-002"}
+	cases := []struct {
+		name         string
+		v            via.Via
+		matchingDone bool
+		want         detectedViaStyle
+	}{
+		{"before matching", half, false, viaStyleFilled},
+		{"stale match flag before matching", matched, false, viaStyleFilled},
+		{"matched", matched, true, viaStyleHidden},
+		{"unmatched", half, true, viaStyleUnmatched},
+	}
+	for _, c := range cases {
+		if got := detectedViaStyleFor(c.v, c.matchingDone); got != c.want {
+			t.Errorf("%s: style %d, want %d", c.name, got, c.want)
+		}
+	}
+}